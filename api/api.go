@@ -4,6 +4,7 @@ import (
 	"github.com/dilmurodov/online_banking/api/docs"
 	"github.com/dilmurodov/online_banking/api/handlers"
 	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/pkg/models"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
@@ -30,20 +31,46 @@ func SetUpRouter(h handlers.Handler, cfg config.Config) (r *gin.Engine) {
 	api := r.Group("/api")
 	{
 		v1 := api.Group("/v1")
+		// id запроса и запись изменяющих запросов в журнал аудита
+		v1.Use(h.RequestIDMiddleware, h.AuditMiddleware)
 		{
 			// auth
 			auth := v1.Group("/auth")
 			{
 				// авторизация
 				auth.POST("/login", h.LoginHandler)
+				// второй шаг авторизации с кодом 2FA
+				auth.POST("/login/mfa", h.LoginMFAHandler)
 				// регистрация
 				auth.POST("/register", h.RegisterHandler)
+				// запрос кода для восстановления пароля
+				auth.POST("/password/forgot", h.ForgotPasswordHandler)
+				// смена пароля по коду
+				auth.POST("/password/reset", h.ResetPasswordHandler)
 			}
 
 			// user
 			account := v1.Group("/user")
 			account.Use(h.AuthMiddleware)
 			{
+				// профиль пользователя
+				account.GET("/me", h.UserMeGetHandler)
+				account.PATCH("/me", h.UserMeUpdateHandler)
+				// удаление пользователя с закрытием его счетов
+				account.DELETE("/me", h.UserMeDeleteHandler)
+				// смена пароля
+				account.POST("/me/password", h.StepUpMiddleware, h.UserPasswordChangeHandler)
+				// смена номера телефона с подтверждением кодом из SMS
+				account.POST("/me/phone", h.StepUpMiddleware, h.UserPhoneChangeHandler)
+				account.POST("/me/phone/confirm", h.UserPhoneConfirmHandler)
+				// двухфакторная аутентификация
+				account.POST("/me/2fa/enroll", h.TOTPEnrollHandler)
+				account.POST("/me/2fa/confirm", h.TOTPConfirmHandler)
+				account.POST("/me/2fa/disable", h.TOTPDisableHandler)
+				account.POST("/me/2fa/recovery-codes", h.RecoveryCodesRegenerateHandler)
+				// подтверждение кодом перед важными операциями
+				account.POST("/me/2fa/step-up", h.StepUpHandler)
+
 				// создание счета
 				account.POST("/accounts", h.AccountCreateHandler)
 				// получение списка счетов
@@ -54,6 +81,82 @@ func SetUpRouter(h handlers.Handler, cfg config.Config) (r *gin.Engine) {
 				account.GET("/accounts/:id/transactions", h.AccountTransactionsHandler)
 				// получение транзакции по id
 				account.GET("/accounts/:id/transactions/:transaction_id", h.AccountTransactionByIDHandler)
+				// поиск транзакций по всем счетам
+				account.GET("/transactions/search", h.TransactionsSearchHandler)
+				// история начисления процентов
+				account.GET("/accounts/:id/interest", h.InterestAccrualsGetHandler)
+				// продукты счетов и процентные ставки
+				account.GET("/products", h.ProductsGetHandler)
+				// использование овердрафта
+				account.GET("/accounts/:id/overdraft", h.OverdraftGetHandler)
+				// подключение овердрафта и изменение лимита
+				account.PUT("/accounts/:id/overdraft", h.OverdraftSetHandler)
+				// копилки счета и прогресс накоплений
+				account.GET("/accounts/:id/pots", h.PotsGetHandler)
+				account.POST("/accounts/:id/pots", h.PotCreateHandler)
+				account.GET("/accounts/:id/pots/:pot_id", h.PotGetHandler)
+				account.PUT("/accounts/:id/pots/:pot_id", h.PotUpdateHandler)
+				// перевод между основным балансом и копилкой
+				account.POST("/accounts/:id/pots/:pot_id/deposit", h.PotDepositHandler)
+				account.POST("/accounts/:id/pots/:pot_id/withdraw", h.PotWithdrawHandler)
+				// закрытие копилки с возвратом остатка на счет
+				account.DELETE("/accounts/:id/pots/:pot_id", h.PotCloseHandler)
+				// выписка по счету
+				account.GET("/accounts/:id/statement", h.AccountStatementHandler)
+				// совладельцы счета и их роли
+				account.GET("/accounts/:id/holders", h.AccountHoldersGetHandler)
+				account.PUT("/accounts/:id/holders/:user_id", h.AccountHolderUpdateHandler)
+				account.DELETE("/accounts/:id/holders/:user_id", h.AccountHolderDeleteHandler)
+				// приглашение совладельца по номеру телефона
+				account.POST("/accounts/:id/invitations", h.StepUpMiddleware, h.InvitationCreateHandler)
+				account.DELETE("/accounts/:id/invitations/:invitation_id", h.InvitationRevokeHandler)
+				// порог перевода, требующего подтверждения второго владельца
+				account.PUT("/accounts/:id/dual-approval", h.DualApprovalSetHandler)
+				// переводы на подтверждении
+				account.GET("/accounts/:id/approvals", h.TransferApprovalsGetHandler)
+				account.POST("/accounts/:id/approvals/:approval_id/approve", h.TransferApproveHandler)
+				account.POST("/accounts/:id/approvals/:approval_id/reject", h.TransferRejectHandler)
+				// входящие приглашения стать совладельцем
+				account.GET("/invitations", h.InvitationsGetHandler)
+				account.POST("/invitations/:id/accept", h.InvitationAcceptHandler)
+				account.POST("/invitations/:id/decline", h.InvitationDeclineHandler)
+				// счет для зачисления переводов по номеру телефона
+				account.PUT("/default-account", h.SetDefaultAccountHandler)
+				// закрытие счета и история его статусов
+				account.POST("/accounts/:id/close", h.StepUpMiddleware, h.AccountCloseHandler)
+				account.GET("/accounts/:id/status-history", h.AccountStatusHistoryHandler)
+
+				// справочник получателей
+				account.POST("/beneficiaries", h.StepUpMiddleware, h.BeneficiaryCreateHandler)
+				account.GET("/beneficiaries", h.BeneficiariesGetHandler)
+				account.GET("/beneficiaries/:id", h.BeneficiaryGetHandler)
+				account.PUT("/beneficiaries/:id", h.StepUpMiddleware, h.BeneficiaryUpdateHandler)
+				account.DELETE("/beneficiaries/:id", h.BeneficiaryDeleteHandler)
+
+				// срочные вклады
+				account.GET("/deposits/terms", h.DepositTermsGetHandler)
+				account.POST("/deposits", h.DepositOpenHandler)
+				account.GET("/deposits", h.DepositsGetHandler)
+				account.GET("/deposits/:id", h.DepositGetHandler)
+				// распоряжение по окончании срока вклада
+				account.PUT("/deposits/:id/instruction", h.DepositInstructionUpdateHandler)
+				// досрочное расторжение вклада
+				account.POST("/deposits/:id/withdraw", h.DepositWithdrawHandler)
+
+				// потребительские кредиты
+				account.GET("/loans/products", h.LoanProductsGetHandler)
+				// расчет графика платежей
+				account.POST("/loans/quote", h.LoanQuoteHandler)
+				account.POST("/loans", h.LoanApplyHandler)
+				account.GET("/loans", h.LoansGetHandler)
+				account.GET("/loans/:id", h.LoanGetHandler)
+				// досрочное погашение кредита
+				account.POST("/loans/:id/early-repayment", h.LoanEarlyRepaymentHandler)
+				// уровень верификации KYC и его лимиты
+				account.GET("/kyc", h.KYCStatusHandler)
+				// загрузка документов и заявка на повышение уровня
+				account.POST("/kyc/documents", h.KYCDocumentUploadHandler)
+				account.POST("/kyc/applications", h.KYCApplicationSubmitHandler)
 			}
 
 			// payments
@@ -70,6 +173,82 @@ func SetUpRouter(h handlers.Handler, cfg config.Config) (r *gin.Engine) {
 				payments.POST("/capture", h.CaptureTransactionsHandler)
 				// перевод на чужой счет
 				payments.POST("/transfer", h.TransferHandler)
+				// поиск получателя по номеру телефона
+				payments.POST("/phone/lookup", h.PhoneLookupHandler)
+				// перевод по номеру телефона
+				payments.POST("/phone/transfer", h.PhoneTransferHandler)
+				// подтверждение платежа, остановленного антифрод-проверкой
+				payments.POST("/challenges/:id/confirm", h.ConfirmPaymentChallengeHandler)
+
+				// запрос денег у другого пользователя
+				payments.POST("/requests", h.CreatePaymentRequestHandler)
+				// входящие запросы на оплату
+				payments.GET("/requests/incoming", h.IncomingPaymentRequestsHandler)
+				// исходящие запросы на оплату
+				payments.GET("/requests/outgoing", h.OutgoingPaymentRequestsHandler)
+				// получение запроса на оплату с историей
+				payments.GET("/requests/:id", h.GetPaymentRequestHandler)
+				// оплата запроса
+				payments.POST("/requests/:id/accept", h.AcceptPaymentRequestHandler)
+				// отклонение запроса
+				payments.POST("/requests/:id/decline", h.DeclinePaymentRequestHandler)
+				// отмена запроса отправителем
+				payments.POST("/requests/:id/cancel", h.CancelPaymentRequestHandler)
+			}
+
+			// admin
+			admin := v1.Group("/admin")
+			admin.Use(h.AuthMiddleware)
+			{
+				// поиск и просмотр пользователей
+				admin.GET("/users", h.RequirePermission(models.PermissionUsersRead), h.AdminSearchUsersHandler)
+				admin.GET("/users/:id", h.RequirePermission(models.PermissionUsersRead), h.AdminGetUserHandler)
+				// назначение роли
+				admin.PUT("/users/:id/role", h.RequirePermission(models.PermissionRolesManage), h.AdminSetUserRoleHandler)
+				// поиск и просмотр счетов
+				admin.GET("/accounts", h.RequirePermission(models.PermissionAccountsRead), h.AdminSearchAccountsHandler)
+				admin.GET("/accounts/:id", h.RequirePermission(models.PermissionAccountsRead), h.AdminGetAccountHandler)
+				admin.GET("/accounts/:id/transactions", h.RequirePermission(models.PermissionTransactionsRead), h.AdminAccountTransactionsHandler)
+				// просмотр любой транзакции
+				admin.GET("/transactions/:id", h.RequirePermission(models.PermissionTransactionsRead), h.AdminGetTransactionHandler)
+				// предложение отмены перевода
+				admin.POST("/transactions/:id/reversal", h.RequirePermission(models.PermissionTransactionsReverse), h.AdminReverseTransactionHandler)
+				// блокировка счета и предложение разблокировки
+				admin.POST("/accounts/:id/freeze", h.RequirePermission(models.PermissionAccountsFreeze), h.AdminAccountFreezeHandler)
+				admin.POST("/accounts/:id/unfreeze", h.RequirePermission(models.PermissionAccountsFreeze), h.AdminAccountUnfreezeHandler)
+				// история статусов счета
+				admin.GET("/accounts/:id/status-history", h.RequirePermission(models.PermissionAccountsRead), h.AdminAccountStatusHistoryHandler)
+				// предложение ручной корректировки баланса
+				admin.POST("/accounts/:id/adjustments", h.RequirePermission(models.PermissionAdjustmentsCreate), h.AdminCreateAdjustmentHandler)
+				// операции на подтверждении вторым сотрудником
+				admin.GET("/operations", h.RequirePermission(models.PermissionOperationsRead), h.AdminOperationsHandler)
+				admin.GET("/operations/:id", h.RequirePermission(models.PermissionOperationsRead), h.AdminGetOperationHandler)
+				admin.POST("/operations/:id/approve", h.RequirePermission(models.PermissionOperationsDecide), h.AdminApproveOperationHandler)
+				admin.POST("/operations/:id/reject", h.RequirePermission(models.PermissionOperationsDecide), h.AdminRejectOperationHandler)
+				// журнал аудита
+				admin.GET("/audit-events", h.RequirePermission(models.PermissionAuditRead), h.AdminAuditEventsHandler)
+				// антифрод: решения проверок и правила
+				admin.GET("/fraud/decisions", h.RequirePermission(models.PermissionFraudReview), h.AdminFraudDecisionsHandler)
+				admin.GET("/fraud/decisions/:id", h.RequirePermission(models.PermissionFraudReview), h.AdminGetFraudDecisionHandler)
+				admin.POST("/fraud/decisions/:id/review", h.RequirePermission(models.PermissionFraudReview), h.AdminReviewFraudDecisionHandler)
+				admin.GET("/fraud/rules", h.RequirePermission(models.PermissionFraudReview), h.AdminFraudRulesHandler)
+				admin.POST("/fraud/rules/reload", h.RequirePermission(models.PermissionFraudRulesManage), h.AdminReloadFraudRulesHandler)
+				// AML: кейсы подозрительных операций и отчеты по ним
+				admin.GET("/aml/cases", h.RequirePermission(models.PermissionAMLCases), h.AdminAMLCasesHandler)
+				admin.GET("/aml/cases/:id", h.RequirePermission(models.PermissionAMLCases), h.AdminGetAMLCaseHandler)
+				admin.POST("/aml/cases/:id/status", h.RequirePermission(models.PermissionAMLCases), h.AdminUpdateAMLCaseStatusHandler)
+				admin.GET("/aml/cases/:id/report", h.RequirePermission(models.PermissionAMLCases), h.AdminAMLCaseReportHandler)
+				// санкционный скрининг: совпадения на проверке и список
+				admin.GET("/sanctions/hits", h.RequirePermission(models.PermissionSanctionsReview), h.AdminSanctionsHitsHandler)
+				admin.GET("/sanctions/hits/:id", h.RequirePermission(models.PermissionSanctionsReview), h.AdminGetSanctionsHitHandler)
+				admin.POST("/sanctions/hits/:id/review", h.RequirePermission(models.PermissionSanctionsReview), h.AdminReviewSanctionsHitHandler)
+				admin.GET("/sanctions/list", h.RequirePermission(models.PermissionSanctionsReview), h.AdminSanctionsListHandler)
+				admin.POST("/sanctions/list/reload", h.RequirePermission(models.PermissionSanctionsListManage), h.AdminReloadSanctionsListHandler)
+				// KYC: заявки на верификацию и документы к ним
+				admin.GET("/kyc/applications", h.RequirePermission(models.PermissionKYCReview), h.AdminKYCApplicationsHandler)
+				admin.GET("/kyc/applications/:id", h.RequirePermission(models.PermissionKYCReview), h.AdminGetKYCApplicationHandler)
+				admin.POST("/kyc/applications/:id/review", h.RequirePermission(models.PermissionKYCReview), h.AdminReviewKYCApplicationHandler)
+				admin.GET("/kyc/documents/:id/file", h.RequirePermission(models.PermissionKYCReview), h.AdminKYCDocumentFileHandler)
 			}
 		}
 	}
@@ -81,7 +260,8 @@ func customCORSMiddleware() gin.HandlerFunc {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Credentials", "true")
 		c.Header("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, PATCH, DELETE")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-Request-ID")
+		c.Header("Access-Control-Expose-Headers", "X-Request-ID")
 		c.Header("Access-Control-Max-Age", "3600")
 
 		if c.Request.Method == "OPTIONS" {
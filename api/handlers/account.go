@@ -1,8 +1,13 @@
 package handlers
 
 import (
+	"encoding/csv"
+	"fmt"
+	"strconv"
+
 	"github.com/dilmurodov/online_banking/api/http"
 	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
 	"github.com/dilmurodov/online_banking/pkg/models"
 	"github.com/dilmurodov/online_banking/pkg/util"
 	"github.com/gin-gonic/gin"
@@ -13,11 +18,12 @@ import (
 // @ID create_account
 // @Router /api/v1/user/accounts [POST]
 // @Summary Create Account
-// @Description Create Account
+// @Description Create Account. The product defaults to a current account.
 // @Tags Account
 // @Accept json
 // @Produce json
-// @Success 201 {object} http.Response{data=string} "Created"
+// @Param body body models.CreateAccountRequest false "Account product"
+// @Success 201 {object} http.Response{data=models.Account} "Created"
 // @Response 400 {object} http.Response{data=string} "Bad Request"
 // @Failure 500 {object} http.Response{data=string} "Server Error"
 func (h *Handler) AccountCreateHandler(c *gin.Context) {
@@ -30,13 +36,23 @@ func (h *Handler) AccountCreateHandler(c *gin.Context) {
 
 	auth := authObj.(*models.HasAccessModel)
 
-	req := &models.CreateAccountRequest{
-		UserID:  auth.UserId,
-		Balance: 0,
+	req := &models.CreateAccountRequest{}
+
+	// the body is optional, older clients send none
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(req); err != nil {
+			h.handleResponse(c, http.BadRequest, err.Error())
+			return
+		}
 	}
+	req.UserID = auth.UserId
+	req.Balance = 0
 
 	resp, err := h.services.AccountService().CreateAccount(c.Request.Context(), req)
-	if err != nil {
+	if _, ok := err.(*customerrors.ProductNotFoundError); ok {
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	} else if err != nil {
 		h.handleResponse(c, http.InternalServerError, err.Error())
 		return
 	}
@@ -53,7 +69,10 @@ func (h *Handler) AccountCreateHandler(c *gin.Context) {
 // @Tags Account
 // @Accept json
 // @Produce json
-// @Success 200 {object} http.Response{data=string} "OK"
+// @Param cursor query string false "next_cursor or prev_cursor from a previous page"
+// @Param offset query integer false "offset, ignored when cursor is set"
+// @Param limit query integer false "limit"
+// @Success 200 {object} http.Response{data=models.GetAccountsByUserIDResponse} "OK"
 // @Response 400 {object} http.Response{data=string} "Bad Request"
 // @Failure 500 {object} http.Response{data=string} "Server Error"
 func (h *Handler) AccountsGetHandler(c *gin.Context) {
@@ -66,12 +85,30 @@ func (h *Handler) AccountsGetHandler(c *gin.Context) {
 
 	auth := authObj.(*models.HasAccessModel)
 
+	offset, err := h.getOffsetParam(c)
+	if err != nil {
+		h.handleResponse(c, http.InvalidArgument, err.Error())
+		return
+	}
+
+	limit, err := h.getLimitParam(c)
+	if err != nil {
+		h.handleResponse(c, http.InvalidArgument, err.Error())
+		return
+	}
+
 	req := &models.GetAccountsByUserIDRequest{
 		UserID: auth.UserId,
+		Cursor: c.Query("cursor"),
+		Limit:  limit,
+		Offset: offset,
 	}
 
 	resp, err := h.services.AccountService().GetAccountsByUserID(c.Request.Context(), req)
-	if err != nil {
+	if _, ok := err.(*customerrors.InvalidCursorError); ok {
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	} else if err != nil {
 		h.handleResponse(c, http.InternalServerError, err.Error())
 		return
 	}
@@ -94,12 +131,30 @@ func (h *Handler) AccountsGetHandler(c *gin.Context) {
 // @Failure 500 {object} http.Response{data=string} "Server Error"
 func (h *Handler) AccountGetHandler(c *gin.Context) {
 
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
 	accountID := c.Param("id")
 	if !util.IsValidUUID(accountID) {
 		h.handleResponse(c, http.BadRequest, "Invalid account ID")
 		return
 	}
 
+	verified, err := h.userHasAccountPermission(c, auth.UserId, accountID, models.AccountPermissionView)
+	if err != nil {
+		h.handleResponse(c, http.InternalServerError, err.Error())
+		return
+	}
+	if !verified {
+		h.handleResponse(c, http.BadRequest, "account not found")
+		return
+	}
+
 	req := &models.GetAccountByIDRequest{
 		ID: accountID,
 	}
@@ -123,23 +178,64 @@ func (h *Handler) AccountGetHandler(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path string true "Account ID"
-// @Success 200 {object} http.Response{data=string} "OK"
+// @Param search query string false "Description text or exact reference"
+// @Param cursor query string false "next_cursor or prev_cursor from a previous page"
+// @Param offset query integer false "offset, ignored when cursor is set"
+// @Param limit query integer false "limit"
+// @Success 200 {object} http.Response{data=models.GetTransactionsByAccountIDResponse} "OK"
 // @Response 400 {object} http.Response{data=string} "Bad Request"
 // @Failure 500 {object} http.Response{data=string} "Server Error"
 func (h *Handler) AccountTransactionsHandler(c *gin.Context) {
 
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
 	accountID := c.Param("id")
 	if !util.IsValidUUID(accountID) {
 		h.handleResponse(c, http.BadRequest, "Invalid account ID")
 		return
 	}
 
+	verified, err := h.userHasAccountPermission(c, auth.UserId, accountID, models.AccountPermissionView)
+	if err != nil {
+		h.handleResponse(c, http.InternalServerError, err.Error())
+		return
+	}
+	if !verified {
+		h.handleResponse(c, http.BadRequest, "account not found")
+		return
+	}
+
+	offset, err := h.getOffsetParam(c)
+	if err != nil {
+		h.handleResponse(c, http.InvalidArgument, err.Error())
+		return
+	}
+
+	limit, err := h.getLimitParam(c)
+	if err != nil {
+		h.handleResponse(c, http.InvalidArgument, err.Error())
+		return
+	}
+
 	req := &models.GetTransactionsByAccountIDRequest{
 		AccountID: accountID,
+		Search:    c.Query("search"),
+		Cursor:    c.Query("cursor"),
+		Limit:     limit,
+		Offset:    offset,
 	}
 
 	resp, err := h.services.AccountService().GetAccountTransactions(c.Request.Context(), req)
-	if err != nil {
+	if _, ok := err.(*customerrors.InvalidCursorError); ok {
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	} else if err != nil {
 		h.handleResponse(c, http.InternalServerError, err.Error())
 		return
 	}
@@ -163,12 +259,30 @@ func (h *Handler) AccountTransactionsHandler(c *gin.Context) {
 // @Failure 500 {object} http.Response{data=string} "Server Error"
 func (h *Handler) AccountTransactionByIDHandler(c *gin.Context) {
 
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
 	accountID := c.Param("id")
 	if !util.IsValidUUID(accountID) {
 		h.handleResponse(c, http.BadRequest, "Invalid account ID")
 		return
 	}
 
+	verified, err := h.userHasAccountPermission(c, auth.UserId, accountID, models.AccountPermissionView)
+	if err != nil {
+		h.handleResponse(c, http.InternalServerError, err.Error())
+		return
+	}
+	if !verified {
+		h.handleResponse(c, http.BadRequest, "account not found")
+		return
+	}
+
 	transactionID := c.Param("transaction_id")
 	if !util.IsValidUUID(transactionID) {
 		h.handleResponse(c, http.BadRequest, "Invalid transaction ID")
@@ -191,3 +305,221 @@ func (h *Handler) AccountTransactionByIDHandler(c *gin.Context) {
 
 	h.handleResponse(c, http.OK, resp)
 }
+
+// SetDefaultAccount godoc
+// @Security BearerAuth
+// @ID set_default_account
+// @Router /api/v1/user/default-account [PUT]
+// @Summary Set Default Receiving Account
+// @Description Set the account that receives transfers sent to the user's phone number. It must be an account the user can pay from.
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param body body models.SetDefaultAccountRequest true "Default account"
+// @Success 200 {object} http.Response{data=string} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) SetDefaultAccountHandler(c *gin.Context) {
+
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	var req models.SetDefaultAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	}
+	if !util.IsValidUUID(req.AccountID) {
+		h.handleResponse(c, http.BadRequest, "Invalid account ID")
+		return
+	}
+
+	// money sent to the phone is only received on an account the user can also pay from
+	verified, err := h.userHasAccountPermission(c, auth.UserId, req.AccountID, models.AccountPermissionTransact)
+	if err != nil {
+		h.handleResponse(c, http.InternalServerError, err.Error())
+		return
+	}
+	if !verified {
+		h.handleResponse(c, http.BadRequest, "account not found")
+		return
+	}
+
+	req.UserID = auth.UserId
+	err = h.services.UserService().SetDefaultAccount(c.Request.Context(), &req)
+	if err != nil {
+		h.handleResponse(c, http.InternalServerError, err.Error())
+		return
+	}
+
+	h.handleResponse(c, http.OK, "OK")
+}
+
+// GetAccountStatement godoc
+// @Security BearerAuth
+// @ID get_account_statement
+// @Router /api/v1/user/accounts/{id}/statement [GET]
+// @Summary Export Account Statement
+// @Description Export the account's transactions with their descriptions and references as CSV. A statement holds at most 10000 transactions, when there are more the X-Next-Cursor header carries the cursor of the rest.
+// @Tags Account
+// @Accept json
+// @Produce text/csv
+// @Param id path string true "Account ID"
+// @Param from query string false "RFC3339 start of the period"
+// @Param to query string false "RFC3339 end of the period"
+// @Param cursor query string false "X-Next-Cursor of the previous part of the statement"
+// @Success 200 {string} string "CSV statement"
+// @Header 200 {string} X-Next-Cursor "Cursor of the rest of the statement, only set when it was cut off"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) AccountStatementHandler(c *gin.Context) {
+
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	accountID := c.Param("id")
+	if !util.IsValidUUID(accountID) {
+		h.handleResponse(c, http.BadRequest, "Invalid account ID")
+		return
+	}
+
+	verified, err := h.userHasAccountPermission(c, auth.UserId, accountID, models.AccountPermissionView)
+	if err != nil {
+		h.handleResponse(c, http.InternalServerError, err.Error())
+		return
+	}
+	if !verified {
+		h.handleResponse(c, http.BadRequest, "account not found")
+		return
+	}
+
+	resp, err := h.services.AccountService().GetAccountTransactions(c.Request.Context(), &models.GetTransactionsByAccountIDRequest{
+		AccountID: accountID,
+		From:      c.Query("from"),
+		To:        c.Query("to"),
+		Cursor:    c.Query("cursor"),
+		Limit:     config.StatementMaxRows,
+	})
+	if _, ok := err.(*customerrors.InvalidCursorError); ok {
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	} else if err != nil {
+		h.handleResponse(c, http.InternalServerError, err.Error())
+		return
+	}
+
+	// a statement cut off at the row limit tells the client where the rest starts
+	if resp.NextCursor != "" {
+		c.Header("X-Next-Cursor", resp.NextCursor)
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=statement-%s.csv", accountID))
+	c.Header("Content-Type", "text/csv")
+
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write([]string{"id", "created_at", "type", "amount", "counterparty", "description", "reference", "done"})
+	for _, t := range resp.Transactions {
+		_ = w.Write([]string{
+			t.ID,
+			t.CreatedAt,
+			t.Type,
+			strconv.FormatFloat(t.Amount, 'f', 2, 64),
+			t.RecipientID,
+			t.Description,
+			t.Reference,
+			strconv.FormatBool(t.Done),
+		})
+	}
+	w.Flush()
+}
+
+// SearchTransactions godoc
+// @Security BearerAuth
+// @ID search_transactions
+// @Router /api/v1/user/transactions/search [GET]
+// @Summary Search Transactions
+// @Description Search transactions across all of the user's accounts. The response contains counts by type and by month for the same filter.
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param q query string false "Description text or exact reference"
+// @Param type query string false "debit or credit"
+// @Param status query string false "pending or completed"
+// @Param min_amount query number false "Minimum amount"
+// @Param max_amount query number false "Maximum amount"
+// @Param counterparty_id query string false "Counterparty account ID"
+// @Param from query string false "RFC3339 start of the period"
+// @Param to query string false "RFC3339 end of the period"
+// @Param offset query integer false "offset"
+// @Param limit query integer false "limit"
+// @Success 200 {object} http.Response{data=models.SearchTransactionsResponse} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) TransactionsSearchHandler(c *gin.Context) {
+
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	offset, err := h.getOffsetParam(c)
+	if err != nil {
+		h.handleResponse(c, http.InvalidArgument, err.Error())
+		return
+	}
+
+	limit, err := h.getLimitParam(c)
+	if err != nil {
+		h.handleResponse(c, http.InvalidArgument, err.Error())
+		return
+	}
+
+	req := &models.SearchTransactionsRequest{
+		UserID:         auth.UserId,
+		Query:          c.Query("q"),
+		Type:           c.Query("type"),
+		Status:         c.Query("status"),
+		CounterpartyID: c.Query("counterparty_id"),
+		From:           c.Query("from"),
+		To:             c.Query("to"),
+		Limit:          limit,
+		Offset:         offset,
+	}
+
+	if v := c.Query("min_amount"); v != "" {
+		if req.MinAmount, err = strconv.ParseFloat(v, 64); err != nil {
+			h.handleResponse(c, http.InvalidArgument, "invalid min_amount")
+			return
+		}
+	}
+	if v := c.Query("max_amount"); v != "" {
+		if req.MaxAmount, err = strconv.ParseFloat(v, 64); err != nil {
+			h.handleResponse(c, http.InvalidArgument, "invalid max_amount")
+			return
+		}
+	}
+
+	resp, err := h.services.AccountService().SearchTransactions(c.Request.Context(), req)
+	if err != nil {
+		if _, ok := err.(*customerrors.InternalServerError); ok {
+			h.handleResponse(c, http.InternalServerError, err.Error())
+			return
+		}
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
@@ -0,0 +1,554 @@
+package handlers
+
+import (
+	"github.com/dilmurodov/online_banking/api/http"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/dilmurodov/online_banking/pkg/util"
+	"github.com/gin-gonic/gin"
+)
+
+// GetAccountHolders godoc
+// @Security BearerAuth
+// @ID get_account_holders
+// @Router /api/v1/user/accounts/{id}/holders [GET]
+// @Summary Get Account Holders
+// @Description Get the holders of an account with their roles and the dual approval threshold
+// @Tags Joint Account
+// @Accept json
+// @Produce json
+// @Param id path string true "Account ID"
+// @Success 200 {object} http.Response{data=models.GetAccountHoldersResponse} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) AccountHoldersGetHandler(c *gin.Context) {
+
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	accountID := c.Param("id")
+	if !util.IsValidUUID(accountID) {
+		h.handleResponse(c, http.BadRequest, "Invalid account ID")
+		return
+	}
+
+	resp, err := h.services.AccountService().GetAccountHolders(c.Request.Context(), &models.AccountHolderRequest{
+		AccountID: accountID,
+		UserID:    auth.UserId,
+	})
+	if err != nil {
+		h.handleAccountHolderError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// UpdateAccountHolder godoc
+// @Security BearerAuth
+// @ID update_account_holder
+// @Router /api/v1/user/accounts/{id}/holders/{user_id} [PUT]
+// @Summary Update Account Holder
+// @Description Change the role of a holder, only the owner can do it and the owner's role can't be changed
+// @Tags Joint Account
+// @Accept json
+// @Produce json
+// @Param id path string true "Account ID"
+// @Param user_id path string true "Holder's user ID"
+// @Param body body models.AccountHolderRequest true "Role"
+// @Success 200 {object} http.Response{data=models.GetAccountHoldersResponse} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) AccountHolderUpdateHandler(c *gin.Context) {
+
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	byID, ok := h.accountHolderRequest(c, auth.UserId)
+	if !ok {
+		return
+	}
+
+	var req models.AccountHolderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	}
+	req.AccountID = byID.AccountID
+	req.UserID = byID.UserID
+	req.HolderID = byID.HolderID
+
+	resp, err := h.services.AccountService().UpdateAccountHolder(c.Request.Context(), &req)
+	if err != nil {
+		h.handleAccountHolderError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// DeleteAccountHolder godoc
+// @Security BearerAuth
+// @ID delete_account_holder
+// @Router /api/v1/user/accounts/{id}/holders/{user_id} [DELETE]
+// @Summary Remove Account Holder
+// @Description Remove a holder from the account. The owner removes anyone but themselves, other holders can leave the account
+// @Tags Joint Account
+// @Accept json
+// @Produce json
+// @Param id path string true "Account ID"
+// @Param user_id path string true "Holder's user ID"
+// @Success 200 {object} http.Response{data=string} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) AccountHolderDeleteHandler(c *gin.Context) {
+
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	req, ok := h.accountHolderRequest(c, auth.UserId)
+	if !ok {
+		return
+	}
+
+	if err := h.services.AccountService().DeleteAccountHolder(c.Request.Context(), req); err != nil {
+		h.handleAccountHolderError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, "OK")
+}
+
+// SetDualApproval godoc
+// @Security BearerAuth
+// @ID set_dual_approval
+// @Router /api/v1/user/accounts/{id}/dual-approval [PUT]
+// @Summary Set Dual Approval
+// @Description Set the amount above which a transfer from the account waits for the approval of a second holder, zero turns it off
+// @Tags Joint Account
+// @Accept json
+// @Produce json
+// @Param id path string true "Account ID"
+// @Param body body models.SetDualApprovalRequest true "Threshold"
+// @Success 200 {object} http.Response{data=models.GetAccountHoldersResponse} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) DualApprovalSetHandler(c *gin.Context) {
+
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	accountID := c.Param("id")
+	if !util.IsValidUUID(accountID) {
+		h.handleResponse(c, http.BadRequest, "Invalid account ID")
+		return
+	}
+
+	var req models.SetDualApprovalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	}
+	req.AccountID = accountID
+	req.UserID = auth.UserId
+
+	resp, err := h.services.AccountService().SetDualApproval(c.Request.Context(), &req)
+	if err != nil {
+		h.handleAccountHolderError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// CreateInvitation godoc
+// @Security BearerAuth
+// @ID create_account_invitation
+// @Router /api/v1/user/accounts/{id}/invitations [POST]
+// @Summary Invite Account Holder
+// @Description Invite a user by phone number to hold the account as co_owner, signer or viewer
+// @Tags Joint Account
+// @Accept json
+// @Produce json
+// @Param id path string true "Account ID"
+// @Param body body models.CreateInvitationRequest true "Invitation"
+// @Param X-Step-Up-Token header string false "Step-up token, required once 2FA is on"
+// @Success 201 {object} http.Response{data=models.AccountInvitation} "Created"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) InvitationCreateHandler(c *gin.Context) {
+
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	accountID := c.Param("id")
+	if !util.IsValidUUID(accountID) {
+		h.handleResponse(c, http.BadRequest, "Invalid account ID")
+		return
+	}
+
+	var req models.CreateInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	}
+	req.AccountID = accountID
+	req.UserID = auth.UserId
+
+	resp, err := h.services.AccountService().CreateInvitation(c.Request.Context(), &req)
+	if err != nil {
+		h.handleAccountHolderError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.Created, resp)
+}
+
+// RevokeInvitation godoc
+// @Security BearerAuth
+// @ID revoke_account_invitation
+// @Router /api/v1/user/accounts/{id}/invitations/{invitation_id} [DELETE]
+// @Summary Revoke Invitation
+// @Description Withdraw an invitation that wasn't answered yet
+// @Tags Joint Account
+// @Accept json
+// @Produce json
+// @Param id path string true "Account ID"
+// @Param invitation_id path string true "Invitation ID"
+// @Success 200 {object} http.Response{data=models.AccountInvitation} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) InvitationRevokeHandler(c *gin.Context) {
+
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	accountID := c.Param("id")
+	if !util.IsValidUUID(accountID) {
+		h.handleResponse(c, http.BadRequest, "Invalid account ID")
+		return
+	}
+
+	invitationID := c.Param("invitation_id")
+	if !util.IsValidUUID(invitationID) {
+		h.handleResponse(c, http.BadRequest, "Invalid invitation ID")
+		return
+	}
+
+	resp, err := h.services.AccountService().RevokeInvitation(c.Request.Context(), &models.InvitationByIDRequest{
+		ID:        invitationID,
+		UserID:    auth.UserId,
+		AccountID: accountID,
+	})
+	if err != nil {
+		h.handleAccountHolderError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// GetInvitations godoc
+// @Security BearerAuth
+// @ID get_account_invitations
+// @Router /api/v1/user/invitations [GET]
+// @Summary Get Invitations
+// @Description Get the invitations to hold accounts waiting for the user's answer
+// @Tags Joint Account
+// @Accept json
+// @Produce json
+// @Success 200 {object} http.Response{data=models.GetInvitationsResponse} "OK"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) InvitationsGetHandler(c *gin.Context) {
+
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	resp, err := h.services.AccountService().GetInvitations(c.Request.Context(), auth.UserId)
+	if err != nil {
+		h.handleResponse(c, http.InternalServerError, err.Error())
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// AcceptInvitation godoc
+// @Security BearerAuth
+// @ID accept_account_invitation
+// @Router /api/v1/user/invitations/{id}/accept [POST]
+// @Summary Accept Invitation
+// @Description Accept an invitation and become a holder of the account in the invited role
+// @Tags Joint Account
+// @Accept json
+// @Produce json
+// @Param id path string true "Invitation ID"
+// @Success 200 {object} http.Response{data=models.AccountInvitation} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) InvitationAcceptHandler(c *gin.Context) {
+	h.answerInvitation(c, true)
+}
+
+// DeclineInvitation godoc
+// @Security BearerAuth
+// @ID decline_account_invitation
+// @Router /api/v1/user/invitations/{id}/decline [POST]
+// @Summary Decline Invitation
+// @Description Decline an invitation to hold an account
+// @Tags Joint Account
+// @Accept json
+// @Produce json
+// @Param id path string true "Invitation ID"
+// @Success 200 {object} http.Response{data=models.AccountInvitation} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) InvitationDeclineHandler(c *gin.Context) {
+	h.answerInvitation(c, false)
+}
+
+func (h *Handler) answerInvitation(c *gin.Context, accept bool) {
+
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	invitationID := c.Param("id")
+	if !util.IsValidUUID(invitationID) {
+		h.handleResponse(c, http.BadRequest, "Invalid invitation ID")
+		return
+	}
+
+	req := &models.InvitationByIDRequest{
+		ID:     invitationID,
+		UserID: auth.UserId,
+	}
+
+	var (
+		resp *models.AccountInvitation
+		err  error
+	)
+	if accept {
+		resp, err = h.services.AccountService().AcceptInvitation(c.Request.Context(), req)
+	} else {
+		resp, err = h.services.AccountService().DeclineInvitation(c.Request.Context(), req)
+	}
+	if err != nil {
+		h.handleAccountHolderError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// GetTransferApprovals godoc
+// @Security BearerAuth
+// @ID get_transfer_approvals
+// @Router /api/v1/user/accounts/{id}/approvals [GET]
+// @Summary Get Transfer Approvals
+// @Description Get the transfers from the account that waited or wait for a second holder's approval
+// @Tags Joint Account
+// @Accept json
+// @Produce json
+// @Param id path string true "Account ID"
+// @Param status query string false "pending, approved, rejected or expired"
+// @Param offset query integer false "offset"
+// @Param limit query integer false "limit"
+// @Success 200 {object} http.Response{data=models.GetTransferApprovalsResponse} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) TransferApprovalsGetHandler(c *gin.Context) {
+
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	accountID := c.Param("id")
+	if !util.IsValidUUID(accountID) {
+		h.handleResponse(c, http.BadRequest, "Invalid account ID")
+		return
+	}
+
+	offset, err := h.getOffsetParam(c)
+	if err != nil {
+		h.handleResponse(c, http.InvalidArgument, err.Error())
+		return
+	}
+
+	limit, err := h.getLimitParam(c)
+	if err != nil {
+		h.handleResponse(c, http.InvalidArgument, err.Error())
+		return
+	}
+
+	resp, err := h.services.PaymentService().GetTransferApprovals(c.Request.Context(), &models.GetTransferApprovalsRequest{
+		AccountID: accountID,
+		Status:    c.Query("status"),
+		Limit:     limit,
+		Offset:    offset,
+	}, auth.UserId)
+	if err != nil {
+		h.handleAccountHolderError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// ApproveTransfer godoc
+// @Security BearerAuth
+// @ID approve_transfer
+// @Router /api/v1/user/accounts/{id}/approvals/{approval_id}/approve [POST]
+// @Summary Approve Transfer
+// @Description Approve a transfer requested by another holder, the transfer is made at once
+// @Tags Joint Account
+// @Accept json
+// @Produce json
+// @Param id path string true "Account ID"
+// @Param approval_id path string true "Approval ID"
+// @Success 200 {object} http.Response{data=models.TransferApprovalResponse} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Response 403 {object} http.Response{data=string} "Blocked by the sanctions checks"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) TransferApproveHandler(c *gin.Context) {
+	h.decideTransfer(c, true)
+}
+
+// RejectTransfer godoc
+// @Security BearerAuth
+// @ID reject_transfer
+// @Router /api/v1/user/accounts/{id}/approvals/{approval_id}/reject [POST]
+// @Summary Reject Transfer
+// @Description Reject a transfer waiting for approval, the holder who requested it can withdraw it the same way
+// @Tags Joint Account
+// @Accept json
+// @Produce json
+// @Param id path string true "Account ID"
+// @Param approval_id path string true "Approval ID"
+// @Success 200 {object} http.Response{data=models.TransferApprovalResponse} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) TransferRejectHandler(c *gin.Context) {
+	h.decideTransfer(c, false)
+}
+
+func (h *Handler) decideTransfer(c *gin.Context, approve bool) {
+
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	accountID := c.Param("id")
+	if !util.IsValidUUID(accountID) {
+		h.handleResponse(c, http.BadRequest, "Invalid account ID")
+		return
+	}
+
+	approvalID := c.Param("approval_id")
+	if !util.IsValidUUID(approvalID) {
+		h.handleResponse(c, http.BadRequest, "Invalid approval ID")
+		return
+	}
+
+	req := &models.TransferApprovalByIDRequest{
+		ID:        approvalID,
+		AccountID: accountID,
+		UserID:    auth.UserId,
+	}
+
+	var (
+		resp *models.TransferApprovalResponse
+		err  error
+	)
+	if approve {
+		resp, err = h.services.PaymentService().ApproveTransfer(c.Request.Context(), req)
+	} else {
+		resp, err = h.services.PaymentService().RejectTransfer(c.Request.Context(), req)
+	}
+	if err != nil {
+		if h.handlePaymentScreeningError(c, auth.UserId, err) {
+			return
+		}
+		h.handleAccountHolderError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// accountHolderRequest reads the account and holder ids from the path, answering 400 when either is invalid
+func (h *Handler) accountHolderRequest(c *gin.Context, userID string) (*models.AccountHolderRequest, bool) {
+	accountID := c.Param("id")
+	if !util.IsValidUUID(accountID) {
+		h.handleResponse(c, http.BadRequest, "Invalid account ID")
+		return nil, false
+	}
+
+	holderID := c.Param("user_id")
+	if !util.IsValidUUID(holderID) {
+		h.handleResponse(c, http.BadRequest, "Invalid user ID")
+		return nil, false
+	}
+
+	return &models.AccountHolderRequest{
+		AccountID: accountID,
+		UserID:    userID,
+		HolderID:  holderID,
+	}, true
+}
+
+func (h *Handler) handleAccountHolderError(c *gin.Context, err error) {
+	switch err.(type) {
+	case *customerrors.InternalServerError:
+		h.handleResponse(c, http.InternalServerError, err.Error())
+	default:
+		h.handleResponse(c, http.BadRequest, err.Error())
+	}
+}
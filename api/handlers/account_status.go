@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"github.com/dilmurodov/online_banking/api/http"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/dilmurodov/online_banking/pkg/util"
+	"github.com/gin-gonic/gin"
+)
+
+// AccountCloseHandler godoc
+// @Security BearerAuth
+// @ID close_account
+// @Router /api/v1/user/accounts/{id}/close [POST]
+// @Summary Close Account
+// @Description Close an account for good. Pots, loans, term deposits and pending payments have to be settled first. Accrued interest is paid out and a remaining balance goes to the payout account
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param id path string true "Account ID"
+// @Param body body models.CloseAccountRequest true "Payout account and reason"
+// @Param X-Step-Up-Token header string false "Step-up token, required once 2FA is on"
+// @Success 200 {object} http.Response{data=models.CloseAccountResponse} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) AccountCloseHandler(c *gin.Context) {
+
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	accountID := c.Param("id")
+	if !util.IsValidUUID(accountID) {
+		h.handleResponse(c, http.BadRequest, "Invalid account ID")
+		return
+	}
+
+	var req models.CloseAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	}
+	req.AccountID = accountID
+	req.UserID = auth.UserId
+	req.IP = c.ClientIP()
+
+	resp, err := h.services.AccountStatusService().CloseAccount(c.Request.Context(), &req)
+	if err != nil {
+		h.handleAccountStatusError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// AccountStatusHistoryHandler godoc
+// @Security BearerAuth
+// @ID get_account_status_history
+// @Router /api/v1/user/accounts/{id}/status-history [GET]
+// @Summary Get Account Status History
+// @Description Get every status change of an account, newest first
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param id path string true "Account ID"
+// @Success 200 {object} http.Response{data=models.GetAccountStatusHistoryResponse} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) AccountStatusHistoryHandler(c *gin.Context) {
+
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	accountID := c.Param("id")
+	if !util.IsValidUUID(accountID) {
+		h.handleResponse(c, http.BadRequest, "Invalid account ID")
+		return
+	}
+
+	verified, err := h.userHasAccountPermission(c, auth.UserId, accountID, models.AccountPermissionView)
+	if err != nil {
+		h.handleResponse(c, http.InternalServerError, err.Error())
+		return
+	}
+	if !verified {
+		h.handleResponse(c, http.BadRequest, "account not found")
+		return
+	}
+
+	resp, err := h.services.AccountStatusService().GetStatusHistory(c.Request.Context(), accountID)
+	if err != nil {
+		h.handleAccountStatusError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// AdminAccountFreezeHandler godoc
+// @Security BearerAuth
+// @ID admin_freeze_account
+// @Router /api/v1/admin/accounts/{id}/freeze [POST]
+// @Summary Freeze Account
+// @Description Block debits from an account, credits still go through
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Account ID"
+// @Param body body models.FreezeAccountRequest true "Reason"
+// @Success 200 {object} http.Response{data=models.Account} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) AdminAccountFreezeHandler(c *gin.Context) {
+	req, ok := h.getFreezeAccountRequest(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.services.AccountStatusService().FreezeAccount(c.Request.Context(), req)
+	if err != nil {
+		h.handleAccountStatusError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// AdminAccountUnfreezeHandler godoc
+// @Security BearerAuth
+// @ID admin_unfreeze_account
+// @Router /api/v1/admin/accounts/{id}/unfreeze [POST]
+// @Summary Unfreeze Account
+// @Description Propose to let a frozen account make payments again, another staff member has to approve it
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Account ID"
+// @Param body body models.FreezeAccountRequest false "Reason"
+// @Success 201 {object} http.Response{data=models.PendingOperation} "Created"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) AdminAccountUnfreezeHandler(c *gin.Context) {
+	req, ok := h.getFreezeAccountRequest(c)
+	if !ok {
+		return
+	}
+
+	req.IP = c.ClientIP()
+
+	resp, err := h.services.AdminService().ProposeUnfreeze(c.Request.Context(), req)
+	if err != nil {
+		h.handleAccountStatusError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.Created, resp)
+}
+
+// AdminAccountStatusHistoryHandler godoc
+// @Security BearerAuth
+// @ID admin_get_account_status_history
+// @Router /api/v1/admin/accounts/{id}/status-history [GET]
+// @Summary Get Account Status History
+// @Description Get every status change of any account, newest first
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Account ID"
+// @Success 200 {object} http.Response{data=models.GetAccountStatusHistoryResponse} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) AdminAccountStatusHistoryHandler(c *gin.Context) {
+	accountID := c.Param("id")
+	if !util.IsValidUUID(accountID) {
+		h.handleResponse(c, http.BadRequest, "Invalid account ID")
+		return
+	}
+
+	resp, err := h.services.AccountStatusService().GetStatusHistory(c.Request.Context(), accountID)
+	if err != nil {
+		h.handleAccountStatusError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+func (h *Handler) getFreezeAccountRequest(c *gin.Context) (*models.FreezeAccountRequest, bool) {
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return nil, false
+	}
+
+	accountID := c.Param("id")
+	if !util.IsValidUUID(accountID) {
+		h.handleResponse(c, http.BadRequest, "Invalid account ID")
+		return nil, false
+	}
+
+	var req models.FreezeAccountRequest
+	// the body is optional, an unfreeze needs no reason
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			h.handleResponse(c, http.BadRequest, err.Error())
+			return nil, false
+		}
+	}
+	req.AccountID = accountID
+	req.ActorID = authObj.(*models.HasAccessModel).UserId
+
+	return &req, true
+}
+
+func (h *Handler) handleAccountStatusError(c *gin.Context, err error) {
+	switch err.(type) {
+	case *customerrors.InternalServerError:
+		h.handleResponse(c, http.InternalServerError, err.Error())
+	default:
+		h.handleResponse(c, http.BadRequest, err.Error())
+	}
+}
@@ -0,0 +1,546 @@
+package handlers
+
+import (
+	"github.com/dilmurodov/online_banking/api/http"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/dilmurodov/online_banking/pkg/util"
+	"github.com/gin-gonic/gin"
+)
+
+// AdminSearchUsersHandler godoc
+// @Security BearerAuth
+// @ID admin_search_users
+// @Router /api/v1/admin/users [GET]
+// @Summary Search Users
+// @Description Search users by phone, name or exact id, newest first
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param q query string false "Phone, name or user id"
+// @Param role query string false "customer, support, compliance or admin"
+// @Param offset query integer false "offset"
+// @Param limit query integer false "limit"
+// @Success 200 {object} http.Response{data=models.SearchUsersResponse} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) AdminSearchUsersHandler(c *gin.Context) {
+	offset, err := h.getOffsetParam(c)
+	if err != nil {
+		h.handleResponse(c, http.InvalidArgument, err.Error())
+		return
+	}
+
+	limit, err := h.getLimitParam(c)
+	if err != nil {
+		h.handleResponse(c, http.InvalidArgument, err.Error())
+		return
+	}
+
+	resp, err := h.services.AdminService().SearchUsers(c.Request.Context(), &models.SearchUsersRequest{
+		Query:  c.Query("q"),
+		Role:   c.Query("role"),
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		h.handleAdminError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// AdminGetUserHandler godoc
+// @Security BearerAuth
+// @ID admin_get_user
+// @Router /api/v1/admin/users/{id} [GET]
+// @Summary Get User
+// @Description Get any user with the accounts they hold
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} http.Response{data=models.AdminUserResponse} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) AdminGetUserHandler(c *gin.Context) {
+	userID := c.Param("id")
+	if !util.IsValidUUID(userID) {
+		h.handleResponse(c, http.BadRequest, "Invalid user ID")
+		return
+	}
+
+	resp, err := h.services.AdminService().GetUser(c.Request.Context(), userID)
+	if err != nil {
+		h.handleAdminError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// AdminSetUserRoleHandler godoc
+// @Security BearerAuth
+// @ID admin_set_user_role
+// @Router /api/v1/admin/users/{id}/role [PUT]
+// @Summary Set User Role
+// @Description Give a user a staff role or take it away, the user has to sign in again
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param body body models.SetUserRoleRequest true "Role"
+// @Success 200 {object} http.Response{data=models.User} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) AdminSetUserRoleHandler(c *gin.Context) {
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	userID := c.Param("id")
+	if !util.IsValidUUID(userID) {
+		h.handleResponse(c, http.BadRequest, "Invalid user ID")
+		return
+	}
+
+	var req models.SetUserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	}
+	req.UserID = userID
+	req.ActorID = auth.UserId
+	req.IP = c.ClientIP()
+
+	resp, err := h.services.AdminService().SetUserRole(c.Request.Context(), &req)
+	if err != nil {
+		h.handleAdminError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// AdminSearchAccountsHandler godoc
+// @Security BearerAuth
+// @ID admin_search_accounts
+// @Router /api/v1/admin/accounts [GET]
+// @Summary Search Accounts
+// @Description Search accounts by account id, owner id or owner phone, newest first
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param q query string false "Account id, user id or phone"
+// @Param status query string false "active, frozen or closed"
+// @Param offset query integer false "offset"
+// @Param limit query integer false "limit"
+// @Success 200 {object} http.Response{data=models.SearchAccountsResponse} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) AdminSearchAccountsHandler(c *gin.Context) {
+	offset, err := h.getOffsetParam(c)
+	if err != nil {
+		h.handleResponse(c, http.InvalidArgument, err.Error())
+		return
+	}
+
+	limit, err := h.getLimitParam(c)
+	if err != nil {
+		h.handleResponse(c, http.InvalidArgument, err.Error())
+		return
+	}
+
+	resp, err := h.services.AdminService().SearchAccounts(c.Request.Context(), &models.SearchAccountsRequest{
+		Query:  c.Query("q"),
+		Status: c.Query("status"),
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		h.handleAdminError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// AdminGetAccountHandler godoc
+// @Security BearerAuth
+// @ID admin_get_account
+// @Router /api/v1/admin/accounts/{id} [GET]
+// @Summary Get Account
+// @Description Get any account
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Account ID"
+// @Success 200 {object} http.Response{data=models.Account} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) AdminGetAccountHandler(c *gin.Context) {
+	accountID := c.Param("id")
+	if !util.IsValidUUID(accountID) {
+		h.handleResponse(c, http.BadRequest, "Invalid account ID")
+		return
+	}
+
+	resp, err := h.services.AdminService().GetAccount(c.Request.Context(), accountID)
+	if err != nil {
+		h.handleAdminError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// AdminAccountTransactionsHandler godoc
+// @Security BearerAuth
+// @ID admin_get_account_transactions
+// @Router /api/v1/admin/accounts/{id}/transactions [GET]
+// @Summary Get Account Transactions
+// @Description Get the transactions of any account
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Account ID"
+// @Param search query string false "Description text or exact reference"
+// @Param cursor query string false "next_cursor or prev_cursor from a previous page"
+// @Param offset query integer false "offset, ignored when cursor is set"
+// @Param limit query integer false "limit"
+// @Success 200 {object} http.Response{data=models.GetTransactionsByAccountIDResponse} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) AdminAccountTransactionsHandler(c *gin.Context) {
+	accountID := c.Param("id")
+	if !util.IsValidUUID(accountID) {
+		h.handleResponse(c, http.BadRequest, "Invalid account ID")
+		return
+	}
+
+	offset, err := h.getOffsetParam(c)
+	if err != nil {
+		h.handleResponse(c, http.InvalidArgument, err.Error())
+		return
+	}
+
+	limit, err := h.getLimitParam(c)
+	if err != nil {
+		h.handleResponse(c, http.InvalidArgument, err.Error())
+		return
+	}
+
+	resp, err := h.services.AdminService().GetAccountTransactions(c.Request.Context(), &models.GetTransactionsByAccountIDRequest{
+		AccountID: accountID,
+		Search:    c.Query("search"),
+		Cursor:    c.Query("cursor"),
+		Limit:     limit,
+		Offset:    offset,
+	})
+	if err != nil {
+		h.handleAdminError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// AdminGetTransactionHandler godoc
+// @Security BearerAuth
+// @ID admin_get_transaction
+// @Router /api/v1/admin/transactions/{id} [GET]
+// @Summary Get Transaction
+// @Description Get any transaction
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Transaction ID"
+// @Success 200 {object} http.Response{data=models.Transaction} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) AdminGetTransactionHandler(c *gin.Context) {
+	transactionID := c.Param("id")
+	if !util.IsValidUUID(transactionID) {
+		h.handleResponse(c, http.BadRequest, "Invalid transaction ID")
+		return
+	}
+
+	resp, err := h.services.AdminService().GetTransaction(c.Request.Context(), transactionID)
+	if err != nil {
+		h.handleAdminError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// AdminCreateAdjustmentHandler godoc
+// @Security BearerAuth
+// @ID admin_create_adjustment
+// @Router /api/v1/admin/accounts/{id}/adjustments [POST]
+// @Summary Create Manual Adjustment
+// @Description Propose to credit or debit an account by hand, another staff member has to approve it.
+// @Description Reason codes: fee_refund, chargeback, goodwill, correction, fraud_recovery
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Account ID"
+// @Param body body models.CreateAdjustmentRequest true "Adjustment"
+// @Success 201 {object} http.Response{data=models.PendingOperation} "Created"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) AdminCreateAdjustmentHandler(c *gin.Context) {
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	accountID := c.Param("id")
+	if !util.IsValidUUID(accountID) {
+		h.handleResponse(c, http.BadRequest, "Invalid account ID")
+		return
+	}
+
+	var req models.CreateAdjustmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	}
+	req.AccountID = accountID
+	req.ActorID = auth.UserId
+	req.IP = c.ClientIP()
+
+	resp, err := h.services.AdminService().ProposeAdjustment(c.Request.Context(), &req)
+	if err != nil {
+		h.handleAdminError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.Created, resp)
+}
+
+// AdminReverseTransactionHandler godoc
+// @Security BearerAuth
+// @ID admin_reverse_transaction
+// @Router /api/v1/admin/transactions/{id}/reversal [POST]
+// @Summary Reverse Transaction
+// @Description Propose to post a completed transfer back, another staff member has to approve it.
+// @Description Reason codes: fee_refund, chargeback, goodwill, correction, fraud_recovery
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Transaction ID"
+// @Param body body models.ReverseTransactionRequest true "Reason"
+// @Success 201 {object} http.Response{data=models.PendingOperation} "Created"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) AdminReverseTransactionHandler(c *gin.Context) {
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	transactionID := c.Param("id")
+	if !util.IsValidUUID(transactionID) {
+		h.handleResponse(c, http.BadRequest, "Invalid transaction ID")
+		return
+	}
+
+	var req models.ReverseTransactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	}
+	req.TransactionID = transactionID
+	req.ActorID = auth.UserId
+	req.IP = c.ClientIP()
+
+	resp, err := h.services.AdminService().ProposeReversal(c.Request.Context(), &req)
+	if err != nil {
+		h.handleAdminError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.Created, resp)
+}
+
+// AdminOperationsHandler godoc
+// @Security BearerAuth
+// @ID admin_get_operations
+// @Router /api/v1/admin/operations [GET]
+// @Summary Get Pending Operations
+// @Description Get the staff operations proposed for approval, newest first
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param type query string false "manual_adjustment, account_unfreeze or transaction_reversal"
+// @Param status query string false "pending, approved, rejected or expired"
+// @Param offset query integer false "offset"
+// @Param limit query integer false "limit"
+// @Success 200 {object} http.Response{data=models.GetPendingOperationsResponse} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) AdminOperationsHandler(c *gin.Context) {
+	offset, err := h.getOffsetParam(c)
+	if err != nil {
+		h.handleResponse(c, http.InvalidArgument, err.Error())
+		return
+	}
+
+	limit, err := h.getLimitParam(c)
+	if err != nil {
+		h.handleResponse(c, http.InvalidArgument, err.Error())
+		return
+	}
+
+	resp, err := h.services.AdminService().GetPendingOperations(c.Request.Context(), &models.GetPendingOperationsRequest{
+		Type:   c.Query("type"),
+		Status: c.Query("status"),
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		h.handleAdminError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// AdminGetOperationHandler godoc
+// @Security BearerAuth
+// @ID admin_get_operation
+// @Router /api/v1/admin/operations/{id} [GET]
+// @Summary Get Pending Operation
+// @Description Get a staff operation proposed for approval
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Operation ID"
+// @Success 200 {object} http.Response{data=models.PendingOperation} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) AdminGetOperationHandler(c *gin.Context) {
+	operationID := c.Param("id")
+	if !util.IsValidUUID(operationID) {
+		h.handleResponse(c, http.BadRequest, "Invalid operation ID")
+		return
+	}
+
+	resp, err := h.services.AdminService().GetPendingOperation(c.Request.Context(), operationID)
+	if err != nil {
+		h.handleAdminError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// AdminApproveOperationHandler godoc
+// @Security BearerAuth
+// @ID admin_approve_operation
+// @Router /api/v1/admin/operations/{id}/approve [POST]
+// @Summary Approve Pending Operation
+// @Description Approve and execute an operation proposed by another staff member
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Operation ID"
+// @Param body body models.DecideOperationRequest false "Comment"
+// @Success 200 {object} http.Response{data=models.DecideOperationResponse} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) AdminApproveOperationHandler(c *gin.Context) {
+	req, ok := h.getDecideOperationRequest(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.services.AdminService().ApproveOperation(c.Request.Context(), req)
+	if err != nil {
+		h.handleAdminError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// AdminRejectOperationHandler godoc
+// @Security BearerAuth
+// @ID admin_reject_operation
+// @Router /api/v1/admin/operations/{id}/reject [POST]
+// @Summary Reject Pending Operation
+// @Description Reject an operation waiting for approval, the staff member who proposed it may withdraw it the same way
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Operation ID"
+// @Param body body models.DecideOperationRequest false "Comment"
+// @Success 200 {object} http.Response{data=models.DecideOperationResponse} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) AdminRejectOperationHandler(c *gin.Context) {
+	req, ok := h.getDecideOperationRequest(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.services.AdminService().RejectOperation(c.Request.Context(), req)
+	if err != nil {
+		h.handleAdminError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+func (h *Handler) getDecideOperationRequest(c *gin.Context) (*models.DecideOperationRequest, bool) {
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return nil, false
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	operationID := c.Param("id")
+	if !util.IsValidUUID(operationID) {
+		h.handleResponse(c, http.BadRequest, "Invalid operation ID")
+		return nil, false
+	}
+
+	var req models.DecideOperationRequest
+	// the body is optional, a decision needs no comment
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			h.handleResponse(c, http.BadRequest, err.Error())
+			return nil, false
+		}
+	}
+	req.ID = operationID
+	req.ActorID = auth.UserId
+	req.ActorRole = auth.Role
+	req.IP = c.ClientIP()
+
+	return &req, true
+}
+
+func (h *Handler) handleAdminError(c *gin.Context, err error) {
+	switch err.(type) {
+	case *customerrors.InternalServerError:
+		h.handleResponse(c, http.InternalServerError, err.Error())
+	default:
+		h.handleResponse(c, http.BadRequest, err.Error())
+	}
+}
@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/dilmurodov/online_banking/api/http"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/dilmurodov/online_banking/pkg/util"
+	"github.com/gin-gonic/gin"
+)
+
+// AdminAMLCasesHandler godoc
+// @Security BearerAuth
+// @ID admin_get_aml_cases
+// @Router /api/v1/admin/aml/cases [GET]
+// @Summary Get AML Cases
+// @Description Get the suspicious activity cases opened by the monitoring, newest first
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param status query string false "open, investigating, reported or closed"
+// @Param type query string false "cash_threshold, structuring or rapid_movement"
+// @Param account_id query string false "Account ID"
+// @Param offset query integer false "offset"
+// @Param limit query integer false "limit"
+// @Success 200 {object} http.Response{data=models.GetAMLCasesResponse} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) AdminAMLCasesHandler(c *gin.Context) {
+	offset, err := h.getOffsetParam(c)
+	if err != nil {
+		h.handleResponse(c, http.InvalidArgument, err.Error())
+		return
+	}
+
+	limit, err := h.getLimitParam(c)
+	if err != nil {
+		h.handleResponse(c, http.InvalidArgument, err.Error())
+		return
+	}
+
+	req := &models.GetAMLCasesRequest{
+		Status:    c.Query("status"),
+		Type:      c.Query("type"),
+		AccountID: c.Query("account_id"),
+		Limit:     limit,
+		Offset:    offset,
+	}
+	if req.AccountID != "" && !util.IsValidUUID(req.AccountID) {
+		h.handleResponse(c, http.BadRequest, "Invalid account ID")
+		return
+	}
+
+	resp, err := h.services.AMLService().GetCases(c.Request.Context(), req)
+	if err != nil {
+		h.handleAdminError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// AdminGetAMLCaseHandler godoc
+// @Security BearerAuth
+// @ID admin_get_aml_case
+// @Router /api/v1/admin/aml/cases/{id} [GET]
+// @Summary Get AML Case
+// @Description Get a suspicious activity case
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Case ID"
+// @Success 200 {object} http.Response{data=models.AMLCase} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) AdminGetAMLCaseHandler(c *gin.Context) {
+	caseID := c.Param("id")
+	if !util.IsValidUUID(caseID) {
+		h.handleResponse(c, http.BadRequest, "Invalid case ID")
+		return
+	}
+
+	resp, err := h.services.AMLService().GetCase(c.Request.Context(), caseID)
+	if err != nil {
+		h.handleAdminError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// AdminUpdateAMLCaseStatusHandler godoc
+// @Security BearerAuth
+// @ID admin_update_aml_case_status
+// @Router /api/v1/admin/aml/cases/{id}/status [POST]
+// @Summary Update AML Case Status
+// @Description Move a case on: open to investigating or closed, investigating to reported or closed, reported to closed
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Case ID"
+// @Param body body models.UpdateAMLCaseStatusRequest true "Status"
+// @Success 200 {object} http.Response{data=models.AMLCase} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) AdminUpdateAMLCaseStatusHandler(c *gin.Context) {
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	caseID := c.Param("id")
+	if !util.IsValidUUID(caseID) {
+		h.handleResponse(c, http.BadRequest, "Invalid case ID")
+		return
+	}
+
+	var req models.UpdateAMLCaseStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	}
+	req.ID = caseID
+	req.ActorID = auth.UserId
+	req.IP = c.ClientIP()
+
+	resp, err := h.services.AMLService().UpdateCaseStatus(c.Request.Context(), &req)
+	if err != nil {
+		h.handleAdminError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// AdminAMLCaseReportHandler godoc
+// @Security BearerAuth
+// @ID admin_aml_case_report
+// @Router /api/v1/admin/aml/cases/{id}/report [GET]
+// @Summary Export AML Case Report
+// @Description Export the case with its subject, linked transactions and history as JSON, or the transactions as CSV
+// @Tags Admin
+// @Accept json
+// @Produce json,text/csv
+// @Param id path string true "Case ID"
+// @Param format query string false "json or csv"
+// @Success 200 {object} http.Response{data=models.AMLCaseReport} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) AdminAMLCaseReportHandler(c *gin.Context) {
+	caseID := c.Param("id")
+	if !util.IsValidUUID(caseID) {
+		h.handleResponse(c, http.BadRequest, "Invalid case ID")
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		h.handleResponse(c, http.BadRequest, "format must be json or csv")
+		return
+	}
+
+	report, err := h.services.AMLService().GetCaseReport(c.Request.Context(), caseID)
+	if err != nil {
+		h.handleAdminError(c, err)
+		return
+	}
+
+	if format == "json" {
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=aml-case-%s.json", caseID))
+		h.handleResponse(c, http.OK, report)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=aml-case-%s.csv", caseID))
+	c.Header("Content-Type", "text/csv")
+
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write([]string{"case_id", "case_type", "case_status", "report_reference", "user_id", "subject",
+		"transaction_id", "created_at", "account_id", "type", "amount", "counterparty", "description", "reference"})
+	subject := report.Subject.FirstName + " " + report.Subject.LastName
+	for _, t := range report.Transactions {
+		_ = w.Write([]string{
+			report.Case.ID,
+			report.Case.Type,
+			report.Case.Status,
+			report.Case.ReportReference,
+			report.Case.UserID,
+			subject,
+			t.ID,
+			t.CreatedAt.UTC().Format(time.RFC3339),
+			t.AccountID,
+			t.Type,
+			strconv.FormatFloat(t.Amount, 'f', 2, 64),
+			t.RecipientID,
+			t.Description,
+			t.Reference,
+		})
+	}
+	w.Flush()
+}
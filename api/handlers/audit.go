@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"context"
+	"encoding/hex"
+	"regexp"
+	"strings"
+
+	"github.com/dilmurodov/online_banking/api/http"
+	"github.com/dilmurodov/online_banking/pkg/auditlog"
+	"github.com/dilmurodov/online_banking/pkg/logger"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/dilmurodov/online_banking/pkg/security"
+	"github.com/gin-gonic/gin"
+)
+
+// requestIDPattern is what a request id sent by the client has to look like to be kept
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestIDMiddleware gives every request an id, taken from the X-Request-ID header when the
+// client sends a sane one. It is put on the request context for the audit log with the client's
+// address and sent back.
+func (h *Handler) RequestIDMiddleware(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+	if !requestIDPattern.MatchString(requestID) {
+		b, err := security.GenerateRandomBytes(16)
+		if err != nil {
+			h.handleResponse(c, http.InternalServerError, err.Error())
+			c.Abort()
+			return
+		}
+		requestID = hex.EncodeToString(b)
+	}
+
+	c.Set("request_id", requestID)
+	ctx := auditlog.WithRequestID(c.Request.Context(), requestID)
+	c.Request = c.Request.WithContext(auditlog.WithClientIP(ctx, c.ClientIP()))
+	c.Header("X-Request-ID", requestID)
+
+	c.Next()
+}
+
+// AuditMiddleware writes every state-changing request to the audit log once it is handled,
+// successful or not. Request bodies are never written, they hold passwords and codes.
+func (h *Handler) AuditMiddleware(c *gin.Context) {
+	c.Next()
+
+	switch c.Request.Method {
+	case "POST", "PUT", "PATCH", "DELETE":
+	default:
+		return
+	}
+	route := c.FullPath()
+	if route == "" {
+		return
+	}
+
+	event := &models.AuditEvent{
+		EventType: models.AuditEventRequest,
+		IP:        c.ClientIP(),
+		RequestID: c.GetString("request_id"),
+		Details: map[string]interface{}{
+			"action": c.Request.Method + " " + route,
+			"status": c.Writer.Status(),
+		},
+	}
+	if authObj, ok := c.Get("auth"); ok {
+		event.ActorID = authObj.(*models.HasAccessModel).UserId
+	}
+	event.ResourceType, event.ResourceID = routeResource(route, c.Params)
+
+	// the request context is done once the client goes away, the entry is written regardless
+	if err := h.services.AuditService().Record(context.Background(), event); err != nil {
+		h.log.Error("---AuditMiddleware--->", logger.Error(err))
+	}
+}
+
+// routeResource names what a route acts on, the segment before its last parameter and that
+// parameter's value, or the last segment for a route without parameters
+func routeResource(route string, params gin.Params) (string, string) {
+	segments := strings.Split(strings.Trim(route, "/"), "/")
+	for i := len(segments) - 1; i > 0; i-- {
+		if strings.HasPrefix(segments[i], ":") {
+			return segments[i-1], params.ByName(segments[i][1:])
+		}
+	}
+	return segments[len(segments)-1], ""
+}
+
+// AdminAuditEventsHandler godoc
+// @Security BearerAuth
+// @ID admin_get_audit_events
+// @Router /api/v1/admin/audit-events [GET]
+// @Summary Get Audit Events
+// @Description Search the audit log, newest first
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param event_type query string false "event type"
+// @Param actor_id query string false "who made the change"
+// @Param user_id query string false "the user the event is about"
+// @Param resource_type query string false "resource type"
+// @Param resource_id query string false "resource id"
+// @Param request_id query string false "request id"
+// @Param from query string false "RFC3339 time, inclusive"
+// @Param to query string false "RFC3339 time, exclusive"
+// @Param offset query integer false "offset"
+// @Param limit query integer false "limit"
+// @Success 200 {object} http.Response{data=models.GetAuditEventsResponse} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) AdminAuditEventsHandler(c *gin.Context) {
+	offset, err := h.getOffsetParam(c)
+	if err != nil {
+		h.handleResponse(c, http.InvalidArgument, err.Error())
+		return
+	}
+
+	limit, err := h.getLimitParam(c)
+	if err != nil {
+		h.handleResponse(c, http.InvalidArgument, err.Error())
+		return
+	}
+
+	resp, err := h.services.AuditService().GetAuditEvents(c.Request.Context(), &models.GetAuditEventsRequest{
+		EventType:    c.Query("event_type"),
+		ActorID:      c.Query("actor_id"),
+		UserID:       c.Query("user_id"),
+		ResourceType: c.Query("resource_type"),
+		ResourceID:   c.Query("resource_id"),
+		RequestID:    c.Query("request_id"),
+		From:         c.Query("from"),
+		To:           c.Query("to"),
+		Limit:        limit,
+		Offset:       offset,
+	})
+	if err != nil {
+		h.handleAdminError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
@@ -1,12 +1,15 @@
 package handlers
 
 import (
+	"math"
+	"strconv"
+	"time"
+
 	"github.com/dilmurodov/online_banking/api/http"
 	"github.com/dilmurodov/online_banking/config"
 	"github.com/dilmurodov/online_banking/pkg/customerrors"
 	"github.com/dilmurodov/online_banking/pkg/jwt"
 	"github.com/dilmurodov/online_banking/pkg/models"
-	"github.com/dilmurodov/online_banking/pkg/security"
 	"github.com/dilmurodov/online_banking/pkg/util"
 	"github.com/gin-gonic/gin"
 )
@@ -22,6 +25,7 @@ import (
 // @Param user body models.RegisterUserRequest true "Request body"
 // @Success 201 {object} http.Response{data=models.UserWithAuth} "Created"
 // @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Response 409 {object} http.Response{data=string} "Phone already registered"
 // @Failure 500 {object} http.Response{data=string} "Server Error"
 func (h *Handler) RegisterHandler(c *gin.Context) {
 	var user *models.RegisterUserRequest
@@ -31,27 +35,24 @@ func (h *Handler) RegisterHandler(c *gin.Context) {
 		h.handleResponse(c, http.BadRequest, err.Error())
 		return
 	}
-	if len(user.Password) < 6 {
-		h.handleResponse(c, http.BadRequest, "password must be at least 6 characters")
-		return
-	}
-
 	if !util.IsValidPhone(user.Phone) {
 		h.handleResponse(c, http.BadRequest, "invalid phone number")
 		return
 	}
 
 	_, err = h.services.UserService().GetUserPasswordByPhone(c.Request.Context(), user.Phone)
-	if err != nil {
-		if e, ok := err.(*customerrors.UserNotFoundWithPhoneError); !ok {
-			h.handleResponse(c, http.InternalServerError, e.Error())
-			return
-		}
+	if err == nil {
+		h.handleResponse(c, http.Conflict, config.USER_ALREADY_EXISTS)
+		return
+	}
+	if _, ok := err.(*customerrors.UserNotFoundWithPhoneError); !ok {
+		h.handleResponse(c, http.InternalServerError, err.Error())
+		return
 	}
 
-	hashedPassword, err := security.HashPassword(user.Password)
+	hashedPassword, err := h.services.UserService().HashNewPassword(user.Phone, user.Password)
 	if err != nil {
-		h.handleResponse(c, http.InternalServerError, err.Error())
+		h.handleUserError(c, err)
 		return
 	}
 
@@ -66,6 +67,10 @@ func (h *Handler) RegisterHandler(c *gin.Context) {
 			},
 		},
 	)
+	if err != nil && err.Error() == config.USER_ALREADY_EXISTS {
+		h.handleResponse(c, http.Conflict, err.Error())
+		return
+	}
 	if err != nil {
 		h.handleResponse(c, http.InternalServerError, err.Error())
 		return
@@ -74,6 +79,7 @@ func (h *Handler) RegisterHandler(c *gin.Context) {
 	m := map[interface{}]interface{}{
 		"user_id": resp.Guid,
 		"phone":   resp.Phone,
+		"role":    models.RoleCustomer,
 	}
 
 	accessToken, refreshTokenk, err := jwt.GenJWT(m, []byte(config.SigningKey))
@@ -98,13 +104,16 @@ func (h *Handler) RegisterHandler(c *gin.Context) {
 // @ID login_user
 // @Router /api/v1/auth/login [POST]
 // @Summary Login User
-// @Description Login User
+// @Description Login User. A user with 2FA gets an MFA token instead, it is exchanged for the tokens at /auth/login/mfa
 // @Tags User
 // @Accept json
 // @Produce json
 // @Param user body models.LoginUserRequest true "Request body"
 // @Success 201 {object} http.Response{data=models.UserWithAuth} "Created"
+// @Success 200 {object} http.Response{data=models.MFAChallenge} "MFA Required"
 // @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Response 401 {object} http.Response{data=string} "Wrong phone or password"
+// @Response 429 {object} http.Response{data=string} "Too many failed attempts, see the Retry-After header"
 // @Failure 500 {object} http.Response{data=string} "Server Error"
 func (h *Handler) LoginHandler(c *gin.Context) {
 
@@ -120,17 +129,37 @@ func (h *Handler) LoginHandler(c *gin.Context) {
 		&models.GetByCredentialsRequest{
 			Phone:    req.Phone,
 			Password: req.Password,
+			IP:       c.ClientIP(),
 		},
 	)
 
 	if err != nil {
-		h.handleResponse(c, http.InternalServerError, err.Error())
+		h.handleLoginError(c, err)
+		return
+	}
+
+	// the password is right, but the tokens wait for the second factor
+	if resp.TwoFactorEnabled {
+		token, err := jwt.GenToken(map[interface{}]interface{}{
+			"mfa_user_id": resp.Guid,
+		}, []byte(config.SigningKey), config.MFATokenTTL)
+		if err != nil {
+			h.handleResponse(c, http.InternalServerError, err.Error())
+			return
+		}
+
+		h.handleResponse(c, http.OK, &models.MFAChallenge{
+			MFARequired: true,
+			MFAToken:    token,
+			ExpiresAt:   time.Now().Add(config.MFATokenTTL).Format(time.RFC3339),
+		})
 		return
 	}
 
 	m := map[interface{}]interface{}{
 		"user_id": resp.Guid,
 		"phone":   resp.Phone,
+		"role":    resp.Role,
 	}
 	accessToken, refreshTokenk, err := jwt.GenJWT(m, []byte(config.SigningKey))
 	if err != nil {
@@ -149,3 +178,80 @@ func (h *Handler) LoginHandler(c *gin.Context) {
 		RefreshToken: refreshTokenk,
 	})
 }
+
+// ForgotPasswordHandler godoc
+// @ID forgot_password
+// @Router /api/v1/auth/password/forgot [POST]
+// @Summary Forgot Password
+// @Description Text a password reset code to the phone. The answer is the same whether the phone is registered or not
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param body body models.ForgotPasswordRequest true "Phone"
+// @Success 200 {object} http.Response{data=string} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) ForgotPasswordHandler(c *gin.Context) {
+
+	var req models.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	}
+
+	// Limit the codes a phone can have sent, every one is a paid text message
+	if !h.otpSendLimiter.Allow("reset:" + req.Phone) {
+		h.handleResponse(c, http.TooManyRequests, "too many codes requested, try again later")
+		return
+	}
+
+	if err := h.services.UserService().ForgotPassword(c.Request.Context(), &req); err != nil {
+		h.handleUserError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, "if the phone is registered, a code has been sent to it")
+}
+
+// ResetPasswordHandler godoc
+// @ID reset_password
+// @Router /api/v1/auth/password/reset [POST]
+// @Summary Reset Password
+// @Description Set a new password with the code sent to the phone. Every session started before the reset is signed out
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param body body models.ResetPasswordRequest true "Phone, code and new password"
+// @Success 200 {object} http.Response{data=string} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) ResetPasswordHandler(c *gin.Context) {
+
+	var req models.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	}
+
+	if err := h.services.UserService().ResetPassword(c.Request.Context(), &req); err != nil {
+		h.handleUserError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, "password changed")
+}
+
+// handleLoginError answers an unknown phone and a wrong password the same way
+func (h *Handler) handleLoginError(c *gin.Context, err error) {
+	switch e := err.(type) {
+	case *customerrors.InvalidCredentialsError:
+		h.handleResponse(c, http.Unauthorized, err.Error())
+	case *customerrors.LoginLockedError:
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(e.RetryAfter.Seconds()))))
+		h.handleResponse(c, http.TooManyRequests, err.Error())
+	case *customerrors.InternalServerError:
+		h.handleResponse(c, http.InternalServerError, err.Error())
+	default:
+		h.handleResponse(c, http.BadRequest, err.Error())
+	}
+}
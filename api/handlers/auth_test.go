@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	nethttp "net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/internal/service"
+	"github.com/dilmurodov/online_banking/internal/service/user"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// registerServices answers the user calls of the registration, any other call panics
+type registerServices struct {
+	service.ServiceManagerI
+	user.ServiceI
+
+	lookupErr error
+	createErr error
+}
+
+func (s *registerServices) UserService() user.ServiceI {
+	return s
+}
+
+func (s *registerServices) GetUserPasswordByPhone(ctx context.Context, phone string) (*models.User, error) {
+	if s.lookupErr != nil {
+		return nil, s.lookupErr
+	}
+	return &models.User{Guid: "TestUserID", Phone: phone}, nil
+}
+
+func (s *registerServices) HashNewPassword(phone, password string) (string, error) {
+	return "TestHash", nil
+}
+
+func (s *registerServices) CreateUser(ctx context.Context, req *models.CreateUserRequest) (*models.User, error) {
+	if s.createErr != nil {
+		return nil, s.createErr
+	}
+	return &models.User{Guid: "TestUserID", Phone: req.User.Phone}, nil
+}
+
+func TestHandler_Register(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	notFound := &customerrors.UserNotFoundWithPhoneError{Phone: "+998901234567"}
+
+	register := func(svcs *registerServices) int {
+		h := NewHandler(config.Config{}, zap.NewNop(), svcs)
+		router := gin.New()
+		router.POST("/auth/register", h.RegisterHandler)
+
+		w := httptest.NewRecorder()
+		body := `{"first_name":"John","last_name":"Doe","phone":"+998901234567","password":"Secret123!"}`
+		router.ServeHTTP(w, httptest.NewRequest(nethttp.MethodPost, "/auth/register", strings.NewReader(body)))
+		return w.Code
+	}
+
+	t.Run("PHONE_TAKEN", func(t *testing.T) {
+		require.Equal(t, nethttp.StatusConflict, register(&registerServices{}))
+	})
+
+	t.Run("PHONE_TAKEN_MEANWHILE", func(t *testing.T) {
+		require.Equal(t, nethttp.StatusConflict, register(&registerServices{lookupErr: notFound, createErr: errors.New(config.USER_ALREADY_EXISTS)}))
+	})
+
+	t.Run("LOOKUP_FAILED", func(t *testing.T) {
+		require.Equal(t, nethttp.StatusInternalServerError, register(&registerServices{lookupErr: errors.New("connection refused")}))
+	})
+
+	t.Run("SUCCESS", func(t *testing.T) {
+		require.Equal(t, nethttp.StatusCreated, register(&registerServices{lookupErr: notFound}))
+	})
+}
@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"github.com/dilmurodov/online_banking/api/http"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/dilmurodov/online_banking/pkg/util"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateBeneficiary godoc
+// @Security BearerAuth
+// @ID create_beneficiary
+// @Router /api/v1/user/beneficiaries [POST]
+// @Summary Create Beneficiary
+// @Description Save a transfer destination to the address book
+// @Tags Beneficiary
+// @Accept json
+// @Produce json
+// @Param body body models.CreateBeneficiaryRequest true "Beneficiary"
+// @Param X-Step-Up-Token header string false "Step-up token, required once 2FA is on"
+// @Success 201 {object} http.Response{data=models.Beneficiary} "Created"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) BeneficiaryCreateHandler(c *gin.Context) {
+
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	var req models.CreateBeneficiaryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	}
+	req.UserID = auth.UserId
+
+	resp, err := h.services.BeneficiaryService().CreateBeneficiary(c.Request.Context(), &req)
+	if err != nil {
+		h.handleBeneficiaryError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.Created, resp)
+}
+
+// GetBeneficiaries godoc
+// @Security BearerAuth
+// @ID get_beneficiaries
+// @Router /api/v1/user/beneficiaries [GET]
+// @Summary Get Beneficiaries
+// @Description Get Beneficiaries
+// @Tags Beneficiary
+// @Accept json
+// @Produce json
+// @Success 200 {object} http.Response{data=models.GetBeneficiariesResponse} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) BeneficiariesGetHandler(c *gin.Context) {
+
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	resp, err := h.services.BeneficiaryService().GetBeneficiaries(c.Request.Context(), &models.GetBeneficiariesRequest{
+		UserID: auth.UserId,
+	})
+	if err != nil {
+		h.handleResponse(c, http.InternalServerError, err.Error())
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// GetBeneficiary godoc
+// @Security BearerAuth
+// @ID get_beneficiary
+// @Router /api/v1/user/beneficiaries/{id} [GET]
+// @Summary Get Beneficiary
+// @Description Get Beneficiary
+// @Tags Beneficiary
+// @Accept json
+// @Produce json
+// @Param id path string true "Beneficiary ID"
+// @Success 200 {object} http.Response{data=models.Beneficiary} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) BeneficiaryGetHandler(c *gin.Context) {
+
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	id := c.Param("id")
+	if !util.IsValidUUID(id) {
+		h.handleResponse(c, http.BadRequest, "Invalid beneficiary ID")
+		return
+	}
+
+	resp, err := h.services.BeneficiaryService().GetBeneficiaryByID(c.Request.Context(), &models.BeneficiaryByIDRequest{
+		ID:     id,
+		UserID: auth.UserId,
+	})
+	if err != nil {
+		h.handleBeneficiaryError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// UpdateBeneficiary godoc
+// @Security BearerAuth
+// @ID update_beneficiary
+// @Router /api/v1/user/beneficiaries/{id} [PUT]
+// @Summary Update Beneficiary
+// @Description Update the nickname and limits of a beneficiary
+// @Tags Beneficiary
+// @Accept json
+// @Produce json
+// @Param id path string true "Beneficiary ID"
+// @Param body body models.UpdateBeneficiaryRequest true "Beneficiary"
+// @Param X-Step-Up-Token header string false "Step-up token, required once 2FA is on"
+// @Success 200 {object} http.Response{data=models.Beneficiary} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) BeneficiaryUpdateHandler(c *gin.Context) {
+
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	id := c.Param("id")
+	if !util.IsValidUUID(id) {
+		h.handleResponse(c, http.BadRequest, "Invalid beneficiary ID")
+		return
+	}
+
+	var req models.UpdateBeneficiaryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	}
+	req.ID = id
+	req.UserID = auth.UserId
+
+	resp, err := h.services.BeneficiaryService().UpdateBeneficiary(c.Request.Context(), &req)
+	if err != nil {
+		h.handleBeneficiaryError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// DeleteBeneficiary godoc
+// @Security BearerAuth
+// @ID delete_beneficiary
+// @Router /api/v1/user/beneficiaries/{id} [DELETE]
+// @Summary Delete Beneficiary
+// @Description Delete Beneficiary
+// @Tags Beneficiary
+// @Accept json
+// @Produce json
+// @Param id path string true "Beneficiary ID"
+// @Success 200 {object} http.Response{data=string} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) BeneficiaryDeleteHandler(c *gin.Context) {
+
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	id := c.Param("id")
+	if !util.IsValidUUID(id) {
+		h.handleResponse(c, http.BadRequest, "Invalid beneficiary ID")
+		return
+	}
+
+	err := h.services.BeneficiaryService().DeleteBeneficiary(c.Request.Context(), &models.BeneficiaryByIDRequest{
+		ID:     id,
+		UserID: auth.UserId,
+	})
+	if err != nil {
+		h.handleBeneficiaryError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, "OK")
+}
+
+func (h *Handler) handleBeneficiaryError(c *gin.Context, err error) {
+	switch err.(type) {
+	case *customerrors.InternalServerError:
+		h.handleResponse(c, http.InternalServerError, err.Error())
+	default:
+		h.handleResponse(c, http.BadRequest, err.Error())
+	}
+}
@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"github.com/dilmurodov/online_banking/api/http"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/dilmurodov/online_banking/pkg/util"
+	"github.com/gin-gonic/gin"
+)
+
+// GetDepositTerms godoc
+// @Security BearerAuth
+// @ID get_deposit_terms
+// @Router /api/v1/user/deposits/terms [GET]
+// @Summary Get Deposit Terms
+// @Description Get the offered deposit terms with their rates and minimum amounts
+// @Tags Deposit
+// @Accept json
+// @Produce json
+// @Success 200 {object} http.Response{data=models.GetDepositTermsResponse} "OK"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) DepositTermsGetHandler(c *gin.Context) {
+
+	resp, err := h.services.DepositService().GetDepositTerms(c.Request.Context())
+	if err != nil {
+		h.handleResponse(c, http.InternalServerError, err.Error())
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// OpenDeposit godoc
+// @Security BearerAuth
+// @ID open_deposit
+// @Router /api/v1/user/deposits [POST]
+// @Summary Open Deposit
+// @Description Open a term deposit funded from a current account
+// @Tags Deposit
+// @Accept json
+// @Produce json
+// @Param body body models.OpenDepositRequest true "Deposit"
+// @Success 201 {object} http.Response{data=models.TermDeposit} "Created"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) DepositOpenHandler(c *gin.Context) {
+
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	var req models.OpenDepositRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	}
+	req.UserID = auth.UserId
+
+	if !util.IsValidUUID(req.SourceAccountID) {
+		h.handleResponse(c, http.BadRequest, "Invalid account ID")
+		return
+	}
+
+	resp, err := h.services.DepositService().OpenDeposit(c.Request.Context(), &req)
+	if err != nil {
+		h.handleDepositError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.Created, resp)
+}
+
+// GetDeposits godoc
+// @Security BearerAuth
+// @ID get_deposits
+// @Router /api/v1/user/deposits [GET]
+// @Summary Get Deposits
+// @Description Get the user's term deposits
+// @Tags Deposit
+// @Accept json
+// @Produce json
+// @Param status query string false "active, matured, rolled_over or withdrawn"
+// @Param offset query integer false "offset"
+// @Param limit query integer false "limit"
+// @Success 200 {object} http.Response{data=models.GetDepositsResponse} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) DepositsGetHandler(c *gin.Context) {
+
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	offset, err := h.getOffsetParam(c)
+	if err != nil {
+		h.handleResponse(c, http.InvalidArgument, err.Error())
+		return
+	}
+
+	limit, err := h.getLimitParam(c)
+	if err != nil {
+		h.handleResponse(c, http.InvalidArgument, err.Error())
+		return
+	}
+
+	resp, err := h.services.DepositService().GetDeposits(c.Request.Context(), &models.GetDepositsRequest{
+		UserID: auth.UserId,
+		Status: c.Query("status"),
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		h.handleResponse(c, http.InternalServerError, err.Error())
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// GetDeposit godoc
+// @Security BearerAuth
+// @ID get_deposit
+// @Router /api/v1/user/deposits/{id} [GET]
+// @Summary Get Deposit
+// @Description Get Deposit
+// @Tags Deposit
+// @Accept json
+// @Produce json
+// @Param id path string true "Deposit ID"
+// @Success 200 {object} http.Response{data=models.TermDeposit} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) DepositGetHandler(c *gin.Context) {
+
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	id := c.Param("id")
+	if !util.IsValidUUID(id) {
+		h.handleResponse(c, http.BadRequest, "Invalid deposit ID")
+		return
+	}
+
+	resp, err := h.services.DepositService().GetDepositByID(c.Request.Context(), &models.DepositByIDRequest{
+		ID:     id,
+		UserID: auth.UserId,
+	})
+	if err != nil {
+		h.handleDepositError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// UpdateDepositInstruction godoc
+// @Security BearerAuth
+// @ID update_deposit_instruction
+// @Router /api/v1/user/deposits/{id}/instruction [PUT]
+// @Summary Update Deposit Instruction
+// @Description Choose whether an active deposit is paid out or rolled over at maturity
+// @Tags Deposit
+// @Accept json
+// @Produce json
+// @Param id path string true "Deposit ID"
+// @Param body body models.UpdateDepositInstructionRequest true "Instruction"
+// @Success 200 {object} http.Response{data=models.TermDeposit} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) DepositInstructionUpdateHandler(c *gin.Context) {
+
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	id := c.Param("id")
+	if !util.IsValidUUID(id) {
+		h.handleResponse(c, http.BadRequest, "Invalid deposit ID")
+		return
+	}
+
+	var req models.UpdateDepositInstructionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	}
+	req.ID = id
+	req.UserID = auth.UserId
+
+	resp, err := h.services.DepositService().UpdateDepositInstruction(c.Request.Context(), &req)
+	if err != nil {
+		h.handleDepositError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// WithdrawDeposit godoc
+// @Security BearerAuth
+// @ID withdraw_deposit
+// @Router /api/v1/user/deposits/{id}/withdraw [POST]
+// @Summary Withdraw Deposit
+// @Description Close a deposit before maturity. Interest is recalculated at the early withdrawal rate
+// @Tags Deposit
+// @Accept json
+// @Produce json
+// @Param id path string true "Deposit ID"
+// @Success 200 {object} http.Response{data=models.WithdrawDepositResponse} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) DepositWithdrawHandler(c *gin.Context) {
+
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	id := c.Param("id")
+	if !util.IsValidUUID(id) {
+		h.handleResponse(c, http.BadRequest, "Invalid deposit ID")
+		return
+	}
+
+	resp, err := h.services.DepositService().WithdrawDeposit(c.Request.Context(), &models.DepositByIDRequest{
+		ID:     id,
+		UserID: auth.UserId,
+	})
+	if err != nil {
+		h.handleDepositError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+func (h *Handler) handleDepositError(c *gin.Context, err error) {
+	switch err.(type) {
+	case *customerrors.InternalServerError:
+		h.handleResponse(c, http.InternalServerError, err.Error())
+	default:
+		h.handleResponse(c, http.BadRequest, err.Error())
+	}
+}
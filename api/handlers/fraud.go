@@ -0,0 +1,259 @@
+package handlers
+
+import (
+	"github.com/dilmurodov/online_banking/api/http"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/dilmurodov/online_banking/pkg/util"
+	"github.com/gin-gonic/gin"
+)
+
+// handlePaymentScreeningError answers a payment stopped by the fraud or the sanctions checks and reports
+// whether it did. A challenged payment gets a code sent to the user and the decision to confirm it with.
+func (h *Handler) handlePaymentScreeningError(c *gin.Context, userID string, err error) bool {
+	switch e := err.(type) {
+	case *customerrors.FraudChallengeRequiredError:
+		// Limit the codes a user can have sent, every one is a paid text message
+		if !h.otpSendLimiter.Allow(userID) {
+			h.handleResponse(c, http.TooManyRequests, "too many codes requested, try again later")
+			return true
+		}
+		challenge, err := h.services.UserService().SendPaymentChallenge(c.Request.Context(), userID, e.DecisionID)
+		if err != nil {
+			h.handleUserError(c, err)
+			return true
+		}
+		h.handleResponse(c, http.Forbidden, challenge)
+		return true
+	case *customerrors.FraudBlockedError:
+		h.handleResponse(c, http.Forbidden, err.Error())
+		return true
+	case *customerrors.FraudChallengeInvalidError:
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return true
+	case *customerrors.SanctionsBlockedError:
+		h.handleResponse(c, http.Forbidden, err.Error())
+		return true
+	}
+	return false
+}
+
+// ConfirmPaymentChallengeHandler godoc
+// @Security BearerAuth
+// @ID confirm_payment_challenge
+// @Router /api/v1/payments/challenges/{id}/confirm [POST]
+// @Summary Confirm Payment Challenge
+// @Description Confirm a payment the fraud checks challenged with the code sent by SMS, then repeat the payment with the challenge_id
+// @Tags Payment
+// @Accept json
+// @Produce json
+// @Param id path string true "Decision ID"
+// @Param body body models.ConfirmFraudChallengeRequest true "Code"
+// @Success 200 {object} http.Response{data=string} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) ConfirmPaymentChallengeHandler(c *gin.Context) {
+
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	decisionID := c.Param("id")
+	if !util.IsValidUUID(decisionID) {
+		h.handleResponse(c, http.BadRequest, "Invalid challenge ID")
+		return
+	}
+
+	var req models.ConfirmFraudChallengeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	}
+	req.DecisionID = decisionID
+	req.UserID = auth.UserId
+
+	if err := h.services.UserService().ConfirmPaymentChallenge(c.Request.Context(), &req); err != nil {
+		h.handleUserError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, "confirmed")
+}
+
+// AdminFraudDecisionsHandler godoc
+// @Security BearerAuth
+// @ID admin_get_fraud_decisions
+// @Router /api/v1/admin/fraud/decisions [GET]
+// @Summary Get Fraud Decisions
+// @Description Get the payments the fraud checks scored, newest first
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param user_id query string false "User ID"
+// @Param account_id query string false "Account ID"
+// @Param outcome query string false "allow, challenge or block"
+// @Param review_status query string false "pending, legitimate or fraud"
+// @Param offset query integer false "offset"
+// @Param limit query integer false "limit"
+// @Success 200 {object} http.Response{data=models.GetFraudDecisionsResponse} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) AdminFraudDecisionsHandler(c *gin.Context) {
+	offset, err := h.getOffsetParam(c)
+	if err != nil {
+		h.handleResponse(c, http.InvalidArgument, err.Error())
+		return
+	}
+
+	limit, err := h.getLimitParam(c)
+	if err != nil {
+		h.handleResponse(c, http.InvalidArgument, err.Error())
+		return
+	}
+
+	req := &models.GetFraudDecisionsRequest{
+		UserID:       c.Query("user_id"),
+		AccountID:    c.Query("account_id"),
+		Outcome:      c.Query("outcome"),
+		ReviewStatus: c.Query("review_status"),
+		Limit:        limit,
+		Offset:       offset,
+	}
+	if (req.UserID != "" && !util.IsValidUUID(req.UserID)) || (req.AccountID != "" && !util.IsValidUUID(req.AccountID)) {
+		h.handleResponse(c, http.BadRequest, "Invalid ID")
+		return
+	}
+
+	resp, err := h.services.AdminService().GetFraudDecisions(c.Request.Context(), req)
+	if err != nil {
+		h.handleAdminError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// AdminGetFraudDecisionHandler godoc
+// @Security BearerAuth
+// @ID admin_get_fraud_decision
+// @Router /api/v1/admin/fraud/decisions/{id} [GET]
+// @Summary Get Fraud Decision
+// @Description Get a payment the fraud checks scored with the rules that fired
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Decision ID"
+// @Success 200 {object} http.Response{data=models.FraudDecision} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) AdminGetFraudDecisionHandler(c *gin.Context) {
+	decisionID := c.Param("id")
+	if !util.IsValidUUID(decisionID) {
+		h.handleResponse(c, http.BadRequest, "Invalid decision ID")
+		return
+	}
+
+	resp, err := h.services.AdminService().GetFraudDecision(c.Request.Context(), decisionID)
+	if err != nil {
+		h.handleAdminError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// AdminReviewFraudDecisionHandler godoc
+// @Security BearerAuth
+// @ID admin_review_fraud_decision
+// @Router /api/v1/admin/fraud/decisions/{id}/review [POST]
+// @Summary Review Fraud Decision
+// @Description Mark a scored payment as legitimate or as fraud
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Decision ID"
+// @Param body body models.ReviewFraudDecisionRequest true "Review"
+// @Success 200 {object} http.Response{data=models.FraudDecision} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) AdminReviewFraudDecisionHandler(c *gin.Context) {
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	decisionID := c.Param("id")
+	if !util.IsValidUUID(decisionID) {
+		h.handleResponse(c, http.BadRequest, "Invalid decision ID")
+		return
+	}
+
+	var req models.ReviewFraudDecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	}
+	req.ID = decisionID
+	req.ActorID = auth.UserId
+	req.IP = c.ClientIP()
+
+	resp, err := h.services.AdminService().ReviewFraudDecision(c.Request.Context(), &req)
+	if err != nil {
+		h.handleAdminError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// AdminFraudRulesHandler godoc
+// @Security BearerAuth
+// @ID admin_get_fraud_rules
+// @Router /api/v1/admin/fraud/rules [GET]
+// @Summary Get Fraud Rules
+// @Description Get the fraud rules in use
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Success 200 {object} http.Response{data=models.FraudRules} "OK"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) AdminFraudRulesHandler(c *gin.Context) {
+	h.handleResponse(c, http.OK, h.services.AdminService().GetFraudRules())
+}
+
+// AdminReloadFraudRulesHandler godoc
+// @Security BearerAuth
+// @ID admin_reload_fraud_rules
+// @Router /api/v1/admin/fraud/rules/reload [POST]
+// @Summary Reload Fraud Rules
+// @Description Read the fraud rules file again without a restart, the rules in use are kept when the file is invalid
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Success 200 {object} http.Response{data=models.FraudRules} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) AdminReloadFraudRulesHandler(c *gin.Context) {
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	resp, err := h.services.AdminService().ReloadFraudRules(c.Request.Context(), auth.UserId, c.ClientIP())
+	if err != nil {
+		h.handleAdminError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
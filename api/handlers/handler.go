@@ -8,6 +8,8 @@ import (
 	"github.com/dilmurodov/online_banking/internal/service"
 
 	"github.com/dilmurodov/online_banking/pkg/logger"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/dilmurodov/online_banking/pkg/ratelimit"
 
 	"github.com/gin-gonic/gin"
 )
@@ -16,6 +18,10 @@ type Handler struct {
 	cfg      config.Config
 	log      logger.LoggerI
 	services service.ServiceManagerI
+
+	phoneLookupLimiter *ratelimit.Limiter
+	otpSendLimiter     *ratelimit.Limiter
+	mfaVerifyLimiter   *ratelimit.Limiter
 }
 
 func NewHandler(cfg config.Config, log logger.LoggerI, svcs service.ServiceManagerI) Handler {
@@ -23,35 +29,31 @@ func NewHandler(cfg config.Config, log logger.LoggerI, svcs service.ServiceManag
 		cfg:      cfg,
 		log:      log,
 		services: svcs,
+
+		phoneLookupLimiter: ratelimit.New(cfg.PhoneLookupLimit, config.PhoneLookupWindow),
+		otpSendLimiter:     ratelimit.New(cfg.OTPSendLimit, config.OTPSendWindow),
+		mfaVerifyLimiter:   ratelimit.New(config.MFAVerifyLimit, config.MFAVerifyWindow),
 	}
 }
 
+// handleResponse logs the outcome without the data of a successful response, it holds
+// balances, personal details and tokens. The data of a failed one is the error message.
 func (h *Handler) handleResponse(c *gin.Context, status http.Status, data interface{}) {
+	fields := []logger.Field{
+		logger.Int("code", status.Code),
+		logger.String("status", status.Status),
+		logger.Any("description", status.Description),
+		logger.String("request_id", c.GetString("request_id")),
+		logger.String("route", c.Request.Method+" "+c.FullPath()),
+	}
+
 	switch code := status.Code; {
 	case code < 300:
-		h.log.Info(
-			"---Response--->",
-			logger.Int("code", status.Code),
-			logger.String("status", status.Status),
-			logger.Any("description", status.Description),
-			logger.Any("data", data),
-		)
+		h.log.Info("---Response--->", fields...)
 	case code < 400:
-		h.log.Warn(
-			"---Response--->",
-			logger.Int("code", status.Code),
-			logger.String("status", status.Status),
-			logger.Any("description", status.Description),
-			logger.Any("data", data),
-		)
+		h.log.Warn("---Response--->", fields...)
 	default:
-		h.log.Error(
-			"---Response--->",
-			logger.Int("code", status.Code),
-			logger.String("status", status.Status),
-			logger.Any("description", status.Description),
-			logger.Any("data", data),
-		)
+		h.log.Error("---Response--->", append(fields, logger.Any("data", data))...)
 	}
 
 	c.JSON(status.Code, http.Response{
@@ -70,3 +72,17 @@ func (h *Handler) getLimitParam(c *gin.Context) (offset int, err error) {
 	offsetStr := c.DefaultQuery("limit", h.cfg.DefaultLimit)
 	return strconv.Atoi(offsetStr)
 }
+
+// userHasAccountPermission checks that the user holds the given account in a role that allows the permission
+func (h *Handler) userHasAccountPermission(c *gin.Context, userID, accountID, permission string) (bool, error) {
+	accounts, err := h.services.AccountService().GetAccountsByUserID(c.Request.Context(), &models.GetAccountsByUserIDRequest{UserID: userID})
+	if err != nil {
+		return false, err
+	}
+	for _, account := range accounts.Accounts {
+		if accountID == account.ID {
+			return models.AccountRoleAllows(account.Role, permission), nil
+		}
+	}
+	return false, nil
+}
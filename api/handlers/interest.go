@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"github.com/dilmurodov/online_banking/api/http"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/dilmurodov/online_banking/pkg/util"
+	"github.com/gin-gonic/gin"
+)
+
+// GetProducts godoc
+// @Security BearerAuth
+// @ID get_products
+// @Router /api/v1/user/products [GET]
+// @Summary Get Account Products
+// @Description Get the account products with their annual interest rates and day-count conventions
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Success 200 {object} http.Response{data=models.GetProductsResponse} "OK"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) ProductsGetHandler(c *gin.Context) {
+
+	resp, err := h.services.InterestService().GetProducts(c.Request.Context())
+	if err != nil {
+		h.handleResponse(c, http.InternalServerError, err.Error())
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// GetInterestAccruals godoc
+// @Security BearerAuth
+// @ID get_interest_accruals
+// @Router /api/v1/user/accounts/{id}/interest [GET]
+// @Summary Get Interest Accruals
+// @Description Get the daily interest accrual history of an account and the interest waiting for capitalization
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param id path string true "Account ID"
+// @Param from query string false "First business date, YYYY-MM-DD"
+// @Param to query string false "Last business date, YYYY-MM-DD"
+// @Param offset query integer false "offset"
+// @Param limit query integer false "limit"
+// @Success 200 {object} http.Response{data=models.GetInterestAccrualsResponse} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) InterestAccrualsGetHandler(c *gin.Context) {
+
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	accountID := c.Param("id")
+	if !util.IsValidUUID(accountID) {
+		h.handleResponse(c, http.BadRequest, "Invalid account ID")
+		return
+	}
+
+	verified, err := h.userHasAccountPermission(c, auth.UserId, accountID, models.AccountPermissionView)
+	if err != nil {
+		h.handleResponse(c, http.InternalServerError, err.Error())
+		return
+	}
+	if !verified {
+		h.handleResponse(c, http.BadRequest, "account not found")
+		return
+	}
+
+	offset, err := h.getOffsetParam(c)
+	if err != nil {
+		h.handleResponse(c, http.InvalidArgument, err.Error())
+		return
+	}
+
+	limit, err := h.getLimitParam(c)
+	if err != nil {
+		h.handleResponse(c, http.InvalidArgument, err.Error())
+		return
+	}
+
+	resp, err := h.services.InterestService().GetInterestAccruals(c.Request.Context(), &models.GetInterestAccrualsRequest{
+		AccountID: accountID,
+		From:      c.Query("from"),
+		To:        c.Query("to"),
+		Limit:     limit,
+		Offset:    offset,
+	})
+	if err != nil {
+		if _, ok := err.(*customerrors.InternalServerError); ok {
+			h.handleResponse(c, http.InternalServerError, err.Error())
+			return
+		}
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
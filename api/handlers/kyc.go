@@ -0,0 +1,302 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"mime"
+
+	"github.com/dilmurodov/online_banking/api/http"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/dilmurodov/online_banking/pkg/util"
+	"github.com/gin-gonic/gin"
+)
+
+// KYCStatusHandler godoc
+// @Security BearerAuth
+// @ID get_kyc_status
+// @Router /api/v1/user/kyc [GET]
+// @Summary Get KYC Status
+// @Description Get the verification tier of the signed in user with its balance, deposit and withdrawal caps, the latest application and the documents uploaded since
+// @Tags User
+// @Accept json
+// @Produce json
+// @Success 200 {object} http.Response{data=models.KYCStatus} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) KYCStatusHandler(c *gin.Context) {
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	resp, err := h.services.KYCService().GetStatus(c.Request.Context(), auth.UserId)
+	if err != nil {
+		h.handleUserError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// KYCDocumentUploadHandler godoc
+// @Security BearerAuth
+// @ID upload_kyc_document
+// @Router /api/v1/user/kyc/documents [POST]
+// @Summary Upload KYC Document
+// @Description Upload a JPEG, PNG or PDF document, it waits until the next application is submitted
+// @Tags User
+// @Accept multipart/form-data
+// @Produce json
+// @Param type formData string true "passport, id_card, driving_licence, proof_of_address or selfie"
+// @Param file formData file true "Document"
+// @Success 201 {object} http.Response{data=models.KYCDocument} "Created"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) KYCDocumentUploadHandler(c *gin.Context) {
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	}
+	if header.Size > h.cfg.KYCDocumentMaxSize {
+		h.handleResponse(c, http.BadRequest, fmt.Sprintf("file must not be larger than %d bytes", h.cfg.KYCDocumentMaxSize))
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	}
+	defer file.Close()
+
+	// one byte over the limit is enough for the service to refuse it
+	content, err := io.ReadAll(io.LimitReader(file, h.cfg.KYCDocumentMaxSize+1))
+	if err != nil {
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	}
+
+	resp, err := h.services.KYCService().UploadDocument(c.Request.Context(), &models.UploadKYCDocumentRequest{
+		UserID:   auth.UserId,
+		Type:     c.PostForm("type"),
+		FileName: header.Filename,
+		Content:  content,
+	})
+	if err != nil {
+		h.handleUserError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.Created, resp)
+}
+
+// KYCApplicationSubmitHandler godoc
+// @Security BearerAuth
+// @ID submit_kyc_application
+// @Router /api/v1/user/kyc/applications [POST]
+// @Summary Submit KYC Application
+// @Description Ask to be moved to a higher tier with the documents uploaded since the last application. The basic tier needs a passport, an ID card or a driving licence, the full tier a proof of address as well.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param body body models.SubmitKYCApplicationRequest true "Tier"
+// @Success 201 {object} http.Response{data=models.KYCApplication} "Created"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) KYCApplicationSubmitHandler(c *gin.Context) {
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	var req models.SubmitKYCApplicationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	}
+	req.UserID = auth.UserId
+
+	resp, err := h.services.KYCService().SubmitApplication(c.Request.Context(), &req)
+	if err != nil {
+		h.handleUserError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.Created, resp)
+}
+
+// AdminKYCApplicationsHandler godoc
+// @Security BearerAuth
+// @ID admin_get_kyc_applications
+// @Router /api/v1/admin/kyc/applications [GET]
+// @Summary Get KYC Applications
+// @Description Get the KYC applications, oldest first. Pending applications are the review queue.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param user_id query string false "User ID"
+// @Param status query string false "pending, approved or rejected"
+// @Param tier query string false "basic or full"
+// @Param offset query integer false "offset"
+// @Param limit query integer false "limit"
+// @Success 200 {object} http.Response{data=models.GetKYCApplicationsResponse} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) AdminKYCApplicationsHandler(c *gin.Context) {
+	offset, err := h.getOffsetParam(c)
+	if err != nil {
+		h.handleResponse(c, http.InvalidArgument, err.Error())
+		return
+	}
+
+	limit, err := h.getLimitParam(c)
+	if err != nil {
+		h.handleResponse(c, http.InvalidArgument, err.Error())
+		return
+	}
+
+	req := &models.GetKYCApplicationsRequest{
+		UserID: c.Query("user_id"),
+		Status: c.Query("status"),
+		Tier:   c.Query("tier"),
+		Limit:  limit,
+		Offset: offset,
+	}
+	if req.UserID != "" && !util.IsValidUUID(req.UserID) {
+		h.handleResponse(c, http.BadRequest, "Invalid user ID")
+		return
+	}
+
+	resp, err := h.services.KYCService().GetApplications(c.Request.Context(), req)
+	if err != nil {
+		h.handleAdminError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// AdminGetKYCApplicationHandler godoc
+// @Security BearerAuth
+// @ID admin_get_kyc_application
+// @Router /api/v1/admin/kyc/applications/{id} [GET]
+// @Summary Get KYC Application
+// @Description Get a KYC application with the documents submitted with it
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Application ID"
+// @Success 200 {object} http.Response{data=models.KYCApplication} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) AdminGetKYCApplicationHandler(c *gin.Context) {
+	applicationID := c.Param("id")
+	if !util.IsValidUUID(applicationID) {
+		h.handleResponse(c, http.BadRequest, "Invalid application ID")
+		return
+	}
+
+	resp, err := h.services.KYCService().GetApplication(c.Request.Context(), applicationID)
+	if err != nil {
+		h.handleAdminError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// AdminReviewKYCApplicationHandler godoc
+// @Security BearerAuth
+// @ID admin_review_kyc_application
+// @Router /api/v1/admin/kyc/applications/{id}/review [POST]
+// @Summary Review KYC Application
+// @Description Approve a pending application, which moves the user to its tier, or reject it with the reason in the comment
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Application ID"
+// @Param body body models.ReviewKYCApplicationRequest true "Review"
+// @Success 200 {object} http.Response{data=models.KYCApplication} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) AdminReviewKYCApplicationHandler(c *gin.Context) {
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	applicationID := c.Param("id")
+	if !util.IsValidUUID(applicationID) {
+		h.handleResponse(c, http.BadRequest, "Invalid application ID")
+		return
+	}
+
+	var req models.ReviewKYCApplicationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	}
+	req.ID = applicationID
+	req.ActorID = auth.UserId
+	req.IP = c.ClientIP()
+
+	resp, err := h.services.KYCService().ReviewApplication(c.Request.Context(), &req)
+	if err != nil {
+		h.handleAdminError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// AdminKYCDocumentFileHandler godoc
+// @Security BearerAuth
+// @ID admin_get_kyc_document_file
+// @Router /api/v1/admin/kyc/documents/{id}/file [GET]
+// @Summary Download KYC Document
+// @Description Download the file of a KYC document as it was uploaded
+// @Tags Admin
+// @Accept json
+// @Produce image/jpeg,image/png,application/pdf
+// @Param id path string true "Document ID"
+// @Success 200 {file} file "Document"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) AdminKYCDocumentFileHandler(c *gin.Context) {
+	documentID := c.Param("id")
+	if !util.IsValidUUID(documentID) {
+		h.handleResponse(c, http.BadRequest, "Invalid document ID")
+		return
+	}
+
+	doc, file, err := h.services.KYCService().GetDocumentFile(c.Request.Context(), documentID)
+	if err != nil {
+		h.handleAdminError(c, err)
+		return
+	}
+	defer file.Close()
+
+	// the type was told from the content on upload, the browser must not guess another one
+	c.DataFromReader(http.OK.Code, doc.Size, doc.ContentType, file, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": doc.FileName}),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"github.com/dilmurodov/online_banking/api/http"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/dilmurodov/online_banking/pkg/util"
+	"github.com/gin-gonic/gin"
+)
+
+// GetLoanProducts godoc
+// @Security BearerAuth
+// @ID get_loan_products
+// @Router /api/v1/user/loans/products [GET]
+// @Summary Get Loan Products
+// @Description Get the offered loans with their rates, amount and term limits
+// @Tags Loan
+// @Accept json
+// @Produce json
+// @Success 200 {object} http.Response{data=models.GetLoanProductsResponse} "OK"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) LoanProductsGetHandler(c *gin.Context) {
+
+	resp, err := h.services.LoanService().GetLoanProducts(c.Request.Context())
+	if err != nil {
+		h.handleResponse(c, http.InternalServerError, err.Error())
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// QuoteLoan godoc
+// @Security BearerAuth
+// @ID quote_loan
+// @Router /api/v1/user/loans/quote [POST]
+// @Summary Quote Loan
+// @Description Get the repayment schedule of a loan without applying for it
+// @Tags Loan
+// @Accept json
+// @Produce json
+// @Param body body models.LoanQuoteRequest true "Loan"
+// @Success 200 {object} http.Response{data=models.LoanQuote} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) LoanQuoteHandler(c *gin.Context) {
+
+	var req models.LoanQuoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	}
+
+	resp, err := h.services.LoanService().QuoteLoan(c.Request.Context(), &req)
+	if err != nil {
+		h.handleLoanError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// ApplyLoan godoc
+// @Security BearerAuth
+// @ID apply_loan
+// @Router /api/v1/user/loans [POST]
+// @Summary Apply Loan
+// @Description Take a loan disbursed into a current account, the installments are debited from it on their due dates
+// @Tags Loan
+// @Accept json
+// @Produce json
+// @Param body body models.ApplyLoanRequest true "Loan"
+// @Success 201 {object} http.Response{data=models.Loan} "Created"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) LoanApplyHandler(c *gin.Context) {
+
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	var req models.ApplyLoanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	}
+	req.UserID = auth.UserId
+
+	if !util.IsValidUUID(req.AccountID) {
+		h.handleResponse(c, http.BadRequest, "Invalid account ID")
+		return
+	}
+
+	resp, err := h.services.LoanService().ApplyLoan(c.Request.Context(), &req)
+	if err != nil {
+		h.handleLoanError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.Created, resp)
+}
+
+// GetLoans godoc
+// @Security BearerAuth
+// @ID get_loans
+// @Router /api/v1/user/loans [GET]
+// @Summary Get Loans
+// @Description Get the user's loans
+// @Tags Loan
+// @Accept json
+// @Produce json
+// @Param status query string false "active, delinquent, defaulted or repaid"
+// @Param offset query integer false "offset"
+// @Param limit query integer false "limit"
+// @Success 200 {object} http.Response{data=models.GetLoansResponse} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) LoansGetHandler(c *gin.Context) {
+
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	offset, err := h.getOffsetParam(c)
+	if err != nil {
+		h.handleResponse(c, http.InvalidArgument, err.Error())
+		return
+	}
+
+	limit, err := h.getLimitParam(c)
+	if err != nil {
+		h.handleResponse(c, http.InvalidArgument, err.Error())
+		return
+	}
+
+	resp, err := h.services.LoanService().GetLoans(c.Request.Context(), &models.GetLoansRequest{
+		UserID: auth.UserId,
+		Status: c.Query("status"),
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		h.handleResponse(c, http.InternalServerError, err.Error())
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// GetLoan godoc
+// @Security BearerAuth
+// @ID get_loan
+// @Router /api/v1/user/loans/{id} [GET]
+// @Summary Get Loan
+// @Description Get the loan with its repayment schedule
+// @Tags Loan
+// @Accept json
+// @Produce json
+// @Param id path string true "Loan ID"
+// @Success 200 {object} http.Response{data=models.Loan} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) LoanGetHandler(c *gin.Context) {
+
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	id := c.Param("id")
+	if !util.IsValidUUID(id) {
+		h.handleResponse(c, http.BadRequest, "Invalid loan ID")
+		return
+	}
+
+	resp, err := h.services.LoanService().GetLoanByID(c.Request.Context(), &models.LoanByIDRequest{
+		ID:     id,
+		UserID: auth.UserId,
+	})
+	if err != nil {
+		h.handleLoanError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// EarlyRepayment godoc
+// @Security BearerAuth
+// @ID loan_early_repayment
+// @Router /api/v1/user/loans/{id}/early-repayment [POST]
+// @Summary Loan Early Repayment
+// @Description Repay a loan in full or in part ahead of the schedule. A partial repayment lowers the installments or shortens the term
+// @Tags Loan
+// @Accept json
+// @Produce json
+// @Param id path string true "Loan ID"
+// @Param body body models.EarlyRepaymentRequest true "Repayment"
+// @Success 200 {object} http.Response{data=models.EarlyRepaymentResponse} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) LoanEarlyRepaymentHandler(c *gin.Context) {
+
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	id := c.Param("id")
+	if !util.IsValidUUID(id) {
+		h.handleResponse(c, http.BadRequest, "Invalid loan ID")
+		return
+	}
+
+	var req models.EarlyRepaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	}
+	req.ID = id
+	req.UserID = auth.UserId
+
+	resp, err := h.services.LoanService().EarlyRepayment(c.Request.Context(), &req)
+	if err != nil {
+		h.handleLoanError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+func (h *Handler) handleLoanError(c *gin.Context, err error) {
+	switch err.(type) {
+	case *customerrors.InternalServerError:
+		h.handleResponse(c, http.InternalServerError, err.Error())
+	default:
+		h.handleResponse(c, http.BadRequest, err.Error())
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/dilmurodov/online_banking/api/http"
 	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
 	"github.com/dilmurodov/online_banking/pkg/jwt"
 	"github.com/dilmurodov/online_banking/pkg/models"
 	"github.com/gin-gonic/gin"
@@ -24,6 +25,60 @@ func (h *Handler) AuthMiddleware(c *gin.Context) {
 	c.Next()
 }
 
+// RequirePermission lets through only the signed in users whose role has the permission,
+// it goes after AuthMiddleware
+func (h *Handler) RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authObj, ok := c.Get("auth")
+		if !ok {
+			h.handleResponse(c, http.Unauthorized, "unauthorized")
+			c.Abort()
+			return
+		}
+
+		if !models.RoleAllows(authObj.(*models.HasAccessModel).Role, permission) {
+			h.handleResponse(c, http.Forbidden, "no access")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// StepUpMiddleware asks a user with 2FA for a fresh code before sensitive operations,
+// the code is traded for a step-up token that comes in the X-Step-Up-Token header
+func (h *Handler) StepUpMiddleware(c *gin.Context) {
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		c.Abort()
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	user, err := h.services.UserService().GetUserByID(c.Request.Context(), &models.GetUserByIDRequest{UserId: auth.UserId})
+	if err != nil {
+		h.handleResponse(c, http.Forbidden, err.Error())
+		c.Abort()
+		return
+	}
+	if !user.User.TwoFactorEnabled {
+		c.Next()
+		return
+	}
+
+	claims, err := jwt.ExtractClaims(c.GetHeader("X-Step-Up-Token"), config.SigningKey)
+	if err != nil || claims["step_up_user_id"] != auth.UserId || claimUnix(claims["exp"]) < time.Now().Unix() {
+		h.handleResponse(c, http.Forbidden, (&customerrors.StepUpRequiredError{}).Error())
+		c.Abort()
+		return
+	}
+
+	c.Next()
+}
+
 func (h *Handler) hasAccess(c *gin.Context, result *models.HasAccessModel) bool {
 
 	bearerToken := c.GetHeader("Authorization")
@@ -40,16 +95,7 @@ func (h *Handler) hasAccess(c *gin.Context, result *models.HasAccessModel) bool
 		return false
 	}
 
-	var tm time.Time
-	switch exp := claims["exp"].(type) {
-	case float64:
-		tm = time.Unix(int64(exp), 0)
-	case json.Number:
-		v, _ := exp.Int64()
-		tm = time.Unix(v, 0)
-	}
-
-	if tm.Unix() < time.Now().Unix() {
+	if claimUnix(claims["exp"]) < time.Now().Unix() {
 		h.handleResponse(c, http.Forbidden, "token expired")
 		return false
 	}
@@ -66,7 +112,7 @@ func (h *Handler) hasAccess(c *gin.Context, result *models.HasAccessModel) bool
 		return false
 	}
 
-	_, err = h.services.UserService().GetUserByID(c, &models.GetUserByIDRequest{
+	user, err := h.services.UserService().GetUserByID(c, &models.GetUserByIDRequest{
 		UserId: userId.(string),
 		Phone:  phone.(string),
 	})
@@ -75,8 +121,34 @@ func (h *Handler) hasAccess(c *gin.Context, result *models.HasAccessModel) bool
 		return false
 	}
 
+	// a password reset signs the user out of every session started before it. Both times are whole
+	// seconds, so a token issued in the second of the reset is revoked as well
+	if revokedAt := user.User.SessionsRevokedAt; revokedAt > 0 && claimUnix(claims["iat"]) <= revokedAt {
+		h.handleResponse(c, http.Forbidden, "token revoked")
+		return false
+	}
+
+	// the role is read from the database, a token issued before a role change is not accepted
+	if role, ok := claims["role"].(string); ok && role != user.User.Role {
+		h.handleResponse(c, http.Forbidden, "token revoked")
+		return false
+	}
+
 	result.UserId = userId.(string)
 	result.Phone = phone.(string)
+	result.Role = user.User.Role
 
 	return true
 }
+
+// claimUnix reads a unix time claim, a missing one reads as zero
+func claimUnix(claim interface{}) int64 {
+	switch v := claim.(type) {
+	case float64:
+		return int64(v)
+	case json.Number:
+		n, _ := v.Int64()
+		return n
+	}
+	return 0
+}
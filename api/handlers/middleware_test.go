@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	nethttp "net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestHandler_RequirePermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := NewHandler(config.Config{}, zap.NewNop(), nil)
+
+	decide := func(role string) int {
+		router := gin.New()
+		router.POST("/operations/:id/approve", func(c *gin.Context) {
+			c.Set("auth", &models.HasAccessModel{UserId: "TestUserID", Role: role})
+		}, h.RequirePermission(models.PermissionOperationsDecide), func(c *gin.Context) {
+			c.Status(nethttp.StatusOK)
+		})
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(nethttp.MethodPost, "/operations/TestOperationID/approve", nil))
+		return w.Code
+	}
+
+	t.Run("READ_ONLY", func(t *testing.T) {
+		// compliance sees the operations waiting for approval, it can't decide them
+		r := require.New(t)
+		r.True(models.RoleAllows(models.RoleCompliance, models.PermissionOperationsRead))
+		r.Equal(nethttp.StatusForbidden, decide(models.RoleCompliance))
+		r.Equal(nethttp.StatusForbidden, decide(models.RoleCustomer))
+	})
+
+	t.Run("DECIDE", func(t *testing.T) {
+		require.Equal(t, nethttp.StatusOK, decide(models.RoleAdmin))
+	})
+}
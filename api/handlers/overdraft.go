@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"github.com/dilmurodov/online_banking/api/http"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/dilmurodov/online_banking/pkg/util"
+	"github.com/gin-gonic/gin"
+)
+
+// GetOverdraftUsage godoc
+// @Security BearerAuth
+// @ID get_overdraft_usage
+// @Router /api/v1/user/accounts/{id}/overdraft [GET]
+// @Summary Get Overdraft Usage
+// @Description Get the overdraft limit of an account, how much of it is used and the overdraft interest owed
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param id path string true "Account ID"
+// @Success 200 {object} http.Response{data=models.OverdraftUsage} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) OverdraftGetHandler(c *gin.Context) {
+
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	accountID := c.Param("id")
+	if !util.IsValidUUID(accountID) {
+		h.handleResponse(c, http.BadRequest, "Invalid account ID")
+		return
+	}
+
+	verified, err := h.userHasAccountPermission(c, auth.UserId, accountID, models.AccountPermissionView)
+	if err != nil {
+		h.handleResponse(c, http.InternalServerError, err.Error())
+		return
+	}
+	if !verified {
+		h.handleResponse(c, http.BadRequest, "account not found")
+		return
+	}
+
+	resp, err := h.services.OverdraftService().GetOverdraftUsage(c.Request.Context(), accountID)
+	if err != nil {
+		h.handleOverdraftError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// SetOverdraftLimit godoc
+// @Security BearerAuth
+// @ID set_overdraft_limit
+// @Router /api/v1/user/accounts/{id}/overdraft [PUT]
+// @Summary Set Overdraft Limit
+// @Description Opt a current account into an overdraft, a zero limit opts out. The limit can't go below the overdrawn amount
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param id path string true "Account ID"
+// @Param body body models.SetOverdraftLimitRequest true "Limit"
+// @Success 200 {object} http.Response{data=models.OverdraftUsage} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) OverdraftSetHandler(c *gin.Context) {
+
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	accountID := c.Param("id")
+	if !util.IsValidUUID(accountID) {
+		h.handleResponse(c, http.BadRequest, "Invalid account ID")
+		return
+	}
+
+	var req models.SetOverdraftLimitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	}
+	req.AccountID = accountID
+	req.UserID = auth.UserId
+
+	resp, err := h.services.OverdraftService().SetOverdraftLimit(c.Request.Context(), &req)
+	if err != nil {
+		h.handleOverdraftError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+func (h *Handler) handleOverdraftError(c *gin.Context, err error) {
+	switch err.(type) {
+	case *customerrors.InternalServerError:
+		h.handleResponse(c, http.InternalServerError, err.Error())
+	default:
+		h.handleResponse(c, http.BadRequest, err.Error())
+	}
+}
@@ -0,0 +1,349 @@
+package handlers
+
+import (
+	"github.com/dilmurodov/online_banking/api/http"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/dilmurodov/online_banking/pkg/util"
+	"github.com/gin-gonic/gin"
+)
+
+// @Security BearerAuth
+// CreatePaymentRequestHandler godoc
+// @ID create_payment_request
+// @Summary Request Money
+// @Description Ask another user, addressed by phone or account, to pay into one of your accounts
+// @Tags PaymentRequest
+// @Accept json
+// @Produce json
+// @Param body body models.CreatePaymentRequestRequest true "Payment request"
+// @Router /api/v1/payments/requests [POST]
+// @Success 201 {object} http.Response{data=models.PaymentRequest} "Created"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Response 429 {object} http.Response{data=string} "Too Many Requests"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) CreatePaymentRequestHandler(c *gin.Context) {
+
+	// Get auth from context
+	auth, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+	authObj := auth.(*models.HasAccessModel)
+
+	// Get request body
+	var req models.CreatePaymentRequestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	}
+	req.RequesterID = authObj.UserId
+
+	// A request addressed by phone tells whether the phone is registered, it counts against the lookup limit
+	if req.ToPhone != "" && !h.phoneLookupLimiter.Allow(authObj.UserId) {
+		h.handleResponse(c, http.TooManyRequests, "too many lookups, try again later")
+		return
+	}
+
+	// Check if the receiving account is owned by user
+	verified, err := h.userHasAccountPermission(c, authObj.UserId, req.AccountID, models.AccountPermissionView)
+	if err != nil {
+		h.handleResponse(c, http.InternalServerError, err.Error())
+		return
+	}
+	if !verified {
+		h.handleResponse(c, http.BadRequest, "account not found")
+		return
+	}
+
+	// Call service
+	resp, err := h.services.PaymentRequestService().CreatePaymentRequest(c.Request.Context(), &req)
+	if err != nil {
+		h.handlePaymentRequestError(c, err)
+		return
+	}
+	h.handleResponse(c, http.Created, resp)
+}
+
+// @Security BearerAuth
+// IncomingPaymentRequestsHandler godoc
+// @ID incoming_payment_requests
+// @Summary Incoming Payment Requests
+// @Description Payment requests the user has been asked to pay
+// @Tags PaymentRequest
+// @Accept json
+// @Produce json
+// @Param status query string false "pending, accepted, declined, cancelled or expired"
+// @Param limit query int false "limit"
+// @Param offset query int false "offset"
+// @Router /api/v1/payments/requests/incoming [GET]
+// @Success 200 {object} http.Response{data=models.GetPaymentRequestsResponse} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) IncomingPaymentRequestsHandler(c *gin.Context) {
+
+	auth, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+	authObj := auth.(*models.HasAccessModel)
+
+	req, ok := h.getPaymentRequestsParams(c)
+	if !ok {
+		return
+	}
+	req.PayerID = authObj.UserId
+
+	resp, err := h.services.PaymentRequestService().GetIncomingPaymentRequests(c.Request.Context(), req)
+	if err != nil {
+		h.handleResponse(c, http.InternalServerError, err.Error())
+		return
+	}
+	h.handleResponse(c, http.OK, resp)
+}
+
+// @Security BearerAuth
+// OutgoingPaymentRequestsHandler godoc
+// @ID outgoing_payment_requests
+// @Summary Outgoing Payment Requests
+// @Description Payment requests the user has sent, with their current status
+// @Tags PaymentRequest
+// @Accept json
+// @Produce json
+// @Param status query string false "pending, accepted, declined, cancelled or expired"
+// @Param limit query int false "limit"
+// @Param offset query int false "offset"
+// @Router /api/v1/payments/requests/outgoing [GET]
+// @Success 200 {object} http.Response{data=models.GetPaymentRequestsResponse} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) OutgoingPaymentRequestsHandler(c *gin.Context) {
+
+	auth, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+	authObj := auth.(*models.HasAccessModel)
+
+	req, ok := h.getPaymentRequestsParams(c)
+	if !ok {
+		return
+	}
+	req.RequesterID = authObj.UserId
+
+	resp, err := h.services.PaymentRequestService().GetOutgoingPaymentRequests(c.Request.Context(), req)
+	if err != nil {
+		h.handleResponse(c, http.InternalServerError, err.Error())
+		return
+	}
+	h.handleResponse(c, http.OK, resp)
+}
+
+// @Security BearerAuth
+// GetPaymentRequestHandler godoc
+// @ID get_payment_request
+// @Summary Get Payment Request
+// @Description Get a payment request with its status history
+// @Tags PaymentRequest
+// @Accept json
+// @Produce json
+// @Param id path string true "Payment request ID"
+// @Router /api/v1/payments/requests/{id} [GET]
+// @Success 200 {object} http.Response{data=models.PaymentRequest} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) GetPaymentRequestHandler(c *gin.Context) {
+
+	auth, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+	authObj := auth.(*models.HasAccessModel)
+
+	id := c.Param("id")
+	if !util.IsValidUUID(id) {
+		h.handleResponse(c, http.BadRequest, "Invalid payment request ID")
+		return
+	}
+
+	resp, err := h.services.PaymentRequestService().GetPaymentRequestByID(c.Request.Context(), &models.GetPaymentRequestByIDRequest{
+		ID:     id,
+		UserID: authObj.UserId,
+	})
+	if err != nil {
+		h.handlePaymentRequestError(c, err)
+		return
+	}
+	h.handleResponse(c, http.OK, resp)
+}
+
+// @Security BearerAuth
+// AcceptPaymentRequestHandler godoc
+// @ID accept_payment_request
+// @Summary Accept Payment Request
+// @Description Pay a pending payment request from one of your accounts
+// @Tags PaymentRequest
+// @Accept json
+// @Produce json
+// @Param id path string true "Payment request ID"
+// @Param body body models.AcceptPaymentRequestRequest true "Paying account"
+// @Router /api/v1/payments/requests/{id}/accept [POST]
+// @Success 201 {object} http.Response{data=models.AcceptPaymentRequestResponse} "Created"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Response 403 {object} http.Response{data=models.FraudChallenge} "Payment challenged or blocked"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) AcceptPaymentRequestHandler(c *gin.Context) {
+
+	auth, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+	authObj := auth.(*models.HasAccessModel)
+
+	id := c.Param("id")
+	if !util.IsValidUUID(id) {
+		h.handleResponse(c, http.BadRequest, "Invalid payment request ID")
+		return
+	}
+
+	var req models.AcceptPaymentRequestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	}
+	req.ID = id
+	req.UserID = authObj.UserId
+
+	// Check if account is owned by user
+	verified, err := h.userHasAccountPermission(c, authObj.UserId, req.FromAccountID, models.AccountPermissionTransact)
+	if err != nil {
+		h.handleResponse(c, http.InternalServerError, err.Error())
+		return
+	}
+	if !verified {
+		h.handleResponse(c, http.BadRequest, "account not found")
+		return
+	}
+
+	resp, err := h.services.PaymentRequestService().AcceptPaymentRequest(c.Request.Context(), &req)
+	if err != nil {
+		if h.handlePaymentScreeningError(c, authObj.UserId, err) {
+			return
+		}
+		h.handlePaymentRequestError(c, err)
+		return
+	}
+	h.handleResponse(c, http.Created, resp)
+}
+
+// @Security BearerAuth
+// DeclinePaymentRequestHandler godoc
+// @ID decline_payment_request
+// @Summary Decline Payment Request
+// @Description Refuse a pending payment request addressed to you
+// @Tags PaymentRequest
+// @Accept json
+// @Produce json
+// @Param id path string true "Payment request ID"
+// @Router /api/v1/payments/requests/{id}/decline [POST]
+// @Success 200 {object} http.Response{data=models.PaymentRequest} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) DeclinePaymentRequestHandler(c *gin.Context) {
+
+	auth, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+	authObj := auth.(*models.HasAccessModel)
+
+	id := c.Param("id")
+	if !util.IsValidUUID(id) {
+		h.handleResponse(c, http.BadRequest, "Invalid payment request ID")
+		return
+	}
+
+	resp, err := h.services.PaymentRequestService().DeclinePaymentRequest(c.Request.Context(), &models.DeclinePaymentRequestRequest{
+		ID:     id,
+		UserID: authObj.UserId,
+	})
+	if err != nil {
+		h.handlePaymentRequestError(c, err)
+		return
+	}
+	h.handleResponse(c, http.OK, resp)
+}
+
+// @Security BearerAuth
+// CancelPaymentRequestHandler godoc
+// @ID cancel_payment_request
+// @Summary Cancel Payment Request
+// @Description Withdraw a pending payment request you have sent
+// @Tags PaymentRequest
+// @Accept json
+// @Produce json
+// @Param id path string true "Payment request ID"
+// @Router /api/v1/payments/requests/{id}/cancel [POST]
+// @Success 200 {object} http.Response{data=models.PaymentRequest} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) CancelPaymentRequestHandler(c *gin.Context) {
+
+	auth, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+	authObj := auth.(*models.HasAccessModel)
+
+	id := c.Param("id")
+	if !util.IsValidUUID(id) {
+		h.handleResponse(c, http.BadRequest, "Invalid payment request ID")
+		return
+	}
+
+	resp, err := h.services.PaymentRequestService().CancelPaymentRequest(c.Request.Context(), &models.DeclinePaymentRequestRequest{
+		ID:     id,
+		UserID: authObj.UserId,
+	})
+	if err != nil {
+		h.handlePaymentRequestError(c, err)
+		return
+	}
+	h.handleResponse(c, http.OK, resp)
+}
+
+func (h *Handler) getPaymentRequestsParams(c *gin.Context) (*models.GetPaymentRequestsRequest, bool) {
+	limit, err := h.getLimitParam(c)
+	if err != nil {
+		h.handleResponse(c, http.InvalidArgument, err.Error())
+		return nil, false
+	}
+
+	offset, err := h.getOffsetParam(c)
+	if err != nil {
+		h.handleResponse(c, http.InvalidArgument, err.Error())
+		return nil, false
+	}
+
+	return &models.GetPaymentRequestsRequest{
+		Status: c.Query("status"),
+		Limit:  limit,
+		Offset: offset,
+	}, true
+}
+
+func (h *Handler) handlePaymentRequestError(c *gin.Context, err error) {
+	switch err.(type) {
+	case *customerrors.InternalServerError:
+		h.handleResponse(c, http.InternalServerError, err.Error())
+	default:
+		h.handleResponse(c, http.BadRequest, err.Error())
+	}
+}
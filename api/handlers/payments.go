@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"github.com/dilmurodov/online_banking/api/http"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
 	"github.com/dilmurodov/online_banking/pkg/models"
 	"github.com/gin-gonic/gin"
 )
@@ -18,6 +19,7 @@ import (
 // @Router /api/v1/payments/withdrawal [POST]
 // @Success 201 {object} http.Response{data=models.WithDrawalResponse} "Created"
 // @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Response 403 {object} http.Response{data=models.FraudChallenge} "Payment challenged or blocked"
 // @Failure 500 {object} http.Response{data=string} "Server Error"
 func (h *Handler) WithDrawalHandler(c *gin.Context) {
 
@@ -44,7 +46,7 @@ func (h *Handler) WithDrawalHandler(c *gin.Context) {
 		return
 	}
 	for _, account := range accounts.Accounts {
-		if req.AccountID == account.ID {
+		if req.AccountID == account.ID && models.AccountRoleAllows(account.Role, models.AccountPermissionTransact) {
 			verified = true
 			break
 		}
@@ -54,8 +56,17 @@ func (h *Handler) WithDrawalHandler(c *gin.Context) {
 		return
 	}
 
+	req.UserID = authObj.UserId
 	resp, err := h.services.PaymentService().WithDrawal(c.Request.Context(), &req)
 	if err != nil {
+		if h.handlePaymentScreeningError(c, authObj.UserId, err) {
+			return
+		}
+		switch err.(type) {
+		case *customerrors.InvalidAmountError, *customerrors.InvalidRemittanceError, *customerrors.AccountLockedError, *customerrors.AccountStatusError, *customerrors.InsufficientFundsError, *customerrors.KYCLimitExceededError:
+			h.handleResponse(c, http.BadRequest, err.Error())
+			return
+		}
 		h.handleResponse(c, http.InternalServerError, err.Error())
 		return
 	}
@@ -74,6 +85,7 @@ func (h *Handler) WithDrawalHandler(c *gin.Context) {
 // @Router /api/v1/payments/deposit [POST]
 // @Success 201 {object} http.Response{data=models.DepositResponse} "Created"
 // @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Response 403 {object} http.Response{data=string} "Blocked by the sanctions checks"
 // @Failure 500 {object} http.Response{data=string} "Server Error"
 func (h *Handler) DepositHandler(c *gin.Context) {
 
@@ -103,7 +115,7 @@ func (h *Handler) DepositHandler(c *gin.Context) {
 		return
 	}
 	for _, account := range accounts.Accounts {
-		if req.AccountID == account.ID {
+		if req.AccountID == account.ID && models.AccountRoleAllows(account.Role, models.AccountPermissionTransact) {
 			verified = true
 			break
 		}
@@ -116,6 +128,14 @@ func (h *Handler) DepositHandler(c *gin.Context) {
 	// Call service
 	resp, err := h.services.PaymentService().Deposit(c.Request.Context(), &req)
 	if err != nil {
+		if h.handlePaymentScreeningError(c, authObj.UserId, err) {
+			return
+		}
+		switch err.(type) {
+		case *customerrors.InvalidAmountError, *customerrors.InvalidRemittanceError, *customerrors.AccountLockedError, *customerrors.AccountStatusError, *customerrors.InsufficientFundsError, *customerrors.KYCLimitExceededError:
+			h.handleResponse(c, http.BadRequest, err.Error())
+			return
+		}
 		h.handleResponse(c, http.InternalServerError, err.Error())
 		return
 	}
@@ -181,7 +201,7 @@ func (h *Handler) CaptureTransactionsHandler(c *gin.Context) {
 		return
 	}
 	for _, account := range accounts.Accounts {
-		if req.AccountID == account.ID {
+		if req.AccountID == account.ID && models.AccountRoleAllows(account.Role, models.AccountPermissionTransact) {
 			verified = true
 			break
 		}
@@ -194,6 +214,11 @@ func (h *Handler) CaptureTransactionsHandler(c *gin.Context) {
 	// Call service
 	err = h.services.PaymentService().CaptureTransactions(c.Request.Context(), &req)
 	if err != nil {
+		switch err.(type) {
+		case *customerrors.InsufficientFundsError, *customerrors.AccountStatusError:
+			h.handleResponse(c, http.BadRequest, err.Error())
+			return
+		}
 		h.handleResponse(c, http.InternalServerError, err.Error())
 		return
 	}
@@ -205,7 +230,7 @@ func (h *Handler) CaptureTransactionsHandler(c *gin.Context) {
 // TransferHandler godoc
 // @ID transfer
 // @Summary Transfer
-// @Description Transfer
+// @Description Transfer. Above the account's dual approval threshold the transfer waits for another holder and the approval is returned instead of the transactions
 // @Tags Payment
 // @Accept json
 // @Produce json
@@ -213,6 +238,7 @@ func (h *Handler) CaptureTransactionsHandler(c *gin.Context) {
 // @Router /api/v1/payments/transfer [POST]
 // @Success 201 {object} http.Response{data=models.TransferResponse} "Created"
 // @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Response 403 {object} http.Response{data=models.FraudChallenge} "Payment challenged or blocked"
 // @Failure 500 {object} http.Response{data=string} "Server Error"
 func (h *Handler) TransferHandler(c *gin.Context) {
 
@@ -242,7 +268,7 @@ func (h *Handler) TransferHandler(c *gin.Context) {
 		return
 	}
 	for _, account := range accounts.Accounts {
-		if req.FromAccountID == account.ID {
+		if req.FromAccountID == account.ID && models.AccountRoleAllows(account.Role, models.AccountPermissionTransact) {
 			verified = true
 			break
 		}
@@ -253,8 +279,131 @@ func (h *Handler) TransferHandler(c *gin.Context) {
 	}
 
 	// Call service
+	req.UserID = authObj.UserId
 	resp, err := h.services.PaymentService().Transfer(c.Request.Context(), &req)
 	if err != nil {
+		if h.handlePaymentScreeningError(c, authObj.UserId, err) {
+			return
+		}
+		switch err.(type) {
+		case *customerrors.BeneficiaryNotFoundError, *customerrors.TransferLimitExceededError, *customerrors.InvalidAmountError, *customerrors.InvalidRemittanceError, *customerrors.AccountLockedError, *customerrors.AccountStatusError, *customerrors.InsufficientFundsError, *customerrors.KYCLimitExceededError,
+			*customerrors.AccountNotFoundError, *customerrors.AccountPermissionError:
+			h.handleResponse(c, http.BadRequest, err.Error())
+		default:
+			h.handleResponse(c, http.InternalServerError, err.Error())
+		}
+		return
+	}
+	h.handleResponse(c, http.Created, resp)
+}
+
+// @Security BearerAuth
+// PhoneLookupHandler godoc
+// @ID phone_lookup
+// @Summary Look Up Recipient By Phone
+// @Description Resolves a phone number to a masked recipient name and a short-lived confirmation token
+// @Tags Payment
+// @Accept json
+// @Produce json
+// @Param body body models.PhoneLookupRequest true "Phone"
+// @Router /api/v1/payments/phone/lookup [POST]
+// @Success 200 {object} http.Response{data=models.PhoneLookupResponse} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Response 429 {object} http.Response{data=string} "Too Many Requests"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) PhoneLookupHandler(c *gin.Context) {
+
+	// Get auth from context
+	auth, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+	authObj := auth.(*models.HasAccessModel)
+
+	// Limit lookups per user so phone numbers can't be enumerated
+	if !h.phoneLookupLimiter.Allow(authObj.UserId) {
+		h.handleResponse(c, http.TooManyRequests, "too many lookups, try again later")
+		return
+	}
+
+	// Get request body
+	var req models.PhoneLookupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	}
+	req.UserID = authObj.UserId
+
+	// Call service
+	resp, err := h.services.PaymentService().LookupPhoneRecipient(c.Request.Context(), &req)
+	if err != nil {
+		switch err.(type) {
+		case *customerrors.RecipientNotFoundError, *customerrors.InvalidPhoneError:
+			h.handleResponse(c, http.BadRequest, (&customerrors.RecipientNotFoundError{}).Error())
+		default:
+			h.handleResponse(c, http.InternalServerError, err.Error())
+		}
+		return
+	}
+	h.handleResponse(c, http.OK, resp)
+}
+
+// @Security BearerAuth
+// PhoneTransferHandler godoc
+// @ID phone_transfer
+// @Summary Transfer By Phone
+// @Description Transfer to the recipient confirmed by the phone lookup
+// @Tags Payment
+// @Accept json
+// @Produce json
+// @Param body body models.PhoneTransferRequest true "Transfer"
+// @Router /api/v1/payments/phone/transfer [POST]
+// @Success 201 {object} http.Response{data=models.TransferResponse} "Created"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Response 403 {object} http.Response{data=models.FraudChallenge} "Payment challenged or blocked"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) PhoneTransferHandler(c *gin.Context) {
+
+	// Get auth from context
+	auth, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+	authObj := auth.(*models.HasAccessModel)
+
+	// Get request body
+	var req models.PhoneTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	}
+	req.UserID = authObj.UserId
+
+	// Check if account is owned by user
+	verified, err := h.userHasAccountPermission(c, authObj.UserId, req.FromAccountID, models.AccountPermissionTransact)
+	if err != nil {
+		h.handleResponse(c, http.InternalServerError, err.Error())
+		return
+	}
+	if !verified {
+		h.handleResponse(c, http.BadRequest, "account not found")
+		return
+	}
+
+	// Call service
+	resp, err := h.services.PaymentService().TransferByPhone(c.Request.Context(), &req)
+	if err != nil {
+		if h.handlePaymentScreeningError(c, authObj.UserId, err) {
+			return
+		}
+		switch err.(type) {
+		case *customerrors.InvalidConfirmationTokenError, *customerrors.InvalidAmountError, *customerrors.InvalidRemittanceError, *customerrors.AccountLockedError, *customerrors.AccountStatusError, *customerrors.InsufficientFundsError, *customerrors.KYCLimitExceededError,
+			*customerrors.AccountNotFoundError, *customerrors.AccountPermissionError:
+			h.handleResponse(c, http.BadRequest, err.Error())
+			return
+		}
 		h.handleResponse(c, http.InternalServerError, err.Error())
 		return
 	}
@@ -0,0 +1,342 @@
+package handlers
+
+import (
+	"github.com/dilmurodov/online_banking/api/http"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/dilmurodov/online_banking/pkg/util"
+	"github.com/gin-gonic/gin"
+)
+
+// GetPots godoc
+// @Security BearerAuth
+// @ID get_pots
+// @Router /api/v1/user/accounts/{id}/pots [GET]
+// @Summary Get Pots
+// @Description Get the pots of an account with the progress towards their targets
+// @Tags Pot
+// @Accept json
+// @Produce json
+// @Param id path string true "Account ID"
+// @Param closed query boolean false "include the closed pots"
+// @Success 200 {object} http.Response{data=models.GetPotsResponse} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) PotsGetHandler(c *gin.Context) {
+
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	accountID := c.Param("id")
+	if !util.IsValidUUID(accountID) {
+		h.handleResponse(c, http.BadRequest, "Invalid account ID")
+		return
+	}
+
+	verified, err := h.userHasAccountPermission(c, auth.UserId, accountID, models.AccountPermissionView)
+	if err != nil {
+		h.handleResponse(c, http.InternalServerError, err.Error())
+		return
+	}
+	if !verified {
+		h.handleResponse(c, http.BadRequest, "account not found")
+		return
+	}
+
+	resp, err := h.services.PotService().GetPots(c.Request.Context(), &models.GetPotsRequest{
+		UserID:    auth.UserId,
+		AccountID: accountID,
+		Closed:    c.Query("closed") == "true",
+	})
+	if err != nil {
+		h.handleResponse(c, http.InternalServerError, err.Error())
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// CreatePot godoc
+// @Security BearerAuth
+// @ID create_pot
+// @Router /api/v1/user/accounts/{id}/pots [POST]
+// @Summary Create Pot
+// @Description Open a pot under an account, optionally with a target and a round-up rule
+// @Tags Pot
+// @Accept json
+// @Produce json
+// @Param id path string true "Account ID"
+// @Param body body models.CreatePotRequest true "Pot"
+// @Success 201 {object} http.Response{data=models.Pot} "Created"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) PotCreateHandler(c *gin.Context) {
+
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	accountID := c.Param("id")
+	if !util.IsValidUUID(accountID) {
+		h.handleResponse(c, http.BadRequest, "Invalid account ID")
+		return
+	}
+
+	var req models.CreatePotRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	}
+	req.UserID = auth.UserId
+	req.AccountID = accountID
+
+	resp, err := h.services.PotService().CreatePot(c.Request.Context(), &req)
+	if err != nil {
+		h.handlePotError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.Created, resp)
+}
+
+// GetPot godoc
+// @Security BearerAuth
+// @ID get_pot
+// @Router /api/v1/user/accounts/{id}/pots/{pot_id} [GET]
+// @Summary Get Pot
+// @Description Get Pot
+// @Tags Pot
+// @Accept json
+// @Produce json
+// @Param id path string true "Account ID"
+// @Param pot_id path string true "Pot ID"
+// @Success 200 {object} http.Response{data=models.Pot} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) PotGetHandler(c *gin.Context) {
+
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	req, ok := h.potByIDRequest(c, auth.UserId)
+	if !ok {
+		return
+	}
+
+	resp, err := h.services.PotService().GetPotByID(c.Request.Context(), req)
+	if err != nil {
+		h.handlePotError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// UpdatePot godoc
+// @Security BearerAuth
+// @ID update_pot
+// @Router /api/v1/user/accounts/{id}/pots/{pot_id} [PUT]
+// @Summary Update Pot
+// @Description Replace the name, target and round-up rule of a pot
+// @Tags Pot
+// @Accept json
+// @Produce json
+// @Param id path string true "Account ID"
+// @Param pot_id path string true "Pot ID"
+// @Param body body models.UpdatePotRequest true "Pot"
+// @Success 200 {object} http.Response{data=models.Pot} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) PotUpdateHandler(c *gin.Context) {
+
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	byID, ok := h.potByIDRequest(c, auth.UserId)
+	if !ok {
+		return
+	}
+
+	var req models.UpdatePotRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	}
+	req.ID = byID.ID
+	req.UserID = byID.UserID
+	req.AccountID = byID.AccountID
+
+	resp, err := h.services.PotService().UpdatePot(c.Request.Context(), &req)
+	if err != nil {
+		h.handlePotError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// PotDeposit godoc
+// @Security BearerAuth
+// @ID pot_deposit
+// @Router /api/v1/user/accounts/{id}/pots/{pot_id}/deposit [POST]
+// @Summary Move To Pot
+// @Description Move money from the account's balance into the pot
+// @Tags Pot
+// @Accept json
+// @Produce json
+// @Param id path string true "Account ID"
+// @Param pot_id path string true "Pot ID"
+// @Param body body models.PotMoveRequest true "Amount"
+// @Success 200 {object} http.Response{data=models.PotMoveResponse} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) PotDepositHandler(c *gin.Context) {
+	h.potMove(c, true)
+}
+
+// PotWithdraw godoc
+// @Security BearerAuth
+// @ID pot_withdraw
+// @Router /api/v1/user/accounts/{id}/pots/{pot_id}/withdraw [POST]
+// @Summary Move From Pot
+// @Description Move money from the pot back to the account's balance
+// @Tags Pot
+// @Accept json
+// @Produce json
+// @Param id path string true "Account ID"
+// @Param pot_id path string true "Pot ID"
+// @Param body body models.PotMoveRequest true "Amount"
+// @Success 200 {object} http.Response{data=models.PotMoveResponse} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) PotWithdrawHandler(c *gin.Context) {
+	h.potMove(c, false)
+}
+
+func (h *Handler) potMove(c *gin.Context, in bool) {
+
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	byID, ok := h.potByIDRequest(c, auth.UserId)
+	if !ok {
+		return
+	}
+
+	var req models.PotMoveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	}
+	req.ID = byID.ID
+	req.UserID = byID.UserID
+	req.AccountID = byID.AccountID
+
+	var (
+		resp *models.PotMoveResponse
+		err  error
+	)
+	if in {
+		resp, err = h.services.PotService().MoveToPot(c.Request.Context(), &req)
+	} else {
+		resp, err = h.services.PotService().MoveFromPot(c.Request.Context(), &req)
+	}
+	if err != nil {
+		h.handlePotError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// ClosePot godoc
+// @Security BearerAuth
+// @ID close_pot
+// @Router /api/v1/user/accounts/{id}/pots/{pot_id} [DELETE]
+// @Summary Close Pot
+// @Description Close the pot, its balance goes back to the account
+// @Tags Pot
+// @Accept json
+// @Produce json
+// @Param id path string true "Account ID"
+// @Param pot_id path string true "Pot ID"
+// @Success 200 {object} http.Response{data=models.PotMoveResponse} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) PotCloseHandler(c *gin.Context) {
+
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	req, ok := h.potByIDRequest(c, auth.UserId)
+	if !ok {
+		return
+	}
+
+	resp, err := h.services.PotService().ClosePot(c.Request.Context(), req)
+	if err != nil {
+		h.handlePotError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// potByIDRequest reads the account and pot ids from the path, answering 400 when either is invalid
+func (h *Handler) potByIDRequest(c *gin.Context, userID string) (*models.PotByIDRequest, bool) {
+	accountID := c.Param("id")
+	if !util.IsValidUUID(accountID) {
+		h.handleResponse(c, http.BadRequest, "Invalid account ID")
+		return nil, false
+	}
+
+	potID := c.Param("pot_id")
+	if !util.IsValidUUID(potID) {
+		h.handleResponse(c, http.BadRequest, "Invalid pot ID")
+		return nil, false
+	}
+
+	return &models.PotByIDRequest{
+		ID:        potID,
+		UserID:    userID,
+		AccountID: accountID,
+	}, true
+}
+
+func (h *Handler) handlePotError(c *gin.Context, err error) {
+	switch err.(type) {
+	case *customerrors.InternalServerError:
+		h.handleResponse(c, http.InternalServerError, err.Error())
+	default:
+		h.handleResponse(c, http.BadRequest, err.Error())
+	}
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"github.com/dilmurodov/online_banking/api/http"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/dilmurodov/online_banking/pkg/util"
+	"github.com/gin-gonic/gin"
+)
+
+// AdminSanctionsHitsHandler godoc
+// @Security BearerAuth
+// @ID admin_get_sanctions_hits
+// @Router /api/v1/admin/sanctions/hits [GET]
+// @Summary Get Sanctions Hits
+// @Description Get the names that matched the sanctions list, newest first. Pending hits are the review queue.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param user_id query string false "User ID"
+// @Param status query string false "pending, confirmed or dismissed"
+// @Param source query string false "registration, profile or beneficiary"
+// @Param offset query integer false "offset"
+// @Param limit query integer false "limit"
+// @Success 200 {object} http.Response{data=models.GetSanctionsHitsResponse} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) AdminSanctionsHitsHandler(c *gin.Context) {
+	offset, err := h.getOffsetParam(c)
+	if err != nil {
+		h.handleResponse(c, http.InvalidArgument, err.Error())
+		return
+	}
+
+	limit, err := h.getLimitParam(c)
+	if err != nil {
+		h.handleResponse(c, http.InvalidArgument, err.Error())
+		return
+	}
+
+	req := &models.GetSanctionsHitsRequest{
+		UserID: c.Query("user_id"),
+		Status: c.Query("status"),
+		Source: c.Query("source"),
+		Limit:  limit,
+		Offset: offset,
+	}
+	if req.UserID != "" && !util.IsValidUUID(req.UserID) {
+		h.handleResponse(c, http.BadRequest, "Invalid user ID")
+		return
+	}
+
+	resp, err := h.services.SanctionsService().GetHits(c.Request.Context(), req)
+	if err != nil {
+		h.handleAdminError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// AdminGetSanctionsHitHandler godoc
+// @Security BearerAuth
+// @ID admin_get_sanctions_hit
+// @Router /api/v1/admin/sanctions/hits/{id} [GET]
+// @Summary Get Sanctions Hit
+// @Description Get a name that matched the sanctions list with the entry it matched
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Hit ID"
+// @Success 200 {object} http.Response{data=models.SanctionsHit} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) AdminGetSanctionsHitHandler(c *gin.Context) {
+	hitID := c.Param("id")
+	if !util.IsValidUUID(hitID) {
+		h.handleResponse(c, http.BadRequest, "Invalid hit ID")
+		return
+	}
+
+	resp, err := h.services.SanctionsService().GetHit(c.Request.Context(), hitID)
+	if err != nil {
+		h.handleAdminError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// AdminReviewSanctionsHitHandler godoc
+// @Security BearerAuth
+// @ID admin_review_sanctions_hit
+// @Router /api/v1/admin/sanctions/hits/{id}/review [POST]
+// @Summary Review Sanctions Hit
+// @Description Confirm a hit, which keeps the user's payments blocked, or dismiss it as someone else, which lets them through
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Hit ID"
+// @Param body body models.ReviewSanctionsHitRequest true "Review"
+// @Success 200 {object} http.Response{data=models.SanctionsHit} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) AdminReviewSanctionsHitHandler(c *gin.Context) {
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	hitID := c.Param("id")
+	if !util.IsValidUUID(hitID) {
+		h.handleResponse(c, http.BadRequest, "Invalid hit ID")
+		return
+	}
+
+	var req models.ReviewSanctionsHitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	}
+	req.ID = hitID
+	req.ActorID = auth.UserId
+	req.IP = c.ClientIP()
+
+	resp, err := h.services.SanctionsService().ReviewHit(c.Request.Context(), &req)
+	if err != nil {
+		h.handleAdminError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// AdminSanctionsListHandler godoc
+// @Security BearerAuth
+// @ID admin_get_sanctions_list
+// @Router /api/v1/admin/sanctions/list [GET]
+// @Summary Get Sanctions List
+// @Description Get the file, size and load time of the sanctions list in use
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Success 200 {object} http.Response{data=models.SanctionsListInfo} "OK"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) AdminSanctionsListHandler(c *gin.Context) {
+	h.handleResponse(c, http.OK, h.services.SanctionsService().GetListInfo())
+}
+
+// AdminReloadSanctionsListHandler godoc
+// @Security BearerAuth
+// @ID admin_reload_sanctions_list
+// @Router /api/v1/admin/sanctions/list/reload [POST]
+// @Summary Reload Sanctions List
+// @Description Read the sanctions list file again without a restart, the list in use is kept when the file is invalid
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Success 200 {object} http.Response{data=models.SanctionsListInfo} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) AdminReloadSanctionsListHandler(c *gin.Context) {
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	resp, err := h.services.SanctionsService().ReloadList(c.Request.Context(), auth.UserId, c.ClientIP())
+	if err != nil {
+		h.handleAdminError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
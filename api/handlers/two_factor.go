@@ -0,0 +1,302 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/dilmurodov/online_banking/api/http"
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/pkg/jwt"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/gin-gonic/gin"
+)
+
+// TOTPEnrollHandler godoc
+// @Security BearerAuth
+// @ID enroll_totp
+// @Router /api/v1/user/me/2fa/enroll [POST]
+// @Summary Enroll Authenticator
+// @Description Create the secret for an authenticator app. 2FA is turned on once the first code is confirmed
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param body body models.EnrollTOTPRequest true "Password"
+// @Success 200 {object} http.Response{data=models.TOTPEnrollment} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) TOTPEnrollHandler(c *gin.Context) {
+
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	var req models.EnrollTOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	}
+	req.UserID = auth.UserId
+	req.Phone = auth.Phone
+
+	resp, err := h.services.UserService().EnrollTOTP(c.Request.Context(), &req)
+	if err != nil {
+		h.handleUserError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// TOTPConfirmHandler godoc
+// @Security BearerAuth
+// @ID confirm_totp
+// @Router /api/v1/user/me/2fa/confirm [POST]
+// @Summary Confirm Authenticator
+// @Description Turn 2FA on with the first code of the authenticator app. The recovery codes are returned once
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param body body models.ConfirmTOTPRequest true "Code"
+// @Success 200 {object} http.Response{data=models.RecoveryCodesResponse} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) TOTPConfirmHandler(c *gin.Context) {
+
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	if !h.mfaVerifyLimiter.Allow(auth.UserId) {
+		h.handleResponse(c, http.TooManyRequests, "too many codes tried, try again later")
+		return
+	}
+
+	var req models.ConfirmTOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	}
+	req.UserID = auth.UserId
+
+	resp, err := h.services.UserService().ConfirmTOTP(c.Request.Context(), &req)
+	if err != nil {
+		h.handleUserError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// TOTPDisableHandler godoc
+// @Security BearerAuth
+// @ID disable_totp
+// @Router /api/v1/user/me/2fa/disable [POST]
+// @Summary Disable 2FA
+// @Description Turn 2FA off with the password and an authenticator or a recovery code
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param body body models.DisableTOTPRequest true "Password and code"
+// @Success 200 {object} http.Response{data=string} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) TOTPDisableHandler(c *gin.Context) {
+
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	if !h.mfaVerifyLimiter.Allow(auth.UserId) {
+		h.handleResponse(c, http.TooManyRequests, "too many codes tried, try again later")
+		return
+	}
+
+	var req models.DisableTOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	}
+	req.UserID = auth.UserId
+	req.Phone = auth.Phone
+
+	if err := h.services.UserService().DisableTOTP(c.Request.Context(), &req); err != nil {
+		h.handleUserError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, "two-factor authentication disabled")
+}
+
+// RecoveryCodesRegenerateHandler godoc
+// @Security BearerAuth
+// @ID regenerate_recovery_codes
+// @Router /api/v1/user/me/2fa/recovery-codes [POST]
+// @Summary Regenerate Recovery Codes
+// @Description Replace the recovery codes, the old ones stop working
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param body body models.StepUpRequest true "Code"
+// @Success 200 {object} http.Response{data=models.RecoveryCodesResponse} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) RecoveryCodesRegenerateHandler(c *gin.Context) {
+
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	if !h.mfaVerifyLimiter.Allow(auth.UserId) {
+		h.handleResponse(c, http.TooManyRequests, "too many codes tried, try again later")
+		return
+	}
+
+	var req models.StepUpRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	}
+	req.UserID = auth.UserId
+
+	resp, err := h.services.UserService().RegenerateRecoveryCodes(c.Request.Context(), &req)
+	if err != nil {
+		h.handleUserError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// StepUpHandler godoc
+// @Security BearerAuth
+// @ID step_up
+// @Router /api/v1/user/me/2fa/step-up [POST]
+// @Summary Step Up
+// @Description Confirm a code to get the token sensitive operations such as adding beneficiaries, changing the password or phone, inviting holders and closing accounts take in the X-Step-Up-Token header
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param body body models.StepUpRequest true "Code"
+// @Success 200 {object} http.Response{data=models.StepUpResponse} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) StepUpHandler(c *gin.Context) {
+
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	if !h.mfaVerifyLimiter.Allow(auth.UserId) {
+		h.handleResponse(c, http.TooManyRequests, "too many codes tried, try again later")
+		return
+	}
+
+	var req models.StepUpRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	}
+
+	if err := h.services.UserService().VerifyMFA(c.Request.Context(), auth.UserId, req.Code); err != nil {
+		h.handleUserError(c, err)
+		return
+	}
+
+	m := map[interface{}]interface{}{
+		"step_up_user_id": auth.UserId,
+	}
+	token, err := jwt.GenToken(m, []byte(config.SigningKey), config.StepUpTokenTTL)
+	if err != nil {
+		h.handleResponse(c, http.InternalServerError, err.Error())
+		return
+	}
+
+	h.handleResponse(c, http.OK, &models.StepUpResponse{
+		StepUpToken: token,
+		ExpiresAt:   time.Now().Add(config.StepUpTokenTTL).Format(time.RFC3339),
+	})
+}
+
+// LoginMFAHandler godoc
+// @ID login_mfa
+// @Router /api/v1/auth/login/mfa [POST]
+// @Summary Login Second Step
+// @Description Exchange the MFA token of the login and an authenticator or a recovery code for the access and refresh tokens
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param body body models.MFALoginRequest true "MFA token and code"
+// @Success 201 {object} http.Response{data=models.UserWithAuth} "Created"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) LoginMFAHandler(c *gin.Context) {
+
+	var req models.MFALoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	}
+
+	claims, err := jwt.ExtractClaims(req.MFAToken, config.SigningKey)
+	if err != nil {
+		h.handleResponse(c, http.Forbidden, "mfa token is invalid or expired")
+		return
+	}
+	userID, ok := claims["mfa_user_id"].(string)
+	if !ok || claimUnix(claims["exp"]) < time.Now().Unix() {
+		h.handleResponse(c, http.Forbidden, "mfa token is invalid or expired")
+		return
+	}
+
+	if !h.mfaVerifyLimiter.Allow(userID) {
+		h.handleResponse(c, http.TooManyRequests, "too many codes tried, try again later")
+		return
+	}
+
+	if err = h.services.UserService().VerifyMFA(c.Request.Context(), userID, req.Code); err != nil {
+		h.handleUserError(c, err)
+		return
+	}
+
+	resp, err := h.services.UserService().GetUserByID(c.Request.Context(), &models.GetUserByIDRequest{UserId: userID})
+	if err != nil {
+		h.handleUserError(c, err)
+		return
+	}
+
+	m := map[interface{}]interface{}{
+		"user_id": resp.User.Guid,
+		"phone":   resp.User.Phone,
+		"role":    resp.User.Role,
+	}
+	accessToken, refreshToken, err := jwt.GenJWT(m, []byte(config.SigningKey))
+	if err != nil {
+		h.handleResponse(c, http.InternalServerError, err.Error())
+		return
+	}
+
+	h.handleResponse(c, http.Created, &models.UserWithAuth{
+		User:         resp.User,
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	})
+}
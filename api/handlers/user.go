@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"github.com/dilmurodov/online_banking/api/http"
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/jwt"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/gin-gonic/gin"
+)
+
+// UserMeGetHandler godoc
+// @Security BearerAuth
+// @ID get_me
+// @Router /api/v1/user/me [GET]
+// @Summary Get Profile
+// @Description Get the signed in user's profile
+// @Tags User
+// @Accept json
+// @Produce json
+// @Success 200 {object} http.Response{data=models.User} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) UserMeGetHandler(c *gin.Context) {
+
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	resp, err := h.services.UserService().GetUserByID(c.Request.Context(), &models.GetUserByIDRequest{
+		UserId: auth.UserId,
+	})
+	if err != nil {
+		h.handleUserError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp.User)
+}
+
+// UserMeUpdateHandler godoc
+// @Security BearerAuth
+// @ID update_me
+// @Router /api/v1/user/me [PATCH]
+// @Summary Update Profile
+// @Description Change the first and last name, the names left out stay as they are
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param body body models.UpdateProfileRequest true "Names"
+// @Success 200 {object} http.Response{data=models.User} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) UserMeUpdateHandler(c *gin.Context) {
+
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	var req models.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	}
+	req.UserID = auth.UserId
+
+	resp, err := h.services.UserService().UpdateProfile(c.Request.Context(), &req)
+	if err != nil {
+		h.handleUserError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// UserPasswordChangeHandler godoc
+// @Security BearerAuth
+// @ID change_password
+// @Router /api/v1/user/me/password [POST]
+// @Summary Change Password
+// @Description Set a new password, the current one has to be given
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param body body models.ChangePasswordRequest true "Current and new password"
+// @Param X-Step-Up-Token header string false "Step-up token, required once 2FA is on"
+// @Success 200 {object} http.Response{data=string} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) UserPasswordChangeHandler(c *gin.Context) {
+
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	var req models.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	}
+	req.UserID = auth.UserId
+	req.Phone = auth.Phone
+
+	if err := h.services.UserService().ChangePassword(c.Request.Context(), &req); err != nil {
+		h.handleUserError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, "password changed")
+}
+
+// UserPhoneChangeHandler godoc
+// @Security BearerAuth
+// @ID change_phone
+// @Router /api/v1/user/me/phone [POST]
+// @Summary Change Phone
+// @Description Send a confirmation code to the new phone number
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param body body models.ChangePhoneRequest true "New phone number"
+// @Param X-Step-Up-Token header string false "Step-up token, required once 2FA is on"
+// @Success 200 {object} http.Response{data=models.ChangePhoneResponse} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) UserPhoneChangeHandler(c *gin.Context) {
+
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	// Limit the codes a user can have sent, every one is a paid text message
+	if !h.otpSendLimiter.Allow(auth.UserId) {
+		h.handleResponse(c, http.TooManyRequests, "too many codes requested, try again later")
+		return
+	}
+
+	var req models.ChangePhoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	}
+	req.UserID = auth.UserId
+
+	resp, err := h.services.UserService().RequestPhoneChange(c.Request.Context(), &req)
+	if err != nil {
+		h.handleUserError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, resp)
+}
+
+// UserPhoneConfirmHandler godoc
+// @Security BearerAuth
+// @ID confirm_phone
+// @Router /api/v1/user/me/phone/confirm [POST]
+// @Summary Confirm Phone Change
+// @Description Confirm the new phone number with the code sent to it. The old tokens stop working, new ones are returned
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param body body models.ConfirmPhoneRequest true "Code"
+// @Success 200 {object} http.Response{data=models.UserWithAuth} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) UserPhoneConfirmHandler(c *gin.Context) {
+
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	var req models.ConfirmPhoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	}
+	req.UserID = auth.UserId
+	req.Phone = auth.Phone
+
+	resp, err := h.services.UserService().ConfirmPhoneChange(c.Request.Context(), &req)
+	if err != nil {
+		h.handleUserError(c, err)
+		return
+	}
+
+	// the phone is part of the token, so the user is signed in again with the new one
+	m := map[interface{}]interface{}{
+		"user_id": resp.Guid,
+		"phone":   resp.Phone,
+		"role":    resp.Role,
+	}
+	accessToken, refreshToken, err := jwt.GenJWT(m, []byte(config.SigningKey))
+	if err != nil {
+		h.handleResponse(c, http.InternalServerError, err.Error())
+		return
+	}
+
+	h.handleResponse(c, http.OK, &models.UserWithAuth{
+		User:         resp,
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	})
+}
+
+// UserMeDeleteHandler godoc
+// @Security BearerAuth
+// @ID delete_me
+// @Router /api/v1/user/me [DELETE]
+// @Summary Delete User
+// @Description Delete the user. The accounts they own are closed, so they have to be empty and free of pots, loans and term deposits
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param body body models.DeleteUserRequest true "Password"
+// @Success 200 {object} http.Response{data=string} "OK"
+// @Response 400 {object} http.Response{data=string} "Bad Request"
+// @Failure 500 {object} http.Response{data=string} "Server Error"
+func (h *Handler) UserMeDeleteHandler(c *gin.Context) {
+
+	authObj, ok := c.Get("auth")
+	if !ok {
+		h.handleResponse(c, http.Unauthorized, "unauthorized")
+		return
+	}
+
+	auth := authObj.(*models.HasAccessModel)
+
+	var req models.DeleteUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleResponse(c, http.BadRequest, err.Error())
+		return
+	}
+	req.UserID = auth.UserId
+	req.Phone = auth.Phone
+
+	if err := h.services.UserService().DeleteUser(c.Request.Context(), &req); err != nil {
+		h.handleUserError(c, err)
+		return
+	}
+
+	h.handleResponse(c, http.OK, "user deleted")
+}
+
+func (h *Handler) handleUserError(c *gin.Context, err error) {
+	switch err.(type) {
+	case *customerrors.InternalServerError:
+		h.handleResponse(c, http.InternalServerError, err.Error())
+	default:
+		h.handleResponse(c, http.BadRequest, err.Error())
+	}
+}
@@ -2,18 +2,34 @@ package main
 
 import (
 	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/dilmurodov/online_banking/api"
 	"github.com/dilmurodov/online_banking/api/handlers"
 	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/internal/jobs"
 	"github.com/dilmurodov/online_banking/internal/service"
+	"github.com/dilmurodov/online_banking/pkg/blob"
 	"github.com/dilmurodov/online_banking/pkg/logger"
+	"github.com/dilmurodov/online_banking/pkg/security"
+	"github.com/dilmurodov/online_banking/pkg/sms"
 	"github.com/dilmurodov/online_banking/storage/postgres"
 
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
+	runJob := flag.String("run-job", "", "run the named end-of-day job (or \"all\") for -business-date and exit")
+	businessDate := flag.String("business-date", "", "business date for -run-job in YYYY-MM-DD, yesterday by default")
+	setRole := flag.String("set-role", "", "give the user with -phone the role (customer, support, compliance or admin) and exit")
+	phone := flag.String("phone", "", "phone of the user for -set-role")
+	verifyAudit := flag.Bool("verify-audit", false, "check the hash chain of the audit log and exit, non-zero when it is broken")
+	scanAML := flag.Bool("scan-aml", false, "check the transactions committed since the last AML scan and exit")
+	flag.Parse()
+
 	cfg := config.Load()
 
 	var loggerLevel string
@@ -39,7 +55,99 @@ func main() {
 	}
 	defer strg.CloseDB()
 
-	svcs := service.NewServiceManager(cfg, log, strg)
+	smsSender, err := sms.New(cfg, log)
+	if err != nil {
+		log.Panic("sms.New", logger.Error(err))
+	}
+
+	passwordPolicy, err := security.NewPasswordPolicy(cfg)
+	if err != nil {
+		log.Panic("security.NewPasswordPolicy", logger.Error(err))
+	}
+
+	blobStore, err := blob.New(cfg)
+	if err != nil {
+		log.Panic("blob.New", logger.Error(err))
+	}
+
+	svcs := service.NewServiceManager(cfg, log, strg, smsSender, passwordPolicy, blobStore)
+
+	if *setRole != "" {
+		user, err := svcs.AdminService().SetUserRoleByPhone(context.Background(), *phone, *setRole)
+		if err != nil {
+			log.Panic("AdminService.SetUserRoleByPhone", logger.Error(err))
+		}
+		log.Info("role set", logger.String("user_id", user.Guid), logger.String("role", user.Role))
+		return
+	}
+
+	if *verifyAudit {
+		report, err := svcs.AuditService().VerifyChain(context.Background())
+		if err != nil {
+			log.Panic("AuditService.VerifyChain", logger.Error(err))
+		}
+		if !report.Valid {
+			log.Panic("audit log chain is broken", logger.Any("report", report))
+		}
+		log.Info("audit log chain is intact", logger.Any("report", report))
+		return
+	}
+
+	if *scanAML {
+		n, err := svcs.AMLService().Scan(context.Background())
+		if err != nil {
+			log.Panic("AMLService.Scan", logger.Error(err))
+		}
+		log.Info("aml scan finished", logger.Int("transactions", n))
+		return
+	}
+
+	runner, err := jobs.NewRunner(cfg, log, strg)
+	if err != nil {
+		log.Panic("jobs.NewRunner", logger.Error(err))
+	}
+	// accrual has to finish before the month is capitalized
+	runner.Register("interest_accrual", svcs.InterestService().AccrueDaily)
+	runner.Register("interest_capitalization", svcs.InterestService().Capitalize)
+	runner.Register("term_deposits", svcs.DepositService().ProcessDueDeposits)
+	runner.Register("overdraft_accrual", svcs.OverdraftService().AccrueDaily)
+	runner.Register("overdraft_charge", svcs.OverdraftService().Charge)
+	runner.Register("loan_repayments", svcs.LoanService().CollectInstallments)
+
+	if *runJob != "" {
+		day := runner.LastClosedBusinessDate()
+		if *businessDate != "" {
+			day, err = runner.ParseBusinessDate(*businessDate)
+			if err != nil {
+				log.Panic("runner.ParseBusinessDate", logger.Error(err))
+			}
+		}
+		name := *runJob
+		if name == "all" {
+			name = ""
+		}
+		if err := runner.Run(context.Background(), name, day); err != nil {
+			log.Panic("runner.Run", logger.Error(err))
+		}
+		return
+	}
+
+	if cfg.JobsEnabled {
+		go runner.Start(context.Background())
+	}
+	if cfg.AMLEnabled {
+		go svcs.AMLService().Start(context.Background())
+	}
+
+	// SIGHUP reloads the fraud rules and the sanctions list files without a restart
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	go func() {
+		for range reload {
+			_, _ = svcs.PaymentService().ReloadFraudRules()
+			_, _ = svcs.SanctionsService().Reload()
+		}
+	}()
 
 	h := handlers.NewHandler(cfg, log, svcs)
 
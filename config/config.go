@@ -34,6 +34,78 @@ type Config struct {
 	PostgresMaxConnections int32
 	DefaultOffset          string
 	DefaultLimit           string
+
+	PhoneLookupLimit int
+	// OTPSendLimit is how many one-time codes a user can request in a window
+	OTPSendLimit int
+	// SMSSender picks how text messages go out: "log" or "file"
+	SMSSender string
+	// SMSFilePath is where the file sender appends the messages
+	SMSFilePath string
+
+	BeneficiaryCoolingOffHours int
+	BeneficiaryCoolingOffLimit float64
+
+	// OverdraftMaxLimit is the largest overdraft a customer may set on an account
+	OverdraftMaxLimit float64
+
+	JobsEnabled         bool
+	JobsIntervalMinutes int
+	JobsCatchUpDays     int
+	// BusinessTimezone decides where a business date starts and ends
+	BusinessTimezone string
+
+	// PasswordMinLength is the shortest new password accepted
+	PasswordMinLength int
+	// PasswordBreachedFile lists leaked passwords that are refused, one per line, none when empty
+	PasswordBreachedFile string
+	// Argon2Memory (KiB), Argon2Time and Argon2Threads are the costs of new password hashes,
+	// older hashes are upgraded on the next login
+	Argon2Memory  int
+	Argon2Time    int
+	Argon2Threads int
+
+	// FraudRulesFile is the JSON file the fraud rules are read from, the defaults are used when empty
+	FraudRulesFile string
+
+	// SanctionsListFile is the watchlist in the OFAC SDN CSV or XML format, nothing is screened when empty
+	SanctionsListFile string
+	// SanctionsMatchThreshold is the least Jaro-Winkler score, from 0 to 1, a name is reported as a hit at
+	SanctionsMatchThreshold float64
+
+	// BlobStore picks where uploaded files are kept: "local" keeps them under BlobLocalDir
+	BlobStore    string
+	BlobLocalDir string
+
+	// KYCDocumentMaxSize is the largest document, in bytes, a user can upload
+	KYCDocumentMaxSize int64
+	// KYC caps of each tier: the largest balance of an account and the largest single deposit and
+	// withdrawal, zero is no cap. The tier of the account's owner applies.
+	KYCUnverifiedBalanceLimit    float64
+	KYCUnverifiedDepositLimit    float64
+	KYCUnverifiedWithdrawalLimit float64
+	KYCBasicBalanceLimit         float64
+	KYCBasicDepositLimit         float64
+	KYCBasicWithdrawalLimit      float64
+	KYCFullBalanceLimit          float64
+	KYCFullDepositLimit          float64
+	KYCFullWithdrawalLimit       float64
+
+	// AMLEnabled starts the worker that watches committed transactions for money laundering patterns
+	AMLEnabled         bool
+	AMLIntervalSeconds int
+	// AMLCashThreshold is the cash deposit or withdrawal that is always flagged
+	AMLCashThreshold float64
+	// AMLStructuringMargin is how far below the threshold, as a share of it, a deposit counts towards
+	// structuring, AMLStructuringCount such deposits within AMLStructuringWindowHours are flagged
+	AMLStructuringMargin      float64
+	AMLStructuringCount       int
+	AMLStructuringWindowHours int
+	// AMLRapidMinAmount is the least money that must come into an account within AMLRapidWindowHours
+	// before AMLRapidRatio of it going out again is flagged
+	AMLRapidMinAmount   float64
+	AMLRapidRatio       float64
+	AMLRapidWindowHours int
 }
 
 // Load ...
@@ -62,6 +134,56 @@ func Load() Config {
 	config.DefaultOffset = cast.ToString(getOrReturnDefaultValue("DEFAULT_OFFSET", "0"))
 	config.DefaultLimit = cast.ToString(getOrReturnDefaultValue("DEFAULT_LIMIT", "100"))
 
+	config.PhoneLookupLimit = cast.ToInt(getOrReturnDefaultValue("PHONE_LOOKUP_LIMIT", 10))
+	config.OTPSendLimit = cast.ToInt(getOrReturnDefaultValue("OTP_SEND_LIMIT", 5))
+	config.SMSSender = cast.ToString(getOrReturnDefaultValue("SMS_SENDER", "log"))
+	config.SMSFilePath = cast.ToString(getOrReturnDefaultValue("SMS_FILE_PATH", "sms.log"))
+
+	config.BeneficiaryCoolingOffHours = cast.ToInt(getOrReturnDefaultValue("BENEFICIARY_COOLING_OFF_HOURS", 24))
+	config.BeneficiaryCoolingOffLimit = cast.ToFloat64(getOrReturnDefaultValue("BENEFICIARY_COOLING_OFF_LIMIT", 1000000))
+
+	config.OverdraftMaxLimit = cast.ToFloat64(getOrReturnDefaultValue("OVERDRAFT_MAX_LIMIT", 5000))
+
+	config.JobsEnabled = cast.ToBool(getOrReturnDefaultValue("JOBS_ENABLED", true))
+	config.JobsIntervalMinutes = cast.ToInt(getOrReturnDefaultValue("JOBS_INTERVAL_MINUTES", 60))
+	config.JobsCatchUpDays = cast.ToInt(getOrReturnDefaultValue("JOBS_CATCH_UP_DAYS", 7))
+	config.BusinessTimezone = cast.ToString(getOrReturnDefaultValue("BUSINESS_TIMEZONE", "UTC"))
+
+	config.PasswordMinLength = cast.ToInt(getOrReturnDefaultValue("PASSWORD_MIN_LENGTH", 8))
+	config.PasswordBreachedFile = cast.ToString(getOrReturnDefaultValue("PASSWORD_BREACHED_FILE", ""))
+	config.Argon2Memory = cast.ToInt(getOrReturnDefaultValue("ARGON2_MEMORY", 64*1024))
+	config.Argon2Time = cast.ToInt(getOrReturnDefaultValue("ARGON2_TIME", 3))
+	config.Argon2Threads = cast.ToInt(getOrReturnDefaultValue("ARGON2_THREADS", 4))
+
+	config.FraudRulesFile = cast.ToString(getOrReturnDefaultValue("FRAUD_RULES_FILE", ""))
+
+	config.SanctionsListFile = cast.ToString(getOrReturnDefaultValue("SANCTIONS_LIST_FILE", ""))
+	config.SanctionsMatchThreshold = cast.ToFloat64(getOrReturnDefaultValue("SANCTIONS_MATCH_THRESHOLD", 0.92))
+
+	config.BlobStore = cast.ToString(getOrReturnDefaultValue("BLOB_STORE", "local"))
+	config.BlobLocalDir = cast.ToString(getOrReturnDefaultValue("BLOB_LOCAL_DIR", "blobs"))
+
+	config.KYCDocumentMaxSize = cast.ToInt64(getOrReturnDefaultValue("KYC_DOCUMENT_MAX_SIZE", 5<<20))
+	config.KYCUnverifiedBalanceLimit = cast.ToFloat64(getOrReturnDefaultValue("KYC_UNVERIFIED_BALANCE_LIMIT", 1000))
+	config.KYCUnverifiedDepositLimit = cast.ToFloat64(getOrReturnDefaultValue("KYC_UNVERIFIED_DEPOSIT_LIMIT", 500))
+	config.KYCUnverifiedWithdrawalLimit = cast.ToFloat64(getOrReturnDefaultValue("KYC_UNVERIFIED_WITHDRAWAL_LIMIT", 200))
+	config.KYCBasicBalanceLimit = cast.ToFloat64(getOrReturnDefaultValue("KYC_BASIC_BALANCE_LIMIT", 15000))
+	config.KYCBasicDepositLimit = cast.ToFloat64(getOrReturnDefaultValue("KYC_BASIC_DEPOSIT_LIMIT", 5000))
+	config.KYCBasicWithdrawalLimit = cast.ToFloat64(getOrReturnDefaultValue("KYC_BASIC_WITHDRAWAL_LIMIT", 2000))
+	config.KYCFullBalanceLimit = cast.ToFloat64(getOrReturnDefaultValue("KYC_FULL_BALANCE_LIMIT", 0))
+	config.KYCFullDepositLimit = cast.ToFloat64(getOrReturnDefaultValue("KYC_FULL_DEPOSIT_LIMIT", 0))
+	config.KYCFullWithdrawalLimit = cast.ToFloat64(getOrReturnDefaultValue("KYC_FULL_WITHDRAWAL_LIMIT", 0))
+
+	config.AMLEnabled = cast.ToBool(getOrReturnDefaultValue("AML_ENABLED", true))
+	config.AMLIntervalSeconds = cast.ToInt(getOrReturnDefaultValue("AML_INTERVAL_SECONDS", 60))
+	config.AMLCashThreshold = cast.ToFloat64(getOrReturnDefaultValue("AML_CASH_THRESHOLD", 10000))
+	config.AMLStructuringMargin = cast.ToFloat64(getOrReturnDefaultValue("AML_STRUCTURING_MARGIN", 0.1))
+	config.AMLStructuringCount = cast.ToInt(getOrReturnDefaultValue("AML_STRUCTURING_COUNT", 3))
+	config.AMLStructuringWindowHours = cast.ToInt(getOrReturnDefaultValue("AML_STRUCTURING_WINDOW_HOURS", 72))
+	config.AMLRapidMinAmount = cast.ToFloat64(getOrReturnDefaultValue("AML_RAPID_MIN_AMOUNT", 5000))
+	config.AMLRapidRatio = cast.ToFloat64(getOrReturnDefaultValue("AML_RAPID_RATIO", 0.9))
+	config.AMLRapidWindowHours = cast.ToInt(getOrReturnDefaultValue("AML_RAPID_WINDOW_HOURS", 24))
+
 	return config
 }
 
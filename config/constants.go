@@ -12,6 +12,71 @@ const (
 	AccessTokenExpiresInTime time.Duration = 1 * 24 * 60 * time.Minute
 	// RefreshTokenExpiresInTime ...
 	RefreshTokenExpiresInTime time.Duration = 30 * 24 * 60 * time.Minute
+	// PhoneRecipientTokenExpiresInTime is how long a confirmed phone transfer recipient stays valid
+	PhoneRecipientTokenExpiresInTime time.Duration = 5 * time.Minute
+	// PhoneLookupWindow is the window the phone lookup limit is counted over
+	PhoneLookupWindow time.Duration = 1 * time.Hour
+	// PaymentRequestDefaultTTL is how long a payment request stays payable when no expiry is given
+	PaymentRequestDefaultTTL time.Duration = 3 * 24 * time.Hour
+	// PaymentRequestMaxTTL is the longest expiry a payment request may have
+	PaymentRequestMaxTTL time.Duration = 30 * 24 * time.Hour
+	// PaymentRequestMemoMaxLength ...
+	PaymentRequestMemoMaxLength = 140
+	// StatementMaxRows is the most transactions a single statement export contains
+	StatementMaxRows = 10000
+	// SearchMaxLimit is the largest page the transaction search returns
+	SearchMaxLimit = 100
+	// JobStaleAfter is how long a job run may stay "running" before another instance takes it over
+	JobStaleAfter time.Duration = 1 * time.Hour
+	// LoanDefaultAfterDays is how long an installment may stay unpaid before the loan is in default
+	LoanDefaultAfterDays = 90
+	// AccountInvitationTTL is how long an invitation to hold an account can be accepted
+	AccountInvitationTTL time.Duration = 7 * 24 * time.Hour
+	// TransferApprovalTTL is how long a transfer waits for the second holder's approval
+	TransferApprovalTTL time.Duration = 24 * time.Hour
+	// PendingOperationTTL is how long a staff operation waits for another staff member's approval
+	PendingOperationTTL time.Duration = 48 * time.Hour
+	// FraudChallengeTTL is how long a challenged payment can be confirmed and retried
+	FraudChallengeTTL time.Duration = 15 * time.Minute
+	// AMLSettleDelay is how old a transaction must be before the AML worker reads it, so the database
+	// transactions that started before it have committed
+	AMLSettleDelay time.Duration = 1 * time.Minute
+	// AMLBatchSize is how many transactions the AML worker reads per database transaction
+	AMLBatchSize = 500
+	// OTPCodeTTL is how long a one-time code sent by SMS can be used
+	OTPCodeTTL time.Duration = 5 * time.Minute
+	// OTPMaxAttempts is how many wrong guesses burn a one-time code
+	OTPMaxAttempts = 5
+	// OTPSendWindow is the window the one-time code sending limit is counted over
+	OTPSendWindow time.Duration = 1 * time.Hour
+	// TOTPIssuer is the name authenticator apps show next to the codes
+	TOTPIssuer = "Online Banking"
+	// RecoveryCodesCount is how many recovery codes a user gets with 2FA
+	RecoveryCodesCount = 10
+	// MFATokenTTL is how long the second login step can be finished
+	MFATokenTTL time.Duration = 5 * time.Minute
+	// StepUpTokenTTL is how long a confirmed code unlocks the sensitive operations
+	StepUpTokenTTL time.Duration = 5 * time.Minute
+	// MFAVerifyLimit is how many 2FA codes a user can try in a window
+	MFAVerifyLimit = 5
+	// MFAVerifyWindow is the window the 2FA code limit is counted over
+	MFAVerifyWindow time.Duration = 5 * time.Minute
+	// LoginDelayAfter is how many failed logins go by before each next one has to wait
+	LoginDelayAfter = 3
+	// LoginDelayBase is the first wait, it doubles with every failure up to LoginDelayMax
+	LoginDelayBase time.Duration = 1 * time.Second
+	// LoginDelayMax caps the wait between failed logins
+	LoginDelayMax time.Duration = 1 * time.Minute
+	// LoginPhoneLockoutAfter is how many failed logins lock a phone out
+	LoginPhoneLockoutAfter = 10
+	// LoginIPLockoutAfter is how many failed logins lock an IP out, many users can share one
+	LoginIPLockoutAfter = 50
+	// LoginLockoutDuration is how long a phone or an IP stays locked out
+	LoginLockoutDuration time.Duration = 15 * time.Minute
+	// LoginFailureWindow is how long a failed login is remembered after the last one
+	LoginFailureWindow time.Duration = 1 * time.Hour
+	// BusinessDateLayout ...
+	BusinessDateLayout = "2006-01-02"
 	// SigningKey ...
 	SigningKey = "amsdklma345345345lsdmvjrbvuidj345345vyuvhsndsbdvnjshd"
 )
@@ -22,6 +87,17 @@ const (
 	TEST_ENVIRONMENT       = "test"
 	PRODUCTION_ENVIRONMENT = "release"
 )
+
+// System ledger accounts seeded by migrations
+const (
+	SystemUserID             = "00000000-0000-4000-8000-000000000001"
+	InterestExpenseAccountID = "00000000-0000-4000-8000-000000000101"
+	InterestIncomeAccountID  = "00000000-0000-4000-8000-000000000102"
+	LoanPortfolioAccountID   = "00000000-0000-4000-8000-000000000103"
+	FeeIncomeAccountID       = "00000000-0000-4000-8000-000000000104"
+	AdjustmentAccountID      = "00000000-0000-4000-8000-000000000105"
+)
+
 const TimestampFormat = "2006-01-02 15:04:05.000000"
 
 const (
@@ -30,7 +106,7 @@ const (
 
 	SYSTEM_ERROR    = "system error"
 	PASSWORD_WRONG  = "password is wrong, please check and try again"
-	DUBLICATE_PHONE = "pq: duplicate key value violates unique constraint \"users_phone_key\""
+	DUBLICATE_PHONE = "pq: duplicate key value violates unique constraint \"users_phone_deleted_at_unique\""
 
 	INVALID_PASSWORD_LENGTH = "password must not be less than 6 characters"
 )
@@ -18,6 +18,7 @@ require (
 	github.com/swaggo/swag v1.8.12
 	go.uber.org/zap v1.24.0
 	golang.org/x/crypto v0.8.0
+	golang.org/x/text v0.9.0
 )
 
 require (
@@ -57,7 +58,6 @@ require (
 	golang.org/x/net v0.9.0 // indirect
 	golang.org/x/sys v0.7.0 // indirect
 	golang.org/x/term v0.7.0 // indirect
-	golang.org/x/text v0.9.0 // indirect
 	golang.org/x/tools v0.7.0 // indirect
 	google.golang.org/protobuf v1.28.1 // indirect
 	gopkg.in/yaml.v2 v2.4.0 // indirect
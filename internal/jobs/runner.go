@@ -0,0 +1,155 @@
+// Package jobs runs end-of-day jobs once per business date.
+// Each run is claimed in the job_runs table, so several API instances can share the schedule.
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/pkg/logger"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/dilmurodov/online_banking/storage"
+)
+
+// JobFunc processes a single business date. It must be safe to call again for a date it already
+// partly processed, because a failed run is retried.
+type JobFunc func(ctx context.Context, businessDate time.Time) error
+
+type job struct {
+	name string
+	fn   JobFunc
+}
+
+type Runner struct {
+	cfg  config.Config
+	log  logger.LoggerI
+	strg storage.StorageI
+	loc  *time.Location
+	jobs []job
+}
+
+func NewRunner(cfg config.Config, log logger.LoggerI, strg storage.StorageI) (*Runner, error) {
+	loc, err := time.LoadLocation(cfg.BusinessTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business timezone %q: %w", cfg.BusinessTimezone, err)
+	}
+
+	return &Runner{
+		cfg:  cfg,
+		log:  log,
+		strg: strg,
+		loc:  loc,
+	}, nil
+}
+
+// Register adds a job. Jobs run in registration order and a failure stops the later ones for that date.
+func (r *Runner) Register(name string, fn JobFunc) {
+	r.jobs = append(r.jobs, job{name: name, fn: fn})
+}
+
+// ParseBusinessDate parses a YYYY-MM-DD date in the business timezone
+func (r *Runner) ParseBusinessDate(date string) (time.Time, error) {
+	return time.ParseInLocation(config.BusinessDateLayout, date, r.loc)
+}
+
+// LastClosedBusinessDate returns yesterday in the business timezone
+func (r *Runner) LastClosedBusinessDate() time.Time {
+	now := time.Now().In(r.loc)
+	return time.Date(now.Year(), now.Month(), now.Day()-1, 0, 0, 0, 0, r.loc)
+}
+
+// Run runs the named job, or every job when the name is empty, for one business date
+func (r *Runner) Run(ctx context.Context, name string, businessDate time.Time) error {
+	for _, j := range r.jobs {
+		if name != "" && j.name != name {
+			continue
+		}
+		if err := r.runJob(ctx, j, businessDate); err != nil {
+			return err
+		}
+		if name != "" {
+			return nil
+		}
+	}
+
+	if name != "" {
+		return fmt.Errorf("unknown job %q", name)
+	}
+	return nil
+}
+
+// Start runs the jobs for the last closed business dates every interval until the context is done.
+// Dates already done are skipped, so the catch-up window only costs a lookup per job and date.
+func (r *Runner) Start(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(r.cfg.JobsIntervalMinutes) * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		r.catchUp(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *Runner) catchUp(ctx context.Context) {
+	last := r.LastClosedBusinessDate()
+
+	for days := r.cfg.JobsCatchUpDays - 1; days >= 0; days-- {
+		businessDate := last.AddDate(0, 0, -days)
+		if err := r.Run(ctx, "", businessDate); err != nil {
+			// later dates depend on earlier ones, so wait for the next tick
+			return
+		}
+	}
+}
+
+func (r *Runner) runJob(ctx context.Context, j job, businessDate time.Time) error {
+	date := businessDate.Format(config.BusinessDateLayout)
+
+	started, err := r.strg.Job().StartJobRun(ctx, &models.StartJobRunRequest{
+		Job:          j.name,
+		BusinessDate: date,
+		StaleBefore:  time.Now().Add(-config.JobStaleAfter),
+	})
+	if err != nil {
+		r.log.Error("---RunJob->StartJobRun--->", logger.String("job", j.name), logger.String("business_date", date), logger.Error(err))
+		return err
+	}
+	if !started {
+		// a run still in progress elsewhere must finish before the jobs that depend on it
+		status, err := r.strg.Job().GetJobRunStatus(ctx, j.name, date)
+		if err != nil {
+			return err
+		}
+		if status != models.JobStatusDone {
+			return fmt.Errorf("job %s for %s is %s", j.name, date, status)
+		}
+		return nil
+	}
+
+	r.log.Info("---RunJob--->", logger.String("job", j.name), logger.String("business_date", date))
+
+	finish := &models.FinishJobRunRequest{
+		Job:          j.name,
+		BusinessDate: date,
+	}
+
+	jobErr := j.fn(ctx, businessDate)
+	if jobErr != nil {
+		r.log.Error("---RunJob--->", logger.String("job", j.name), logger.String("business_date", date), logger.Error(jobErr))
+		finish.Error = jobErr.Error()
+	}
+
+	if err := r.strg.Job().FinishJobRun(ctx, finish); err != nil {
+		r.log.Error("---RunJob->FinishJobRun--->", logger.String("job", j.name), logger.String("business_date", date), logger.Error(err))
+		return err
+	}
+
+	return jobErr
+}
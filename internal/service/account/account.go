@@ -2,7 +2,9 @@ package account
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/dilmurodov/online_banking/config"
 	"github.com/dilmurodov/online_banking/pkg/logger"
 	"github.com/dilmurodov/online_banking/pkg/models"
 )
@@ -10,6 +12,18 @@ import (
 func (self *Service) CreateAccount(ctx context.Context, req *models.CreateAccountRequest) (resp *models.Account, err error) {
 	self.log.Info("---CreateAccount--->", logger.Any("req", req))
 
+	if req.Product == "" {
+		req.Product = models.ProductCurrent
+	}
+	// these accounts are opened together with the deposit or pot they hold
+	if req.Product == models.ProductTermDeposit || req.Product == models.ProductPot {
+		return nil, fmt.Errorf("%s accounts can't be opened directly", req.Product)
+	}
+	if _, err = self.strg.Interest().GetProductByCode(ctx, req.Product); err != nil {
+		self.log.Error("---CreateAccount->GetProductByCode--->", logger.Any("err", err))
+		return nil, err
+	}
+
 	resp, err = self.strg.Account().CreateAccount(ctx, req)
 	if err != nil {
 		self.log.Error("---CreateAccount--->", logger.Any("err", err))
@@ -66,3 +80,37 @@ func (s *Service) GetAccountTransactionByID(ctx context.Context, req *models.Get
 
 	return resp, nil
 }
+
+func (self *Service) SearchTransactions(ctx context.Context, req *models.SearchTransactionsRequest) (resp *models.SearchTransactionsResponse, err error) {
+	self.log.Info("---SearchTransactions--->", logger.Any("req", req))
+
+	switch req.Type {
+	case "", "debit", "credit":
+	default:
+		return nil, fmt.Errorf("type must be debit or credit")
+	}
+
+	switch req.Status {
+	case "", models.TransactionStatusPending, models.TransactionStatusCompleted:
+	default:
+		return nil, fmt.Errorf("status must be %s or %s", models.TransactionStatusPending, models.TransactionStatusCompleted)
+	}
+
+	if req.MinAmount < 0 || req.MaxAmount < 0 || (req.MaxAmount > 0 && req.MinAmount > req.MaxAmount) {
+		return nil, fmt.Errorf("invalid amount range")
+	}
+
+	if req.Limit <= 0 || req.Limit > config.SearchMaxLimit {
+		req.Limit = config.SearchMaxLimit
+	}
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+
+	resp, err = self.strg.TxRepo().SearchTransactions(ctx, req)
+	if err != nil {
+		self.log.Error("---SearchTransactions--->", logger.Error(err))
+		return nil, err
+	}
+	return resp, nil
+}
@@ -3,13 +3,18 @@ package account
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/helper"
 	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/dilmurodov/online_banking/internal/service/servicetest"
 	mock_storage "github.com/dilmurodov/online_banking/storage/mock"
 	"github.com/dilmurodov/online_banking/storage/postgres"
 	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
@@ -23,8 +28,11 @@ func TestAccount_CreateAccount(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	r.NoError(err)
 
+	product := mock.NewRows([]string{"code", "name", "annual_rate", "day_count", "created_at", "updated_at"}).AddRow("current", "Current account", "0", "ACT/365", "2021-01-01", "2021-01-01")
+	mock.ExpectQuery(`^SELECT (.+?) FROM products`).WithArgs("current").WillReturnRows(product)
+
 	rows := mock.NewRows([]string{"guid"}).AddRow("TestUserID")
-	mock.ExpectPrepare("INSERT INTO accounts").ExpectQuery().WithArgs("TestUserID", 0.0).WillReturnRows(rows)
+	mock.ExpectPrepare("INSERT INTO accounts").ExpectQuery().WithArgs("TestUserID", 0.0, "current").WillReturnRows(rows)
 
 	repo := mock_storage.NewMockAccountRepoI(ctrl)
 	s := NewService(config.Config{}, zap.NewNop(), postgres.NewStore(db))
@@ -55,7 +63,7 @@ func TestAccount_GetAccountByID(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	r.NoError(err)
 
-	rows := mock.NewRows([]string{"guid", "user_id", "balance", "created_at", "updated_at"}).AddRow("TestUserID", "TestUserID", 0.0, "2021-01-01", "2021-01-01")
+	rows := mock.NewRows([]string{"guid", "user_id", "balance", "product", "accrued_interest", "overdraft_limit", "created_at", "updated_at", "status", "closed_at"}).AddRow("TestUserID", "TestUserID", 0.0, "current", "0", 0.0, "2021-01-01", "2021-01-01", "active", nil)
 	mock.ExpectQuery(`^SELECT (.+?) FROM accounts * `).WithArgs("TestUserID").WillReturnRows(rows)
 
 	repo := mock_storage.NewMockAccountRepoI(ctrl)
@@ -88,7 +96,7 @@ func TestAccount_GetAccountsByUserID(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	r.NoError(err)
 
-	rows := mock.NewRows([]string{"guid", "user_id", "balance", "created_at", "updated_at", "count"}).AddRow("TestUserID", "TestUserID", 0.0, "2021-01-01", "2021-01-01", 1)
+	rows := mock.NewRows([]string{"guid", "user_id", "balance", "product", "accrued_interest", "overdraft_limit", "created_at", "updated_at", "status", "closed_at", "role", "count"}).AddRow("TestUserID", "TestUserID", 0.0, "current", "0", 0.0, "2021-01-01", "2021-01-01", "active", nil, "owner", 1)
 	mock.ExpectQuery(`^SELECT (.+?) FROM accounts * `).WithArgs("TestUserID").WillReturnRows(rows)
 
 	repo := mock_storage.NewMockAccountRepoI(ctrl)
@@ -125,8 +133,8 @@ func TestAccount_GetAccountTransactions(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	r.NoError(err)
 
-	rows := mock.NewRows([]string{"guid", "account_id", "transaction_amount", "transaction_type", "recipient_id", "created_at", "count", "approved", "done", "done_timestampe"}).AddRow("TestTransactionID", "TestUserID", 0.0, "TestType", "TestUserID", "2021-01-01", 1, true, true, "2021-01-01")
-	mock.ExpectQuery(`^SELECT (.+?) FROM transactions * `).WithArgs("TestUserID").WillReturnRows(rows)
+	rows := mock.NewRows([]string{"guid", "account_id", "transaction_amount", "transaction_type", "recipient_id", "description", "reference", "created_at", "count", "approved", "done", "done_timestampe"}).AddRow("TestTransactionID", "TestUserID", 0.0, "TestType", "TestUserID", "", "", "2021-01-01", 1, true, true, "2021-01-01")
+	mock.ExpectQuery(`^SELECT (.+?) FROM transactions * `).WithArgs("TestUserID", 11, 0).WillReturnRows(rows)
 
 	repo := mock_storage.NewMockTxRepoI(ctrl)
 
@@ -171,7 +179,7 @@ func TestAccount_GetAccountTransactionByID(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	r.NoError(err)
 
-	rows := mock.NewRows([]string{"guid", "account_id", "transaction_amount", "transaction_type", "recipient_id", "created_at", "approved", "done", "done_timestampe"}).AddRow("TestTransactionID", "TestUserID", 0.0, "TestType", "TestUserID", "2021-01-01", true, true, "2021-01-01")
+	rows := mock.NewRows([]string{"guid", "account_id", "transaction_amount", "transaction_type", "recipient_id", "description", "reference", "created_at", "approved", "done", "done_timestampe"}).AddRow("TestTransactionID", "TestUserID", 0.0, "TestType", "TestUserID", "", "", "2021-01-01", true, true, "2021-01-01")
 	mock.ExpectQuery(`^SELECT (.+?) FROM transactions * `).WithArgs("TestTransactionID", "TestUserID").WillReturnRows(rows)
 
 	repo := mock_storage.NewMockTxRepoI(ctrl)
@@ -204,3 +212,192 @@ func TestAccount_GetAccountTransactionByID(t *testing.T) {
 
 	r.NoError(err)
 }
+
+func TestAccount_SearchTransactions(t *testing.T) {
+
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db, mock, err := sqlmock.New()
+	r.NoError(err)
+
+	facets := mock.NewRows([]string{"transaction_type", "to_char", "grouping", "count"}).
+		AddRow("debit", nil, 0, 2).
+		AddRow(nil, "2023-01", 1, 2)
+	mock.ExpectQuery(`^SELECT (.+?) FROM transactions t (.+) GROUPING SETS`).
+		WithArgs("TestUserID", pq.Array([]string{"owner", "co_owner", "signer", "viewer"}), "coffee", "coffee", "debit").
+		WillReturnRows(facets)
+
+	rows := mock.NewRows([]string{"guid", "account_id", "transaction_amount", "transaction_type", "recipient_id", "description", "reference", "created_at", "approved", "done", "done_timestamp", "count"}).
+		AddRow("TestTransactionID", "TestAccountID", 10.0, "debit", "TestRecipientID", "coffee", "", "2023-01-01", true, true, "2023-01-01", 2).
+		AddRow("TestTransactionID2", "TestAccountID", 15.0, "debit", "TestRecipientID", "coffee beans", "", "2023-01-02", true, true, "2023-01-02", 2)
+	mock.ExpectQuery(`^SELECT (.+?) FROM transactions t (.+) LIMIT \$6 OFFSET \$7`).
+		WithArgs("TestUserID", pq.Array([]string{"owner", "co_owner", "signer", "viewer"}), "coffee", "coffee", "debit", config.SearchMaxLimit, 0).
+		WillReturnRows(rows)
+
+	repo := mock_storage.NewMockTxRepoI(ctrl)
+	s := NewService(config.Config{}, zap.NewNop(), postgres.NewStore(db))
+
+	t.Run("SUCCESS", func(t *testing.T) {
+		in := &models.SearchTransactionsRequest{
+			UserID: "TestUserID",
+			Query:  "coffee",
+			Type:   "debit",
+		}
+
+		repo.EXPECT().SearchTransactions(context.Background(), in).Return(&models.SearchTransactionsResponse{}, nil).Times(1).AnyTimes()
+		resp, err := s.SearchTransactions(context.Background(), in)
+		r.NoError(err)
+		r.Equal(2, resp.Count)
+		r.Len(resp.Facets.ByType, 1)
+		r.Equal("2023-01", resp.Facets.ByMonth[0].Key)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("INVALID_RANGE", func(t *testing.T) {
+		_, err := s.SearchTransactions(context.Background(), &models.SearchTransactionsRequest{
+			UserID:    "TestUserID",
+			MinAmount: 100,
+			MaxAmount: 10,
+		})
+		r.Error(err)
+	})
+}
+
+func TestAccount_GetAccountTransactionsByCursor(t *testing.T) {
+
+	r := require.New(t)
+
+	db, mock, err := sqlmock.New()
+	r.NoError(err)
+
+	s := NewService(config.Config{}, zap.NewNop(), postgres.NewStore(db))
+	columns := []string{"guid", "account_id", "transaction_amount", "transaction_type", "recipient_id", "description", "reference", "created_at", "count", "approved", "done", "done_timestamp"}
+
+	t.Run("NEXT_PAGE", func(t *testing.T) {
+		cursor := helper.EncodeCursor(helper.Cursor{CreatedAt: "2021-01-01T00:00:00Z", ID: "TestTransactionID1"})
+
+		rows := mock.NewRows(columns).
+			AddRow("TestTransactionID2", "TestAccountID", 1.0, "debit", "TestRecipientID", "", "", "2021-01-02T00:00:00Z", 0, true, true, nil).
+			AddRow("TestTransactionID3", "TestAccountID", 1.0, "debit", "TestRecipientID", "", "", "2021-01-03T00:00:00Z", 0, true, true, nil).
+			AddRow("TestTransactionID4", "TestAccountID", 1.0, "debit", "TestRecipientID", "", "", "2021-01-04T00:00:00Z", 0, true, true, nil)
+		mock.ExpectQuery(`^SELECT (.+?) FROM transactions WHERE (.+) \(created_at, guid\) > \(\$2, \$3\) ORDER BY created_at ASC, guid ASC LIMIT \$4$`).
+			WithArgs("TestAccountID", "2021-01-01T00:00:00Z", "TestTransactionID1", 3).
+			WillReturnRows(rows)
+
+		resp, err := s.GetAccountTransactions(context.Background(), &models.GetTransactionsByAccountIDRequest{
+			AccountID: "TestAccountID",
+			Cursor:    cursor,
+			Limit:     2,
+		})
+		r.NoError(err)
+		r.Len(resp.Transactions, 2)
+
+		next, err := helper.DecodeCursor(resp.NextCursor)
+		r.NoError(err)
+		r.Equal("TestTransactionID3", next.ID)
+
+		prev, err := helper.DecodeCursor(resp.PrevCursor)
+		r.NoError(err)
+		r.Equal("TestTransactionID2", prev.ID)
+		r.True(prev.Backward)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("INVALID_CURSOR", func(t *testing.T) {
+		_, err := s.GetAccountTransactions(context.Background(), &models.GetTransactionsByAccountIDRequest{
+			AccountID: "TestAccountID",
+			Cursor:    "not-a-cursor",
+		})
+		r.IsType(&customerrors.InvalidCursorError{}, err)
+	})
+}
+
+func TestAccount_AcceptInvitation(t *testing.T) {
+	r := require.New(t)
+
+	db, mock, err := sqlmock.New()
+	r.NoError(err)
+
+	s := NewService(config.Config{}, zap.NewNop(), postgres.NewStore(db))
+
+	invitationColumns := []string{"guid", "account_id", "invited_by", "user_id", "role", "status", "expires_at", "responded_at", "created_at"}
+	expiresAt := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+
+	servicetest.ExpectAuditBegin(mock)
+	mock.ExpectQuery(`^SELECT (.+) FROM account_invitations WHERE guid = \$1 FOR UPDATE`).WithArgs("TestInvitationID").
+		WillReturnRows(sqlmock.NewRows(invitationColumns).AddRow("TestInvitationID", "TestAccountID", "TestOwnerID", "TestUserID", "signer", "pending", expiresAt, nil, "2021-01-01"))
+	mock.ExpectExec(`^INSERT INTO account_holders`).WithArgs("TestAccountID", "TestUserID", "signer").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`^UPDATE account_invitations SET status`).WithArgs("TestInvitationID", "accepted").WillReturnResult(sqlmock.NewResult(0, 1))
+	servicetest.ExpectAuditEvent(mock, models.AuditEventInvitationAnswered, "TestUserID", "", []byte(`{"account_id":"TestAccountID","role":"signer"}`), "TestUserID", models.AuditResourceInvitation, "TestInvitationID", "",
+		`{"status":"pending"}`, `{"status":"accepted"}`, "", sqlmock.AnyArg(), sqlmock.AnyArg())
+	mock.ExpectCommit()
+
+	t.Run("SUCCESS", func(t *testing.T) {
+		resp, err := s.AcceptInvitation(context.Background(), &models.InvitationByIDRequest{
+			ID:     "TestInvitationID",
+			UserID: "TestUserID",
+		})
+		r.NoError(err)
+		r.Equal(models.InvitationStatusAccepted, resp.Status)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	// only the invited user can answer the invitation
+	servicetest.ExpectAuditBegin(mock)
+	mock.ExpectQuery(`^SELECT (.+) FROM account_invitations WHERE guid = \$1 FOR UPDATE`).WithArgs("TestInvitationID").
+		WillReturnRows(sqlmock.NewRows(invitationColumns).AddRow("TestInvitationID", "TestAccountID", "TestOwnerID", "TestUserID", "signer", "pending", expiresAt, nil, "2021-01-01"))
+	mock.ExpectRollback()
+
+	t.Run("OTHER_USER", func(t *testing.T) {
+		_, err := s.AcceptInvitation(context.Background(), &models.InvitationByIDRequest{
+			ID:     "TestInvitationID",
+			UserID: "TestOtherUserID",
+		})
+		r.Equal(&customerrors.InvitationNotFoundError{Guid: "TestInvitationID"}, err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+}
+
+func TestAccount_DeleteAccountHolder(t *testing.T) {
+	r := require.New(t)
+
+	strg, mock := servicetest.NewStore(t)
+	s := NewService(config.Config{}, zap.NewNop(), strg)
+
+	mock.ExpectQuery(`^SELECT h.role`).WithArgs("TestAccountID", "TestOwnerID").WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("owner"))
+	servicetest.ExpectAuditBegin(mock)
+	mock.ExpectQuery(`FOR UPDATE OF h$`).WithArgs("TestAccountID", "TestUserID").WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("signer"))
+	mock.ExpectExec(`^DELETE FROM account_holders`).WithArgs("TestAccountID", "TestUserID").WillReturnResult(sqlmock.NewResult(0, 1))
+	servicetest.ExpectAuditEvent(mock, models.AuditEventAccountHolderRemoved, "TestUserID", "", []byte("null"), "TestOwnerID", models.AuditResourceAccount, "TestAccountID", "",
+		`{"role":"signer"}`, nil, "", sqlmock.AnyArg(), sqlmock.AnyArg())
+	mock.ExpectCommit()
+
+	t.Run("SUCCESS", func(t *testing.T) {
+		err := s.DeleteAccountHolder(context.Background(), &models.AccountHolderRequest{
+			AccountID: "TestAccountID",
+			HolderID:  "TestUserID",
+			UserID:    "TestOwnerID",
+		})
+		r.NoError(err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	// the owner can't be removed, nothing is written to the audit log
+	mock.ExpectQuery(`^SELECT h.role`).WithArgs("TestAccountID", "TestOwnerID").WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("owner"))
+	servicetest.ExpectAuditBegin(mock)
+	mock.ExpectQuery(`FOR UPDATE OF h$`).WithArgs("TestAccountID", "TestOwnerID").WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("owner"))
+	mock.ExpectExec(`^DELETE FROM account_holders`).WithArgs("TestAccountID", "TestOwnerID").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	t.Run("OWNER", func(t *testing.T) {
+		err := s.DeleteAccountHolder(context.Background(), &models.AccountHolderRequest{
+			AccountID: "TestAccountID",
+			HolderID:  "TestOwnerID",
+			UserID:    "TestOwnerID",
+		})
+		r.Equal(&customerrors.AccountHolderNotFoundError{UserID: "TestOwnerID"}, err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+}
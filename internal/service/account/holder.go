@@ -0,0 +1,371 @@
+package account
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/interest"
+	"github.com/dilmurodov/online_banking/pkg/logger"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/dilmurodov/online_banking/storage"
+)
+
+// CheckPermission checks that the user holds the account in a role that allows the permission.
+// A user who doesn't hold the account gets AccountNotFoundError, so account ids can't be probed.
+func CheckPermission(ctx context.Context, strg storage.StorageI, accountID, userID, permission string) error {
+	role, err := strg.AccountHolder().GetAccountRole(ctx, accountID, userID)
+	if err != nil {
+		return err
+	}
+	if role == "" {
+		return &customerrors.AccountNotFoundError{Guid: accountID}
+	}
+	if !models.AccountRoleAllows(role, permission) {
+		return &customerrors.AccountPermissionError{Guid: accountID}
+	}
+	return nil
+}
+
+func (s *Service) GetAccountHolders(ctx context.Context, req *models.AccountHolderRequest) (*models.GetAccountHoldersResponse, error) {
+	if err := CheckPermission(ctx, s.strg, req.AccountID, req.UserID, models.AccountPermissionView); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.strg.AccountHolder().GetAccountHolders(ctx, req.AccountID)
+	if err != nil {
+		s.log.Error("---GetAccountHolders--->", logger.Error(err))
+		return nil, err
+	}
+	return resp, nil
+}
+
+// UpdateAccountHolder changes the role of a holder, the owner's role can't be changed
+func (s *Service) UpdateAccountHolder(ctx context.Context, req *models.AccountHolderRequest) (*models.GetAccountHoldersResponse, error) {
+	s.log.Info("---UpdateAccountHolder--->", logger.Any("req", req))
+
+	if !models.IsInvitableAccountRole(req.Role) {
+		return nil, fmt.Errorf("role must be one of %s, %s, %s", models.AccountRoleCoOwner, models.AccountRoleSigner, models.AccountRoleViewer)
+	}
+	if err := CheckPermission(ctx, s.strg, req.AccountID, req.UserID, models.AccountPermissionManageHolders); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.strg.Audit().BeginTx(ctx)
+	if err != nil {
+		s.log.Error("---UpdateAccountHolder->BeginTx--->", logger.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	role, err := s.strg.AccountHolder().GetAccountRoleForUpdate(ctx, tx, req.AccountID, req.HolderID)
+	if err != nil {
+		s.log.Error("---UpdateAccountHolder->GetAccountRoleForUpdate--->", logger.Error(err))
+		return nil, err
+	}
+
+	if err = s.strg.AccountHolder().UpdateAccountHolder(ctx, tx, req.AccountID, req.HolderID, req.Role); err != nil {
+		s.log.Error("---UpdateAccountHolder--->", logger.Error(err))
+		return nil, err
+	}
+
+	err = s.strg.Audit().CreateAuditEvent(ctx, tx, &models.AuditEvent{
+		EventType:    models.AuditEventAccountHolderChanged,
+		ActorID:      req.UserID,
+		UserID:       req.HolderID,
+		ResourceType: models.AuditResourceAccount,
+		ResourceID:   req.AccountID,
+		Before:       map[string]interface{}{"role": role},
+		After:        map[string]interface{}{"role": req.Role},
+	})
+	if err != nil {
+		s.log.Error("---UpdateAccountHolder->CreateAuditEvent--->", logger.Error(err))
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.log.Error("---UpdateAccountHolder->Commit--->", logger.Error(err))
+		return nil, err
+	}
+
+	return s.strg.AccountHolder().GetAccountHolders(ctx, req.AccountID)
+}
+
+// DeleteAccountHolder removes a holder from the account. Any holder but the owner may leave on their own.
+func (s *Service) DeleteAccountHolder(ctx context.Context, req *models.AccountHolderRequest) error {
+	s.log.Info("---DeleteAccountHolder--->", logger.Any("req", req))
+
+	permission := models.AccountPermissionManageHolders
+	if req.HolderID == req.UserID {
+		permission = models.AccountPermissionView
+	}
+	if err := CheckPermission(ctx, s.strg, req.AccountID, req.UserID, permission); err != nil {
+		return err
+	}
+
+	tx, err := s.strg.Audit().BeginTx(ctx)
+	if err != nil {
+		s.log.Error("---DeleteAccountHolder->BeginTx--->", logger.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	role, err := s.strg.AccountHolder().GetAccountRoleForUpdate(ctx, tx, req.AccountID, req.HolderID)
+	if err != nil {
+		s.log.Error("---DeleteAccountHolder->GetAccountRoleForUpdate--->", logger.Error(err))
+		return err
+	}
+
+	if err = s.strg.AccountHolder().DeleteAccountHolder(ctx, tx, req.AccountID, req.HolderID); err != nil {
+		s.log.Error("---DeleteAccountHolder--->", logger.Error(err))
+		return err
+	}
+
+	err = s.strg.Audit().CreateAuditEvent(ctx, tx, &models.AuditEvent{
+		EventType:    models.AuditEventAccountHolderRemoved,
+		ActorID:      req.UserID,
+		UserID:       req.HolderID,
+		ResourceType: models.AuditResourceAccount,
+		ResourceID:   req.AccountID,
+		Before:       map[string]interface{}{"role": role},
+	})
+	if err != nil {
+		s.log.Error("---DeleteAccountHolder->CreateAuditEvent--->", logger.Error(err))
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.log.Error("---DeleteAccountHolder->Commit--->", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// SetDualApproval sets the amount above which a transfer needs a second holder's approval
+func (s *Service) SetDualApproval(ctx context.Context, req *models.SetDualApprovalRequest) (*models.GetAccountHoldersResponse, error) {
+	s.log.Info("---SetDualApproval--->", logger.Any("req", req))
+
+	threshold, err := interest.ParseAmount(req.Threshold)
+	if err != nil || threshold.Sign() < 0 {
+		return nil, fmt.Errorf("threshold must not be negative and have at most %d decimal places", interest.PostingScale)
+	}
+	if err = CheckPermission(ctx, s.strg, req.AccountID, req.UserID, models.AccountPermissionManageHolders); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.strg.Audit().BeginTx(ctx)
+	if err != nil {
+		s.log.Error("---SetDualApproval->BeginTx--->", logger.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// the account stays locked, so the threshold read for the audit log is the one replaced
+	if _, err = s.strg.Account().GetAccountForUpdate(ctx, tx, req.AccountID); err != nil {
+		s.log.Error("---SetDualApproval->GetAccountForUpdate--->", logger.Error(err))
+		return nil, err
+	}
+	policy, err := s.strg.AccountHolder().GetApprovalPolicy(ctx, req.AccountID, req.UserID)
+	if err != nil {
+		s.log.Error("---SetDualApproval->GetApprovalPolicy--->", logger.Error(err))
+		return nil, err
+	}
+
+	formatted := interest.FormatDecimal(threshold, interest.PostingScale)
+	if err = s.strg.AccountHolder().SetDualApprovalThreshold(ctx, tx, req.AccountID, formatted); err != nil {
+		s.log.Error("---SetDualApproval--->", logger.Error(err))
+		return nil, err
+	}
+
+	err = s.strg.Audit().CreateAuditEvent(ctx, tx, &models.AuditEvent{
+		EventType:    models.AuditEventDualApprovalChanged,
+		ActorID:      req.UserID,
+		UserID:       req.UserID,
+		ResourceType: models.AuditResourceAccount,
+		ResourceID:   req.AccountID,
+		Before:       map[string]interface{}{"dual_approval_threshold": policy.Threshold},
+		After:        map[string]interface{}{"dual_approval_threshold": formatted},
+	})
+	if err != nil {
+		s.log.Error("---SetDualApproval->CreateAuditEvent--->", logger.Error(err))
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.log.Error("---SetDualApproval->Commit--->", logger.Error(err))
+		return nil, err
+	}
+
+	return s.strg.AccountHolder().GetAccountHolders(ctx, req.AccountID)
+}
+
+// CreateInvitation invites the user with the phone to hold the account in the role
+func (s *Service) CreateInvitation(ctx context.Context, req *models.CreateInvitationRequest) (*models.AccountInvitation, error) {
+	s.log.Info("---CreateInvitation--->", logger.Any("req", req))
+
+	if !models.IsInvitableAccountRole(req.Role) {
+		return nil, fmt.Errorf("role must be one of %s, %s, %s", models.AccountRoleCoOwner, models.AccountRoleSigner, models.AccountRoleViewer)
+	}
+	if err := CheckPermission(ctx, s.strg, req.AccountID, req.UserID, models.AccountPermissionManageHolders); err != nil {
+		return nil, err
+	}
+
+	account, err := s.strg.Account().GetAccountByID(ctx, &models.GetAccountByIDRequest{ID: req.AccountID})
+	if err != nil {
+		return nil, err
+	}
+	if account.Product != models.ProductCurrent && account.Product != models.ProductSavings {
+		return nil, fmt.Errorf("only current and savings accounts can be held jointly")
+	}
+
+	invitee, err := s.strg.User().GetUserPasswordByPhone(ctx, req.Phone)
+	if err != nil {
+		return nil, err
+	}
+	role, err := s.strg.AccountHolder().GetAccountRole(ctx, req.AccountID, invitee.Guid)
+	if err != nil {
+		return nil, err
+	}
+	if role != "" {
+		return nil, fmt.Errorf("the user already holds this account")
+	}
+
+	tx, err := s.strg.Audit().BeginTx(ctx)
+	if err != nil {
+		s.log.Error("---CreateInvitation->BeginTx--->", logger.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	resp, err := s.strg.AccountHolder().CreateInvitation(ctx, tx, &models.AccountInvitation{
+		AccountID: req.AccountID,
+		InvitedBy: req.UserID,
+		UserID:    invitee.Guid,
+		Role:      req.Role,
+		ExpiresAt: time.Now().Add(config.AccountInvitationTTL).Format(config.DatabaseTimeLayout),
+	})
+	if err != nil {
+		s.log.Error("---CreateInvitation--->", logger.Error(err))
+		return nil, err
+	}
+
+	err = s.strg.Audit().CreateAuditEvent(ctx, tx, &models.AuditEvent{
+		EventType:    models.AuditEventInvitationCreated,
+		ActorID:      req.UserID,
+		UserID:       invitee.Guid,
+		ResourceType: models.AuditResourceInvitation,
+		ResourceID:   resp.ID,
+		Details:      map[string]interface{}{"account_id": req.AccountID},
+		After:        map[string]interface{}{"status": resp.Status, "role": resp.Role},
+	})
+	if err != nil {
+		s.log.Error("---CreateInvitation->CreateAuditEvent--->", logger.Error(err))
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.log.Error("---CreateInvitation->Commit--->", logger.Error(err))
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (s *Service) GetInvitations(ctx context.Context, userID string) (*models.GetInvitationsResponse, error) {
+	resp, err := s.strg.AccountHolder().GetInvitations(ctx, userID)
+	if err != nil {
+		s.log.Error("---GetInvitations--->", logger.Error(err))
+		return nil, err
+	}
+	return resp, nil
+}
+
+// AcceptInvitation makes the invited user a holder of the account
+func (s *Service) AcceptInvitation(ctx context.Context, req *models.InvitationByIDRequest) (*models.AccountInvitation, error) {
+	s.log.Info("---AcceptInvitation--->", logger.Any("req", req))
+	return s.answerInvitation(ctx, req, models.InvitationStatusAccepted)
+}
+
+func (s *Service) DeclineInvitation(ctx context.Context, req *models.InvitationByIDRequest) (*models.AccountInvitation, error) {
+	s.log.Info("---DeclineInvitation--->", logger.Any("req", req))
+	return s.answerInvitation(ctx, req, models.InvitationStatusDeclined)
+}
+
+// RevokeInvitation withdraws an invitation that wasn't answered yet
+func (s *Service) RevokeInvitation(ctx context.Context, req *models.InvitationByIDRequest) (*models.AccountInvitation, error) {
+	s.log.Info("---RevokeInvitation--->", logger.Any("req", req))
+
+	if err := CheckPermission(ctx, s.strg, req.AccountID, req.UserID, models.AccountPermissionManageHolders); err != nil {
+		return nil, err
+	}
+	return s.answerInvitation(ctx, req, models.InvitationStatusRevoked)
+}
+
+// answerInvitation moves a pending invitation to the status, adding the holder when it is accepted.
+// The invitee answers their own invitations, revoking is checked against the account instead.
+func (s *Service) answerInvitation(ctx context.Context, req *models.InvitationByIDRequest, status string) (*models.AccountInvitation, error) {
+	tx, err := s.strg.Audit().BeginTx(ctx)
+	if err != nil {
+		s.log.Error("---AnswerInvitation->BeginTx--->", logger.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	invitation, err := s.strg.AccountHolder().GetInvitationForUpdate(ctx, tx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if status == models.InvitationStatusRevoked {
+		if invitation.AccountID != req.AccountID {
+			return nil, &customerrors.InvitationNotFoundError{Guid: req.ID}
+		}
+	} else if invitation.UserID != req.UserID {
+		return nil, &customerrors.InvitationNotFoundError{Guid: req.ID}
+	}
+
+	if invitation.Status != models.InvitationStatusPending {
+		return nil, &customerrors.InvitationNotFoundError{Guid: req.ID}
+	}
+	expiresAt, err := time.Parse(time.RFC3339, invitation.ExpiresAt)
+	if err == nil && !expiresAt.After(time.Now()) && status != models.InvitationStatusRevoked {
+		return nil, &customerrors.InvitationNotFoundError{Guid: req.ID}
+	}
+
+	if status == models.InvitationStatusAccepted {
+		err = s.strg.AccountHolder().AddAccountHolder(ctx, tx, invitation.AccountID, invitation.UserID, invitation.Role)
+		if err != nil {
+			s.log.Error("---AnswerInvitation->AddAccountHolder--->", logger.Error(err))
+			return nil, err
+		}
+	}
+	if err = s.strg.AccountHolder().UpdateInvitationStatus(ctx, tx, invitation.ID, status); err != nil {
+		s.log.Error("---AnswerInvitation->UpdateInvitationStatus--->", logger.Error(err))
+		return nil, err
+	}
+
+	err = s.strg.Audit().CreateAuditEvent(ctx, tx, &models.AuditEvent{
+		EventType:    models.AuditEventInvitationAnswered,
+		ActorID:      req.UserID,
+		UserID:       invitation.UserID,
+		ResourceType: models.AuditResourceInvitation,
+		ResourceID:   invitation.ID,
+		Details:      map[string]interface{}{"account_id": invitation.AccountID, "role": invitation.Role},
+		Before:       map[string]interface{}{"status": invitation.Status},
+		After:        map[string]interface{}{"status": status},
+	})
+	if err != nil {
+		s.log.Error("---AnswerInvitation->CreateAuditEvent--->", logger.Error(err))
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.log.Error("---AnswerInvitation->Commit--->", logger.Error(err))
+		return nil, err
+	}
+
+	invitation.Status = status
+	return invitation, nil
+}
@@ -15,6 +15,16 @@ type ServiceI interface {
 	GetAccountByID(ctx context.Context, req *models.GetAccountByIDRequest) (resp *models.Account, err error)
 	GetAccountTransactions(ctx context.Context, req *models.GetTransactionsByAccountIDRequest) (resp *models.GetTransactionsByAccountIDResponse, err error)
 	GetAccountTransactionByID(ctx context.Context, req *models.GetTransactionByIDRequest) (resp *models.Transaction, err error)
+	SearchTransactions(ctx context.Context, req *models.SearchTransactionsRequest) (resp *models.SearchTransactionsResponse, err error)
+	GetAccountHolders(ctx context.Context, req *models.AccountHolderRequest) (*models.GetAccountHoldersResponse, error)
+	UpdateAccountHolder(ctx context.Context, req *models.AccountHolderRequest) (*models.GetAccountHoldersResponse, error)
+	DeleteAccountHolder(ctx context.Context, req *models.AccountHolderRequest) error
+	SetDualApproval(ctx context.Context, req *models.SetDualApprovalRequest) (*models.GetAccountHoldersResponse, error)
+	CreateInvitation(ctx context.Context, req *models.CreateInvitationRequest) (*models.AccountInvitation, error)
+	GetInvitations(ctx context.Context, userID string) (*models.GetInvitationsResponse, error)
+	AcceptInvitation(ctx context.Context, req *models.InvitationByIDRequest) (*models.AccountInvitation, error)
+	DeclineInvitation(ctx context.Context, req *models.InvitationByIDRequest) (*models.AccountInvitation, error)
+	RevokeInvitation(ctx context.Context, req *models.InvitationByIDRequest) (*models.AccountInvitation, error)
 }
 
 type Service struct {
@@ -0,0 +1,340 @@
+package accountstatus
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/internal/service/account"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/helper"
+	"github.com/dilmurodov/online_banking/pkg/interest"
+	"github.com/dilmurodov/online_banking/pkg/logger"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/dilmurodov/online_banking/pkg/util"
+)
+
+const reasonMaxLength = 255
+
+// CloseAccount closes an account for good at its owner's request.
+// The accrued interest is paid first and what is left on the balance goes to the payout account.
+func (s *Service) CloseAccount(ctx context.Context, req *models.CloseAccountRequest) (*models.CloseAccountResponse, error) {
+	s.log.Info("---CloseAccount--->", logger.Any("req", req))
+
+	req.Reason = strings.TrimSpace(req.Reason)
+	if utf8.RuneCountInString(req.Reason) > reasonMaxLength {
+		return nil, fmt.Errorf("reason must not be longer than %d characters", reasonMaxLength)
+	}
+
+	if err := account.CheckPermission(ctx, s.strg, req.AccountID, req.UserID, models.AccountPermissionManageHolders); err != nil {
+		return nil, err
+	}
+	if req.PayoutAccountID != "" {
+		if !util.IsValidUUID(req.PayoutAccountID) {
+			return nil, fmt.Errorf("invalid payout account id")
+		}
+		if req.PayoutAccountID == req.AccountID {
+			return nil, fmt.Errorf("payout account must differ from the account being closed")
+		}
+		// the balance can only be paid out to an account the user could move it on from
+		if err := account.CheckPermission(ctx, s.strg, req.PayoutAccountID, req.UserID, models.AccountPermissionTransact); err != nil {
+			return nil, err
+		}
+	}
+
+	tx, err := s.strg.Audit().BeginTx(ctx)
+	if err != nil {
+		s.log.Error("---CloseAccount->BeginTx--->", logger.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	resp, err := s.closeAccount(ctx, tx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.log.Error("---CloseAccount->Commit--->", logger.Error(err))
+		return nil, err
+	}
+
+	resp.Account, err = s.strg.Account().GetAccountByID(ctx, &models.GetAccountByIDRequest{ID: req.AccountID})
+	if err != nil {
+		s.log.Error("---CloseAccount->GetAccountByID--->", logger.Error(err))
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// CloseUserAccounts closes every account the user owns in the caller's transaction,
+// the closures are audited so it has to be begun with Audit().BeginTx.
+// Nothing can be paid out, so an account that still holds money keeps the user from leaving.
+func (s *Service) CloseUserAccounts(ctx context.Context, tx *sql.Tx, userID, reason string) error {
+	s.log.Info("---CloseUserAccounts--->", logger.String("user_id", userID))
+
+	// read in the transaction, so an account opened or shared meanwhile isn't left behind
+	accounts, err := s.strg.Account().GetOwnedAccountsForUpdate(ctx, tx, userID)
+	if err != nil {
+		s.log.Error("---CloseUserAccounts->GetOwnedAccountsForUpdate--->", logger.Error(err))
+		return err
+	}
+
+	for _, a := range accounts {
+		if a.Status == models.AccountStatusClosed {
+			continue
+		}
+		// term deposits and pots close with their own products
+		if a.Product != models.ProductCurrent && a.Product != models.ProductSavings {
+			continue
+		}
+		_, err = s.closeAccount(ctx, tx, &models.CloseAccountRequest{
+			AccountID: a.ID,
+			UserID:    userID,
+			Reason:    reason,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// closeAccount pays the interest, moves the balance out and marks the account closed in the transaction
+func (s *Service) closeAccount(ctx context.Context, tx *sql.Tx, req *models.CloseAccountRequest) (*models.CloseAccountResponse, error) {
+	acc, err := s.strg.Account().GetAccountForUpdate(ctx, tx, req.AccountID)
+	if err != nil {
+		s.log.Error("---CloseAccount->GetAccountForUpdate--->", logger.Error(err))
+		return nil, err
+	}
+	if acc.Product != models.ProductCurrent && acc.Product != models.ProductSavings {
+		return nil, fmt.Errorf("only current and savings accounts can be closed")
+	}
+	if acc.Status != models.AccountStatusActive {
+		return nil, &customerrors.AccountStatusError{Guid: acc.ID, Status: acc.Status}
+	}
+
+	// read under the lock, so nothing is opened on the account between the check and the close
+	blockers, err := s.strg.Account().GetClosingBlockers(ctx, tx, acc.ID)
+	if err != nil {
+		s.log.Error("---CloseAccount->GetClosingBlockers--->", logger.Error(err))
+		return nil, err
+	}
+	if reason := blockers.Reason(); reason != "" {
+		return nil, &customerrors.AccountCloseError{Guid: acc.ID, Reason: reason}
+	}
+
+	resp := &models.CloseAccountResponse{
+		Transactions: make([]*models.Transaction, 0),
+	}
+
+	// the stored balance may have drifted off whole cents, what is paid out is the balance to the cent
+	balance, err := interest.ParseDecimal(strconv.FormatFloat(acc.Balance, 'f', -1, 64))
+	if err != nil {
+		s.log.Error("---CloseAccount->ParseDecimal--->", logger.Error(err))
+		return nil, err
+	}
+	balance = interest.Round(balance, interest.PostingScale)
+
+	paid, err := s.payAccruedInterest(ctx, tx, acc, resp)
+	if err != nil {
+		return nil, err
+	}
+	balance.Add(balance, paid)
+
+	switch balance.Sign() {
+	case -1:
+		return nil, &customerrors.AccountCloseError{Guid: acc.ID, Reason: "the balance is negative"}
+	case 1:
+		if req.PayoutAccountID == "" {
+			return nil, &customerrors.AccountCloseError{Guid: acc.ID, Reason: "a payout account is required for the remaining balance"}
+		}
+		posted, err := s.payment.PostTransfer(ctx, tx, &models.PostTransferRequest{
+			FromAccountID:     acc.ID,
+			ToAccountID:       req.PayoutAccountID,
+			Amount:            interest.FormatDecimal(balance, interest.PostingScale),
+			Description:       "Balance of the closed account",
+			CustomerInitiated: true,
+		})
+		if err != nil {
+			s.log.Error("---CloseAccount->PostTransfer--->", logger.Error(err))
+			return nil, err
+		}
+		resp.Transactions = append(resp.Transactions, posted.Transactions...)
+	}
+
+	err = s.strg.Account().UpdateAccountStatus(ctx, tx, &models.AccountStatusChange{
+		AccountID:  acc.ID,
+		FromStatus: models.AccountStatusActive,
+		ToStatus:   models.AccountStatusClosed,
+		ActorID:    req.UserID,
+		ActorType:  models.AccountStatusActorUser,
+		Reason:     req.Reason,
+	})
+	if err != nil {
+		s.log.Error("---CloseAccount->UpdateAccountStatus--->", logger.Error(err))
+		return nil, err
+	}
+
+	err = s.strg.Audit().CreateAuditEvent(ctx, tx, &models.AuditEvent{
+		EventType:    models.AuditEventAccountStatusChanged,
+		ActorID:      req.UserID,
+		UserID:       acc.UserID,
+		ResourceType: models.AuditResourceAccount,
+		ResourceID:   acc.ID,
+		IP:           req.IP,
+		Details:      map[string]interface{}{"reason": req.Reason, "payout_account_id": req.PayoutAccountID},
+		Before:       map[string]interface{}{"status": models.AccountStatusActive, "balance": interest.FormatDecimal(balance, interest.PostingScale)},
+		After:        map[string]interface{}{"status": models.AccountStatusClosed, "balance": "0.00"},
+	})
+	if err != nil {
+		s.log.Error("---CloseAccount->CreateAuditEvent--->", logger.Error(err))
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// payAccruedInterest capitalizes the whole cents of the interest accrued since the last month end
+func (s *Service) payAccruedInterest(ctx context.Context, tx *sql.Tx, acc *models.Account, resp *models.CloseAccountResponse) (*big.Rat, error) {
+	accrued, err := interest.ParseDecimal(acc.AccruedInterest)
+	if err != nil {
+		s.log.Error("---CloseAccount->ParseDecimal--->", logger.Error(err))
+		return nil, err
+	}
+
+	amount := interest.RoundDown(accrued, interest.PostingScale)
+	if amount.Sign() <= 0 {
+		return new(big.Rat), nil
+	}
+
+	// the closing day is the period, it can't clash with a month end capitalization
+	today := helper.BusinessToday(s.cfg.BusinessTimezone)
+	posted, err := s.payment.PostTransfer(ctx, tx, &models.PostTransferRequest{
+		FromAccountID: config.InterestExpenseAccountID,
+		ToAccountID:   acc.ID,
+		Amount:        interest.FormatDecimal(amount, interest.PostingScale),
+		Description:   "Interest on closing",
+		Reference:     "INT-" + today.Format("2006-01-02"),
+	})
+	if err != nil {
+		s.log.Error("---CloseAccount->PostTransfer--->", logger.Error(err))
+		return nil, err
+	}
+
+	err = s.strg.Interest().CreateCapitalization(ctx, tx, &models.InterestCapitalization{
+		AccountID:     acc.ID,
+		Period:        today.Format(config.BusinessDateLayout),
+		Amount:        interest.FormatDecimal(amount, interest.PostingScale),
+		TransactionID: posted.Transactions[1].ID,
+	})
+	if err != nil {
+		s.log.Error("---CloseAccount->CreateCapitalization--->", logger.Error(err))
+		return nil, err
+	}
+	resp.Transactions = append(resp.Transactions, posted.Transactions...)
+
+	return amount, nil
+}
+
+// FreezeAccount blocks debits from the account, credits still go through
+func (s *Service) FreezeAccount(ctx context.Context, req *models.FreezeAccountRequest) (*models.Account, error) {
+	s.log.Info("---FreezeAccount--->", logger.Any("req", req))
+
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" {
+		return nil, fmt.Errorf("reason is required")
+	}
+
+	tx, err := s.strg.Audit().BeginTx(ctx)
+	if err != nil {
+		s.log.Error("---FreezeAccount->BeginTx--->", logger.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err = s.changeStatus(ctx, tx, req, models.AccountStatusActive, models.AccountStatusFrozen); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.log.Error("---FreezeAccount->Commit--->", logger.Error(err))
+		return nil, err
+	}
+
+	return s.strg.Account().GetAccountByID(ctx, &models.GetAccountByIDRequest{ID: req.AccountID})
+}
+
+// UnfreezeAccount lets a frozen account make payments again in the caller's transaction,
+// it is made once another staff member approves it. The change is audited, the transaction
+// has to be begun with Audit().BeginTx
+func (s *Service) UnfreezeAccount(ctx context.Context, tx *sql.Tx, req *models.FreezeAccountRequest) error {
+	s.log.Info("---UnfreezeAccount--->", logger.Any("req", req))
+
+	req.Reason = strings.TrimSpace(req.Reason)
+
+	return s.changeStatus(ctx, tx, req, models.AccountStatusFrozen, models.AccountStatusActive)
+}
+
+// changeStatus makes a staff status change, the current status has to be from
+func (s *Service) changeStatus(ctx context.Context, tx *sql.Tx, req *models.FreezeAccountRequest, from, to string) error {
+	if utf8.RuneCountInString(req.Reason) > reasonMaxLength {
+		return fmt.Errorf("reason must not be longer than %d characters", reasonMaxLength)
+	}
+
+	acc, err := s.strg.Account().GetAccountForUpdate(ctx, tx, req.AccountID)
+	if err != nil {
+		s.log.Error("---ChangeStatus->GetAccountForUpdate--->", logger.Error(err))
+		return err
+	}
+	if acc.Status != from {
+		return &customerrors.AccountStatusError{Guid: acc.ID, Status: acc.Status}
+	}
+
+	err = s.strg.Account().UpdateAccountStatus(ctx, tx, &models.AccountStatusChange{
+		AccountID:  acc.ID,
+		FromStatus: from,
+		ToStatus:   to,
+		ActorID:    req.ActorID,
+		ActorType:  models.AccountStatusActorAdmin,
+		Reason:     req.Reason,
+	})
+	if err != nil {
+		s.log.Error("---ChangeStatus->UpdateAccountStatus--->", logger.Error(err))
+		return err
+	}
+
+	err = s.strg.Audit().CreateAuditEvent(ctx, tx, &models.AuditEvent{
+		EventType:    models.AuditEventAccountStatusChanged,
+		ActorID:      req.ActorID,
+		UserID:       acc.UserID,
+		ResourceType: models.AuditResourceAccount,
+		ResourceID:   acc.ID,
+		IP:           req.IP,
+		Details:      map[string]interface{}{"reason": req.Reason},
+		Before:       map[string]interface{}{"status": from},
+		After:        map[string]interface{}{"status": to},
+	})
+	if err != nil {
+		s.log.Error("---ChangeStatus->CreateAuditEvent--->", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (s *Service) GetStatusHistory(ctx context.Context, accountID string) (*models.GetAccountStatusHistoryResponse, error) {
+	resp, err := s.strg.Account().GetAccountStatusHistory(ctx, accountID)
+	if err != nil {
+		s.log.Error("---GetStatusHistory--->", logger.Error(err))
+		return nil, err
+	}
+	return resp, nil
+}
@@ -0,0 +1,187 @@
+package accountstatus
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/internal/service/payment"
+	"github.com/dilmurodov/online_banking/internal/service/servicetest"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestService(t *testing.T) (*Service, sqlmock.Sqlmock) {
+	strg, mock := servicetest.NewStore(t)
+
+	return NewService(
+		config.Config{BusinessTimezone: "UTC"},
+		zap.NewNop(),
+		strg,
+		payment.NewService(config.Config{}, zap.NewNop(), strg),
+	), mock
+}
+
+func TestAccountStatus_CloseAccount(t *testing.T) {
+	r := require.New(t)
+
+	s, mock := newTestService(t)
+
+	payoutAccountID := "00000000-0000-4000-8000-0000000000aa"
+
+	owner := sqlmock.NewRows([]string{"role"}).AddRow("owner")
+	mock.ExpectQuery(`^SELECT h.role FROM account_holders`).WithArgs("TestAccountID", "TestUserID").WillReturnRows(owner)
+	mock.ExpectQuery(`^SELECT h.role FROM account_holders`).WithArgs(payoutAccountID, "TestUserID").WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("owner"))
+
+	servicetest.ExpectAuditBegin(mock)
+	mock.ExpectQuery(`^SELECT (.+?) FROM accounts WHERE guid = \$1 AND deleted_at = 0 FOR UPDATE`).WithArgs("TestAccountID").
+		WillReturnRows(sqlmock.NewRows([]string{"guid", "user_id", "balance", "product", "accrued_interest", "overdraft_limit", "status", "closed_at"}).
+			AddRow("TestAccountID", "TestUserID", 10.5, "savings", "0.5312000000", 0.0, "active", nil))
+	mock.ExpectQuery(`^SELECT \(SELECT count\(1\) FROM pots`).WithArgs("TestAccountID").
+		WillReturnRows(sqlmock.NewRows([]string{"pots", "loans", "deposits", "pending"}).AddRow(0, 0, 0, 0))
+
+	// the whole cents of the accrued interest are paid before the balance moves out
+	servicetest.ExpectPosting(mock, config.InterestExpenseAccountID, "TestAccountID", 0.53, "0.53", "Interest on closing", sqlmock.AnyArg())
+	mock.ExpectQuery(`^INSERT INTO interest_capitalizations`).WithArgs("TestAccountID", sqlmock.AnyArg(), "0.53", "TestCreditID").
+		WillReturnRows(sqlmock.NewRows([]string{"guid"}).AddRow("TestCapitalizationID"))
+	mock.ExpectExec(`^UPDATE accounts SET accrued_interest`).WithArgs("0.53", "TestAccountID").WillReturnResult(sqlmock.NewResult(0, 1))
+	// paying the balance out is the customer's transfer, it is screened
+	mock.ExpectQuery(`^SELECT EXISTS \(\s*SELECT 1\s*FROM account_holders ah\s*JOIN sanctions_hits`).WithArgs(pq.Array([]string{"TestAccountID", payoutAccountID})).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectQuery(`^SELECT (.+?) FROM accounts\s+WHERE guid=\$1`).WithArgs(payoutAccountID).
+		WillReturnRows(sqlmock.NewRows([]string{"guid", "user_id", "balance", "product", "accrued_interest", "overdraft_limit", "created_at", "updated_at", "status", "closed_at"}).
+			AddRow(payoutAccountID, "TestUserID", 0.0, "current", "0", 0.0, "2023-01-01", "2023-01-01", "active", nil))
+	mock.ExpectQuery(`^SELECT u.kyc_tier`).WithArgs(payoutAccountID).WillReturnRows(sqlmock.NewRows([]string{"kyc_tier"}).AddRow(models.KYCTierFull))
+	servicetest.ExpectPosting(mock, "TestAccountID", payoutAccountID, 11.03, "11.03", "Balance of the closed account", "")
+
+	mock.ExpectExec(`^UPDATE accounts SET`).WithArgs("TestAccountID", "active", "closed").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`^INSERT INTO account_status_history`).WithArgs("TestAccountID", "active", "closed", "TestUserID", "user", "moving banks").
+		WillReturnRows(sqlmock.NewRows([]string{"guid", "created_at"}).AddRow("TestChangeID", "2023-05-15"))
+	mock.ExpectQuery(`^SELECT hash FROM audit_events`).WillReturnRows(sqlmock.NewRows([]string{"hash"}))
+	mock.ExpectQuery(`^INSERT INTO audit_events`).
+		WithArgs(models.AuditEventAccountStatusChanged, "TestUserID", "10.0.0.1", []byte(`{"payout_account_id":"`+payoutAccountID+`","reason":"moving banks"}`), "TestUserID",
+			models.AuditResourceAccount, "TestAccountID", "", `{"balance":"11.03","status":"active"}`, `{"balance":"0.00","status":"closed"}`,
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"guid", "seq"}).AddRow("TestEventID", 1))
+	mock.ExpectCommit()
+
+	mock.ExpectQuery(`^SELECT (.+?) FROM accounts`).WithArgs("TestAccountID").
+		WillReturnRows(sqlmock.NewRows([]string{"guid", "user_id", "balance", "product", "accrued_interest", "overdraft_limit", "created_at", "updated_at", "status", "closed_at"}).
+			AddRow("TestAccountID", "TestUserID", 0.0, "savings", "0.0012000000", 0.0, "2023-01-01", "2023-05-15", "closed", "2023-05-15"))
+
+	t.Run("SUCCESS", func(t *testing.T) {
+		resp, err := s.CloseAccount(context.Background(), &models.CloseAccountRequest{
+			AccountID:       "TestAccountID",
+			UserID:          "TestUserID",
+			PayoutAccountID: payoutAccountID,
+			Reason:          " moving banks ",
+			IP:              "10.0.0.1",
+		})
+		r.NoError(err)
+		r.Equal(models.AccountStatusClosed, resp.Account.Status)
+		r.Len(resp.Transactions, 4)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("PAYOUT_SANCTIONED", func(t *testing.T) {
+		s, mock := newTestService(t)
+
+		mock.ExpectQuery(`^SELECT h.role FROM account_holders`).WithArgs("TestAccountID", "TestUserID").WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("owner"))
+		mock.ExpectQuery(`^SELECT h.role FROM account_holders`).WithArgs(payoutAccountID, "TestUserID").WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("owner"))
+		servicetest.ExpectAuditBegin(mock)
+		mock.ExpectQuery(`^SELECT (.+?) FROM accounts WHERE guid = \$1 AND deleted_at = 0 FOR UPDATE`).WithArgs("TestAccountID").
+			WillReturnRows(sqlmock.NewRows([]string{"guid", "user_id", "balance", "product", "accrued_interest", "overdraft_limit", "status", "closed_at"}).
+				AddRow("TestAccountID", "TestUserID", 10.0, "current", "0", 0.0, "active", nil))
+		mock.ExpectQuery(`^SELECT \(SELECT count\(1\) FROM pots`).WithArgs("TestAccountID").
+			WillReturnRows(sqlmock.NewRows([]string{"pots", "loans", "deposits", "pending"}).AddRow(0, 0, 0, 0))
+		mock.ExpectQuery(`^SELECT EXISTS \(\s*SELECT 1\s*FROM account_holders ah\s*JOIN sanctions_hits`).WithArgs(pq.Array([]string{"TestAccountID", payoutAccountID})).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectRollback()
+
+		_, err := s.CloseAccount(context.Background(), &models.CloseAccountRequest{
+			AccountID:       "TestAccountID",
+			UserID:          "TestUserID",
+			PayoutAccountID: payoutAccountID,
+		})
+		r.IsType(&customerrors.SanctionsBlockedError{}, err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("BLOCKED_BY_POT", func(t *testing.T) {
+		s, mock := newTestService(t)
+
+		mock.ExpectQuery(`^SELECT h.role FROM account_holders`).WithArgs("TestAccountID", "TestUserID").WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("owner"))
+		servicetest.ExpectAuditBegin(mock)
+		mock.ExpectQuery(`^SELECT (.+?) FROM accounts WHERE guid = \$1 AND deleted_at = 0 FOR UPDATE`).WithArgs("TestAccountID").
+			WillReturnRows(sqlmock.NewRows([]string{"guid", "user_id", "balance", "product", "accrued_interest", "overdraft_limit", "status", "closed_at"}).
+				AddRow("TestAccountID", "TestUserID", 0.0, "current", "0", 0.0, "active", nil))
+		mock.ExpectQuery(`^SELECT \(SELECT count\(1\) FROM pots`).WithArgs("TestAccountID").
+			WillReturnRows(sqlmock.NewRows([]string{"pots", "loans", "deposits", "pending"}).AddRow(1, 0, 0, 0))
+		mock.ExpectRollback()
+
+		_, err := s.CloseAccount(context.Background(), &models.CloseAccountRequest{
+			AccountID: "TestAccountID",
+			UserID:    "TestUserID",
+		})
+		r.Equal(&customerrors.AccountCloseError{Guid: "TestAccountID", Reason: "the account has open pots"}, err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("DRIFTED_BALANCE", func(t *testing.T) {
+		s, mock := newTestService(t)
+
+		// a balance off whole cents is rounded to them, it still has to be paid out
+		mock.ExpectQuery(`^SELECT h.role FROM account_holders`).WithArgs("TestAccountID", "TestUserID").WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("owner"))
+		servicetest.ExpectAuditBegin(mock)
+		mock.ExpectQuery(`^SELECT (.+?) FROM accounts WHERE guid = \$1 AND deleted_at = 0 FOR UPDATE`).WithArgs("TestAccountID").
+			WillReturnRows(sqlmock.NewRows([]string{"guid", "user_id", "balance", "product", "accrued_interest", "overdraft_limit", "status", "closed_at"}).
+				AddRow("TestAccountID", "TestUserID", 0.1+0.2, "current", "0", 0.0, "active", nil))
+		mock.ExpectQuery(`^SELECT \(SELECT count\(1\) FROM pots`).WithArgs("TestAccountID").
+			WillReturnRows(sqlmock.NewRows([]string{"pots", "loans", "deposits", "pending"}).AddRow(0, 0, 0, 0))
+		mock.ExpectRollback()
+
+		_, err := s.CloseAccount(context.Background(), &models.CloseAccountRequest{
+			AccountID: "TestAccountID",
+			UserID:    "TestUserID",
+		})
+		r.Equal(&customerrors.AccountCloseError{Guid: "TestAccountID", Reason: "a payout account is required for the remaining balance"}, err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+}
+
+func TestAccountStatus_CloseUserAccounts(t *testing.T) {
+	r := require.New(t)
+
+	s, mock := newTestService(t)
+
+	// the owned accounts are read locked in the caller's transaction, the closed ones and other products are left alone
+	servicetest.ExpectAuditBegin(mock)
+	mock.ExpectQuery(`^SELECT (.+?) FROM accounts a\s+JOIN account_holders h(.+?)FOR UPDATE OF a`).WithArgs("TestUserID", models.AccountRoleOwner).
+		WillReturnRows(sqlmock.NewRows([]string{"guid", "product", "status"}).
+			AddRow("TestClosedID", "current", "closed").
+			AddRow("TestDepositID", "term_deposit", "active").
+			AddRow("TestAccountID", "current", "active"))
+	mock.ExpectQuery(`^SELECT (.+?) FROM accounts WHERE guid = \$1 AND deleted_at = 0 FOR UPDATE`).WithArgs("TestAccountID").
+		WillReturnRows(sqlmock.NewRows([]string{"guid", "user_id", "balance", "product", "accrued_interest", "overdraft_limit", "status", "closed_at"}).
+			AddRow("TestAccountID", "TestUserID", 0.0, "current", "0", 0.0, "active", nil))
+	mock.ExpectQuery(`^SELECT \(SELECT count\(1\) FROM pots`).WithArgs("TestAccountID").
+		WillReturnRows(sqlmock.NewRows([]string{"pots", "loans", "deposits", "pending"}).AddRow(0, 0, 0, 0))
+	mock.ExpectExec(`^UPDATE accounts SET`).WithArgs("TestAccountID", "active", "closed").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`^INSERT INTO account_status_history`).WithArgs("TestAccountID", "active", "closed", "TestUserID", "user", "user deleted").
+		WillReturnRows(sqlmock.NewRows([]string{"guid", "created_at"}).AddRow("TestChangeID", "2023-05-15"))
+	servicetest.ExpectAuditEvent(mock)
+	mock.ExpectCommit()
+
+	t.Run("SUCCESS", func(t *testing.T) {
+		tx, err := s.strg.Audit().BeginTx(context.Background())
+		r.NoError(err)
+
+		r.NoError(s.CloseUserAccounts(context.Background(), tx, "TestUserID", "user deleted"))
+		r.NoError(tx.Commit())
+		r.NoError(mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,36 @@
+package accountstatus
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/internal/service/payment"
+	"github.com/dilmurodov/online_banking/pkg/logger"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/dilmurodov/online_banking/storage"
+)
+
+type ServiceI interface {
+	CloseAccount(ctx context.Context, req *models.CloseAccountRequest) (*models.CloseAccountResponse, error)
+	CloseUserAccounts(ctx context.Context, tx *sql.Tx, userID, reason string) error
+	FreezeAccount(ctx context.Context, req *models.FreezeAccountRequest) (*models.Account, error)
+	UnfreezeAccount(ctx context.Context, tx *sql.Tx, req *models.FreezeAccountRequest) error
+	GetStatusHistory(ctx context.Context, accountID string) (*models.GetAccountStatusHistoryResponse, error)
+}
+
+type Service struct {
+	cfg     config.Config
+	log     logger.LoggerI
+	strg    storage.StorageI
+	payment payment.ServiceI
+}
+
+func NewService(cfg config.Config, log logger.LoggerI, strg storage.StorageI, paymentService payment.ServiceI) *Service {
+	return &Service{
+		cfg:     cfg,
+		log:     log,
+		strg:    strg,
+		payment: paymentService,
+	}
+}
@@ -0,0 +1,270 @@
+package admin
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/interest"
+	"github.com/dilmurodov/online_banking/pkg/logger"
+	"github.com/dilmurodov/online_banking/pkg/models"
+)
+
+const commentMaxLength = 255
+
+func (s *Service) SearchUsers(ctx context.Context, req *models.SearchUsersRequest) (*models.SearchUsersResponse, error) {
+	req.Query = strings.TrimSpace(req.Query)
+	if req.Role != "" && !models.IsValidRole(req.Role) {
+		return nil, fmt.Errorf("invalid role")
+	}
+	if req.Limit <= 0 || req.Limit > config.SearchMaxLimit {
+		req.Limit = config.SearchMaxLimit
+	}
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+
+	resp, err := s.strg.Admin().SearchUsers(ctx, req)
+	if err != nil {
+		s.log.Error("---SearchUsers--->", logger.Error(err))
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetUser returns the user with every account they hold
+func (s *Service) GetUser(ctx context.Context, userID string) (*models.AdminUserResponse, error) {
+	user, err := s.strg.User().GetUserByID(ctx, &models.GetUserByIDRequest{UserId: userID})
+	if err != nil {
+		s.log.Error("---GetUser->GetUserByID--->", logger.Error(err))
+		return nil, err
+	}
+
+	accounts, err := s.strg.Account().GetAccountsByUserID(ctx, &models.GetAccountsByUserIDRequest{UserID: userID})
+	if err != nil {
+		s.log.Error("---GetUser->GetAccountsByUserID--->", logger.Error(err))
+		return nil, err
+	}
+
+	return &models.AdminUserResponse{User: user.User, Accounts: accounts.Accounts}, nil
+}
+
+// SetUserRole gives the user a staff role or takes it away, nobody can change their own role
+func (s *Service) SetUserRole(ctx context.Context, req *models.SetUserRoleRequest) (*models.User, error) {
+	s.log.Info("---SetUserRole--->", logger.Any("req", req))
+
+	if !models.IsValidRole(req.Role) {
+		return nil, fmt.Errorf("invalid role")
+	}
+	if req.UserID == req.ActorID {
+		return nil, fmt.Errorf("you can't change your own role")
+	}
+
+	user, err := s.strg.User().GetUserByID(ctx, &models.GetUserByIDRequest{UserId: req.UserID})
+	if err != nil {
+		s.log.Error("---SetUserRole->GetUserByID--->", logger.Error(err))
+		return nil, err
+	}
+	if user.User.Role == req.Role {
+		return user.User, nil
+	}
+
+	tx, err := s.strg.Audit().BeginTx(ctx)
+	if err != nil {
+		s.log.Error("---SetUserRole->BeginTx--->", logger.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err = s.strg.User().SetUserRole(ctx, tx, req.UserID, req.Role); err != nil {
+		s.log.Error("---SetUserRole->SetUserRole--->", logger.Error(err))
+		return nil, err
+	}
+
+	err = s.strg.Audit().CreateAuditEvent(ctx, tx, &models.AuditEvent{
+		EventType:    models.AuditEventRoleChanged,
+		ActorID:      req.ActorID,
+		UserID:       req.UserID,
+		ResourceType: models.AuditResourceUser,
+		ResourceID:   req.UserID,
+		IP:           req.IP,
+		Before:       map[string]interface{}{"role": user.User.Role},
+		After:        map[string]interface{}{"role": req.Role},
+	})
+	if err != nil {
+		s.log.Error("---SetUserRole->CreateAuditEvent--->", logger.Error(err))
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.log.Error("---SetUserRole->Commit--->", logger.Error(err))
+		return nil, err
+	}
+
+	user.User.Role = req.Role
+	return user.User, nil
+}
+
+// SetUserRoleByPhone is how the first admin is made from the command line, there is no actor then
+func (s *Service) SetUserRoleByPhone(ctx context.Context, phone, role string) (*models.User, error) {
+	user, err := s.strg.User().GetUserPasswordByPhone(ctx, phone)
+	if err != nil {
+		s.log.Error("---SetUserRoleByPhone->GetUserPasswordByPhone--->", logger.Error(err))
+		return nil, err
+	}
+
+	return s.SetUserRole(ctx, &models.SetUserRoleRequest{UserID: user.Guid, Role: role})
+}
+
+func (s *Service) SearchAccounts(ctx context.Context, req *models.SearchAccountsRequest) (*models.SearchAccountsResponse, error) {
+	req.Query = strings.TrimSpace(req.Query)
+	switch req.Status {
+	case "", models.AccountStatusActive, models.AccountStatusFrozen, models.AccountStatusClosed:
+	default:
+		return nil, fmt.Errorf("invalid status")
+	}
+	if req.Limit <= 0 || req.Limit > config.SearchMaxLimit {
+		req.Limit = config.SearchMaxLimit
+	}
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+
+	resp, err := s.strg.Admin().SearchAccounts(ctx, req)
+	if err != nil {
+		s.log.Error("---SearchAccounts--->", logger.Error(err))
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *Service) GetAccount(ctx context.Context, accountID string) (*models.Account, error) {
+	acc, err := s.strg.Account().GetAccountByID(ctx, &models.GetAccountByIDRequest{ID: accountID})
+	if err != nil {
+		// the account lookup reports a missing account as a missing user
+		var notFound *customerrors.UserNotFoundError
+		if errors.As(err, &notFound) {
+			return nil, &customerrors.AccountNotFoundError{Guid: accountID}
+		}
+		s.log.Error("---GetAccount--->", logger.Error(err))
+		return nil, err
+	}
+	return acc, nil
+}
+
+func (s *Service) GetAccountTransactions(ctx context.Context, req *models.GetTransactionsByAccountIDRequest) (*models.GetTransactionsByAccountIDResponse, error) {
+	if _, err := s.GetAccount(ctx, req.AccountID); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.strg.TxRepo().GetTransactionsByAccountID(ctx, req)
+	if err != nil {
+		s.log.Error("---GetAccountTransactions--->", logger.Error(err))
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *Service) GetTransaction(ctx context.Context, id string) (*models.Transaction, error) {
+	t, err := s.strg.Admin().GetTransaction(ctx, id)
+	if err != nil {
+		s.log.Error("---GetTransaction--->", logger.Error(err))
+		return nil, err
+	}
+	return t, nil
+}
+
+// validateAdjustment checks the adjustment before it is proposed and once more before it is made
+func validateAdjustment(req *models.CreateAdjustmentRequest) error {
+	if req.Direction != models.AdjustmentDirectionCredit && req.Direction != models.AdjustmentDirectionDebit {
+		return fmt.Errorf("direction must be %s or %s", models.AdjustmentDirectionCredit, models.AdjustmentDirectionDebit)
+	}
+	if _, ok := models.AdjustmentReasonCodes[req.ReasonCode]; !ok {
+		return fmt.Errorf("invalid reason code")
+	}
+	req.Comment = strings.TrimSpace(req.Comment)
+	if utf8.RuneCountInString(req.Comment) > commentMaxLength {
+		return fmt.Errorf("comment must not be longer than %d characters", commentMaxLength)
+	}
+	amount, err := interest.ParseAmount(req.Amount)
+	if err != nil || amount.Sign() <= 0 {
+		return fmt.Errorf("amount must be positive with at most %d decimal places", interest.PostingScale)
+	}
+	return nil
+}
+
+// createAdjustment credits or debits the account by hand against the adjustment account in the caller's transaction.
+// The staff member who proposed it and the one who approved it are both recorded.
+func (s *Service) createAdjustment(ctx context.Context, tx *sql.Tx, req *models.CreateAdjustmentRequest, approvedBy string) (*models.CreateAdjustmentResponse, error) {
+	if err := validateAdjustment(req); err != nil {
+		return nil, err
+	}
+	amount, _ := interest.ParseAmount(req.Amount)
+
+	transfer := &models.PostTransferRequest{
+		FromAccountID: config.AdjustmentAccountID,
+		ToAccountID:   req.AccountID,
+		Amount:        interest.FormatDecimal(amount, interest.PostingScale),
+		Description:   "Manual adjustment: " + models.AdjustmentReasonCodes[req.ReasonCode],
+	}
+	if req.Direction == models.AdjustmentDirectionDebit {
+		transfer.FromAccountID, transfer.ToAccountID = req.AccountID, config.AdjustmentAccountID
+	}
+
+	posted, err := s.payment.PostTransfer(ctx, tx, transfer)
+	if err != nil {
+		s.log.Error("---CreateAdjustment->PostTransfer--->", logger.Error(err))
+		return nil, err
+	}
+
+	// the customer's leg is the credit of a credit and the debit of a debit
+	leg := posted.Transactions[1]
+	if req.Direction == models.AdjustmentDirectionDebit {
+		leg = posted.Transactions[0]
+	}
+
+	adjustment := &models.ManualAdjustment{
+		AccountID:     req.AccountID,
+		Direction:     req.Direction,
+		Amount:        transfer.Amount,
+		ReasonCode:    req.ReasonCode,
+		Comment:       req.Comment,
+		ActorID:       req.ActorID,
+		ApprovedBy:    approvedBy,
+		TransactionID: leg.ID,
+	}
+	if err = s.strg.Admin().CreateManualAdjustment(ctx, tx, adjustment); err != nil {
+		s.log.Error("---CreateAdjustment->CreateManualAdjustment--->", logger.Error(err))
+		return nil, err
+	}
+
+	err = s.strg.Audit().CreateAuditEvent(ctx, tx, &models.AuditEvent{
+		EventType:    models.AuditEventManualAdjustment,
+		ActorID:      req.ActorID,
+		ResourceType: models.AuditResourceAccount,
+		ResourceID:   req.AccountID,
+		IP:           req.IP,
+		Details: map[string]interface{}{
+			"adjustment_id":  adjustment.ID,
+			"direction":      req.Direction,
+			"amount":         adjustment.Amount,
+			"reason_code":    req.ReasonCode,
+			"approved_by":    approvedBy,
+			"transaction_id": leg.ID,
+		},
+	})
+	if err != nil {
+		s.log.Error("---CreateAdjustment->CreateAuditEvent--->", logger.Error(err))
+		return nil, err
+	}
+
+	return &models.CreateAdjustmentResponse{
+		Adjustment:   adjustment,
+		Transactions: posted.Transactions,
+	}, nil
+}
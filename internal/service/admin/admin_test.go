@@ -0,0 +1,364 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/internal/service/accountstatus"
+	"github.com/dilmurodov/online_banking/internal/service/payment"
+	"github.com/dilmurodov/online_banking/internal/service/servicetest"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestService(t *testing.T) (*Service, sqlmock.Sqlmock) {
+	strg, mock := servicetest.NewStore(t)
+	paymentService := payment.NewService(config.Config{}, zap.NewNop(), strg)
+
+	return NewService(
+		config.Config{BusinessTimezone: "UTC"},
+		zap.NewNop(),
+		strg,
+		paymentService,
+		accountstatus.NewService(config.Config{BusinessTimezone: "UTC"}, zap.NewNop(), strg, paymentService),
+	), mock
+}
+
+var operationColumns = []string{"guid", "operation_type", "resource_id", "payload", "status", "proposed_by", "decided_by", "comment", "result", "expires_at", "decided_at", "created_at"}
+
+func TestAdmin_ProposeAdjustment(t *testing.T) {
+	accountID := "00000000-0000-4000-8000-0000000000aa"
+
+	t.Run("SUCCESS", func(t *testing.T) {
+		r := require.New(t)
+		s, mock := newTestService(t)
+
+		mock.ExpectQuery(`^SELECT (.+?) FROM accounts`).WithArgs(accountID).
+			WillReturnRows(sqlmock.NewRows([]string{"guid", "user_id", "balance", "product", "accrued_interest", "overdraft_limit", "created_at", "updated_at", "status", "closed_at"}).
+				AddRow(accountID, "TestUserID", 10.0, "current", "0", 0.0, "2023-01-01", "2023-01-01", "active", nil))
+		servicetest.ExpectAuditBegin(mock)
+		// only the fields the maker sent are kept in the payload
+		mock.ExpectQuery(`^INSERT INTO pending_operations`).
+			WithArgs(models.OperationTypeManualAdjustment, accountID, []byte(`{"amount":12.5,"comment":"double charged","direction":"credit","reason_code":"fee_refund"}`), "TestMakerID", sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows(operationColumns).AddRow("TestOperationID", models.OperationTypeManualAdjustment, accountID,
+				[]byte(`{"amount":12.5,"comment":"double charged","direction":"credit","reason_code":"fee_refund"}`), "pending", "TestMakerID", nil, "", []byte(`{}`), "2023-05-17T00:00:00Z", nil, "2023-05-15"))
+		servicetest.ExpectAuditEvent(mock, models.AuditEventOperationProposed, nil, "127.0.0.1", sqlmock.AnyArg(), "TestMakerID",
+			models.AuditResourceOperation, "TestOperationID", "", nil, sqlmock.AnyArg(), "", sqlmock.AnyArg(), sqlmock.AnyArg())
+		mock.ExpectCommit()
+
+		op, err := s.ProposeAdjustment(context.Background(), &models.CreateAdjustmentRequest{
+			AccountID:  accountID,
+			ActorID:    "TestMakerID",
+			IP:         "127.0.0.1",
+			Direction:  models.AdjustmentDirectionCredit,
+			Amount:     12.5,
+			ReasonCode: "fee_refund",
+			Comment:    " double charged ",
+		})
+		r.NoError(err)
+		r.Equal("TestOperationID", op.ID)
+		r.Equal(models.OperationStatusPending, op.Status)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("INVALID_REASON_CODE", func(t *testing.T) {
+		r := require.New(t)
+		s, mock := newTestService(t)
+
+		_, err := s.ProposeAdjustment(context.Background(), &models.CreateAdjustmentRequest{
+			AccountID:  accountID,
+			ActorID:    "TestMakerID",
+			Direction:  models.AdjustmentDirectionCredit,
+			Amount:     10,
+			ReasonCode: "because",
+		})
+		r.EqualError(err, "invalid reason code")
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("NOT_POSITIVE_AMOUNT", func(t *testing.T) {
+		r := require.New(t)
+		s, mock := newTestService(t)
+
+		_, err := s.ProposeAdjustment(context.Background(), &models.CreateAdjustmentRequest{
+			AccountID:  accountID,
+			ActorID:    "TestMakerID",
+			Direction:  models.AdjustmentDirectionCredit,
+			Amount:     -10,
+			ReasonCode: "goodwill",
+		})
+		r.Error(err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+}
+
+func TestAdmin_ApproveOperation(t *testing.T) {
+	accountID := "00000000-0000-4000-8000-0000000000aa"
+	expiresAt := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+
+	adjustmentRow := func(direction string, amount float64, expiresAt string) *sqlmock.Rows {
+		payload, _ := json.Marshal(map[string]interface{}{"direction": direction, "amount": amount, "reason_code": "correction", "comment": ""})
+		return sqlmock.NewRows(operationColumns).AddRow("TestOperationID", models.OperationTypeManualAdjustment, accountID,
+			payload, "pending", "TestMakerID", nil, "", []byte(`{}`), expiresAt, nil, "2023-05-15")
+	}
+
+	t.Run("ADJUSTMENT_CREDIT", func(t *testing.T) {
+		r := require.New(t)
+		s, mock := newTestService(t)
+
+		description := "Manual adjustment: " + models.AdjustmentReasonCodes["correction"]
+
+		servicetest.ExpectAuditBegin(mock)
+		mock.ExpectQuery(`^SELECT (.+?) FROM pending_operations WHERE guid = \$1 FOR UPDATE`).WithArgs("TestOperationID").
+			WillReturnRows(adjustmentRow(models.AdjustmentDirectionCredit, 12.5, expiresAt))
+		servicetest.ExpectPosting(mock, config.AdjustmentAccountID, accountID, 12.5, "12.50", description, "")
+		// the customer's leg of a credit is the credit, the maker and the checker are both kept
+		mock.ExpectQuery(`^INSERT INTO manual_adjustments`).
+			WithArgs(accountID, models.AdjustmentDirectionCredit, "12.50", "correction", "", "TestMakerID", "TestCheckerID", "TestCreditID").
+			WillReturnRows(sqlmock.NewRows([]string{"guid", "created_at"}).AddRow("TestAdjustmentID", "2023-05-15"))
+		servicetest.ExpectAuditEvent(mock, models.AuditEventManualAdjustment, nil, "127.0.0.1", sqlmock.AnyArg(), "TestMakerID",
+			models.AuditResourceAccount, accountID, "", nil, nil, "", sqlmock.AnyArg(), sqlmock.AnyArg())
+		mock.ExpectExec(`^UPDATE pending_operations SET`).
+			WithArgs("TestOperationID", models.OperationStatusApproved, "TestCheckerID", "checked", []byte(`{"adjustment_id":"TestAdjustmentID"}`)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		// the decision keeps the status before and after it
+		servicetest.ExpectAuditEvent(mock, models.AuditEventOperationApproved, nil, "127.0.0.1", sqlmock.AnyArg(), "TestCheckerID",
+			models.AuditResourceOperation, "TestOperationID", "", `{"status":"pending"}`, sqlmock.AnyArg(), "", sqlmock.AnyArg(), sqlmock.AnyArg())
+		mock.ExpectCommit()
+
+		resp, err := s.ApproveOperation(context.Background(), &models.DecideOperationRequest{
+			ID:        "TestOperationID",
+			ActorID:   "TestCheckerID",
+			ActorRole: models.RoleAdmin,
+			IP:        "127.0.0.1",
+			Comment:   "checked",
+		})
+		r.NoError(err)
+		r.Equal(models.OperationStatusApproved, resp.Operation.Status)
+		r.Equal("TestCheckerID", resp.Operation.DecidedBy)
+		r.Len(resp.Transactions, 2)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("ADJUSTMENT_DEBIT", func(t *testing.T) {
+		r := require.New(t)
+		s, mock := newTestService(t)
+
+		description := "Manual adjustment: " + models.AdjustmentReasonCodes["correction"]
+
+		servicetest.ExpectAuditBegin(mock)
+		mock.ExpectQuery(`^SELECT (.+?) FROM pending_operations`).WillReturnRows(adjustmentRow(models.AdjustmentDirectionDebit, 3, expiresAt))
+		servicetest.ExpectPosting(mock, accountID, config.AdjustmentAccountID, 3, "3.00", description, "")
+		// the customer's leg of a debit is the debit
+		mock.ExpectQuery(`^INSERT INTO manual_adjustments`).
+			WithArgs(accountID, models.AdjustmentDirectionDebit, "3.00", "correction", "", "TestMakerID", "TestCheckerID", "TestDebitID").
+			WillReturnRows(sqlmock.NewRows([]string{"guid", "created_at"}).AddRow("TestAdjustmentID", "2023-05-15"))
+		servicetest.ExpectAuditEvent(mock)
+		mock.ExpectExec(`^UPDATE pending_operations SET`).WillReturnResult(sqlmock.NewResult(0, 1))
+		servicetest.ExpectAuditEvent(mock)
+		mock.ExpectCommit()
+
+		resp, err := s.ApproveOperation(context.Background(), &models.DecideOperationRequest{
+			ID:        "TestOperationID",
+			ActorID:   "TestCheckerID",
+			ActorRole: models.RoleAdmin,
+		})
+		r.NoError(err)
+		r.Equal("TestDebitID", resp.Transactions[0].ID)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("SAME_STAFF_MEMBER", func(t *testing.T) {
+		r := require.New(t)
+		s, mock := newTestService(t)
+
+		servicetest.ExpectAuditBegin(mock)
+		mock.ExpectQuery(`^SELECT (.+?) FROM pending_operations`).WillReturnRows(adjustmentRow(models.AdjustmentDirectionCredit, 12.5, expiresAt))
+		mock.ExpectRollback()
+
+		_, err := s.ApproveOperation(context.Background(), &models.DecideOperationRequest{
+			ID:        "TestOperationID",
+			ActorID:   "TestMakerID",
+			ActorRole: models.RoleAdmin,
+		})
+		r.EqualError(err, "an operation must be approved by another staff member")
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("NO_PERMISSION", func(t *testing.T) {
+		r := require.New(t)
+		s, mock := newTestService(t)
+
+		servicetest.ExpectAuditBegin(mock)
+		mock.ExpectQuery(`^SELECT (.+?) FROM pending_operations`).WillReturnRows(adjustmentRow(models.AdjustmentDirectionCredit, 12.5, expiresAt))
+		mock.ExpectRollback()
+
+		// compliance may freeze accounts but not adjust them
+		_, err := s.ApproveOperation(context.Background(), &models.DecideOperationRequest{
+			ID:        "TestOperationID",
+			ActorID:   "TestCheckerID",
+			ActorRole: models.RoleCompliance,
+		})
+		r.EqualError(err, "you may not approve this operation")
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("EXPIRED", func(t *testing.T) {
+		r := require.New(t)
+		s, mock := newTestService(t)
+
+		servicetest.ExpectAuditBegin(mock)
+		mock.ExpectQuery(`^SELECT (.+?) FROM pending_operations`).
+			WillReturnRows(adjustmentRow(models.AdjustmentDirectionCredit, 12.5, "2023-05-15T00:00:00Z"))
+		mock.ExpectExec(`^UPDATE pending_operations SET`).WithArgs("TestOperationID", models.OperationStatusExpired, nil, "", []byte(`{}`)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		_, err := s.ApproveOperation(context.Background(), &models.DecideOperationRequest{
+			ID:        "TestOperationID",
+			ActorID:   "TestCheckerID",
+			ActorRole: models.RoleAdmin,
+		})
+		r.IsType(&customerrors.PendingOperationStateError{}, err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+}
+
+func TestAdmin_ProposeReversal(t *testing.T) {
+	r := require.New(t)
+	s, mock := newTestService(t)
+
+	transactionID := "00000000-0000-4000-8000-0000000000bb"
+	accountID := "00000000-0000-4000-8000-0000000000aa"
+
+	// a cash deposit names its own account as the recipient, there is no one to post it back from
+	mock.ExpectQuery(`^SELECT (.+?) FROM transactions WHERE guid = \$1`).WithArgs(transactionID).
+		WillReturnRows(sqlmock.NewRows([]string{"guid", "account_id", "transaction_amount", "transaction_type", "recipient_id", "description", "reference", "created_at", "approved", "done", "done_timestamp"}).
+			AddRow(transactionID, accountID, 10.0, "credit", accountID, "", "", "2023-05-15", true, true, "2023-05-15"))
+
+	_, err := s.ProposeReversal(context.Background(), &models.ReverseTransactionRequest{
+		TransactionID: transactionID,
+		ActorID:       "TestMakerID",
+		ReasonCode:    "chargeback",
+	})
+	r.EqualError(err, "only a completed transfer between accounts can be reversed")
+	r.NoError(mock.ExpectationsWereMet())
+}
+
+func TestAdmin_SetUserRole(t *testing.T) {
+	userColumns := []string{"guid", "first_name", "last_name", "phone", "created_at", "updated_at", "sessions_revoked_at", "two_factor_enabled", "role"}
+
+	t.Run("SUCCESS", func(t *testing.T) {
+		r := require.New(t)
+		s, mock := newTestService(t)
+
+		mock.ExpectQuery(`^SELECT (.+?) FROM "users"`).
+			WillReturnRows(sqlmock.NewRows(userColumns).AddRow("TestUserID", "TestFirstName", "TestLastName", "TestPhone", "2021-01-01 00:00:00", "2021-01-01 00:00:00", 0, false, models.RoleCustomer))
+		servicetest.ExpectAuditBegin(mock)
+		mock.ExpectExec(`^UPDATE "users" SET`).WithArgs("TestUserID", models.RoleSupport).WillReturnResult(sqlmock.NewResult(0, 1))
+		// the actor is kept apart from the user whose role changed
+		servicetest.ExpectAuditEvent(mock, models.AuditEventRoleChanged, "TestUserID", "127.0.0.1", []byte("null"), "TestAdminID",
+			models.AuditResourceUser, "TestUserID", "", `{"role":"customer"}`, `{"role":"support"}`, "", sqlmock.AnyArg(), sqlmock.AnyArg())
+		mock.ExpectCommit()
+
+		user, err := s.SetUserRole(context.Background(), &models.SetUserRoleRequest{
+			UserID:  "TestUserID",
+			ActorID: "TestAdminID",
+			IP:      "127.0.0.1",
+			Role:    models.RoleSupport,
+		})
+		r.NoError(err)
+		r.Equal(models.RoleSupport, user.Role)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("OWN_ROLE", func(t *testing.T) {
+		r := require.New(t)
+		s, mock := newTestService(t)
+
+		_, err := s.SetUserRole(context.Background(), &models.SetUserRoleRequest{
+			UserID:  "TestAdminID",
+			ActorID: "TestAdminID",
+			Role:    models.RoleCustomer,
+		})
+		r.EqualError(err, "you can't change your own role")
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("INVALID_ROLE", func(t *testing.T) {
+		r := require.New(t)
+		s, mock := newTestService(t)
+
+		_, err := s.SetUserRole(context.Background(), &models.SetUserRoleRequest{
+			UserID:  "TestUserID",
+			ActorID: "TestAdminID",
+			Role:    "root",
+		})
+		r.EqualError(err, "invalid role")
+		r.NoError(mock.ExpectationsWereMet())
+	})
+}
+
+func TestAdmin_ReviewFraudDecision(t *testing.T) {
+	decisionColumns := []string{"guid", "user_id", "account_id", "operation", "counterparty_id", "amount", "score", "outcome", "hits",
+		"challenge_status", "review_status", "reviewed_by", "review_comment", "reviewed_at", "created_at"}
+
+	t.Run("SUCCESS", func(t *testing.T) {
+		r := require.New(t)
+		s, mock := newTestService(t)
+
+		mock.ExpectQuery(`^SELECT (.+?) FROM fraud_decisions`).WithArgs("TestDecisionID").
+			WillReturnRows(sqlmock.NewRows(decisionColumns).AddRow("TestDecisionID", "TestUserID", "TestAccountID", models.FraudOperationTransfer, "TestToAccountID",
+				"5000.00", 100, models.FraudOutcomeBlock, []byte(`[{"rule":"velocity","score":50,"reason":""}]`), nil, models.FraudReviewPending, nil, "", nil, "2023-05-15"))
+		servicetest.ExpectAuditBegin(mock)
+		mock.ExpectExec(`^UPDATE fraud_decisions SET`).WithArgs("TestDecisionID", models.FraudReviewFraud, "TestAdminID", "card stolen").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		servicetest.ExpectAuditEvent(mock, models.AuditEventFraudReviewed, "TestUserID", "127.0.0.1", []byte(`{"comment":"card stolen"}`), "TestAdminID",
+			models.AuditResourceFraudDecision, "TestDecisionID", "", `{"review_status":"pending"}`, `{"review_status":"fraud"}`, "", sqlmock.AnyArg(), sqlmock.AnyArg())
+		mock.ExpectCommit()
+
+		decision, err := s.ReviewFraudDecision(context.Background(), &models.ReviewFraudDecisionRequest{
+			ID:      "TestDecisionID",
+			ActorID: "TestAdminID",
+			IP:      "127.0.0.1",
+			Status:  models.FraudReviewFraud,
+			Comment: " card stolen ",
+		})
+		r.NoError(err)
+		r.Equal(models.FraudReviewFraud, decision.ReviewStatus)
+		r.Len(decision.Hits, 1)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("INVALID_STATUS", func(t *testing.T) {
+		r := require.New(t)
+		s, mock := newTestService(t)
+
+		_, err := s.ReviewFraudDecision(context.Background(), &models.ReviewFraudDecisionRequest{
+			ID:     "TestDecisionID",
+			Status: models.FraudReviewPending,
+		})
+		r.EqualError(err, "invalid status")
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("NOT_FOUND", func(t *testing.T) {
+		r := require.New(t)
+		s, mock := newTestService(t)
+
+		mock.ExpectQuery(`^SELECT (.+?) FROM fraud_decisions`).WithArgs("TestDecisionID").WillReturnRows(sqlmock.NewRows(decisionColumns))
+
+		_, err := s.ReviewFraudDecision(context.Background(), &models.ReviewFraudDecisionRequest{
+			ID:     "TestDecisionID",
+			Status: models.FraudReviewLegitimate,
+		})
+		r.IsType(&customerrors.FraudDecisionNotFoundError{}, err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,152 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/pkg/logger"
+	"github.com/dilmurodov/online_banking/pkg/models"
+)
+
+func (s *Service) GetFraudDecisions(ctx context.Context, req *models.GetFraudDecisionsRequest) (*models.GetFraudDecisionsResponse, error) {
+	switch req.Outcome {
+	case "", models.FraudOutcomeChallenge, models.FraudOutcomeBlock, models.FraudOutcomeAllow:
+	default:
+		return nil, fmt.Errorf("invalid outcome")
+	}
+	switch req.ReviewStatus {
+	case "", models.FraudReviewPending, models.FraudReviewLegitimate, models.FraudReviewFraud:
+	default:
+		return nil, fmt.Errorf("invalid review status")
+	}
+	if req.Limit <= 0 || req.Limit > config.SearchMaxLimit {
+		req.Limit = config.SearchMaxLimit
+	}
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+
+	resp, err := s.strg.Fraud().GetFraudDecisions(ctx, req)
+	if err != nil {
+		s.log.Error("---GetFraudDecisions--->", logger.Error(err))
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *Service) GetFraudDecision(ctx context.Context, id string) (*models.FraudDecision, error) {
+	decision, err := s.strg.Fraud().GetFraudDecision(ctx, id)
+	if err != nil {
+		s.log.Error("---GetFraudDecision--->", logger.Error(err))
+		return nil, err
+	}
+	return decision, nil
+}
+
+// ReviewFraudDecision marks a decision as a legitimate payment or as fraud, a review can be corrected later
+func (s *Service) ReviewFraudDecision(ctx context.Context, req *models.ReviewFraudDecisionRequest) (*models.FraudDecision, error) {
+	s.log.Info("---ReviewFraudDecision--->", logger.Any("req", req))
+
+	if req.Status != models.FraudReviewLegitimate && req.Status != models.FraudReviewFraud {
+		return nil, fmt.Errorf("invalid status")
+	}
+	req.Comment = strings.TrimSpace(req.Comment)
+	if utf8.RuneCountInString(req.Comment) > commentMaxLength {
+		return nil, fmt.Errorf("comment must not be longer than %d characters", commentMaxLength)
+	}
+
+	decision, err := s.GetFraudDecision(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.strg.Audit().BeginTx(ctx)
+	if err != nil {
+		s.log.Error("---ReviewFraudDecision->BeginTx--->", logger.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err = s.strg.Fraud().ReviewFraudDecision(ctx, tx, req); err != nil {
+		s.log.Error("---ReviewFraudDecision->ReviewFraudDecision--->", logger.Error(err))
+		return nil, err
+	}
+
+	err = s.strg.Audit().CreateAuditEvent(ctx, tx, &models.AuditEvent{
+		EventType:    models.AuditEventFraudReviewed,
+		ActorID:      req.ActorID,
+		UserID:       decision.UserID,
+		ResourceType: models.AuditResourceFraudDecision,
+		ResourceID:   req.ID,
+		IP:           req.IP,
+		Details:      map[string]interface{}{"comment": req.Comment},
+		Before:       map[string]interface{}{"review_status": decision.ReviewStatus},
+		After:        map[string]interface{}{"review_status": req.Status},
+	})
+	if err != nil {
+		s.log.Error("---ReviewFraudDecision->CreateAuditEvent--->", logger.Error(err))
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.log.Error("---ReviewFraudDecision->Commit--->", logger.Error(err))
+		return nil, err
+	}
+
+	decision.ReviewStatus = req.Status
+	decision.ReviewedBy = req.ActorID
+	decision.ReviewComment = req.Comment
+	return decision, nil
+}
+
+func (s *Service) GetFraudRules() models.FraudRules {
+	return s.payment.GetFraudRules()
+}
+
+// ReloadFraudRules reads the rules file again, the running rules stay in place when it is invalid
+func (s *Service) ReloadFraudRules(ctx context.Context, actorID, ip string) (models.FraudRules, error) {
+	s.log.Info("---ReloadFraudRules--->", logger.String("actor_id", actorID))
+
+	before, err := toPayload(s.payment.GetFraudRules())
+	if err != nil {
+		return models.FraudRules{}, err
+	}
+	rules, err := s.payment.ReloadFraudRules()
+	if err != nil {
+		return rules, err
+	}
+	after, err := toPayload(rules)
+	if err != nil {
+		return rules, err
+	}
+
+	tx, err := s.strg.Audit().BeginTx(ctx)
+	if err != nil {
+		s.log.Error("---ReloadFraudRules->BeginTx--->", logger.Error(err))
+		return rules, err
+	}
+	defer tx.Rollback()
+
+	err = s.strg.Audit().CreateAuditEvent(ctx, tx, &models.AuditEvent{
+		EventType:    models.AuditEventFraudRulesReloaded,
+		ActorID:      actorID,
+		ResourceType: models.AuditResourceFraudRules,
+		IP:           ip,
+		Before:       before,
+		After:        after,
+	})
+	if err != nil {
+		s.log.Error("---ReloadFraudRules->CreateAuditEvent--->", logger.Error(err))
+		return rules, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.log.Error("---ReloadFraudRules->Commit--->", logger.Error(err))
+		return rules, err
+	}
+
+	return rules, nil
+}
@@ -0,0 +1,53 @@
+package admin
+
+import (
+	"context"
+
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/internal/service/accountstatus"
+	"github.com/dilmurodov/online_banking/internal/service/payment"
+	"github.com/dilmurodov/online_banking/pkg/logger"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/dilmurodov/online_banking/storage"
+)
+
+type ServiceI interface {
+	SearchUsers(ctx context.Context, req *models.SearchUsersRequest) (*models.SearchUsersResponse, error)
+	GetUser(ctx context.Context, userID string) (*models.AdminUserResponse, error)
+	SetUserRole(ctx context.Context, req *models.SetUserRoleRequest) (*models.User, error)
+	SetUserRoleByPhone(ctx context.Context, phone, role string) (*models.User, error)
+	SearchAccounts(ctx context.Context, req *models.SearchAccountsRequest) (*models.SearchAccountsResponse, error)
+	GetAccount(ctx context.Context, accountID string) (*models.Account, error)
+	GetAccountTransactions(ctx context.Context, req *models.GetTransactionsByAccountIDRequest) (*models.GetTransactionsByAccountIDResponse, error)
+	GetTransaction(ctx context.Context, id string) (*models.Transaction, error)
+	ProposeAdjustment(ctx context.Context, req *models.CreateAdjustmentRequest) (*models.PendingOperation, error)
+	ProposeUnfreeze(ctx context.Context, req *models.FreezeAccountRequest) (*models.PendingOperation, error)
+	ProposeReversal(ctx context.Context, req *models.ReverseTransactionRequest) (*models.PendingOperation, error)
+	GetPendingOperations(ctx context.Context, req *models.GetPendingOperationsRequest) (*models.GetPendingOperationsResponse, error)
+	GetPendingOperation(ctx context.Context, id string) (*models.PendingOperation, error)
+	ApproveOperation(ctx context.Context, req *models.DecideOperationRequest) (*models.DecideOperationResponse, error)
+	RejectOperation(ctx context.Context, req *models.DecideOperationRequest) (*models.DecideOperationResponse, error)
+	GetFraudDecisions(ctx context.Context, req *models.GetFraudDecisionsRequest) (*models.GetFraudDecisionsResponse, error)
+	GetFraudDecision(ctx context.Context, id string) (*models.FraudDecision, error)
+	ReviewFraudDecision(ctx context.Context, req *models.ReviewFraudDecisionRequest) (*models.FraudDecision, error)
+	GetFraudRules() models.FraudRules
+	ReloadFraudRules(ctx context.Context, actorID, ip string) (models.FraudRules, error)
+}
+
+type Service struct {
+	cfg           config.Config
+	log           logger.LoggerI
+	strg          storage.StorageI
+	payment       payment.ServiceI
+	accountStatus accountstatus.ServiceI
+}
+
+func NewService(cfg config.Config, log logger.LoggerI, strg storage.StorageI, paymentService payment.ServiceI, accountStatusService accountstatus.ServiceI) *Service {
+	return &Service{
+		cfg:           cfg,
+		log:           log,
+		strg:          strg,
+		payment:       paymentService,
+		accountStatus: accountStatusService,
+	}
+}
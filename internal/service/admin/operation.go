@@ -0,0 +1,426 @@
+package admin
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/interest"
+	"github.com/dilmurodov/online_banking/pkg/logger"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/dilmurodov/online_banking/pkg/util"
+)
+
+// ProposeAdjustment stores a manual adjustment until another staff member approves it
+func (s *Service) ProposeAdjustment(ctx context.Context, req *models.CreateAdjustmentRequest) (*models.PendingOperation, error) {
+	s.log.Info("---ProposeAdjustment--->", logger.Any("req", req))
+
+	if err := validateAdjustment(req); err != nil {
+		return nil, err
+	}
+	if _, err := s.GetAccount(ctx, req.AccountID); err != nil {
+		return nil, err
+	}
+
+	return s.propose(ctx, models.OperationTypeManualAdjustment, req.AccountID, req.ActorID, req.IP, req)
+}
+
+// ProposeUnfreeze stores the unfreeze of a frozen account until another staff member approves it
+func (s *Service) ProposeUnfreeze(ctx context.Context, req *models.FreezeAccountRequest) (*models.PendingOperation, error) {
+	s.log.Info("---ProposeUnfreeze--->", logger.Any("req", req))
+
+	req.Reason = strings.TrimSpace(req.Reason)
+	if utf8.RuneCountInString(req.Reason) > commentMaxLength {
+		return nil, fmt.Errorf("reason must not be longer than %d characters", commentMaxLength)
+	}
+
+	acc, err := s.GetAccount(ctx, req.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	if acc.Status != models.AccountStatusFrozen {
+		return nil, &customerrors.AccountStatusError{Guid: acc.ID, Status: acc.Status}
+	}
+
+	return s.propose(ctx, models.OperationTypeAccountUnfreeze, req.AccountID, req.ActorID, req.IP, req)
+}
+
+// ProposeReversal stores the reversal of a transfer until another staff member approves it
+func (s *Service) ProposeReversal(ctx context.Context, req *models.ReverseTransactionRequest) (*models.PendingOperation, error) {
+	s.log.Info("---ProposeReversal--->", logger.Any("req", req))
+
+	if _, ok := models.AdjustmentReasonCodes[req.ReasonCode]; !ok {
+		return nil, fmt.Errorf("invalid reason code")
+	}
+	req.Comment = strings.TrimSpace(req.Comment)
+	if utf8.RuneCountInString(req.Comment) > commentMaxLength {
+		return nil, fmt.Errorf("comment must not be longer than %d characters", commentMaxLength)
+	}
+
+	t, err := s.GetTransaction(ctx, req.TransactionID)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkReversible(t); err != nil {
+		return nil, err
+	}
+
+	return s.propose(ctx, models.OperationTypeTransactionReversal, req.TransactionID, req.ActorID, req.IP, req)
+}
+
+// propose stores the operation with its payload and audits the proposal
+func (s *Service) propose(ctx context.Context, operationType, resourceID, actorID, ip string, payload interface{}) (*models.PendingOperation, error) {
+	p, err := toPayload(payload)
+	if err != nil {
+		s.log.Error("---Propose->ToPayload--->", logger.Error(err))
+		return nil, err
+	}
+
+	tx, err := s.strg.Audit().BeginTx(ctx)
+	if err != nil {
+		s.log.Error("---Propose->BeginTx--->", logger.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	op, err := s.strg.Operation().CreatePendingOperation(ctx, tx, &models.PendingOperation{
+		Type:       operationType,
+		ResourceID: resourceID,
+		Payload:    p,
+		ProposedBy: actorID,
+		ExpiresAt:  time.Now().Add(config.PendingOperationTTL).Format(config.DatabaseTimeLayout),
+	})
+	if err != nil {
+		s.log.Error("---Propose->CreatePendingOperation--->", logger.Error(err))
+		return nil, err
+	}
+
+	err = s.strg.Audit().CreateAuditEvent(ctx, tx, &models.AuditEvent{
+		EventType:    models.AuditEventOperationProposed,
+		ActorID:      actorID,
+		ResourceType: models.AuditResourceOperation,
+		ResourceID:   op.ID,
+		IP:           ip,
+		Details: map[string]interface{}{
+			"type":        operationType,
+			"resource_id": resourceID,
+		},
+		After: map[string]interface{}{
+			"status":  op.Status,
+			"payload": op.Payload,
+		},
+	})
+	if err != nil {
+		s.log.Error("---Propose->CreateAuditEvent--->", logger.Error(err))
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.log.Error("---Propose->Commit--->", logger.Error(err))
+		return nil, err
+	}
+
+	return op, nil
+}
+
+func (s *Service) GetPendingOperations(ctx context.Context, req *models.GetPendingOperationsRequest) (*models.GetPendingOperationsResponse, error) {
+	if req.Type != "" && models.OperationPermission(req.Type) == "" {
+		return nil, fmt.Errorf("invalid operation type")
+	}
+	switch req.Status {
+	case "", models.OperationStatusPending, models.OperationStatusApproved, models.OperationStatusRejected, models.OperationStatusExpired:
+	default:
+		return nil, fmt.Errorf("invalid status")
+	}
+	if req.Limit <= 0 || req.Limit > config.SearchMaxLimit {
+		req.Limit = config.SearchMaxLimit
+	}
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+
+	resp, err := s.strg.Operation().GetPendingOperations(ctx, req)
+	if err != nil {
+		s.log.Error("---GetPendingOperations--->", logger.Error(err))
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *Service) GetPendingOperation(ctx context.Context, id string) (*models.PendingOperation, error) {
+	op, err := s.strg.Operation().GetPendingOperation(ctx, id)
+	if err != nil {
+		s.log.Error("---GetPendingOperation--->", logger.Error(err))
+		return nil, err
+	}
+	return op, nil
+}
+
+// ApproveOperation executes a pending operation. The checker must be someone other than the maker
+// and needs the same permission the operation takes.
+func (s *Service) ApproveOperation(ctx context.Context, req *models.DecideOperationRequest) (*models.DecideOperationResponse, error) {
+	s.log.Info("---ApproveOperation--->", logger.Any("req", req))
+
+	tx, err := s.strg.Audit().BeginTx(ctx)
+	if err != nil {
+		s.log.Error("---ApproveOperation->BeginTx--->", logger.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	op, err := s.strg.Operation().GetPendingOperationForUpdate(ctx, tx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if op.Status != models.OperationStatusPending {
+		return nil, &customerrors.PendingOperationStateError{Status: op.Status}
+	}
+	if op.ProposedBy == req.ActorID {
+		return nil, fmt.Errorf("an operation must be approved by another staff member")
+	}
+	if !models.RoleAllows(req.ActorRole, models.OperationPermission(op.Type)) {
+		return nil, fmt.Errorf("you may not approve this operation")
+	}
+
+	expiresAt, err := time.Parse(time.RFC3339, op.ExpiresAt)
+	if err == nil && !expiresAt.After(time.Now()) {
+		op.Status = models.OperationStatusExpired
+		if err = s.strg.Operation().UpdatePendingOperation(ctx, tx, op); err != nil {
+			s.log.Error("---ApproveOperation->UpdatePendingOperation--->", logger.Error(err))
+			return nil, err
+		}
+		if err = tx.Commit(); err != nil {
+			s.log.Error("---ApproveOperation->Commit--->", logger.Error(err))
+			return nil, err
+		}
+		return nil, &customerrors.PendingOperationStateError{Status: op.Status}
+	}
+
+	resp, err := s.execute(ctx, tx, op, req)
+	if err != nil {
+		return nil, err
+	}
+
+	op.Status = models.OperationStatusApproved
+	op.DecidedBy = req.ActorID
+	op.Comment = strings.TrimSpace(req.Comment)
+	if err = s.strg.Operation().UpdatePendingOperation(ctx, tx, op); err != nil {
+		s.log.Error("---ApproveOperation->UpdatePendingOperation--->", logger.Error(err))
+		return nil, err
+	}
+
+	if err = s.auditDecision(ctx, tx, models.AuditEventOperationApproved, op, req); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.log.Error("---ApproveOperation->Commit--->", logger.Error(err))
+		return nil, err
+	}
+
+	if op.Type == models.OperationTypeAccountUnfreeze {
+		resp.Account, err = s.GetAccount(ctx, op.ResourceID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	resp.Operation = op
+
+	return resp, nil
+}
+
+// RejectOperation refuses a pending operation, the maker may withdraw it the same way
+func (s *Service) RejectOperation(ctx context.Context, req *models.DecideOperationRequest) (*models.DecideOperationResponse, error) {
+	s.log.Info("---RejectOperation--->", logger.Any("req", req))
+
+	tx, err := s.strg.Audit().BeginTx(ctx)
+	if err != nil {
+		s.log.Error("---RejectOperation->BeginTx--->", logger.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	op, err := s.strg.Operation().GetPendingOperationForUpdate(ctx, tx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if op.Status != models.OperationStatusPending {
+		return nil, &customerrors.PendingOperationStateError{Status: op.Status}
+	}
+	if op.ProposedBy != req.ActorID && !models.RoleAllows(req.ActorRole, models.OperationPermission(op.Type)) {
+		return nil, fmt.Errorf("you may not reject this operation")
+	}
+
+	op.Status = models.OperationStatusRejected
+	op.DecidedBy = req.ActorID
+	op.Comment = strings.TrimSpace(req.Comment)
+	if err = s.strg.Operation().UpdatePendingOperation(ctx, tx, op); err != nil {
+		s.log.Error("---RejectOperation->UpdatePendingOperation--->", logger.Error(err))
+		return nil, err
+	}
+
+	if err = s.auditDecision(ctx, tx, models.AuditEventOperationRejected, op, req); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.log.Error("---RejectOperation->Commit--->", logger.Error(err))
+		return nil, err
+	}
+
+	return &models.DecideOperationResponse{Operation: op}, nil
+}
+
+// execute makes the approved operation through the same services the customers' payments go through
+func (s *Service) execute(ctx context.Context, tx *sql.Tx, op *models.PendingOperation, req *models.DecideOperationRequest) (*models.DecideOperationResponse, error) {
+	resp := &models.DecideOperationResponse{}
+
+	switch op.Type {
+	case models.OperationTypeManualAdjustment:
+		var adjustment models.CreateAdjustmentRequest
+		if err := fromPayload(op.Payload, &adjustment); err != nil {
+			return nil, err
+		}
+		adjustment.AccountID = op.ResourceID
+		adjustment.ActorID = op.ProposedBy
+		adjustment.IP = req.IP
+
+		created, err := s.createAdjustment(ctx, tx, &adjustment, req.ActorID)
+		if err != nil {
+			return nil, err
+		}
+		op.Result = map[string]interface{}{"adjustment_id": created.Adjustment.ID}
+		resp.Transactions = created.Transactions
+
+	case models.OperationTypeAccountUnfreeze:
+		var unfreeze models.FreezeAccountRequest
+		if err := fromPayload(op.Payload, &unfreeze); err != nil {
+			return nil, err
+		}
+		unfreeze.AccountID = op.ResourceID
+		unfreeze.ActorID = op.ProposedBy
+
+		if err := s.accountStatus.UnfreezeAccount(ctx, tx, &unfreeze); err != nil {
+			return nil, err
+		}
+		op.Result = map[string]interface{}{"status": models.AccountStatusActive}
+
+	case models.OperationTypeTransactionReversal:
+		var reversal models.ReverseTransactionRequest
+		if err := fromPayload(op.Payload, &reversal); err != nil {
+			return nil, err
+		}
+		reversal.TransactionID = op.ResourceID
+
+		posted, err := s.reverseTransaction(ctx, tx, &reversal)
+		if err != nil {
+			return nil, err
+		}
+		op.Result = map[string]interface{}{
+			"transaction_ids": []string{posted.Transactions[0].ID, posted.Transactions[1].ID},
+		}
+		resp.Transactions = posted.Transactions
+
+	default:
+		return nil, fmt.Errorf("unknown operation type %s", op.Type)
+	}
+
+	return resp, nil
+}
+
+// checkReversible lets only settled transfers between two accounts be reversed
+func checkReversible(t *models.Transaction) error {
+	if !t.Done || !util.IsValidUUID(t.RecipientID) || t.RecipientID == t.AccountID {
+		return fmt.Errorf("only a completed transfer between accounts can be reversed")
+	}
+	return nil
+}
+
+// reverseTransaction posts the transfer back, from the account that was credited to the one that was debited
+func (s *Service) reverseTransaction(ctx context.Context, tx *sql.Tx, req *models.ReverseTransactionRequest) (*models.TransferResponse, error) {
+	t, err := s.strg.Admin().GetTransaction(ctx, req.TransactionID)
+	if err != nil {
+		s.log.Error("---ReverseTransaction->GetTransaction--->", logger.Error(err))
+		return nil, err
+	}
+	if err = checkReversible(t); err != nil {
+		return nil, err
+	}
+
+	amount, err := interest.ParseAmount(t.Amount)
+	if err != nil {
+		s.log.Error("---ReverseTransaction->ParseAmount--->", logger.Error(err))
+		return nil, err
+	}
+
+	transfer := &models.PostTransferRequest{
+		FromAccountID: t.RecipientID,
+		ToAccountID:   t.AccountID,
+		Amount:        interest.FormatDecimal(amount, interest.PostingScale),
+		Description:   "Reversal of transaction " + t.ID,
+	}
+	if t.Type == "credit" {
+		transfer.FromAccountID, transfer.ToAccountID = t.AccountID, t.RecipientID
+	}
+
+	posted, err := s.payment.PostTransfer(ctx, tx, transfer)
+	if err != nil {
+		s.log.Error("---ReverseTransaction->PostTransfer--->", logger.Error(err))
+		return nil, err
+	}
+	return posted, nil
+}
+
+// auditDecision records who decided on the operation, the maker is in the details.
+// Only pending operations are decided on, so that is always the status before.
+func (s *Service) auditDecision(ctx context.Context, tx *sql.Tx, eventType string, op *models.PendingOperation, req *models.DecideOperationRequest) error {
+	err := s.strg.Audit().CreateAuditEvent(ctx, tx, &models.AuditEvent{
+		EventType:    eventType,
+		ActorID:      req.ActorID,
+		ResourceType: models.AuditResourceOperation,
+		ResourceID:   op.ID,
+		IP:           req.IP,
+		Details: map[string]interface{}{
+			"type":        op.Type,
+			"resource_id": op.ResourceID,
+			"proposed_by": op.ProposedBy,
+		},
+		Before: map[string]interface{}{"status": models.OperationStatusPending},
+		After: map[string]interface{}{
+			"status": op.Status,
+			"result": op.Result,
+		},
+	})
+	if err != nil {
+		s.log.Error("---AuditDecision->CreateAuditEvent--->", logger.Error(err))
+		return err
+	}
+	return nil
+}
+
+// toPayload keeps the JSON fields of a request, the ones set by the server are left out
+func toPayload(v interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var payload map[string]interface{}
+	if err = json.Unmarshal(data, &payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+func fromPayload(payload map[string]interface{}, v interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,214 @@
+package aml
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/internal/service/servicetest"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var (
+	amlTransactionColumns = []string{"guid", "user_id", "account_id", "recipient_id", "transaction_type", "transaction_amount", "description", "reference", "created_at"}
+	amlCaseColumns        = []string{"guid", "user_id", "account_id", "case_type", "status", "reason", "assignee_id", "report_reference", "count", "created_at", "updated_at"}
+)
+
+func newTestService(t *testing.T) (*Service, sqlmock.Sqlmock) {
+	strg, mock := servicetest.NewStore(t)
+
+	return NewService(config.Config{
+		AMLCashThreshold:          10000,
+		AMLStructuringMargin:      0.1,
+		AMLStructuringCount:       3,
+		AMLStructuringWindowHours: 72,
+		AMLRapidMinAmount:         5000,
+		AMLRapidRatio:             0.9,
+		AMLRapidWindowHours:       24,
+	}, zap.NewNop(), strg), mock
+}
+
+func expectBatch(mock sqlmock.Sqlmock, transactions *sqlmock.Rows) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT last_created_at, last_transaction_id FROM aml_monitor_cursor`).
+		WillReturnRows(sqlmock.NewRows([]string{"last_created_at", "last_transaction_id"}).AddRow(time.Date(2023, 5, 14, 0, 0, 0, 0, time.UTC), "TestCursorID"))
+	mock.ExpectQuery(`^SELECT (.+?) FROM transactions t`).
+		WithArgs(time.Date(2023, 5, 14, 0, 0, 0, 0, time.UTC), "TestCursorID", sqlmock.AnyArg(), config.SystemUserID, config.AMLBatchSize).
+		WillReturnRows(transactions)
+}
+
+func TestAML_Scan(t *testing.T) {
+	now := time.Date(2023, 5, 15, 12, 0, 0, 0, time.UTC)
+
+	t.Run("CASH_THRESHOLD", func(t *testing.T) {
+		r := require.New(t)
+		s, mock := newTestService(t)
+
+		expectBatch(mock, sqlmock.NewRows(amlTransactionColumns).
+			AddRow("TestDepositID", "TestUserID", "TestAccountID", "TestAccountID", "credit", 12000.0, "", "", now))
+		// a large deposit is flagged on its own, the history is not read
+		mock.ExpectQuery(`^SELECT (.+?) FROM aml_cases c`).WithArgs("TestAccountID", models.AMLCaseCashThreshold).WillReturnRows(sqlmock.NewRows(amlCaseColumns))
+		mock.ExpectQuery(`^INSERT INTO aml_cases`).WithArgs("TestUserID", "TestAccountID", models.AMLCaseCashThreshold, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"guid", "created_at", "updated_at"}).AddRow("TestCaseID", "2023-05-15", "2023-05-15"))
+		mock.ExpectExec(`^INSERT INTO aml_case_history`).WithArgs("TestCaseID", models.AMLCaseStatusOpen, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`^INSERT INTO aml_case_transactions`).WithArgs("TestCaseID", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`^UPDATE aml_cases SET updated_at`).WithArgs("TestCaseID").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`^UPDATE aml_monitor_cursor SET`).WithArgs(now, "TestDepositID").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		n, err := s.Scan(context.Background())
+		r.NoError(err)
+		r.Equal(1, n)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("STRUCTURING", func(t *testing.T) {
+		r := require.New(t)
+		s, mock := newTestService(t)
+
+		expectBatch(mock, sqlmock.NewRows(amlTransactionColumns).
+			AddRow("TestDeposit3ID", "TestUserID", "TestAccountID", "TestAccountID", "credit", 9500.0, "", "", now))
+		mock.ExpectQuery(`^SELECT (.+?) FROM transactions t`).WithArgs("TestAccountID", now.Add(-72*time.Hour), now).
+			WillReturnRows(sqlmock.NewRows(amlTransactionColumns).
+				AddRow("TestDeposit1ID", "TestUserID", "TestAccountID", "TestAccountID", "credit", 9800.0, "", "", now.Add(-48*time.Hour)).
+				AddRow("TestSmallDepositID", "TestUserID", "TestAccountID", "TestAccountID", "credit", 500.0, "", "", now.Add(-30*time.Hour)).
+				AddRow("TestDeposit2ID", "TestUserID", "TestAccountID", "TestAccountID", "credit", 9200.0, "", "", now.Add(-24*time.Hour)).
+				AddRow("TestDeposit3ID", "TestUserID", "TestAccountID", "TestAccountID", "credit", 9500.0, "", "", now))
+		// the account already has a structuring case being worked on, the deposits go to it
+		mock.ExpectQuery(`^SELECT (.+?) FROM aml_cases c`).WithArgs("TestAccountID", models.AMLCaseStructuring).
+			WillReturnRows(sqlmock.NewRows(amlCaseColumns).AddRow("TestCaseID", "TestUserID", "TestAccountID", models.AMLCaseStructuring, models.AMLCaseStatusInvestigating, "", "TestOfficerID", "", 2, "2023-05-14", "2023-05-14"))
+		mock.ExpectExec(`^INSERT INTO aml_case_transactions`).WithArgs("TestCaseID", `{"TestDeposit1ID","TestDeposit2ID","TestDeposit3ID"}`).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`^UPDATE aml_cases SET updated_at`).WithArgs("TestCaseID").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`^UPDATE aml_monitor_cursor SET`).WithArgs(now, "TestDeposit3ID").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		n, err := s.Scan(context.Background())
+		r.NoError(err)
+		r.Equal(1, n)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("RAPID_MOVEMENT", func(t *testing.T) {
+		r := require.New(t)
+		s, mock := newTestService(t)
+
+		expectBatch(mock, sqlmock.NewRows(amlTransactionColumns).
+			AddRow("TestDebitID", "TestUserID", "TestAccountID", "TestOtherAccountID", "debit", 5000.0, "", "", now))
+		mock.ExpectQuery(`^SELECT (.+?) FROM transactions t`).WithArgs("TestAccountID", now.Add(-72*time.Hour), now).
+			WillReturnRows(sqlmock.NewRows(amlTransactionColumns).
+				AddRow("TestOldCreditID", "TestUserID", "TestAccountID", "TestThirdAccountID", "credit", 20000.0, "", "", now.Add(-48*time.Hour)).
+				AddRow("TestCreditID", "TestUserID", "TestAccountID", "TestThirdAccountID", "credit", 5500.0, "", "", now.Add(-2*time.Hour)).
+				AddRow("TestDebitID", "TestUserID", "TestAccountID", "TestOtherAccountID", "debit", 5000.0, "", "", now))
+		mock.ExpectQuery(`^SELECT (.+?) FROM aml_cases c`).WithArgs("TestAccountID", models.AMLCaseRapidMovement).WillReturnRows(sqlmock.NewRows(amlCaseColumns))
+		mock.ExpectQuery(`^INSERT INTO aml_cases`).WithArgs("TestUserID", "TestAccountID", models.AMLCaseRapidMovement, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"guid", "created_at", "updated_at"}).AddRow("TestCaseID", "2023-05-15", "2023-05-15"))
+		mock.ExpectExec(`^INSERT INTO aml_case_history`).WillReturnResult(sqlmock.NewResult(0, 1))
+		// the credit older than the window is left out
+		mock.ExpectExec(`^INSERT INTO aml_case_transactions`).WithArgs("TestCaseID", `{"TestCreditID","TestDebitID"}`).WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec(`^UPDATE aml_cases SET updated_at`).WithArgs("TestCaseID").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`^UPDATE aml_monitor_cursor SET`).WithArgs(now, "TestDebitID").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		n, err := s.Scan(context.Background())
+		r.NoError(err)
+		r.Equal(1, n)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("NOTHING_NEW", func(t *testing.T) {
+		r := require.New(t)
+		s, mock := newTestService(t)
+
+		expectBatch(mock, sqlmock.NewRows(amlTransactionColumns))
+		mock.ExpectRollback()
+
+		n, err := s.Scan(context.Background())
+		r.NoError(err)
+		r.Equal(0, n)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+}
+
+func TestAML_UpdateCaseStatus(t *testing.T) {
+	caseRow := func(status string) *sqlmock.Rows {
+		return sqlmock.NewRows(amlCaseColumns).AddRow("TestCaseID", "TestUserID", "TestAccountID", models.AMLCaseStructuring, status, "", nil, "", 3, "2023-05-14", "2023-05-14")
+	}
+
+	t.Run("REPORTED", func(t *testing.T) {
+		r := require.New(t)
+		s, mock := newTestService(t)
+
+		mock.ExpectQuery(`^SELECT (.+?) FROM aml_cases c`).WithArgs("TestCaseID").WillReturnRows(caseRow(models.AMLCaseStatusInvestigating))
+		servicetest.ExpectAuditBegin(mock)
+		mock.ExpectExec(`^UPDATE aml_cases SET`).
+			WithArgs("TestCaseID", models.AMLCaseStatusInvestigating, models.AMLCaseStatusReported, "TestOfficerID", "FIU-2023-001").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`^INSERT INTO aml_case_history`).
+			WithArgs("TestCaseID", models.AMLCaseStatusInvestigating, models.AMLCaseStatusReported, "TestOfficerID", "").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		servicetest.ExpectAuditEvent(mock, models.AuditEventAMLCaseStatusChanged, "TestUserID", "127.0.0.1", []byte(`{"comment":""}`), "TestOfficerID",
+			models.AuditResourceAMLCase, "TestCaseID", "", `{"status":"investigating"}`, `{"status":"reported"}`, "", sqlmock.AnyArg(), sqlmock.AnyArg())
+		mock.ExpectCommit()
+
+		c, err := s.UpdateCaseStatus(context.Background(), &models.UpdateAMLCaseStatusRequest{
+			ID:              "TestCaseID",
+			ActorID:         "TestOfficerID",
+			IP:              "127.0.0.1",
+			Status:          models.AMLCaseStatusReported,
+			ReportReference: " FIU-2023-001 ",
+		})
+		r.NoError(err)
+		r.Equal(models.AMLCaseStatusReported, c.Status)
+		r.Equal("FIU-2023-001", c.ReportReference)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("SKIPPED_INVESTIGATION", func(t *testing.T) {
+		r := require.New(t)
+		s, mock := newTestService(t)
+
+		mock.ExpectQuery(`^SELECT (.+?) FROM aml_cases c`).WithArgs("TestCaseID").WillReturnRows(caseRow(models.AMLCaseStatusOpen))
+
+		_, err := s.UpdateCaseStatus(context.Background(), &models.UpdateAMLCaseStatusRequest{
+			ID:              "TestCaseID",
+			ActorID:         "TestOfficerID",
+			Status:          models.AMLCaseStatusReported,
+			ReportReference: "FIU-2023-001",
+		})
+		r.IsType(&customerrors.AMLCaseStatusError{}, err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("CLOSED_CASE", func(t *testing.T) {
+		r := require.New(t)
+		s, mock := newTestService(t)
+
+		mock.ExpectQuery(`^SELECT (.+?) FROM aml_cases c`).WithArgs("TestCaseID").WillReturnRows(caseRow(models.AMLCaseStatusClosed))
+
+		_, err := s.UpdateCaseStatus(context.Background(), &models.UpdateAMLCaseStatusRequest{
+			ID:      "TestCaseID",
+			ActorID: "TestOfficerID",
+			Status:  models.AMLCaseStatusInvestigating,
+		})
+		r.IsType(&customerrors.AMLCaseStatusError{}, err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("CLOSE_WITHOUT_COMMENT", func(t *testing.T) {
+		r := require.New(t)
+		s, mock := newTestService(t)
+
+		_, err := s.UpdateCaseStatus(context.Background(), &models.UpdateAMLCaseStatusRequest{
+			ID:      "TestCaseID",
+			ActorID: "TestOfficerID",
+			Status:  models.AMLCaseStatusClosed,
+		})
+		r.EqualError(err, "comment is required to close a case")
+		r.NoError(mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,163 @@
+package aml
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/logger"
+	"github.com/dilmurodov/online_banking/pkg/models"
+)
+
+const (
+	commentMaxLength         = 255
+	reportReferenceMaxLength = 64
+)
+
+func (s *Service) GetCases(ctx context.Context, req *models.GetAMLCasesRequest) (*models.GetAMLCasesResponse, error) {
+	switch req.Status {
+	case "", models.AMLCaseStatusOpen, models.AMLCaseStatusInvestigating, models.AMLCaseStatusReported, models.AMLCaseStatusClosed:
+	default:
+		return nil, fmt.Errorf("invalid status")
+	}
+	switch req.Type {
+	case "", models.AMLCaseCashThreshold, models.AMLCaseStructuring, models.AMLCaseRapidMovement:
+	default:
+		return nil, fmt.Errorf("invalid case type")
+	}
+	if req.Limit <= 0 || req.Limit > config.SearchMaxLimit {
+		req.Limit = config.SearchMaxLimit
+	}
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+
+	resp, err := s.strg.AML().GetAMLCases(ctx, req)
+	if err != nil {
+		s.log.Error("---GetAMLCases--->", logger.Error(err))
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *Service) GetCase(ctx context.Context, id string) (*models.AMLCase, error) {
+	c, err := s.strg.AML().GetAMLCase(ctx, id)
+	if err != nil {
+		s.log.Error("---GetAMLCase--->", logger.Error(err))
+		return nil, err
+	}
+	return c, nil
+}
+
+// UpdateCaseStatus moves a case through the workflow: open, investigating, reported and closed.
+// A reported case needs the reference of the filed report, a closed one the reason it was closed.
+func (s *Service) UpdateCaseStatus(ctx context.Context, req *models.UpdateAMLCaseStatusRequest) (*models.AMLCase, error) {
+	s.log.Info("---UpdateAMLCaseStatus--->", logger.Any("req", req))
+
+	req.Comment = strings.TrimSpace(req.Comment)
+	req.ReportReference = strings.TrimSpace(req.ReportReference)
+	if utf8.RuneCountInString(req.Comment) > commentMaxLength {
+		return nil, fmt.Errorf("comment must not be longer than %d characters", commentMaxLength)
+	}
+	switch req.Status {
+	case models.AMLCaseStatusInvestigating:
+	case models.AMLCaseStatusReported:
+		if req.ReportReference == "" || utf8.RuneCountInString(req.ReportReference) > reportReferenceMaxLength {
+			return nil, fmt.Errorf("report_reference is required and must not be longer than %d characters", reportReferenceMaxLength)
+		}
+	case models.AMLCaseStatusClosed:
+		if req.Comment == "" {
+			return nil, fmt.Errorf("comment is required to close a case")
+		}
+	default:
+		return nil, fmt.Errorf("invalid status")
+	}
+
+	c, err := s.GetCase(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !models.AMLCaseTransitionAllowed(c.Status, req.Status) {
+		return nil, &customerrors.AMLCaseStatusError{From: c.Status, To: req.Status}
+	}
+
+	tx, err := s.strg.Audit().BeginTx(ctx)
+	if err != nil {
+		s.log.Error("---UpdateAMLCaseStatus->BeginTx--->", logger.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err = s.strg.AML().UpdateAMLCaseStatus(ctx, tx, req, c.Status); err != nil {
+		s.log.Error("---UpdateAMLCaseStatus->UpdateAMLCaseStatus--->", logger.Error(err))
+		return nil, err
+	}
+
+	err = s.strg.Audit().CreateAuditEvent(ctx, tx, &models.AuditEvent{
+		EventType:    models.AuditEventAMLCaseStatusChanged,
+		ActorID:      req.ActorID,
+		UserID:       c.UserID,
+		ResourceType: models.AuditResourceAMLCase,
+		ResourceID:   c.ID,
+		IP:           req.IP,
+		Details:      map[string]interface{}{"comment": req.Comment},
+		Before:       map[string]interface{}{"status": c.Status},
+		After:        map[string]interface{}{"status": req.Status},
+	})
+	if err != nil {
+		s.log.Error("---UpdateAMLCaseStatus->CreateAuditEvent--->", logger.Error(err))
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.log.Error("---UpdateAMLCaseStatus->Commit--->", logger.Error(err))
+		return nil, err
+	}
+
+	c.Status = req.Status
+	switch req.Status {
+	case models.AMLCaseStatusInvestigating:
+		c.AssigneeID = req.ActorID
+	case models.AMLCaseStatusReported:
+		c.ReportReference = req.ReportReference
+	}
+	return c, nil
+}
+
+// GetCaseReport gathers the case, its subject, the linked transactions and the history for export
+func (s *Service) GetCaseReport(ctx context.Context, id string) (*models.AMLCaseReport, error) {
+	c, err := s.GetCase(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.strg.User().GetUserByID(ctx, &models.GetUserByIDRequest{UserId: c.UserID})
+	if err != nil {
+		s.log.Error("---GetAMLCaseReport->GetUserByID--->", logger.Error(err))
+		return nil, err
+	}
+
+	transactions, err := s.strg.AML().GetAMLCaseTransactions(ctx, id)
+	if err != nil {
+		s.log.Error("---GetAMLCaseReport->GetAMLCaseTransactions--->", logger.Error(err))
+		return nil, err
+	}
+
+	history, err := s.strg.AML().GetAMLCaseHistory(ctx, id)
+	if err != nil {
+		s.log.Error("---GetAMLCaseReport->GetAMLCaseHistory--->", logger.Error(err))
+		return nil, err
+	}
+
+	return &models.AMLCaseReport{
+		Case:         c,
+		Subject:      user.User,
+		Transactions: transactions,
+		History:      history,
+		GeneratedAt:  time.Now().UTC().Format(time.RFC3339),
+	}, nil
+}
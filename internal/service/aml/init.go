@@ -0,0 +1,44 @@
+package aml
+
+import (
+	"context"
+	"time"
+
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/pkg/logger"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/dilmurodov/online_banking/storage"
+)
+
+type ServiceI interface {
+	Start(ctx context.Context)
+	Scan(ctx context.Context) (int, error)
+	GetCases(ctx context.Context, req *models.GetAMLCasesRequest) (*models.GetAMLCasesResponse, error)
+	GetCase(ctx context.Context, id string) (*models.AMLCase, error)
+	UpdateCaseStatus(ctx context.Context, req *models.UpdateAMLCaseStatusRequest) (*models.AMLCase, error)
+	GetCaseReport(ctx context.Context, id string) (*models.AMLCaseReport, error)
+}
+
+type Service struct {
+	cfg   config.Config
+	log   logger.LoggerI
+	strg  storage.StorageI
+	rules models.AMLRules
+}
+
+func NewService(cfg config.Config, log logger.LoggerI, strg storage.StorageI) *Service {
+	return &Service{
+		cfg:  cfg,
+		log:  log,
+		strg: strg,
+		rules: models.AMLRules{
+			CashThreshold:     cfg.AMLCashThreshold,
+			StructuringMargin: cfg.AMLStructuringMargin,
+			StructuringCount:  cfg.AMLStructuringCount,
+			StructuringWindow: time.Duration(cfg.AMLStructuringWindowHours) * time.Hour,
+			RapidMinAmount:    cfg.AMLRapidMinAmount,
+			RapidRatio:        cfg.AMLRapidRatio,
+			RapidWindow:       time.Duration(cfg.AMLRapidWindowHours) * time.Hour,
+		},
+	}
+}
@@ -0,0 +1,137 @@
+package aml
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/dilmurodov/online_banking/config"
+	amlrules "github.com/dilmurodov/online_banking/pkg/aml"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/logger"
+	"github.com/dilmurodov/online_banking/pkg/models"
+)
+
+// Start scans the new transactions every interval until the context is done
+func (s *Service) Start(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(s.cfg.AMLIntervalSeconds) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.Scan(ctx); err != nil {
+			s.log.Error("---AMLMonitor->Scan--->", logger.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Scan checks the transactions committed since the last scan in batches and returns how many it checked
+func (s *Service) Scan(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		n, err := s.scanBatch(ctx)
+		total += n
+		if err != nil || n < config.AMLBatchSize {
+			return total, err
+		}
+	}
+}
+
+// scanBatch checks a batch and moves the cursor past it in the same transaction,
+// so the cases of a batch are opened exactly once
+func (s *Service) scanBatch(ctx context.Context) (int, error) {
+	tx, err := s.strg.TxRepo().BeginTx(ctx)
+	if err != nil {
+		s.log.Error("---AMLScan->BeginTx--->", logger.Error(err))
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	cursor, err := s.strg.AML().GetAMLCursor(ctx, tx)
+	if err != nil {
+		s.log.Error("---AMLScan->GetAMLCursor--->", logger.Error(err))
+		return 0, err
+	}
+
+	transactions, err := s.strg.AML().GetAMLTransactions(ctx, tx, &models.GetAMLTransactionsRequest{
+		After:  cursor,
+		Before: time.Now().Add(-config.AMLSettleDelay),
+		Limit:  config.AMLBatchSize,
+	})
+	if err != nil {
+		s.log.Error("---AMLScan->GetAMLTransactions--->", logger.Error(err))
+		return 0, err
+	}
+	if len(transactions) == 0 {
+		return 0, nil
+	}
+
+	for _, t := range transactions {
+		var history []*models.AMLTransaction
+		if amlrules.NeedsHistory(s.rules, t) {
+			history, err = s.strg.AML().GetAMLActivity(ctx, tx, &models.GetAMLActivityRequest{
+				AccountID: t.AccountID,
+				From:      t.CreatedAt.Add(-amlrules.Window(s.rules)),
+				To:        t.CreatedAt,
+			})
+			if err != nil {
+				s.log.Error("---AMLScan->GetAMLActivity--->", logger.Error(err))
+				return 0, err
+			}
+		}
+
+		for _, flag := range amlrules.Evaluate(s.rules, t, history) {
+			if err = s.flag(ctx, tx, t, flag); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	last := transactions[len(transactions)-1]
+	err = s.strg.AML().SetAMLCursor(ctx, tx, &models.AMLCursor{CreatedAt: last.CreatedAt, TransactionID: last.ID})
+	if err != nil {
+		s.log.Error("---AMLScan->SetAMLCursor--->", logger.Error(err))
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.log.Error("---AMLScan->Commit--->", logger.Error(err))
+		return 0, err
+	}
+
+	return len(transactions), nil
+}
+
+// flag links the transactions to the account's case of the kind that is still being worked on,
+// or opens one
+func (s *Service) flag(ctx context.Context, tx *sql.Tx, t *models.AMLTransaction, flag *models.AMLFlag) error {
+	c, err := s.strg.AML().GetActiveAMLCase(ctx, tx, t.AccountID, flag.Type)
+	if _, ok := err.(*customerrors.AMLCaseNotFoundError); ok {
+		c, err = s.strg.AML().CreateAMLCase(ctx, tx, &models.AMLCase{
+			UserID:    t.UserID,
+			AccountID: t.AccountID,
+			Type:      flag.Type,
+			Reason:    flag.Reason,
+		})
+		if err != nil {
+			s.log.Error("---AMLFlag->CreateAMLCase--->", logger.Error(err))
+			return err
+		}
+		s.log.Info("---AMLFlag->CaseOpened--->", logger.String("case_id", c.ID), logger.String("type", flag.Type), logger.String("reason", flag.Reason))
+	} else if err != nil {
+		s.log.Error("---AMLFlag->GetActiveAMLCase--->", logger.Error(err))
+		return err
+	}
+
+	if _, err = s.strg.AML().LinkAMLCaseTransactions(ctx, tx, c.ID, flag.TransactionIDs); err != nil {
+		s.log.Error("---AMLFlag->LinkAMLCaseTransactions--->", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
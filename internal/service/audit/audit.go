@@ -0,0 +1,122 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dilmurodov/online_banking/config"
+
+	"github.com/dilmurodov/online_banking/pkg/auditlog"
+	"github.com/dilmurodov/online_banking/pkg/logger"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/dilmurodov/online_banking/pkg/util"
+)
+
+// verifyBatchSize is how many events are read at a time while walking the chain
+const verifyBatchSize = 500
+
+// Record appends an event that is not part of another change in its own transaction
+func (s *Service) Record(ctx context.Context, event *models.AuditEvent) error {
+	tx, err := s.strg.Audit().BeginTx(ctx)
+	if err != nil {
+		s.log.Error("---Record->BeginTx--->", logger.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = s.strg.Audit().CreateAuditEvent(ctx, tx, event); err != nil {
+		s.log.Error("---Record->CreateAuditEvent--->", logger.Error(err))
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.log.Error("---Record->Commit--->", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (s *Service) GetAuditEvents(ctx context.Context, req *models.GetAuditEventsRequest) (*models.GetAuditEventsResponse, error) {
+	s.log.Info("---GetAuditEvents--->", logger.Any("req", req))
+
+	for _, id := range []string{req.ActorID, req.UserID} {
+		if id != "" && !util.IsValidUUID(id) {
+			return nil, fmt.Errorf("invalid user id")
+		}
+	}
+	for _, t := range []string{req.From, req.To} {
+		if t != "" && !util.IsValidTimeStamp(t) {
+			return nil, fmt.Errorf("from and to must be RFC3339 times")
+		}
+	}
+	if req.Limit <= 0 || req.Limit > config.SearchMaxLimit {
+		req.Limit = config.SearchMaxLimit
+	}
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+
+	resp, err := s.strg.Audit().GetAuditEvents(ctx, req)
+	if err != nil {
+		s.log.Error("---GetAuditEvents--->", logger.Error(err))
+		return nil, err
+	}
+	return resp, nil
+}
+
+// VerifyChain walks the whole log in chain order and recomputes every hash.
+// Events written before the chain existed are only allowed in front of it,
+// an unchained event after the first chained one means the chain was cut.
+func (s *Service) VerifyChain(ctx context.Context) (*models.AuditChainReport, error) {
+	var (
+		report   = &models.AuditChainReport{Valid: true}
+		afterSeq int64
+	)
+
+	for {
+		events, err := s.strg.Audit().GetAuditChain(ctx, afterSeq, verifyBatchSize)
+		if err != nil {
+			s.log.Error("---VerifyChain->GetAuditChain--->", logger.Error(err))
+			return nil, err
+		}
+
+		for _, e := range events {
+			afterSeq = e.Seq
+
+			if e.Hash == "" {
+				if report.Checked == 0 {
+					report.Legacy++
+					continue
+				}
+				return broken(report, e, "event is not chained"), nil
+			}
+			if e.PrevHash != report.HeadHash {
+				return broken(report, e, "prev_hash does not match the event before it"), nil
+			}
+			hash, err := auditlog.Hash(e.PrevHash, e)
+			if err != nil {
+				return broken(report, e, err.Error()), nil
+			}
+			if hash != e.Hash {
+				return broken(report, e, "hash does not match the event"), nil
+			}
+
+			report.Checked++
+			report.HeadSeq = e.Seq
+			report.HeadHash = e.Hash
+		}
+
+		if len(events) < verifyBatchSize {
+			return report, nil
+		}
+	}
+}
+
+func broken(report *models.AuditChainReport, e *models.AuditEvent, reason string) *models.AuditChainReport {
+	report.Valid = false
+	report.BrokenAtSeq = e.Seq
+	report.BrokenEventID = e.ID
+	report.Reason = reason
+	return report
+}
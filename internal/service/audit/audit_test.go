@@ -0,0 +1,265 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/internal/service/servicetest"
+	"github.com/dilmurodov/online_banking/pkg/auditlog"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/dilmurodov/online_banking/storage/postgres"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var auditEventColumns = []string{"guid", "seq", "event_type", "actor_id", "user_id", "resource_type", "resource_id",
+	"ip", "request_id", "details", "before", "after", "prev_hash", "hash", "created_at"}
+
+func newTestService(t *testing.T) (*Service, sqlmock.Sqlmock) {
+	strg, mock := servicetest.NewStore(t)
+
+	return NewService(config.Config{}, zap.NewNop(), strg), mock
+}
+
+// chain hashes the events in order the way the repo writes them, events with an empty
+// CreatedAt are left unchained like the ones written before the chain existed
+func chain(t *testing.T, events ...*models.AuditEvent) {
+	prev := ""
+	for _, e := range events {
+		if e.CreatedAt == "" {
+			e.CreatedAt = "2023-05-15T10:00:00Z"
+			continue
+		}
+		hash, err := auditlog.Hash(prev, e)
+		require.NoError(t, err)
+		e.PrevHash, e.Hash, prev = prev, hash, hash
+	}
+}
+
+func chainRows(t *testing.T, events ...*models.AuditEvent) *sqlmock.Rows {
+	rows := sqlmock.NewRows(auditEventColumns)
+	for _, e := range events {
+		details, err := json.Marshal(e.Details)
+		require.NoError(t, err)
+		var before, after driver.Value
+		if e.Before != nil {
+			before, err = json.Marshal(e.Before)
+			require.NoError(t, err)
+		}
+		if e.After != nil {
+			after, err = json.Marshal(e.After)
+			require.NoError(t, err)
+		}
+		rows.AddRow(e.ID, e.Seq, e.EventType, nilIfEmpty(e.ActorID), nilIfEmpty(e.UserID), e.ResourceType, e.ResourceID,
+			e.IP, e.RequestID, details, before, after, e.PrevHash, e.Hash, e.CreatedAt)
+	}
+	return rows
+}
+
+func nilIfEmpty(s string) driver.Value {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func testEvents() []*models.AuditEvent {
+	return []*models.AuditEvent{
+		{ID: "TestLegacyID", Seq: 1, EventType: models.AuditEventLoginLocked, IP: "10.0.0.1",
+			Details: map[string]interface{}{"key": "ip:10.0.0.1"}},
+		{ID: "TestRoleID", Seq: 2, EventType: models.AuditEventRoleChanged, ActorID: "TestAdminID", UserID: "TestUserID",
+			ResourceType: models.AuditResourceUser, ResourceID: "TestUserID", IP: "127.0.0.1", RequestID: "TestRequestID",
+			Before: map[string]interface{}{"role": models.RoleCustomer}, After: map[string]interface{}{"role": models.RoleSupport},
+			CreatedAt: "2023-05-15T10:00:00.123456Z"},
+		{ID: "TestRequestEventID", Seq: 4, EventType: models.AuditEventRequest, ActorID: "TestAdminID",
+			ResourceType: "role", IP: "127.0.0.1", RequestID: "TestRequestID",
+			Details:   map[string]interface{}{"action": "PUT /api/v1/admin/users/:id/role", "status": 200},
+			CreatedAt: "2023-05-15T15:00:00.5+05:00"},
+	}
+}
+
+func TestAudit_Record(t *testing.T) {
+	r := require.New(t)
+	s, mock := newTestService(t)
+
+	event := &models.AuditEvent{
+		EventType:    models.AuditEventRequest,
+		ActorID:      "TestUserID",
+		ResourceType: "accounts",
+		IP:           "127.0.0.1",
+		Details:      map[string]interface{}{"action": "POST /api/v1/user/accounts", "status": 201},
+	}
+
+	servicetest.ExpectAuditBegin(mock)
+	mock.ExpectQuery(`^SELECT hash FROM audit_events`).WillReturnRows(sqlmock.NewRows([]string{"hash"}).AddRow("TestPrevHash"))
+	// the request id comes from the context
+	mock.ExpectQuery(`^INSERT INTO audit_events`).
+		WithArgs(models.AuditEventRequest, nil, "127.0.0.1", sqlmock.AnyArg(), "TestUserID", "accounts", "", "TestRequestID",
+			nil, nil, "TestPrevHash", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"guid", "seq"}).AddRow("TestEventID", 7))
+	mock.ExpectCommit()
+
+	err := s.Record(auditlog.WithRequestID(context.Background(), "TestRequestID"), event)
+	r.NoError(err)
+	r.Equal("TestEventID", event.ID)
+	r.Equal(int64(7), event.Seq)
+
+	// the hash is chained to the head, and the time it covers survives being read back
+	hash, err := auditlog.Hash("TestPrevHash", event)
+	r.NoError(err)
+	r.Equal(hash, event.Hash)
+	r.NoError(mock.ExpectationsWereMet())
+}
+
+// TestAudit_RecordConcurrently appends from many transactions at once, each one has to chain to
+// the event committed before it. It needs a migrated database, TEST_POSTGRES_DSN points at it
+func TestAudit_RecordConcurrently(t *testing.T) {
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN is not set")
+	}
+	r := require.New(t)
+
+	db, err := sql.Open("postgres", dsn)
+	r.NoError(err)
+	defer db.Close()
+	s := NewService(config.Config{}, zap.NewNop(), postgres.NewStore(db))
+
+	const writers = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- s.Record(context.Background(), &models.AuditEvent{
+				EventType:    models.AuditEventRequest,
+				ResourceType: "accounts",
+				Details:      map[string]interface{}{"action": "TestAudit_RecordConcurrently", "writer": i},
+			})
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		r.NoError(err)
+	}
+
+	report, err := s.VerifyChain(context.Background())
+	r.NoError(err)
+	r.True(report.Valid, report.Reason)
+	r.GreaterOrEqual(report.Checked, writers)
+}
+
+func TestAudit_VerifyChain(t *testing.T) {
+	t.Run("VALID", func(t *testing.T) {
+		r := require.New(t)
+		s, mock := newTestService(t)
+
+		events := testEvents()
+		chain(t, events...)
+		mock.ExpectQuery(`^SELECT (.+?) FROM audit_events`).WithArgs(0, verifyBatchSize).WillReturnRows(chainRows(t, events...))
+
+		report, err := s.VerifyChain(context.Background())
+		r.NoError(err)
+		r.True(report.Valid)
+		r.Equal(2, report.Checked)
+		r.Equal(1, report.Legacy)
+		r.Equal(int64(4), report.HeadSeq)
+		r.Equal(events[2].Hash, report.HeadHash)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("EDITED_EVENT", func(t *testing.T) {
+		r := require.New(t)
+		s, mock := newTestService(t)
+
+		events := testEvents()
+		chain(t, events...)
+		events[1].After["role"] = models.RoleAdmin
+		mock.ExpectQuery(`^SELECT (.+?) FROM audit_events`).WillReturnRows(chainRows(t, events...))
+
+		report, err := s.VerifyChain(context.Background())
+		r.NoError(err)
+		r.False(report.Valid)
+		r.Equal(int64(2), report.BrokenAtSeq)
+		r.Equal("TestRoleID", report.BrokenEventID)
+		r.Equal("hash does not match the event", report.Reason)
+	})
+
+	t.Run("DELETED_EVENT", func(t *testing.T) {
+		r := require.New(t)
+		s, mock := newTestService(t)
+
+		events := testEvents()
+		chain(t, events...)
+		mock.ExpectQuery(`^SELECT (.+?) FROM audit_events`).WillReturnRows(chainRows(t, events[0], events[2]))
+
+		report, err := s.VerifyChain(context.Background())
+		r.NoError(err)
+		r.False(report.Valid)
+		r.Equal(int64(4), report.BrokenAtSeq)
+		r.Equal("prev_hash does not match the event before it", report.Reason)
+	})
+
+	t.Run("UNCHAINED_AFTER_CHAIN", func(t *testing.T) {
+		r := require.New(t)
+		s, mock := newTestService(t)
+
+		events := testEvents()
+		chain(t, events...)
+		events[2].Hash = ""
+		mock.ExpectQuery(`^SELECT (.+?) FROM audit_events`).WillReturnRows(chainRows(t, events...))
+
+		report, err := s.VerifyChain(context.Background())
+		r.NoError(err)
+		r.False(report.Valid)
+		r.Equal(int64(4), report.BrokenAtSeq)
+		r.Equal("event is not chained", report.Reason)
+	})
+}
+
+func TestAudit_GetAuditEvents(t *testing.T) {
+	t.Run("SUCCESS", func(t *testing.T) {
+		r := require.New(t)
+		s, mock := newTestService(t)
+
+		events := testEvents()
+		chain(t, events...)
+		rows := sqlmock.NewRows(append(auditEventColumns, "count"))
+		details, _ := json.Marshal(events[2].Details)
+		rows.AddRow(events[2].ID, events[2].Seq, events[2].EventType, events[2].ActorID, nil, events[2].ResourceType, "",
+			events[2].IP, events[2].RequestID, details, nil, nil, events[2].PrevHash, events[2].Hash, events[2].CreatedAt, 1)
+
+		// the limit is capped
+		mock.ExpectQuery(`^SELECT (.+?) FROM audit_events WHERE actor_id = \$1 AND request_id = \$2`).
+			WithArgs("00000000-0000-4000-8000-0000000000aa", "TestRequestID", config.SearchMaxLimit, 0).
+			WillReturnRows(rows)
+
+		resp, err := s.GetAuditEvents(context.Background(), &models.GetAuditEventsRequest{
+			ActorID:   "00000000-0000-4000-8000-0000000000aa",
+			RequestID: "TestRequestID",
+			Limit:     1000,
+		})
+		r.NoError(err)
+		r.Equal(1, resp.Count)
+		r.Equal("PUT /api/v1/admin/users/:id/role", resp.Events[0].Details["action"])
+		r.Nil(resp.Events[0].Before)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("INVALID_TIME", func(t *testing.T) {
+		r := require.New(t)
+		s, _ := newTestService(t)
+
+		_, err := s.GetAuditEvents(context.Background(), &models.GetAuditEventsRequest{From: "yesterday"})
+		r.Error(err)
+	})
+}
@@ -0,0 +1,30 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/pkg/logger"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/dilmurodov/online_banking/storage"
+)
+
+type ServiceI interface {
+	Record(ctx context.Context, event *models.AuditEvent) error
+	GetAuditEvents(ctx context.Context, req *models.GetAuditEventsRequest) (*models.GetAuditEventsResponse, error)
+	VerifyChain(ctx context.Context) (*models.AuditChainReport, error)
+}
+
+type Service struct {
+	cfg  config.Config
+	log  logger.LoggerI
+	strg storage.StorageI
+}
+
+func NewService(cfg config.Config, log logger.LoggerI, strg storage.StorageI) *Service {
+	return &Service{
+		cfg:  cfg,
+		log:  log,
+		strg: strg,
+	}
+}
@@ -0,0 +1,252 @@
+package beneficiary
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/logger"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/dilmurodov/online_banking/pkg/util"
+)
+
+const nicknameMaxLength = 64
+
+// CoolingOffUntil returns the moment transfers to the beneficiary stop being capped
+func CoolingOffUntil(cfg config.Config, b *models.Beneficiary) time.Time {
+	createdAt, err := time.Parse(time.RFC3339, b.CreatedAt)
+	if err != nil {
+		// Treat unparsable timestamps as freshly added so the cap still applies
+		createdAt = time.Now()
+	}
+	return createdAt.Add(time.Duration(cfg.BeneficiaryCoolingOffHours) * time.Hour)
+}
+
+func (s *Service) withCoolingOff(b *models.Beneficiary) *models.Beneficiary {
+	b.CoolingOffUntil = CoolingOffUntil(s.cfg, b).Format(config.DatabaseTimeLayout)
+	return b
+}
+
+func validateLimits(transferLimit, dailyLimit float64) error {
+	if transferLimit < 0 || dailyLimit < 0 {
+		return fmt.Errorf("limits must not be negative")
+	}
+	if transferLimit > 0 && dailyLimit > 0 && transferLimit > dailyLimit {
+		return fmt.Errorf("transfer_limit must not exceed daily_limit")
+	}
+	return nil
+}
+
+func validateNickname(nickname string) (string, error) {
+	nickname = strings.TrimSpace(nickname)
+	if nickname == "" || utf8.RuneCountInString(nickname) > nicknameMaxLength {
+		return "", fmt.Errorf("nickname must be between 1 and %d characters", nicknameMaxLength)
+	}
+	return nickname, nil
+}
+
+func (s *Service) CreateBeneficiary(ctx context.Context, req *models.CreateBeneficiaryRequest) (*models.Beneficiary, error) {
+	s.log.Info("---CreateBeneficiary--->", logger.Any("req", req))
+
+	nickname, err := validateNickname(req.Nickname)
+	if err != nil {
+		return nil, err
+	}
+	req.Nickname = nickname
+
+	if err := validateLimits(req.TransferLimit, req.DailyLimit); err != nil {
+		return nil, err
+	}
+
+	if !util.IsValidUUID(req.AccountID) {
+		return nil, &customerrors.AccountNotFoundError{Guid: req.AccountID}
+	}
+	account, err := s.strg.Account().GetAccountByID(ctx, &models.GetAccountByIDRequest{ID: req.AccountID})
+	if err != nil {
+		s.log.Error("---CreateBeneficiary->GetAccountByID--->", logger.Error(err))
+		return nil, &customerrors.AccountNotFoundError{Guid: req.AccountID}
+	}
+
+	// the owner is screened before the beneficiary is saved, so a failed screening leaves no
+	// unscreened entry behind. A hit blocks the payments to the account until it is reviewed.
+	_, err = s.sanctions.ScreenUser(ctx, &models.ScreenUserRequest{
+		UserID:      account.UserID,
+		Source:      models.SanctionsSourceBeneficiary,
+		RequestedBy: req.UserID,
+	})
+	if err != nil {
+		s.log.Error("---CreateBeneficiary->ScreenUser--->", logger.Error(err))
+		return nil, err
+	}
+
+	tx, err := s.strg.Audit().BeginTx(ctx)
+	if err != nil {
+		s.log.Error("---CreateBeneficiary->BeginTx--->", logger.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	resp, err := s.strg.Beneficiary().CreateBeneficiary(ctx, tx, req)
+	if err != nil {
+		s.log.Error("---CreateBeneficiary--->", logger.Error(err))
+		return nil, err
+	}
+
+	err = s.strg.Audit().CreateAuditEvent(ctx, tx, &models.AuditEvent{
+		EventType:    models.AuditEventBeneficiaryCreated,
+		ActorID:      req.UserID,
+		UserID:       req.UserID,
+		ResourceType: models.AuditResourceBeneficiary,
+		ResourceID:   resp.ID,
+		After:        auditSnapshot(resp),
+	})
+	if err != nil {
+		s.log.Error("---CreateBeneficiary->CreateAuditEvent--->", logger.Error(err))
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.log.Error("---CreateBeneficiary->Commit--->", logger.Error(err))
+		return nil, err
+	}
+
+	return s.withCoolingOff(resp), nil
+}
+
+func (s *Service) GetBeneficiaryByID(ctx context.Context, req *models.BeneficiaryByIDRequest) (*models.Beneficiary, error) {
+	s.log.Info("---GetBeneficiaryByID--->", logger.Any("req", req))
+
+	resp, err := s.strg.Beneficiary().GetBeneficiaryByID(ctx, req)
+	if err != nil {
+		s.log.Error("---GetBeneficiaryByID--->", logger.Error(err))
+		return nil, err
+	}
+
+	return s.withCoolingOff(resp), nil
+}
+
+func (s *Service) GetBeneficiaries(ctx context.Context, req *models.GetBeneficiariesRequest) (*models.GetBeneficiariesResponse, error) {
+	s.log.Info("---GetBeneficiaries--->", logger.Any("req", req))
+
+	resp, err := s.strg.Beneficiary().GetBeneficiaries(ctx, req)
+	if err != nil {
+		s.log.Error("---GetBeneficiaries--->", logger.Error(err))
+		return nil, err
+	}
+
+	for _, b := range resp.Beneficiaries {
+		s.withCoolingOff(b)
+	}
+
+	return resp, nil
+}
+
+func (s *Service) UpdateBeneficiary(ctx context.Context, req *models.UpdateBeneficiaryRequest) (*models.Beneficiary, error) {
+	s.log.Info("---UpdateBeneficiary--->", logger.Any("req", req))
+
+	nickname, err := validateNickname(req.Nickname)
+	if err != nil {
+		return nil, err
+	}
+	req.Nickname = nickname
+
+	if err := validateLimits(req.TransferLimit, req.DailyLimit); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.strg.Audit().BeginTx(ctx)
+	if err != nil {
+		s.log.Error("---UpdateBeneficiary->BeginTx--->", logger.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	before, err := s.strg.Beneficiary().GetBeneficiaryForUpdate(ctx, tx, &models.BeneficiaryByIDRequest{ID: req.ID, UserID: req.UserID})
+	if err != nil {
+		s.log.Error("---UpdateBeneficiary->GetBeneficiaryForUpdate--->", logger.Error(err))
+		return nil, err
+	}
+
+	resp, err := s.strg.Beneficiary().UpdateBeneficiary(ctx, tx, req)
+	if err != nil {
+		s.log.Error("---UpdateBeneficiary--->", logger.Error(err))
+		return nil, err
+	}
+
+	err = s.strg.Audit().CreateAuditEvent(ctx, tx, &models.AuditEvent{
+		EventType:    models.AuditEventBeneficiaryUpdated,
+		ActorID:      req.UserID,
+		UserID:       req.UserID,
+		ResourceType: models.AuditResourceBeneficiary,
+		ResourceID:   resp.ID,
+		Before:       auditSnapshot(before),
+		After:        auditSnapshot(resp),
+	})
+	if err != nil {
+		s.log.Error("---UpdateBeneficiary->CreateAuditEvent--->", logger.Error(err))
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.log.Error("---UpdateBeneficiary->Commit--->", logger.Error(err))
+		return nil, err
+	}
+
+	return s.withCoolingOff(resp), nil
+}
+
+func (s *Service) DeleteBeneficiary(ctx context.Context, req *models.BeneficiaryByIDRequest) error {
+	s.log.Info("---DeleteBeneficiary--->", logger.Any("req", req))
+
+	tx, err := s.strg.Audit().BeginTx(ctx)
+	if err != nil {
+		s.log.Error("---DeleteBeneficiary->BeginTx--->", logger.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	before, err := s.strg.Beneficiary().GetBeneficiaryForUpdate(ctx, tx, req)
+	if err != nil {
+		s.log.Error("---DeleteBeneficiary->GetBeneficiaryForUpdate--->", logger.Error(err))
+		return err
+	}
+
+	if err = s.strg.Beneficiary().DeleteBeneficiary(ctx, tx, req); err != nil {
+		s.log.Error("---DeleteBeneficiary--->", logger.Error(err))
+		return err
+	}
+
+	err = s.strg.Audit().CreateAuditEvent(ctx, tx, &models.AuditEvent{
+		EventType:    models.AuditEventBeneficiaryDeleted,
+		ActorID:      req.UserID,
+		UserID:       req.UserID,
+		ResourceType: models.AuditResourceBeneficiary,
+		ResourceID:   req.ID,
+		Before:       auditSnapshot(before),
+	})
+	if err != nil {
+		s.log.Error("---DeleteBeneficiary->CreateAuditEvent--->", logger.Error(err))
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.log.Error("---DeleteBeneficiary->Commit--->", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// auditSnapshot is the part of a beneficiary the audit log keeps before and after a change
+func auditSnapshot(b *models.Beneficiary) map[string]interface{} {
+	return map[string]interface{}{
+		"nickname":       b.Nickname,
+		"account_id":     b.AccountID,
+		"transfer_limit": b.TransferLimit,
+		"daily_limit":    b.DailyLimit,
+	}
+}
@@ -0,0 +1,164 @@
+package beneficiary
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/internal/service/sanctions"
+	"github.com/dilmurodov/online_banking/internal/service/servicetest"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	mock_storage "github.com/dilmurodov/online_banking/storage/mock"
+	"github.com/dilmurodov/online_banking/storage/postgres"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const testAccountID = "5f0c6a3e-4b1d-4c8e-9a7b-2d3e4f5a6b7c"
+
+func TestBeneficiary_CreateBeneficiary(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db, mock, err := sqlmock.New()
+	r.NoError(err)
+
+	s := NewService(
+		config.Config{BeneficiaryCoolingOffHours: 24},
+		zap.NewNop(),
+		postgres.NewStore(db),
+		sanctions.NewService(config.Config{}, zap.NewNop(), nil),
+	)
+
+	createdAt := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC).Format(time.RFC3339)
+
+	account := sqlmock.NewRows([]string{"guid", "user_id", "balance", "product", "accrued_interest", "overdraft_limit", "created_at", "updated_at", "status", "closed_at"}).AddRow(testAccountID, "TestUserID2", 0.0, "current", "0", 0.0, createdAt, createdAt, "active", nil)
+	created := sqlmock.NewRows([]string{"guid", "user_id", "nickname", "account_id", "transfer_limit", "daily_limit", "created_at", "updated_at"}).AddRow("TestBeneficiaryID", "TestUserID", "Mom", testAccountID, 0.0, 0.0, createdAt, createdAt)
+
+	mock.ExpectQuery(`^SELECT (.+?) FROM accounts * `).WithArgs(testAccountID).WillReturnRows(account)
+	servicetest.ExpectAuditBegin(mock)
+	mock.ExpectQuery(`^INSERT INTO beneficiaries`).WithArgs("TestUserID", "Mom", testAccountID, 0.0, 0.0).WillReturnRows(created)
+	servicetest.ExpectAuditEvent(mock, models.AuditEventBeneficiaryCreated, "TestUserID", "", []byte("null"), "TestUserID", models.AuditResourceBeneficiary, "TestBeneficiaryID", "",
+		nil, `{"account_id":"`+testAccountID+`","daily_limit":0,"nickname":"Mom","transfer_limit":0}`, "", sqlmock.AnyArg(), sqlmock.AnyArg())
+	mock.ExpectCommit()
+
+	repo := mock_storage.NewMockBeneficiaryRepoI(ctrl)
+
+	t.Run("SUCCESS", func(t *testing.T) {
+
+		ctx := context.Background()
+
+		in := &models.CreateBeneficiaryRequest{
+			UserID:    "TestUserID",
+			Nickname:  "  Mom ",
+			AccountID: testAccountID,
+		}
+
+		repo.EXPECT().CreateBeneficiary(ctx, gomock.Any(), in).Return(&models.Beneficiary{ID: "TestBeneficiaryID"}, nil).Times(1).AnyTimes()
+
+		resp, err := s.CreateBeneficiary(ctx, in)
+		r.NoError(err)
+		r.Equal("Mom", resp.Nickname)
+		r.Equal("2021-01-02T00:00:00Z", resp.CoolingOffUntil)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("SCREENING_FAILED", func(t *testing.T) {
+		r := require.New(t)
+
+		list := filepath.Join(t.TempDir(), "sdn.csv")
+		r.NoError(os.WriteFile(list, []byte(`36,"IVANOV, Ivan Petrovich","individual","SDGT",-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,-0-`+"\n"), 0o600))
+
+		db, mock, err := sqlmock.New()
+		r.NoError(err)
+		strg := postgres.NewStore(db)
+		s := NewService(
+			config.Config{BeneficiaryCoolingOffHours: 24},
+			zap.NewNop(),
+			strg,
+			sanctions.NewService(config.Config{SanctionsListFile: list, SanctionsMatchThreshold: 0.92}, zap.NewNop(), strg),
+		)
+
+		// the owner can't be screened, the beneficiary is not saved
+		mock.ExpectQuery(`^SELECT (.+?) FROM accounts * `).WithArgs(testAccountID).
+			WillReturnRows(sqlmock.NewRows([]string{"guid", "user_id", "balance", "product", "accrued_interest", "overdraft_limit", "created_at", "updated_at", "status", "closed_at"}).
+				AddRow(testAccountID, "TestUserID2", 0.0, "current", "0", 0.0, createdAt, createdAt, "active", nil))
+		mock.ExpectQuery(`^SELECT (.+?) FROM "users"`).WithArgs("TestUserID2").WillReturnError(sql.ErrConnDone)
+
+		_, err = s.CreateBeneficiary(context.Background(), &models.CreateBeneficiaryRequest{
+			UserID:    "TestUserID",
+			Nickname:  "Mom",
+			AccountID: testAccountID,
+		})
+		r.Error(err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("INVALID_LIMITS", func(t *testing.T) {
+
+		_, err := s.CreateBeneficiary(context.Background(), &models.CreateBeneficiaryRequest{
+			UserID:        "TestUserID",
+			Nickname:      "Mom",
+			AccountID:     testAccountID,
+			TransferLimit: 500,
+			DailyLimit:    100,
+		})
+		r.Error(err)
+	})
+}
+
+func TestBeneficiary_UpdateBeneficiary(t *testing.T) {
+	r := require.New(t)
+
+	strg, mock := servicetest.NewStore(t)
+	s := NewService(
+		config.Config{BeneficiaryCoolingOffHours: 24},
+		zap.NewNop(),
+		strg,
+		sanctions.NewService(config.Config{}, zap.NewNop(), nil),
+	)
+
+	createdAt := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC).Format(time.RFC3339)
+	columns := []string{"guid", "user_id", "nickname", "account_id", "transfer_limit", "daily_limit", "created_at", "updated_at"}
+
+	servicetest.ExpectAuditBegin(mock)
+	mock.ExpectQuery(`^SELECT (.+?) FROM beneficiaries(.+?)FOR UPDATE`).WithArgs("TestBeneficiaryID", "TestUserID").
+		WillReturnRows(sqlmock.NewRows(columns).AddRow("TestBeneficiaryID", "TestUserID", "Mom", testAccountID, 0.0, 0.0, createdAt, createdAt))
+	mock.ExpectQuery(`^UPDATE beneficiaries SET`).WithArgs("Mother", 100.0, 500.0, "TestBeneficiaryID", "TestUserID").
+		WillReturnRows(sqlmock.NewRows(columns).AddRow("TestBeneficiaryID", "TestUserID", "Mother", testAccountID, 100.0, 500.0, createdAt, createdAt))
+	servicetest.ExpectAuditEvent(mock, models.AuditEventBeneficiaryUpdated, "TestUserID", "", []byte("null"), "TestUserID", models.AuditResourceBeneficiary, "TestBeneficiaryID", "",
+		`{"account_id":"`+testAccountID+`","daily_limit":0,"nickname":"Mom","transfer_limit":0}`,
+		`{"account_id":"`+testAccountID+`","daily_limit":500,"nickname":"Mother","transfer_limit":100}`, "", sqlmock.AnyArg(), sqlmock.AnyArg())
+	mock.ExpectCommit()
+
+	t.Run("SUCCESS", func(t *testing.T) {
+		resp, err := s.UpdateBeneficiary(context.Background(), &models.UpdateBeneficiaryRequest{
+			ID:            "TestBeneficiaryID",
+			UserID:        "TestUserID",
+			Nickname:      "Mother",
+			TransferLimit: 100,
+			DailyLimit:    500,
+		})
+		r.NoError(err)
+		r.Equal("Mother", resp.Nickname)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	servicetest.ExpectAuditBegin(mock)
+	mock.ExpectQuery(`^SELECT (.+?) FROM beneficiaries(.+?)FOR UPDATE`).WithArgs("TestOtherID", "TestUserID").WillReturnRows(sqlmock.NewRows(columns))
+	mock.ExpectRollback()
+
+	t.Run("NOT_FOUND", func(t *testing.T) {
+		_, err := s.UpdateBeneficiary(context.Background(), &models.UpdateBeneficiaryRequest{ID: "TestOtherID", UserID: "TestUserID", Nickname: "Mother"})
+		r.Equal(&customerrors.BeneficiaryNotFoundError{Guid: "TestOtherID"}, err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+}
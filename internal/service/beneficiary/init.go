@@ -0,0 +1,35 @@
+package beneficiary
+
+import (
+	"context"
+
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/internal/service/sanctions"
+	"github.com/dilmurodov/online_banking/pkg/logger"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/dilmurodov/online_banking/storage"
+)
+
+type ServiceI interface {
+	CreateBeneficiary(ctx context.Context, req *models.CreateBeneficiaryRequest) (*models.Beneficiary, error)
+	GetBeneficiaryByID(ctx context.Context, req *models.BeneficiaryByIDRequest) (*models.Beneficiary, error)
+	GetBeneficiaries(ctx context.Context, req *models.GetBeneficiariesRequest) (*models.GetBeneficiariesResponse, error)
+	UpdateBeneficiary(ctx context.Context, req *models.UpdateBeneficiaryRequest) (*models.Beneficiary, error)
+	DeleteBeneficiary(ctx context.Context, req *models.BeneficiaryByIDRequest) error
+}
+
+type Service struct {
+	cfg       config.Config
+	log       logger.LoggerI
+	strg      storage.StorageI
+	sanctions sanctions.ServiceI
+}
+
+func NewService(cfg config.Config, log logger.LoggerI, strg storage.StorageI, sanctionsService sanctions.ServiceI) *Service {
+	return &Service{
+		cfg:       cfg,
+		log:       log,
+		strg:      strg,
+		sanctions: sanctionsService,
+	}
+}
@@ -0,0 +1,511 @@
+package deposit
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/internal/service/account"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/helper"
+	"github.com/dilmurodov/online_banking/pkg/interest"
+	"github.com/dilmurodov/online_banking/pkg/logger"
+	"github.com/dilmurodov/online_banking/pkg/models"
+)
+
+func (s *Service) GetDepositTerms(ctx context.Context) (*models.GetDepositTermsResponse, error) {
+	resp, err := s.strg.Deposit().GetDepositTerms(ctx)
+	if err != nil {
+		s.log.Error("---GetDepositTerms--->", logger.Error(err))
+		return nil, err
+	}
+	return resp, nil
+}
+
+// OpenDeposit opens a deposit account and moves the principal into it from the customer's current account
+func (s *Service) OpenDeposit(ctx context.Context, req *models.OpenDepositRequest) (*models.TermDeposit, error) {
+	s.log.Info("---OpenDeposit--->", logger.Any("req", req))
+
+	if req.InterestPayout == "" {
+		req.InterestPayout = models.DepositInterestAtMaturity
+	}
+	if req.InterestPayout != models.DepositInterestAtMaturity && req.InterestPayout != models.DepositInterestMonthly {
+		return nil, fmt.Errorf("interest_payout must be %s or %s", models.DepositInterestAtMaturity, models.DepositInterestMonthly)
+	}
+	if req.OnMaturity == "" {
+		req.OnMaturity = models.DepositOnMaturityPayout
+	}
+	if err := validateOnMaturity(req.OnMaturity); err != nil {
+		return nil, err
+	}
+
+	amount, err := interest.ParseAmount(req.Amount)
+	if err != nil || amount.Sign() <= 0 {
+		return nil, fmt.Errorf("amount must be positive with at most %d decimal places", interest.PostingScale)
+	}
+
+	term, err := s.strg.Deposit().GetDepositTerm(ctx, req.TermMonths)
+	if err != nil {
+		s.log.Error("---OpenDeposit->GetDepositTerm--->", logger.Error(err))
+		return nil, err
+	}
+	if term == nil {
+		return nil, fmt.Errorf("deposits for %d months are not offered", req.TermMonths)
+	}
+	if minAmount, err := interest.ParseDecimal(term.MinAmount); err == nil && amount.Cmp(minAmount) < 0 {
+		return nil, fmt.Errorf("the minimum amount for this term is %s", term.MinAmount)
+	}
+
+	if err := account.CheckPermission(ctx, s.strg, req.SourceAccountID, req.UserID, models.AccountPermissionTransact); err != nil {
+		return nil, err
+	}
+	source, err := s.strg.Account().GetAccountByID(ctx, &models.GetAccountByIDRequest{ID: req.SourceAccountID})
+	if err != nil {
+		return nil, &customerrors.AccountNotFoundError{Guid: req.SourceAccountID}
+	}
+	if source.Product != models.ProductCurrent {
+		return nil, fmt.Errorf("deposits can only be funded from a current account")
+	}
+	// the posting below would let the overdraft cover the principal
+	if source.OverdraftLimit > 0 && math.Round(source.Balance*100) < math.Round(req.Amount*100) {
+		return nil, fmt.Errorf("a deposit can't be funded from an overdraft")
+	}
+
+	openedOn := helper.BusinessToday(s.cfg.BusinessTimezone)
+
+	tx, err := s.strg.TxRepo().BeginTx(ctx)
+	if err != nil {
+		s.log.Error("---OpenDeposit->BeginTx--->", logger.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	deposit, err := s.strg.Deposit().CreateDeposit(ctx, tx, &models.TermDeposit{
+		UserID:              req.UserID,
+		SourceAccountID:     source.ID,
+		Principal:           interest.FormatDecimal(amount, interest.PostingScale),
+		TermMonths:          term.TermMonths,
+		AnnualRate:          term.AnnualRate,
+		EarlyWithdrawalRate: term.EarlyWithdrawalRate,
+		DayCount:            term.DayCount,
+		InterestPayout:      req.InterestPayout,
+		OnMaturity:          req.OnMaturity,
+		OpenedOn:            openedOn.Format(config.BusinessDateLayout),
+		MaturityDate:        interest.AddMonths(openedOn, term.TermMonths).Format(config.BusinessDateLayout),
+	})
+	if err != nil {
+		s.log.Error("---OpenDeposit->CreateDeposit--->", logger.Error(err))
+		return nil, err
+	}
+
+	_, err = s.payment.PostTransfer(ctx, tx, &models.PostTransferRequest{
+		FromAccountID:     source.ID,
+		ToAccountID:       deposit.AccountID,
+		Amount:            deposit.Principal,
+		Description:       fmt.Sprintf("Term deposit for %d months", deposit.TermMonths),
+		CustomerInitiated: true,
+	})
+	if err != nil {
+		s.log.Error("---OpenDeposit->PostTransfer--->", logger.Error(err))
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.log.Error("---OpenDeposit->Commit--->", logger.Error(err))
+		return nil, err
+	}
+
+	return deposit, nil
+}
+
+func (s *Service) GetDeposits(ctx context.Context, req *models.GetDepositsRequest) (*models.GetDepositsResponse, error) {
+	resp, err := s.strg.Deposit().GetDeposits(ctx, req)
+	if err != nil {
+		s.log.Error("---GetDeposits--->", logger.Error(err))
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *Service) GetDepositByID(ctx context.Context, req *models.DepositByIDRequest) (*models.TermDeposit, error) {
+	resp, err := s.strg.Deposit().GetDepositByID(ctx, req)
+	if err != nil {
+		s.log.Error("---GetDepositByID--->", logger.Error(err))
+		return nil, err
+	}
+	return resp, nil
+}
+
+// UpdateDepositInstruction changes what happens to an active deposit at maturity
+func (s *Service) UpdateDepositInstruction(ctx context.Context, req *models.UpdateDepositInstructionRequest) (*models.TermDeposit, error) {
+	s.log.Info("---UpdateDepositInstruction--->", logger.Any("req", req))
+
+	if err := validateOnMaturity(req.OnMaturity); err != nil {
+		return nil, err
+	}
+
+	// checks that the deposit belongs to the user
+	if _, err := s.strg.Deposit().GetDepositByID(ctx, &models.DepositByIDRequest{ID: req.ID, UserID: req.UserID}); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.strg.TxRepo().BeginTx(ctx)
+	if err != nil {
+		s.log.Error("---UpdateDepositInstruction->BeginTx--->", logger.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	deposit, err := s.strg.Deposit().GetDepositForUpdate(ctx, tx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if deposit.Status != models.DepositStatusActive {
+		return nil, &customerrors.DepositStateError{Status: deposit.Status}
+	}
+
+	deposit.OnMaturity = req.OnMaturity
+	if err = s.strg.Deposit().UpdateDeposit(ctx, tx, deposit); err != nil {
+		s.log.Error("---UpdateDepositInstruction->UpdateDeposit--->", logger.Error(err))
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.log.Error("---UpdateDepositInstruction->Commit--->", logger.Error(err))
+		return nil, err
+	}
+
+	return deposit, nil
+}
+
+// WithdrawDeposit closes an active deposit before maturity. Interest is recalculated at the early
+// withdrawal rate for the elapsed days; interest already paid above that is taken back from the principal.
+func (s *Service) WithdrawDeposit(ctx context.Context, req *models.DepositByIDRequest) (*models.WithdrawDepositResponse, error) {
+	s.log.Info("---WithdrawDeposit--->", logger.Any("req", req))
+
+	if _, err := s.strg.Deposit().GetDepositByID(ctx, req); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.strg.TxRepo().BeginTx(ctx)
+	if err != nil {
+		s.log.Error("---WithdrawDeposit->BeginTx--->", logger.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	deposit, err := s.strg.Deposit().GetDepositForUpdate(ctx, tx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if deposit.Status != models.DepositStatusActive {
+		return nil, &customerrors.DepositStateError{Status: deposit.Status}
+	}
+
+	terms, err := parseDeposit(deposit)
+	if err != nil {
+		return nil, err
+	}
+
+	today := helper.BusinessToday(s.cfg.BusinessTimezone)
+	// a matured deposit is settled by the end-of-day job under its own instruction
+	if !today.Before(terms.maturityDate) {
+		return nil, &customerrors.DepositStateError{Status: models.DepositStatusMatured}
+	}
+
+	earned := interest.RoundDown(
+		new(big.Rat).Mul(
+			new(big.Rat).Mul(terms.principal, terms.earlyRate),
+			interest.YearFraction(interest.DayCount(deposit.DayCount), terms.openedOn, today),
+		),
+		interest.PostingScale,
+	)
+
+	resp := &models.WithdrawDepositResponse{
+		Transactions: make([]*models.Transaction, 0),
+	}
+	post := func(from, to string, amount *big.Rat, description string) error {
+		if amount.Sign() <= 0 {
+			return nil
+		}
+		posted, err := s.payment.PostTransfer(ctx, tx, &models.PostTransferRequest{
+			FromAccountID:     from,
+			ToAccountID:       to,
+			Amount:            interest.FormatDecimal(amount, interest.PostingScale),
+			Description:       description,
+			CustomerInitiated: true,
+		})
+		if err != nil {
+			s.log.Error("---WithdrawDeposit->PostTransfer--->", logger.Error(err))
+			return err
+		}
+		resp.Transactions = append(resp.Transactions, posted.Transactions...)
+		return nil
+	}
+
+	penalty := new(big.Rat).Sub(terms.paidInterest, earned)
+	topUp := new(big.Rat)
+	if penalty.Sign() < 0 {
+		topUp.Neg(penalty)
+		penalty.SetInt64(0)
+	}
+	payout := new(big.Rat).Sub(terms.principal, penalty)
+
+	if err = post(config.InterestExpenseAccountID, deposit.SourceAccountID, topUp, "Term deposit interest, early withdrawal"); err != nil {
+		return nil, err
+	}
+	if err = post(deposit.AccountID, config.InterestExpenseAccountID, penalty, "Term deposit early withdrawal penalty"); err != nil {
+		return nil, err
+	}
+	if err = post(deposit.AccountID, deposit.SourceAccountID, payout, "Term deposit early withdrawal"); err != nil {
+		return nil, err
+	}
+
+	deposit.Status = models.DepositStatusWithdrawn
+	deposit.PaidInterest = interest.FormatDecimal(earned, interest.PostingScale)
+	deposit.InterestPaidUntil = today.Format(config.BusinessDateLayout)
+	if err = s.strg.Deposit().UpdateDeposit(ctx, tx, deposit); err != nil {
+		s.log.Error("---WithdrawDeposit->UpdateDeposit--->", logger.Error(err))
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.log.Error("---WithdrawDeposit->Commit--->", logger.Error(err))
+		return nil, err
+	}
+
+	resp.Deposit = deposit
+	resp.Interest = deposit.PaidInterest
+	resp.Penalty = interest.FormatDecimal(penalty, interest.PostingScale)
+	resp.Payout = interest.FormatDecimal(new(big.Rat).Add(payout, topUp), interest.PostingScale)
+
+	return resp, nil
+}
+
+// ProcessDueDeposits pays the monthly interest and settles the maturities that fall due once the
+// business date closes. Every deposit is settled in its own transaction under a row lock,
+// so a repeated run finds nothing left to do.
+func (s *Service) ProcessDueDeposits(ctx context.Context, businessDate time.Time) error {
+	due := businessDate.AddDate(0, 0, 1)
+	s.log.Info("---ProcessDueDeposits--->", logger.String("due", due.Format(config.BusinessDateLayout)))
+
+	ids, err := s.strg.Deposit().GetDueDepositIDs(ctx, due.Format(config.BusinessDateLayout))
+	if err != nil {
+		s.log.Error("---ProcessDueDeposits->GetDueDepositIDs--->", logger.Error(err))
+		return err
+	}
+
+	var failed int
+	for _, id := range ids {
+		if err := s.settleDeposit(ctx, id, due); err != nil {
+			s.log.Error("---ProcessDueDeposits->settleDeposit--->", logger.String("deposit_id", id), logger.Error(err))
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("settlement failed for %d deposits", failed)
+	}
+	return nil
+}
+
+func (s *Service) settleDeposit(ctx context.Context, id string, due time.Time) error {
+	tx, err := s.strg.TxRepo().BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	deposit, err := s.strg.Deposit().GetDepositForUpdate(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if deposit.Status != models.DepositStatusActive {
+		return nil
+	}
+
+	terms, err := parseDeposit(deposit)
+	if err != nil {
+		return err
+	}
+
+	// monthly payments fall on the monthly anniversaries of the opening date, the last period ends at maturity
+	for deposit.InterestPayout == models.DepositInterestMonthly {
+		next := interest.AddMonths(terms.openedOn, deposit.InterestPeriodsPaid+1)
+		if next.After(due) || !next.Before(terms.maturityDate) {
+			break
+		}
+		if _, err = s.payInterest(ctx, tx, deposit, terms, next, deposit.SourceAccountID); err != nil {
+			return err
+		}
+		deposit.InterestPeriodsPaid++
+	}
+
+	if !terms.maturityDate.After(due) {
+		if err = s.mature(ctx, tx, deposit, terms); err != nil {
+			return err
+		}
+	}
+
+	if err = s.strg.Deposit().UpdateDeposit(ctx, tx, deposit); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *Service) mature(ctx context.Context, tx *sql.Tx, deposit *models.TermDeposit, terms *depositTerms) error {
+	var (
+		term *models.DepositTerm
+		err  error
+	)
+
+	rollover := deposit.OnMaturity == models.DepositOnMaturityRollover
+	if rollover {
+		// a term the bank stopped offering can't be renewed, the deposit is paid out instead
+		term, err = s.strg.Deposit().GetDepositTerm(ctx, deposit.TermMonths)
+		if err != nil {
+			return err
+		}
+		rollover = term != nil
+	}
+
+	// interest paid at maturity is added to a renewed deposit, otherwise it goes to the source account
+	target := deposit.SourceAccountID
+	if rollover && deposit.InterestPayout == models.DepositInterestAtMaturity {
+		target = deposit.AccountID
+	}
+
+	paid, err := s.payInterest(ctx, tx, deposit, terms, terms.maturityDate, target)
+	if err != nil {
+		return err
+	}
+	deposit.InterestPeriodsPaid++
+
+	if !rollover {
+		_, err = s.payment.PostTransfer(ctx, tx, &models.PostTransferRequest{
+			FromAccountID: deposit.AccountID,
+			ToAccountID:   deposit.SourceAccountID,
+			Amount:        deposit.Principal,
+			Description:   "Term deposit maturity",
+		})
+		if err != nil {
+			return err
+		}
+		deposit.Status = models.DepositStatusMatured
+		return nil
+	}
+
+	principal := terms.principal
+	if target == deposit.AccountID {
+		principal = new(big.Rat).Add(principal, paid)
+	}
+
+	_, err = s.strg.Deposit().CreateDeposit(ctx, tx, &models.TermDeposit{
+		UserID:              deposit.UserID,
+		AccountID:           deposit.AccountID,
+		SourceAccountID:     deposit.SourceAccountID,
+		Principal:           interest.FormatDecimal(principal, interest.PostingScale),
+		TermMonths:          term.TermMonths,
+		AnnualRate:          term.AnnualRate,
+		EarlyWithdrawalRate: term.EarlyWithdrawalRate,
+		DayCount:            term.DayCount,
+		InterestPayout:      deposit.InterestPayout,
+		OnMaturity:          deposit.OnMaturity,
+		OpenedOn:            deposit.MaturityDate,
+		MaturityDate:        interest.AddMonths(terms.maturityDate, term.TermMonths).Format(config.BusinessDateLayout),
+		RolledFromID:        deposit.ID,
+	})
+	if err != nil {
+		return err
+	}
+	deposit.Status = models.DepositStatusRolledOver
+
+	return nil
+}
+
+// payInterest pays the contract interest for the days from the last payment up to the given date
+func (s *Service) payInterest(ctx context.Context, tx *sql.Tx, deposit *models.TermDeposit, terms *depositTerms, until time.Time, target string) (*big.Rat, error) {
+	from, err := time.Parse(config.BusinessDateLayout, deposit.InterestPaidUntil)
+	if err != nil {
+		return nil, err
+	}
+
+	amount := interest.RoundDown(
+		new(big.Rat).Mul(
+			new(big.Rat).Mul(terms.principal, terms.rate),
+			interest.YearFraction(interest.DayCount(deposit.DayCount), from, until),
+		),
+		interest.PostingScale,
+	)
+
+	if amount.Sign() > 0 {
+		_, err = s.payment.PostTransfer(ctx, tx, &models.PostTransferRequest{
+			FromAccountID: config.InterestExpenseAccountID,
+			ToAccountID:   target,
+			Amount:        interest.FormatDecimal(amount, interest.PostingScale),
+			Description:   "Term deposit interest",
+			Reference:     "TDI-" + until.Format(config.BusinessDateLayout),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	terms.paidInterest.Add(terms.paidInterest, amount)
+	deposit.PaidInterest = interest.FormatDecimal(terms.paidInterest, interest.PostingScale)
+	deposit.InterestPaidUntil = until.Format(config.BusinessDateLayout)
+
+	return amount, nil
+}
+
+// depositTerms holds the parsed decimal and date fields of a deposit
+type depositTerms struct {
+	principal    *big.Rat
+	rate         *big.Rat
+	earlyRate    *big.Rat
+	paidInterest *big.Rat
+	openedOn     time.Time
+	maturityDate time.Time
+}
+
+func parseDeposit(d *models.TermDeposit) (*depositTerms, error) {
+	var (
+		t   depositTerms
+		err error
+	)
+
+	for _, f := range []struct {
+		dst   **big.Rat
+		value string
+	}{
+		{&t.principal, d.Principal},
+		{&t.rate, d.AnnualRate},
+		{&t.earlyRate, d.EarlyWithdrawalRate},
+		{&t.paidInterest, d.PaidInterest},
+	} {
+		if *f.dst, err = interest.ParseDecimal(f.value); err != nil {
+			return nil, fmt.Errorf("deposit %s: %w", d.ID, err)
+		}
+	}
+
+	if t.openedOn, err = time.Parse(config.BusinessDateLayout, d.OpenedOn); err != nil {
+		return nil, err
+	}
+	if t.maturityDate, err = time.Parse(config.BusinessDateLayout, d.MaturityDate); err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+func validateOnMaturity(onMaturity string) error {
+	if onMaturity != models.DepositOnMaturityPayout && onMaturity != models.DepositOnMaturityRollover {
+		return fmt.Errorf("on_maturity must be %s or %s", models.DepositOnMaturityPayout, models.DepositOnMaturityRollover)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package deposit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/internal/service/payment"
+	"github.com/dilmurodov/online_banking/internal/service/servicetest"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var depositRowColumns = []string{
+	"guid", "user_id", "account_id", "source_account_id", "principal", "term_months", "annual_rate",
+	"early_withdrawal_rate", "day_count", "interest_payout", "on_maturity", "status", "opened_on",
+	"maturity_date", "interest_paid_until", "interest_periods_paid", "paid_interest", "rolled_from_id",
+	"closed_at", "created_at", "updated_at",
+}
+
+func newTestService(t *testing.T) (*Service, sqlmock.Sqlmock) {
+	strg, mock := servicetest.NewStore(t)
+
+	return NewService(
+		config.Config{BusinessTimezone: "UTC"},
+		zap.NewNop(),
+		strg,
+		payment.NewService(config.Config{}, zap.NewNop(), strg),
+	), mock
+}
+
+func TestDeposit_ProcessDueDeposits(t *testing.T) {
+	r := require.New(t)
+
+	s, mock := newTestService(t)
+
+	// closing 14 April settles the deposit maturing on 15 April
+	businessDate := time.Date(2023, time.April, 14, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(`^SELECT guid FROM term_deposits`).
+		WithArgs("2023-04-15").
+		WillReturnRows(sqlmock.NewRows([]string{"guid"}).AddRow("TestDepositID"))
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT (.+) FROM term_deposits WHERE guid = \$1 FOR UPDATE`).
+		WithArgs("TestDepositID").
+		WillReturnRows(sqlmock.NewRows(depositRowColumns).AddRow(
+			"TestDepositID", "TestUserID", "TestDepositAccountID", "TestAccountID", "1000.00", 3, "0.05",
+			"0.01", "ACT/365", "maturity", "payout", "active", "2023-01-15",
+			"2023-04-15", "2023-01-15", 0, "0.00", nil,
+			nil, "2023-01-15T10:00:00Z", "2023-01-15T10:00:00Z",
+		))
+
+	// 90 days at 5%: 12.328... rounded down to the cent
+	servicetest.ExpectPosting(mock, config.InterestExpenseAccountID, "TestAccountID", 12.32, "12.32", "Term deposit interest", "TDI-2023-04-15")
+	servicetest.ExpectPosting(mock, "TestDepositAccountID", "TestAccountID", 1000, "1000.00", "Term deposit maturity", "")
+
+	mock.ExpectExec(`^UPDATE term_deposits SET`).
+		WithArgs("TestDepositID", "matured", "payout", "2023-04-15", 1, "12.32").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	t.Run("MATURITY_PAYOUT", func(t *testing.T) {
+		err := s.ProcessDueDeposits(context.Background(), businessDate)
+		r.NoError(err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+}
+
+func TestDeposit_OpenDeposit_NotOffered(t *testing.T) {
+	r := require.New(t)
+
+	s, mock := newTestService(t)
+
+	mock.ExpectQuery(`^SELECT (.+) FROM term_deposit_terms`).
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"term_months", "annual_rate", "early_withdrawal_rate", "day_count", "min_amount"}))
+
+	t.Run("FAIL", func(t *testing.T) {
+		_, err := s.OpenDeposit(context.Background(), &models.OpenDepositRequest{
+			UserID:          "TestUserID",
+			SourceAccountID: "TestAccountID",
+			Amount:          500,
+			TermMonths:      5,
+		})
+		r.Error(err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+}
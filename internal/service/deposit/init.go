@@ -0,0 +1,38 @@
+package deposit
+
+import (
+	"context"
+	"time"
+
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/internal/service/payment"
+	"github.com/dilmurodov/online_banking/pkg/logger"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/dilmurodov/online_banking/storage"
+)
+
+type ServiceI interface {
+	GetDepositTerms(ctx context.Context) (*models.GetDepositTermsResponse, error)
+	OpenDeposit(ctx context.Context, req *models.OpenDepositRequest) (*models.TermDeposit, error)
+	GetDeposits(ctx context.Context, req *models.GetDepositsRequest) (*models.GetDepositsResponse, error)
+	GetDepositByID(ctx context.Context, req *models.DepositByIDRequest) (*models.TermDeposit, error)
+	UpdateDepositInstruction(ctx context.Context, req *models.UpdateDepositInstructionRequest) (*models.TermDeposit, error)
+	WithdrawDeposit(ctx context.Context, req *models.DepositByIDRequest) (*models.WithdrawDepositResponse, error)
+	ProcessDueDeposits(ctx context.Context, businessDate time.Time) error
+}
+
+type Service struct {
+	cfg     config.Config
+	log     logger.LoggerI
+	strg    storage.StorageI
+	payment payment.ServiceI
+}
+
+func NewService(cfg config.Config, log logger.LoggerI, strg storage.StorageI, paymentService payment.ServiceI) *Service {
+	return &Service{
+		cfg:     cfg,
+		log:     log,
+		strg:    strg,
+		payment: paymentService,
+	}
+}
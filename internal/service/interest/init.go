@@ -0,0 +1,35 @@
+package interest
+
+import (
+	"context"
+	"time"
+
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/internal/service/payment"
+	"github.com/dilmurodov/online_banking/pkg/logger"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/dilmurodov/online_banking/storage"
+)
+
+type ServiceI interface {
+	GetProducts(ctx context.Context) (*models.GetProductsResponse, error)
+	GetInterestAccruals(ctx context.Context, req *models.GetInterestAccrualsRequest) (*models.GetInterestAccrualsResponse, error)
+	AccrueDaily(ctx context.Context, businessDate time.Time) error
+	Capitalize(ctx context.Context, businessDate time.Time) error
+}
+
+type Service struct {
+	cfg     config.Config
+	log     logger.LoggerI
+	strg    storage.StorageI
+	payment payment.ServiceI
+}
+
+func NewService(cfg config.Config, log logger.LoggerI, strg storage.StorageI, paymentService payment.ServiceI) *Service {
+	return &Service{
+		cfg:     cfg,
+		log:     log,
+		strg:    strg,
+		payment: paymentService,
+	}
+}
@@ -0,0 +1,197 @@
+package interest
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/pkg/interest"
+	"github.com/dilmurodov/online_banking/pkg/logger"
+	"github.com/dilmurodov/online_banking/pkg/models"
+)
+
+func (s *Service) GetProducts(ctx context.Context) (*models.GetProductsResponse, error) {
+	resp, err := s.strg.Interest().GetProducts(ctx)
+	if err != nil {
+		s.log.Error("---GetProducts--->", logger.Error(err))
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *Service) GetInterestAccruals(ctx context.Context, req *models.GetInterestAccrualsRequest) (*models.GetInterestAccrualsResponse, error) {
+	s.log.Info("---GetInterestAccruals--->", logger.Any("req", req))
+
+	for _, date := range []string{req.From, req.To} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse(config.BusinessDateLayout, date); err != nil {
+			return nil, fmt.Errorf("dates must be in %s format", config.BusinessDateLayout)
+		}
+	}
+
+	account, err := s.strg.Account().GetAccountByID(ctx, &models.GetAccountByIDRequest{ID: req.AccountID})
+	if err != nil {
+		s.log.Error("---GetInterestAccruals->GetAccountByID--->", logger.Error(err))
+		return nil, err
+	}
+
+	resp, err := s.strg.Interest().GetInterestAccruals(ctx, req)
+	if err != nil {
+		s.log.Error("---GetInterestAccruals--->", logger.Error(err))
+		return nil, err
+	}
+	resp.AccruedInterest = account.AccruedInterest
+
+	return resp, nil
+}
+
+// AccrueDaily accrues one day of interest on the end-of-day balance of every interest-bearing account.
+// Accounts that already accrued for the date are skipped, so the run can be repeated safely.
+func (s *Service) AccrueDaily(ctx context.Context, businessDate time.Time) error {
+	date := businessDate.Format(config.BusinessDateLayout)
+	s.log.Info("---AccrueDaily--->", logger.String("business_date", date))
+
+	candidates, err := s.strg.Interest().GetAccrualCandidates(ctx, &models.GetAccrualCandidatesRequest{
+		BusinessDate: date,
+		EndOfDay:     businessDate.AddDate(0, 0, 1),
+	})
+	if err != nil {
+		s.log.Error("---AccrueDaily->GetAccrualCandidates--->", logger.Error(err))
+		return err
+	}
+
+	// one broken account must not hold back the others, the failed run is retried on the next tick
+	var accrued, failed int
+	for _, c := range candidates {
+		balance, err := interest.ParseDecimal(c.Balance)
+		if err != nil {
+			s.log.Error("---AccrueDaily->ParseDecimal--->", logger.String("account_id", c.AccountID), logger.Error(err))
+			failed++
+			continue
+		}
+		rate, err := interest.ParseDecimal(c.AnnualRate)
+		if err != nil {
+			s.log.Error("---AccrueDaily->ParseDecimal--->", logger.String("account_id", c.AccountID), logger.Error(err))
+			failed++
+			continue
+		}
+
+		// Overdrawn balances earn nothing here, debit interest is charged separately
+		if balance.Sign() <= 0 {
+			continue
+		}
+
+		amount := interest.DailyInterest(balance, rate, interest.DayCount(c.DayCount), businessDate)
+		if amount.Sign() == 0 {
+			continue
+		}
+
+		created, err := s.strg.Interest().CreateAccrual(ctx, &models.InterestAccrual{
+			AccountID:    c.AccountID,
+			BusinessDate: date,
+			Balance:      c.Balance,
+			AnnualRate:   c.AnnualRate,
+			DayCount:     c.DayCount,
+			Amount:       interest.FormatDecimal(amount, interest.AccrualScale),
+		})
+		if err != nil {
+			s.log.Error("---AccrueDaily->CreateAccrual--->", logger.String("account_id", c.AccountID), logger.Error(err))
+			failed++
+			continue
+		}
+		if created {
+			accrued++
+		}
+	}
+
+	s.log.Info("---AccrueDaily->Done--->", logger.String("business_date", date), logger.Int("accrued", accrued), logger.Int("failed", failed))
+	if failed > 0 {
+		return fmt.Errorf("interest accrual failed for %d accounts", failed)
+	}
+	return nil
+}
+
+// Capitalize posts the interest accrued during the month from the bank's interest expense account.
+// It only acts on the last day of a month. Fractions of a cent stay accrued and carry into the next month.
+func (s *Service) Capitalize(ctx context.Context, businessDate time.Time) error {
+	if !interest.IsLastDayOfMonth(businessDate) {
+		return nil
+	}
+
+	period := time.Date(businessDate.Year(), businessDate.Month(), 1, 0, 0, 0, 0, businessDate.Location())
+	s.log.Info("---Capitalize--->", logger.String("period", period.Format(config.BusinessDateLayout)))
+
+	candidates, err := s.strg.Interest().GetCapitalizationCandidates(ctx, &models.GetCapitalizationCandidatesRequest{
+		Period: period.Format(config.BusinessDateLayout),
+	})
+	if err != nil {
+		s.log.Error("---Capitalize->GetCapitalizationCandidates--->", logger.Error(err))
+		return err
+	}
+
+	var failed int
+	for _, c := range candidates {
+		accrued, err := interest.ParseDecimal(c.AccruedInterest)
+		if err != nil {
+			s.log.Error("---Capitalize->ParseDecimal--->", logger.String("account_id", c.AccountID), logger.Error(err))
+			failed++
+			continue
+		}
+
+		amount := interest.RoundDown(accrued, interest.PostingScale)
+		if amount.Sign() <= 0 {
+			continue
+		}
+
+		if err := s.capitalizeAccount(ctx, c.AccountID, period, interest.FormatDecimal(amount, interest.PostingScale)); err != nil {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("interest capitalization failed for %d accounts", failed)
+	}
+	return nil
+}
+
+func (s *Service) capitalizeAccount(ctx context.Context, accountID string, period time.Time, amount string) error {
+	tx, err := s.strg.TxRepo().BeginTx(ctx)
+	if err != nil {
+		s.log.Error("---Capitalize->BeginTx--->", logger.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	posted, err := s.payment.PostTransfer(ctx, tx, &models.PostTransferRequest{
+		FromAccountID: config.InterestExpenseAccountID,
+		ToAccountID:   accountID,
+		Amount:        amount,
+		Description:   "Interest for " + period.Format("2006-01"),
+		Reference:     "INT-" + period.Format("2006-01"),
+	})
+	if err != nil {
+		s.log.Error("---Capitalize->PostTransfer--->", logger.Error(err))
+		return err
+	}
+
+	err = s.strg.Interest().CreateCapitalization(ctx, tx, &models.InterestCapitalization{
+		AccountID:     accountID,
+		Period:        period.Format(config.BusinessDateLayout),
+		Amount:        amount,
+		TransactionID: posted.Transactions[1].ID,
+	})
+	if err != nil {
+		s.log.Error("---Capitalize->CreateCapitalization--->", logger.Error(err))
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.log.Error("---Capitalize->Commit--->", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
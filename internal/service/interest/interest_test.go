@@ -0,0 +1,103 @@
+package interest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/internal/service/payment"
+	"github.com/dilmurodov/online_banking/internal/service/servicetest"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestService(t *testing.T) (*Service, sqlmock.Sqlmock) {
+	strg, mock := servicetest.NewStore(t)
+
+	return NewService(
+		config.Config{},
+		zap.NewNop(),
+		strg,
+		payment.NewService(config.Config{}, zap.NewNop(), strg),
+	), mock
+}
+
+func TestInterest_AccrueDaily(t *testing.T) {
+	r := require.New(t)
+
+	s, mock := newTestService(t)
+
+	// 28 February 2023 accrues three days under 30/360
+	businessDate := time.Date(2023, time.February, 28, 0, 0, 0, 0, time.UTC)
+
+	candidates := sqlmock.NewRows([]string{"guid", "balance", "annual_rate", "day_count"}).
+		AddRow("TestAccountID1", "36500", "0.04", "ACT/365").
+		AddRow("TestAccountID2", "36000", "0.04", "30/360").
+		AddRow("TestAccountID3", "-10", "0.04", "ACT/365")
+	mock.ExpectQuery(`^SELECT (.+?) FROM accounts a JOIN products p`).
+		WithArgs("2023-02-28", businessDate.AddDate(0, 0, 1)).
+		WillReturnRows(candidates)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^INSERT INTO interest_accruals`).
+		WithArgs("TestAccountID1", "2023-02-28", "36500", "0.04", "ACT/365", "4.0000000000").
+		WillReturnRows(sqlmock.NewRows([]string{"guid"}).AddRow("TestAccrualID1"))
+	mock.ExpectExec(`^UPDATE accounts SET accrued_interest`).WithArgs("4.0000000000", "TestAccountID1").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^INSERT INTO interest_accruals`).
+		WithArgs("TestAccountID2", "2023-02-28", "36000", "0.04", "30/360", "12.0000000000").
+		WillReturnRows(sqlmock.NewRows([]string{"guid"}))
+	mock.ExpectRollback()
+
+	t.Run("SUCCESS", func(t *testing.T) {
+		err := s.AccrueDaily(context.Background(), businessDate)
+		r.NoError(err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+}
+
+func TestInterest_Capitalize(t *testing.T) {
+	r := require.New(t)
+
+	s, mock := newTestService(t)
+
+	t.Run("NOT_MONTH_END", func(t *testing.T) {
+		err := s.Capitalize(context.Background(), time.Date(2023, time.February, 27, 0, 0, 0, 0, time.UTC))
+		r.NoError(err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	candidates := sqlmock.NewRows([]string{"guid", "accrued_interest"}).AddRow("TestAccountID", "16.0049999999")
+	mock.ExpectQuery(`^SELECT (.+?) FROM accounts a`).WithArgs("2023-02-01").WillReturnRows(candidates)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT status FROM accounts`).WithArgs(config.InterestExpenseAccountID).WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("active"))
+	mock.ExpectPrepare("INSERT INTO transactions").ExpectQuery().
+		WithArgs(config.InterestExpenseAccountID, 16.0, "TestAccountID", "debit", "Interest for 2023-02", "INT-2023-02").
+		WillReturnRows(sqlmock.NewRows([]string{"guid", "transaction_amount", "transaction_type", "recipient_id", "description", "reference", "created_at"}).
+			AddRow("TestDebitID", 16.0, "debit", "TestAccountID", "Interest for 2023-02", "INT-2023-02", "2023-03-01"))
+	mock.ExpectExec(`^UPDATE transactions SET`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`^UPDATE accounts SET balance = balance`).WithArgs("-16.00", config.InterestExpenseAccountID).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`^SELECT status FROM accounts`).WithArgs("TestAccountID").WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("active"))
+	mock.ExpectPrepare("INSERT INTO transactions").ExpectQuery().
+		WithArgs("TestAccountID", 16.0, config.InterestExpenseAccountID, "credit", "Interest for 2023-02", "INT-2023-02").
+		WillReturnRows(sqlmock.NewRows([]string{"guid", "transaction_amount", "transaction_type", "recipient_id", "description", "reference", "created_at"}).
+			AddRow("TestCreditID", 16.0, "credit", config.InterestExpenseAccountID, "Interest for 2023-02", "INT-2023-02", "2023-03-01"))
+	mock.ExpectExec(`^UPDATE transactions SET`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`^UPDATE accounts SET balance = balance`).WithArgs("16.00", "TestAccountID").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`^INSERT INTO interest_capitalizations`).
+		WithArgs("TestAccountID", "2023-02-01", "16.00", "TestCreditID").
+		WillReturnRows(sqlmock.NewRows([]string{"guid"}).AddRow("TestCapitalizationID"))
+	mock.ExpectExec(`^UPDATE accounts SET accrued_interest = accrued_interest -`).WithArgs("16.00", "TestAccountID").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	t.Run("MONTH_END", func(t *testing.T) {
+		err := s.Capitalize(context.Background(), time.Date(2023, time.February, 28, 0, 0, 0, 0, time.UTC))
+		r.NoError(err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+}
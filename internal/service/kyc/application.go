@@ -0,0 +1,228 @@
+package kyc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/kyc"
+	"github.com/dilmurodov/online_banking/pkg/logger"
+	"github.com/dilmurodov/online_banking/pkg/models"
+)
+
+const commentMaxLength = 255
+
+// GetStatus returns the tier of the user with its caps, the latest application and the documents
+// uploaded since
+func (s *Service) GetStatus(ctx context.Context, userID string) (*models.KYCStatus, error) {
+	tier, err := s.strg.KYC().GetKYCTier(ctx, userID)
+	if err != nil {
+		s.log.Error("---GetKYCStatus->GetKYCTier--->", logger.Error(err))
+		return nil, err
+	}
+
+	application, err := s.strg.KYC().GetLatestKYCApplication(ctx, userID)
+	if err != nil {
+		s.log.Error("---GetKYCStatus->GetLatestKYCApplication--->", logger.Error(err))
+		return nil, err
+	}
+
+	documents, err := s.strg.KYC().GetUnsubmittedKYCDocuments(ctx, userID)
+	if err != nil {
+		s.log.Error("---GetKYCStatus->GetUnsubmittedKYCDocuments--->", logger.Error(err))
+		return nil, err
+	}
+
+	return &models.KYCStatus{
+		Tier:        tier,
+		Limits:      kyc.Limits(s.cfg, tier),
+		Application: application,
+		Documents:   documents,
+	}, nil
+}
+
+// SubmitApplication asks for the user to be moved to a higher tier on the documents uploaded since the
+// last application. They have to include what the tier needs, see kyc.MissingDocuments.
+func (s *Service) SubmitApplication(ctx context.Context, req *models.SubmitKYCApplicationRequest) (*models.KYCApplication, error) {
+	s.log.Info("---SubmitKYCApplication--->", logger.Any("req", req))
+
+	if req.Tier != models.KYCTierBasic && req.Tier != models.KYCTierFull {
+		return nil, fmt.Errorf("tier must be basic or full")
+	}
+
+	current, err := s.strg.KYC().GetKYCTier(ctx, req.UserID)
+	if err != nil {
+		s.log.Error("---SubmitKYCApplication->GetKYCTier--->", logger.Error(err))
+		return nil, err
+	}
+	if !kyc.Above(req.Tier, current) {
+		return nil, fmt.Errorf("the user is already at the %s tier", current)
+	}
+
+	tx, err := s.strg.TxRepo().BeginTx(ctx)
+	if err != nil {
+		s.log.Error("---SubmitKYCApplication->BeginTx--->", logger.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	application, err := s.strg.KYC().CreateKYCApplication(ctx, tx, &models.KYCApplication{
+		UserID: req.UserID,
+		Tier:   req.Tier,
+	})
+	if err != nil {
+		s.log.Error("---SubmitKYCApplication->CreateKYCApplication--->", logger.Error(err))
+		return nil, err
+	}
+
+	documents, err := s.strg.KYC().AttachKYCDocuments(ctx, tx, req.UserID, application.ID)
+	if err != nil {
+		s.log.Error("---SubmitKYCApplication->AttachKYCDocuments--->", logger.Error(err))
+		return nil, err
+	}
+
+	types := make([]string, 0, len(documents))
+	for _, d := range documents {
+		types = append(types, d.Type)
+	}
+	if missing := kyc.MissingDocuments(current, req.Tier, types); len(missing) > 0 {
+		return nil, &customerrors.KYCDocumentsMissingError{Missing: missing}
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.log.Error("---SubmitKYCApplication->Commit--->", logger.Error(err))
+		return nil, err
+	}
+
+	application.Documents = documents
+	return application, nil
+}
+
+func (s *Service) GetApplications(ctx context.Context, req *models.GetKYCApplicationsRequest) (*models.GetKYCApplicationsResponse, error) {
+	switch req.Status {
+	case "", models.KYCApplicationPending, models.KYCApplicationApproved, models.KYCApplicationRejected:
+	default:
+		return nil, fmt.Errorf("invalid status")
+	}
+	switch req.Tier {
+	case "", models.KYCTierBasic, models.KYCTierFull:
+	default:
+		return nil, fmt.Errorf("invalid tier")
+	}
+	if req.Limit <= 0 || req.Limit > config.SearchMaxLimit {
+		req.Limit = config.SearchMaxLimit
+	}
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+
+	resp, err := s.strg.KYC().GetKYCApplications(ctx, req)
+	if err != nil {
+		s.log.Error("---GetKYCApplications--->", logger.Error(err))
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetApplication returns the application with the metadata of the documents submitted with it
+func (s *Service) GetApplication(ctx context.Context, id string) (*models.KYCApplication, error) {
+	application, err := s.strg.KYC().GetKYCApplication(ctx, id)
+	if err != nil {
+		s.log.Error("---GetKYCApplication--->", logger.Error(err))
+		return nil, err
+	}
+
+	application.Documents, err = s.strg.KYC().GetKYCApplicationDocuments(ctx, id)
+	if err != nil {
+		s.log.Error("---GetKYCApplication->GetKYCApplicationDocuments--->", logger.Error(err))
+		return nil, err
+	}
+
+	return application, nil
+}
+
+// ReviewApplication approves a pending application, which moves the user to its tier, or rejects it
+// with the reason in the comment. Staff can't review their own application.
+func (s *Service) ReviewApplication(ctx context.Context, req *models.ReviewKYCApplicationRequest) (*models.KYCApplication, error) {
+	s.log.Info("---ReviewKYCApplication--->", logger.Any("req", req))
+
+	if req.Status != models.KYCApplicationApproved && req.Status != models.KYCApplicationRejected {
+		return nil, fmt.Errorf("invalid status")
+	}
+	req.Comment = strings.TrimSpace(req.Comment)
+	if utf8.RuneCountInString(req.Comment) > commentMaxLength {
+		return nil, fmt.Errorf("comment must not be longer than %d characters", commentMaxLength)
+	}
+	if req.Status == models.KYCApplicationRejected && req.Comment == "" {
+		return nil, fmt.Errorf("comment is required to reject an application")
+	}
+
+	tx, err := s.strg.Audit().BeginTx(ctx)
+	if err != nil {
+		s.log.Error("---ReviewKYCApplication->BeginTx--->", logger.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	application, err := s.strg.KYC().GetKYCApplicationForUpdate(ctx, tx, req.ID)
+	if err != nil {
+		s.log.Error("---ReviewKYCApplication->GetKYCApplicationForUpdate--->", logger.Error(err))
+		return nil, err
+	}
+	if application.Status != models.KYCApplicationPending {
+		return nil, &customerrors.KYCApplicationStateError{Status: application.Status}
+	}
+	if application.UserID == req.ActorID {
+		return nil, fmt.Errorf("an application must be reviewed by someone other than the applicant")
+	}
+
+	before, err := s.strg.KYC().GetKYCTier(ctx, application.UserID)
+	if err != nil {
+		s.log.Error("---ReviewKYCApplication->GetKYCTier--->", logger.Error(err))
+		return nil, err
+	}
+
+	if err = s.strg.KYC().ReviewKYCApplication(ctx, tx, req); err != nil {
+		s.log.Error("---ReviewKYCApplication->ReviewKYCApplication--->", logger.Error(err))
+		return nil, err
+	}
+
+	// the tier only ever goes up, a user may have been moved past the application meanwhile
+	after := before
+	if req.Status == models.KYCApplicationApproved && kyc.Above(application.Tier, before) {
+		after = application.Tier
+		if err = s.strg.KYC().SetKYCTier(ctx, tx, application.UserID, after); err != nil {
+			s.log.Error("---ReviewKYCApplication->SetKYCTier--->", logger.Error(err))
+			return nil, err
+		}
+	}
+
+	err = s.strg.Audit().CreateAuditEvent(ctx, tx, &models.AuditEvent{
+		EventType:    models.AuditEventKYCApplicationReviewed,
+		ActorID:      req.ActorID,
+		UserID:       application.UserID,
+		ResourceType: models.AuditResourceKYCApplication,
+		ResourceID:   application.ID,
+		IP:           req.IP,
+		Details:      map[string]interface{}{"comment": req.Comment, "tier": application.Tier},
+		Before:       map[string]interface{}{"status": application.Status, "kyc_tier": before},
+		After:        map[string]interface{}{"status": req.Status, "kyc_tier": after},
+	})
+	if err != nil {
+		s.log.Error("---ReviewKYCApplication->CreateAuditEvent--->", logger.Error(err))
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.log.Error("---ReviewKYCApplication->Commit--->", logger.Error(err))
+		return nil, err
+	}
+
+	application.Status = req.Status
+	application.ReviewedBy = req.ActorID
+	application.ReviewComment = req.Comment
+	return application, nil
+}
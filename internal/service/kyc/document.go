@@ -0,0 +1,134 @@
+package kyc
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dilmurodov/online_banking/pkg/blob"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/kyc"
+	"github.com/dilmurodov/online_banking/pkg/logger"
+	"github.com/dilmurodov/online_banking/pkg/models"
+)
+
+const (
+	fileNameMaxLength = 255
+	// documentsMaxUnsubmitted is how many uploaded documents can wait to be submitted with an application
+	documentsMaxUnsubmitted = 10
+)
+
+// documentContentTypes are the files taken as documents, told by their content and not by the name
+var documentContentTypes = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"application/pdf": true,
+}
+
+// documentKey is where the file of the document is kept in the blob store
+func documentKey(doc *models.KYCDocument) string {
+	return path.Join("kyc", doc.UserID, doc.ID)
+}
+
+// UploadDocument stores the file in the blob store and its metadata with the user's documents,
+// it waits there until the user submits an application
+func (s *Service) UploadDocument(ctx context.Context, req *models.UploadKYCDocumentRequest) (*models.KYCDocument, error) {
+	if !kyc.IsValidDocumentType(req.Type) {
+		return nil, fmt.Errorf("invalid document type")
+	}
+	if len(req.Content) == 0 {
+		return nil, fmt.Errorf("file is empty")
+	}
+	if int64(len(req.Content)) > s.cfg.KYCDocumentMaxSize {
+		return nil, fmt.Errorf("file must not be larger than %d bytes", s.cfg.KYCDocumentMaxSize)
+	}
+	contentType := http.DetectContentType(req.Content)
+	if !documentContentTypes[contentType] {
+		return nil, fmt.Errorf("file must be a JPEG, PNG or PDF")
+	}
+
+	// only the base name is kept, the client's directories mean nothing here
+	fileName := strings.TrimSpace(req.FileName)
+	fileName = fileName[strings.LastIndexAny(fileName, `/\`)+1:]
+	if fileName == "" {
+		fileName = req.Type
+	}
+	if utf8.RuneCountInString(fileName) > fileNameMaxLength {
+		return nil, fmt.Errorf("file name must not be longer than %d characters", fileNameMaxLength)
+	}
+
+	unsubmitted, err := s.strg.KYC().GetUnsubmittedKYCDocuments(ctx, req.UserID)
+	if err != nil {
+		s.log.Error("---UploadKYCDocument->GetUnsubmittedKYCDocuments--->", logger.Error(err))
+		return nil, err
+	}
+	if len(unsubmitted) >= documentsMaxUnsubmitted {
+		return nil, fmt.Errorf("at most %d documents can wait to be submitted", documentsMaxUnsubmitted)
+	}
+
+	sum := sha256.Sum256(req.Content)
+
+	tx, err := s.strg.TxRepo().BeginTx(ctx)
+	if err != nil {
+		s.log.Error("---UploadKYCDocument->BeginTx--->", logger.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	doc, err := s.strg.KYC().CreateKYCDocument(ctx, tx, &models.KYCDocument{
+		UserID:      req.UserID,
+		Type:        req.Type,
+		FileName:    fileName,
+		ContentType: contentType,
+		Size:        int64(len(req.Content)),
+		SHA256:      hex.EncodeToString(sum[:]),
+	})
+	if err != nil {
+		s.log.Error("---UploadKYCDocument->CreateKYCDocument--->", logger.Error(err))
+		return nil, err
+	}
+
+	// the row is only committed once the file is stored, a failed upload leaves nothing behind
+	if err = s.blobs.Put(ctx, documentKey(doc), bytes.NewReader(req.Content)); err != nil {
+		s.log.Error("---UploadKYCDocument->Put--->", logger.Error(err))
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.log.Error("---UploadKYCDocument->Commit--->", logger.Error(err))
+		if err := s.blobs.Delete(ctx, documentKey(doc)); err != nil {
+			s.log.Error("---UploadKYCDocument->Delete--->", logger.Error(err))
+		}
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// GetDocumentFile opens the file of the document for a reviewer, the caller closes it
+func (s *Service) GetDocumentFile(ctx context.Context, id string) (*models.KYCDocument, io.ReadCloser, error) {
+	doc, err := s.strg.KYC().GetKYCDocument(ctx, id)
+	if err != nil {
+		s.log.Error("---GetKYCDocumentFile->GetKYCDocument--->", logger.Error(err))
+		return nil, nil, err
+	}
+
+	file, err := s.blobs.Get(ctx, documentKey(doc))
+	if errors.Is(err, blob.ErrNotFound) {
+		s.log.Error("---GetKYCDocumentFile->Get--->", logger.String("document_id", doc.ID), logger.Error(err))
+		return nil, nil, &customerrors.KYCDocumentNotFoundError{Guid: id}
+	} else if err != nil {
+		s.log.Error("---GetKYCDocumentFile->Get--->", logger.Error(err))
+		return nil, nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return doc, file, nil
+}
@@ -0,0 +1,38 @@
+package kyc
+
+import (
+	"context"
+	"io"
+
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/pkg/blob"
+	"github.com/dilmurodov/online_banking/pkg/logger"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/dilmurodov/online_banking/storage"
+)
+
+type ServiceI interface {
+	GetStatus(ctx context.Context, userID string) (*models.KYCStatus, error)
+	UploadDocument(ctx context.Context, req *models.UploadKYCDocumentRequest) (*models.KYCDocument, error)
+	SubmitApplication(ctx context.Context, req *models.SubmitKYCApplicationRequest) (*models.KYCApplication, error)
+	GetApplications(ctx context.Context, req *models.GetKYCApplicationsRequest) (*models.GetKYCApplicationsResponse, error)
+	GetApplication(ctx context.Context, id string) (*models.KYCApplication, error)
+	ReviewApplication(ctx context.Context, req *models.ReviewKYCApplicationRequest) (*models.KYCApplication, error)
+	GetDocumentFile(ctx context.Context, id string) (*models.KYCDocument, io.ReadCloser, error)
+}
+
+type Service struct {
+	cfg   config.Config
+	log   logger.LoggerI
+	strg  storage.StorageI
+	blobs blob.StoreI
+}
+
+func NewService(cfg config.Config, log logger.LoggerI, strg storage.StorageI, blobs blob.StoreI) *Service {
+	return &Service{
+		cfg:   cfg,
+		log:   log,
+		strg:  strg,
+		blobs: blobs,
+	}
+}
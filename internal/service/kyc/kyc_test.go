@@ -0,0 +1,292 @@
+package kyc
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/internal/service/servicetest"
+	"github.com/dilmurodov/online_banking/pkg/blob"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// testPNG is a 1x1 PNG header, enough for http.DetectContentType
+var testPNG = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01\x08\x06\x00\x00\x00\x1f\x15\xc4\x89")
+
+var (
+	kycDocumentColumns    = []string{"guid", "user_id", "application_id", "type", "file_name", "content_type", "size", "sha256", "created_at"}
+	kycApplicationColumns = []string{"guid", "user_id", "tier", "status", "reviewed_by", "review_comment", "reviewed_at", "created_at"}
+)
+
+func newTestService(t *testing.T) (*Service, sqlmock.Sqlmock, string) {
+	strg, mock := servicetest.NewStore(t)
+
+	dir := t.TempDir()
+	return NewService(config.Config{
+		KYCDocumentMaxSize:        1024,
+		KYCUnverifiedBalanceLimit: 1000,
+		KYCUnverifiedDepositLimit: 500,
+	}, zap.NewNop(), strg, blob.NewLocalStore(dir)), mock, dir
+}
+
+func documentRows(docs ...[]driver.Value) *sqlmock.Rows {
+	rows := sqlmock.NewRows(kycDocumentColumns)
+	for _, d := range docs {
+		rows.AddRow(d...)
+	}
+	return rows
+}
+
+func TestKYC_UploadDocument(t *testing.T) {
+	t.Run("SUCCESS", func(t *testing.T) {
+		r := require.New(t)
+		s, mock, dir := newTestService(t)
+
+		mock.ExpectQuery(`^SELECT (.+?) FROM kyc_documents`).WithArgs("TestUserID").WillReturnRows(documentRows())
+		mock.ExpectBegin()
+		// the directories of the client's file name are dropped and the type is told from the content
+		mock.ExpectQuery(`^INSERT INTO kyc_documents`).
+			WithArgs("TestUserID", models.KYCDocumentPassport, "passport.png", "image/png", int64(len(testPNG)), sqlmock.AnyArg()).
+			WillReturnRows(documentRows([]driver.Value{"TestDocumentID", "TestUserID", nil, models.KYCDocumentPassport, "passport.png", "image/png",
+				int64(len(testPNG)), "TestSHA256", "2023-05-15"}))
+		mock.ExpectCommit()
+
+		doc, err := s.UploadDocument(context.Background(), &models.UploadKYCDocumentRequest{
+			UserID:   "TestUserID",
+			Type:     models.KYCDocumentPassport,
+			FileName: `C:\Users\ivan\passport.png`,
+			Content:  testPNG,
+		})
+		r.NoError(err)
+		r.Equal("TestDocumentID", doc.ID)
+		r.NoError(mock.ExpectationsWereMet())
+
+		stored, err := os.ReadFile(filepath.Join(dir, "kyc", "TestUserID", "TestDocumentID"))
+		r.NoError(err)
+		r.Equal(testPNG, stored)
+
+		// a reviewer gets the same file back
+		mock.ExpectQuery(`^SELECT (.+?) FROM kyc_documents`).WithArgs("TestDocumentID").
+			WillReturnRows(documentRows([]driver.Value{"TestDocumentID", "TestUserID", "TestApplicationID", models.KYCDocumentPassport, "passport.png", "image/png",
+				int64(len(testPNG)), "TestSHA256", "2023-05-15"}))
+
+		_, file, err := s.GetDocumentFile(context.Background(), "TestDocumentID")
+		r.NoError(err)
+		defer file.Close()
+		content, err := io.ReadAll(file)
+		r.NoError(err)
+		r.Equal(testPNG, content)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("NOT_A_DOCUMENT", func(t *testing.T) {
+		r := require.New(t)
+		s, mock, _ := newTestService(t)
+
+		_, err := s.UploadDocument(context.Background(), &models.UploadKYCDocumentRequest{
+			UserID:   "TestUserID",
+			Type:     models.KYCDocumentPassport,
+			FileName: "passport.png",
+			Content:  []byte("<html><script>alert(1)</script></html>"),
+		})
+		r.EqualError(err, "file must be a JPEG, PNG or PDF")
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("TOO_LARGE", func(t *testing.T) {
+		r := require.New(t)
+		s, mock, _ := newTestService(t)
+
+		_, err := s.UploadDocument(context.Background(), &models.UploadKYCDocumentRequest{
+			UserID:   "TestUserID",
+			Type:     models.KYCDocumentPassport,
+			FileName: "passport.png",
+			Content:  append(testPNG, make([]byte, 1024)...),
+		})
+		r.EqualError(err, "file must not be larger than 1024 bytes")
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("FILE_NOT_STORED", func(t *testing.T) {
+		r := require.New(t)
+		s, mock, dir := newTestService(t)
+
+		// a file where the user's directory should be makes the blob store fail, the row is rolled back
+		r.NoError(os.MkdirAll(filepath.Join(dir, "kyc"), 0700))
+		r.NoError(os.WriteFile(filepath.Join(dir, "kyc", "TestUserID"), nil, 0600))
+
+		mock.ExpectQuery(`^SELECT (.+?) FROM kyc_documents`).WithArgs("TestUserID").WillReturnRows(documentRows())
+		mock.ExpectBegin()
+		mock.ExpectQuery(`^INSERT INTO kyc_documents`).
+			WillReturnRows(documentRows([]driver.Value{"TestDocumentID", "TestUserID", nil, models.KYCDocumentPassport, "passport.png", "image/png",
+				int64(len(testPNG)), "TestSHA256", "2023-05-15"}))
+		mock.ExpectRollback()
+
+		_, err := s.UploadDocument(context.Background(), &models.UploadKYCDocumentRequest{
+			UserID:   "TestUserID",
+			Type:     models.KYCDocumentPassport,
+			FileName: "passport.png",
+			Content:  testPNG,
+		})
+		r.IsType(&customerrors.InternalServerError{}, err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+}
+
+func TestKYC_SubmitApplication(t *testing.T) {
+	t.Run("BASIC", func(t *testing.T) {
+		r := require.New(t)
+		s, mock, _ := newTestService(t)
+
+		mock.ExpectQuery(`^SELECT kyc_tier FROM users`).WithArgs("TestUserID").
+			WillReturnRows(sqlmock.NewRows([]string{"kyc_tier"}).AddRow(models.KYCTierUnverified))
+		mock.ExpectBegin()
+		mock.ExpectQuery(`^INSERT INTO kyc_applications`).WithArgs("TestUserID", models.KYCTierBasic).
+			WillReturnRows(sqlmock.NewRows(kycApplicationColumns).AddRow("TestApplicationID", "TestUserID", models.KYCTierBasic, models.KYCApplicationPending, nil, "", nil, "2023-05-15"))
+		mock.ExpectQuery(`^UPDATE kyc_documents SET`).WithArgs("TestUserID", "TestApplicationID").
+			WillReturnRows(documentRows([]driver.Value{"TestDocumentID", "TestUserID", "TestApplicationID", models.KYCDocumentIDCard, "id.pdf", "application/pdf",
+				int64(2048), "TestSHA256", "2023-05-15"}))
+		mock.ExpectCommit()
+
+		application, err := s.SubmitApplication(context.Background(), &models.SubmitKYCApplicationRequest{
+			UserID: "TestUserID",
+			Tier:   models.KYCTierBasic,
+		})
+		r.NoError(err)
+		r.Equal(models.KYCApplicationPending, application.Status)
+		r.Len(application.Documents, 1)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("MISSING_DOCUMENTS", func(t *testing.T) {
+		r := require.New(t)
+		s, mock, _ := newTestService(t)
+
+		// an unverified user going straight to full needs a proof of address besides the passport
+		mock.ExpectQuery(`^SELECT kyc_tier FROM users`).WithArgs("TestUserID").
+			WillReturnRows(sqlmock.NewRows([]string{"kyc_tier"}).AddRow(models.KYCTierUnverified))
+		mock.ExpectBegin()
+		mock.ExpectQuery(`^INSERT INTO kyc_applications`).WithArgs("TestUserID", models.KYCTierFull).
+			WillReturnRows(sqlmock.NewRows(kycApplicationColumns).AddRow("TestApplicationID", "TestUserID", models.KYCTierFull, models.KYCApplicationPending, nil, "", nil, "2023-05-15"))
+		mock.ExpectQuery(`^UPDATE kyc_documents SET`).WithArgs("TestUserID", "TestApplicationID").
+			WillReturnRows(documentRows([]driver.Value{"TestDocumentID", "TestUserID", "TestApplicationID", models.KYCDocumentPassport, "passport.png", "image/png",
+				int64(2048), "TestSHA256", "2023-05-15"}))
+		mock.ExpectRollback()
+
+		_, err := s.SubmitApplication(context.Background(), &models.SubmitKYCApplicationRequest{
+			UserID: "TestUserID",
+			Tier:   models.KYCTierFull,
+		})
+		r.Equal(&customerrors.KYCDocumentsMissingError{Missing: []string{models.KYCDocumentProofOfAddress}}, err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("ALREADY_AT_TIER", func(t *testing.T) {
+		r := require.New(t)
+		s, mock, _ := newTestService(t)
+
+		mock.ExpectQuery(`^SELECT kyc_tier FROM users`).WithArgs("TestUserID").
+			WillReturnRows(sqlmock.NewRows([]string{"kyc_tier"}).AddRow(models.KYCTierFull))
+
+		_, err := s.SubmitApplication(context.Background(), &models.SubmitKYCApplicationRequest{
+			UserID: "TestUserID",
+			Tier:   models.KYCTierBasic,
+		})
+		r.EqualError(err, "the user is already at the full tier")
+		r.NoError(mock.ExpectationsWereMet())
+	})
+}
+
+func TestKYC_ReviewApplication(t *testing.T) {
+	applicationRow := func(status string) *sqlmock.Rows {
+		return sqlmock.NewRows(kycApplicationColumns).AddRow("TestApplicationID", "TestUserID", models.KYCTierBasic, status, nil, "", nil, "2023-05-15")
+	}
+
+	t.Run("APPROVED", func(t *testing.T) {
+		r := require.New(t)
+		s, mock, _ := newTestService(t)
+
+		servicetest.ExpectAuditBegin(mock)
+		mock.ExpectQuery(`^SELECT (.+?) FROM kyc_applications (.+) FOR UPDATE`).WithArgs("TestApplicationID").
+			WillReturnRows(applicationRow(models.KYCApplicationPending))
+		mock.ExpectQuery(`^SELECT kyc_tier FROM users`).WithArgs("TestUserID").
+			WillReturnRows(sqlmock.NewRows([]string{"kyc_tier"}).AddRow(models.KYCTierUnverified))
+		mock.ExpectExec(`^UPDATE kyc_applications SET`).
+			WithArgs("TestApplicationID", models.KYCApplicationApproved, "TestOfficerID", "").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`^UPDATE users SET kyc_tier`).WithArgs("TestUserID", models.KYCTierBasic).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		servicetest.ExpectAuditEvent(mock, models.AuditEventKYCApplicationReviewed, "TestUserID", "127.0.0.1",
+			[]byte(`{"comment":"","tier":"basic"}`), "TestOfficerID", models.AuditResourceKYCApplication, "TestApplicationID", "",
+			`{"kyc_tier":"unverified","status":"pending"}`, `{"kyc_tier":"basic","status":"approved"}`, "", sqlmock.AnyArg(), sqlmock.AnyArg())
+		mock.ExpectCommit()
+
+		application, err := s.ReviewApplication(context.Background(), &models.ReviewKYCApplicationRequest{
+			ID:      "TestApplicationID",
+			ActorID: "TestOfficerID",
+			IP:      "127.0.0.1",
+			Status:  models.KYCApplicationApproved,
+		})
+		r.NoError(err)
+		r.Equal(models.KYCApplicationApproved, application.Status)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("OWN_APPLICATION", func(t *testing.T) {
+		r := require.New(t)
+		s, mock, _ := newTestService(t)
+
+		servicetest.ExpectAuditBegin(mock)
+		mock.ExpectQuery(`^SELECT (.+?) FROM kyc_applications (.+) FOR UPDATE`).WithArgs("TestApplicationID").
+			WillReturnRows(applicationRow(models.KYCApplicationPending))
+		mock.ExpectRollback()
+
+		_, err := s.ReviewApplication(context.Background(), &models.ReviewKYCApplicationRequest{
+			ID:      "TestApplicationID",
+			ActorID: "TestUserID",
+			Status:  models.KYCApplicationApproved,
+		})
+		r.EqualError(err, "an application must be reviewed by someone other than the applicant")
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("ALREADY_REVIEWED", func(t *testing.T) {
+		r := require.New(t)
+		s, mock, _ := newTestService(t)
+
+		servicetest.ExpectAuditBegin(mock)
+		mock.ExpectQuery(`^SELECT (.+?) FROM kyc_applications (.+) FOR UPDATE`).WithArgs("TestApplicationID").
+			WillReturnRows(applicationRow(models.KYCApplicationRejected))
+		mock.ExpectRollback()
+
+		_, err := s.ReviewApplication(context.Background(), &models.ReviewKYCApplicationRequest{
+			ID:      "TestApplicationID",
+			ActorID: "TestOfficerID",
+			Status:  models.KYCApplicationApproved,
+		})
+		r.Equal(&customerrors.KYCApplicationStateError{Status: models.KYCApplicationRejected}, err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("REJECTED_WITHOUT_COMMENT", func(t *testing.T) {
+		r := require.New(t)
+		s, mock, _ := newTestService(t)
+
+		_, err := s.ReviewApplication(context.Background(), &models.ReviewKYCApplicationRequest{
+			ID:      "TestApplicationID",
+			ActorID: "TestOfficerID",
+			Status:  models.KYCApplicationRejected,
+			Comment: "  ",
+		})
+		r.EqualError(err, "comment is required to reject an application")
+		r.NoError(mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,38 @@
+package loan
+
+import (
+	"context"
+	"time"
+
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/internal/service/payment"
+	"github.com/dilmurodov/online_banking/pkg/logger"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/dilmurodov/online_banking/storage"
+)
+
+type ServiceI interface {
+	GetLoanProducts(ctx context.Context) (*models.GetLoanProductsResponse, error)
+	QuoteLoan(ctx context.Context, req *models.LoanQuoteRequest) (*models.LoanQuote, error)
+	ApplyLoan(ctx context.Context, req *models.ApplyLoanRequest) (*models.Loan, error)
+	GetLoans(ctx context.Context, req *models.GetLoansRequest) (*models.GetLoansResponse, error)
+	GetLoanByID(ctx context.Context, req *models.LoanByIDRequest) (*models.Loan, error)
+	EarlyRepayment(ctx context.Context, req *models.EarlyRepaymentRequest) (*models.EarlyRepaymentResponse, error)
+	CollectInstallments(ctx context.Context, businessDate time.Time) error
+}
+
+type Service struct {
+	cfg     config.Config
+	log     logger.LoggerI
+	strg    storage.StorageI
+	payment payment.ServiceI
+}
+
+func NewService(cfg config.Config, log logger.LoggerI, strg storage.StorageI, paymentService payment.ServiceI) *Service {
+	return &Service{
+		cfg:     cfg,
+		log:     log,
+		strg:    strg,
+		payment: paymentService,
+	}
+}
@@ -0,0 +1,598 @@
+package loan
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/internal/service/account"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/helper"
+	"github.com/dilmurodov/online_banking/pkg/interest"
+	"github.com/dilmurodov/online_banking/pkg/loan"
+	"github.com/dilmurodov/online_banking/pkg/logger"
+	"github.com/dilmurodov/online_banking/pkg/models"
+)
+
+func (s *Service) GetLoanProducts(ctx context.Context) (*models.GetLoanProductsResponse, error) {
+	resp, err := s.strg.Loan().GetLoanProducts(ctx)
+	if err != nil {
+		s.log.Error("---GetLoanProducts--->", logger.Error(err))
+		return nil, err
+	}
+	return resp, nil
+}
+
+// QuoteLoan returns the schedule the loan would have if it were disbursed today
+func (s *Service) QuoteLoan(ctx context.Context, req *models.LoanQuoteRequest) (*models.LoanQuote, error) {
+	s.log.Info("---QuoteLoan--->", logger.Any("req", req))
+
+	_, quote, err := s.quote(ctx, req, helper.BusinessToday(s.cfg.BusinessTimezone))
+	if err != nil {
+		return nil, err
+	}
+	return quote, nil
+}
+
+func (s *Service) quote(ctx context.Context, req *models.LoanQuoteRequest, disbursedOn time.Time) (*models.LoanProduct, *models.LoanQuote, error) {
+	if req.ScheduleType == "" {
+		req.ScheduleType = models.LoanScheduleAnnuity
+	}
+	if !loan.ScheduleType(req.ScheduleType).Valid() {
+		return nil, nil, fmt.Errorf("schedule_type must be %s or %s", models.LoanScheduleAnnuity, models.LoanScheduleDifferentiated)
+	}
+
+	amount, err := interest.ParseAmount(req.Amount)
+	if err != nil || amount.Sign() <= 0 {
+		return nil, nil, fmt.Errorf("amount must be positive with at most %d decimal places", interest.PostingScale)
+	}
+
+	product, err := s.strg.Loan().GetLoanProduct(ctx, req.Product)
+	if err != nil {
+		s.log.Error("---QuoteLoan->GetLoanProduct--->", logger.Error(err))
+		return nil, nil, err
+	}
+
+	terms, err := parseDecimals(product.AnnualRate, product.MinAmount, product.MaxAmount)
+	if err != nil {
+		return nil, nil, err
+	}
+	rate, minAmount, maxAmount := terms[0], terms[1], terms[2]
+
+	if amount.Cmp(minAmount) < 0 || amount.Cmp(maxAmount) > 0 {
+		return nil, nil, fmt.Errorf("amount must be between %s and %s", product.MinAmount, product.MaxAmount)
+	}
+	if req.TermMonths < product.MinTermMonths || req.TermMonths > product.MaxTermMonths {
+		return nil, nil, fmt.Errorf("term_months must be between %d and %d", product.MinTermMonths, product.MaxTermMonths)
+	}
+
+	schedule := loan.Build(loan.ScheduleType(req.ScheduleType), amount, rate, req.TermMonths, disbursedOn, 1)
+
+	totalInterest := new(big.Rat)
+	for _, i := range schedule {
+		totalInterest.Add(totalInterest, i.Interest)
+	}
+
+	return product, &models.LoanQuote{
+		Product:       product.Code,
+		Principal:     interest.FormatDecimal(amount, interest.PostingScale),
+		AnnualRate:    product.AnnualRate,
+		TermMonths:    req.TermMonths,
+		ScheduleType:  req.ScheduleType,
+		TotalInterest: interest.FormatDecimal(totalInterest, interest.PostingScale),
+		TotalPayment:  interest.FormatDecimal(new(big.Rat).Add(amount, totalInterest), interest.PostingScale),
+		Installments:  toInstallments("", schedule),
+	}, nil
+}
+
+// ApplyLoan grants a loan within the product's limits and disburses it into the borrower's current account
+func (s *Service) ApplyLoan(ctx context.Context, req *models.ApplyLoanRequest) (*models.Loan, error) {
+	s.log.Info("---ApplyLoan--->", logger.Any("req", req))
+
+	disbursedOn := helper.BusinessToday(s.cfg.BusinessTimezone)
+
+	product, quote, err := s.quote(ctx, &req.LoanQuoteRequest, disbursedOn)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = account.CheckPermission(ctx, s.strg, req.AccountID, req.UserID, models.AccountPermissionManage); err != nil {
+		return nil, err
+	}
+	account, err := s.strg.Account().GetAccountByID(ctx, &models.GetAccountByIDRequest{ID: req.AccountID})
+	if err != nil {
+		return nil, &customerrors.AccountNotFoundError{Guid: req.AccountID}
+	}
+	if account.Product != models.ProductCurrent {
+		return nil, fmt.Errorf("loans can only be disbursed into a current account")
+	}
+
+	tx, err := s.strg.Audit().BeginTx(ctx)
+	if err != nil {
+		s.log.Error("---ApplyLoan->BeginTx--->", logger.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	posted, err := s.payment.PostTransfer(ctx, tx, &models.PostTransferRequest{
+		FromAccountID:     config.LoanPortfolioAccountID,
+		ToAccountID:       account.ID,
+		Amount:            quote.Principal,
+		Description:       "Loan disbursement",
+		CustomerInitiated: true,
+	})
+	if err != nil {
+		s.log.Error("---ApplyLoan->PostTransfer--->", logger.Error(err))
+		return nil, err
+	}
+
+	created, err := s.strg.Loan().CreateLoan(ctx, tx, &models.Loan{
+		UserID:                    req.UserID,
+		AccountID:                 account.ID,
+		Product:                   product.Code,
+		Principal:                 quote.Principal,
+		AnnualRate:                product.AnnualRate,
+		TermMonths:                quote.TermMonths,
+		ScheduleType:              quote.ScheduleType,
+		LateFee:                   product.LateFee,
+		GraceDays:                 product.GraceDays,
+		DisbursedOn:               disbursedOn.Format(config.BusinessDateLayout),
+		DisbursementTransactionID: posted.Transactions[1].ID,
+	})
+	if err != nil {
+		s.log.Error("---ApplyLoan->CreateLoan--->", logger.Error(err))
+		return nil, err
+	}
+
+	for _, i := range quote.Installments {
+		i.LoanID = created.ID
+	}
+	if err = s.strg.Loan().CreateInstallments(ctx, tx, quote.Installments); err != nil {
+		s.log.Error("---ApplyLoan->CreateInstallments--->", logger.Error(err))
+		return nil, err
+	}
+
+	err = s.strg.Audit().CreateAuditEvent(ctx, tx, &models.AuditEvent{
+		EventType:    models.AuditEventLoanDisbursed,
+		ActorID:      req.UserID,
+		UserID:       req.UserID,
+		ResourceType: models.AuditResourceLoan,
+		ResourceID:   created.ID,
+		Details: map[string]interface{}{
+			"account_id":     account.ID,
+			"product":        product.Code,
+			"term_months":    quote.TermMonths,
+			"schedule_type":  quote.ScheduleType,
+			"transaction_id": created.DisbursementTransactionID,
+		},
+		After: auditSnapshot(created),
+	})
+	if err != nil {
+		s.log.Error("---ApplyLoan->CreateAuditEvent--->", logger.Error(err))
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.log.Error("---ApplyLoan->Commit--->", logger.Error(err))
+		return nil, err
+	}
+	created.Installments = quote.Installments
+
+	return created, nil
+}
+
+func (s *Service) GetLoans(ctx context.Context, req *models.GetLoansRequest) (*models.GetLoansResponse, error) {
+	resp, err := s.strg.Loan().GetLoans(ctx, req)
+	if err != nil {
+		s.log.Error("---GetLoans--->", logger.Error(err))
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *Service) GetLoanByID(ctx context.Context, req *models.LoanByIDRequest) (*models.Loan, error) {
+	resp, err := s.strg.Loan().GetLoanByID(ctx, req)
+	if err != nil {
+		s.log.Error("---GetLoanByID--->", logger.Error(err))
+		return nil, err
+	}
+	return resp, nil
+}
+
+// EarlyRepayment pays principal ahead of the schedule. An amount covering the outstanding principal
+// and the interest accrued since the last due date closes the loan, a smaller one rebuilds the
+// installments not yet due with either a lower payment or fewer months.
+func (s *Service) EarlyRepayment(ctx context.Context, req *models.EarlyRepaymentRequest) (*models.EarlyRepaymentResponse, error) {
+	s.log.Info("---EarlyRepayment--->", logger.Any("req", req))
+
+	if req.Mode == "" {
+		req.Mode = models.EarlyRepaymentReducePayment
+	}
+	if req.Mode != models.EarlyRepaymentReducePayment && req.Mode != models.EarlyRepaymentReduceTerm {
+		return nil, fmt.Errorf("mode must be %s or %s", models.EarlyRepaymentReducePayment, models.EarlyRepaymentReduceTerm)
+	}
+
+	amount, err := interest.ParseAmount(req.Amount)
+	if err != nil || amount.Sign() <= 0 {
+		return nil, fmt.Errorf("amount must be positive with at most %d decimal places", interest.PostingScale)
+	}
+
+	// checks that the loan belongs to the user
+	if _, err := s.strg.Loan().GetLoanByID(ctx, &models.LoanByIDRequest{ID: req.ID, UserID: req.UserID}); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.strg.Audit().BeginTx(ctx)
+	if err != nil {
+		s.log.Error("---EarlyRepayment->BeginTx--->", logger.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	l, err := s.strg.Loan().GetLoanForUpdate(ctx, tx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	// overdue installments are collected by the repayment job first
+	if l.Status != models.LoanStatusActive {
+		return nil, &customerrors.LoanStateError{Status: l.Status}
+	}
+	before := auditSnapshot(l)
+
+	values, err := parseDecimals(l.OutstandingPrincipal, l.AnnualRate)
+	if err != nil {
+		return nil, err
+	}
+	outstanding, rate := values[0], values[1]
+
+	today := helper.BusinessToday(s.cfg.BusinessTimezone)
+	lastDue, err := time.Parse(config.BusinessDateLayout, l.DisbursedOn)
+	if err != nil {
+		return nil, err
+	}
+	pending := make([]*models.LoanInstallment, 0)
+	for _, i := range l.Installments {
+		due, err := time.Parse(config.BusinessDateLayout, i.DueDate)
+		if err != nil {
+			return nil, err
+		}
+		if !due.After(today) && due.After(lastDue) {
+			lastDue = due
+		}
+		if i.Status == models.LoanInstallmentPending {
+			pending = append(pending, i)
+		}
+	}
+
+	accrued := interest.RoundDown(
+		new(big.Rat).Mul(
+			new(big.Rat).Mul(outstanding, rate),
+			interest.YearFraction(interest.ACT365, lastDue, today),
+		),
+		interest.PostingScale,
+	)
+
+	resp := &models.EarlyRepaymentResponse{
+		Interest:     interest.FormatDecimal(new(big.Rat), interest.PostingScale),
+		Transactions: make([]*models.Transaction, 0),
+	}
+	post := func(to string, amount *big.Rat, description string) error {
+		if amount.Sign() <= 0 {
+			return nil
+		}
+		posted, err := s.payment.PostTransfer(ctx, tx, &models.PostTransferRequest{
+			FromAccountID:     l.AccountID,
+			ToAccountID:       to,
+			Amount:            interest.FormatDecimal(amount, interest.PostingScale),
+			Description:       description,
+			CustomerInitiated: true,
+		})
+		if err != nil {
+			s.log.Error("---EarlyRepayment->PostTransfer--->", logger.Error(err))
+			return err
+		}
+		resp.Transactions = append(resp.Transactions, posted.Transactions...)
+		return nil
+	}
+
+	payoff := new(big.Rat).Add(outstanding, accrued)
+	switch {
+	case amount.Cmp(payoff) >= 0:
+		if err = post(config.InterestIncomeAccountID, accrued, "Loan early repayment, interest"); err != nil {
+			return nil, err
+		}
+		if err = post(config.LoanPortfolioAccountID, outstanding, "Loan early repayment"); err != nil {
+			return nil, err
+		}
+		if err = s.strg.Loan().DeletePendingInstallments(ctx, tx, l.ID); err != nil {
+			return nil, err
+		}
+		resp.Principal = interest.FormatDecimal(outstanding, interest.PostingScale)
+		resp.Interest = interest.FormatDecimal(accrued, interest.PostingScale)
+		outstanding = new(big.Rat)
+		l.Status = models.LoanStatusRepaid
+
+	case amount.Cmp(outstanding) >= 0:
+		return nil, fmt.Errorf("repaying the loan in full takes %s", interest.FormatDecimal(payoff, interest.PostingScale))
+
+	default:
+		if len(pending) == 0 {
+			return nil, &customerrors.LoanStateError{Status: l.Status}
+		}
+		if err = post(config.LoanPortfolioAccountID, amount, "Loan early repayment"); err != nil {
+			return nil, err
+		}
+		outstanding.Sub(outstanding, amount)
+
+		schedule, err := s.rebuild(l, pending, outstanding, rate, req.Mode)
+		if err != nil {
+			return nil, err
+		}
+		if err = s.strg.Loan().DeletePendingInstallments(ctx, tx, l.ID); err != nil {
+			return nil, err
+		}
+		if err = s.strg.Loan().CreateInstallments(ctx, tx, toInstallments(l.ID, schedule)); err != nil {
+			return nil, err
+		}
+		resp.Principal = interest.FormatDecimal(amount, interest.PostingScale)
+	}
+
+	l.OutstandingPrincipal = interest.FormatDecimal(outstanding, interest.PostingScale)
+	if err = s.strg.Loan().UpdateLoan(ctx, tx, l); err != nil {
+		s.log.Error("---EarlyRepayment->UpdateLoan--->", logger.Error(err))
+		return nil, err
+	}
+
+	err = s.strg.Audit().CreateAuditEvent(ctx, tx, &models.AuditEvent{
+		EventType:    models.AuditEventLoanEarlyRepayment,
+		ActorID:      req.UserID,
+		UserID:       req.UserID,
+		ResourceType: models.AuditResourceLoan,
+		ResourceID:   l.ID,
+		Details: map[string]interface{}{
+			"principal": resp.Principal,
+			"interest":  resp.Interest,
+			"mode":      req.Mode,
+		},
+		Before: before,
+		After:  auditSnapshot(l),
+	})
+	if err != nil {
+		s.log.Error("---EarlyRepayment->CreateAuditEvent--->", logger.Error(err))
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.log.Error("---EarlyRepayment->Commit--->", logger.Error(err))
+		return nil, err
+	}
+
+	resp.Loan, err = s.strg.Loan().GetLoanByID(ctx, &models.LoanByIDRequest{ID: req.ID, UserID: req.UserID})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// auditSnapshot is the state of the loan the audit log records before and after a change
+func auditSnapshot(l *models.Loan) map[string]interface{} {
+	return map[string]interface{}{
+		"status":                l.Status,
+		"outstanding_principal": l.OutstandingPrincipal,
+	}
+}
+
+// rebuild spreads the reduced principal over the pending installments, keeping their due dates
+func (s *Service) rebuild(l *models.Loan, pending []*models.LoanInstallment, principal, rate *big.Rat, mode string) ([]loan.Installment, error) {
+	start, err := time.Parse(config.BusinessDateLayout, l.DisbursedOn)
+	if err != nil {
+		return nil, err
+	}
+	scheduleType := loan.ScheduleType(l.ScheduleType)
+	first := pending[0].Number
+
+	if mode == models.EarlyRepaymentReducePayment {
+		return loan.Build(scheduleType, principal, rate, len(pending), start, first), nil
+	}
+
+	// the annuity keeps its installment, the differentiated schedule its principal part
+	keep := pending[0].Total
+	if scheduleType == loan.Differentiated {
+		keep = pending[0].Principal
+	}
+	payment, err := interest.ParseDecimal(keep)
+	if err != nil {
+		return nil, err
+	}
+
+	return loan.BuildWithPayment(scheduleType, principal, rate, payment, start, first)
+}
+
+// CollectInstallments debits the installments due by the business date from the borrowers' accounts.
+// An installment the account can't cover becomes overdue, is charged the late fee once past the
+// grace days and is retried on every run. Each loan is collected in its own transaction.
+func (s *Service) CollectInstallments(ctx context.Context, businessDate time.Time) error {
+	date := businessDate.Format(config.BusinessDateLayout)
+	s.log.Info("---CollectInstallments--->", logger.String("business_date", date))
+
+	ids, err := s.strg.Loan().GetDueLoanIDs(ctx, date)
+	if err != nil {
+		s.log.Error("---CollectInstallments->GetDueLoanIDs--->", logger.Error(err))
+		return err
+	}
+
+	var failed int
+	for _, id := range ids {
+		if err := s.collectLoan(ctx, id, businessDate); err != nil {
+			s.log.Error("---CollectInstallments->collectLoan--->", logger.String("loan_id", id), logger.Error(err))
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("installment collection failed for %d loans", failed)
+	}
+	return nil
+}
+
+func (s *Service) collectLoan(ctx context.Context, id string, businessDate time.Time) error {
+	tx, err := s.strg.TxRepo().BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	l, err := s.strg.Loan().GetLoanForUpdate(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if l.Status == models.LoanStatusRepaid {
+		return nil
+	}
+
+	values, err := parseDecimals(l.OutstandingPrincipal, l.LateFeesDue, l.LateFee)
+	if err != nil {
+		return err
+	}
+	outstanding, feesDue, lateFee := values[0], values[1], values[2]
+
+	account, err := s.strg.Account().GetAccountByID(ctx, &models.GetAccountByIDRequest{ID: l.AccountID})
+	if err != nil {
+		return err
+	}
+	// collecting only what the account covers keeps a failed debit from aborting the transaction
+	available := interest.Round(new(big.Rat).SetFloat64(account.Balance+account.OverdraftLimit), interest.PostingScale)
+
+	var (
+		maxDaysPastDue int
+		blocked        bool
+		unpaid         bool
+	)
+	for _, i := range l.Installments {
+		if i.Status == models.LoanInstallmentPaid {
+			continue
+		}
+		due, err := time.Parse(config.BusinessDateLayout, i.DueDate)
+		if err != nil {
+			return err
+		}
+		if due.After(businessDate) {
+			unpaid = true
+			break
+		}
+
+		amounts, err := parseDecimals(i.Principal, i.Interest, i.LateFee)
+		if err != nil {
+			return err
+		}
+		principal, periodInterest, fee := amounts[0], amounts[1], amounts[2]
+
+		daysPastDue := int(businessDate.Sub(due).Hours() / 24)
+		if fee.Sign() == 0 && lateFee.Sign() > 0 && daysPastDue > l.GraceDays {
+			fee.Set(lateFee)
+			feesDue.Add(feesDue, fee)
+			i.LateFee = interest.FormatDecimal(fee, interest.PostingScale)
+		}
+
+		total := new(big.Rat).Add(principal, periodInterest)
+		total.Add(total, fee)
+
+		// installments are collected in order, a later one is not paid before an earlier one
+		if !blocked && available.Cmp(total) >= 0 {
+			description := fmt.Sprintf("Loan installment %d", i.Number)
+			for _, leg := range []struct {
+				to     string
+				amount *big.Rat
+				suffix string
+			}{
+				{config.InterestIncomeAccountID, periodInterest, ", interest"},
+				{config.LoanPortfolioAccountID, principal, ""},
+				{config.FeeIncomeAccountID, fee, ", late fee"},
+			} {
+				if err = s.postRepayment(ctx, tx, l.AccountID, leg.to, leg.amount, description+leg.suffix); err != nil {
+					return err
+				}
+			}
+			available.Sub(available, total)
+			outstanding.Sub(outstanding, principal)
+			feesDue.Sub(feesDue, fee)
+			i.Status = models.LoanInstallmentPaid
+		} else {
+			blocked = true
+			unpaid = true
+			i.Status = models.LoanInstallmentOverdue
+			if daysPastDue > maxDaysPastDue {
+				maxDaysPastDue = daysPastDue
+			}
+		}
+
+		if err = s.strg.Loan().UpdateInstallment(ctx, tx, i); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case !unpaid && outstanding.Sign() == 0:
+		l.Status = models.LoanStatusRepaid
+	case maxDaysPastDue >= config.LoanDefaultAfterDays:
+		l.Status = models.LoanStatusDefaulted
+	case blocked:
+		l.Status = models.LoanStatusDelinquent
+	default:
+		l.Status = models.LoanStatusActive
+	}
+	l.OutstandingPrincipal = interest.FormatDecimal(outstanding, interest.PostingScale)
+	l.LateFeesDue = interest.FormatDecimal(feesDue, interest.PostingScale)
+
+	if err = s.strg.Loan().UpdateLoan(ctx, tx, l); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *Service) postRepayment(ctx context.Context, tx *sql.Tx, from, to string, amount *big.Rat, description string) error {
+	if amount.Sign() <= 0 {
+		return nil
+	}
+	_, err := s.payment.PostTransfer(ctx, tx, &models.PostTransferRequest{
+		FromAccountID: from,
+		ToAccountID:   to,
+		Amount:        interest.FormatDecimal(amount, interest.PostingScale),
+		Description:   description,
+	})
+	return err
+}
+
+func toInstallments(loanID string, schedule []loan.Installment) []*models.LoanInstallment {
+	installments := make([]*models.LoanInstallment, 0, len(schedule))
+	for _, i := range schedule {
+		installments = append(installments, &models.LoanInstallment{
+			LoanID:    loanID,
+			Number:    i.Number,
+			DueDate:   i.DueDate.Format(config.BusinessDateLayout),
+			Principal: interest.FormatDecimal(i.Principal, interest.PostingScale),
+			Interest:  interest.FormatDecimal(i.Interest, interest.PostingScale),
+			LateFee:   interest.FormatDecimal(new(big.Rat), interest.PostingScale),
+			Total:     interest.FormatDecimal(i.Total(), interest.PostingScale),
+			Status:    models.LoanInstallmentPending,
+		})
+	}
+	return installments
+}
+
+func parseDecimals(values ...string) ([]*big.Rat, error) {
+	parsed := make([]*big.Rat, 0, len(values))
+	for _, v := range values {
+		r, err := interest.ParseDecimal(v)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", v, err)
+		}
+		parsed = append(parsed, r)
+	}
+	return parsed, nil
+}
@@ -0,0 +1,207 @@
+package loan
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/internal/service/payment"
+	"github.com/dilmurodov/online_banking/internal/service/servicetest"
+	"github.com/dilmurodov/online_banking/pkg/helper"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var loanRowColumns = []string{
+	"guid", "user_id", "account_id", "product", "principal", "annual_rate", "term_months", "schedule_type",
+	"late_fee", "grace_days", "status", "outstanding_principal", "late_fees_due", "disbursed_on",
+	"disbursement_transaction_id", "closed_at", "created_at", "updated_at",
+}
+
+var installmentRowColumns = []string{"guid", "loan_id", "number", "due_date", "principal", "interest", "late_fee", "total", "status", "paid_at"}
+
+var accountRowColumns = []string{"guid", "user_id", "balance", "product", "accrued_interest", "overdraft_limit", "created_at", "updated_at", "status", "closed_at"}
+
+func newTestService(t *testing.T) (*Service, sqlmock.Sqlmock) {
+	strg, mock := servicetest.NewStore(t)
+
+	return NewService(
+		config.Config{BusinessTimezone: "UTC"},
+		zap.NewNop(),
+		strg,
+		payment.NewService(config.Config{}, zap.NewNop(), strg),
+	), mock
+}
+
+// expectDueLoan returns a loan of 1200 with the first of two installments due on 15 May
+func expectDueLoan(mock sqlmock.Sqlmock, balance float64) {
+	mock.ExpectQuery(`^SELECT l.guid FROM loans l`).
+		WithArgs("2023-05-20").
+		WillReturnRows(sqlmock.NewRows([]string{"guid"}).AddRow("TestLoanID"))
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT (.+) FROM loans WHERE guid = \$1 FOR UPDATE`).
+		WithArgs("TestLoanID").
+		WillReturnRows(sqlmock.NewRows(loanRowColumns).AddRow(
+			"TestLoanID", "TestUserID", "TestAccountID", "consumer", "1200.00", "0.18", 2, "differentiated",
+			"10.00", 3, "active", "1200.00", "0.00", "2023-04-15",
+			"TestDisbursementID", nil, "2023-04-15T10:00:00Z", "2023-04-15T10:00:00Z",
+		))
+	mock.ExpectQuery(`^SELECT (.+) FROM loan_installments WHERE loan_id = \$1`).
+		WithArgs("TestLoanID").
+		WillReturnRows(sqlmock.NewRows(installmentRowColumns).
+			AddRow("TestInstallment1", "TestLoanID", 1, "2023-05-15", "600.00", "18.00", "0.00", "618.00", "pending", nil).
+			AddRow("TestInstallment2", "TestLoanID", 2, "2023-06-15", "600.00", "9.00", "0.00", "609.00", "pending", nil))
+	mock.ExpectQuery(`^SELECT (.+) FROM accounts WHERE guid=\$1`).
+		WithArgs("TestAccountID").
+		WillReturnRows(sqlmock.NewRows(accountRowColumns).AddRow("TestAccountID", "TestUserID", balance, "current", "0", 0.0, "2023-01-01", "2023-01-01", "active", nil))
+}
+
+func TestLoan_QuoteLoan(t *testing.T) {
+	r := require.New(t)
+
+	s, mock := newTestService(t)
+
+	mock.ExpectQuery(`^SELECT (.+) FROM loan_products`).
+		WithArgs("consumer").
+		WillReturnRows(sqlmock.NewRows([]string{"code", "name", "annual_rate", "min_amount", "max_amount", "min_term_months", "max_term_months", "late_fee", "grace_days"}).
+			AddRow("consumer", "Consumer loan", "0.18", "500.00", "50000.00", 3, 60, "10.00", 3))
+
+	t.Run("ANNUITY", func(t *testing.T) {
+		resp, err := s.QuoteLoan(context.Background(), &models.LoanQuoteRequest{
+			Product:    "consumer",
+			Amount:     12000,
+			TermMonths: 12,
+		})
+		r.NoError(err)
+		r.NoError(mock.ExpectationsWereMet())
+
+		// 1.5% a month over 12 months: 1100.16 a month
+		r.Equal(models.LoanScheduleAnnuity, resp.ScheduleType)
+		r.Len(resp.Installments, 12)
+		r.Equal("1100.16", resp.Installments[0].Total)
+		r.Equal("180.00", resp.Installments[0].Interest)
+		r.Equal("1201.95", resp.TotalInterest)
+	})
+}
+
+func TestLoan_CollectInstallments(t *testing.T) {
+	r := require.New(t)
+
+	s, mock := newTestService(t)
+
+	businessDate := time.Date(2023, time.May, 20, 0, 0, 0, 0, time.UTC)
+
+	expectDueLoan(mock, 1000)
+
+	// five days past due is beyond the three grace days
+	servicetest.ExpectPosting(mock, "TestAccountID", config.InterestIncomeAccountID, 18, "18.00", "Loan installment 1, interest", "")
+	servicetest.ExpectPosting(mock, "TestAccountID", config.LoanPortfolioAccountID, 600, "600.00", "Loan installment 1", "")
+	servicetest.ExpectPosting(mock, "TestAccountID", config.FeeIncomeAccountID, 10, "10.00", "Loan installment 1, late fee", "")
+
+	mock.ExpectExec(`^UPDATE loan_installments SET`).
+		WithArgs("TestInstallment1", "paid", "10.00").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`^UPDATE loans SET`).
+		WithArgs("TestLoanID", "active", "600.00", "0.00").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	t.Run("PAID", func(t *testing.T) {
+		err := s.CollectInstallments(context.Background(), businessDate)
+		r.NoError(err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+}
+
+func TestLoan_CollectInstallments_InsufficientFunds(t *testing.T) {
+	r := require.New(t)
+
+	s, mock := newTestService(t)
+
+	businessDate := time.Date(2023, time.May, 20, 0, 0, 0, 0, time.UTC)
+
+	expectDueLoan(mock, 100)
+
+	mock.ExpectExec(`^UPDATE loan_installments SET`).
+		WithArgs("TestInstallment1", "overdue", "10.00").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`^UPDATE loans SET`).
+		WithArgs("TestLoanID", "delinquent", "1200.00", "10.00").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	t.Run("OVERDUE", func(t *testing.T) {
+		err := s.CollectInstallments(context.Background(), businessDate)
+		r.NoError(err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+}
+
+func TestLoan_EarlyRepayment(t *testing.T) {
+	r := require.New(t)
+
+	s, mock := newTestService(t)
+
+	// the loan runs relative to today so none of its installments is due yet
+	today := helper.BusinessToday("UTC")
+	disbursedOn := today.AddDate(0, 0, -10).Format(config.BusinessDateLayout)
+	loanRow := func(outstanding string) *sqlmock.Rows {
+		return sqlmock.NewRows(loanRowColumns).AddRow(
+			"TestLoanID", "TestUserID", "TestAccountID", "consumer", "1200.00", "0.18", 2, "differentiated",
+			"10.00", 3, "active", outstanding, "0.00", disbursedOn,
+			"TestDisbursementID", nil, "2023-04-15T10:00:00Z", "2023-04-15T10:00:00Z",
+		)
+	}
+	installmentRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows(installmentRowColumns).
+			AddRow("TestInstallment1", "TestLoanID", 1, today.AddDate(0, 0, 20).Format(config.BusinessDateLayout), "600.00", "18.00", "0.00", "618.00", "pending", nil).
+			AddRow("TestInstallment2", "TestLoanID", 2, today.AddDate(0, 0, 51).Format(config.BusinessDateLayout), "600.00", "9.00", "0.00", "609.00", "pending", nil)
+	}
+
+	mock.ExpectQuery(`^SELECT (.+) FROM loans WHERE guid = \$1 AND user_id = \$2`).
+		WithArgs("TestLoanID", "TestUserID").
+		WillReturnRows(loanRow("1200.00"))
+	mock.ExpectQuery(`^SELECT (.+) FROM loan_installments WHERE loan_id = \$1`).WithArgs("TestLoanID").WillReturnRows(installmentRows())
+	servicetest.ExpectAuditBegin(mock)
+	mock.ExpectQuery(`^SELECT (.+) FROM loans WHERE guid = \$1 FOR UPDATE`).WithArgs("TestLoanID").WillReturnRows(loanRow("1200.00"))
+	mock.ExpectQuery(`^SELECT (.+) FROM loan_installments WHERE loan_id = \$1`).WithArgs("TestLoanID").WillReturnRows(installmentRows())
+	mock.ExpectQuery(`^SELECT EXISTS \(\s*SELECT 1\s*FROM account_holders ah\s*JOIN sanctions_hits`).WithArgs(pq.Array([]string{"TestAccountID", config.LoanPortfolioAccountID})).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectQuery(`^SELECT (.+) FROM accounts WHERE guid=\$1`).
+		WithArgs(config.LoanPortfolioAccountID).
+		WillReturnRows(sqlmock.NewRows(accountRowColumns).AddRow(config.LoanPortfolioAccountID, config.SystemUserID, 0.0, "current", "0", 0.0, "2023-01-01", "2023-01-01", "active", nil))
+	servicetest.ExpectPosting(mock, "TestAccountID", config.LoanPortfolioAccountID, 200, "200.00", "Loan early repayment", "")
+	mock.ExpectExec(`^DELETE FROM loan_installments`).WithArgs("TestLoanID").WillReturnResult(sqlmock.NewResult(0, 2))
+	insert := mock.ExpectPrepare(`^INSERT INTO loan_installments`)
+	insert.ExpectQuery().WillReturnRows(sqlmock.NewRows([]string{"guid"}).AddRow("TestInstallment3"))
+	insert.ExpectQuery().WillReturnRows(sqlmock.NewRows([]string{"guid"}).AddRow("TestInstallment4"))
+	mock.ExpectExec(`^UPDATE loans SET`).
+		WithArgs("TestLoanID", "active", "1000.00", "0.00").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	servicetest.ExpectAuditEvent(mock, models.AuditEventLoanEarlyRepayment, "TestUserID", "", []byte(`{"interest":"0.00","mode":"reduce_payment","principal":"200.00"}`),
+		"TestUserID", models.AuditResourceLoan, "TestLoanID", "",
+		`{"outstanding_principal":"1200.00","status":"active"}`, `{"outstanding_principal":"1000.00","status":"active"}`,
+		"", sqlmock.AnyArg(), sqlmock.AnyArg())
+	mock.ExpectCommit()
+	mock.ExpectQuery(`^SELECT (.+) FROM loans WHERE guid = \$1 AND user_id = \$2`).
+		WithArgs("TestLoanID", "TestUserID").
+		WillReturnRows(loanRow("1000.00"))
+	mock.ExpectQuery(`^SELECT (.+) FROM loan_installments WHERE loan_id = \$1`).WithArgs("TestLoanID").WillReturnRows(installmentRows())
+
+	t.Run("REDUCE_PAYMENT", func(t *testing.T) {
+		resp, err := s.EarlyRepayment(context.Background(), &models.EarlyRepaymentRequest{
+			ID:     "TestLoanID",
+			UserID: "TestUserID",
+			Amount: 200,
+		})
+		r.NoError(err)
+		r.Equal("200.00", resp.Principal)
+		r.Equal("1000.00", resp.Loan.OutstandingPrincipal)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,35 @@
+package overdraft
+
+import (
+	"context"
+	"time"
+
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/internal/service/payment"
+	"github.com/dilmurodov/online_banking/pkg/logger"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/dilmurodov/online_banking/storage"
+)
+
+type ServiceI interface {
+	SetOverdraftLimit(ctx context.Context, req *models.SetOverdraftLimitRequest) (*models.OverdraftUsage, error)
+	GetOverdraftUsage(ctx context.Context, accountID string) (*models.OverdraftUsage, error)
+	AccrueDaily(ctx context.Context, businessDate time.Time) error
+	Charge(ctx context.Context, businessDate time.Time) error
+}
+
+type Service struct {
+	cfg     config.Config
+	log     logger.LoggerI
+	strg    storage.StorageI
+	payment payment.ServiceI
+}
+
+func NewService(cfg config.Config, log logger.LoggerI, strg storage.StorageI, paymentService payment.ServiceI) *Service {
+	return &Service{
+		cfg:     cfg,
+		log:     log,
+		strg:    strg,
+		payment: paymentService,
+	}
+}
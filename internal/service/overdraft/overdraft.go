@@ -0,0 +1,213 @@
+package overdraft
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/internal/service/account"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/interest"
+	"github.com/dilmurodov/online_banking/pkg/logger"
+	"github.com/dilmurodov/online_banking/pkg/models"
+)
+
+// SetOverdraftLimit opts a current account into an overdraft, or out of it with a zero limit
+func (s *Service) SetOverdraftLimit(ctx context.Context, req *models.SetOverdraftLimitRequest) (*models.OverdraftUsage, error) {
+	s.log.Info("---SetOverdraftLimit--->", logger.Any("req", req))
+
+	if req.Limit < 0 || req.Limit > s.cfg.OverdraftMaxLimit {
+		return nil, fmt.Errorf("limit must be between 0 and %.2f", s.cfg.OverdraftMaxLimit)
+	}
+	if _, err := interest.ParseAmount(req.Limit); err != nil {
+		return nil, err
+	}
+
+	if err := account.CheckPermission(ctx, s.strg, req.AccountID, req.UserID, models.AccountPermissionManage); err != nil {
+		return nil, err
+	}
+	account, err := s.strg.Account().GetAccountByID(ctx, &models.GetAccountByIDRequest{ID: req.AccountID})
+	if err != nil {
+		return nil, &customerrors.AccountNotFoundError{Guid: req.AccountID}
+	}
+	if account.Product != models.ProductCurrent {
+		return nil, fmt.Errorf("an overdraft is only available on a current account")
+	}
+	if account.Status == models.AccountStatusClosed {
+		return nil, &customerrors.AccountStatusError{Guid: account.ID, Status: account.Status}
+	}
+
+	if err = s.strg.Overdraft().SetOverdraftLimit(ctx, req); err != nil {
+		s.log.Error("---SetOverdraftLimit--->", logger.Error(err))
+		return nil, err
+	}
+
+	return s.GetOverdraftUsage(ctx, req.AccountID)
+}
+
+func (s *Service) GetOverdraftUsage(ctx context.Context, accountID string) (*models.OverdraftUsage, error) {
+	resp, err := s.strg.Overdraft().GetOverdraftUsage(ctx, accountID)
+	if err != nil {
+		s.log.Error("---GetOverdraftUsage--->", logger.Error(err))
+		return nil, err
+	}
+	return resp, nil
+}
+
+// AccrueDaily accrues one day of overdraft interest on the overdrawn end-of-day balance of every account.
+// Accounts that already accrued for the date are skipped, so the run can be repeated safely.
+func (s *Service) AccrueDaily(ctx context.Context, businessDate time.Time) error {
+	date := businessDate.Format(config.BusinessDateLayout)
+	s.log.Info("---AccrueOverdraft--->", logger.String("business_date", date))
+
+	candidates, err := s.strg.Overdraft().GetOverdraftAccrualCandidates(ctx, &models.GetAccrualCandidatesRequest{
+		BusinessDate: date,
+		EndOfDay:     businessDate.AddDate(0, 0, 1),
+	})
+	if err != nil {
+		s.log.Error("---AccrueOverdraft->GetOverdraftAccrualCandidates--->", logger.Error(err))
+		return err
+	}
+
+	var accrued, failed int
+	for _, c := range candidates {
+		balance, err := interest.ParseDecimal(c.Balance)
+		if err != nil {
+			s.log.Error("---AccrueOverdraft->ParseDecimal--->", logger.String("account_id", c.AccountID), logger.Error(err))
+			failed++
+			continue
+		}
+		rate, err := interest.ParseDecimal(c.AnnualRate)
+		if err != nil {
+			s.log.Error("---AccrueOverdraft->ParseDecimal--->", logger.String("account_id", c.AccountID), logger.Error(err))
+			failed++
+			continue
+		}
+
+		if balance.Sign() >= 0 {
+			continue
+		}
+
+		amount := interest.DailyInterest(balance.Neg(balance), rate, interest.DayCount(c.DayCount), businessDate)
+		if amount.Sign() == 0 {
+			continue
+		}
+
+		created, err := s.strg.Overdraft().CreateOverdraftAccrual(ctx, &models.InterestAccrual{
+			AccountID:    c.AccountID,
+			BusinessDate: date,
+			Balance:      c.Balance,
+			AnnualRate:   c.AnnualRate,
+			DayCount:     c.DayCount,
+			Amount:       interest.FormatDecimal(amount, interest.AccrualScale),
+		})
+		if err != nil {
+			s.log.Error("---AccrueOverdraft->CreateOverdraftAccrual--->", logger.String("account_id", c.AccountID), logger.Error(err))
+			failed++
+			continue
+		}
+		if created {
+			accrued++
+		}
+	}
+
+	s.log.Info("---AccrueOverdraft->Done--->", logger.String("business_date", date), logger.Int("accrued", accrued), logger.Int("failed", failed))
+	if failed > 0 {
+		return fmt.Errorf("overdraft accrual failed for %d accounts", failed)
+	}
+	return nil
+}
+
+// Charge debits the overdraft interest owed for the month to the bank's interest income account.
+// It only acts on the last day of a month. The charge never takes an account past its limit:
+// what doesn't fit, like fractions of a cent, stays owed and is charged at a later month end.
+func (s *Service) Charge(ctx context.Context, businessDate time.Time) error {
+	if !interest.IsLastDayOfMonth(businessDate) {
+		return nil
+	}
+
+	period := time.Date(businessDate.Year(), businessDate.Month(), 1, 0, 0, 0, 0, businessDate.Location())
+	s.log.Info("---ChargeOverdraft--->", logger.String("period", period.Format(config.BusinessDateLayout)))
+
+	candidates, err := s.strg.Overdraft().GetOverdraftChargeCandidates(ctx, &models.GetCapitalizationCandidatesRequest{
+		Period: period.Format(config.BusinessDateLayout),
+	})
+	if err != nil {
+		s.log.Error("---ChargeOverdraft->GetOverdraftChargeCandidates--->", logger.Error(err))
+		return err
+	}
+
+	var failed int
+	for _, c := range candidates {
+		accrued, err := interest.ParseDecimal(c.AccruedInterest)
+		if err != nil {
+			s.log.Error("---ChargeOverdraft->ParseDecimal--->", logger.String("account_id", c.AccountID), logger.Error(err))
+			failed++
+			continue
+		}
+		available, err := interest.ParseDecimal(c.Available)
+		if err != nil {
+			s.log.Error("---ChargeOverdraft->ParseDecimal--->", logger.String("account_id", c.AccountID), logger.Error(err))
+			failed++
+			continue
+		}
+
+		amount := interest.RoundDown(accrued, interest.PostingScale)
+		if available = interest.RoundDown(available, interest.PostingScale); available.Cmp(amount) < 0 {
+			amount = available
+		}
+		if amount.Sign() <= 0 {
+			s.log.Info("---ChargeOverdraft->LimitReached--->", logger.String("account_id", c.AccountID))
+			continue
+		}
+
+		if err := s.chargeAccount(ctx, c.AccountID, period, interest.FormatDecimal(amount, interest.PostingScale)); err != nil {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("overdraft charge failed for %d accounts", failed)
+	}
+	return nil
+}
+
+func (s *Service) chargeAccount(ctx context.Context, accountID string, period time.Time, amount string) error {
+	tx, err := s.strg.TxRepo().BeginTx(ctx)
+	if err != nil {
+		s.log.Error("---ChargeOverdraft->BeginTx--->", logger.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	posted, err := s.payment.PostTransfer(ctx, tx, &models.PostTransferRequest{
+		FromAccountID: accountID,
+		ToAccountID:   config.InterestIncomeAccountID,
+		Amount:        amount,
+		Description:   "Overdraft interest for " + period.Format("2006-01"),
+		Reference:     "ODI-" + period.Format("2006-01"),
+	})
+	if err != nil {
+		s.log.Error("---ChargeOverdraft->PostTransfer--->", logger.Error(err))
+		return err
+	}
+
+	err = s.strg.Overdraft().CreateOverdraftCharge(ctx, tx, &models.InterestCapitalization{
+		AccountID:     accountID,
+		Period:        period.Format(config.BusinessDateLayout),
+		Amount:        amount,
+		TransactionID: posted.Transactions[0].ID,
+	})
+	if err != nil {
+		s.log.Error("---ChargeOverdraft->CreateOverdraftCharge--->", logger.Error(err))
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.log.Error("---ChargeOverdraft->Commit--->", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
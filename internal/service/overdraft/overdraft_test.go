@@ -0,0 +1,124 @@
+package overdraft
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/internal/service/payment"
+	"github.com/dilmurodov/online_banking/internal/service/servicetest"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestService(t *testing.T) (*Service, sqlmock.Sqlmock) {
+	strg, mock := servicetest.NewStore(t)
+
+	return NewService(
+		config.Config{OverdraftMaxLimit: 5000},
+		zap.NewNop(),
+		strg,
+		payment.NewService(config.Config{}, zap.NewNop(), strg),
+	), mock
+}
+
+func TestOverdraft_AccrueDaily(t *testing.T) {
+	r := require.New(t)
+
+	s, mock := newTestService(t)
+
+	businessDate := time.Date(2023, time.March, 10, 0, 0, 0, 0, time.UTC)
+
+	candidates := sqlmock.NewRows([]string{"guid", "balance", "overdraft_rate", "day_count"}).
+		AddRow("TestAccountID", "-365.00", "0.20", "ACT/365")
+	mock.ExpectQuery(`^SELECT (.+?) FROM \(`).
+		WithArgs("2023-03-10", businessDate.AddDate(0, 0, 1)).
+		WillReturnRows(candidates)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^INSERT INTO overdraft_accruals`).
+		WithArgs("TestAccountID", "2023-03-10", "-365.00", "0.20", "ACT/365", "0.2000000000").
+		WillReturnRows(sqlmock.NewRows([]string{"guid"}).AddRow("TestAccrualID"))
+	mock.ExpectExec(`^UPDATE accounts SET accrued_overdraft_interest`).WithArgs("0.2000000000", "TestAccountID").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	t.Run("SUCCESS", func(t *testing.T) {
+		err := s.AccrueDaily(context.Background(), businessDate)
+		r.NoError(err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+}
+
+func TestOverdraft_Charge(t *testing.T) {
+	r := require.New(t)
+
+	s, mock := newTestService(t)
+
+	// 6.20 is owed but only 4.50 of the limit is left, the rest waits for a later month end
+	candidates := sqlmock.NewRows([]string{"guid", "accrued_overdraft_interest", "available"}).AddRow("TestAccountID", "6.2000000000", "4.5")
+	mock.ExpectQuery(`^SELECT (.+?) FROM accounts a`).WithArgs("2023-03-01").WillReturnRows(candidates)
+
+	txColumns := []string{"guid", "transaction_amount", "transaction_type", "recipient_id", "description", "reference", "created_at"}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT status FROM accounts`).WithArgs("TestAccountID").WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("active"))
+	mock.ExpectPrepare("INSERT INTO transactions").ExpectQuery().
+		WithArgs("TestAccountID", 4.5, config.InterestIncomeAccountID, "debit", "Overdraft interest for 2023-03", "ODI-2023-03").
+		WillReturnRows(sqlmock.NewRows(txColumns).AddRow("TestDebitID", 4.5, "debit", config.InterestIncomeAccountID, "Overdraft interest for 2023-03", "ODI-2023-03", "2023-04-01"))
+	mock.ExpectExec(`^UPDATE transactions SET`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`^UPDATE accounts SET balance = balance`).WithArgs("-4.50", "TestAccountID").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`^SELECT status FROM accounts`).WithArgs(config.InterestIncomeAccountID).WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("active"))
+	mock.ExpectPrepare("INSERT INTO transactions").ExpectQuery().
+		WithArgs(config.InterestIncomeAccountID, 4.5, "TestAccountID", "credit", "Overdraft interest for 2023-03", "ODI-2023-03").
+		WillReturnRows(sqlmock.NewRows(txColumns).AddRow("TestCreditID", 4.5, "credit", "TestAccountID", "Overdraft interest for 2023-03", "ODI-2023-03", "2023-04-01"))
+	mock.ExpectExec(`^UPDATE transactions SET`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`^UPDATE accounts SET balance = balance`).WithArgs("4.50", config.InterestIncomeAccountID).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`^INSERT INTO overdraft_charges`).
+		WithArgs("TestAccountID", "2023-03-01", "4.50", "TestDebitID").
+		WillReturnRows(sqlmock.NewRows([]string{"guid"}).AddRow("TestChargeID"))
+	mock.ExpectExec(`^UPDATE accounts SET accrued_overdraft_interest = accrued_overdraft_interest -`).WithArgs("4.50", "TestAccountID").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	t.Run("MONTH_END", func(t *testing.T) {
+		err := s.Charge(context.Background(), time.Date(2023, time.March, 31, 0, 0, 0, 0, time.UTC))
+		r.NoError(err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+}
+
+func TestOverdraft_SetOverdraftLimit(t *testing.T) {
+	r := require.New(t)
+
+	s, mock := newTestService(t)
+
+	account := sqlmock.NewRows([]string{"guid", "user_id", "balance", "product", "accrued_interest", "overdraft_limit", "created_at", "updated_at", "status", "closed_at"}).
+		AddRow("TestAccountID", "TestUserID", -120.0, "current", "0", 300.0, "2021-01-01", "2021-01-01", "active", nil)
+	mock.ExpectQuery(`^SELECT h.role FROM account_holders`).WithArgs("TestAccountID", "TestUserID").WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("owner"))
+	mock.ExpectQuery(`^SELECT (.+?) FROM accounts`).WithArgs("TestAccountID").WillReturnRows(account)
+	mock.ExpectExec(`^UPDATE accounts SET overdraft_limit`).WithArgs(100.0, "TestAccountID").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`^SELECT \(-balance\)`).WithArgs("TestAccountID").WillReturnRows(sqlmock.NewRows([]string{"used"}).AddRow("120.00"))
+
+	t.Run("BELOW_USAGE", func(t *testing.T) {
+		_, err := s.SetOverdraftLimit(context.Background(), &models.SetOverdraftLimitRequest{
+			AccountID: "TestAccountID",
+			UserID:    "TestUserID",
+			Limit:     100,
+		})
+		r.Equal(&customerrors.OverdraftLimitError{Used: "120.00"}, err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("OVER_MAX", func(t *testing.T) {
+		_, err := s.SetOverdraftLimit(context.Background(), &models.SetOverdraftLimitRequest{
+			AccountID: "TestAccountID",
+			UserID:    "TestUserID",
+			Limit:     5000.01,
+		})
+		r.Error(err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+}
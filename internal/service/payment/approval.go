@@ -0,0 +1,257 @@
+package payment
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/internal/service/account"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/interest"
+	"github.com/dilmurodov/online_banking/pkg/logger"
+	"github.com/dilmurodov/online_banking/pkg/models"
+)
+
+// checkHolder checks that the user may pay from the account and returns the account's approval policy
+func (s *Service) checkHolder(ctx context.Context, req *models.TransferRequest) (*models.ApprovalPolicy, error) {
+	policy, err := s.strg.AccountHolder().GetApprovalPolicy(ctx, req.FromAccountID, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if policy.Role == "" {
+		return nil, &customerrors.AccountNotFoundError{Guid: req.FromAccountID}
+	}
+	if !models.AccountRoleAllows(policy.Role, models.AccountPermissionTransact) {
+		return nil, &customerrors.AccountPermissionError{Guid: req.FromAccountID}
+	}
+	return policy, nil
+}
+
+// requestApproval stores the transfer for approval instead when the amount is above the account's
+// dual approval threshold and another holder can approve it
+func (s *Service) requestApproval(ctx context.Context, req *models.TransferRequest, policy *models.ApprovalPolicy) (*models.TransferApproval, error) {
+	threshold, err := interest.ParseDecimal(policy.Threshold)
+	if err != nil || threshold.Sign() <= 0 || policy.Approvers == 0 {
+		return nil, nil
+	}
+	amount, err := interest.ParseAmount(req.Amount)
+	if err != nil || amount.Cmp(threshold) <= 0 {
+		return nil, nil
+	}
+	if req.Immediate {
+		return nil, &customerrors.DualApprovalRequiredError{AccountID: req.FromAccountID}
+	}
+
+	tx, err := s.strg.Audit().BeginTx(ctx)
+	if err != nil {
+		s.log.Error("---Transfer->BeginTx--->", logger.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	approval, err := s.strg.AccountHolder().CreateTransferApproval(ctx, tx, &models.TransferApproval{
+		AccountID:   req.FromAccountID,
+		RequestedBy: req.UserID,
+		ToAccountID: req.ToAccountID,
+		Amount:      req.Amount,
+		Description: req.Description,
+		Reference:   req.Reference,
+		ExpiresAt:   time.Now().Add(config.TransferApprovalTTL).Format(config.DatabaseTimeLayout),
+	})
+	if err != nil {
+		s.log.Error("---Transfer->CreateTransferApproval--->", logger.Error(err))
+		return nil, err
+	}
+
+	err = s.strg.Audit().CreateAuditEvent(ctx, tx, &models.AuditEvent{
+		EventType:    models.AuditEventTransferApprovalRequested,
+		ActorID:      req.UserID,
+		UserID:       req.UserID,
+		ResourceType: models.AuditResourceTransferApproval,
+		ResourceID:   approval.ID,
+		Details:      approvalDetails(approval),
+		After:        map[string]interface{}{"status": approval.Status},
+	})
+	if err != nil {
+		s.log.Error("---Transfer->CreateAuditEvent--->", logger.Error(err))
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.log.Error("---Transfer->Commit--->", logger.Error(err))
+		return nil, err
+	}
+
+	return approval, nil
+}
+
+func (s *Service) GetTransferApprovals(ctx context.Context, req *models.GetTransferApprovalsRequest, userID string) (*models.GetTransferApprovalsResponse, error) {
+	if err := account.CheckPermission(ctx, s.strg, req.AccountID, userID, models.AccountPermissionView); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.strg.AccountHolder().GetTransferApprovals(ctx, req)
+	if err != nil {
+		s.log.Error("---GetTransferApprovals--->", logger.Error(err))
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ApproveTransfer makes a transfer waiting for approval. It must be approved by a holder other than the one who requested it.
+func (s *Service) ApproveTransfer(ctx context.Context, req *models.TransferApprovalByIDRequest) (*models.TransferApprovalResponse, error) {
+	s.log.Info("---ApproveTransfer--->", logger.Any("req", req))
+
+	if err := account.CheckPermission(ctx, s.strg, req.AccountID, req.UserID, models.AccountPermissionTransact); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.strg.Audit().BeginTx(ctx)
+	if err != nil {
+		s.log.Error("---ApproveTransfer->BeginTx--->", logger.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	approval, err := s.strg.AccountHolder().GetTransferApprovalForUpdate(ctx, tx, req)
+	if err != nil {
+		return nil, err
+	}
+	if approval.Status != models.TransferApprovalStatusPending {
+		return nil, &customerrors.TransferApprovalStateError{Status: approval.Status}
+	}
+	if approval.RequestedBy == req.UserID {
+		return nil, fmt.Errorf("a transfer must be approved by another holder of the account")
+	}
+
+	expiresAt, err := time.Parse(time.RFC3339, approval.ExpiresAt)
+	if err == nil && !expiresAt.After(time.Now()) {
+		approval.Status = models.TransferApprovalStatusExpired
+		if err = s.strg.AccountHolder().UpdateTransferApproval(ctx, tx, approval); err != nil {
+			s.log.Error("---ApproveTransfer->UpdateTransferApproval--->", logger.Error(err))
+			return nil, err
+		}
+		if err = s.auditDecision(ctx, tx, approval, req.UserID); err != nil {
+			s.log.Error("---ApproveTransfer->CreateAuditEvent--->", logger.Error(err))
+			return nil, err
+		}
+		if err = tx.Commit(); err != nil {
+			s.log.Error("---ApproveTransfer->Commit--->", logger.Error(err))
+			return nil, err
+		}
+		return nil, &customerrors.TransferApprovalStateError{Status: approval.Status}
+	}
+
+	// a hit may have come up while the transfer was waiting
+	if err = s.checkSanctions(ctx, approval.AccountID, approval.ToAccountID); err != nil {
+		return nil, err
+	}
+
+	transfer, err := s.transfer(ctx, tx, &models.TransferRequest{
+		FromAccountID: approval.AccountID,
+		ToAccountID:   approval.ToAccountID,
+		Amount:        approval.Amount,
+		Description:   approval.Description,
+		Reference:     approval.Reference,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	approval.Status = models.TransferApprovalStatusApproved
+	approval.DecidedBy = req.UserID
+	if len(transfer.Transactions) > 0 {
+		approval.TransactionID = transfer.Transactions[0].ID
+	}
+	if err = s.strg.AccountHolder().UpdateTransferApproval(ctx, tx, approval); err != nil {
+		s.log.Error("---ApproveTransfer->UpdateTransferApproval--->", logger.Error(err))
+		return nil, err
+	}
+
+	if err = s.auditDecision(ctx, tx, approval, req.UserID); err != nil {
+		s.log.Error("---ApproveTransfer->CreateAuditEvent--->", logger.Error(err))
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.log.Error("---ApproveTransfer->Commit--->", logger.Error(err))
+		return nil, err
+	}
+
+	return &models.TransferApprovalResponse{
+		Approval:     approval,
+		Transactions: transfer.Transactions,
+	}, nil
+}
+
+// RejectTransfer refuses a transfer waiting for approval, the holder who requested it may withdraw it the same way
+func (s *Service) RejectTransfer(ctx context.Context, req *models.TransferApprovalByIDRequest) (*models.TransferApprovalResponse, error) {
+	s.log.Info("---RejectTransfer--->", logger.Any("req", req))
+
+	if err := account.CheckPermission(ctx, s.strg, req.AccountID, req.UserID, models.AccountPermissionTransact); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.strg.Audit().BeginTx(ctx)
+	if err != nil {
+		s.log.Error("---RejectTransfer->BeginTx--->", logger.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	approval, err := s.strg.AccountHolder().GetTransferApprovalForUpdate(ctx, tx, req)
+	if err != nil {
+		return nil, err
+	}
+	if approval.Status != models.TransferApprovalStatusPending {
+		return nil, &customerrors.TransferApprovalStateError{Status: approval.Status}
+	}
+
+	approval.Status = models.TransferApprovalStatusRejected
+	approval.DecidedBy = req.UserID
+	if err = s.strg.AccountHolder().UpdateTransferApproval(ctx, tx, approval); err != nil {
+		s.log.Error("---RejectTransfer->UpdateTransferApproval--->", logger.Error(err))
+		return nil, err
+	}
+
+	if err = s.auditDecision(ctx, tx, approval, req.UserID); err != nil {
+		s.log.Error("---RejectTransfer->CreateAuditEvent--->", logger.Error(err))
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.log.Error("---RejectTransfer->Commit--->", logger.Error(err))
+		return nil, err
+	}
+
+	return &models.TransferApprovalResponse{Approval: approval}, nil
+}
+
+// auditDecision records a pending approval's move to the status it was given, in the caller's transaction
+func (s *Service) auditDecision(ctx context.Context, tx *sql.Tx, approval *models.TransferApproval, actorID string) error {
+	after := map[string]interface{}{"status": approval.Status}
+	if approval.TransactionID != "" {
+		after["transaction_id"] = approval.TransactionID
+	}
+
+	return s.strg.Audit().CreateAuditEvent(ctx, tx, &models.AuditEvent{
+		EventType:    models.AuditEventTransferApprovalDecided,
+		ActorID:      actorID,
+		UserID:       approval.RequestedBy,
+		ResourceType: models.AuditResourceTransferApproval,
+		ResourceID:   approval.ID,
+		Details:      approvalDetails(approval),
+		Before:       map[string]interface{}{"status": models.TransferApprovalStatusPending},
+		After:        after,
+	})
+}
+
+func approvalDetails(approval *models.TransferApproval) map[string]interface{} {
+	return map[string]interface{}{
+		"account_id":    approval.AccountID,
+		"to_account_id": approval.ToAccountID,
+		"amount":        approval.Amount,
+	}
+}
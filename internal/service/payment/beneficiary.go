@@ -0,0 +1,94 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/internal/service/beneficiary"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/logger"
+	"github.com/dilmurodov/online_banking/pkg/models"
+)
+
+// applyBeneficiary resolves the destination of a transfer from the user's address book
+// and enforces the beneficiary's limits
+func (s *Service) applyBeneficiary(ctx context.Context, req *models.TransferRequest) error {
+	b, err := s.strg.Beneficiary().GetBeneficiaryByID(ctx, &models.BeneficiaryByIDRequest{
+		ID:     req.BeneficiaryID,
+		UserID: req.UserID,
+	})
+	if err != nil {
+		s.log.Error("---Transfer->GetBeneficiaryByID--->", logger.Error(err))
+		return err
+	}
+
+	if req.ToAccountID != "" && req.ToAccountID != b.AccountID {
+		return fmt.Errorf("to_account_id does not match the beneficiary")
+	}
+	req.ToAccountID = b.AccountID
+
+	return s.checkBeneficiaryLimits(ctx, req, b)
+}
+
+// checkRecipientBeneficiary enforces the limits of a transfer made to an account of the user's address
+// book without naming the beneficiary, so paying the account directly doesn't get around them
+func (s *Service) checkRecipientBeneficiary(ctx context.Context, req *models.TransferRequest) error {
+	b, err := s.strg.Beneficiary().GetBeneficiaryByAccount(ctx, &models.BeneficiaryByAccountRequest{
+		UserID:    req.UserID,
+		AccountID: req.ToAccountID,
+	})
+	if err != nil {
+		s.log.Error("---Transfer->GetBeneficiaryByAccount--->", logger.Error(err))
+		return err
+	}
+	if b == nil {
+		return nil
+	}
+
+	return s.checkBeneficiaryLimits(ctx, req, b)
+}
+
+// checkBeneficiaryLimits enforces the beneficiary's limits and the cooling-off cap for newly added beneficiaries
+func (s *Service) checkBeneficiaryLimits(ctx context.Context, req *models.TransferRequest, b *models.Beneficiary) error {
+	if b.TransferLimit > 0 && req.Amount > b.TransferLimit {
+		return &customerrors.TransferLimitExceededError{Limit: b.TransferLimit, Reason: "per transfer"}
+	}
+
+	now := time.Now()
+
+	if b.DailyLimit > 0 {
+		// the day starts at midnight of the business timezone
+		day := now.In(s.location())
+		sent, err := s.strg.TxRepo().GetTransferredTotal(ctx, &models.GetTransferredTotalRequest{
+			UserID:      req.UserID,
+			RecipientID: b.AccountID,
+			From:        time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location()).Format(config.DatabaseTimeLayout),
+		})
+		if err != nil {
+			s.log.Error("---Transfer->GetTransferredTotal--->", logger.Error(err))
+			return err
+		}
+		if sent+req.Amount > b.DailyLimit {
+			return &customerrors.TransferLimitExceededError{Limit: b.DailyLimit, Reason: "daily"}
+		}
+	}
+
+	if now.Before(beneficiary.CoolingOffUntil(s.cfg, b)) {
+		sent, err := s.strg.TxRepo().GetTransferredTotal(ctx, &models.GetTransferredTotalRequest{
+			UserID:      req.UserID,
+			RecipientID: b.AccountID,
+			From:        b.CreatedAt,
+		})
+		if err != nil {
+			s.log.Error("---Transfer->GetTransferredTotal--->", logger.Error(err))
+			return err
+		}
+		if sent+req.Amount > s.cfg.BeneficiaryCoolingOffLimit {
+			return &customerrors.TransferLimitExceededError{Limit: s.cfg.BeneficiaryCoolingOffLimit, Reason: "new beneficiary"}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,124 @@
+package payment
+
+import (
+	"context"
+	"time"
+
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/interest"
+	"github.com/dilmurodov/online_banking/pkg/logger"
+	"github.com/dilmurodov/online_banking/pkg/models"
+)
+
+// fraudCheck is a customer debit about to be made
+type fraudCheck struct {
+	UserID         string
+	AccountID      string
+	Operation      string
+	CounterpartyID string
+	Amount         float64
+	// ChallengeID is the challenge the customer confirmed for this payment, if any
+	ChallengeID string
+}
+
+// screen scores the debit before it is made. Decisions where a rule fired are stored for review.
+// A challenged payment goes through once its challenge was confirmed with a code, a blocked one never does.
+func (s *Service) screen(ctx context.Context, req *fraudCheck) error {
+	rules := s.fraud.Rules()
+	now := time.Now()
+
+	signals, err := s.strg.Fraud().GetFraudSignals(ctx, &models.GetFraudSignalsRequest{
+		UserID:         req.UserID,
+		AccountID:      req.AccountID,
+		CounterpartyID: req.CounterpartyID,
+		HourAgo:        now.Add(-time.Hour).Format(config.DatabaseTimeLayout),
+		Since:          now.AddDate(0, 0, -rules.AmountAverage.LookbackDays).Format(config.DatabaseTimeLayout),
+	})
+	if err != nil {
+		s.log.Error("---Screen->GetFraudSignals--->", logger.Error(err))
+		return err
+	}
+	signals.Operation = req.Operation
+	signals.Amount = req.Amount
+	signals.Hour = now.In(s.location()).Hour()
+
+	assessment := s.fraud.Assess(signals)
+	if len(assessment.Hits) == 0 {
+		return nil
+	}
+
+	amount, err := interest.ParseAmount(req.Amount)
+	if err != nil {
+		return err
+	}
+
+	if assessment.Outcome == models.FraudOutcomeChallenge && req.ChallengeID != "" {
+		err = s.strg.Fraud().UseFraudChallenge(ctx, &models.UseFraudChallengeRequest{
+			DecisionID:     req.ChallengeID,
+			UserID:         req.UserID,
+			AccountID:      req.AccountID,
+			CounterpartyID: req.CounterpartyID,
+			Amount:         interest.FormatDecimal(amount, interest.PostingScale),
+			CreatedAfter:   now.Add(-config.FraudChallengeTTL).Format(config.DatabaseTimeLayout),
+		})
+		if err != nil {
+			s.log.Error("---Screen->UseFraudChallenge--->", logger.Error(err))
+			return err
+		}
+		return nil
+	}
+
+	decision := &models.FraudDecision{
+		UserID:         req.UserID,
+		AccountID:      req.AccountID,
+		Operation:      req.Operation,
+		CounterpartyID: req.CounterpartyID,
+		Amount:         interest.FormatDecimal(amount, interest.PostingScale),
+		Score:          assessment.Score,
+		Outcome:        assessment.Outcome,
+		Hits:           assessment.Hits,
+	}
+	if assessment.Outcome == models.FraudOutcomeChallenge {
+		decision.ChallengeStatus = models.FraudChallengePending
+	}
+	decision, err = s.strg.Fraud().CreateFraudDecision(ctx, decision)
+	if err != nil {
+		s.log.Error("---Screen->CreateFraudDecision--->", logger.Error(err))
+		return err
+	}
+
+	s.log.Warn("---Screen--->", logger.String("decision_id", decision.ID), logger.String("outcome", decision.Outcome), logger.Int("score", decision.Score))
+
+	switch assessment.Outcome {
+	case models.FraudOutcomeChallenge:
+		return &customerrors.FraudChallengeRequiredError{DecisionID: decision.ID}
+	case models.FraudOutcomeBlock:
+		return &customerrors.FraudBlockedError{DecisionID: decision.ID}
+	}
+	return nil
+}
+
+// ReloadFraudRules reads the fraud rules file again, the rules in force stay when it is not valid
+func (s *Service) ReloadFraudRules() (models.FraudRules, error) {
+	rules, err := s.fraud.Reload()
+	if err != nil {
+		s.log.Error("---ReloadFraudRules--->", logger.Error(err))
+		return rules, err
+	}
+	s.log.Info("---ReloadFraudRules--->", logger.Any("rules", rules))
+	return rules, nil
+}
+
+func (s *Service) GetFraudRules() models.FraudRules {
+	return s.fraud.Rules()
+}
+
+// location is the business timezone the night-time rule and the daily limits are read in, UTC when unknown
+func (s *Service) location() *time.Location {
+	loc, err := time.LoadLocation(s.cfg.BusinessTimezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
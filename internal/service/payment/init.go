@@ -2,8 +2,10 @@ package payment
 
 import (
 	"context"
+	"database/sql"
 
 	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/pkg/fraud"
 	"github.com/dilmurodov/online_banking/pkg/logger"
 	"github.com/dilmurodov/online_banking/pkg/models"
 	"github.com/dilmurodov/online_banking/storage"
@@ -14,18 +16,34 @@ type ServiceI interface {
 	WithDrawal(ctx context.Context, req *models.WithDrawalRequest) (*models.WithDrawalResponse, error)
 	Transfer(ctx context.Context, req *models.TransferRequest) (*models.TransferResponse, error)
 	Deposit(ctx context.Context, req *models.DepositRequest) (*models.DepositResponse, error)
+	LookupPhoneRecipient(ctx context.Context, req *models.PhoneLookupRequest) (*models.PhoneLookupResponse, error)
+	TransferByPhone(ctx context.Context, req *models.PhoneTransferRequest) (*models.TransferResponse, error)
+	PostTransfer(ctx context.Context, tx *sql.Tx, req *models.PostTransferRequest) (*models.TransferResponse, error)
+	GetTransferApprovals(ctx context.Context, req *models.GetTransferApprovalsRequest, userID string) (*models.GetTransferApprovalsResponse, error)
+	ApproveTransfer(ctx context.Context, req *models.TransferApprovalByIDRequest) (*models.TransferApprovalResponse, error)
+	RejectTransfer(ctx context.Context, req *models.TransferApprovalByIDRequest) (*models.TransferApprovalResponse, error)
+	ReloadFraudRules() (models.FraudRules, error)
+	GetFraudRules() models.FraudRules
 }
 
 type Service struct {
-	cfg  config.Config
-	log  logger.LoggerI
-	strg storage.StorageI
+	cfg   config.Config
+	log   logger.LoggerI
+	strg  storage.StorageI
+	fraud *fraud.Engine
 }
 
+// NewService starts with the fraud rules from cfg.FraudRulesFile, the defaults when it can't be read
 func NewService(cfg config.Config, log logger.LoggerI, strg storage.StorageI) ServiceI {
+	engine := fraud.NewEngine(cfg.FraudRulesFile)
+	if _, err := engine.Reload(); err != nil {
+		log.Error("---NewService->FraudRules--->", logger.Error(err))
+	}
+
 	return &Service{
-		cfg:  cfg,
-		log:  log,
-		strg: strg,
+		cfg:   cfg,
+		log:   log,
+		strg:  strg,
+		fraud: engine,
 	}
 }
@@ -0,0 +1,47 @@
+package payment
+
+import (
+	"context"
+	"math"
+	"strconv"
+
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/kyc"
+	"github.com/dilmurodov/online_banking/pkg/logger"
+	"github.com/dilmurodov/online_banking/pkg/models"
+)
+
+// checkKYCLimits refuses a payment the KYC tier of the account's owner doesn't allow. A deposit is
+// capped by the deposit limit, a withdrawal by the withdrawal limit, and every credit by the balance
+// it would leave on the account, limit is models.KYCLimitBalance for a credit that is neither.
+func (s *Service) checkKYCLimits(ctx context.Context, account *models.Account, amount float64, limit string) error {
+	tier, err := s.strg.KYC().GetAccountKYCTier(ctx, account.ID)
+	if err != nil {
+		s.log.Error("---CheckKYCLimits->GetAccountKYCTier--->", logger.Error(err))
+		return err
+	}
+	limits := kyc.Limits(s.cfg, tier)
+
+	switch limit {
+	case models.KYCLimitWithdrawal:
+		return checkCap(account, tier, models.KYCLimitWithdrawal, limits.Withdrawal, amount)
+	case models.KYCLimitDeposit:
+		if err = checkCap(account, tier, models.KYCLimitDeposit, limits.Deposit, amount); err != nil {
+			return err
+		}
+	}
+	return checkCap(account, tier, models.KYCLimitBalance, limits.Balance, account.Balance+amount)
+}
+
+// checkCap compares in cents like checkFunds, a zero cap is no cap
+func checkCap(account *models.Account, tier, limit string, capped, value float64) error {
+	if capped <= 0 || math.Round(value*100) <= math.Round(capped*100) {
+		return nil
+	}
+	return &customerrors.KYCLimitExceededError{
+		AccountID: account.ID,
+		Tier:      tier,
+		Limit:     limit,
+		Cap:       strconv.FormatFloat(capped, 'f', 2, 64),
+	}
+}
@@ -0,0 +1,101 @@
+package payment
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/pkg/logger"
+	"github.com/dilmurodov/online_banking/pkg/models"
+)
+
+// PostTransfer books a settled transfer inside the caller's database transaction.
+// Unlike Transfer it does not wait for capture: it is meant for postings the bank books itself,
+// such as interest, or on a customer's request, where the caller commits the transfer together with its own records.
+func (s *Service) PostTransfer(ctx context.Context, tx *sql.Tx, req *models.PostTransferRequest) (resp *models.TransferResponse, err error) {
+	s.log.Info("---PostTransfer--->", logger.Any("req", req))
+	resp = &models.TransferResponse{}
+
+	if err = validateRemittance(req.Description, req.Reference); err != nil {
+		return nil, err
+	}
+
+	amount, err := strconv.ParseFloat(req.Amount, 64)
+	if err != nil || amount <= 0 {
+		return nil, fmt.Errorf("invalid posting amount %q", req.Amount)
+	}
+
+	if req.CustomerInitiated {
+		if err = s.checkSanctions(ctx, req.FromAccountID, req.ToAccountID); err != nil {
+			return nil, err
+		}
+
+		// the credited account keeps to the balance cap of its owner's KYC tier, the bank's ledger accounts have none
+		toAccount, err := s.strg.Account().GetAccountByID(ctx, &models.GetAccountByIDRequest{ID: req.ToAccountID})
+		if err != nil {
+			s.log.Error("---PostTransfer->GetAccountByID--->", logger.Error(err))
+			return nil, err
+		}
+		if toAccount.UserID != config.SystemUserID {
+			if err = s.checkKYCLimits(ctx, toAccount, amount, models.KYCLimitBalance); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	legs := []struct {
+		accountID   string
+		recipientID string
+		txType      string
+		delta       string
+	}{
+		{req.FromAccountID, req.ToAccountID, "debit", "-" + req.Amount},
+		{req.ToAccountID, req.FromAccountID, "credit", req.Amount},
+	}
+
+	for _, leg := range legs {
+		if err = s.checkStatus(ctx, tx, leg.accountID, leg.txType == "debit"); err != nil {
+			return nil, err
+		}
+
+		created, err := s.strg.TxRepo().CreateTransaction(ctx, tx, &models.Transaction{
+			AccountID:   leg.accountID,
+			Amount:      amount,
+			Type:        leg.txType,
+			RecipientID: leg.recipientID,
+			Description: req.Description,
+			Reference:   req.Reference,
+		})
+		if err != nil {
+			s.log.Error("---PostTransfer->CreateTransaction--->", logger.Error(err))
+			return nil, err
+		}
+
+		err = s.strg.TxRepo().ApproveTransactions(ctx, tx, &models.ApproveTransactionsRequest{
+			AccountID:      leg.accountID,
+			TransactionIDS: []string{created.ID},
+		})
+		if err != nil {
+			s.log.Error("---PostTransfer->ApproveTransactions--->", logger.Error(err))
+			return nil, err
+		}
+
+		err = s.strg.Account().AdjustAccountBalance(ctx, tx, &models.AdjustAccountBalanceRequest{
+			AccountID: leg.accountID,
+			Amount:    leg.delta,
+		})
+		if err != nil {
+			s.log.Error("---PostTransfer->AdjustAccountBalance--->", logger.Error(err))
+			return nil, err
+		}
+
+		created.AccountID = leg.accountID
+		created.Approved = true
+		created.Done = true
+		resp.Transactions = append(resp.Transactions, created)
+	}
+
+	return resp, nil
+}
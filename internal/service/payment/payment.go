@@ -2,26 +2,131 @@ package payment
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
-	"log"
+	"math"
+	"strconv"
 
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/interest"
 	"github.com/dilmurodov/online_banking/pkg/logger"
 	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/dilmurodov/online_banking/pkg/util"
 )
 
 // Transfer transfers the specified amount from one account to another
 func (s *Service) Transfer(ctx context.Context, req *models.TransferRequest) (resp *models.TransferResponse, err error) {
 	s.log.Info("---Transfer--->", logger.Any("req", req))
-	resp = &models.TransferResponse{}
 
-	// Begin a database transaction for the transfer
-	tx, err := s.strg.TxRepo().BeginTx(ctx)
+	if err = validateAmount(req.Amount); err != nil {
+		return nil, err
+	}
+	if err = validateRemittance(req.Description, req.Reference); err != nil {
+		return nil, err
+	}
+
+	// Resolve the destination from the address book when a beneficiary is given
+	if req.BeneficiaryID != "" {
+		if err = s.applyBeneficiary(ctx, req); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = s.checkSanctions(ctx, req.FromAccountID, req.ToAccountID); err != nil {
+		return nil, err
+	}
+
+	// A transfer made by a holder follows their role, is screened for fraud and follows the account's dual approval
+	if req.UserID != "" {
+		policy, err := s.checkHolder(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		if req.BeneficiaryID == "" {
+			if err = s.checkRecipientBeneficiary(ctx, req); err != nil {
+				return nil, err
+			}
+		}
+
+		err = s.screen(ctx, &fraudCheck{
+			UserID:         req.UserID,
+			AccountID:      req.FromAccountID,
+			Operation:      models.FraudOperationTransfer,
+			CounterpartyID: req.ToAccountID,
+			Amount:         req.Amount,
+			ChallengeID:    req.ChallengeID,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		approval, err := s.requestApproval(ctx, req, policy)
+		if err != nil {
+			return nil, err
+		}
+		if approval != nil {
+			return &models.TransferResponse{Approval: approval}, nil
+		}
+	}
+
+	// Begin a database transaction for the transfer, a holder's transfer is audited
+	// and waits its turn on the audit chain, the ones the system makes aren't
+	begin := s.strg.TxRepo().BeginTx
+	if req.UserID != "" {
+		begin = s.strg.Audit().BeginTx
+	}
+	tx, err := begin(ctx)
 	if err != nil {
 		s.log.Error("failed to begin transaction", logger.Error(err))
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback()
 
+	resp, err = s.transfer(ctx, tx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.UserID != "" {
+		transactionIDs := make([]string, 0, len(resp.Transactions))
+		for _, t := range resp.Transactions {
+			transactionIDs = append(transactionIDs, t.ID)
+		}
+
+		err = s.strg.Audit().CreateAuditEvent(ctx, tx, &models.AuditEvent{
+			EventType:    models.AuditEventTransferMade,
+			ActorID:      req.UserID,
+			UserID:       req.UserID,
+			ResourceType: models.AuditResourceAccount,
+			ResourceID:   req.FromAccountID,
+			Details: map[string]interface{}{
+				"to_account_id": req.ToAccountID,
+				"amount":        req.Amount,
+				"reference":     req.Reference,
+			},
+			After: map[string]interface{}{"transaction_ids": transactionIDs},
+		})
+		if err != nil {
+			s.log.Error("---Transfer->CreateAuditEvent--->", logger.Error(err))
+			return nil, err
+		}
+	}
+
+	// Commit the transaction
+	err = tx.Commit()
+	if err != nil {
+		s.log.Error("failed to commit transaction", logger.Error(err))
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return resp, nil
+}
+
+// transfer creates the pending debit and credit of a transfer in the transaction
+func (s *Service) transfer(ctx context.Context, tx *sql.Tx, req *models.TransferRequest) (resp *models.TransferResponse, err error) {
+	resp = &models.TransferResponse{}
+
 	// Get the account objects from the account repository
 	fromAccount, err := s.strg.Account().GetAccountByID(ctx, &models.GetAccountByIDRequest{
 		ID: req.FromAccountID,
@@ -39,10 +144,26 @@ func (s *Service) Transfer(ctx context.Context, req *models.TransferRequest) (re
 		return nil, fmt.Errorf("failed to get to account: %w", err)
 	}
 
-	// Ensure that the from account has enough funds to transfer
-	if fromAccount.Balance < req.Amount {
+	if err = checkPayable(fromAccount); err != nil {
+		return nil, err
+	}
+	if err = checkPayable(toAccount); err != nil {
+		return nil, err
+	}
+	if err = s.checkStatus(ctx, tx, fromAccount.ID, true); err != nil {
+		return nil, err
+	}
+	if err = s.checkStatus(ctx, tx, toAccount.ID, false); err != nil {
+		return nil, err
+	}
+
+	// Ensure that the from account has enough funds to transfer, the overdraft included
+	if err = checkFunds(fromAccount, req.Amount); err != nil {
 		s.log.Error("insufficient funds in from account", logger.Error(err))
-		return nil, fmt.Errorf("insufficient funds in from account")
+		return nil, err
+	}
+	if err = s.checkKYCLimits(ctx, toAccount, req.Amount, models.KYCLimitBalance); err != nil {
+		return nil, err
 	}
 
 	// Debit the amount from the from account and credit it to the to account
@@ -55,6 +176,8 @@ func (s *Service) Transfer(ctx context.Context, req *models.TransferRequest) (re
 		Amount:      req.Amount,
 		Type:        "debit",
 		RecipientID: toAccount.ID,
+		Description: req.Description,
+		Reference:   req.Reference,
 	}
 
 	creditTx := &models.Transaction{
@@ -62,6 +185,8 @@ func (s *Service) Transfer(ctx context.Context, req *models.TransferRequest) (re
 		Amount:      req.Amount,
 		Type:        "credit",
 		RecipientID: fromAccount.ID,
+		Description: req.Description,
+		Reference:   req.Reference,
 	}
 
 	// Save the debit and credit transactions to the database
@@ -79,13 +204,6 @@ func (s *Service) Transfer(ctx context.Context, req *models.TransferRequest) (re
 	}
 	resp.Transactions = append(resp.Transactions, createTx2)
 
-	// Commit the transaction
-	err = tx.Commit()
-	if err != nil {
-		s.log.Error("failed to commit transaction", logger.Error(err))
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
 	return resp, nil
 }
 
@@ -93,6 +211,32 @@ func (s *Service) Transfer(ctx context.Context, req *models.TransferRequest) (re
 func (s *Service) WithDrawal(ctx context.Context, req *models.WithDrawalRequest) (resp *models.WithDrawalResponse, err error) {
 	resp = &models.WithDrawalResponse{}
 	s.log.Info("---WithDrawal---", logger.Any("req", req))
+
+	if err = validateAmount(req.Amount); err != nil {
+		return nil, err
+	}
+	if err = validateRemittance(req.Description, req.Reference); err != nil {
+		return nil, err
+	}
+
+	if err = s.checkSanctions(ctx, req.AccountID); err != nil {
+		return nil, err
+	}
+
+	// A withdrawal made by a customer is screened for fraud
+	if req.UserID != "" {
+		err = s.screen(ctx, &fraudCheck{
+			UserID:      req.UserID,
+			AccountID:   req.AccountID,
+			Operation:   models.FraudOperationWithdrawal,
+			Amount:      req.Amount,
+			ChallengeID: req.ChallengeID,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Begin a database transaction for the transfer
 	tx, err := s.strg.TxRepo().BeginTx(ctx)
 	if err != nil {
@@ -110,12 +254,28 @@ func (s *Service) WithDrawal(ctx context.Context, req *models.WithDrawalRequest)
 		return nil, fmt.Errorf("failed to get from account: %w", err)
 	}
 
+	if err = checkPayable(account); err != nil {
+		return nil, err
+	}
+	if err = s.checkStatus(ctx, tx, account.ID, true); err != nil {
+		return nil, err
+	}
+	if err = checkFunds(account, req.Amount); err != nil {
+		s.log.Error("insufficient funds in account", logger.Any("err", err))
+		return nil, err
+	}
+	if err = s.checkKYCLimits(ctx, account, req.Amount, models.KYCLimitWithdrawal); err != nil {
+		return nil, err
+	}
+
 	// Create credit transactions for the transfer
 	debitTx := &models.Transaction{
 		AccountID:   account.ID,
 		Amount:      req.Amount,
 		Type:        "debit",
 		RecipientID: account.ID,
+		Description: req.Description,
+		Reference:   req.Reference,
 	}
 
 	// Save the debit and credit transactions to the database
@@ -143,10 +303,7 @@ func (s *Service) CaptureTransactions(ctx context.Context, req *models.CaptureTr
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
 
-	var (
-		accnt *models.Account
-		cntr  int
-	)
+	var cntr int
 
 	// Get transactions
 	transactions, err := s.strg.TxRepo().GetTransactionsByIDS(ctx, &models.GetTransactionsByIDSRequest{
@@ -159,46 +316,38 @@ func (s *Service) CaptureTransactions(ctx context.Context, req *models.CaptureTr
 	}
 
 	for _, v := range transactions.Transactions {
-		if err != nil {
-			_ = tx.Rollback()
-			s.log.Error("failed to get from account", logger.Error(err))
-			return fmt.Errorf("failed to get from account: %w", err)
+		if v.Type != "credit" && v.Type != "debit" {
+			continue
 		}
+		cntr++
 
-		if v.Type == "credit" {
-			cntr++
-			log.Println("credit---->", v.Amount)
-			accnt, err = s.strg.Account().GetAccountByID(ctx, &models.GetAccountByIDRequest{
-				ID: v.AccountID,
-			})
-			if err != nil {
-				_ = tx.Rollback()
-				s.log.Error("failed to get from account", logger.Error(err))
-				return fmt.Errorf("failed to get from account: %w", err)
-			}
-			accnt.Balance += v.Amount
+		debit := v.Type == "debit"
+		if err = s.checkStatus(ctx, tx, v.AccountID, debit); err != nil {
+			_ = tx.Rollback()
+			return err
 		}
 
-		if v.Type == "debit" {
-			cntr++
-			log.Println("debit---->", v.Amount)
-			accnt, err = s.strg.Account().GetAccountByID(ctx, &models.GetAccountByIDRequest{
-				ID: v.AccountID,
-			})
-			if err != nil {
-				_ = tx.Rollback()
-				s.log.Error("failed to get from account", logger.Error(err))
-				return fmt.Errorf("failed to get from account: %w", err)
-			}
-			accnt.Balance -= v.Amount
+		// The balance moves by the amount relative to the stored one, a debit beyond the overdraft limit is refused
+		delta := strconv.FormatFloat(v.Amount, 'f', -1, 64)
+		if debit {
+			delta = "-" + delta
 		}
-
-		// Update the account balances in the database
-		err = s.strg.Account().UpdateAccountBalance(ctx, tx, accnt)
+		err = s.strg.Account().AdjustAccountBalance(ctx, tx, &models.AdjustAccountBalanceRequest{
+			AccountID: v.AccountID,
+			Amount:    delta,
+		})
 		if err != nil {
 			_ = tx.Rollback()
-			s.log.Error("failed to update from account", logger.Error(err))
-			return fmt.Errorf("failed to update from account: %w", err)
+			s.log.Error("---CaptureTransactions->AdjustAccountBalance--->", logger.Error(err))
+			return err
+		}
+
+		if debit {
+			if err = s.roundUp(ctx, tx, v.AccountID, v.Amount); err != nil {
+				_ = tx.Rollback()
+				s.log.Error("failed to round up", logger.Error(err))
+				return fmt.Errorf("failed to round up: %w", err)
+			}
 		}
 	}
 
@@ -233,6 +382,16 @@ func (s *Service) CaptureTransactions(ctx context.Context, req *models.CaptureTr
 func (s *Service) Deposit(ctx context.Context, req *models.DepositRequest) (resp *models.DepositResponse, err error) {
 	s.log.Info("---Deposit--->", logger.Any("req", req))
 	resp = &models.DepositResponse{}
+
+	if err = validateAmount(req.Amount); err != nil {
+		return nil, err
+	}
+	if err = validateRemittance(req.Description, req.Reference); err != nil {
+		return nil, err
+	}
+	if err = s.checkSanctions(ctx, req.AccountID); err != nil {
+		return nil, err
+	}
 	// Begin a database transaction for the transfer
 	tx, err := s.strg.TxRepo().BeginTx(ctx)
 	if err != nil {
@@ -250,12 +409,24 @@ func (s *Service) Deposit(ctx context.Context, req *models.DepositRequest) (resp
 		return nil, fmt.Errorf("failed to get from account: %w", err)
 	}
 
+	if err = checkPayable(account); err != nil {
+		return nil, err
+	}
+	if err = s.checkStatus(ctx, tx, account.ID, false); err != nil {
+		return nil, err
+	}
+	if err = s.checkKYCLimits(ctx, account, req.Amount, models.KYCLimitDeposit); err != nil {
+		return nil, err
+	}
+
 	// Create credit transactions for the transfer
 	creditTx := &models.Transaction{
 		AccountID:   account.ID,
 		Amount:      req.Amount,
 		Type:        "credit",
 		RecipientID: account.ID,
+		Description: req.Description,
+		Reference:   req.Reference,
 	}
 
 	// Save the debit and credit transactions to the database
@@ -275,3 +446,58 @@ func (s *Service) Deposit(ctx context.Context, req *models.DepositRequest) (resp
 
 	return resp, nil
 }
+
+// validateAmount rejects amounts that aren't positive whole cents, the ledger can't post a fraction of a cent
+func validateAmount(amount float64) error {
+	parsed, err := interest.ParseAmount(amount)
+	if err != nil || parsed.Sign() <= 0 {
+		return &customerrors.InvalidAmountError{Amount: fmt.Sprint(amount)}
+	}
+	return nil
+}
+
+// validateRemittance checks the optional description and external reference of a payment
+func validateRemittance(description, reference string) error {
+	if !util.IsValidDescription(description) {
+		return &customerrors.InvalidRemittanceError{Field: "description"}
+	}
+	if !util.IsValidReference(reference) {
+		return &customerrors.InvalidRemittanceError{Field: "reference"}
+	}
+	return nil
+}
+
+// checkPayable rejects customer payments on accounts only the bank moves money on,
+// such as the account holding a term deposit's locked principal or a pot's balance
+func checkPayable(account *models.Account) error {
+	if account.Product == models.ProductTermDeposit || account.Product == models.ProductPot {
+		return &customerrors.AccountLockedError{Guid: account.ID}
+	}
+	return nil
+}
+
+// checkStatus reads the account's status inside the transaction, so a freeze or a close can't race the payment.
+// A frozen account takes credits only, a closed one takes nothing.
+func (s *Service) checkStatus(ctx context.Context, tx *sql.Tx, accountID string, debit bool) error {
+	status, err := s.strg.Account().GetAccountStatus(ctx, tx, accountID)
+	if err != nil {
+		return err
+	}
+	if status == models.AccountStatusActive || (status == models.AccountStatusFrozen && !debit) {
+		return nil
+	}
+	return &customerrors.AccountStatusError{Guid: accountID, Status: status}
+}
+
+// checkFunds rejects a debit the balance and the overdraft limit together can't cover.
+// Amounts are compared in cents so float noise can't turn an exact match into a shortfall.
+func checkFunds(account *models.Account, amount float64) error {
+	shortfall := math.Round(amount*100) - math.Round((account.Balance+account.OverdraftLimit)*100)
+	if shortfall > 0 {
+		return &customerrors.InsufficientFundsError{
+			AccountID: account.ID,
+			Shortfall: strconv.FormatFloat(shortfall/100, 'f', 2, 64),
+		}
+	}
+	return nil
+}
@@ -3,10 +3,14 @@ package payment
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/internal/service/servicetest"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
 	"github.com/dilmurodov/online_banking/pkg/models"
 	mock_storage "github.com/dilmurodov/online_banking/storage/mock"
 	"github.com/dilmurodov/online_banking/storage/postgres"
@@ -34,22 +38,27 @@ func TestPayment_Transfer(t *testing.T) {
 	repo := mock_storage.NewMockAccountRepoI(ctrl)
 	repoTx := mock_storage.NewMockTxRepoI(ctrl)
 
+	expectSanctionsClear(mock, "TestAccountID1", "TestAccountID2")
 	mock.ExpectBegin()
-	row1 := sqlmock.NewRows([]string{"guid", "user_id", "balance", "created_at", "updated_at"}).AddRow("TestAccountID1", "TestUserID", 200, "2021-01-01", "2021-01-01")
+	row1 := sqlmock.NewRows([]string{"guid", "user_id", "balance", "product", "accrued_interest", "overdraft_limit", "created_at", "updated_at", "status", "closed_at"}).AddRow("TestAccountID1", "TestUserID", 200, "current", "0", 0.0, "2021-01-01", "2021-01-01", "active", nil)
 
-	row2 := sqlmock.NewRows([]string{"guid", "user_id", "balance", "created_at", "updated_at"}).AddRow("TestAccountID2", "TestUserID", 200, "2021-01-01", "2021-01-01")
+	row2 := sqlmock.NewRows([]string{"guid", "user_id", "balance", "product", "accrued_interest", "overdraft_limit", "created_at", "updated_at", "status", "closed_at"}).AddRow("TestAccountID2", "TestUserID", 200, "current", "0", 0.0, "2021-01-01", "2021-01-01", "active", nil)
 
-	txrow1 := sqlmock.NewRows([]string{"guid", "transaction_amount", "recipient_id", "transaction_type", "created_at"}).AddRow("TestTransactionID", 100.0, "TestAccountID2", "debit", "2021-01-01")
+	txrow1 := sqlmock.NewRows([]string{"guid", "transaction_amount", "recipient_id", "transaction_type", "description", "reference", "created_at"}).AddRow("TestTransactionID", 100.0, "TestAccountID2", "debit", "Rent for May", "INV-2021-05", "2021-01-01")
 
-	txrow2 := sqlmock.NewRows([]string{"guid", "transaction_amount", "recipient_id", "transaction_type", "created_at"}).AddRow("TestTransactionID2", 100.0, "TestAccountID2", "debit", "2021-01-01")
+	txrow2 := sqlmock.NewRows([]string{"guid", "transaction_amount", "recipient_id", "transaction_type", "description", "reference", "created_at"}).AddRow("TestTransactionID2", 100.0, "TestAccountID2", "debit", "Rent for May", "INV-2021-05", "2021-01-01")
 
 	mock.ExpectQuery(`^SELECT (.+?) FROM accounts * `).WithArgs("TestAccountID1").WillReturnRows(row1)
 
 	mock.ExpectQuery(`^SELECT (.+?) FROM accounts * `).WithArgs("TestAccountID2").WillReturnRows(row2)
+	mock.ExpectQuery(`^SELECT status FROM accounts`).WithArgs("TestAccountID1").WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("active"))
+	mock.ExpectQuery(`^SELECT status FROM accounts`).WithArgs("TestAccountID2").WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("active"))
+	expectKYCTier(mock, "TestAccountID2", models.KYCTierFull)
 
-	mock.ExpectPrepare("INSERT INTO transactions").ExpectQuery().WithArgs("TestAccountID1", 100.0, "TestAccountID2", "debit").WillReturnRows(txrow1)
+	// both legs carry the remittance information
+	mock.ExpectPrepare("INSERT INTO transactions").ExpectQuery().WithArgs("TestAccountID1", 100.0, "TestAccountID2", "debit", "Rent for May", "INV-2021-05").WillReturnRows(txrow1)
 
-	mock.ExpectPrepare("INSERT INTO transactions").ExpectQuery().WithArgs("TestAccountID2", 100.0, "TestAccountID1", "credit").WillReturnRows(txrow2)
+	mock.ExpectPrepare("INSERT INTO transactions").ExpectQuery().WithArgs("TestAccountID2", 100.0, "TestAccountID1", "credit", "Rent for May", "INV-2021-05").WillReturnRows(txrow2)
 	mock.ExpectCommit()
 
 	t.Run("SUCCESS", func(t *testing.T) {
@@ -88,6 +97,8 @@ func TestPayment_Transfer(t *testing.T) {
 			FromAccountID: "TestAccountID1",
 			ToAccountID:   "TestAccountID2",
 			Amount:        100.0,
+			Description:   "Rent for May",
+			Reference:     "INV-2021-05",
 		}
 
 		repoTx.EXPECT().BeginTx(ctx).Return(tx, nil).Times(1).AnyTimes()
@@ -128,14 +139,17 @@ func TestPayment_WithDrawal(t *testing.T) {
 	repo := mock_storage.NewMockAccountRepoI(ctrl)
 	repoTx := mock_storage.NewMockTxRepoI(ctrl)
 
+	expectSanctionsClear(mock, "TestAccountID1")
 	mock.ExpectBegin()
-	row1 := sqlmock.NewRows([]string{"guid", "user_id", "balance", "created_at", "updated_at"}).AddRow("TestAccountID1", "TestUserID", 200, "2021-01-01", "2021-01-01")
+	row1 := sqlmock.NewRows([]string{"guid", "user_id", "balance", "product", "accrued_interest", "overdraft_limit", "created_at", "updated_at", "status", "closed_at"}).AddRow("TestAccountID1", "TestUserID", 200, "current", "0", 0.0, "2021-01-01", "2021-01-01", "active", nil)
 
-	txrow := sqlmock.NewRows([]string{"guid", "transaction_amount", "transaction_type", "recipient_id", "created_at"}).AddRow("TestTransactionID", 100.0, "debit", "TestAccountID1", "2021-01-01")
+	txrow := sqlmock.NewRows([]string{"guid", "transaction_amount", "transaction_type", "recipient_id", "description", "reference", "created_at"}).AddRow("TestTransactionID", 100.0, "debit", "TestAccountID1", "", "", "2021-01-01")
 
 	mock.ExpectQuery(`^SELECT (.+?) FROM accounts * `).WithArgs("TestAccountID1").WillReturnRows(row1)
+	mock.ExpectQuery(`^SELECT status FROM accounts`).WithArgs("TestAccountID1").WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("active"))
+	expectKYCTier(mock, "TestAccountID1", models.KYCTierFull)
 
-	mock.ExpectPrepare("INSERT INTO transactions").ExpectQuery().WithArgs("TestAccountID1", 100.0, "TestAccountID1", "debit").WillReturnRows(txrow)
+	mock.ExpectPrepare("INSERT INTO transactions").ExpectQuery().WithArgs("TestAccountID1", 100.0, "TestAccountID1", "debit", "", "").WillReturnRows(txrow)
 
 	mock.ExpectCommit()
 
@@ -195,14 +209,17 @@ func TestPayment_Deposit(t *testing.T) {
 	repo := mock_storage.NewMockAccountRepoI(ctrl)
 	repoTx := mock_storage.NewMockTxRepoI(ctrl)
 
+	expectSanctionsClear(mock, "TestAccountID1")
 	mock.ExpectBegin()
-	row1 := sqlmock.NewRows([]string{"guid", "user_id", "balance", "created_at", "updated_at"}).AddRow("TestAccountID1", "TestUserID", 200, "2021-01-01", "2021-01-01")
+	row1 := sqlmock.NewRows([]string{"guid", "user_id", "balance", "product", "accrued_interest", "overdraft_limit", "created_at", "updated_at", "status", "closed_at"}).AddRow("TestAccountID1", "TestUserID", 200, "current", "0", 0.0, "2021-01-01", "2021-01-01", "active", nil)
 
-	txrow := sqlmock.NewRows([]string{"guid", "transaction_amount", "transaction_type", "recipient_id", "created_at"}).AddRow("TestTransactionID", 100.0, "credit", "TestAccountID1", "2021-01-01")
+	txrow := sqlmock.NewRows([]string{"guid", "transaction_amount", "transaction_type", "recipient_id", "description", "reference", "created_at"}).AddRow("TestTransactionID", 100.0, "credit", "TestAccountID1", "", "", "2021-01-01")
 
 	mock.ExpectQuery(`^SELECT (.+?) FROM accounts * `).WithArgs("TestAccountID1").WillReturnRows(row1)
+	mock.ExpectQuery(`^SELECT status FROM accounts`).WithArgs("TestAccountID1").WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("active"))
+	expectKYCTier(mock, "TestAccountID1", models.KYCTierFull)
 
-	mock.ExpectPrepare("INSERT INTO transactions").ExpectQuery().WithArgs("TestAccountID1", 100.0, "TestAccountID1", "credit").WillReturnRows(txrow)
+	mock.ExpectPrepare("INSERT INTO transactions").ExpectQuery().WithArgs("TestAccountID1", 100.0, "TestAccountID1", "credit", "", "").WillReturnRows(txrow)
 
 	mock.ExpectCommit()
 
@@ -262,15 +279,17 @@ func TestPayment_CaptureTransactions(t *testing.T) {
 	repoTx := mock_storage.NewMockTxRepoI(ctrl)
 
 	mock.ExpectBegin()
-	row1 := sqlmock.NewRows([]string{"guid", "user_id", "balance", "created_at", "updated_at"}).AddRow("TestAccountID1", "TestUserID", 200, "2021-01-01", "2021-01-01")
 
-	txrow := sqlmock.NewRows([]string{"guid", "account_id", "transaction_amount", "transaction_type", "recipient_id", "created_at", "approved", "done", "done_timestampe"}).AddRow("TestTransactionID", "TestAccountID1", 100.0, "debit", "TestAccountID1", "2021-01-01", true, true, "2021-01-01")
+	txrow := sqlmock.NewRows([]string{"guid", "account_id", "transaction_amount", "transaction_type", "recipient_id", "description", "reference", "created_at", "approved", "done", "done_timestampe"}).AddRow("TestTransactionID", "TestAccountID1", 100.0, "debit", "TestAccountID1", "", "", "2021-01-01", true, true, "2021-01-01")
 
 	mock.ExpectQuery(`^SELECT (.+?) FROM transactions * `).WithArgs(pq.Array([]string{"TestTransactionID"})).WillReturnRows(txrow)
 
-	mock.ExpectQuery(`^SELECT (.+?) FROM accounts * `).WithArgs("TestAccountID1").WillReturnRows(row1)
+	mock.ExpectQuery(`^SELECT status FROM accounts`).WithArgs("TestAccountID1").WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("active"))
+
+	mock.ExpectExec(`^UPDATE accounts SET balance = balance`).WithArgs("-100", "TestAccountID1").WillReturnResult(sqlmock.NewResult(0, 1))
 
-	mock.ExpectPrepare(`UPDATE accounts`).ExpectExec().WithArgs(100.0, "TestAccountID1").WillReturnResult(sqlmock.NewResult(1, 1))
+	// the account has no round-up pot
+	mock.ExpectQuery(`^SELECT (.+?) FROM pots p`).WithArgs("TestAccountID1").WillReturnRows(sqlmock.NewRows([]string{"guid"}))
 
 	mock.ExpectExec(`^UPDATE transactions
 	SET (.+?) WHERE * `).WithArgs(pq.Array([]string{"TestTransactionID"}), "TestAccountID1").WillReturnResult(sqlmock.NewResult(1, 1))
@@ -326,9 +345,9 @@ func TestPayment_CaptureTransactions(t *testing.T) {
 			ID: "TestAccountID1",
 		}).Return(acc1, nil).Times(1).AnyTimes()
 
-		repo.EXPECT().UpdateAccountBalance(ctx, tx, &models.Account{
-			ID:      "TestAccountID1",
-			Balance: 100.0,
+		repo.EXPECT().AdjustAccountBalance(ctx, tx, &models.AdjustAccountBalanceRequest{
+			AccountID: "TestAccountID1",
+			Amount:    "-100",
 		}).Return(nil).Times(1).AnyTimes()
 
 		repoTx.EXPECT().ApproveTransactions(ctx, tx, appTxs).Return(nil).Times(1).AnyTimes()
@@ -338,3 +357,804 @@ func TestPayment_CaptureTransactions(t *testing.T) {
 		r.NoError(mock.ExpectationsWereMet())
 	})
 }
+
+func TestPayment_LookupPhoneRecipient(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db, mock, err := sqlmock.New()
+	r.NoError(err)
+
+	s := NewService(
+		config.Config{},
+		zap.NewNop(),
+		postgres.NewStore(db),
+	)
+
+	repo := mock_storage.NewMockUserRepoI(ctrl)
+
+	row := sqlmock.NewRows([]string{"guid", "first_name", "last_name", "guid"}).AddRow("TestUserID2", "John", "Doe", "TestAccountID2")
+
+	mock.ExpectQuery(`^SELECT (.+?) FROM "users" * `).WithArgs("+998901234567", pq.Array([]string{"owner", "co_owner", "signer"})).WillReturnRows(row)
+
+	t.Run("SUCCESS", func(t *testing.T) {
+
+		ctx := context.Background()
+
+		repo.EXPECT().GetPhoneRecipient(ctx, "+998901234567").Return(&models.PhoneRecipient{
+			UserID:    "TestUserID2",
+			FirstName: "John",
+			LastName:  "Doe",
+			AccountID: "TestAccountID2",
+		}, nil).Times(1).AnyTimes()
+
+		resp, err := s.LookupPhoneRecipient(ctx, &models.PhoneLookupRequest{
+			UserID: "TestUserID1",
+			Phone:  "+998901234567",
+		})
+		r.NoError(err)
+		r.Equal("J*** D.", resp.MaskedName)
+		r.NotEmpty(resp.ConfirmationToken)
+		r.NoError(mock.ExpectationsWereMet())
+
+		// the token is bound to the user who made the lookup
+		_, err = s.TransferByPhone(ctx, &models.PhoneTransferRequest{
+			UserID:            "TestUserID3",
+			FromAccountID:     "TestAccountID3",
+			ConfirmationToken: resp.ConfirmationToken,
+			Amount:            100.0,
+		})
+		r.IsType(&customerrors.InvalidConfirmationTokenError{}, err)
+	})
+
+	t.Run("INVALID_PHONE", func(t *testing.T) {
+
+		_, err := s.LookupPhoneRecipient(context.Background(), &models.PhoneLookupRequest{
+			UserID: "TestUserID1",
+			Phone:  "12345",
+		})
+		r.Error(err)
+	})
+}
+
+func TestPayment_TransferToNewBeneficiary(t *testing.T) {
+	r := require.New(t)
+
+	db, mock, err := sqlmock.New()
+	r.NoError(err)
+
+	s := NewService(
+		config.Config{
+			BeneficiaryCoolingOffHours: 24,
+			BeneficiaryCoolingOffLimit: 500,
+		},
+		zap.NewNop(),
+		postgres.NewStore(db),
+	)
+
+	createdAt := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+
+	row := sqlmock.NewRows([]string{"guid", "user_id", "nickname", "account_id", "transfer_limit", "daily_limit", "created_at", "updated_at"}).AddRow("TestBeneficiaryID", "TestUserID", "Mom", "TestAccountID2", 0.0, 0.0, createdAt, createdAt)
+	total := sqlmock.NewRows([]string{"sum"}).AddRow(400.0)
+
+	mock.ExpectQuery(`^SELECT (.+?) FROM beneficiaries * `).WithArgs("TestBeneficiaryID", "TestUserID").WillReturnRows(row)
+	mock.ExpectQuery(`^SELECT (.+?) FROM transactions * `).WithArgs("TestUserID", "TestAccountID2", createdAt).WillReturnRows(total)
+
+	t.Run("COOLING_OFF_CAP", func(t *testing.T) {
+
+		_, err := s.Transfer(context.Background(), &models.TransferRequest{
+			UserID:        "TestUserID",
+			FromAccountID: "TestAccountID1",
+			BeneficiaryID: "TestBeneficiaryID",
+			Amount:        200.0,
+		})
+		r.IsType(&customerrors.TransferLimitExceededError{}, err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	// paying the beneficiary's account directly is capped the same
+	expectSanctionsClear(mock, "TestAccountID1", "TestAccountID2")
+	mock.ExpectQuery(`^SELECT (.+?) FROM accounts a`).WithArgs("TestAccountID1", "TestUserID").
+		WillReturnRows(sqlmock.NewRows([]string{"role", "dual_approval_threshold", "count"}).AddRow("owner", "0.00", 0))
+	mock.ExpectQuery(`^SELECT (.+?) FROM beneficiaries * `).WithArgs("TestUserID", "TestAccountID2").
+		WillReturnRows(sqlmock.NewRows([]string{"guid", "user_id", "nickname", "account_id", "transfer_limit", "daily_limit", "created_at", "updated_at"}).
+			AddRow("TestBeneficiaryID", "TestUserID", "Mom", "TestAccountID2", 0.0, 0.0, createdAt, createdAt))
+	mock.ExpectQuery(`^SELECT (.+?) FROM transactions * `).WithArgs("TestUserID", "TestAccountID2", createdAt).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(400.0))
+
+	t.Run("COOLING_OFF_CAP_BY_ACCOUNT", func(t *testing.T) {
+
+		_, err := s.Transfer(context.Background(), &models.TransferRequest{
+			UserID:        "TestUserID",
+			FromAccountID: "TestAccountID1",
+			ToAccountID:   "TestAccountID2",
+			Amount:        200.0,
+		})
+		r.Equal(&customerrors.TransferLimitExceededError{Limit: 500, Reason: "new beneficiary"}, err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+}
+
+func TestPayment_TransferBeneficiaryDailyLimit(t *testing.T) {
+	r := require.New(t)
+
+	db, mock, err := sqlmock.New()
+	r.NoError(err)
+
+	s := NewService(
+		config.Config{BusinessTimezone: "Asia/Tashkent"},
+		zap.NewNop(),
+		postgres.NewStore(db),
+	)
+
+	loc, err := time.LoadLocation("Asia/Tashkent")
+	r.NoError(err)
+	now := time.Now().In(loc)
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc).Format(time.RFC3339)
+	createdAt := time.Now().Add(-72 * time.Hour).UTC().Format(time.RFC3339)
+
+	// the day is the business day, what the user sent from any account they hold counts
+	mock.ExpectQuery(`^SELECT (.+?) FROM beneficiaries * `).WithArgs("TestBeneficiaryID", "TestUserID").
+		WillReturnRows(sqlmock.NewRows([]string{"guid", "user_id", "nickname", "account_id", "transfer_limit", "daily_limit", "created_at", "updated_at"}).
+			AddRow("TestBeneficiaryID", "TestUserID", "Mom", "TestAccountID2", 0.0, 1000.0, createdAt, createdAt))
+	mock.ExpectQuery(`^SELECT (.+?) FROM transactions t\s+JOIN account_holders h`).WithArgs("TestUserID", "TestAccountID2", dayStart).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(900.0))
+
+	t.Run("DAILY_LIMIT", func(t *testing.T) {
+		_, err := s.Transfer(context.Background(), &models.TransferRequest{
+			UserID:        "TestUserID",
+			FromAccountID: "TestAccountID1",
+			BeneficiaryID: "TestBeneficiaryID",
+			Amount:        200.0,
+		})
+		r.Equal(&customerrors.TransferLimitExceededError{Limit: 1000, Reason: "daily"}, err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+}
+
+func TestPayment_DepositInvalidRemittance(t *testing.T) {
+	r := require.New(t)
+
+	db, mock, err := sqlmock.New()
+	r.NoError(err)
+
+	s := NewService(
+		config.Config{},
+		zap.NewNop(),
+		postgres.NewStore(db),
+	)
+
+	t.Run("INVALID_REFERENCE", func(t *testing.T) {
+
+		_, err := s.Deposit(context.Background(), &models.DepositRequest{
+			AccountID: "TestAccountID1",
+			Amount:    100.0,
+			Reference: "INV#2021/01",
+		})
+		r.IsType(&customerrors.InvalidRemittanceError{}, err)
+	})
+
+	t.Run("INVALID_DESCRIPTION", func(t *testing.T) {
+
+		_, err := s.Deposit(context.Background(), &models.DepositRequest{
+			AccountID:   "TestAccountID1",
+			Amount:      100.0,
+			Description: "rent\x00",
+		})
+		r.IsType(&customerrors.InvalidRemittanceError{}, err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+}
+
+func TestPayment_TransferOverdraft(t *testing.T) {
+
+	r := require.New(t)
+
+	db, mock, err := sqlmock.New()
+	r.NoError(err)
+
+	s := NewService(
+		config.Config{},
+		zap.NewNop(),
+		postgres.NewStore(db),
+	)
+
+	accountColumns := []string{"guid", "user_id", "balance", "product", "accrued_interest", "overdraft_limit", "created_at", "updated_at", "status", "closed_at"}
+	txColumns := []string{"guid", "transaction_amount", "recipient_id", "transaction_type", "description", "reference", "created_at"}
+
+	// 50 on the balance and a 100 overdraft cover a transfer of 150
+	expectSanctionsClear(mock, "TestAccountID1", "TestAccountID2")
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT (.+?) FROM accounts * `).WithArgs("TestAccountID1").
+		WillReturnRows(sqlmock.NewRows(accountColumns).AddRow("TestAccountID1", "TestUserID", 50.0, "current", "0", 100.0, "2021-01-01", "2021-01-01", "active", nil))
+	mock.ExpectQuery(`^SELECT (.+?) FROM accounts * `).WithArgs("TestAccountID2").
+		WillReturnRows(sqlmock.NewRows(accountColumns).AddRow("TestAccountID2", "TestUserID2", 0.0, "current", "0", 0.0, "2021-01-01", "2021-01-01", "active", nil))
+	mock.ExpectQuery(`^SELECT status FROM accounts`).WithArgs("TestAccountID1").WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("active"))
+	mock.ExpectQuery(`^SELECT status FROM accounts`).WithArgs("TestAccountID2").WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("active"))
+	expectKYCTier(mock, "TestAccountID2", models.KYCTierFull)
+	mock.ExpectPrepare("INSERT INTO transactions").ExpectQuery().WithArgs("TestAccountID1", 150.0, "TestAccountID2", "debit", "", "").
+		WillReturnRows(sqlmock.NewRows(txColumns).AddRow("TestTransactionID", 150.0, "TestAccountID2", "debit", "", "", "2021-01-01"))
+	mock.ExpectPrepare("INSERT INTO transactions").ExpectQuery().WithArgs("TestAccountID2", 150.0, "TestAccountID1", "credit", "", "").
+		WillReturnRows(sqlmock.NewRows(txColumns).AddRow("TestTransactionID2", 150.0, "TestAccountID1", "credit", "", "", "2021-01-01"))
+	mock.ExpectCommit()
+
+	t.Run("SUCCESS", func(t *testing.T) {
+		_, err = s.Transfer(context.Background(), &models.TransferRequest{
+			FromAccountID: "TestAccountID1",
+			ToAccountID:   "TestAccountID2",
+			Amount:        150.0,
+		})
+		r.NoError(err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	expectSanctionsClear(mock, "TestAccountID1", "TestAccountID2")
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT (.+?) FROM accounts * `).WithArgs("TestAccountID1").
+		WillReturnRows(sqlmock.NewRows(accountColumns).AddRow("TestAccountID1", "TestUserID", 50.0, "current", "0", 100.0, "2021-01-01", "2021-01-01", "active", nil))
+	mock.ExpectQuery(`^SELECT (.+?) FROM accounts * `).WithArgs("TestAccountID2").
+		WillReturnRows(sqlmock.NewRows(accountColumns).AddRow("TestAccountID2", "TestUserID2", 0.0, "current", "0", 0.0, "2021-01-01", "2021-01-01", "active", nil))
+	mock.ExpectQuery(`^SELECT status FROM accounts`).WithArgs("TestAccountID1").WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("active"))
+	mock.ExpectQuery(`^SELECT status FROM accounts`).WithArgs("TestAccountID2").WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("active"))
+	mock.ExpectRollback()
+
+	t.Run("INSUFFICIENT_FUNDS", func(t *testing.T) {
+		_, err = s.Transfer(context.Background(), &models.TransferRequest{
+			FromAccountID: "TestAccountID1",
+			ToAccountID:   "TestAccountID2",
+			Amount:        200.1,
+		})
+		r.Equal(&customerrors.InsufficientFundsError{AccountID: "TestAccountID1", Shortfall: "50.10"}, err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+}
+
+func TestPayment_CaptureTransactionsRoundUp(t *testing.T) {
+
+	r := require.New(t)
+
+	db, mock, err := sqlmock.New()
+	r.NoError(err)
+
+	s := NewService(
+		config.Config{},
+		zap.NewNop(),
+		postgres.NewStore(db),
+	)
+
+	lockedAccountColumns := []string{"guid", "user_id", "balance", "product", "accrued_interest", "overdraft_limit", "status", "closed_at"}
+	txColumns := []string{"guid", "transaction_amount", "transaction_type", "recipient_id", "description", "reference", "created_at"}
+	potColumns := []string{"guid", "user_id", "account_id", "pot_account_id", "name", "target_amount", "target_date", "round_up_to", "status", "balance", "closed_at", "created_at", "updated_at"}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT (.+?) FROM transactions * `).WithArgs(pq.Array([]string{"TestTransactionID"})).
+		WillReturnRows(sqlmock.NewRows([]string{"guid", "account_id", "transaction_amount", "transaction_type", "recipient_id", "description", "reference", "created_at", "approved", "done", "done_timestampe"}).
+			AddRow("TestTransactionID", "TestAccountID1", 12.3, "debit", "TestAccountID2", "", "", "2021-01-01", true, false, nil))
+	mock.ExpectQuery(`^SELECT status FROM accounts`).WithArgs("TestAccountID1").WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("active"))
+	mock.ExpectExec(`^UPDATE accounts SET balance = balance`).WithArgs("-12.3", "TestAccountID1").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// 12.30 rounded up to a whole unit puts 0.70 into the pot, the balance left after the debit covers it
+	mock.ExpectQuery(`^SELECT (.+?) FROM pots p`).WithArgs("TestAccountID1").
+		WillReturnRows(sqlmock.NewRows(potColumns).AddRow("TestPotID", "TestUserID", "TestAccountID1", "TestPotAccountID", "Holiday", nil, nil, "1", "active", "0.00", nil, "2021-01-01", "2021-01-01"))
+	mock.ExpectQuery(`^SELECT (.+?) FROM accounts(.+?)FOR UPDATE`).WithArgs("TestAccountID1").
+		WillReturnRows(sqlmock.NewRows(lockedAccountColumns).AddRow("TestAccountID1", "TestUserID", 87.7, "current", "0", 0.0, "active", nil))
+	mock.ExpectQuery(`^SELECT status FROM accounts`).WithArgs("TestAccountID1").WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("active"))
+	mock.ExpectPrepare("INSERT INTO transactions").ExpectQuery().WithArgs("TestAccountID1", 0.7, "TestPotAccountID", "debit", "Round-up to pot Holiday", "").
+		WillReturnRows(sqlmock.NewRows(txColumns).AddRow("TestRoundUpDebitID", 0.7, "debit", "TestPotAccountID", "Round-up to pot Holiday", "", "2021-01-01"))
+	mock.ExpectExec(`^UPDATE transactions SET`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`^UPDATE accounts SET balance = balance`).WithArgs("-0.70", "TestAccountID1").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`^SELECT status FROM accounts`).WithArgs("TestPotAccountID").WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("active"))
+	mock.ExpectPrepare("INSERT INTO transactions").ExpectQuery().WithArgs("TestPotAccountID", 0.7, "TestAccountID1", "credit", "Round-up to pot Holiday", "").
+		WillReturnRows(sqlmock.NewRows(txColumns).AddRow("TestRoundUpCreditID", 0.7, "credit", "TestAccountID1", "Round-up to pot Holiday", "", "2021-01-01"))
+	mock.ExpectExec(`^UPDATE transactions SET`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`^UPDATE accounts SET balance = balance`).WithArgs("0.70", "TestPotAccountID").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectExec(`^UPDATE transactions
+	SET (.+?) WHERE * `).WithArgs(pq.Array([]string{"TestTransactionID"}), "TestAccountID1").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	t.Run("SUCCESS", func(t *testing.T) {
+		err = s.CaptureTransactions(context.Background(), &models.CaptureTransactionsRequest{
+			AccountID:      "TestAccountID1",
+			TransactionIDS: []string{"TestTransactionID"},
+		})
+		r.NoError(err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	// a sub-cent debit has no spare change to round up, it still settles
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT (.+?) FROM transactions * `).WithArgs(pq.Array([]string{"TestTransactionID"})).
+		WillReturnRows(sqlmock.NewRows([]string{"guid", "account_id", "transaction_amount", "transaction_type", "recipient_id", "description", "reference", "created_at", "approved", "done", "done_timestampe"}).
+			AddRow("TestTransactionID", "TestAccountID1", 0.005, "debit", "TestAccountID2", "", "", "2021-01-01", true, false, nil))
+	mock.ExpectQuery(`^SELECT status FROM accounts`).WithArgs("TestAccountID1").WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("active"))
+	mock.ExpectExec(`^UPDATE accounts SET balance = balance`).WithArgs("-0.005", "TestAccountID1").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`^SELECT (.+?) FROM pots p`).WithArgs("TestAccountID1").
+		WillReturnRows(sqlmock.NewRows(potColumns).AddRow("TestPotID", "TestUserID", "TestAccountID1", "TestPotAccountID", "Holiday", nil, nil, "1", "active", "0.00", nil, "2021-01-01", "2021-01-01"))
+	mock.ExpectExec(`^UPDATE transactions
+	SET (.+?) WHERE * `).WithArgs(pq.Array([]string{"TestTransactionID"}), "TestAccountID1").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	t.Run("SUB_CENT_DEBIT", func(t *testing.T) {
+		err = s.CaptureTransactions(context.Background(), &models.CaptureTransactionsRequest{
+			AccountID:      "TestAccountID1",
+			TransactionIDS: []string{"TestTransactionID"},
+		})
+		r.NoError(err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+}
+
+func TestPayment_InvalidAmount(t *testing.T) {
+	r := require.New(t)
+
+	db, mock, err := sqlmock.New()
+	r.NoError(err)
+
+	s := NewService(
+		config.Config{},
+		zap.NewNop(),
+		postgres.NewStore(db),
+	)
+
+	// amounts that aren't positive whole cents are rejected before anything is read
+	for _, amount := range []float64{0.005, 0, -10} {
+		_, err = s.Transfer(context.Background(), &models.TransferRequest{
+			FromAccountID: "TestAccountID1",
+			ToAccountID:   "TestAccountID2",
+			Amount:        amount,
+		})
+		r.Equal(&customerrors.InvalidAmountError{Amount: fmt.Sprint(amount)}, err)
+
+		_, err = s.WithDrawal(context.Background(), &models.WithDrawalRequest{AccountID: "TestAccountID1", Amount: amount})
+		r.Equal(&customerrors.InvalidAmountError{Amount: fmt.Sprint(amount)}, err)
+
+		_, err = s.Deposit(context.Background(), &models.DepositRequest{AccountID: "TestAccountID1", Amount: amount})
+		r.Equal(&customerrors.InvalidAmountError{Amount: fmt.Sprint(amount)}, err)
+	}
+	r.NoError(mock.ExpectationsWereMet())
+}
+
+// expectSanctionsClear expects the lookup of sanctions hits on the accounts of a payment to find none
+func expectSanctionsClear(mock sqlmock.Sqlmock, accountIDs ...string) {
+	mock.ExpectQuery(`^SELECT EXISTS`).WithArgs(pq.Array(accountIDs)).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+}
+
+// expectKYCTier expects the lookup of the KYC tier of the account's owner
+func expectKYCTier(mock sqlmock.Sqlmock, accountID, tier string) {
+	mock.ExpectQuery(`^SELECT u.kyc_tier`).WithArgs(accountID).
+		WillReturnRows(sqlmock.NewRows([]string{"kyc_tier"}).AddRow(tier))
+}
+
+// expectNoBeneficiary expects the recipient of a transfer not to be in the user's address book
+func expectNoBeneficiary(mock sqlmock.Sqlmock, userID, accountID string) {
+	mock.ExpectQuery(`^SELECT (.+?) FROM beneficiaries`).WithArgs(userID, accountID).
+		WillReturnRows(sqlmock.NewRows([]string{"guid", "user_id", "nickname", "account_id", "transfer_limit", "daily_limit", "created_at", "updated_at"}))
+}
+
+var fraudSignalColumns = []string{"debits_last_hour", "average_debit", "debit_count", "counterparty_payments", "own_counterparty"}
+
+func TestPayment_TransferDualApproval(t *testing.T) {
+	r := require.New(t)
+
+	db, mock, err := sqlmock.New()
+	r.NoError(err)
+
+	s := NewService(
+		config.Config{},
+		zap.NewNop(),
+		postgres.NewStore(db),
+	)
+
+	approvalColumns := []string{"guid", "account_id", "requested_by", "to_account_id", "amount", "description", "reference", "status", "decided_by", "transaction_id", "expires_at", "decided_at", "created_at"}
+
+	// above the threshold of a joint account the transfer waits for the other holder
+	expectSanctionsClear(mock, "TestAccountID1", "TestAccountID2")
+	mock.ExpectQuery(`^SELECT (.+?) FROM accounts a`).WithArgs("TestAccountID1", "TestUserID").
+		WillReturnRows(sqlmock.NewRows([]string{"role", "dual_approval_threshold", "count"}).AddRow("co_owner", "500.00", 1))
+	expectNoBeneficiary(mock, "TestUserID", "TestAccountID2")
+	mock.ExpectQuery(`^SELECT (.+?) FROM transactions t`).WithArgs("TestUserID", "TestAccountID1", "TestAccountID2", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows(fraudSignalColumns).AddRow(0, 0, 0, 0, false))
+	servicetest.ExpectAuditBegin(mock)
+	mock.ExpectQuery(`^INSERT INTO transfer_approvals`).
+		WithArgs("TestAccountID1", "TestUserID", "TestAccountID2", 600.0, "", "", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows(approvalColumns).
+			AddRow("TestApprovalID", "TestAccountID1", "TestUserID", "TestAccountID2", 600.0, "", "", "pending", nil, nil, "2021-01-02T00:00:00Z", nil, "2021-01-01T00:00:00Z"))
+	servicetest.ExpectAuditEvent(mock, models.AuditEventTransferApprovalRequested, "TestUserID", "", []byte(`{"account_id":"TestAccountID1","amount":600,"to_account_id":"TestAccountID2"}`),
+		"TestUserID", models.AuditResourceTransferApproval, "TestApprovalID", "", nil, `{"status":"pending"}`, "", sqlmock.AnyArg(), sqlmock.AnyArg())
+	mock.ExpectCommit()
+
+	t.Run("PENDING_APPROVAL", func(t *testing.T) {
+		resp, err := s.Transfer(context.Background(), &models.TransferRequest{
+			UserID:        "TestUserID",
+			FromAccountID: "TestAccountID1",
+			ToAccountID:   "TestAccountID2",
+			Amount:        600.0,
+		})
+		r.NoError(err)
+		r.Empty(resp.Transactions)
+		r.Equal("TestApprovalID", resp.Approval.ID)
+		r.Equal(models.TransferApprovalStatusPending, resp.Approval.Status)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	// at or below the threshold it is made right away
+	expectSanctionsClear(mock, "TestAccountID1", "TestAccountID2")
+	mock.ExpectQuery(`^SELECT (.+?) FROM accounts a`).WithArgs("TestAccountID1", "TestUserID").
+		WillReturnRows(sqlmock.NewRows([]string{"role", "dual_approval_threshold", "count"}).AddRow("co_owner", "500.00", 1))
+	expectNoBeneficiary(mock, "TestUserID", "TestAccountID2")
+	mock.ExpectQuery(`^SELECT (.+?) FROM transactions t`).WithArgs("TestUserID", "TestAccountID1", "TestAccountID2", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows(fraudSignalColumns).AddRow(0, 0, 0, 0, false))
+	servicetest.ExpectAuditBegin(mock)
+	mock.ExpectQuery(`^SELECT (.+?) FROM accounts * `).WithArgs("TestAccountID1").
+		WillReturnRows(sqlmock.NewRows([]string{"guid", "user_id", "balance", "product", "accrued_interest", "overdraft_limit", "created_at", "updated_at", "status", "closed_at"}).
+			AddRow("TestAccountID1", "TestOwnerID", 1000, "current", "0", 0.0, "2021-01-01", "2021-01-01", "active", nil))
+	mock.ExpectQuery(`^SELECT (.+?) FROM accounts * `).WithArgs("TestAccountID2").
+		WillReturnRows(sqlmock.NewRows([]string{"guid", "user_id", "balance", "product", "accrued_interest", "overdraft_limit", "created_at", "updated_at", "status", "closed_at"}).
+			AddRow("TestAccountID2", "TestRecipientID", 0, "current", "0", 0.0, "2021-01-01", "2021-01-01", "active", nil))
+	mock.ExpectQuery(`^SELECT status FROM accounts`).WithArgs("TestAccountID1").WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("active"))
+	mock.ExpectQuery(`^SELECT status FROM accounts`).WithArgs("TestAccountID2").WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("active"))
+	expectKYCTier(mock, "TestAccountID2", models.KYCTierFull)
+	mock.ExpectPrepare("INSERT INTO transactions").ExpectQuery().WithArgs("TestAccountID1", 500.0, "TestAccountID2", "debit", "", "").
+		WillReturnRows(sqlmock.NewRows(servicetest.TransactionRowColumns).AddRow("TestDebitID", 500.0, "debit", "TestAccountID2", "", "", "2021-01-01"))
+	mock.ExpectPrepare("INSERT INTO transactions").ExpectQuery().WithArgs("TestAccountID2", 500.0, "TestAccountID1", "credit", "", "").
+		WillReturnRows(sqlmock.NewRows(servicetest.TransactionRowColumns).AddRow("TestCreditID", 500.0, "credit", "TestAccountID1", "", "", "2021-01-01"))
+	servicetest.ExpectAuditEvent(mock, models.AuditEventTransferMade, "TestUserID", "", []byte(`{"amount":500,"reference":"","to_account_id":"TestAccountID2"}`),
+		"TestUserID", models.AuditResourceAccount, "TestAccountID1", "", nil, `{"transaction_ids":["TestDebitID","TestCreditID"]}`, "", sqlmock.AnyArg(), sqlmock.AnyArg())
+	mock.ExpectCommit()
+
+	t.Run("AT_THRESHOLD", func(t *testing.T) {
+		resp, err := s.Transfer(context.Background(), &models.TransferRequest{
+			UserID:        "TestUserID",
+			FromAccountID: "TestAccountID1",
+			ToAccountID:   "TestAccountID2",
+			Amount:        500.0,
+		})
+		r.NoError(err)
+		r.Nil(resp.Approval)
+		r.Len(resp.Transactions, 2)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	// the other holder turns it down
+	mock.ExpectQuery(`^SELECT h.role FROM account_holders`).WithArgs("TestAccountID1", "TestOtherUserID").
+		WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("owner"))
+	servicetest.ExpectAuditBegin(mock)
+	mock.ExpectQuery(`^SELECT (.+?) FROM transfer_approvals(.+?)FOR UPDATE`).WithArgs("TestApprovalID", "TestAccountID1").
+		WillReturnRows(sqlmock.NewRows(approvalColumns).
+			AddRow("TestApprovalID", "TestAccountID1", "TestUserID", "TestAccountID2", 600.0, "", "", "pending", nil, nil, "2021-01-02T00:00:00Z", nil, "2021-01-01T00:00:00Z"))
+	mock.ExpectExec(`^UPDATE transfer_approvals SET`).WithArgs("TestApprovalID", models.TransferApprovalStatusRejected, "TestOtherUserID", nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	servicetest.ExpectAuditEvent(mock, models.AuditEventTransferApprovalDecided, "TestUserID", "", []byte(`{"account_id":"TestAccountID1","amount":600,"to_account_id":"TestAccountID2"}`),
+		"TestOtherUserID", models.AuditResourceTransferApproval, "TestApprovalID", "", `{"status":"pending"}`, `{"status":"rejected"}`, "", sqlmock.AnyArg(), sqlmock.AnyArg())
+	mock.ExpectCommit()
+
+	t.Run("REJECTED", func(t *testing.T) {
+		resp, err := s.RejectTransfer(context.Background(), &models.TransferApprovalByIDRequest{ID: "TestApprovalID", AccountID: "TestAccountID1", UserID: "TestOtherUserID"})
+		r.NoError(err)
+		r.Equal(models.TransferApprovalStatusRejected, resp.Approval.Status)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	// a viewer can't pay from the account at all
+	expectSanctionsClear(mock, "TestAccountID1", "TestAccountID2")
+	mock.ExpectQuery(`^SELECT (.+?) FROM accounts a`).WithArgs("TestAccountID1", "TestViewerID").
+		WillReturnRows(sqlmock.NewRows([]string{"role", "dual_approval_threshold", "count"}).AddRow("viewer", "500.00", 2))
+
+	t.Run("VIEWER", func(t *testing.T) {
+		_, err := s.Transfer(context.Background(), &models.TransferRequest{
+			UserID:        "TestViewerID",
+			FromAccountID: "TestAccountID1",
+			ToAccountID:   "TestAccountID2",
+			Amount:        10.0,
+		})
+		r.Equal(&customerrors.AccountPermissionError{Guid: "TestAccountID1"}, err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+}
+
+func TestPayment_FrozenAccount(t *testing.T) {
+
+	r := require.New(t)
+
+	db, mock, err := sqlmock.New()
+	r.NoError(err)
+
+	s := NewService(
+		config.Config{},
+		zap.NewNop(),
+		postgres.NewStore(db),
+	)
+
+	accountColumns := []string{"guid", "user_id", "balance", "product", "accrued_interest", "overdraft_limit", "created_at", "updated_at", "status", "closed_at"}
+
+	// a frozen account can't be debited
+	expectSanctionsClear(mock, "TestAccountID1")
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT (.+?) FROM accounts * `).WithArgs("TestAccountID1").
+		WillReturnRows(sqlmock.NewRows(accountColumns).AddRow("TestAccountID1", "TestUserID", 200.0, "current", "0", 0.0, "2021-01-01", "2021-01-01", "frozen", nil))
+	mock.ExpectQuery(`^SELECT status FROM accounts`).WithArgs("TestAccountID1").WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("frozen"))
+	mock.ExpectRollback()
+
+	t.Run("DEBIT_BLOCKED", func(t *testing.T) {
+		_, err = s.WithDrawal(context.Background(), &models.WithDrawalRequest{
+			AccountID: "TestAccountID1",
+			Amount:    100.0,
+		})
+		r.Equal(&customerrors.AccountStatusError{Guid: "TestAccountID1", Status: "frozen"}, err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	// but it still takes credits
+	expectSanctionsClear(mock, "TestAccountID1")
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT (.+?) FROM accounts * `).WithArgs("TestAccountID1").
+		WillReturnRows(sqlmock.NewRows(accountColumns).AddRow("TestAccountID1", "TestUserID", 200.0, "current", "0", 0.0, "2021-01-01", "2021-01-01", "frozen", nil))
+	mock.ExpectQuery(`^SELECT status FROM accounts`).WithArgs("TestAccountID1").WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("frozen"))
+	expectKYCTier(mock, "TestAccountID1", models.KYCTierFull)
+	mock.ExpectPrepare("INSERT INTO transactions").ExpectQuery().WithArgs("TestAccountID1", 100.0, "TestAccountID1", "credit", "", "").
+		WillReturnRows(sqlmock.NewRows([]string{"guid", "transaction_amount", "transaction_type", "recipient_id", "description", "reference", "created_at"}).AddRow("TestTransactionID", 100.0, "credit", "TestAccountID1", "", "", "2021-01-01"))
+	mock.ExpectCommit()
+
+	t.Run("CREDIT_ALLOWED", func(t *testing.T) {
+		_, err = s.Deposit(context.Background(), &models.DepositRequest{
+			AccountID: "TestAccountID1",
+			Amount:    100.0,
+		})
+		r.NoError(err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+}
+
+func TestPayment_FraudScreening(t *testing.T) {
+	r := require.New(t)
+
+	db, mock, err := sqlmock.New()
+	r.NoError(err)
+
+	s := NewService(
+		config.Config{},
+		zap.NewNop(),
+		postgres.NewStore(db),
+	)
+
+	decisionColumns := []string{"guid", "user_id", "account_id", "operation", "counterparty_id", "amount", "score", "outcome", "hits",
+		"challenge_status", "review_status", "reviewed_by", "review_comment", "reviewed_at", "created_at"}
+	approvalColumns := []string{"guid", "account_id", "requested_by", "to_account_id", "amount", "description", "reference", "status", "decided_by", "transaction_id", "expires_at", "decided_at", "created_at"}
+	req := &models.TransferRequest{
+		UserID:        "TestUserID",
+		FromAccountID: "TestAccountID1",
+		ToAccountID:   "TestAccountID2",
+		Amount:        600.0,
+	}
+	expectPolicy := func() {
+		expectSanctionsClear(mock, "TestAccountID1", "TestAccountID2")
+		mock.ExpectQuery(`^SELECT (.+?) FROM accounts a`).WithArgs("TestAccountID1", "TestUserID").
+			WillReturnRows(sqlmock.NewRows([]string{"role", "dual_approval_threshold", "count"}).AddRow("co_owner", "500.00", 1))
+		expectNoBeneficiary(mock, "TestUserID", "TestAccountID2")
+	}
+
+	// the eleventh debit within the hour is challenged
+	expectPolicy()
+	mock.ExpectQuery(`^SELECT (.+?) FROM transactions t`).
+		WillReturnRows(sqlmock.NewRows(fraudSignalColumns).AddRow(10, 200.0, 20, 3, false))
+	mock.ExpectQuery(`^INSERT INTO fraud_decisions`).
+		WithArgs("TestUserID", "TestAccountID1", models.FraudOperationTransfer, "TestAccountID2", "600.00", 50, models.FraudOutcomeChallenge,
+			sqlmock.AnyArg(), models.FraudChallengePending).
+		WillReturnRows(sqlmock.NewRows(decisionColumns).AddRow("TestDecisionID", "TestUserID", "TestAccountID1", "transfer", "TestAccountID2", "600.00", 50,
+			"challenge", []byte(`[{"rule":"velocity","score":50,"reason":"11 debits from the account in the last hour"}]`), "pending", "pending", nil, "", nil, "2021-01-01"))
+
+	t.Run("CHALLENGE", func(t *testing.T) {
+		_, err := s.Transfer(context.Background(), req)
+		r.Equal(&customerrors.FraudChallengeRequiredError{DecisionID: "TestDecisionID"}, err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	// the retry with the confirmed challenge uses it up and carries on to the approval
+	expectPolicy()
+	mock.ExpectQuery(`^SELECT (.+?) FROM transactions t`).
+		WillReturnRows(sqlmock.NewRows(fraudSignalColumns).AddRow(10, 200.0, 20, 3, false))
+	mock.ExpectExec(`^UPDATE fraud_decisions SET challenge_status = 'used'`).
+		WithArgs("TestDecisionID", "TestUserID", "TestAccountID1", "TestAccountID2", "600.00", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	servicetest.ExpectAuditBegin(mock)
+	mock.ExpectQuery(`^INSERT INTO transfer_approvals`).
+		WillReturnRows(sqlmock.NewRows(approvalColumns).
+			AddRow("TestApprovalID", "TestAccountID1", "TestUserID", "TestAccountID2", 600.0, "", "", "pending", nil, nil, "2021-01-02T00:00:00Z", nil, "2021-01-01T00:00:00Z"))
+	servicetest.ExpectAuditEvent(mock)
+	mock.ExpectCommit()
+
+	t.Run("CHALLENGE_CONFIRMED", func(t *testing.T) {
+		retry := *req
+		retry.ChallengeID = "TestDecisionID"
+		resp, err := s.Transfer(context.Background(), &retry)
+		r.NoError(err)
+		r.Equal("TestApprovalID", resp.Approval.ID)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	// a challenge confirmed for another payment does not count
+	expectPolicy()
+	mock.ExpectQuery(`^SELECT (.+?) FROM transactions t`).
+		WillReturnRows(sqlmock.NewRows(fraudSignalColumns).AddRow(10, 200.0, 20, 3, false))
+	mock.ExpectExec(`^UPDATE fraud_decisions SET challenge_status = 'used'`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	t.Run("CHALLENGE_MISMATCH", func(t *testing.T) {
+		retry := *req
+		retry.ChallengeID = "TestOtherDecisionID"
+		_, err := s.Transfer(context.Background(), &retry)
+		r.Equal(&customerrors.FraudChallengeInvalidError{}, err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	// fast, far above the average and to a new counterparty adds up to a block
+	expectPolicy()
+	mock.ExpectQuery(`^SELECT (.+?) FROM transactions t`).
+		WillReturnRows(sqlmock.NewRows(fraudSignalColumns).AddRow(10, 200.0, 20, 0, false))
+	mock.ExpectQuery(`^INSERT INTO fraud_decisions`).
+		WithArgs("TestUserID", "TestAccountID1", models.FraudOperationTransfer, "TestAccountID2", "1500.00", 110, models.FraudOutcomeBlock,
+			sqlmock.AnyArg(), nil).
+		WillReturnRows(sqlmock.NewRows(decisionColumns).AddRow("TestDecisionID2", "TestUserID", "TestAccountID1", "transfer", "TestAccountID2", "1500.00", 110,
+			"block", []byte(`[]`), nil, "pending", nil, "", nil, "2021-01-01"))
+
+	t.Run("BLOCK", func(t *testing.T) {
+		blocked := *req
+		blocked.Amount = 1500.0
+		_, err := s.Transfer(context.Background(), &blocked)
+		r.Equal(&customerrors.FraudBlockedError{DecisionID: "TestDecisionID2"}, err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+}
+
+func TestPayment_SanctionsBlock(t *testing.T) {
+	r := require.New(t)
+
+	db, mock, err := sqlmock.New()
+	r.NoError(err)
+
+	s := NewService(
+		config.Config{},
+		zap.NewNop(),
+		postgres.NewStore(db),
+	)
+
+	// a holder of the receiving account has a hit waiting for review
+	mock.ExpectQuery(`^SELECT EXISTS`).WithArgs(pq.Array([]string{"TestAccountID1", "TestAccountID2"})).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	t.Run("TRANSFER", func(t *testing.T) {
+		_, err := s.Transfer(context.Background(), &models.TransferRequest{
+			UserID:        "TestUserID",
+			FromAccountID: "TestAccountID1",
+			ToAccountID:   "TestAccountID2",
+			Amount:        100.0,
+		})
+		r.Equal(&customerrors.SanctionsBlockedError{}, err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	mock.ExpectQuery(`^SELECT EXISTS`).WithArgs(pq.Array([]string{"TestAccountID2"})).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	t.Run("DEPOSIT", func(t *testing.T) {
+		_, err := s.Deposit(context.Background(), &models.DepositRequest{
+			AccountID: "TestAccountID2",
+			Amount:    100.0,
+		})
+		r.Equal(&customerrors.SanctionsBlockedError{}, err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+}
+
+func TestPayment_KYCLimits(t *testing.T) {
+	r := require.New(t)
+
+	db, mock, err := sqlmock.New()
+	r.NoError(err)
+
+	s := NewService(
+		config.Config{
+			KYCUnverifiedBalanceLimit:    1000,
+			KYCUnverifiedDepositLimit:    500,
+			KYCUnverifiedWithdrawalLimit: 200,
+		},
+		zap.NewNop(),
+		postgres.NewStore(db),
+	)
+
+	accountColumns := []string{"guid", "user_id", "balance", "product", "accrued_interest", "overdraft_limit", "created_at", "updated_at", "status", "closed_at"}
+
+	expectDeposit := func(balance float64) {
+		expectSanctionsClear(mock, "TestAccountID1")
+		mock.ExpectBegin()
+		mock.ExpectQuery(`^SELECT (.+?) FROM accounts * `).WithArgs("TestAccountID1").
+			WillReturnRows(sqlmock.NewRows(accountColumns).AddRow("TestAccountID1", "TestUserID", balance, "current", "0", 0.0, "2021-01-01", "2021-01-01", "active", nil))
+		mock.ExpectQuery(`^SELECT status FROM accounts`).WithArgs("TestAccountID1").WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("active"))
+	}
+
+	expectDeposit(0)
+	expectKYCTier(mock, "TestAccountID1", models.KYCTierUnverified)
+	mock.ExpectRollback()
+
+	t.Run("DEPOSIT_CAP", func(t *testing.T) {
+		_, err := s.Deposit(context.Background(), &models.DepositRequest{
+			AccountID: "TestAccountID1",
+			Amount:    500.01,
+		})
+		r.Equal(&customerrors.KYCLimitExceededError{AccountID: "TestAccountID1", Tier: models.KYCTierUnverified, Limit: models.KYCLimitDeposit, Cap: "500.00"}, err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	// 800 on the balance and a deposit of 300 would leave more than the unverified tier may hold
+	expectDeposit(800)
+	expectKYCTier(mock, "TestAccountID1", models.KYCTierUnverified)
+	mock.ExpectRollback()
+
+	t.Run("BALANCE_CAP", func(t *testing.T) {
+		_, err := s.Deposit(context.Background(), &models.DepositRequest{
+			AccountID: "TestAccountID1",
+			Amount:    300,
+		})
+		r.Equal(&customerrors.KYCLimitExceededError{AccountID: "TestAccountID1", Tier: models.KYCTierUnverified, Limit: models.KYCLimitBalance, Cap: "1000.00"}, err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	// the full tier has no caps by default
+	expectDeposit(800)
+	expectKYCTier(mock, "TestAccountID1", models.KYCTierFull)
+	mock.ExpectPrepare("INSERT INTO transactions").ExpectQuery().WithArgs("TestAccountID1", 600.0, "TestAccountID1", "credit", "", "").
+		WillReturnRows(sqlmock.NewRows([]string{"guid", "transaction_amount", "transaction_type", "recipient_id", "description", "reference", "created_at"}).AddRow("TestTransactionID", 600.0, "credit", "TestAccountID1", "", "", "2021-01-01"))
+	mock.ExpectCommit()
+
+	t.Run("FULL_TIER", func(t *testing.T) {
+		_, err := s.Deposit(context.Background(), &models.DepositRequest{
+			AccountID: "TestAccountID1",
+			Amount:    600,
+		})
+		r.NoError(err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	expectSanctionsClear(mock, "TestAccountID1")
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT (.+?) FROM accounts * `).WithArgs("TestAccountID1").
+		WillReturnRows(sqlmock.NewRows(accountColumns).AddRow("TestAccountID1", "TestUserID", 400.0, "current", "0", 0.0, "2021-01-01", "2021-01-01", "active", nil))
+	mock.ExpectQuery(`^SELECT status FROM accounts`).WithArgs("TestAccountID1").WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("active"))
+	expectKYCTier(mock, "TestAccountID1", models.KYCTierUnverified)
+	mock.ExpectRollback()
+
+	t.Run("WITHDRAWAL_CAP", func(t *testing.T) {
+		_, err := s.WithDrawal(context.Background(), &models.WithDrawalRequest{
+			AccountID: "TestAccountID1",
+			Amount:    250,
+		})
+		r.Equal(&customerrors.KYCLimitExceededError{AccountID: "TestAccountID1", Tier: models.KYCTierUnverified, Limit: models.KYCLimitWithdrawal, Cap: "200.00"}, err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	// moving money in on the customer's request, such as out of a pot, keeps to the balance cap as well
+	mock.ExpectBegin()
+	expectSanctionsClear(mock, "TestAccountID2", "TestAccountID1")
+	mock.ExpectQuery(`^SELECT (.+?) FROM accounts * `).WithArgs("TestAccountID1").
+		WillReturnRows(sqlmock.NewRows(accountColumns).AddRow("TestAccountID1", "TestUserID", 800.0, "current", "0", 0.0, "2021-01-01", "2021-01-01", "active", nil))
+	expectKYCTier(mock, "TestAccountID1", models.KYCTierUnverified)
+	mock.ExpectRollback()
+
+	t.Run("POSTING_BALANCE_CAP", func(t *testing.T) {
+		tx, err := db.Begin()
+		r.NoError(err)
+
+		_, err = s.PostTransfer(context.Background(), tx, &models.PostTransferRequest{
+			FromAccountID:     "TestAccountID2",
+			ToAccountID:       "TestAccountID1",
+			Amount:            "200.01",
+			Description:       "Move from pot Holiday",
+			CustomerInitiated: true,
+		})
+		r.Equal(&customerrors.KYCLimitExceededError{AccountID: "TestAccountID1", Tier: models.KYCTierUnverified, Limit: models.KYCLimitBalance, Cap: "1000.00"}, err)
+		r.NoError(tx.Rollback())
+		r.NoError(mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,83 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/jwt"
+	"github.com/dilmurodov/online_banking/pkg/logger"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/dilmurodov/online_banking/pkg/util"
+)
+
+const phoneRecipientPurpose = "phone_recipient"
+
+// LookupPhoneRecipient resolves a phone number to a recipient and returns only the masked name
+// with a short-lived token, so the sender never learns the recipient's account id
+func (s *Service) LookupPhoneRecipient(ctx context.Context, req *models.PhoneLookupRequest) (resp *models.PhoneLookupResponse, err error) {
+	s.log.Info("---LookupPhoneRecipient--->", logger.Any("req", req))
+
+	if !util.IsValidPhone(req.Phone) {
+		return nil, &customerrors.InvalidPhoneError{Phone: req.Phone}
+	}
+
+	recipient, err := s.strg.User().GetPhoneRecipient(ctx, req.Phone)
+	if err != nil {
+		s.log.Error("---LookupPhoneRecipient->GetPhoneRecipient--->", logger.Error(err))
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(config.PhoneRecipientTokenExpiresInTime)
+	token, err := jwt.GenToken(map[interface{}]interface{}{
+		"purpose":    phoneRecipientPurpose,
+		"user_id":    req.UserID,
+		"account_id": recipient.AccountID,
+	}, []byte(config.SigningKey), config.PhoneRecipientTokenExpiresInTime)
+	if err != nil {
+		s.log.Error("---LookupPhoneRecipient->GenToken--->", logger.Error(err))
+		return nil, err
+	}
+
+	return &models.PhoneLookupResponse{
+		MaskedName:        util.MaskName(recipient.FirstName, recipient.LastName),
+		ConfirmationToken: token,
+		ExpiresAt:         expiresAt.Format(config.DatabaseTimeLayout),
+	}, nil
+}
+
+// TransferByPhone transfers money to the account confirmed by a previous LookupPhoneRecipient call
+func (s *Service) TransferByPhone(ctx context.Context, req *models.PhoneTransferRequest) (resp *models.TransferResponse, err error) {
+	s.log.Info("---TransferByPhone--->", logger.Any("from_account_id", req.FromAccountID), logger.Any("amount", req.Amount))
+
+	claims, err := jwt.ExtractClaims(req.ConfirmationToken, config.SigningKey)
+	if err != nil {
+		s.log.Error("---TransferByPhone->ExtractClaims--->", logger.Error(err))
+		return nil, &customerrors.InvalidConfirmationTokenError{}
+	}
+
+	if claims["purpose"] != phoneRecipientPurpose || claims["user_id"] != req.UserID {
+		return nil, &customerrors.InvalidConfirmationTokenError{}
+	}
+
+	toAccountID, ok := claims["account_id"].(string)
+	if !ok || toAccountID == "" {
+		return nil, &customerrors.InvalidConfirmationTokenError{}
+	}
+
+	if toAccountID == req.FromAccountID {
+		return nil, fmt.Errorf("cannot transfer to the same account")
+	}
+
+	return s.Transfer(ctx, &models.TransferRequest{
+		UserID:        req.UserID,
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   toAccountID,
+		Amount:        req.Amount,
+		Description:   req.Description,
+		Reference:     req.Reference,
+		ChallengeID:   req.ChallengeID,
+	})
+}
@@ -0,0 +1,56 @@
+package payment
+
+import (
+	"context"
+	"database/sql"
+	"math/big"
+
+	"github.com/dilmurodov/online_banking/pkg/interest"
+	"github.com/dilmurodov/online_banking/pkg/logger"
+	"github.com/dilmurodov/online_banking/pkg/models"
+)
+
+// roundUp moves the spare change of a settled debit into the account's round-up pot.
+// The round-up is skipped when the balance left after the debit can't cover it,
+// so it never takes the account into its overdraft.
+func (s *Service) roundUp(ctx context.Context, tx *sql.Tx, accountID string, amount float64) error {
+	pot, err := s.strg.Pot().GetRoundUpPot(ctx, accountID)
+	if err != nil || pot == nil {
+		return err
+	}
+
+	// A debit settled before amounts were checked may carry a fraction of a cent, it has no spare change to move
+	debit, err := interest.ParseAmount(amount)
+	if err != nil {
+		s.log.Info("---RoundUp->skipped--->", logger.String("account_id", accountID), logger.Error(err))
+		return nil
+	}
+	unit, err := interest.ParseDecimal(pot.RoundUpTo)
+	if err != nil {
+		return err
+	}
+
+	spare := new(big.Rat).Sub(interest.RoundUp(debit, unit), debit)
+	if spare.Sign() == 0 {
+		return nil
+	}
+
+	// the balance is read locked in the transaction, the debit just settled included
+	account, err := s.strg.Account().GetAccountForUpdate(ctx, tx, accountID)
+	if err != nil {
+		return err
+	}
+	balance := interest.Round(new(big.Rat).SetFloat64(account.Balance), interest.PostingScale)
+	if balance.Cmp(spare) < 0 {
+		s.log.Info("---RoundUp->skipped--->", logger.String("account_id", account.ID))
+		return nil
+	}
+
+	_, err = s.PostTransfer(ctx, tx, &models.PostTransferRequest{
+		FromAccountID: accountID,
+		ToAccountID:   pot.PotAccountID,
+		Amount:        interest.FormatDecimal(spare, interest.PostingScale),
+		Description:   "Round-up to pot " + pot.Name,
+	})
+	return err
+}
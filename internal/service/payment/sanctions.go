@@ -0,0 +1,22 @@
+package payment
+
+import (
+	"context"
+
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/logger"
+)
+
+// checkSanctions refuses a payment while a holder of any of the accounts has a sanctions hit that
+// is pending review or confirmed
+func (s *Service) checkSanctions(ctx context.Context, accountIDs ...string) error {
+	blocked, err := s.strg.Sanctions().HasBlockingSanctionsHit(ctx, accountIDs)
+	if err != nil {
+		s.log.Error("---CheckSanctions->HasBlockingSanctionsHit--->", logger.Error(err))
+		return err
+	}
+	if blocked {
+		return &customerrors.SanctionsBlockedError{}
+	}
+	return nil
+}
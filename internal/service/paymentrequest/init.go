@@ -0,0 +1,37 @@
+package paymentrequest
+
+import (
+	"context"
+
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/internal/service/payment"
+	"github.com/dilmurodov/online_banking/pkg/logger"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/dilmurodov/online_banking/storage"
+)
+
+type ServiceI interface {
+	CreatePaymentRequest(ctx context.Context, req *models.CreatePaymentRequestRequest) (*models.PaymentRequest, error)
+	GetIncomingPaymentRequests(ctx context.Context, req *models.GetPaymentRequestsRequest) (*models.GetPaymentRequestsResponse, error)
+	GetOutgoingPaymentRequests(ctx context.Context, req *models.GetPaymentRequestsRequest) (*models.GetPaymentRequestsResponse, error)
+	GetPaymentRequestByID(ctx context.Context, req *models.GetPaymentRequestByIDRequest) (*models.PaymentRequest, error)
+	AcceptPaymentRequest(ctx context.Context, req *models.AcceptPaymentRequestRequest) (*models.AcceptPaymentRequestResponse, error)
+	DeclinePaymentRequest(ctx context.Context, req *models.DeclinePaymentRequestRequest) (*models.PaymentRequest, error)
+	CancelPaymentRequest(ctx context.Context, req *models.DeclinePaymentRequestRequest) (*models.PaymentRequest, error)
+}
+
+type Service struct {
+	cfg     config.Config
+	log     logger.LoggerI
+	strg    storage.StorageI
+	payment payment.ServiceI
+}
+
+func NewService(cfg config.Config, log logger.LoggerI, strg storage.StorageI, paymentService payment.ServiceI) *Service {
+	return &Service{
+		cfg:     cfg,
+		log:     log,
+		strg:    strg,
+		payment: paymentService,
+	}
+}
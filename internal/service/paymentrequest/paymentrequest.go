@@ -0,0 +1,304 @@
+package paymentrequest
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/logger"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/dilmurodov/online_banking/pkg/util"
+)
+
+// CreatePaymentRequest asks another user, addressed by phone or account, to pay the requester
+func (s *Service) CreatePaymentRequest(ctx context.Context, req *models.CreatePaymentRequestRequest) (*models.PaymentRequest, error) {
+	s.log.Info("---CreatePaymentRequest--->", logger.Any("req", req))
+
+	if req.Amount <= 0 {
+		return nil, &customerrors.InvalidAmountError{Amount: fmt.Sprint(req.Amount)}
+	}
+	if utf8.RuneCountInString(req.Memo) > config.PaymentRequestMemoMaxLength {
+		return nil, fmt.Errorf("memo must not be longer than %d characters", config.PaymentRequestMemoMaxLength)
+	}
+
+	expiresAt := time.Now().Add(config.PaymentRequestDefaultTTL)
+	if req.ExpiresAt != "" {
+		t, err := time.Parse(time.RFC3339, req.ExpiresAt)
+		if err != nil {
+			return nil, fmt.Errorf("expires_at must be an RFC3339 timestamp")
+		}
+		if !t.After(time.Now()) || t.After(time.Now().Add(config.PaymentRequestMaxTTL)) {
+			return nil, fmt.Errorf("expires_at must be in the future and within %s", config.PaymentRequestMaxTTL)
+		}
+		expiresAt = t
+	}
+
+	paymentRequest := &models.PaymentRequest{
+		RequesterID:        req.RequesterID,
+		RequesterAccountID: req.AccountID,
+		Amount:             req.Amount,
+		Memo:               req.Memo,
+		ExpiresAt:          expiresAt.Format(config.DatabaseTimeLayout),
+	}
+
+	switch {
+	case req.ToAccount != "":
+		if !util.IsValidUUID(req.ToAccount) {
+			return nil, &customerrors.RecipientNotFoundError{}
+		}
+		account, err := s.strg.Account().GetAccountByID(ctx, &models.GetAccountByIDRequest{ID: req.ToAccount})
+		if err != nil {
+			s.log.Error("---CreatePaymentRequest->GetAccountByID--->", logger.Error(err))
+			return nil, &customerrors.RecipientNotFoundError{}
+		}
+		paymentRequest.PayerID = account.UserID
+		paymentRequest.PayerAccountID = account.ID
+	case req.ToPhone != "":
+		if !util.IsValidPhone(req.ToPhone) {
+			return nil, &customerrors.RecipientNotFoundError{}
+		}
+		recipient, err := s.strg.User().GetPhoneRecipient(ctx, req.ToPhone)
+		if err != nil {
+			s.log.Error("---CreatePaymentRequest->GetPhoneRecipient--->", logger.Error(err))
+			return nil, err
+		}
+		paymentRequest.PayerID = recipient.UserID
+	default:
+		return nil, fmt.Errorf("either to_phone or to_account_id is required")
+	}
+
+	if paymentRequest.PayerID == req.RequesterID {
+		return nil, fmt.Errorf("cannot request money from yourself")
+	}
+
+	resp, err := s.strg.PaymentRequest().CreatePaymentRequest(ctx, paymentRequest)
+	if err != nil {
+		s.log.Error("---CreatePaymentRequest--->", logger.Error(err))
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// GetIncomingPaymentRequests lists requests the user has been asked to pay
+func (s *Service) GetIncomingPaymentRequests(ctx context.Context, req *models.GetPaymentRequestsRequest) (*models.GetPaymentRequestsResponse, error) {
+	s.log.Info("---GetIncomingPaymentRequests--->", logger.Any("req", req))
+
+	return s.getPaymentRequests(ctx, &models.GetPaymentRequestsRequest{
+		PayerID: req.PayerID,
+		Status:  req.Status,
+		Limit:   req.Limit,
+		Offset:  req.Offset,
+	})
+}
+
+// GetOutgoingPaymentRequests lists requests the user has sent, so the requester can follow their status
+func (s *Service) GetOutgoingPaymentRequests(ctx context.Context, req *models.GetPaymentRequestsRequest) (*models.GetPaymentRequestsResponse, error) {
+	s.log.Info("---GetOutgoingPaymentRequests--->", logger.Any("req", req))
+
+	return s.getPaymentRequests(ctx, &models.GetPaymentRequestsRequest{
+		RequesterID: req.RequesterID,
+		Status:      req.Status,
+		Limit:       req.Limit,
+		Offset:      req.Offset,
+	})
+}
+
+func (s *Service) getPaymentRequests(ctx context.Context, req *models.GetPaymentRequestsRequest) (*models.GetPaymentRequestsResponse, error) {
+	// Overdue requests are expired lazily so listings never show them as payable
+	err := s.strg.PaymentRequest().ExpirePaymentRequests(ctx)
+	if err != nil {
+		s.log.Error("---GetPaymentRequests->ExpirePaymentRequests--->", logger.Error(err))
+		return nil, err
+	}
+
+	resp, err := s.strg.PaymentRequest().GetPaymentRequests(ctx, req)
+	if err != nil {
+		s.log.Error("---GetPaymentRequests--->", logger.Error(err))
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// GetPaymentRequestByID returns a request with its history to either of its parties
+func (s *Service) GetPaymentRequestByID(ctx context.Context, req *models.GetPaymentRequestByIDRequest) (*models.PaymentRequest, error) {
+	s.log.Info("---GetPaymentRequestByID--->", logger.Any("req", req))
+
+	err := s.strg.PaymentRequest().ExpirePaymentRequests(ctx)
+	if err != nil {
+		s.log.Error("---GetPaymentRequestByID->ExpirePaymentRequests--->", logger.Error(err))
+		return nil, err
+	}
+
+	resp, err := s.strg.PaymentRequest().GetPaymentRequestByID(ctx, req.ID)
+	if err != nil {
+		s.log.Error("---GetPaymentRequestByID--->", logger.Error(err))
+		return nil, err
+	}
+
+	if resp.RequesterID != req.UserID && resp.PayerID != req.UserID {
+		return nil, &customerrors.PaymentRequestNotFoundError{Guid: req.ID}
+	}
+
+	return resp, nil
+}
+
+// AcceptPaymentRequest pays a pending request from one of the payer's accounts
+func (s *Service) AcceptPaymentRequest(ctx context.Context, req *models.AcceptPaymentRequestRequest) (*models.AcceptPaymentRequestResponse, error) {
+	s.log.Info("---AcceptPaymentRequest--->", logger.Any("req", req))
+
+	paymentRequest, err := s.getPendingForPayer(ctx, req.ID, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if paymentRequest.PayerAccountID != "" && paymentRequest.PayerAccountID != req.FromAccountID {
+		return nil, fmt.Errorf("payment request must be paid from account %s", paymentRequest.PayerAccountID)
+	}
+
+	// Claim the request first so it can't be paid twice
+	_, err = s.strg.PaymentRequest().UpdatePaymentRequestStatus(ctx, &models.UpdatePaymentRequestStatusRequest{
+		ID:         req.ID,
+		FromStatus: models.PaymentRequestStatusPending,
+		ToStatus:   models.PaymentRequestStatusAccepted,
+		ActorID:    req.UserID,
+	})
+	if err != nil {
+		s.log.Error("---AcceptPaymentRequest->UpdatePaymentRequestStatus--->", logger.Error(err))
+		return nil, err
+	}
+
+	transfer, err := s.payment.Transfer(ctx, &models.TransferRequest{
+		UserID:        req.UserID,
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   paymentRequest.RequesterAccountID,
+		Amount:        paymentRequest.Amount,
+		Description:   paymentRequest.Memo,
+		Immediate:     true,
+		ChallengeID:   req.ChallengeID,
+	})
+	if err != nil {
+		s.log.Error("---AcceptPaymentRequest->Transfer--->", logger.Error(err))
+
+		// Give the request back to the payer so they can retry
+		_, rerr := s.strg.PaymentRequest().UpdatePaymentRequestStatus(ctx, &models.UpdatePaymentRequestStatusRequest{
+			ID:         req.ID,
+			FromStatus: models.PaymentRequestStatusAccepted,
+			ToStatus:   models.PaymentRequestStatusPending,
+			ActorID:    req.UserID,
+			Note:       "transfer failed",
+		})
+		if rerr != nil {
+			s.log.Error("---AcceptPaymentRequest->UpdatePaymentRequestStatus--->", logger.Error(rerr))
+		}
+		return nil, err
+	}
+
+	var transactionID string
+	if len(transfer.Transactions) > 0 {
+		transactionID = transfer.Transactions[0].ID
+	}
+
+	paymentRequest, err = s.strg.PaymentRequest().UpdatePaymentRequestStatus(ctx, &models.UpdatePaymentRequestStatusRequest{
+		ID:            req.ID,
+		FromStatus:    models.PaymentRequestStatusAccepted,
+		ToStatus:      models.PaymentRequestStatusAccepted,
+		ActorID:       req.UserID,
+		Note:          "transfer created",
+		TransactionID: transactionID,
+	})
+	if err != nil {
+		s.log.Error("---AcceptPaymentRequest->UpdatePaymentRequestStatus--->", logger.Error(err))
+		return nil, err
+	}
+
+	return &models.AcceptPaymentRequestResponse{
+		PaymentRequest: paymentRequest,
+		Transfer:       transfer,
+	}, nil
+}
+
+// DeclinePaymentRequest lets the payer refuse a pending request
+func (s *Service) DeclinePaymentRequest(ctx context.Context, req *models.DeclinePaymentRequestRequest) (*models.PaymentRequest, error) {
+	s.log.Info("---DeclinePaymentRequest--->", logger.Any("req", req))
+
+	_, err := s.getPendingForPayer(ctx, req.ID, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.strg.PaymentRequest().UpdatePaymentRequestStatus(ctx, &models.UpdatePaymentRequestStatusRequest{
+		ID:         req.ID,
+		FromStatus: models.PaymentRequestStatusPending,
+		ToStatus:   models.PaymentRequestStatusDeclined,
+		ActorID:    req.UserID,
+	})
+	if err != nil {
+		s.log.Error("---DeclinePaymentRequest--->", logger.Error(err))
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// CancelPaymentRequest lets the requester withdraw a pending request
+func (s *Service) CancelPaymentRequest(ctx context.Context, req *models.DeclinePaymentRequestRequest) (*models.PaymentRequest, error) {
+	s.log.Info("---CancelPaymentRequest--->", logger.Any("req", req))
+
+	paymentRequest, err := s.strg.PaymentRequest().GetPaymentRequestByID(ctx, req.ID)
+	if err != nil {
+		s.log.Error("---CancelPaymentRequest->GetPaymentRequestByID--->", logger.Error(err))
+		return nil, err
+	}
+	if paymentRequest.RequesterID != req.UserID {
+		return nil, &customerrors.PaymentRequestNotFoundError{Guid: req.ID}
+	}
+
+	resp, err := s.strg.PaymentRequest().UpdatePaymentRequestStatus(ctx, &models.UpdatePaymentRequestStatusRequest{
+		ID:         req.ID,
+		FromStatus: models.PaymentRequestStatusPending,
+		ToStatus:   models.PaymentRequestStatusCancelled,
+		ActorID:    req.UserID,
+	})
+	if err != nil {
+		s.log.Error("---CancelPaymentRequest--->", logger.Error(err))
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// getPendingForPayer loads a request addressed to the user and expires it if it is overdue
+func (s *Service) getPendingForPayer(ctx context.Context, id, userID string) (*models.PaymentRequest, error) {
+	paymentRequest, err := s.strg.PaymentRequest().GetPaymentRequestByID(ctx, id)
+	if err != nil {
+		s.log.Error("---GetPaymentRequestByID--->", logger.Error(err))
+		return nil, err
+	}
+	if paymentRequest.PayerID != userID {
+		return nil, &customerrors.PaymentRequestNotFoundError{Guid: id}
+	}
+	if paymentRequest.Status != models.PaymentRequestStatusPending {
+		return nil, &customerrors.PaymentRequestStateError{Status: models.PaymentRequestStatusPending}
+	}
+
+	expiresAt, err := time.Parse(time.RFC3339, paymentRequest.ExpiresAt)
+	if err == nil && !expiresAt.After(time.Now()) {
+		_, err = s.strg.PaymentRequest().UpdatePaymentRequestStatus(ctx, &models.UpdatePaymentRequestStatusRequest{
+			ID:         id,
+			FromStatus: models.PaymentRequestStatusPending,
+			ToStatus:   models.PaymentRequestStatusExpired,
+			Note:       "expired automatically",
+		})
+		if err != nil {
+			s.log.Error("---UpdatePaymentRequestStatus--->", logger.Error(err))
+		}
+		return nil, &customerrors.PaymentRequestStateError{Status: models.PaymentRequestStatusPending}
+	}
+
+	return paymentRequest, nil
+}
@@ -0,0 +1,108 @@
+package paymentrequest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/internal/service/payment"
+	"github.com/dilmurodov/online_banking/internal/service/servicetest"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	mock_storage "github.com/dilmurodov/online_banking/storage/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var paymentRequestRowColumns = []string{"guid", "requester_id", "requester_account_id", "payer_id", "payer_account_id", "amount", "memo", "status", "transaction_id", "expires_at", "created_at", "updated_at"}
+
+func newTestService(t *testing.T) (*Service, sqlmock.Sqlmock) {
+	strg, mock := servicetest.NewStore(t)
+
+	return NewService(
+		config.Config{},
+		zap.NewNop(),
+		strg,
+		payment.NewService(config.Config{}, zap.NewNop(), strg),
+	), mock
+}
+
+func TestPaymentRequest_CreatePaymentRequest(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s, mock := newTestService(t)
+
+	repo := mock_storage.NewMockPaymentRequestRepoI(ctrl)
+
+	recipient := sqlmock.NewRows([]string{"guid", "first_name", "last_name", "guid"}).AddRow("TestPayerID", "John", "Doe", "TestPayerAccountID")
+	created := sqlmock.NewRows(paymentRequestRowColumns).AddRow("TestRequestID", "TestRequesterID", "TestAccountID", "TestPayerID", nil, 100.0, "lunch", "pending", nil, "2021-01-04T00:00:00Z", "2021-01-01T00:00:00Z", "2021-01-01T00:00:00Z")
+
+	mock.ExpectQuery(`^SELECT (.+?) FROM "users" * `).WithArgs("+998901234567", pq.Array([]string{"owner", "co_owner", "signer"})).WillReturnRows(recipient)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^INSERT INTO payment_requests`).WillReturnRows(created)
+	mock.ExpectExec(`^INSERT INTO payment_request_history`).WithArgs("TestRequestID", "pending", "TestRequesterID").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	t.Run("SUCCESS", func(t *testing.T) {
+
+		ctx := context.Background()
+
+		in := &models.CreatePaymentRequestRequest{
+			RequesterID: "TestRequesterID",
+			AccountID:   "TestAccountID",
+			ToPhone:     "+998901234567",
+			Amount:      100.0,
+			Memo:        "lunch",
+		}
+
+		repo.EXPECT().CreatePaymentRequest(ctx, gomock.Any()).Return(&models.PaymentRequest{ID: "TestRequestID"}, nil).Times(1).AnyTimes()
+
+		resp, err := s.CreatePaymentRequest(ctx, in)
+		r.NoError(err)
+		r.Equal("TestRequestID", resp.ID)
+		r.Equal(models.PaymentRequestStatusPending, resp.Status)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("INVALID_EXPIRY", func(t *testing.T) {
+
+		_, err := s.CreatePaymentRequest(context.Background(), &models.CreatePaymentRequestRequest{
+			RequesterID: "TestRequesterID",
+			AccountID:   "TestAccountID",
+			ToPhone:     "+998901234567",
+			Amount:      100.0,
+			ExpiresAt:   time.Now().Add(-time.Hour).Format(time.RFC3339),
+		})
+		r.Error(err)
+	})
+}
+
+func TestPaymentRequest_DeclinePaymentRequest(t *testing.T) {
+	r := require.New(t)
+
+	s, mock := newTestService(t)
+
+	expiresAt := time.Now().Add(time.Hour).Format(time.RFC3339)
+
+	row := sqlmock.NewRows(paymentRequestRowColumns).AddRow("TestRequestID", "TestRequesterID", "TestAccountID", "TestPayerID", nil, 100.0, "", "pending", nil, expiresAt, expiresAt, expiresAt)
+	history := sqlmock.NewRows([]string{"guid", "from_status", "to_status", "actor_id", "note", "created_at"})
+
+	mock.ExpectQuery(`^SELECT (.+?) FROM payment_requests * `).WithArgs("TestRequestID").WillReturnRows(row)
+	mock.ExpectQuery(`^SELECT (.+?) FROM payment_request_history * `).WithArgs("TestRequestID").WillReturnRows(history)
+
+	t.Run("NOT_PAYER", func(t *testing.T) {
+
+		_, err := s.DeclinePaymentRequest(context.Background(), &models.DeclinePaymentRequestRequest{
+			ID:     "TestRequestID",
+			UserID: "TestRequesterID",
+		})
+		r.IsType(&customerrors.PaymentRequestNotFoundError{}, err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,37 @@
+package pot
+
+import (
+	"context"
+
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/internal/service/payment"
+	"github.com/dilmurodov/online_banking/pkg/logger"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/dilmurodov/online_banking/storage"
+)
+
+type ServiceI interface {
+	CreatePot(ctx context.Context, req *models.CreatePotRequest) (*models.Pot, error)
+	GetPots(ctx context.Context, req *models.GetPotsRequest) (*models.GetPotsResponse, error)
+	GetPotByID(ctx context.Context, req *models.PotByIDRequest) (*models.Pot, error)
+	UpdatePot(ctx context.Context, req *models.UpdatePotRequest) (*models.Pot, error)
+	MoveToPot(ctx context.Context, req *models.PotMoveRequest) (*models.PotMoveResponse, error)
+	MoveFromPot(ctx context.Context, req *models.PotMoveRequest) (*models.PotMoveResponse, error)
+	ClosePot(ctx context.Context, req *models.PotByIDRequest) (*models.PotMoveResponse, error)
+}
+
+type Service struct {
+	cfg     config.Config
+	log     logger.LoggerI
+	strg    storage.StorageI
+	payment payment.ServiceI
+}
+
+func NewService(cfg config.Config, log logger.LoggerI, strg storage.StorageI, paymentService payment.ServiceI) *Service {
+	return &Service{
+		cfg:     cfg,
+		log:     log,
+		strg:    strg,
+		payment: paymentService,
+	}
+}
@@ -0,0 +1,501 @@
+package pot
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/internal/service/account"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/helper"
+	"github.com/dilmurodov/online_banking/pkg/interest"
+	"github.com/dilmurodov/online_banking/pkg/logger"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/dilmurodov/online_banking/pkg/util"
+)
+
+const nameMaxLength = 64
+
+// roundUpUnits are the multiples debits can be rounded up to
+var roundUpUnits = map[string]bool{"1": true, "5": true, "10": true}
+
+// CreatePot opens a pot under one of the user's accounts
+func (s *Service) CreatePot(ctx context.Context, req *models.CreatePotRequest) (*models.Pot, error) {
+	s.log.Info("---CreatePot--->", logger.Any("req", req))
+
+	pot := &models.Pot{
+		UserID:    req.UserID,
+		AccountID: req.AccountID,
+	}
+	if err := s.applySettings(pot, req.Name, req.TargetAmount, req.TargetDate, req.RoundUpTo); err != nil {
+		return nil, err
+	}
+
+	if err := account.CheckPermission(ctx, s.strg, req.AccountID, req.UserID, models.AccountPermissionManage); err != nil {
+		return nil, err
+	}
+	account, err := s.strg.Account().GetAccountByID(ctx, &models.GetAccountByIDRequest{ID: req.AccountID})
+	if err != nil {
+		return nil, &customerrors.AccountNotFoundError{Guid: req.AccountID}
+	}
+	if account.Product == models.ProductPot || account.Product == models.ProductTermDeposit {
+		return nil, fmt.Errorf("pots can only be opened under a current or savings account")
+	}
+
+	if err = s.checkRoundUpFree(ctx, pot); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.strg.Audit().BeginTx(ctx)
+	if err != nil {
+		s.log.Error("---CreatePot->BeginTx--->", logger.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	created, err := s.strg.Pot().CreatePot(ctx, tx, pot)
+	if err != nil {
+		s.log.Error("---CreatePot->CreatePot--->", logger.Error(err))
+		return nil, err
+	}
+
+	err = s.strg.Audit().CreateAuditEvent(ctx, tx, &models.AuditEvent{
+		EventType:    models.AuditEventPotCreated,
+		ActorID:      req.UserID,
+		UserID:       req.UserID,
+		ResourceType: models.AuditResourcePot,
+		ResourceID:   created.ID,
+		Details:      map[string]interface{}{"account_id": req.AccountID},
+		After:        auditSnapshot(created),
+	})
+	if err != nil {
+		s.log.Error("---CreatePot->CreateAuditEvent--->", logger.Error(err))
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.log.Error("---CreatePot->Commit--->", logger.Error(err))
+		return nil, err
+	}
+
+	return s.withProgress(created), nil
+}
+
+// GetPots lists the pots of an account with the progress towards their targets
+func (s *Service) GetPots(ctx context.Context, req *models.GetPotsRequest) (*models.GetPotsResponse, error) {
+	pots, err := s.strg.Pot().GetPots(ctx, req)
+	if err != nil {
+		s.log.Error("---GetPots--->", logger.Error(err))
+		return nil, err
+	}
+
+	saved := new(big.Rat)
+	for _, p := range pots {
+		s.withProgress(p)
+		if p.Status != models.PotStatusActive {
+			continue
+		}
+		balance, err := interest.ParseDecimal(p.Balance)
+		if err != nil {
+			return nil, err
+		}
+		saved.Add(saved, balance)
+	}
+
+	return &models.GetPotsResponse{
+		Pots:  pots,
+		Saved: interest.FormatDecimal(saved, interest.PostingScale),
+	}, nil
+}
+
+func (s *Service) GetPotByID(ctx context.Context, req *models.PotByIDRequest) (*models.Pot, error) {
+	resp, err := s.strg.Pot().GetPotByID(ctx, req)
+	if err != nil {
+		s.log.Error("---GetPotByID--->", logger.Error(err))
+		return nil, err
+	}
+	return s.withProgress(resp), nil
+}
+
+// UpdatePot replaces the name, goal and round-up rule of an active pot
+func (s *Service) UpdatePot(ctx context.Context, req *models.UpdatePotRequest) (*models.Pot, error) {
+	s.log.Info("---UpdatePot--->", logger.Any("req", req))
+
+	if err := account.CheckPermission(ctx, s.strg, req.AccountID, req.UserID, models.AccountPermissionManage); err != nil {
+		return nil, err
+	}
+	pot, err := s.strg.Pot().GetPotByID(ctx, &models.PotByIDRequest{ID: req.ID, UserID: req.UserID, AccountID: req.AccountID})
+	if err != nil {
+		return nil, err
+	}
+	if pot.Status != models.PotStatusActive {
+		return nil, fmt.Errorf("pot is closed")
+	}
+
+	if err = s.applySettings(pot, req.Name, req.TargetAmount, req.TargetDate, req.RoundUpTo); err != nil {
+		return nil, err
+	}
+	if err = s.checkRoundUpFree(ctx, pot); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.strg.Audit().BeginTx(ctx)
+	if err != nil {
+		s.log.Error("---UpdatePot->BeginTx--->", logger.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	before, err := s.strg.Pot().GetPotForUpdate(ctx, tx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if before.Status != models.PotStatusActive {
+		return nil, fmt.Errorf("pot is closed")
+	}
+	pot.Balance = before.Balance
+
+	if err = s.strg.Pot().UpdatePot(ctx, tx, pot); err != nil {
+		s.log.Error("---UpdatePot--->", logger.Error(err))
+		return nil, err
+	}
+
+	err = s.strg.Audit().CreateAuditEvent(ctx, tx, &models.AuditEvent{
+		EventType:    models.AuditEventPotUpdated,
+		ActorID:      req.UserID,
+		UserID:       req.UserID,
+		ResourceType: models.AuditResourcePot,
+		ResourceID:   pot.ID,
+		Before:       auditSnapshot(before),
+		After:        auditSnapshot(pot),
+	})
+	if err != nil {
+		s.log.Error("---UpdatePot->CreateAuditEvent--->", logger.Error(err))
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.log.Error("---UpdatePot->Commit--->", logger.Error(err))
+		return nil, err
+	}
+
+	return s.GetPotByID(ctx, &models.PotByIDRequest{ID: req.ID, UserID: req.UserID, AccountID: req.AccountID})
+}
+
+// MoveToPot puts money aside from the main balance. The move is internal, it settles instantly
+// and does not count against the transfer limits.
+func (s *Service) MoveToPot(ctx context.Context, req *models.PotMoveRequest) (*models.PotMoveResponse, error) {
+	s.log.Info("---MoveToPot--->", logger.Any("req", req))
+	return s.move(ctx, req, true)
+}
+
+// MoveFromPot returns money from the pot to the main balance
+func (s *Service) MoveFromPot(ctx context.Context, req *models.PotMoveRequest) (*models.PotMoveResponse, error) {
+	s.log.Info("---MoveFromPot--->", logger.Any("req", req))
+	return s.move(ctx, req, false)
+}
+
+func (s *Service) move(ctx context.Context, req *models.PotMoveRequest, in bool) (*models.PotMoveResponse, error) {
+	amount, err := interest.ParseAmount(req.Amount)
+	if err != nil || amount.Sign() <= 0 {
+		return nil, fmt.Errorf("amount must be positive with at most %d decimal places", interest.PostingScale)
+	}
+
+	// checks that the pot belongs to an account the user can manage
+	if err := account.CheckPermission(ctx, s.strg, req.AccountID, req.UserID, models.AccountPermissionManage); err != nil {
+		return nil, err
+	}
+	if _, err := s.strg.Pot().GetPotByID(ctx, &models.PotByIDRequest{ID: req.ID, UserID: req.UserID, AccountID: req.AccountID}); err != nil {
+		return nil, err
+	}
+
+	if in {
+		account, err := s.strg.Account().GetAccountByID(ctx, &models.GetAccountByIDRequest{ID: req.AccountID})
+		if err != nil {
+			return nil, err
+		}
+		// the posting below would let the overdraft fill the pot
+		balance := interest.Round(new(big.Rat).SetFloat64(account.Balance), interest.PostingScale)
+		if balance.Cmp(amount) < 0 {
+			return nil, &customerrors.InsufficientFundsError{
+				AccountID: account.ID,
+				Shortfall: interest.FormatDecimal(new(big.Rat).Sub(amount, balance), interest.PostingScale),
+			}
+		}
+	}
+
+	tx, err := s.strg.Audit().BeginTx(ctx)
+	if err != nil {
+		s.log.Error("---MovePot->BeginTx--->", logger.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	pot, err := s.strg.Pot().GetPotForUpdate(ctx, tx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if pot.Status != models.PotStatusActive {
+		return nil, fmt.Errorf("pot is closed")
+	}
+
+	transfer := &models.PostTransferRequest{
+		FromAccountID:     pot.AccountID,
+		ToAccountID:       pot.PotAccountID,
+		Amount:            interest.FormatDecimal(amount, interest.PostingScale),
+		Description:       "Move to pot " + pot.Name,
+		CustomerInitiated: true,
+	}
+	if !in {
+		transfer.FromAccountID, transfer.ToAccountID = pot.PotAccountID, pot.AccountID
+		transfer.Description = "Move from pot " + pot.Name
+	}
+
+	posted, err := s.payment.PostTransfer(ctx, tx, transfer)
+	if err != nil {
+		s.log.Error("---MovePot->PostTransfer--->", logger.Error(err))
+		return nil, err
+	}
+
+	balance, err := interest.ParseDecimal(pot.Balance)
+	if err != nil {
+		return nil, err
+	}
+	if in {
+		balance.Add(balance, amount)
+	} else {
+		balance.Sub(balance, amount)
+	}
+
+	err = s.strg.Audit().CreateAuditEvent(ctx, tx, &models.AuditEvent{
+		EventType:    models.AuditEventPotMoved,
+		ActorID:      req.UserID,
+		UserID:       req.UserID,
+		ResourceType: models.AuditResourcePot,
+		ResourceID:   pot.ID,
+		Details: map[string]interface{}{
+			"from_account_id": transfer.FromAccountID,
+			"to_account_id":   transfer.ToAccountID,
+			"amount":          transfer.Amount,
+		},
+		Before: map[string]interface{}{"balance": pot.Balance},
+		After:  map[string]interface{}{"balance": interest.FormatDecimal(balance, interest.PostingScale)},
+	})
+	if err != nil {
+		s.log.Error("---MovePot->CreateAuditEvent--->", logger.Error(err))
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.log.Error("---MovePot->Commit--->", logger.Error(err))
+		return nil, err
+	}
+
+	resp := &models.PotMoveResponse{Transactions: posted.Transactions}
+	resp.Pot, err = s.GetPotByID(ctx, &models.PotByIDRequest{ID: req.ID, UserID: req.UserID, AccountID: req.AccountID})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// ClosePot returns the pot's balance to the main account and closes the pot
+func (s *Service) ClosePot(ctx context.Context, req *models.PotByIDRequest) (*models.PotMoveResponse, error) {
+	s.log.Info("---ClosePot--->", logger.Any("req", req))
+
+	if err := account.CheckPermission(ctx, s.strg, req.AccountID, req.UserID, models.AccountPermissionManage); err != nil {
+		return nil, err
+	}
+	if _, err := s.strg.Pot().GetPotByID(ctx, req); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.strg.Audit().BeginTx(ctx)
+	if err != nil {
+		s.log.Error("---ClosePot->BeginTx--->", logger.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	pot, err := s.strg.Pot().GetPotForUpdate(ctx, tx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if pot.Status != models.PotStatusActive {
+		return nil, fmt.Errorf("pot is closed")
+	}
+
+	resp := &models.PotMoveResponse{Transactions: make([]*models.Transaction, 0)}
+
+	balance, err := interest.ParseDecimal(pot.Balance)
+	if err != nil {
+		return nil, err
+	}
+	if balance.Sign() > 0 {
+		posted, err := s.payment.PostTransfer(ctx, tx, &models.PostTransferRequest{
+			FromAccountID:     pot.PotAccountID,
+			ToAccountID:       pot.AccountID,
+			Amount:            interest.FormatDecimal(balance, interest.PostingScale),
+			Description:       "Move from pot " + pot.Name,
+			CustomerInitiated: true,
+		})
+		if err != nil {
+			s.log.Error("---ClosePot->PostTransfer--->", logger.Error(err))
+			return nil, err
+		}
+		resp.Transactions = posted.Transactions
+	}
+
+	if err = s.strg.Pot().ClosePot(ctx, tx, pot.ID); err != nil {
+		s.log.Error("---ClosePot->ClosePot--->", logger.Error(err))
+		return nil, err
+	}
+
+	closed := *pot
+	closed.Status = models.PotStatusClosed
+	closed.Balance = interest.FormatDecimal(new(big.Rat), interest.PostingScale)
+
+	err = s.strg.Audit().CreateAuditEvent(ctx, tx, &models.AuditEvent{
+		EventType:    models.AuditEventPotClosed,
+		ActorID:      req.UserID,
+		UserID:       req.UserID,
+		ResourceType: models.AuditResourcePot,
+		ResourceID:   pot.ID,
+		Before:       auditSnapshot(pot),
+		After:        auditSnapshot(&closed),
+	})
+	if err != nil {
+		s.log.Error("---ClosePot->CreateAuditEvent--->", logger.Error(err))
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.log.Error("---ClosePot->Commit--->", logger.Error(err))
+		return nil, err
+	}
+
+	resp.Pot, err = s.GetPotByID(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// applySettings validates the pot's name, goal and round-up rule and stores them on the pot
+func (s *Service) applySettings(pot *models.Pot, name string, targetAmount float64, targetDate string, roundUpTo float64) error {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > nameMaxLength || !util.IsValidDescription(name) {
+		return fmt.Errorf("name must be 1 to %d characters", nameMaxLength)
+	}
+	pot.Name = name
+
+	pot.TargetAmount = ""
+	if targetAmount != 0 {
+		amount, err := interest.ParseAmount(targetAmount)
+		if err != nil || amount.Sign() <= 0 {
+			return fmt.Errorf("target_amount must be positive with at most %d decimal places", interest.PostingScale)
+		}
+		pot.TargetAmount = interest.FormatDecimal(amount, interest.PostingScale)
+	}
+
+	pot.TargetDate = ""
+	if targetDate != "" {
+		date, err := time.Parse(config.BusinessDateLayout, targetDate)
+		if err != nil {
+			return fmt.Errorf("target_date must be formatted as YYYY-MM-DD")
+		}
+		if !date.After(helper.BusinessToday(s.cfg.BusinessTimezone)) {
+			return fmt.Errorf("target_date must be in the future")
+		}
+		pot.TargetDate = targetDate
+	}
+
+	pot.RoundUpTo = ""
+	if roundUpTo != 0 {
+		unit, err := interest.ParseAmount(roundUpTo)
+		if err != nil || !roundUpUnits[interest.FormatDecimal(unit, 0)] || !unit.IsInt() {
+			return fmt.Errorf("round_up_to must be 1, 5 or 10")
+		}
+		pot.RoundUpTo = interest.FormatDecimal(unit, 0)
+	}
+
+	return nil
+}
+
+// checkRoundUpFree makes sure no other pot of the account collects the round-ups
+func (s *Service) checkRoundUpFree(ctx context.Context, pot *models.Pot) error {
+	if pot.RoundUpTo == "" {
+		return nil
+	}
+	current, err := s.strg.Pot().GetRoundUpPot(ctx, pot.AccountID)
+	if err != nil {
+		return err
+	}
+	if current != nil && current.ID != pot.ID {
+		return fmt.Errorf("round-ups already go to the pot %q", current.Name)
+	}
+	return nil
+}
+
+// auditSnapshot is the state of the pot the audit log records before and after a change
+func auditSnapshot(pot *models.Pot) map[string]interface{} {
+	return map[string]interface{}{
+		"name":          pot.Name,
+		"target_amount": pot.TargetAmount,
+		"target_date":   pot.TargetDate,
+		"round_up_to":   pot.RoundUpTo,
+		"status":        pot.Status,
+		"balance":       pot.Balance,
+	}
+}
+
+// withProgress calculates how far the pot is from its target and what it takes to reach it by the target date
+func (s *Service) withProgress(pot *models.Pot) *models.Pot {
+	if pot.TargetAmount == "" {
+		return pot
+	}
+	target, err := interest.ParseDecimal(pot.TargetAmount)
+	if err != nil || target.Sign() <= 0 {
+		return pot
+	}
+	balance, err := interest.ParseDecimal(pot.Balance)
+	if err != nil {
+		return pot
+	}
+
+	remaining := new(big.Rat).Sub(target, balance)
+	if remaining.Sign() < 0 {
+		remaining.SetInt64(0)
+	}
+	progress := &models.PotProgress{
+		Percent:   interest.FormatDecimal(new(big.Rat).Mul(new(big.Rat).Quo(balance, target), big.NewRat(100, 1)), interest.PostingScale),
+		Remaining: interest.FormatDecimal(remaining, interest.PostingScale),
+	}
+
+	if pot.TargetDate != "" && pot.Status == models.PotStatusActive {
+		targetDate, err := time.Parse(config.BusinessDateLayout, pot.TargetDate)
+		today := helper.BusinessToday(s.cfg.BusinessTimezone)
+		if err == nil && targetDate.After(today) {
+			progress.DaysLeft = int(targetDate.Sub(today).Hours() / 24)
+
+			// a month started before the target date still gets its share, rounded up to the cent
+			months := 1
+			for interest.AddMonths(today, months).Before(targetDate) {
+				months++
+			}
+			progress.MonthlyNeeded = interest.FormatDecimal(
+				interest.RoundUp(new(big.Rat).Quo(remaining, big.NewRat(int64(months), 1)), big.NewRat(1, 100)),
+				interest.PostingScale,
+			)
+		}
+	}
+
+	pot.Progress = progress
+	return pot
+}
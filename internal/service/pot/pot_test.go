@@ -0,0 +1,155 @@
+package pot
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/internal/service/payment"
+	"github.com/dilmurodov/online_banking/internal/service/servicetest"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var potRowColumns = []string{
+	"guid", "user_id", "account_id", "pot_account_id", "name", "target_amount", "target_date", "round_up_to",
+	"status", "balance", "closed_at", "created_at", "updated_at",
+}
+
+func newTestService(t *testing.T) (*Service, sqlmock.Sqlmock) {
+	strg, mock := servicetest.NewStore(t)
+
+	return NewService(
+		config.Config{BusinessTimezone: "UTC"},
+		zap.NewNop(),
+		strg,
+		payment.NewService(config.Config{}, zap.NewNop(), strg),
+	), mock
+}
+
+func TestPot_GetPots(t *testing.T) {
+	r := require.New(t)
+
+	s, mock := newTestService(t)
+
+	mock.ExpectQuery(`^SELECT (.+) FROM pots p JOIN accounts a`).
+		WithArgs("TestAccountID", "TestUserID", false, pq.Array([]string{"owner", "co_owner", "signer", "viewer"})).
+		WillReturnRows(sqlmock.NewRows(potRowColumns).
+			AddRow("TestPotID1", "TestUserID", "TestAccountID", "TestPotAccountID1", "Holiday", "1200", nil, "1", "active", "300.00", nil, "2023-01-01", "2023-01-01").
+			AddRow("TestPotID2", "TestUserID", "TestAccountID", "TestPotAccountID2", "Rainy day", nil, nil, nil, "active", "50.25", nil, "2023-01-01", "2023-01-01"))
+
+	t.Run("PROGRESS", func(t *testing.T) {
+		resp, err := s.GetPots(context.Background(), &models.GetPotsRequest{
+			UserID:    "TestUserID",
+			AccountID: "TestAccountID",
+		})
+		r.NoError(err)
+		r.NoError(mock.ExpectationsWereMet())
+
+		r.Equal("350.25", resp.Saved)
+		r.Len(resp.Pots, 2)
+		r.Equal(&models.PotProgress{Percent: "25.00", Remaining: "900.00"}, resp.Pots[0].Progress)
+		// a pot without a target has no progress
+		r.Nil(resp.Pots[1].Progress)
+	})
+}
+
+func TestPot_MoveToPot_Overdraft(t *testing.T) {
+	r := require.New(t)
+
+	s, mock := newTestService(t)
+
+	mock.ExpectQuery(`^SELECT h.role FROM account_holders h`).
+		WithArgs("TestAccountID", "TestUserID").
+		WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("co_owner"))
+	mock.ExpectQuery(`^SELECT (.+) FROM pots p JOIN accounts a`).
+		WithArgs("TestPotID", "TestAccountID", "TestUserID", pq.Array([]string{"owner", "co_owner", "signer", "viewer"})).
+		WillReturnRows(sqlmock.NewRows(potRowColumns).
+			AddRow("TestPotID", "TestUserID", "TestAccountID", "TestPotAccountID", "Holiday", nil, nil, nil, "active", "0.00", nil, "2023-01-01", "2023-01-01"))
+	mock.ExpectQuery(`^SELECT (.+) FROM accounts WHERE guid=\$1`).
+		WithArgs("TestAccountID").
+		WillReturnRows(sqlmock.NewRows([]string{"guid", "user_id", "balance", "product", "accrued_interest", "overdraft_limit", "created_at", "updated_at", "status", "closed_at"}).
+			AddRow("TestAccountID", "TestUserID", 40.0, "current", "0", 500.0, "2023-01-01", "2023-01-01", "active", nil))
+
+	// the overdraft is not used to fill a pot
+	t.Run("INSUFFICIENT_FUNDS", func(t *testing.T) {
+		_, err := s.MoveToPot(context.Background(), &models.PotMoveRequest{
+			ID:        "TestPotID",
+			UserID:    "TestUserID",
+			AccountID: "TestAccountID",
+			Amount:    50,
+		})
+		r.Equal(&customerrors.InsufficientFundsError{AccountID: "TestAccountID", Shortfall: "10.00"}, err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+}
+
+func TestPot_ClosePot_Viewer(t *testing.T) {
+	r := require.New(t)
+
+	s, mock := newTestService(t)
+
+	// a viewer of a joint account sees its pots but can't touch them
+	mock.ExpectQuery(`^SELECT h.role FROM account_holders h`).
+		WithArgs("TestAccountID", "TestViewerID").
+		WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("viewer"))
+
+	t.Run("PERMISSION", func(t *testing.T) {
+		_, err := s.ClosePot(context.Background(), &models.PotByIDRequest{
+			ID:        "TestPotID",
+			UserID:    "TestViewerID",
+			AccountID: "TestAccountID",
+		})
+		r.Equal(&customerrors.AccountPermissionError{Guid: "TestAccountID"}, err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+}
+
+func TestPot_UpdatePot(t *testing.T) {
+	r := require.New(t)
+
+	s, mock := newTestService(t)
+
+	mock.ExpectQuery(`^SELECT h.role FROM account_holders h`).
+		WithArgs("TestAccountID", "TestUserID").
+		WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("owner"))
+	mock.ExpectQuery(`^SELECT (.+) FROM pots p JOIN accounts a`).
+		WithArgs("TestPotID", "TestAccountID", "TestUserID", pq.Array([]string{"owner", "co_owner", "signer", "viewer"})).
+		WillReturnRows(sqlmock.NewRows(potRowColumns).
+			AddRow("TestPotID", "TestUserID", "TestAccountID", "TestPotAccountID", "Holiday", "1200.00", nil, nil, "active", "300.00", nil, "2023-01-01", "2023-01-01"))
+	servicetest.ExpectAuditBegin(mock)
+	mock.ExpectQuery(`^SELECT (.+) FROM pots p JOIN accounts a (.+) FOR UPDATE OF p`).
+		WithArgs("TestPotID").
+		WillReturnRows(sqlmock.NewRows(potRowColumns).
+			AddRow("TestPotID", "TestUserID", "TestAccountID", "TestPotAccountID", "Holiday", "1200.00", nil, nil, "active", "300.00", nil, "2023-01-01", "2023-01-01"))
+	mock.ExpectExec(`^UPDATE pots SET`).
+		WithArgs("TestPotID", "Summer", "1500.00", nil, nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	servicetest.ExpectAuditEvent(mock, models.AuditEventPotUpdated, "TestUserID", "", []byte("null"), "TestUserID", models.AuditResourcePot, "TestPotID", "",
+		`{"balance":"300.00","name":"Holiday","round_up_to":"","status":"active","target_amount":"1200.00","target_date":""}`,
+		`{"balance":"300.00","name":"Summer","round_up_to":"","status":"active","target_amount":"1500.00","target_date":""}`,
+		"", sqlmock.AnyArg(), sqlmock.AnyArg())
+	mock.ExpectCommit()
+	mock.ExpectQuery(`^SELECT (.+) FROM pots p JOIN accounts a`).
+		WithArgs("TestPotID", "TestAccountID", "TestUserID", pq.Array([]string{"owner", "co_owner", "signer", "viewer"})).
+		WillReturnRows(sqlmock.NewRows(potRowColumns).
+			AddRow("TestPotID", "TestUserID", "TestAccountID", "TestPotAccountID", "Summer", "1500.00", nil, nil, "active", "300.00", nil, "2023-01-01", "2023-01-01"))
+
+	t.Run("SUCCESS", func(t *testing.T) {
+		resp, err := s.UpdatePot(context.Background(), &models.UpdatePotRequest{
+			ID:           "TestPotID",
+			UserID:       "TestUserID",
+			AccountID:    "TestAccountID",
+			Name:         "Summer",
+			TargetAmount: 1500,
+		})
+		r.NoError(err)
+		r.Equal("Summer", resp.Name)
+		r.Equal(&models.PotProgress{Percent: "20.00", Remaining: "1200.00"}, resp.Progress)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,166 @@
+package sanctions
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/pkg/logger"
+	"github.com/dilmurodov/online_banking/pkg/models"
+)
+
+const commentMaxLength = 255
+
+func (s *Service) GetHits(ctx context.Context, req *models.GetSanctionsHitsRequest) (*models.GetSanctionsHitsResponse, error) {
+	switch req.Status {
+	case "", models.SanctionsHitPending, models.SanctionsHitConfirmed, models.SanctionsHitDismissed:
+	default:
+		return nil, fmt.Errorf("invalid status")
+	}
+	switch req.Source {
+	case "", models.SanctionsSourceRegistration, models.SanctionsSourceProfile, models.SanctionsSourceBeneficiary:
+	default:
+		return nil, fmt.Errorf("invalid source")
+	}
+	if req.Limit <= 0 || req.Limit > config.SearchMaxLimit {
+		req.Limit = config.SearchMaxLimit
+	}
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+
+	resp, err := s.strg.Sanctions().GetSanctionsHits(ctx, req)
+	if err != nil {
+		s.log.Error("---GetSanctionsHits--->", logger.Error(err))
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *Service) GetHit(ctx context.Context, id string) (*models.SanctionsHit, error) {
+	hit, err := s.strg.Sanctions().GetSanctionsHit(ctx, id)
+	if err != nil {
+		s.log.Error("---GetSanctionsHit--->", logger.Error(err))
+		return nil, err
+	}
+	return hit, nil
+}
+
+// ReviewHit confirms a hit, which keeps the payments of the user blocked, or dismisses it as
+// someone else, which lets them through. A review can be corrected later and needs a comment.
+func (s *Service) ReviewHit(ctx context.Context, req *models.ReviewSanctionsHitRequest) (*models.SanctionsHit, error) {
+	s.log.Info("---ReviewSanctionsHit--->", logger.Any("req", req))
+
+	if req.Status != models.SanctionsHitConfirmed && req.Status != models.SanctionsHitDismissed {
+		return nil, fmt.Errorf("invalid status")
+	}
+	req.Comment = strings.TrimSpace(req.Comment)
+	if req.Comment == "" || utf8.RuneCountInString(req.Comment) > commentMaxLength {
+		return nil, fmt.Errorf("comment is required and must not be longer than %d characters", commentMaxLength)
+	}
+
+	hit, err := s.GetHit(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.strg.Audit().BeginTx(ctx)
+	if err != nil {
+		s.log.Error("---ReviewSanctionsHit->BeginTx--->", logger.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err = s.strg.Sanctions().ReviewSanctionsHit(ctx, tx, req); err != nil {
+		s.log.Error("---ReviewSanctionsHit->ReviewSanctionsHit--->", logger.Error(err))
+		return nil, err
+	}
+
+	err = s.strg.Audit().CreateAuditEvent(ctx, tx, &models.AuditEvent{
+		EventType:    models.AuditEventSanctionsHitReviewed,
+		ActorID:      req.ActorID,
+		UserID:       hit.UserID,
+		ResourceType: models.AuditResourceSanctionsHit,
+		ResourceID:   hit.ID,
+		IP:           req.IP,
+		Details:      map[string]interface{}{"comment": req.Comment, "entry_uid": hit.EntryUID},
+		Before:       map[string]interface{}{"status": hit.Status},
+		After:        map[string]interface{}{"status": req.Status},
+	})
+	if err != nil {
+		s.log.Error("---ReviewSanctionsHit->CreateAuditEvent--->", logger.Error(err))
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.log.Error("---ReviewSanctionsHit->Commit--->", logger.Error(err))
+		return nil, err
+	}
+
+	hit.Status = req.Status
+	hit.ReviewedBy = req.ActorID
+	hit.ReviewComment = req.Comment
+	return hit, nil
+}
+
+func (s *Service) GetListInfo() *models.SanctionsListInfo {
+	return s.screener.Info()
+}
+
+// Reload reads the list file again, the list in force stays in place when it is invalid
+func (s *Service) Reload() (*models.SanctionsListInfo, error) {
+	info, err := s.screener.Reload()
+	if err != nil {
+		s.log.Error("---ReloadSanctionsList--->", logger.Error(err))
+		return info, err
+	}
+	s.log.Info("---ReloadSanctionsList--->", logger.Int("entries", info.Entries))
+	return info, nil
+}
+
+// ReloadList reloads the list on behalf of a staff member and records it in the audit log
+func (s *Service) ReloadList(ctx context.Context, actorID, ip string) (*models.SanctionsListInfo, error) {
+	before := s.screener.Info()
+	after, err := s.Reload()
+	if err != nil {
+		return after, err
+	}
+
+	tx, err := s.strg.Audit().BeginTx(ctx)
+	if err != nil {
+		s.log.Error("---ReloadSanctionsList->BeginTx--->", logger.Error(err))
+		return after, err
+	}
+	defer tx.Rollback()
+
+	err = s.strg.Audit().CreateAuditEvent(ctx, tx, &models.AuditEvent{
+		EventType:    models.AuditEventSanctionsListReloaded,
+		ActorID:      actorID,
+		ResourceType: models.AuditResourceSanctionsList,
+		IP:           ip,
+		Before:       listPayload(before),
+		After:        listPayload(after),
+	})
+	if err != nil {
+		s.log.Error("---ReloadSanctionsList->CreateAuditEvent--->", logger.Error(err))
+		return after, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.log.Error("---ReloadSanctionsList->Commit--->", logger.Error(err))
+		return after, err
+	}
+
+	return after, nil
+}
+
+func listPayload(info *models.SanctionsListInfo) map[string]interface{} {
+	return map[string]interface{}{
+		"file":      info.File,
+		"entries":   info.Entries,
+		"names":     info.Names,
+		"loaded_at": info.LoadedAt,
+	}
+}
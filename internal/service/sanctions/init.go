@@ -0,0 +1,45 @@
+package sanctions
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/pkg/logger"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/dilmurodov/online_banking/pkg/sanctions"
+	"github.com/dilmurodov/online_banking/storage"
+)
+
+type ServiceI interface {
+	ScreenUser(ctx context.Context, req *models.ScreenUserRequest) ([]*models.SanctionsHit, error)
+	ScreenNewUser(ctx context.Context, tx *sql.Tx, req *models.ScreenUserRequest) ([]*models.SanctionsHit, error)
+	GetHits(ctx context.Context, req *models.GetSanctionsHitsRequest) (*models.GetSanctionsHitsResponse, error)
+	GetHit(ctx context.Context, id string) (*models.SanctionsHit, error)
+	ReviewHit(ctx context.Context, req *models.ReviewSanctionsHitRequest) (*models.SanctionsHit, error)
+	GetListInfo() *models.SanctionsListInfo
+	Reload() (*models.SanctionsListInfo, error)
+	ReloadList(ctx context.Context, actorID, ip string) (*models.SanctionsListInfo, error)
+}
+
+type Service struct {
+	cfg      config.Config
+	log      logger.LoggerI
+	strg     storage.StorageI
+	screener *sanctions.Screener
+}
+
+// NewService starts with the list from cfg.SanctionsListFile, nothing is screened when it can't be read
+func NewService(cfg config.Config, log logger.LoggerI, strg storage.StorageI) *Service {
+	screener := sanctions.NewScreener(cfg.SanctionsListFile, cfg.SanctionsMatchThreshold)
+	if _, err := screener.Reload(); err != nil {
+		log.Error("---NewService->SanctionsList--->", logger.Error(err))
+	}
+
+	return &Service{
+		cfg:      cfg,
+		log:      log,
+		strg:     strg,
+		screener: screener,
+	}
+}
@@ -0,0 +1,261 @@
+package sanctions
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/internal/service/servicetest"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const (
+	testSDNCSV = `36,"IVANOV, Ivan Petrovich","individual","SDGT] [RUSSIA-EO14024",-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,"DOB 01 Jan 1970; a.k.a. 'IVANOFF, Ivan'."
+173,"AEROCARIBBEAN AIRLINES",-0- ,"CUBA",-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,"a.k.a. 'AERO-CARIBBEAN'."
+`
+	testSDNXML = `<?xml version="1.0" standalone="yes"?>
+<sdnList xmlns="https://sanctionslistservice.ofac.treas.gov/api/PublicationPreview/exports/XML">
+  <publshInformation><Publish_Date>05/15/2023</Publish_Date><Record_Count>1</Record_Count></publshInformation>
+  <sdnEntry>
+    <uid>7157</uid>
+    <firstName>Gulnara</firstName>
+    <lastName>KARIMOVA</lastName>
+    <sdnType>Individual</sdnType>
+    <programList><program>GLOMAG</program></programList>
+    <akaList><aka><uid>9001</uid><type>a.k.a.</type><category>strong</category><firstName>Gugusha</firstName><lastName>KARIMOVA</lastName></aka></akaList>
+  </sdnEntry>
+</sdnList>
+`
+)
+
+var sanctionsHitColumns = []string{"guid", "user_id", "source", "requested_by", "screened_name", "entry_uid", "entry_name", "matched_name",
+	"programs", "score", "status", "reviewed_by", "review_comment", "reviewed_at", "created_at"}
+
+func writeList(t *testing.T, name, body string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(body), 0o600))
+	return path
+}
+
+func newTestService(t *testing.T, listFile string) (*Service, sqlmock.Sqlmock) {
+	strg, mock := servicetest.NewStore(t)
+
+	return NewService(config.Config{
+		SanctionsListFile:       listFile,
+		SanctionsMatchThreshold: 0.92,
+	}, zap.NewNop(), strg), mock
+}
+
+func TestSanctions_ScreenUser(t *testing.T) {
+	listFile := writeList(t, "sdn.csv", testSDNCSV)
+
+	t.Run("CYRILLIC_NAME", func(t *testing.T) {
+		r := require.New(t)
+		s, mock := newTestService(t, listFile)
+
+		// the name written in Cyrillic and in the other order still matches the listed one
+		mock.ExpectBegin()
+		mock.ExpectQuery(`^INSERT INTO sanctions_hits`).
+			WithArgs("TestUserID", models.SanctionsSourceRegistration, nil, "Иван Иванов", "36", "IVANOV, Ivan Petrovich", "IVANOV, Ivan Petrovich",
+				pq.Array([]string{"SDGT", "RUSSIA-EO14024"}), sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows(sanctionsHitColumns).AddRow("TestHitID", "TestUserID", models.SanctionsSourceRegistration, nil, "Иван Иванов", "36",
+				"IVANOV, Ivan Petrovich", "IVANOV, Ivan Petrovich", "{SDGT,RUSSIA-EO14024}", 1.0, models.SanctionsHitPending, nil, "", nil, "2023-05-15"))
+		mock.ExpectCommit()
+
+		hits, err := s.ScreenUser(context.Background(), &models.ScreenUserRequest{
+			UserID: "TestUserID",
+			Name:   "Иван Иванов",
+			Source: models.SanctionsSourceRegistration,
+		})
+		r.NoError(err)
+		r.Len(hits, 1)
+		r.Equal([]string{"SDGT", "RUSSIA-EO14024"}, hits[0].Programs)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("BENEFICIARY_OWNER", func(t *testing.T) {
+		r := require.New(t)
+		s, mock := newTestService(t, listFile)
+
+		// the owner's name is looked up, a hit already stored for the entry is not stored again
+		mock.ExpectQuery(`^SELECT (.+?) FROM "users"`).WithArgs("TestOwnerID").
+			WillReturnRows(sqlmock.NewRows([]string{"guid", "first_name", "last_name", "phone", "created_at", "updated_at", "sessions_revoked_at", "two_factor_enabled", "role"}).
+				AddRow("TestOwnerID", "Ivan", "Ivanoff", "+998901234567", "2023-05-15", "2023-05-15", 0, false, models.RoleCustomer))
+		mock.ExpectBegin()
+		mock.ExpectQuery(`^INSERT INTO sanctions_hits`).
+			WithArgs("TestOwnerID", models.SanctionsSourceBeneficiary, "TestUserID", "Ivan Ivanoff", "36", "IVANOV, Ivan Petrovich", "IVANOFF, Ivan",
+				pq.Array([]string{"SDGT", "RUSSIA-EO14024"}), sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows(sanctionsHitColumns))
+		mock.ExpectCommit()
+
+		hits, err := s.ScreenUser(context.Background(), &models.ScreenUserRequest{
+			UserID:      "TestOwnerID",
+			Source:      models.SanctionsSourceBeneficiary,
+			RequestedBy: "TestUserID",
+		})
+		r.NoError(err)
+		r.Empty(hits)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("NO_MATCH", func(t *testing.T) {
+		r := require.New(t)
+		s, mock := newTestService(t, listFile)
+
+		for _, name := range []string{"Alisher Navoiy", "Ivan Sidorov", "Ivan"} {
+			hits, err := s.ScreenUser(context.Background(), &models.ScreenUserRequest{
+				UserID: "TestUserID",
+				Name:   name,
+				Source: models.SanctionsSourceRegistration,
+			})
+			r.NoError(err)
+			r.Empty(hits, name)
+		}
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("NO_LIST", func(t *testing.T) {
+		r := require.New(t)
+		s, mock := newTestService(t, "")
+
+		hits, err := s.ScreenUser(context.Background(), &models.ScreenUserRequest{
+			UserID: "TestUserID",
+			Source: models.SanctionsSourceBeneficiary,
+		})
+		r.NoError(err)
+		r.Empty(hits)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+}
+
+func TestSanctions_ReloadList(t *testing.T) {
+	r := require.New(t)
+
+	listFile := writeList(t, "sdn.xml", testSDNXML)
+	s, mock := newTestService(t, listFile)
+
+	info := s.GetListInfo()
+	r.Equal(1, info.Entries)
+	r.Equal(2, info.Names)
+	r.NotEmpty(info.LoadedAt)
+
+	t.Run("XML_ENTRY", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`^INSERT INTO sanctions_hits`).
+			WithArgs("TestUserID", models.SanctionsSourceProfile, nil, "Gulnora Karimova", "7157", "Gulnara KARIMOVA", "Gulnara KARIMOVA",
+				pq.Array([]string{"GLOMAG"}), sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows(sanctionsHitColumns))
+		mock.ExpectCommit()
+
+		_, err := s.ScreenUser(context.Background(), &models.ScreenUserRequest{
+			UserID: "TestUserID",
+			Name:   "Gulnora Karimova",
+			Source: models.SanctionsSourceProfile,
+		})
+		r.NoError(err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("INVALID_FILE", func(t *testing.T) {
+		// a broken upload fails the reload and the list in force stays
+		r.NoError(os.WriteFile(listFile, []byte("<sdnList><sdnEntry>"), 0o600))
+
+		_, err := s.ReloadList(context.Background(), "TestAdminID", "127.0.0.1")
+		r.Error(err)
+		r.Equal(1, s.GetListInfo().Entries)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("SUCCESS", func(t *testing.T) {
+		updated := strings.Replace(testSDNXML, "</sdnList>", `  <sdnEntry>
+    <uid>7158</uid>
+    <lastName>ZAMIN TRADE LLC</lastName>
+    <sdnType>Entity</sdnType>
+    <programList><program>SDGT</program></programList>
+  </sdnEntry>
+</sdnList>`, 1)
+		r.NoError(os.WriteFile(listFile, []byte(updated), 0o600))
+
+		servicetest.ExpectAuditBegin(mock)
+		servicetest.ExpectAuditEvent(mock, models.AuditEventSanctionsListReloaded, nil, "127.0.0.1", []byte(`null`), "TestAdminID",
+			models.AuditResourceSanctionsList, "", "", sqlmock.AnyArg(), sqlmock.AnyArg(), "", sqlmock.AnyArg(), sqlmock.AnyArg())
+		mock.ExpectCommit()
+
+		info, err := s.ReloadList(context.Background(), "TestAdminID", "127.0.0.1")
+		r.NoError(err)
+		r.Equal(2, info.Entries)
+		r.Equal(3, info.Names)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+}
+
+func TestSanctions_ReviewHit(t *testing.T) {
+	hitRow := func(status string) *sqlmock.Rows {
+		return sqlmock.NewRows(sanctionsHitColumns).AddRow("TestHitID", "TestUserID", models.SanctionsSourceRegistration, nil, "Ivan Ivanov", "36",
+			"IVANOV, Ivan Petrovich", "IVANOV, Ivan Petrovich", "{SDGT}", 1.0, status, nil, "", nil, "2023-05-15")
+	}
+
+	t.Run("DISMISSED", func(t *testing.T) {
+		r := require.New(t)
+		s, mock := newTestService(t, "")
+
+		mock.ExpectQuery(`^SELECT (.+?) FROM sanctions_hits`).WithArgs("TestHitID").WillReturnRows(hitRow(models.SanctionsHitPending))
+		servicetest.ExpectAuditBegin(mock)
+		mock.ExpectExec(`^UPDATE sanctions_hits SET`).
+			WithArgs("TestHitID", models.SanctionsHitDismissed, "TestOfficerID", "Different date of birth").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		servicetest.ExpectAuditEvent(mock, models.AuditEventSanctionsHitReviewed, "TestUserID", "127.0.0.1",
+			[]byte(`{"comment":"Different date of birth","entry_uid":"36"}`), "TestOfficerID", models.AuditResourceSanctionsHit, "TestHitID", "",
+			`{"status":"pending"}`, `{"status":"dismissed"}`, "", sqlmock.AnyArg(), sqlmock.AnyArg())
+		mock.ExpectCommit()
+
+		hit, err := s.ReviewHit(context.Background(), &models.ReviewSanctionsHitRequest{
+			ID:      "TestHitID",
+			ActorID: "TestOfficerID",
+			IP:      "127.0.0.1",
+			Status:  models.SanctionsHitDismissed,
+			Comment: " Different date of birth ",
+		})
+		r.NoError(err)
+		r.Equal(models.SanctionsHitDismissed, hit.Status)
+		r.Equal("TestOfficerID", hit.ReviewedBy)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("WITHOUT_COMMENT", func(t *testing.T) {
+		r := require.New(t)
+		s, mock := newTestService(t, "")
+
+		_, err := s.ReviewHit(context.Background(), &models.ReviewSanctionsHitRequest{
+			ID:      "TestHitID",
+			ActorID: "TestOfficerID",
+			Status:  models.SanctionsHitConfirmed,
+		})
+		r.Error(err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("NOT_FOUND", func(t *testing.T) {
+		r := require.New(t)
+		s, mock := newTestService(t, "")
+
+		mock.ExpectQuery(`^SELECT (.+?) FROM sanctions_hits`).WithArgs("TestHitID").WillReturnRows(sqlmock.NewRows(sanctionsHitColumns))
+
+		_, err := s.ReviewHit(context.Background(), &models.ReviewSanctionsHitRequest{
+			ID:      "TestHitID",
+			ActorID: "TestOfficerID",
+			Status:  models.SanctionsHitConfirmed,
+			Comment: "Same passport number",
+		})
+		r.Equal(&customerrors.SanctionsHitNotFoundError{Guid: "TestHitID"}, err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+}
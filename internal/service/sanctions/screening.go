@@ -0,0 +1,98 @@
+package sanctions
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/dilmurodov/online_banking/pkg/logger"
+	"github.com/dilmurodov/online_banking/pkg/models"
+)
+
+// ScreenUser matches the name of the user against the list and stores the hits for review.
+// The name is looked up when the request has none. Until a hit is dismissed the payments
+// of the user are blocked.
+func (s *Service) ScreenUser(ctx context.Context, req *models.ScreenUserRequest) ([]*models.SanctionsHit, error) {
+	hits, err := s.match(ctx, req)
+	if err != nil || len(hits) == 0 {
+		return hits, err
+	}
+
+	tx, err := s.strg.TxRepo().BeginTx(ctx)
+	if err != nil {
+		s.log.Error("---ScreenUser->BeginTx--->", logger.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	created, err := s.storeHits(ctx, tx, hits)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.log.Error("---ScreenUser->Commit--->", logger.Error(err))
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// ScreenNewUser screens a user created in the caller's transaction, the user is saved with its hits or not at all
+func (s *Service) ScreenNewUser(ctx context.Context, tx *sql.Tx, req *models.ScreenUserRequest) ([]*models.SanctionsHit, error) {
+	hits, err := s.match(ctx, req)
+	if err != nil || len(hits) == 0 {
+		return hits, err
+	}
+
+	return s.storeHits(ctx, tx, hits)
+}
+
+// match returns the hits of the user's name on the list, nothing is stored yet
+func (s *Service) match(ctx context.Context, req *models.ScreenUserRequest) ([]*models.SanctionsHit, error) {
+	if s.screener.Info().Entries == 0 {
+		return []*models.SanctionsHit{}, nil
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		user, err := s.strg.User().GetUserByID(ctx, &models.GetUserByIDRequest{UserId: req.UserID})
+		if err != nil {
+			s.log.Error("---ScreenUser->GetUserByID--->", logger.Error(err))
+			return nil, err
+		}
+		name = strings.TrimSpace(user.User.FirstName + " " + user.User.LastName)
+	}
+
+	matches := s.screener.Screen(name)
+	hits := make([]*models.SanctionsHit, 0, len(matches))
+	for _, m := range matches {
+		hits = append(hits, &models.SanctionsHit{
+			UserID:       req.UserID,
+			Source:       req.Source,
+			RequestedBy:  req.RequestedBy,
+			ScreenedName: name,
+			EntryUID:     m.EntryUID,
+			EntryName:    m.EntryName,
+			MatchedName:  m.MatchedName,
+			Programs:     m.Programs,
+			Score:        m.Score,
+		})
+	}
+
+	return hits, nil
+}
+
+// storeHits saves the hits in the transaction and returns the ones that are new
+func (s *Service) storeHits(ctx context.Context, tx *sql.Tx, hits []*models.SanctionsHit) ([]*models.SanctionsHit, error) {
+	created, err := s.strg.Sanctions().CreateSanctionsHits(ctx, tx, hits)
+	if err != nil {
+		s.log.Error("---ScreenUser->CreateSanctionsHits--->", logger.Error(err))
+		return nil, err
+	}
+	for _, h := range created {
+		s.log.Info("---ScreenUser->Hit--->", logger.String("user_id", h.UserID), logger.String("entry_uid", h.EntryUID), logger.Any("score", h.Score))
+	}
+
+	return created, nil
+}
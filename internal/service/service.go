@@ -3,9 +3,25 @@ package service
 import (
 	"github.com/dilmurodov/online_banking/config"
 	"github.com/dilmurodov/online_banking/internal/service/account"
+	"github.com/dilmurodov/online_banking/internal/service/accountstatus"
+	"github.com/dilmurodov/online_banking/internal/service/admin"
+	"github.com/dilmurodov/online_banking/internal/service/aml"
+	"github.com/dilmurodov/online_banking/internal/service/audit"
+	"github.com/dilmurodov/online_banking/internal/service/beneficiary"
+	"github.com/dilmurodov/online_banking/internal/service/deposit"
+	"github.com/dilmurodov/online_banking/internal/service/interest"
+	"github.com/dilmurodov/online_banking/internal/service/kyc"
+	"github.com/dilmurodov/online_banking/internal/service/loan"
+	"github.com/dilmurodov/online_banking/internal/service/overdraft"
 	payment "github.com/dilmurodov/online_banking/internal/service/payment"
+	"github.com/dilmurodov/online_banking/internal/service/paymentrequest"
+	"github.com/dilmurodov/online_banking/internal/service/pot"
+	"github.com/dilmurodov/online_banking/internal/service/sanctions"
 	"github.com/dilmurodov/online_banking/internal/service/user"
+	"github.com/dilmurodov/online_banking/pkg/blob"
 	"github.com/dilmurodov/online_banking/pkg/logger"
+	"github.com/dilmurodov/online_banking/pkg/security"
+	"github.com/dilmurodov/online_banking/pkg/sms"
 	"github.com/dilmurodov/online_banking/storage"
 )
 
@@ -13,24 +29,76 @@ type ServiceManagerI interface {
 	UserService() user.ServiceI
 	AccountService() account.ServiceI
 	PaymentService() payment.ServiceI
+	PaymentRequestService() paymentrequest.ServiceI
+	BeneficiaryService() beneficiary.ServiceI
+	InterestService() interest.ServiceI
+	DepositService() deposit.ServiceI
+	OverdraftService() overdraft.ServiceI
+	LoanService() loan.ServiceI
+	PotService() pot.ServiceI
+	AccountStatusService() accountstatus.ServiceI
+	AdminService() admin.ServiceI
+	AuditService() audit.ServiceI
+	AMLService() aml.ServiceI
+	SanctionsService() sanctions.ServiceI
+	KYCService() kyc.ServiceI
 }
 
 type serviceManager struct {
-	userService    user.ServiceI
-	accountService account.ServiceI
-	paymentService payment.ServiceI
+	userService           user.ServiceI
+	accountService        account.ServiceI
+	paymentService        payment.ServiceI
+	paymentRequestService paymentrequest.ServiceI
+	beneficiaryService    beneficiary.ServiceI
+	interestService       interest.ServiceI
+	depositService        deposit.ServiceI
+	overdraftService      overdraft.ServiceI
+	loanService           loan.ServiceI
+	potService            pot.ServiceI
+	accountStatusService  accountstatus.ServiceI
+	adminService          admin.ServiceI
+	auditService          audit.ServiceI
+	amlService            aml.ServiceI
+	sanctionsService      sanctions.ServiceI
+	kycService            kyc.ServiceI
 }
 
-func NewServiceManager(cfg config.Config, log logger.LoggerI, strg storage.StorageI) ServiceManagerI {
+func NewServiceManager(cfg config.Config, log logger.LoggerI, strg storage.StorageI, smsSender sms.SenderI, passwordPolicy *security.PasswordPolicy, blobStore blob.StoreI) ServiceManagerI {
 
-	userService := user.NewService(cfg, log, strg)
 	accountService := account.NewService(cfg, log, strg)
 	paymentService := payment.NewService(cfg, log, strg)
+	paymentRequestService := paymentrequest.NewService(cfg, log, strg, paymentService)
+	sanctionsService := sanctions.NewService(cfg, log, strg)
+	beneficiaryService := beneficiary.NewService(cfg, log, strg, sanctionsService)
+	interestService := interest.NewService(cfg, log, strg, paymentService)
+	depositService := deposit.NewService(cfg, log, strg, paymentService)
+	overdraftService := overdraft.NewService(cfg, log, strg, paymentService)
+	loanService := loan.NewService(cfg, log, strg, paymentService)
+	potService := pot.NewService(cfg, log, strg, paymentService)
+	accountStatusService := accountstatus.NewService(cfg, log, strg, paymentService)
+	adminService := admin.NewService(cfg, log, strg, paymentService, accountStatusService)
+	auditService := audit.NewService(cfg, log, strg)
+	amlService := aml.NewService(cfg, log, strg)
+	kycService := kyc.NewService(cfg, log, strg, blobStore)
+	userService := user.NewService(cfg, log, strg, smsSender, passwordPolicy, accountStatusService, sanctionsService)
 
 	return &serviceManager{
-		userService:    userService,
-		accountService: accountService,
-		paymentService: paymentService,
+		userService:           userService,
+		accountService:        accountService,
+		paymentService:        paymentService,
+		paymentRequestService: paymentRequestService,
+		beneficiaryService:    beneficiaryService,
+		interestService:       interestService,
+		depositService:        depositService,
+		overdraftService:      overdraftService,
+		loanService:           loanService,
+		potService:            potService,
+		accountStatusService:  accountStatusService,
+		adminService:          adminService,
+		auditService:          auditService,
+		amlService:            amlService,
+		sanctionsService:      sanctionsService,
+		kycService:            kycService,
 	}
 }
 
@@ -45,3 +113,55 @@ func (s *serviceManager) AccountService() account.ServiceI {
 func (s *serviceManager) PaymentService() payment.ServiceI {
 	return s.paymentService
 }
+
+func (s *serviceManager) PaymentRequestService() paymentrequest.ServiceI {
+	return s.paymentRequestService
+}
+
+func (s *serviceManager) BeneficiaryService() beneficiary.ServiceI {
+	return s.beneficiaryService
+}
+
+func (s *serviceManager) InterestService() interest.ServiceI {
+	return s.interestService
+}
+
+func (s *serviceManager) DepositService() deposit.ServiceI {
+	return s.depositService
+}
+
+func (s *serviceManager) OverdraftService() overdraft.ServiceI {
+	return s.overdraftService
+}
+
+func (s *serviceManager) LoanService() loan.ServiceI {
+	return s.loanService
+}
+
+func (s *serviceManager) PotService() pot.ServiceI {
+	return s.potService
+}
+
+func (s *serviceManager) AccountStatusService() accountstatus.ServiceI {
+	return s.accountStatusService
+}
+
+func (s *serviceManager) AdminService() admin.ServiceI {
+	return s.adminService
+}
+
+func (s *serviceManager) AuditService() audit.ServiceI {
+	return s.auditService
+}
+
+func (s *serviceManager) AMLService() aml.ServiceI {
+	return s.amlService
+}
+
+func (s *serviceManager) SanctionsService() sanctions.ServiceI {
+	return s.sanctionsService
+}
+
+func (s *serviceManager) KYCService() kyc.ServiceI {
+	return s.kycService
+}
@@ -0,0 +1,57 @@
+// Package servicetest holds the sqlmock fixtures the service tests share
+package servicetest
+
+import (
+	"database/sql/driver"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dilmurodov/online_banking/storage/postgres"
+	"github.com/stretchr/testify/require"
+)
+
+// TransactionRowColumns are the columns CreateTransaction returns
+var TransactionRowColumns = []string{"guid", "transaction_amount", "transaction_type", "recipient_id", "description", "reference", "created_at"}
+
+// NewStore returns a postgres store over a mocked database
+func NewStore(t *testing.T) (*postgres.Store, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	return postgres.NewStore(db), mock
+}
+
+// ExpectAuditBegin expects a transaction begun to append to the audit log
+func ExpectAuditBegin(mock sqlmock.Sqlmock) {
+	mock.ExpectBegin()
+	mock.ExpectExec(`^LOCK TABLE audit_events`).WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+// ExpectAuditEvent expects an event appended to the audit log as the first one of the chain.
+// The args are the ones of the insert, none accepts any
+func ExpectAuditEvent(mock sqlmock.Sqlmock, args ...driver.Value) {
+	mock.ExpectQuery(`^SELECT hash FROM audit_events`).WillReturnRows(sqlmock.NewRows([]string{"hash"}))
+	insert := mock.ExpectQuery(`^INSERT INTO audit_events`)
+	if len(args) > 0 {
+		insert.WithArgs(args...)
+	}
+	insert.WillReturnRows(sqlmock.NewRows([]string{"guid", "seq"}).AddRow("TestEventID", 1))
+}
+
+// ExpectPosting expects the debit and the credit of a settled transfer, delta is the amount as the balance update sends it
+func ExpectPosting(mock sqlmock.Sqlmock, from, to string, amount float64, delta, description string, reference driver.Value) {
+	rowReference, _ := reference.(string)
+
+	mock.ExpectQuery(`^SELECT status FROM accounts`).WithArgs(from).WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("active"))
+	mock.ExpectPrepare("INSERT INTO transactions").ExpectQuery().
+		WithArgs(from, amount, to, "debit", description, reference).
+		WillReturnRows(sqlmock.NewRows(TransactionRowColumns).AddRow("TestDebitID", amount, "debit", to, description, rowReference, "2023-05-15"))
+	mock.ExpectExec(`^UPDATE transactions SET`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`^UPDATE accounts SET balance = balance`).WithArgs("-"+delta, from).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`^SELECT status FROM accounts`).WithArgs(to).WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("active"))
+	mock.ExpectPrepare("INSERT INTO transactions").ExpectQuery().
+		WithArgs(to, amount, from, "credit", description, reference).
+		WillReturnRows(sqlmock.NewRows(TransactionRowColumns).AddRow("TestCreditID", amount, "credit", from, description, rowReference, "2023-05-15"))
+	mock.ExpectExec(`^UPDATE transactions SET`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`^UPDATE accounts SET balance = balance`).WithArgs(delta, to).WillReturnResult(sqlmock.NewResult(0, 1))
+}
@@ -4,28 +4,56 @@ import (
 	"context"
 
 	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/internal/service/accountstatus"
+	"github.com/dilmurodov/online_banking/internal/service/sanctions"
 	"github.com/dilmurodov/online_banking/pkg/logger"
 	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/dilmurodov/online_banking/pkg/security"
+	"github.com/dilmurodov/online_banking/pkg/sms"
 	"github.com/dilmurodov/online_banking/storage"
 )
 
 type ServiceI interface {
 	GetUserByID(context.Context, *models.GetUserByIDRequest) (*models.GetUserByIDResponse, error)
 	CreateUser(context.Context, *models.CreateUserRequest) (*models.User, error)
+	HashNewPassword(phone, password string) (string, error)
 	GetUserByCredentials(ctx context.Context, req *models.GetByCredentialsRequest) (*models.User, error)
 	GetUserPasswordByPhone(ctx context.Context, phone string) (resp *models.User, err error)
+	SetDefaultAccount(ctx context.Context, req *models.SetDefaultAccountRequest) error
+	UpdateProfile(ctx context.Context, req *models.UpdateProfileRequest) (*models.User, error)
+	ChangePassword(ctx context.Context, req *models.ChangePasswordRequest) error
+	RequestPhoneChange(ctx context.Context, req *models.ChangePhoneRequest) (*models.ChangePhoneResponse, error)
+	ConfirmPhoneChange(ctx context.Context, req *models.ConfirmPhoneRequest) (*models.User, error)
+	DeleteUser(ctx context.Context, req *models.DeleteUserRequest) error
+	ForgotPassword(ctx context.Context, req *models.ForgotPasswordRequest) error
+	ResetPassword(ctx context.Context, req *models.ResetPasswordRequest) error
+	EnrollTOTP(ctx context.Context, req *models.EnrollTOTPRequest) (*models.TOTPEnrollment, error)
+	ConfirmTOTP(ctx context.Context, req *models.ConfirmTOTPRequest) (*models.RecoveryCodesResponse, error)
+	RegenerateRecoveryCodes(ctx context.Context, req *models.StepUpRequest) (*models.RecoveryCodesResponse, error)
+	DisableTOTP(ctx context.Context, req *models.DisableTOTPRequest) error
+	VerifyMFA(ctx context.Context, userID, code string) error
+	SendPaymentChallenge(ctx context.Context, userID, decisionID string) (*models.FraudChallenge, error)
+	ConfirmPaymentChallenge(ctx context.Context, req *models.ConfirmFraudChallengeRequest) error
 }
 
 type Service struct {
-	cfg  config.Config
-	log  logger.LoggerI
-	strg storage.StorageI
+	cfg           config.Config
+	log           logger.LoggerI
+	strg          storage.StorageI
+	sms           sms.SenderI
+	password      *security.PasswordPolicy
+	accountStatus accountstatus.ServiceI
+	sanctions     sanctions.ServiceI
 }
 
-func NewService(cfg config.Config, log logger.LoggerI, strg storage.StorageI) *Service {
+func NewService(cfg config.Config, log logger.LoggerI, strg storage.StorageI, smsSender sms.SenderI, passwordPolicy *security.PasswordPolicy, accountStatusService accountstatus.ServiceI, sanctionsService sanctions.ServiceI) *Service {
 	return &Service{
-		cfg:  cfg,
-		log:  log,
-		strg: strg,
+		cfg:           cfg,
+		log:           log,
+		strg:          strg,
+		sms:           smsSender,
+		password:      passwordPolicy,
+		accountStatus: accountStatusService,
+		sanctions:     sanctionsService,
 	}
 }
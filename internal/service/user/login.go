@@ -0,0 +1,116 @@
+package user
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/pkg/logger"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/dilmurodov/online_banking/pkg/security"
+)
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     string
+)
+
+// dummyPasswordHash is compared against for unknown phones, so they take as long as a wrong password
+func dummyPasswordHash(policy *security.PasswordPolicy) string {
+	dummyHashOnce.Do(func() {
+		password, err := security.GenerateRandomString(16)
+		if err == nil {
+			dummyHash, _ = policy.Hash(password)
+		}
+	})
+	return dummyHash
+}
+
+// loginKeys are the counters a login is checked against, the phone always comes first
+func loginKeys(req *models.GetByCredentialsRequest) []string {
+	keys := []string{"phone:" + req.Phone}
+	if req.IP != "" {
+		keys = append(keys, "ip:"+req.IP)
+	}
+	return keys
+}
+
+// loginFailed counts the failure for every key, makes the next attempt wait and locks out the keys
+// that failed too often. A lockout is written to the audit log.
+func (self *Service) loginFailed(ctx context.Context, req *models.GetByCredentialsRequest, userID string, keys []string) error {
+	tx, err := self.strg.Audit().BeginTx(ctx)
+	if err != nil {
+		self.log.Error("---LoginFailed->BeginTx--->", logger.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	for i, key := range keys {
+		failures, err := self.strg.LoginFailure().RecordLoginFailure(ctx, tx, key, config.LoginFailureWindow)
+		if err != nil {
+			self.log.Error("---LoginFailed->RecordLoginFailure--->", logger.Error(err))
+			return err
+		}
+
+		lockoutAfter := config.LoginPhoneLockoutAfter
+		if i > 0 {
+			lockoutAfter = config.LoginIPLockoutAfter
+		}
+		delay, lockedOut := loginDelay(failures, lockoutAfter)
+		if delay == 0 {
+			continue
+		}
+
+		if err = self.strg.LoginFailure().LockLogin(ctx, tx, key, delay); err != nil {
+			self.log.Error("---LoginFailed->LockLogin--->", logger.Error(err))
+			return err
+		}
+
+		if lockedOut {
+			self.log.Info("---LoginFailed->LockedOut--->", logger.String("key", key), logger.Int("failures", failures))
+			err = self.strg.Audit().CreateAuditEvent(ctx, tx, &models.AuditEvent{
+				EventType: models.AuditEventLoginLocked,
+				UserID:    userID,
+				IP:        req.IP,
+				Details: map[string]interface{}{
+					"key":      key,
+					"failures": failures,
+					"seconds":  int(delay.Seconds()),
+				},
+			})
+			if err != nil {
+				self.log.Error("---LoginFailed->CreateAuditEvent--->", logger.Error(err))
+				return err
+			}
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		self.log.Error("---LoginFailed->Commit--->", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// loginDelay is how long the key waits after its failures, doubling from LoginDelayBase
+// until the lockout takes over
+func loginDelay(failures, lockoutAfter int) (time.Duration, bool) {
+	if failures >= lockoutAfter {
+		return config.LoginLockoutDuration, true
+	}
+	if failures < config.LoginDelayAfter {
+		return 0, false
+	}
+
+	delay := config.LoginDelayBase
+	for i := config.LoginDelayAfter; i < failures && delay < config.LoginDelayMax; i++ {
+		delay *= 2
+	}
+	if delay > config.LoginDelayMax {
+		delay = config.LoginDelayMax
+	}
+
+	return delay, false
+}
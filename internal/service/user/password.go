@@ -0,0 +1,96 @@
+package user
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/logger"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/dilmurodov/online_banking/pkg/util"
+)
+
+// ForgotPassword texts a reset code to the phone. An unknown phone gets the same answer,
+// so the endpoint can't be used to find out who banks with us.
+func (self *Service) ForgotPassword(ctx context.Context, req *models.ForgotPasswordRequest) error {
+	self.log.Info("---ForgotPassword--->", logger.Any("req", req))
+
+	if !util.IsValidPhone(req.Phone) {
+		return fmt.Errorf("invalid phone number")
+	}
+
+	user, err := self.strg.User().GetUserPasswordByPhone(ctx, req.Phone)
+	if _, ok := err.(*customerrors.UserNotFoundWithPhoneError); ok {
+		return nil
+	} else if err != nil {
+		self.log.Error("---ForgotPassword->GetUserPasswordByPhone--->", logger.Error(err))
+		return err
+	}
+
+	_, err = self.sendOTP(ctx, user.Guid, models.OTPPurposePasswordReset, req.Phone, req.Phone, "Код для восстановления пароля: %s. Никому его не сообщайте.")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ResetPassword sets a new password with the code sent by ForgotPassword and signs the user out everywhere
+func (self *Service) ResetPassword(ctx context.Context, req *models.ResetPasswordRequest) error {
+	self.log.Info("---ResetPassword--->", logger.String("phone", req.Phone))
+
+	// a refused password must not burn the code
+	if err := self.password.Check(req.NewPassword, req.Phone); err != nil {
+		return err
+	}
+
+	user, err := self.strg.User().GetUserPasswordByPhone(ctx, req.Phone)
+	if _, ok := err.(*customerrors.UserNotFoundWithPhoneError); ok {
+		return &customerrors.InvalidOTPError{}
+	} else if err != nil {
+		self.log.Error("---ResetPassword->GetUserPasswordByPhone--->", logger.Error(err))
+		return err
+	}
+
+	tx, err := self.strg.Audit().BeginTx(ctx)
+	if err != nil {
+		self.log.Error("---ResetPassword->BeginTx--->", logger.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = self.useOTP(ctx, tx, user.Guid, models.OTPPurposePasswordReset, req.Code); err != nil {
+		return err
+	}
+
+	hashedPassword, err := self.password.Hash(req.NewPassword)
+	if err != nil {
+		self.log.Error("---ResetPassword->Hash--->", logger.Error(err))
+		return err
+	}
+
+	if err = self.strg.User().ResetUserPassword(ctx, tx, user.Guid, hashedPassword); err != nil {
+		self.log.Error("---ResetPassword->ResetUserPassword--->", logger.Error(err))
+		return err
+	}
+
+	err = self.strg.Audit().CreateAuditEvent(ctx, tx, &models.AuditEvent{
+		EventType:    models.AuditEventPasswordReset,
+		ActorID:      user.Guid,
+		UserID:       user.Guid,
+		ResourceType: models.AuditResourceUser,
+		ResourceID:   user.Guid,
+		Details:      map[string]interface{}{"sessions_revoked": true},
+	})
+	if err != nil {
+		self.log.Error("---ResetPassword->CreateAuditEvent--->", logger.Error(err))
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		self.log.Error("---ResetPassword->Commit--->", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package user
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/logger"
+	"github.com/dilmurodov/online_banking/pkg/models"
+)
+
+// SendPaymentChallenge texts the user a code for a payment the fraud checks challenged,
+// the code is bound to the decision
+func (self *Service) SendPaymentChallenge(ctx context.Context, userID, decisionID string) (*models.FraudChallenge, error) {
+	self.log.Info("---SendPaymentChallenge--->", logger.String("user_id", userID), logger.String("decision_id", decisionID))
+
+	decision, err := self.strg.Fraud().GetFraudDecision(ctx, decisionID)
+	if err != nil {
+		self.log.Error("---SendPaymentChallenge->GetFraudDecision--->", logger.Error(err))
+		return nil, err
+	}
+	if decision.UserID != userID || decision.ChallengeStatus != models.FraudChallengePending {
+		return nil, &customerrors.FraudChallengeInvalidError{}
+	}
+
+	user, err := self.strg.User().GetUserByID(ctx, &models.GetUserByIDRequest{UserId: userID})
+	if err != nil {
+		self.log.Error("---SendPaymentChallenge->GetUserByID--->", logger.Error(err))
+		return nil, err
+	}
+
+	text := fmt.Sprintf("Код для подтверждения платежа на сумму %s: %%s. Никому его не сообщайте.", decision.Amount)
+	otp, err := self.sendOTP(ctx, userID, models.OTPPurposePaymentChallenge, decision.ID, user.User.Phone, text)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.FraudChallenge{
+		DecisionID: decision.ID,
+		ExpiresAt:  otp.ExpiresAt,
+		Message:    (&customerrors.FraudChallengeRequiredError{}).Error(),
+	}, nil
+}
+
+// ConfirmPaymentChallenge checks the code sent for the challenge, the payment can be retried with the decision id after it
+func (self *Service) ConfirmPaymentChallenge(ctx context.Context, req *models.ConfirmFraudChallengeRequest) error {
+	self.log.Info("---ConfirmPaymentChallenge--->", logger.String("user_id", req.UserID), logger.String("decision_id", req.DecisionID))
+
+	tx, err := self.strg.TxRepo().BeginTx(ctx)
+	if err != nil {
+		self.log.Error("---ConfirmPaymentChallenge->BeginTx--->", logger.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	otp, err := self.useOTP(ctx, tx, req.UserID, models.OTPPurposePaymentChallenge, req.Code)
+	if err != nil {
+		return err
+	}
+	// only the code sent last is active, it has to be the one sent for this decision
+	if otp.Target != req.DecisionID {
+		return &customerrors.FraudChallengeInvalidError{}
+	}
+
+	if err = self.strg.Fraud().PassFraudChallenge(ctx, tx, req.DecisionID, req.UserID); err != nil {
+		self.log.Error("---ConfirmPaymentChallenge->PassFraudChallenge--->", logger.Error(err))
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		self.log.Error("---ConfirmPaymentChallenge->Commit--->", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,322 @@
+package user
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/logger"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/dilmurodov/online_banking/pkg/security"
+	"github.com/dilmurodov/online_banking/pkg/util"
+)
+
+const nameMaxLength = 64
+
+// UpdateProfile changes the user's first and last name
+func (self *Service) UpdateProfile(ctx context.Context, req *models.UpdateProfileRequest) (*models.User, error) {
+	self.log.Info("---UpdateProfile--->", logger.Any("req", req))
+
+	if req.FirstName == nil && req.LastName == nil {
+		return nil, fmt.Errorf("nothing to update")
+	}
+	for _, name := range []*string{req.FirstName, req.LastName} {
+		if name == nil {
+			continue
+		}
+		*name = strings.TrimSpace(*name)
+		if *name == "" || utf8.RuneCountInString(*name) > nameMaxLength {
+			return nil, fmt.Errorf("names must be between 1 and %d characters", nameMaxLength)
+		}
+	}
+
+	user, err := self.strg.User().UpdateUser(ctx, req)
+	if err != nil {
+		self.log.Error("---UpdateProfile--->", logger.Error(err))
+		return nil, err
+	}
+
+	// a new name is screened like the one the user registered with
+	_, err = self.sanctions.ScreenUser(ctx, &models.ScreenUserRequest{
+		UserID: user.Guid,
+		Name:   user.FirstName + " " + user.LastName,
+		Source: models.SanctionsSourceProfile,
+	})
+	if err != nil {
+		self.log.Error("---UpdateProfile->ScreenUser--->", logger.Error(err))
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// ChangePassword replaces the password once the current one is confirmed
+func (self *Service) ChangePassword(ctx context.Context, req *models.ChangePasswordRequest) error {
+	self.log.Info("---ChangePassword--->", logger.String("user_id", req.UserID))
+
+	if err := self.checkPassword(ctx, req.UserID, req.Phone, req.OldPassword); err != nil {
+		return err
+	}
+
+	hashedPassword, err := self.HashNewPassword(req.Phone, req.NewPassword)
+	if err != nil {
+		return err
+	}
+
+	tx, err := self.strg.Audit().BeginTx(ctx)
+	if err != nil {
+		self.log.Error("---ChangePassword->BeginTx--->", logger.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = self.strg.User().UpdateUserPassword(ctx, tx, req.UserID, hashedPassword); err != nil {
+		self.log.Error("---ChangePassword->UpdateUserPassword--->", logger.Error(err))
+		return err
+	}
+
+	err = self.strg.Audit().CreateAuditEvent(ctx, tx, &models.AuditEvent{
+		EventType:    models.AuditEventPasswordChanged,
+		ActorID:      req.UserID,
+		UserID:       req.UserID,
+		ResourceType: models.AuditResourceUser,
+		ResourceID:   req.UserID,
+	})
+	if err != nil {
+		self.log.Error("---ChangePassword->CreateAuditEvent--->", logger.Error(err))
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		self.log.Error("---ChangePassword->Commit--->", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// RequestPhoneChange sends a code to the new phone number, the number changes once the code is confirmed
+func (self *Service) RequestPhoneChange(ctx context.Context, req *models.ChangePhoneRequest) (*models.ChangePhoneResponse, error) {
+	self.log.Info("---RequestPhoneChange--->", logger.Any("req", req))
+
+	if !util.IsValidPhone(req.Phone) {
+		return nil, fmt.Errorf("invalid phone number")
+	}
+
+	_, err := self.strg.User().GetUserPasswordByPhone(ctx, req.Phone)
+	if err == nil {
+		return nil, &customerrors.UserAlreadyExistsError{Phone: req.Phone}
+	} else if _, ok := err.(*customerrors.UserNotFoundWithPhoneError); !ok {
+		self.log.Error("---RequestPhoneChange->GetUserPasswordByPhone--->", logger.Error(err))
+		return nil, err
+	}
+
+	otp, err := self.sendOTP(ctx, req.UserID, models.OTPPurposePhoneChange, req.Phone, req.Phone, "Код для смены номера телефона: %s. Никому его не сообщайте.")
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ChangePhoneResponse{
+		Phone:     otp.Target,
+		ExpiresAt: otp.ExpiresAt,
+	}, nil
+}
+
+// ConfirmPhoneChange moves the user to the phone number the code was sent to
+func (self *Service) ConfirmPhoneChange(ctx context.Context, req *models.ConfirmPhoneRequest) (*models.User, error) {
+	self.log.Info("---ConfirmPhoneChange--->", logger.String("user_id", req.UserID))
+
+	tx, err := self.strg.Audit().BeginTx(ctx)
+	if err != nil {
+		self.log.Error("---ConfirmPhoneChange->BeginTx--->", logger.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	otp, err := self.useOTP(ctx, tx, req.UserID, models.OTPPurposePhoneChange, req.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = self.strg.User().UpdateUserPhone(ctx, tx, req.UserID, otp.Target); err != nil {
+		self.log.Error("---ConfirmPhoneChange->UpdateUserPhone--->", logger.Error(err))
+		return nil, err
+	}
+
+	err = self.strg.Audit().CreateAuditEvent(ctx, tx, &models.AuditEvent{
+		EventType:    models.AuditEventPhoneChanged,
+		ActorID:      req.UserID,
+		UserID:       req.UserID,
+		ResourceType: models.AuditResourceUser,
+		ResourceID:   req.UserID,
+		Before:       map[string]interface{}{"phone": req.Phone},
+		After:        map[string]interface{}{"phone": otp.Target},
+	})
+	if err != nil {
+		self.log.Error("---ConfirmPhoneChange->CreateAuditEvent--->", logger.Error(err))
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		self.log.Error("---ConfirmPhoneChange->Commit--->", logger.Error(err))
+		return nil, err
+	}
+
+	resp, err := self.strg.User().GetUserByID(ctx, &models.GetUserByIDRequest{UserId: req.UserID})
+	if err != nil {
+		self.log.Error("---ConfirmPhoneChange->GetUserByID--->", logger.Error(err))
+		return nil, err
+	}
+
+	return resp.User, nil
+}
+
+// DeleteUser soft deletes the user after closing the accounts they own and leaving the ones they share
+func (self *Service) DeleteUser(ctx context.Context, req *models.DeleteUserRequest) error {
+	self.log.Info("---DeleteUser--->", logger.String("user_id", req.UserID))
+
+	if err := self.checkPassword(ctx, req.UserID, req.Phone, req.Password); err != nil {
+		return err
+	}
+
+	tx, err := self.strg.Audit().BeginTx(ctx)
+	if err != nil {
+		self.log.Error("---DeleteUser->BeginTx--->", logger.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = self.accountStatus.CloseUserAccounts(ctx, tx, req.UserID, "user deleted"); err != nil {
+		self.log.Error("---DeleteUser->CloseUserAccounts--->", logger.Error(err))
+		return err
+	}
+
+	if err = self.strg.AccountHolder().DeleteUserHoldings(ctx, tx, req.UserID); err != nil {
+		self.log.Error("---DeleteUser->DeleteUserHoldings--->", logger.Error(err))
+		return err
+	}
+
+	if err = self.strg.User().DeleteUser(ctx, tx, req.UserID); err != nil {
+		self.log.Error("---DeleteUser->DeleteUser--->", logger.Error(err))
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		self.log.Error("---DeleteUser->Commit--->", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// HashNewPassword checks a password the user is choosing against the policy and hashes it
+func (self *Service) HashNewPassword(phone, password string) (string, error) {
+	if err := self.password.Check(password, phone); err != nil {
+		return "", err
+	}
+
+	hashedPassword, err := self.password.Hash(password)
+	if err != nil {
+		self.log.Error("---HashNewPassword->Hash--->", logger.Error(err))
+		return "", err
+	}
+
+	return hashedPassword, nil
+}
+
+// checkPassword confirms the password of the signed in user
+func (self *Service) checkPassword(ctx context.Context, userID, phone, password string) error {
+	user, err := self.strg.User().GetUserPasswordByPhone(ctx, phone)
+	if _, ok := err.(*customerrors.UserNotFoundWithPhoneError); ok {
+		return &customerrors.InvalidCredentialsError{}
+	} else if err != nil {
+		self.log.Error("---CheckPassword->GetUserPasswordByPhone--->", logger.Error(err))
+		return err
+	}
+	if user.Guid != userID {
+		return &customerrors.InvalidCredentialsError{}
+	}
+
+	match, err := security.ComparePassword(user.Password, password)
+	if err != nil {
+		self.log.Error("---CheckPassword->ComparePassword--->", logger.Error(err))
+		return err
+	}
+	if !match {
+		return &customerrors.InvalidCredentialsError{}
+	}
+
+	return nil
+}
+
+// sendOTP generates a code for the target, stores its hash and sends the code in the text to the phone
+func (self *Service) sendOTP(ctx context.Context, userID, purpose, target, phone, text string) (*models.OTPCode, error) {
+	code, err := security.GenerateRandomCode(3)
+	if err != nil {
+		self.log.Error("---SendOTP->GenerateRandomCode--->", logger.Error(err))
+		return nil, err
+	}
+
+	otp, err := self.strg.OTP().CreateOTP(ctx, &models.OTPCode{
+		UserID:    userID,
+		Purpose:   purpose,
+		Target:    target,
+		CodeHash:  hashCode(code),
+		ExpiresAt: time.Now().Add(config.OTPCodeTTL).Format(time.RFC3339),
+	})
+	if err != nil {
+		self.log.Error("---SendOTP->CreateOTP--->", logger.Error(err))
+		return nil, err
+	}
+
+	if err = self.sms.Send(ctx, phone, fmt.Sprintf(text, code)); err != nil {
+		self.log.Error("---SendOTP->Send--->", logger.Error(err))
+		return nil, err
+	}
+
+	return otp, nil
+}
+
+// useOTP checks the code against the user's latest code for the purpose and spends it.
+// A wrong guess is counted and committed right away, so the caller's transaction ends with it.
+func (self *Service) useOTP(ctx context.Context, tx *sql.Tx, userID, purpose, code string) (*models.OTPCode, error) {
+	otp, err := self.strg.OTP().GetActiveOTPForUpdate(ctx, tx, userID, purpose)
+	if err != nil {
+		return nil, err
+	}
+	if otp.Attempts >= config.OTPMaxAttempts {
+		return nil, &customerrors.OTPAttemptsExceededError{}
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashCode(code)), []byte(otp.CodeHash)) != 1 {
+		if err = self.strg.OTP().IncrementOTPAttempts(ctx, tx, otp.ID); err != nil {
+			self.log.Error("---UseOTP->IncrementOTPAttempts--->", logger.Error(err))
+			return nil, err
+		}
+		if err = tx.Commit(); err != nil {
+			self.log.Error("---UseOTP->Commit--->", logger.Error(err))
+			return nil, err
+		}
+		return nil, &customerrors.InvalidOTPError{}
+	}
+
+	if err = self.strg.OTP().UseOTP(ctx, tx, otp.ID); err != nil {
+		self.log.Error("---UseOTP->UseOTP--->", logger.Error(err))
+		return nil, err
+	}
+
+	return otp, nil
+}
+
+func hashCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
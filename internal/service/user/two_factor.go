@@ -0,0 +1,277 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/logger"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/dilmurodov/online_banking/pkg/security"
+	"github.com/dilmurodov/online_banking/pkg/totp"
+)
+
+// recoveryCodePool leaves out the characters that are easy to mix up
+const recoveryCodePool = "abcdefghjkmnpqrstuvwxyz23456789"
+
+// EnrollTOTP creates the secret for an authenticator app, 2FA is turned on once ConfirmTOTP gets its first code
+func (self *Service) EnrollTOTP(ctx context.Context, req *models.EnrollTOTPRequest) (*models.TOTPEnrollment, error) {
+	self.log.Info("---EnrollTOTP--->", logger.String("user_id", req.UserID))
+
+	if err := self.checkPassword(ctx, req.UserID, req.Phone, req.Password); err != nil {
+		return nil, err
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		self.log.Error("---EnrollTOTP->GenerateSecret--->", logger.Error(err))
+		return nil, err
+	}
+
+	if err = self.strg.TwoFactor().SetTOTPSecret(ctx, req.UserID, secret); err != nil {
+		self.log.Error("---EnrollTOTP->SetTOTPSecret--->", logger.Error(err))
+		return nil, err
+	}
+
+	return &models.TOTPEnrollment{
+		Secret: secret,
+		URI:    totp.URI(config.TOTPIssuer, req.Phone, secret),
+	}, nil
+}
+
+// ConfirmTOTP turns 2FA on with the first code of the app and hands out the recovery codes
+func (self *Service) ConfirmTOTP(ctx context.Context, req *models.ConfirmTOTPRequest) (*models.RecoveryCodesResponse, error) {
+	self.log.Info("---ConfirmTOTP--->", logger.String("user_id", req.UserID))
+
+	tx, err := self.strg.Audit().BeginTx(ctx)
+	if err != nil {
+		self.log.Error("---ConfirmTOTP->BeginTx--->", logger.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	settings, err := self.strg.TwoFactor().GetTOTPForUpdate(ctx, tx, req.UserID)
+	if err != nil {
+		self.log.Error("---ConfirmTOTP->GetTOTPForUpdate--->", logger.Error(err))
+		return nil, err
+	}
+	if settings.EnabledAt != "" {
+		return nil, &customerrors.TwoFactorStateError{Enabled: true}
+	}
+	if settings.Secret == "" {
+		return nil, &customerrors.TwoFactorStateError{Enabled: false}
+	}
+
+	step, ok := totp.Validate(settings.Secret, req.Code, time.Now())
+	if !ok {
+		return nil, &customerrors.InvalidMFACodeError{}
+	}
+
+	if err = self.strg.TwoFactor().EnableTOTP(ctx, tx, req.UserID, step); err != nil {
+		self.log.Error("---ConfirmTOTP->EnableTOTP--->", logger.Error(err))
+		return nil, err
+	}
+
+	codes, err := self.replaceRecoveryCodes(ctx, tx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = self.strg.Audit().CreateAuditEvent(ctx, tx, &models.AuditEvent{
+		EventType:    models.AuditEventTwoFactorChanged,
+		ActorID:      req.UserID,
+		UserID:       req.UserID,
+		ResourceType: models.AuditResourceUser,
+		ResourceID:   req.UserID,
+		Before:       map[string]interface{}{"two_factor_enabled": false},
+		After:        map[string]interface{}{"two_factor_enabled": true},
+	})
+	if err != nil {
+		self.log.Error("---ConfirmTOTP->CreateAuditEvent--->", logger.Error(err))
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		self.log.Error("---ConfirmTOTP->Commit--->", logger.Error(err))
+		return nil, err
+	}
+
+	return &models.RecoveryCodesResponse{Codes: codes}, nil
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes once a code confirms it's the user
+func (self *Service) RegenerateRecoveryCodes(ctx context.Context, req *models.StepUpRequest) (*models.RecoveryCodesResponse, error) {
+	self.log.Info("---RegenerateRecoveryCodes--->", logger.String("user_id", req.UserID))
+
+	tx, err := self.strg.Audit().BeginTx(ctx)
+	if err != nil {
+		self.log.Error("---RegenerateRecoveryCodes->BeginTx--->", logger.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err = self.verifyMFA(ctx, tx, req.UserID, req.Code); err != nil {
+		return nil, err
+	}
+
+	codes, err := self.replaceRecoveryCodes(ctx, tx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = self.strg.Audit().CreateAuditEvent(ctx, tx, &models.AuditEvent{
+		EventType:    models.AuditEventRecoveryCodesRegenerated,
+		ActorID:      req.UserID,
+		UserID:       req.UserID,
+		ResourceType: models.AuditResourceUser,
+		ResourceID:   req.UserID,
+		Details:      map[string]interface{}{"count": len(codes)},
+	})
+	if err != nil {
+		self.log.Error("---RegenerateRecoveryCodes->CreateAuditEvent--->", logger.Error(err))
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		self.log.Error("---RegenerateRecoveryCodes->Commit--->", logger.Error(err))
+		return nil, err
+	}
+
+	return &models.RecoveryCodesResponse{Codes: codes}, nil
+}
+
+// DisableTOTP turns 2FA off, both the password and a code are asked for
+func (self *Service) DisableTOTP(ctx context.Context, req *models.DisableTOTPRequest) error {
+	self.log.Info("---DisableTOTP--->", logger.String("user_id", req.UserID))
+
+	if err := self.checkPassword(ctx, req.UserID, req.Phone, req.Password); err != nil {
+		return err
+	}
+
+	tx, err := self.strg.Audit().BeginTx(ctx)
+	if err != nil {
+		self.log.Error("---DisableTOTP->BeginTx--->", logger.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = self.verifyMFA(ctx, tx, req.UserID, req.Code); err != nil {
+		return err
+	}
+
+	if err = self.strg.TwoFactor().DisableTOTP(ctx, tx, req.UserID); err != nil {
+		self.log.Error("---DisableTOTP->DisableTOTP--->", logger.Error(err))
+		return err
+	}
+
+	err = self.strg.Audit().CreateAuditEvent(ctx, tx, &models.AuditEvent{
+		EventType:    models.AuditEventTwoFactorChanged,
+		ActorID:      req.UserID,
+		UserID:       req.UserID,
+		ResourceType: models.AuditResourceUser,
+		ResourceID:   req.UserID,
+		Before:       map[string]interface{}{"two_factor_enabled": true},
+		After:        map[string]interface{}{"two_factor_enabled": false},
+	})
+	if err != nil {
+		self.log.Error("---DisableTOTP->CreateAuditEvent--->", logger.Error(err))
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		self.log.Error("---DisableTOTP->Commit--->", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// VerifyMFA checks an authenticator or a recovery code of a user with 2FA on,
+// it is the second login step and the step-up before sensitive operations
+func (self *Service) VerifyMFA(ctx context.Context, userID, code string) error {
+	self.log.Info("---VerifyMFA--->", logger.String("user_id", userID))
+
+	tx, err := self.strg.TxRepo().BeginTx(ctx)
+	if err != nil {
+		self.log.Error("---VerifyMFA->BeginTx--->", logger.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = self.verifyMFA(ctx, tx, userID, code); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		self.log.Error("---VerifyMFA->Commit--->", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// verifyMFA accepts an authenticator code newer than the last one used or spends a recovery code
+func (self *Service) verifyMFA(ctx context.Context, tx *sql.Tx, userID, code string) error {
+	settings, err := self.strg.TwoFactor().GetTOTPForUpdate(ctx, tx, userID)
+	if err != nil {
+		self.log.Error("---VerifyMFA->GetTOTPForUpdate--->", logger.Error(err))
+		return err
+	}
+	if settings.EnabledAt == "" {
+		return &customerrors.TwoFactorStateError{Enabled: false}
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		step, ok := totp.Validate(settings.Secret, code, time.Now())
+		if !ok || step <= settings.LastStep {
+			return &customerrors.InvalidMFACodeError{}
+		}
+		if err = self.strg.TwoFactor().UpdateTOTPLastStep(ctx, tx, userID, step); err != nil {
+			self.log.Error("---VerifyMFA->UpdateTOTPLastStep--->", logger.Error(err))
+			return err
+		}
+		return nil
+	}
+
+	used, err := self.strg.TwoFactor().UseRecoveryCode(ctx, tx, userID, hashCode(normalizeRecoveryCode(code)))
+	if err != nil {
+		self.log.Error("---VerifyMFA->UseRecoveryCode--->", logger.Error(err))
+		return err
+	}
+	if !used {
+		return &customerrors.InvalidMFACodeError{}
+	}
+
+	return nil
+}
+
+// replaceRecoveryCodes generates new recovery codes and keeps only their hashes
+func (self *Service) replaceRecoveryCodes(ctx context.Context, tx *sql.Tx, userID string) ([]string, error) {
+	codes := make([]string, 0, config.RecoveryCodesCount)
+	hashes := make([]string, 0, config.RecoveryCodesCount)
+
+	for i := 0; i < config.RecoveryCodesCount; i++ {
+		code, err := security.GenerateRandomStringByPool(10, recoveryCodePool)
+		if err != nil {
+			self.log.Error("---ReplaceRecoveryCodes->GenerateRandomStringByPool--->", logger.Error(err))
+			return nil, err
+		}
+		codes = append(codes, code[:5]+"-"+code[5:])
+		hashes = append(hashes, hashCode(code))
+	}
+
+	if err := self.strg.TwoFactor().ReplaceRecoveryCodes(ctx, tx, userID, hashes); err != nil {
+		self.log.Error("---ReplaceRecoveryCodes->ReplaceRecoveryCodes--->", logger.Error(err))
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(code, "-", ""))
+}
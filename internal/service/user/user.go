@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
 	"github.com/dilmurodov/online_banking/pkg/logger"
 	"github.com/dilmurodov/online_banking/pkg/models"
 	"github.com/dilmurodov/online_banking/pkg/security"
@@ -29,7 +30,14 @@ func (self *Service) GetUserByID(ctx context.Context, req *models.GetUserByIDReq
 func (self *Service) CreateUser(ctx context.Context, req *models.CreateUserRequest) (resp *models.User, err error) {
 	self.log.Info("---CreateUser--->", logger.Any("req", req))
 
-	resp, err = self.strg.User().CreateUser(ctx, req)
+	tx, err := self.strg.TxRepo().BeginTx(ctx)
+	if err != nil {
+		self.log.Error("---CreateUser->BeginTx--->", logger.Error(err))
+		return nil, fmt.Errorf(`%s, %w`, config.SYSTEM_ERROR, err)
+	}
+	defer tx.Rollback()
+
+	resp, err = self.strg.User().CreateUser(ctx, tx, req)
 	if err != nil && err.Error() == config.DUBLICATE_PHONE {
 		return nil, fmt.Errorf(config.USER_ALREADY_EXISTS)
 	}
@@ -38,38 +46,109 @@ func (self *Service) CreateUser(ctx context.Context, req *models.CreateUserReque
 		return nil, fmt.Errorf(`%s, %w`, config.SYSTEM_ERROR, err)
 	}
 
+	// the user is screened in the transaction, a failed screening leaves no unscreened user behind
+	_, err = self.sanctions.ScreenNewUser(ctx, tx, &models.ScreenUserRequest{
+		UserID: resp.Guid,
+		Name:   resp.FirstName + " " + resp.LastName,
+		Source: models.SanctionsSourceRegistration,
+	})
+	if err != nil {
+		self.log.Error("---CreateUser->ScreenNewUser--->", logger.Error(err))
+		return nil, fmt.Errorf(`%s, %w`, config.SYSTEM_ERROR, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		self.log.Error("---CreateUser->Commit--->", logger.Error(err))
+		return nil, fmt.Errorf(`%s, %w`, config.SYSTEM_ERROR, err)
+	}
+
 	return resp, nil
 }
 
+// GetUserByCredentials checks the phone and the password. An unknown phone fails the same way
+// and takes as long as a wrong password, and failed attempts slow down and lock out the phone and the IP.
 func (self *Service) GetUserByCredentials(ctx context.Context, req *models.GetByCredentialsRequest) (*models.User, error) {
-	self.log.Info("---GetByCredentials--->", logger.Any("req", req))
+	self.log.Info("---GetByCredentials--->", logger.String("phone", req.Phone), logger.String("ip", req.IP))
 
 	if len(req.Password) < 6 {
 		err := fmt.Errorf("password must not be less than 6 characters")
 		self.log.Error("---GetByCredentials--->", logger.Error(err))
 		return nil, err
 	}
+
+	keys := loginKeys(req)
+	locked, err := self.strg.LoginFailure().GetLoginLock(ctx, keys)
+	if err != nil {
+		self.log.Error("---GetByCredentials->GetLoginLock--->", logger.Error(err))
+		return nil, err
+	}
+	if locked > 0 {
+		return nil, &customerrors.LoginLockedError{RetryAfter: locked}
+	}
+
 	user, err := self.strg.User().GetUserPasswordByPhone(ctx, req.Phone)
-	if err != nil && err == sql.ErrNoRows {
+	hashedPassword := dummyPasswordHash(self.password)
+	if err == nil {
+		hashedPassword = user.Password
+	} else if _, ok := err.(*customerrors.UserNotFoundWithPhoneError); ok {
+		user = nil
+	} else {
 		self.log.Error("---GetByCredentials--->GetUser", logger.Error(err))
-		return nil, fmt.Errorf(config.RECORD_NOT_FOUND)
-	} else if err != nil {
-		self.log.Error("---GetByCredentials--->GetUser", logger.Error(err))
-		return nil, fmt.Errorf(`%s, %w`, config.SYSTEM_ERROR, err)
+		return nil, err
 	}
 
-	check, err := security.ComparePassword(user.Password, req.Password)
+	check, err := security.ComparePassword(hashedPassword, req.Password)
 	if err != nil {
 		self.log.Error("---GetByCredentials--->ComparePassword", logger.Error(err))
 		return nil, err
 	}
-	if !check {
-		return nil, fmt.Errorf("password or phone is incorrect")
+	if user == nil || !check {
+		userID := ""
+		if user != nil {
+			userID = user.Guid
+		}
+		if err = self.loginFailed(ctx, req, userID, keys); err != nil {
+			return nil, err
+		}
+		return nil, &customerrors.InvalidCredentialsError{}
+	}
+
+	// only the phone starts over, the IP may be trying many phones
+	if err = self.strg.LoginFailure().ResetLoginFailures(ctx, keys[0]); err != nil {
+		self.log.Error("---GetByCredentials->ResetLoginFailures--->", logger.Error(err))
+		return nil, err
+	}
+
+	// the password is at hand only now, so a hash with old costs is upgraded here. A failed upgrade is tried on the next login.
+	if self.password.NeedsRehash(user.Password) {
+		if err = self.rehashPassword(ctx, user.Guid, req.Password); err != nil {
+			self.log.Error("---GetByCredentials->Rehash--->", logger.Error(err))
+		}
 	}
 
 	return user, nil
 }
 
+// rehashPassword stores the password hashed with the current costs
+func (self *Service) rehashPassword(ctx context.Context, userID, password string) error {
+	hashedPassword, err := self.password.Hash(password)
+	if err != nil {
+		return err
+	}
+
+	tx, err := self.strg.TxRepo().BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = self.strg.User().UpdateUserPassword(ctx, tx, userID, hashedPassword); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
 func (self *Service) GetUserPasswordByPhone(ctx context.Context, phone string) (resp *models.User, err error) {
 	self.log.Info("---GetUserPasswordByPhone--->", logger.Any("phone", phone))
 
@@ -81,3 +160,15 @@ func (self *Service) GetUserPasswordByPhone(ctx context.Context, phone string) (
 
 	return resp, err
 }
+
+func (self *Service) SetDefaultAccount(ctx context.Context, req *models.SetDefaultAccountRequest) error {
+	self.log.Info("---SetDefaultAccount--->", logger.Any("req", req))
+
+	err := self.strg.User().SetDefaultAccount(ctx, req)
+	if err != nil {
+		self.log.Error("---SetDefaultAccount--->", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
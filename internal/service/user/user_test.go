@@ -2,12 +2,22 @@ package user
 
 import (
 	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/internal/service/sanctions"
+	"github.com/dilmurodov/online_banking/internal/service/servicetest"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
 	"github.com/dilmurodov/online_banking/pkg/models"
 	"github.com/dilmurodov/online_banking/pkg/security"
+	"github.com/dilmurodov/online_banking/pkg/sms"
+	"github.com/dilmurodov/online_banking/pkg/totp"
 	mock_storage "github.com/dilmurodov/online_banking/storage/mock"
 	"github.com/dilmurodov/online_banking/storage/postgres"
 	"github.com/golang/mock/gomock"
@@ -28,9 +38,13 @@ func TestUser_GetUserByID(t *testing.T) {
 		config.Config{},
 		zap.NewNop(),
 		postgres.NewStore(db),
+		sms.NewLogSender(zap.NewNop()),
+		&security.PasswordPolicy{MinLength: 8, Params: security.DefaultParams},
+		nil,
+		sanctions.NewService(config.Config{}, zap.NewNop(), nil),
 	)
 
-	rows := mock.NewRows([]string{"guid", "first_name", "last_name", "phone", "created_at", "updated_at"}).AddRow("TestUserID", "TestFirstName", "TestLastName", "TestPhone", "2021-01-01 00:00:00", "2021-01-01 00:00:00")
+	rows := mock.NewRows([]string{"guid", "first_name", "last_name", "phone", "created_at", "updated_at", "sessions_revoked_at", "two_factor_enabled", "role"}).AddRow("TestUserID", "TestFirstName", "TestLastName", "TestPhone", "2021-01-01 00:00:00", "2021-01-01 00:00:00", 0, false, models.RoleCustomer)
 
 	mock.ExpectQuery(`^SELECT (.+?) FROM "users" * `).WithArgs("TestUserID").WillReturnRows(rows)
 
@@ -73,12 +87,18 @@ func TestUser_CreateUser(t *testing.T) {
 		config.Config{},
 		zap.NewNop(),
 		postgres.NewStore(db),
+		sms.NewLogSender(zap.NewNop()),
+		&security.PasswordPolicy{MinLength: 8, Params: security.DefaultParams},
+		nil,
+		sanctions.NewService(config.Config{}, zap.NewNop(), nil),
 	)
 
 	rows := mock.NewRows([]string{"guid", "first_name", "last_name", "phone", "created_at", "updated_at"}).AddRow("TestUserId", "TestFirstName", "TestLastName", "TestPhone", "2021-01-01 00:00:00", "2021-01-01 00:00:00")
 
+	mock.ExpectBegin()
 	mock.ExpectQuery(`^INSERT INTO "users" (.+?) * `).WithArgs("TestFirstName", "TestLastName", "TestPhone", "TestPassword").
 		WillReturnRows(rows)
+	mock.ExpectCommit()
 
 	repo := mock_storage.NewMockUserRepoI(ctrl)
 
@@ -104,10 +124,41 @@ func TestUser_CreateUser(t *testing.T) {
 			UpdatedAt: "2021-01-01 00:00:00",
 		}
 
-		repo.EXPECT().CreateUser(ctx, in).Return(resp, nil).Times(1).AnyTimes()
+		repo.EXPECT().CreateUser(ctx, gomock.Any(), in).Return(resp, nil).Times(1).AnyTimes()
 
 		_, err := s.CreateUser(ctx, in)
 		r.NoError(err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("SCREENING_FAILED", func(t *testing.T) {
+		listFile := filepath.Join(t.TempDir(), "sdn.csv")
+		r.NoError(os.WriteFile(listFile, []byte(`36,"IVANOV, Ivan Petrovich","individual","SDGT",-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,-0-`+"\n"), 0o600))
+
+		strg, mock := servicetest.NewStore(t)
+		s := NewService(
+			config.Config{},
+			zap.NewNop(),
+			strg,
+			sms.NewLogSender(zap.NewNop()),
+			&security.PasswordPolicy{MinLength: 8, Params: security.DefaultParams},
+			nil,
+			sanctions.NewService(config.Config{SanctionsListFile: listFile, SanctionsMatchThreshold: 0.92}, zap.NewNop(), strg),
+		)
+
+		// a hit that can't be stored rolls the user back, no unscreened user is left behind
+		mock.ExpectBegin()
+		mock.ExpectQuery(`^INSERT INTO "users"`).WithArgs("Ivan", "Ivanov", "TestPhone", "TestPassword").
+			WillReturnRows(mock.NewRows([]string{"guid", "first_name", "last_name", "phone", "created_at", "updated_at"}).
+				AddRow("TestUserId", "Ivan", "Ivanov", "TestPhone", "2021-01-01 00:00:00", "2021-01-01 00:00:00"))
+		mock.ExpectQuery(`^INSERT INTO sanctions_hits`).WillReturnError(sql.ErrConnDone)
+		mock.ExpectRollback()
+
+		_, err := s.CreateUser(context.Background(), &models.CreateUserRequest{
+			User: &models.User{FirstName: "Ivan", LastName: "Ivanov", Phone: "TestPhone", Password: "TestPassword"},
+		})
+		r.Error(err)
+		r.NoError(mock.ExpectationsWereMet())
 	})
 }
 
@@ -124,14 +175,20 @@ func TestUser_GetUserByCredentials(t *testing.T) {
 		config.Config{},
 		zap.NewNop(),
 		postgres.NewStore(db),
+		sms.NewLogSender(zap.NewNop()),
+		&security.PasswordPolicy{MinLength: 8, Params: security.DefaultParams},
+		nil,
+		sanctions.NewService(config.Config{}, zap.NewNop(), nil),
 	)
 
 	hpass, err := security.HashPassword("TestPassword")
 	r.NoError(err)
 
-	rows := mock.NewRows([]string{"guid", "first_name", "last_name", "phone", "password", "created_at", "updated_at"}).AddRow("TestUserId", "TestFirstName", "TestLastName", "TestPhone", hpass, "2021-01-01 00:00:00", "2021-01-01 00:00:00")
+	rows := mock.NewRows([]string{"guid", "first_name", "last_name", "phone", "password", "created_at", "updated_at", "two_factor_enabled", "role"}).AddRow("TestUserId", "TestFirstName", "TestLastName", "TestPhone", hpass, "2021-01-01 00:00:00", "2021-01-01 00:00:00", false, models.RoleCustomer)
 
+	mock.ExpectQuery(`^SELECT (.+?) FROM login_failures`).WillReturnRows(sqlmock.NewRows([]string{"seconds"}).AddRow(0))
 	mock.ExpectQuery(`^SELECT (.+?) FROM "users" * `).WithArgs("TestPhone").WillReturnRows(rows)
+	mock.ExpectExec(`^DELETE FROM login_failures`).WithArgs("phone:TestPhone").WillReturnResult(sqlmock.NewResult(0, 1))
 
 	repo := mock_storage.NewMockUserRepoI(ctrl)
 
@@ -152,6 +209,7 @@ func TestUser_GetUserByCredentials(t *testing.T) {
 			Password:  hpass,
 			CreatedAt: "2021-01-01 00:00:00",
 			UpdatedAt: "2021-01-01 00:00:00",
+			Role:      models.RoleCustomer,
 		}
 
 		repo.EXPECT().GetUserPasswordByPhone(ctx, "TestPhone").Return(mockresp, nil).Times(1).AnyTimes()
@@ -162,3 +220,365 @@ func TestUser_GetUserByCredentials(t *testing.T) {
 
 	})
 }
+
+func TestUser_ConfirmPhoneChange(t *testing.T) {
+
+	r := require.New(t)
+
+	db, mock, err := sqlmock.New()
+	r.NoError(err)
+
+	s := NewService(
+		config.Config{},
+		zap.NewNop(),
+		postgres.NewStore(db),
+		sms.NewLogSender(zap.NewNop()),
+		&security.PasswordPolicy{MinLength: 8, Params: security.DefaultParams},
+		nil,
+		sanctions.NewService(config.Config{}, zap.NewNop(), nil),
+	)
+
+	otpColumns := []string{"guid", "user_id", "purpose", "target", "code_hash", "attempts", "expires_at"}
+
+	// a wrong code is counted even though the change fails
+	servicetest.ExpectAuditBegin(mock)
+	mock.ExpectQuery(`^SELECT (.+?) FROM otp_codes`).WithArgs("TestUserID", models.OTPPurposePhoneChange).
+		WillReturnRows(sqlmock.NewRows(otpColumns).AddRow("TestOTPID", "TestUserID", models.OTPPurposePhoneChange, "+998901234567", hashCode("123456"), 0, "2021-01-01T00:05:00Z"))
+	mock.ExpectExec(`^UPDATE otp_codes SET attempts`).WithArgs("TestOTPID").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	t.Run("WRONG_CODE", func(t *testing.T) {
+		_, err := s.ConfirmPhoneChange(context.Background(), &models.ConfirmPhoneRequest{UserID: "TestUserID", Code: "654321"})
+		r.Equal(&customerrors.InvalidOTPError{}, err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	servicetest.ExpectAuditBegin(mock)
+	mock.ExpectQuery(`^SELECT (.+?) FROM otp_codes`).WithArgs("TestUserID", models.OTPPurposePhoneChange).
+		WillReturnRows(sqlmock.NewRows(otpColumns).AddRow("TestOTPID", "TestUserID", models.OTPPurposePhoneChange, "+998901234567", hashCode("123456"), config.OTPMaxAttempts, "2021-01-01T00:05:00Z"))
+	mock.ExpectRollback()
+
+	t.Run("ATTEMPTS_EXCEEDED", func(t *testing.T) {
+		_, err := s.ConfirmPhoneChange(context.Background(), &models.ConfirmPhoneRequest{UserID: "TestUserID", Code: "123456"})
+		r.Equal(&customerrors.OTPAttemptsExceededError{}, err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	servicetest.ExpectAuditBegin(mock)
+	mock.ExpectQuery(`^SELECT (.+?) FROM otp_codes`).WithArgs("TestUserID", models.OTPPurposePhoneChange).
+		WillReturnRows(sqlmock.NewRows(otpColumns).AddRow("TestOTPID", "TestUserID", models.OTPPurposePhoneChange, "+998901234567", hashCode("123456"), 1, "2021-01-01T00:05:00Z"))
+	mock.ExpectExec(`^UPDATE otp_codes SET used_at`).WithArgs("TestOTPID").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE "users" SET`).WithArgs("TestUserID", "+998901234567").WillReturnResult(sqlmock.NewResult(0, 1))
+	servicetest.ExpectAuditEvent(mock, models.AuditEventPhoneChanged, "TestUserID", "", []byte("null"), "TestUserID", models.AuditResourceUser, "TestUserID", "",
+		`{"phone":"+998907654321"}`, `{"phone":"+998901234567"}`, "", sqlmock.AnyArg(), sqlmock.AnyArg())
+	mock.ExpectCommit()
+	mock.ExpectQuery(`^SELECT (.+?) FROM "users" * `).WithArgs("TestUserID").
+		WillReturnRows(sqlmock.NewRows([]string{"guid", "first_name", "last_name", "phone", "created_at", "updated_at", "sessions_revoked_at", "two_factor_enabled", "role"}).AddRow("TestUserID", "TestFirstName", "TestLastName", "+998901234567", "2021-01-01 00:00:00", "2021-01-01 00:00:00", 0, false, models.RoleCustomer))
+
+	t.Run("SUCCESS", func(t *testing.T) {
+		user, err := s.ConfirmPhoneChange(context.Background(), &models.ConfirmPhoneRequest{UserID: "TestUserID", Phone: "+998907654321", Code: "123456"})
+		r.NoError(err)
+		r.Equal("+998901234567", user.Phone)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+}
+
+func TestUser_ConfirmPaymentChallenge(t *testing.T) {
+
+	r := require.New(t)
+
+	db, mock, err := sqlmock.New()
+	r.NoError(err)
+
+	s := NewService(
+		config.Config{},
+		zap.NewNop(),
+		postgres.NewStore(db),
+		sms.NewLogSender(zap.NewNop()),
+		&security.PasswordPolicy{MinLength: 8, Params: security.DefaultParams},
+		nil,
+		sanctions.NewService(config.Config{}, zap.NewNop(), nil),
+	)
+
+	otpColumns := []string{"guid", "user_id", "purpose", "target", "code_hash", "attempts", "expires_at"}
+
+	// the code sent last was for another payment
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT (.+?) FROM otp_codes`).WithArgs("TestUserID", models.OTPPurposePaymentChallenge).
+		WillReturnRows(sqlmock.NewRows(otpColumns).AddRow("TestOTPID", "TestUserID", models.OTPPurposePaymentChallenge, "TestOtherDecisionID", hashCode("123456"), 0, "2021-01-01T00:05:00Z"))
+	mock.ExpectExec(`^UPDATE otp_codes SET used_at`).WithArgs("TestOTPID").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectRollback()
+
+	t.Run("OTHER_DECISION", func(t *testing.T) {
+		err := s.ConfirmPaymentChallenge(context.Background(), &models.ConfirmFraudChallengeRequest{DecisionID: "TestDecisionID", UserID: "TestUserID", Code: "123456"})
+		r.IsType(&customerrors.FraudChallengeInvalidError{}, err)
+	})
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT (.+?) FROM otp_codes`).WithArgs("TestUserID", models.OTPPurposePaymentChallenge).
+		WillReturnRows(sqlmock.NewRows(otpColumns).AddRow("TestOTPID", "TestUserID", models.OTPPurposePaymentChallenge, "TestDecisionID", hashCode("123456"), 0, "2021-01-01T00:05:00Z"))
+	mock.ExpectExec(`^UPDATE otp_codes SET used_at`).WithArgs("TestOTPID").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`^UPDATE fraud_decisions SET challenge_status = 'passed'`).WithArgs("TestDecisionID", "TestUserID").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	t.Run("SUCCESS", func(t *testing.T) {
+		err := s.ConfirmPaymentChallenge(context.Background(), &models.ConfirmFraudChallengeRequest{DecisionID: "TestDecisionID", UserID: "TestUserID", Code: "123456"})
+		r.NoError(err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+}
+
+func TestUser_ResetPassword(t *testing.T) {
+
+	r := require.New(t)
+
+	db, mock, err := sqlmock.New()
+	r.NoError(err)
+
+	s := NewService(
+		config.Config{},
+		zap.NewNop(),
+		postgres.NewStore(db),
+		sms.NewLogSender(zap.NewNop()),
+		&security.PasswordPolicy{MinLength: 8, Params: security.DefaultParams},
+		nil,
+		sanctions.NewService(config.Config{}, zap.NewNop(), nil),
+	)
+
+	userColumns := []string{"guid", "first_name", "last_name", "phone", "password", "created_at", "updated_at", "two_factor_enabled", "role"}
+	otpColumns := []string{"guid", "user_id", "purpose", "target", "code_hash", "attempts", "expires_at"}
+
+	t.Run("WEAK_PASSWORD", func(t *testing.T) {
+		err := s.ResetPassword(context.Background(), &models.ResetPasswordRequest{Phone: "+998901234567", Code: "123456", NewPassword: "pass1234567"})
+		r.IsType(&customerrors.WeakPasswordError{}, err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	// an unknown phone looks the same as a wrong code
+	mock.ExpectQuery(`^SELECT (.+?) FROM "users" * `).WithArgs("+998900000000").WillReturnRows(sqlmock.NewRows(userColumns))
+
+	t.Run("UNKNOWN_PHONE", func(t *testing.T) {
+		err := s.ResetPassword(context.Background(), &models.ResetPasswordRequest{Phone: "+998900000000", Code: "123456", NewPassword: "NewPassword"})
+		r.Equal(&customerrors.InvalidOTPError{}, err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	mock.ExpectQuery(`^SELECT (.+?) FROM "users" * `).WithArgs("+998901234567").
+		WillReturnRows(sqlmock.NewRows(userColumns).AddRow("TestUserID", "TestFirstName", "TestLastName", "+998901234567", "TestHash", "2021-01-01 00:00:00", "2021-01-01 00:00:00", false, models.RoleCustomer))
+	servicetest.ExpectAuditBegin(mock)
+	mock.ExpectQuery(`^SELECT (.+?) FROM otp_codes`).WithArgs("TestUserID", models.OTPPurposePasswordReset).
+		WillReturnRows(sqlmock.NewRows(otpColumns).AddRow("TestOTPID", "TestUserID", models.OTPPurposePasswordReset, "+998901234567", hashCode("123456"), 0, "2021-01-01T00:05:00Z"))
+	mock.ExpectExec(`^UPDATE otp_codes SET used_at`).WithArgs("TestOTPID").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE "users" SET(.+?)sessions_revoked_at`).WithArgs("TestUserID", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+	servicetest.ExpectAuditEvent(mock, models.AuditEventPasswordReset, "TestUserID", "", []byte(`{"sessions_revoked":true}`), "TestUserID", models.AuditResourceUser, "TestUserID", "",
+		nil, nil, "", sqlmock.AnyArg(), sqlmock.AnyArg())
+	mock.ExpectCommit()
+
+	t.Run("SUCCESS", func(t *testing.T) {
+		err := s.ResetPassword(context.Background(), &models.ResetPasswordRequest{Phone: "+998901234567", Code: "123456", NewPassword: "NewPassword"})
+		r.NoError(err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+}
+
+func TestUser_VerifyMFA(t *testing.T) {
+
+	r := require.New(t)
+
+	db, mock, err := sqlmock.New()
+	r.NoError(err)
+
+	s := NewService(
+		config.Config{},
+		zap.NewNop(),
+		postgres.NewStore(db),
+		sms.NewLogSender(zap.NewNop()),
+		&security.PasswordPolicy{MinLength: 8, Params: security.DefaultParams},
+		nil,
+		sanctions.NewService(config.Config{}, zap.NewNop(), nil),
+	)
+
+	secret, err := totp.GenerateSecret()
+	r.NoError(err)
+	step := totp.Step(time.Now())
+	code, err := totp.Code(secret, step)
+	r.NoError(err)
+
+	totpColumns := []string{"totp_secret", "totp_enabled_at", "totp_last_step"}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT (.+?) FROM "users"(.+?)FOR UPDATE`).WithArgs("TestUserID").
+		WillReturnRows(sqlmock.NewRows(totpColumns).AddRow(secret, "2021-01-01T00:00:00Z", 0))
+	mock.ExpectExec(`^UPDATE "users" SET totp_last_step`).WithArgs("TestUserID", step).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	t.Run("SUCCESS", func(t *testing.T) {
+		r.NoError(s.VerifyMFA(context.Background(), "TestUserID", code))
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	// the code of a step that was already accepted can't be used again
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT (.+?) FROM "users"(.+?)FOR UPDATE`).WithArgs("TestUserID").
+		WillReturnRows(sqlmock.NewRows(totpColumns).AddRow(secret, "2021-01-01T00:00:00Z", step+totp.Skew))
+	mock.ExpectRollback()
+
+	t.Run("REPLAYED_CODE", func(t *testing.T) {
+		err := s.VerifyMFA(context.Background(), "TestUserID", code)
+		r.Equal(&customerrors.InvalidMFACodeError{}, err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT (.+?) FROM "users"(.+?)FOR UPDATE`).WithArgs("TestUserID").
+		WillReturnRows(sqlmock.NewRows(totpColumns).AddRow(secret, "2021-01-01T00:00:00Z", step))
+	mock.ExpectExec(`^UPDATE mfa_recovery_codes SET used_at`).WithArgs("TestUserID", hashCode("abcde23456")).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	t.Run("RECOVERY_CODE", func(t *testing.T) {
+		r.NoError(s.VerifyMFA(context.Background(), "TestUserID", "ABCDE-23456"))
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT (.+?) FROM "users"(.+?)FOR UPDATE`).WithArgs("TestUserID").
+		WillReturnRows(sqlmock.NewRows(totpColumns).AddRow(nil, nil, 0))
+	mock.ExpectRollback()
+
+	t.Run("NOT_ENABLED", func(t *testing.T) {
+		err := s.VerifyMFA(context.Background(), "TestUserID", code)
+		r.Equal(&customerrors.TwoFactorStateError{Enabled: false}, err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+}
+
+func TestUser_DisableTOTP(t *testing.T) {
+
+	r := require.New(t)
+
+	db, mock, err := sqlmock.New()
+	r.NoError(err)
+
+	s := NewService(
+		config.Config{},
+		zap.NewNop(),
+		postgres.NewStore(db),
+		sms.NewLogSender(zap.NewNop()),
+		&security.PasswordPolicy{MinLength: 8, Params: security.DefaultParams},
+		nil,
+		sanctions.NewService(config.Config{}, zap.NewNop(), nil),
+	)
+
+	hashed, err := security.HashPasswordWithParams("TestPassword", security.Params{Memory: 1024, Time: 1, Threads: 1, KeyLen: 32, SaltLen: 16})
+	r.NoError(err)
+
+	mock.ExpectQuery(`^SELECT (.+?) FROM "users" * `).WithArgs("TestPhone").
+		WillReturnRows(sqlmock.NewRows([]string{"guid", "first_name", "last_name", "phone", "password", "created_at", "updated_at", "two_factor_enabled", "role"}).
+			AddRow("TestUserID", "TestFirstName", "TestLastName", "TestPhone", hashed, "2021-01-01 00:00:00", "2021-01-01 00:00:00", true, models.RoleCustomer))
+	servicetest.ExpectAuditBegin(mock)
+	mock.ExpectQuery(`^SELECT (.+?) FROM "users"(.+?)FOR UPDATE`).WithArgs("TestUserID").
+		WillReturnRows(sqlmock.NewRows([]string{"totp_secret", "totp_enabled_at", "totp_last_step"}).AddRow("TestSecret", "2021-01-01T00:00:00Z", 0))
+	mock.ExpectExec(`^UPDATE mfa_recovery_codes SET used_at`).WithArgs("TestUserID", hashCode("abcde23456")).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`^UPDATE "users" SET(.+?)totp_secret = NULL`).WithArgs("TestUserID").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`^DELETE FROM mfa_recovery_codes`).WithArgs("TestUserID").WillReturnResult(sqlmock.NewResult(0, 10))
+	servicetest.ExpectAuditEvent(mock, models.AuditEventTwoFactorChanged, "TestUserID", "", []byte("null"), "TestUserID", models.AuditResourceUser, "TestUserID", "",
+		`{"two_factor_enabled":true}`, `{"two_factor_enabled":false}`, "", sqlmock.AnyArg(), sqlmock.AnyArg())
+	mock.ExpectCommit()
+
+	t.Run("SUCCESS", func(t *testing.T) {
+		err := s.DisableTOTP(context.Background(), &models.DisableTOTPRequest{UserID: "TestUserID", Phone: "TestPhone", Password: "TestPassword", Code: "ABCDE-23456"})
+		r.NoError(err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+}
+
+func TestUser_LoginLockout(t *testing.T) {
+
+	r := require.New(t)
+
+	db, mock, err := sqlmock.New()
+	r.NoError(err)
+
+	s := NewService(
+		config.Config{},
+		zap.NewNop(),
+		postgres.NewStore(db),
+		sms.NewLogSender(zap.NewNop()),
+		&security.PasswordPolicy{MinLength: 8, Params: security.DefaultParams},
+		nil,
+		sanctions.NewService(config.Config{}, zap.NewNop(), nil),
+	)
+
+	req := &models.GetByCredentialsRequest{Phone: "+998900000000", Password: "WrongPassword", IP: "10.0.0.1"}
+
+	// an unknown phone fails like a wrong password and the failure that crosses the limit locks the phone out
+	mock.ExpectQuery(`^SELECT (.+?) FROM login_failures`).WillReturnRows(sqlmock.NewRows([]string{"seconds"}).AddRow(0))
+	mock.ExpectQuery(`^SELECT (.+?) FROM "users" * `).WithArgs("+998900000000").
+		WillReturnRows(sqlmock.NewRows([]string{"guid", "first_name", "last_name", "phone", "password", "created_at", "updated_at", "two_factor_enabled", "role"}))
+	servicetest.ExpectAuditBegin(mock)
+	mock.ExpectQuery(`^INSERT INTO login_failures`).WithArgs("phone:+998900000000", config.LoginFailureWindow.Seconds()).
+		WillReturnRows(sqlmock.NewRows([]string{"failures"}).AddRow(config.LoginPhoneLockoutAfter))
+	mock.ExpectExec(`^UPDATE login_failures SET locked_until`).WithArgs("phone:+998900000000", config.LoginLockoutDuration.Seconds()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`^SELECT hash FROM audit_events`).WillReturnRows(sqlmock.NewRows([]string{"hash"}))
+	mock.ExpectQuery(`^INSERT INTO audit_events`).
+		WithArgs(models.AuditEventLoginLocked, nil, "10.0.0.1", sqlmock.AnyArg(), nil, "", "", "", nil, nil, "", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"guid", "seq"}).AddRow("TestEventID", 1))
+	mock.ExpectQuery(`^INSERT INTO login_failures`).WithArgs("ip:10.0.0.1", config.LoginFailureWindow.Seconds()).
+		WillReturnRows(sqlmock.NewRows([]string{"failures"}).AddRow(1))
+	mock.ExpectCommit()
+
+	t.Run("UNKNOWN_PHONE", func(t *testing.T) {
+		_, err := s.GetUserByCredentials(context.Background(), req)
+		r.Equal(&customerrors.InvalidCredentialsError{}, err)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+
+	mock.ExpectQuery(`^SELECT (.+?) FROM login_failures`).WillReturnRows(sqlmock.NewRows([]string{"seconds"}).AddRow(899.5))
+
+	t.Run("LOCKED", func(t *testing.T) {
+		_, err := s.GetUserByCredentials(context.Background(), req)
+		r.IsType(&customerrors.LoginLockedError{}, err)
+		r.Equal("Слишком много неудачных попыток входа, повторите через 900 сек.", err.Error())
+		r.NoError(mock.ExpectationsWereMet())
+	})
+}
+
+func TestUser_LoginRehash(t *testing.T) {
+
+	r := require.New(t)
+
+	db, mock, err := sqlmock.New()
+	r.NoError(err)
+
+	s := NewService(
+		config.Config{},
+		zap.NewNop(),
+		postgres.NewStore(db),
+		sms.NewLogSender(zap.NewNop()),
+		&security.PasswordPolicy{MinLength: 8, Params: security.DefaultParams},
+		nil,
+		sanctions.NewService(config.Config{}, zap.NewNop(), nil),
+	)
+
+	// a hash of the old format with lower costs
+	legacy, err := security.HashPasswordWithParams("TestPassword", security.Params{Memory: 1024, Time: 1, Threads: 1, KeyLen: 32, SaltLen: 16})
+	r.NoError(err)
+	legacy = strings.Replace(legacy, "$m=", "$models=", 1)
+
+	mock.ExpectQuery(`^SELECT (.+?) FROM login_failures`).WillReturnRows(sqlmock.NewRows([]string{"seconds"}).AddRow(0))
+	mock.ExpectQuery(`^SELECT (.+?) FROM "users" * `).WithArgs("TestPhone").
+		WillReturnRows(sqlmock.NewRows([]string{"guid", "first_name", "last_name", "phone", "password", "created_at", "updated_at", "two_factor_enabled", "role"}).
+			AddRow("TestUserID", "TestFirstName", "TestLastName", "TestPhone", legacy, "2021-01-01 00:00:00", "2021-01-01 00:00:00", false, models.RoleCustomer))
+	mock.ExpectExec(`^DELETE FROM login_failures`).WithArgs("phone:TestPhone").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "users" SET(.+?)password = \$2`).WithArgs("TestUserID", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	t.Run("SUCCESS", func(t *testing.T) {
+		user, err := s.GetUserByCredentials(context.Background(), &models.GetByCredentialsRequest{Phone: "TestPhone", Password: "TestPassword"})
+		r.NoError(err)
+		r.Equal("TestUserID", user.Guid)
+		r.NoError(mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,3 @@
+ALTER TABLE "users" DROP CONSTRAINT IF EXISTS "users_default_account_id_fkey";
+
+ALTER TABLE "users" DROP COLUMN IF EXISTS "default_account_id";
@@ -0,0 +1,7 @@
+ALTER TABLE "users"
+    ADD COLUMN IF NOT EXISTS "default_account_id" UUID DEFAULT NULL;
+
+ALTER TABLE "users"
+    ADD CONSTRAINT "users_default_account_id_fkey"
+        FOREIGN KEY ("default_account_id")
+        REFERENCES "accounts" ("guid");
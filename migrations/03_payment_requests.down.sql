@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "payment_request_history";
+
+DROP TABLE IF EXISTS "payment_requests";
@@ -0,0 +1,60 @@
+CREATE TABLE IF NOT EXISTS "payment_requests" (
+    "guid" UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    "requester_id" UUID NOT NULL,
+    "requester_account_id" UUID NOT NULL,
+    "payer_id" UUID NOT NULL,
+    "payer_account_id" UUID DEFAULT NULL,
+    "amount" numeric NOT NULL,
+    "memo" varchar(140) NOT NULL DEFAULT '',
+    "status" varchar(16) NOT NULL DEFAULT 'pending',
+    "transaction_id" UUID DEFAULT NULL,
+    "expires_at" TIMESTAMP WITH TIME ZONE NOT NULL,
+    "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "positive_payment_request"
+        CHECK ("amount" > 0.0),
+
+    CONSTRAINT "payment_requests_status_check"
+        CHECK ("status" IN ('pending', 'accepted', 'declined', 'cancelled', 'expired')),
+
+    CONSTRAINT "payment_requests_requester_id_fkey"
+        FOREIGN KEY ("requester_id")
+        REFERENCES "users" ("guid"),
+
+    CONSTRAINT "payment_requests_requester_account_id_fkey"
+        FOREIGN KEY ("requester_account_id")
+        REFERENCES "accounts" ("guid"),
+
+    CONSTRAINT "payment_requests_payer_id_fkey"
+        FOREIGN KEY ("payer_id")
+        REFERENCES "users" ("guid"),
+
+    CONSTRAINT "payment_requests_payer_account_id_fkey"
+        FOREIGN KEY ("payer_account_id")
+        REFERENCES "accounts" ("guid"),
+
+    CONSTRAINT "payment_requests_transaction_id_fkey"
+        FOREIGN KEY ("transaction_id")
+        REFERENCES "transactions" ("guid")
+);
+
+CREATE INDEX "payment_requests_payer_id_status_idx" ON "payment_requests" ("payer_id", "status");
+
+CREATE INDEX "payment_requests_requester_id_status_idx" ON "payment_requests" ("requester_id", "status");
+
+CREATE TABLE IF NOT EXISTS "payment_request_history" (
+    "guid" UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    "payment_request_id" UUID NOT NULL,
+    "from_status" varchar(16) DEFAULT NULL,
+    "to_status" varchar(16) NOT NULL,
+    "actor_id" UUID DEFAULT NULL,
+    "note" varchar(255) NOT NULL DEFAULT '',
+    "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "payment_request_history_payment_request_id_fkey"
+        FOREIGN KEY ("payment_request_id")
+        REFERENCES "payment_requests" ("guid")
+);
+
+CREATE INDEX "payment_request_history_payment_request_id_idx" ON "payment_request_history" ("payment_request_id");
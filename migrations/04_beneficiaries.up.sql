@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS "beneficiaries" (
+    "guid" UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    "user_id" UUID NOT NULL,
+    "nickname" varchar(64) NOT NULL,
+    "account_id" UUID NOT NULL,
+    -- 0 means the transfer is not limited
+    "transfer_limit" numeric NOT NULL DEFAULT 0,
+    "daily_limit" numeric NOT NULL DEFAULT 0,
+    "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    "deleted_at" TIMESTAMP DEFAULT NULL,
+
+    CONSTRAINT "beneficiaries_limits_check"
+        CHECK ("transfer_limit" >= 0.0 AND "daily_limit" >= 0.0),
+
+    CONSTRAINT "beneficiaries_user_id_fkey"
+        FOREIGN KEY ("user_id")
+        REFERENCES "users" ("guid"),
+
+    CONSTRAINT "beneficiaries_account_id_fkey"
+        FOREIGN KEY ("account_id")
+        REFERENCES "accounts" ("guid")
+);
+
+CREATE UNIQUE INDEX "beneficiaries_user_id_nickname_unique" ON "beneficiaries" ("user_id", "nickname") WHERE "deleted_at" IS NULL;
+
+CREATE UNIQUE INDEX "beneficiaries_user_id_account_id_unique" ON "beneficiaries" ("user_id", "account_id") WHERE "deleted_at" IS NULL;
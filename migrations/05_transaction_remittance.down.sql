@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS "transactions_description_fts_idx";
+
+DROP INDEX IF EXISTS "transactions_reference_idx";
+
+ALTER TABLE "transactions"
+    DROP COLUMN IF EXISTS "description",
+    DROP COLUMN IF EXISTS "reference";
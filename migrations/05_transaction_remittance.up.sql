@@ -0,0 +1,7 @@
+ALTER TABLE "transactions"
+    ADD COLUMN IF NOT EXISTS "description" varchar(140) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS "reference" varchar(35) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS "transactions_reference_idx" ON "transactions" ("reference") WHERE "reference" <> '';
+
+CREATE INDEX IF NOT EXISTS "transactions_description_fts_idx" ON "transactions" USING GIN (to_tsvector('simple', "description"));
@@ -0,0 +1,7 @@
+CREATE INDEX IF NOT EXISTS "transactions_account_id_created_at_guid_idx"
+    ON "transactions" ("account_id", "created_at", "guid")
+    WHERE "deleted_at" IS NULL;
+
+CREATE INDEX IF NOT EXISTS "accounts_user_id_created_at_guid_idx"
+    ON "accounts" ("user_id", "created_at", "guid")
+    WHERE "deleted_at" = 0;
@@ -0,0 +1,26 @@
+DROP TABLE IF EXISTS "job_runs";
+
+DROP TABLE IF EXISTS "interest_capitalizations";
+
+DROP TABLE IF EXISTS "interest_accruals";
+
+DELETE FROM "transactions"
+WHERE "account_id" IN (SELECT "guid" FROM "accounts" WHERE "system")
+    OR "recipient_id" IN (SELECT "guid" FROM "accounts" WHERE "system");
+
+DELETE FROM "accounts" WHERE "system";
+
+DELETE FROM "users" WHERE "guid" = '00000000-0000-4000-8000-000000000001';
+
+ALTER TABLE "accounts" DROP CONSTRAINT IF EXISTS "positive_balance";
+ALTER TABLE "accounts" ADD CONSTRAINT "positive_balance" CHECK ("balance" >= 0.0);
+
+ALTER TABLE "accounts"
+    DROP CONSTRAINT IF EXISTS "accounts_product_fkey",
+    DROP COLUMN IF EXISTS "system",
+    DROP COLUMN IF EXISTS "accrued_interest",
+    DROP COLUMN IF EXISTS "product";
+
+CREATE UNIQUE INDEX IF NOT EXISTS "accounts_user_id_deleted_at_unique" ON "accounts" ("user_id", "deleted_at");
+
+DROP TABLE IF EXISTS "products";
@@ -0,0 +1,97 @@
+CREATE TABLE IF NOT EXISTS "products" (
+    "code" varchar(32) PRIMARY KEY,
+    "name" varchar(255) NOT NULL,
+    -- annual rate as a fraction, 0.04 is 4%
+    "annual_rate" numeric NOT NULL DEFAULT 0,
+    "day_count" varchar(16) NOT NULL DEFAULT 'ACT/365',
+    "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "products_annual_rate_check"
+        CHECK ("annual_rate" >= 0.0),
+
+    CONSTRAINT "products_day_count_check"
+        CHECK ("day_count" IN ('ACT/365', '30/360'))
+);
+
+INSERT INTO "products" ("code", "name", "annual_rate", "day_count") VALUES
+    ('current', 'Current account', 0, 'ACT/365'),
+    ('savings', 'Savings account', 0.04, 'ACT/365')
+ON CONFLICT DO NOTHING;
+
+-- users may now hold several accounts of different products
+DROP INDEX IF EXISTS "accounts_user_id_deleted_at_unique";
+
+ALTER TABLE "accounts"
+    ADD COLUMN IF NOT EXISTS "product" varchar(32) NOT NULL DEFAULT 'current',
+    -- interest accrued but not yet capitalized, kept with full precision
+    ADD COLUMN IF NOT EXISTS "accrued_interest" numeric NOT NULL DEFAULT 0,
+    -- bank-owned ledger accounts may go below zero
+    ADD COLUMN IF NOT EXISTS "system" BOOLEAN NOT NULL DEFAULT false,
+    ADD CONSTRAINT "accounts_product_fkey"
+        FOREIGN KEY ("product")
+        REFERENCES "products" ("code");
+
+ALTER TABLE "accounts" DROP CONSTRAINT IF EXISTS "positive_balance";
+ALTER TABLE "accounts" ADD CONSTRAINT "positive_balance" CHECK ("balance" >= 0.0 OR "system");
+
+-- the bank itself owns the system ledger accounts, its password can never be matched
+INSERT INTO "users" ("guid", "first_name", "last_name", "phone", "password") VALUES
+    ('00000000-0000-4000-8000-000000000001', 'Bank', 'System', 'system', '!')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO "accounts" ("guid", "user_id", "balance", "system") VALUES
+    ('00000000-0000-4000-8000-000000000101', '00000000-0000-4000-8000-000000000001', 0, true)
+ON CONFLICT DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS "interest_accruals" (
+    "guid" UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    "account_id" UUID NOT NULL,
+    "business_date" DATE NOT NULL,
+    -- end-of-day balance the interest was calculated on
+    "balance" numeric NOT NULL,
+    "annual_rate" numeric NOT NULL,
+    "day_count" varchar(16) NOT NULL,
+    "amount" numeric NOT NULL,
+    "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "interest_accruals_account_id_fkey"
+        FOREIGN KEY ("account_id")
+        REFERENCES "accounts" ("guid")
+);
+
+CREATE UNIQUE INDEX "interest_accruals_account_id_business_date_unique" ON "interest_accruals" ("account_id", "business_date");
+
+CREATE TABLE IF NOT EXISTS "interest_capitalizations" (
+    "guid" UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    "account_id" UUID NOT NULL,
+    -- first day of the capitalized month
+    "period" DATE NOT NULL,
+    "amount" numeric NOT NULL,
+    "transaction_id" UUID NOT NULL,
+    "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "interest_capitalizations_account_id_fkey"
+        FOREIGN KEY ("account_id")
+        REFERENCES "accounts" ("guid"),
+
+    CONSTRAINT "interest_capitalizations_transaction_id_fkey"
+        FOREIGN KEY ("transaction_id")
+        REFERENCES "transactions" ("guid")
+);
+
+CREATE UNIQUE INDEX "interest_capitalizations_account_id_period_unique" ON "interest_capitalizations" ("account_id", "period");
+
+CREATE TABLE IF NOT EXISTS "job_runs" (
+    "job" varchar(64) NOT NULL,
+    "business_date" DATE NOT NULL,
+    "status" varchar(16) NOT NULL,
+    "error" text,
+    "started_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    "finished_at" TIMESTAMP WITH TIME ZONE,
+
+    PRIMARY KEY ("job", "business_date"),
+
+    CONSTRAINT "job_runs_status_check"
+        CHECK ("status" IN ('running', 'done', 'failed'))
+);
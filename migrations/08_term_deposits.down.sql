@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "term_deposits";
+
+DROP TABLE IF EXISTS "term_deposit_terms";
+
+DELETE FROM "products" WHERE "code" = 'term_deposit';
@@ -0,0 +1,86 @@
+INSERT INTO "products" ("code", "name", "annual_rate", "day_count") VALUES
+    -- deposit accounts only hold the locked principal, their interest comes from the deposit terms
+    ('term_deposit', 'Term deposit', 0, 'ACT/365')
+ON CONFLICT DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS "term_deposit_terms" (
+    "term_months" INTEGER PRIMARY KEY,
+    "annual_rate" numeric NOT NULL,
+    -- rate paid instead of annual_rate when the deposit is withdrawn before maturity
+    "early_withdrawal_rate" numeric NOT NULL DEFAULT 0,
+    "day_count" varchar(16) NOT NULL DEFAULT 'ACT/365',
+    "min_amount" numeric NOT NULL DEFAULT 0,
+    "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "term_deposit_terms_check"
+        CHECK ("term_months" > 0 AND "annual_rate" >= 0.0 AND "early_withdrawal_rate" >= 0.0 AND "min_amount" >= 0.0),
+
+    CONSTRAINT "term_deposit_terms_day_count_check"
+        CHECK ("day_count" IN ('ACT/365', '30/360'))
+);
+
+INSERT INTO "term_deposit_terms" ("term_months", "annual_rate", "early_withdrawal_rate", "min_amount") VALUES
+    (3, 0.05, 0.01, 100),
+    (6, 0.06, 0.01, 100),
+    (12, 0.07, 0.01, 100)
+ON CONFLICT DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS "term_deposits" (
+    "guid" UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    "user_id" UUID NOT NULL,
+    -- the account holding the locked principal
+    "account_id" UUID NOT NULL,
+    -- the account the deposit was funded from, payouts go back to it
+    "source_account_id" UUID NOT NULL,
+    "principal" numeric NOT NULL,
+    "term_months" INTEGER NOT NULL,
+    "annual_rate" numeric NOT NULL,
+    "early_withdrawal_rate" numeric NOT NULL,
+    "day_count" varchar(16) NOT NULL,
+    "interest_payout" varchar(16) NOT NULL,
+    "on_maturity" varchar(16) NOT NULL,
+    "status" varchar(16) NOT NULL DEFAULT 'active',
+    "opened_on" DATE NOT NULL,
+    "maturity_date" DATE NOT NULL,
+    -- interest is paid for the days before this date
+    "interest_paid_until" DATE NOT NULL,
+    "interest_periods_paid" INTEGER NOT NULL DEFAULT 0,
+    "paid_interest" numeric NOT NULL DEFAULT 0,
+    "rolled_from_id" UUID,
+    "closed_at" TIMESTAMP WITH TIME ZONE,
+    "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "term_deposits_principal_check"
+        CHECK ("principal" > 0.0),
+
+    CONSTRAINT "term_deposits_interest_payout_check"
+        CHECK ("interest_payout" IN ('maturity', 'monthly')),
+
+    CONSTRAINT "term_deposits_on_maturity_check"
+        CHECK ("on_maturity" IN ('payout', 'rollover')),
+
+    CONSTRAINT "term_deposits_status_check"
+        CHECK ("status" IN ('active', 'matured', 'rolled_over', 'withdrawn')),
+
+    CONSTRAINT "term_deposits_user_id_fkey"
+        FOREIGN KEY ("user_id")
+        REFERENCES "users" ("guid"),
+
+    CONSTRAINT "term_deposits_account_id_fkey"
+        FOREIGN KEY ("account_id")
+        REFERENCES "accounts" ("guid"),
+
+    CONSTRAINT "term_deposits_source_account_id_fkey"
+        FOREIGN KEY ("source_account_id")
+        REFERENCES "accounts" ("guid"),
+
+    CONSTRAINT "term_deposits_rolled_from_id_fkey"
+        FOREIGN KEY ("rolled_from_id")
+        REFERENCES "term_deposits" ("guid")
+);
+
+CREATE INDEX "term_deposits_user_id_idx" ON "term_deposits" ("user_id", "created_at");
+
+CREATE INDEX "term_deposits_active_idx" ON "term_deposits" ("maturity_date") WHERE "status" = 'active';
@@ -0,0 +1,21 @@
+DROP TABLE IF EXISTS "overdraft_charges";
+
+DROP TABLE IF EXISTS "overdraft_accruals";
+
+DELETE FROM "transactions"
+WHERE "account_id" = '00000000-0000-4000-8000-000000000102'
+    OR "recipient_id" = '00000000-0000-4000-8000-000000000102';
+
+DELETE FROM "accounts" WHERE "guid" = '00000000-0000-4000-8000-000000000102';
+
+ALTER TABLE "accounts" DROP CONSTRAINT IF EXISTS "balance_within_overdraft";
+ALTER TABLE "accounts" ADD CONSTRAINT "positive_balance" CHECK ("balance" >= 0.0 OR "system");
+
+ALTER TABLE "accounts"
+    DROP CONSTRAINT IF EXISTS "accounts_overdraft_limit_check",
+    DROP COLUMN IF EXISTS "accrued_overdraft_interest",
+    DROP COLUMN IF EXISTS "overdraft_limit";
+
+ALTER TABLE "products"
+    DROP CONSTRAINT IF EXISTS "products_overdraft_rate_check",
+    DROP COLUMN IF EXISTS "overdraft_rate";
@@ -0,0 +1,61 @@
+-- debit interest charged on the overdrawn part of the balance, as a fraction
+ALTER TABLE "products"
+    ADD COLUMN IF NOT EXISTS "overdraft_rate" numeric NOT NULL DEFAULT 0,
+    ADD CONSTRAINT "products_overdraft_rate_check"
+        CHECK ("overdraft_rate" >= 0.0);
+
+UPDATE "products" SET "overdraft_rate" = 0.20 WHERE "code" = 'current';
+
+ALTER TABLE "accounts"
+    -- opt-in, zero keeps the account from going below zero
+    ADD COLUMN IF NOT EXISTS "overdraft_limit" numeric NOT NULL DEFAULT 0,
+    -- overdraft interest accrued but not yet charged, kept with full precision
+    ADD COLUMN IF NOT EXISTS "accrued_overdraft_interest" numeric NOT NULL DEFAULT 0,
+    ADD CONSTRAINT "accounts_overdraft_limit_check"
+        CHECK ("overdraft_limit" >= 0.0);
+
+ALTER TABLE "accounts" DROP CONSTRAINT IF EXISTS "positive_balance";
+ALTER TABLE "accounts" ADD CONSTRAINT "balance_within_overdraft" CHECK ("balance" >= -"overdraft_limit" OR "system");
+
+-- overdraft interest is booked as income of the bank
+INSERT INTO "accounts" ("guid", "user_id", "balance", "system") VALUES
+    ('00000000-0000-4000-8000-000000000102', '00000000-0000-4000-8000-000000000001', 0, true)
+ON CONFLICT DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS "overdraft_accruals" (
+    "guid" UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    "account_id" UUID NOT NULL,
+    "business_date" DATE NOT NULL,
+    -- end-of-day balance, negative
+    "balance" numeric NOT NULL,
+    "annual_rate" numeric NOT NULL,
+    "day_count" varchar(16) NOT NULL,
+    "amount" numeric NOT NULL,
+    "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "overdraft_accruals_account_id_fkey"
+        FOREIGN KEY ("account_id")
+        REFERENCES "accounts" ("guid")
+);
+
+CREATE UNIQUE INDEX "overdraft_accruals_account_id_business_date_unique" ON "overdraft_accruals" ("account_id", "business_date");
+
+CREATE TABLE IF NOT EXISTS "overdraft_charges" (
+    "guid" UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    "account_id" UUID NOT NULL,
+    -- first day of the charged month
+    "period" DATE NOT NULL,
+    "amount" numeric NOT NULL,
+    "transaction_id" UUID NOT NULL,
+    "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "overdraft_charges_account_id_fkey"
+        FOREIGN KEY ("account_id")
+        REFERENCES "accounts" ("guid"),
+
+    CONSTRAINT "overdraft_charges_transaction_id_fkey"
+        FOREIGN KEY ("transaction_id")
+        REFERENCES "transactions" ("guid")
+);
+
+CREATE UNIQUE INDEX "overdraft_charges_account_id_period_unique" ON "overdraft_charges" ("account_id", "period");
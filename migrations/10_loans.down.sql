@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS "loan_installments";
+
+DROP TABLE IF EXISTS "loans";
+
+DELETE FROM "transactions"
+WHERE "account_id" IN ('00000000-0000-4000-8000-000000000103', '00000000-0000-4000-8000-000000000104')
+    OR "recipient_id" IN ('00000000-0000-4000-8000-000000000103', '00000000-0000-4000-8000-000000000104');
+
+DELETE FROM "accounts" WHERE "guid" IN ('00000000-0000-4000-8000-000000000103', '00000000-0000-4000-8000-000000000104');
+
+DROP TABLE IF EXISTS "loan_products";
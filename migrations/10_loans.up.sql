@@ -0,0 +1,106 @@
+CREATE TABLE IF NOT EXISTS "loan_products" (
+    "code" varchar(32) PRIMARY KEY,
+    "name" varchar(255) NOT NULL,
+    -- annual rate as a fraction, charged monthly at a twelfth
+    "annual_rate" numeric NOT NULL,
+    "min_amount" numeric NOT NULL,
+    "max_amount" numeric NOT NULL,
+    "min_term_months" INTEGER NOT NULL,
+    "max_term_months" INTEGER NOT NULL,
+    -- charged once per installment still unpaid after the grace days
+    "late_fee" numeric NOT NULL DEFAULT 0,
+    "grace_days" INTEGER NOT NULL DEFAULT 0,
+    "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "loan_products_amount_check"
+        CHECK ("min_amount" > 0.0 AND "max_amount" >= "min_amount"),
+
+    CONSTRAINT "loan_products_term_check"
+        CHECK ("min_term_months" > 0 AND "max_term_months" >= "min_term_months")
+);
+
+INSERT INTO "loan_products" ("code", "name", "annual_rate", "min_amount", "max_amount", "min_term_months", "max_term_months", "late_fee", "grace_days") VALUES
+    ('consumer', 'Consumer loan', 0.18, 500, 50000, 3, 60, 10, 3)
+ON CONFLICT DO NOTHING;
+
+-- disbursements come out of the loan portfolio and repaid principal returns to it,
+-- late fees are income of the bank
+INSERT INTO "accounts" ("guid", "user_id", "balance", "system") VALUES
+    ('00000000-0000-4000-8000-000000000103', '00000000-0000-4000-8000-000000000001', 0, true),
+    ('00000000-0000-4000-8000-000000000104', '00000000-0000-4000-8000-000000000001', 0, true)
+ON CONFLICT DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS "loans" (
+    "guid" UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    "user_id" UUID NOT NULL,
+    -- receives the disbursement and is debited for the installments
+    "account_id" UUID NOT NULL,
+    "product" varchar(32) NOT NULL,
+    "principal" numeric NOT NULL,
+    "annual_rate" numeric NOT NULL,
+    "term_months" INTEGER NOT NULL,
+    "schedule_type" varchar(16) NOT NULL,
+    "late_fee" numeric NOT NULL,
+    "grace_days" INTEGER NOT NULL,
+    "status" varchar(16) NOT NULL DEFAULT 'active',
+    "outstanding_principal" numeric NOT NULL,
+    "late_fees_due" numeric NOT NULL DEFAULT 0,
+    "disbursed_on" DATE NOT NULL,
+    "disbursement_transaction_id" UUID NOT NULL,
+    "closed_at" TIMESTAMP WITH TIME ZONE,
+    "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "loans_user_id_fkey"
+        FOREIGN KEY ("user_id")
+        REFERENCES "users" ("guid"),
+
+    CONSTRAINT "loans_account_id_fkey"
+        FOREIGN KEY ("account_id")
+        REFERENCES "accounts" ("guid"),
+
+    CONSTRAINT "loans_product_fkey"
+        FOREIGN KEY ("product")
+        REFERENCES "loan_products" ("code"),
+
+    CONSTRAINT "loans_disbursement_transaction_id_fkey"
+        FOREIGN KEY ("disbursement_transaction_id")
+        REFERENCES "transactions" ("guid"),
+
+    CONSTRAINT "loans_schedule_type_check"
+        CHECK ("schedule_type" IN ('annuity', 'differentiated')),
+
+    CONSTRAINT "loans_status_check"
+        CHECK ("status" IN ('active', 'delinquent', 'defaulted', 'repaid')),
+
+    CONSTRAINT "loans_outstanding_principal_check"
+        CHECK ("outstanding_principal" >= 0.0)
+);
+
+CREATE INDEX "loans_user_id_created_at_idx" ON "loans" ("user_id", "created_at" DESC);
+
+CREATE TABLE IF NOT EXISTS "loan_installments" (
+    "guid" UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    "loan_id" UUID NOT NULL,
+    "number" INTEGER NOT NULL,
+    "due_date" DATE NOT NULL,
+    "principal" numeric NOT NULL,
+    "interest" numeric NOT NULL,
+    "late_fee" numeric NOT NULL DEFAULT 0,
+    "status" varchar(16) NOT NULL DEFAULT 'pending',
+    "paid_at" TIMESTAMP WITH TIME ZONE,
+    "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "loan_installments_loan_id_fkey"
+        FOREIGN KEY ("loan_id")
+        REFERENCES "loans" ("guid"),
+
+    CONSTRAINT "loan_installments_status_check"
+        CHECK ("status" IN ('pending', 'overdue', 'paid'))
+);
+
+CREATE UNIQUE INDEX "loan_installments_loan_id_number_unique" ON "loan_installments" ("loan_id", "number");
+
+-- the repayment job looks for unpaid installments that fell due
+CREATE INDEX "loan_installments_due_date_unpaid_idx" ON "loan_installments" ("due_date") WHERE "status" <> 'paid';
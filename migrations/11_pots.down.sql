@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS "pots";
+
+DELETE FROM "transactions" WHERE "account_id" IN (SELECT "guid" FROM "accounts" WHERE "product" = 'pot');
+
+DELETE FROM "accounts" WHERE "product" = 'pot';
+
+DELETE FROM "products" WHERE "code" = 'pot';
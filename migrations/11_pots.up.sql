@@ -0,0 +1,42 @@
+INSERT INTO "products" ("code", "name", "annual_rate", "day_count") VALUES
+    -- a pot's money is held on its own account, set aside from the main balance
+    ('pot', 'Savings pot', 0, 'ACT/365')
+ON CONFLICT DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS "pots" (
+    "guid" UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    "user_id" UUID NOT NULL,
+    -- the main account the pot belongs to, money moves between it and the pot
+    "account_id" UUID NOT NULL,
+    -- the account holding the pot's balance
+    "pot_account_id" UUID NOT NULL UNIQUE,
+    "name" varchar(64) NOT NULL,
+    "target_amount" numeric,
+    "target_date" DATE,
+    -- debits from the main account are rounded up to a multiple of it and the spare change moved to the pot
+    "round_up_to" numeric,
+    "status" varchar(16) NOT NULL DEFAULT 'active',
+    "closed_at" TIMESTAMP WITH TIME ZONE,
+    "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "pots_account_fk"
+        FOREIGN KEY ("account_id")
+        REFERENCES "accounts" ("guid"),
+
+    CONSTRAINT "pots_pot_account_fk"
+        FOREIGN KEY ("pot_account_id")
+        REFERENCES "accounts" ("guid"),
+
+    CONSTRAINT "pots_check"
+        CHECK (("target_amount" IS NULL OR "target_amount" > 0.0) AND ("round_up_to" IS NULL OR "round_up_to" > 0.0)),
+
+    CONSTRAINT "pots_status_check"
+        CHECK ("status" IN ('active', 'closed'))
+);
+
+CREATE INDEX IF NOT EXISTS "pots_account_id_idx" ON "pots" ("account_id");
+
+-- only one pot of an account collects the round-ups
+CREATE UNIQUE INDEX IF NOT EXISTS "pots_round_up_idx" ON "pots" ("account_id")
+    WHERE "round_up_to" IS NOT NULL AND "status" = 'active';
@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS "transfer_approvals";
+
+DROP TABLE IF EXISTS "account_invitations";
+
+ALTER TABLE "accounts"
+    DROP CONSTRAINT IF EXISTS "accounts_dual_approval_threshold_check",
+    DROP COLUMN IF EXISTS "dual_approval_threshold";
+
+DROP TRIGGER IF EXISTS "accounts_add_owner" ON "accounts";
+
+DROP FUNCTION IF EXISTS "add_account_owner"();
+
+DROP TABLE IF EXISTS "account_holders";
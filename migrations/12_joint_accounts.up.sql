@@ -0,0 +1,112 @@
+-- an account may be held by several users, accounts.user_id stays the user who opened it
+CREATE TABLE IF NOT EXISTS "account_holders" (
+    "account_id" UUID NOT NULL,
+    "user_id" UUID NOT NULL,
+    "role" varchar(16) NOT NULL,
+    "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY ("account_id", "user_id"),
+
+    CONSTRAINT "account_holders_account_fk"
+        FOREIGN KEY ("account_id")
+        REFERENCES "accounts" ("guid"),
+
+    CONSTRAINT "account_holders_user_fk"
+        FOREIGN KEY ("user_id")
+        REFERENCES "users" ("guid"),
+
+    CONSTRAINT "account_holders_role_check"
+        CHECK ("role" IN ('owner', 'co_owner', 'viewer', 'signer'))
+);
+
+CREATE INDEX IF NOT EXISTS "account_holders_user_id_idx" ON "account_holders" ("user_id");
+
+-- every account has exactly one owner
+CREATE UNIQUE INDEX IF NOT EXISTS "account_holders_owner_idx" ON "account_holders" ("account_id")
+    WHERE "role" = 'owner';
+
+INSERT INTO "account_holders" ("account_id", "user_id", "role")
+SELECT "guid", "user_id", 'owner' FROM "accounts" WHERE NOT "system"
+ON CONFLICT DO NOTHING;
+
+-- the user opening a customer account becomes its owner, whichever feature opens it
+CREATE OR REPLACE FUNCTION "add_account_owner"() RETURNS TRIGGER AS $$
+BEGIN
+    IF NOT NEW."system" THEN
+        INSERT INTO "account_holders" ("account_id", "user_id", "role")
+        VALUES (NEW."guid", NEW."user_id", 'owner')
+        ON CONFLICT DO NOTHING;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "accounts_add_owner"
+    AFTER INSERT ON "accounts"
+    FOR EACH ROW EXECUTE FUNCTION "add_account_owner"();
+
+ALTER TABLE "accounts"
+    -- transfers above it wait for a second holder's approval, zero turns dual approval off
+    ADD COLUMN IF NOT EXISTS "dual_approval_threshold" numeric NOT NULL DEFAULT 0,
+    ADD CONSTRAINT "accounts_dual_approval_threshold_check"
+        CHECK ("dual_approval_threshold" >= 0.0);
+
+CREATE TABLE IF NOT EXISTS "account_invitations" (
+    "guid" UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    "account_id" UUID NOT NULL,
+    "invited_by" UUID NOT NULL,
+    "user_id" UUID NOT NULL,
+    "role" varchar(16) NOT NULL,
+    "status" varchar(16) NOT NULL DEFAULT 'pending',
+    "expires_at" TIMESTAMP WITH TIME ZONE NOT NULL,
+    "responded_at" TIMESTAMP WITH TIME ZONE,
+    "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "account_invitations_account_fk"
+        FOREIGN KEY ("account_id")
+        REFERENCES "accounts" ("guid"),
+
+    CONSTRAINT "account_invitations_user_fk"
+        FOREIGN KEY ("user_id")
+        REFERENCES "users" ("guid"),
+
+    -- the owner role is never handed over by an invitation
+    CONSTRAINT "account_invitations_role_check"
+        CHECK ("role" IN ('co_owner', 'viewer', 'signer')),
+
+    CONSTRAINT "account_invitations_status_check"
+        CHECK ("status" IN ('pending', 'accepted', 'declined', 'revoked'))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "account_invitations_pending_idx" ON "account_invitations" ("account_id", "user_id")
+    WHERE "status" = 'pending';
+
+CREATE TABLE IF NOT EXISTS "transfer_approvals" (
+    "guid" UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    "account_id" UUID NOT NULL,
+    "requested_by" UUID NOT NULL,
+    "to_account_id" UUID NOT NULL,
+    "amount" numeric NOT NULL,
+    "description" varchar(140) NOT NULL DEFAULT '',
+    "reference" varchar(35) NOT NULL DEFAULT '',
+    "status" varchar(16) NOT NULL DEFAULT 'pending',
+    "decided_by" UUID,
+    -- the debit leg of the transfer made on approval
+    "transaction_id" UUID,
+    "expires_at" TIMESTAMP WITH TIME ZONE NOT NULL,
+    "decided_at" TIMESTAMP WITH TIME ZONE,
+    "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "transfer_approvals_account_fk"
+        FOREIGN KEY ("account_id")
+        REFERENCES "accounts" ("guid"),
+
+    CONSTRAINT "transfer_approvals_amount_check"
+        CHECK ("amount" > 0.0),
+
+    CONSTRAINT "transfer_approvals_status_check"
+        CHECK ("status" IN ('pending', 'approved', 'rejected', 'expired'))
+);
+
+CREATE INDEX IF NOT EXISTS "transfer_approvals_account_id_idx" ON "transfer_approvals" ("account_id", "created_at");
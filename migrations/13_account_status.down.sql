@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS "account_status_history";
+
+ALTER TABLE "accounts"
+    DROP CONSTRAINT IF EXISTS "accounts_status_check",
+    DROP COLUMN IF EXISTS "closed_at",
+    DROP COLUMN IF EXISTS "status";
@@ -0,0 +1,31 @@
+ALTER TABLE "accounts"
+    -- frozen accounts take credits but no debits, closed accounts take neither
+    ADD COLUMN IF NOT EXISTS "status" varchar(16) NOT NULL DEFAULT 'active',
+    ADD COLUMN IF NOT EXISTS "closed_at" TIMESTAMP WITH TIME ZONE,
+    ADD CONSTRAINT "accounts_status_check"
+        CHECK ("status" IN ('active', 'frozen', 'closed'));
+
+-- accounts soft deleted before statuses existed count as closed
+UPDATE "accounts" SET "status" = 'closed', "closed_at" = COALESCE("updated_at", CURRENT_TIMESTAMP)
+WHERE "deleted_at" <> 0;
+
+CREATE TABLE IF NOT EXISTS "account_status_history" (
+    "guid" UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    "account_id" UUID NOT NULL,
+    "from_status" varchar(16) NOT NULL,
+    "to_status" varchar(16) NOT NULL,
+    -- the user who made the change, empty for the bank's staff
+    "actor_id" UUID,
+    "actor_type" varchar(16) NOT NULL,
+    "reason" varchar(255) NOT NULL DEFAULT '',
+    "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "account_status_history_account_fk"
+        FOREIGN KEY ("account_id")
+        REFERENCES "accounts" ("guid"),
+
+    CONSTRAINT "account_status_history_actor_type_check"
+        CHECK ("actor_type" IN ('user', 'admin'))
+);
+
+CREATE INDEX IF NOT EXISTS "account_status_history_account_id_idx" ON "account_status_history" ("account_id", "created_at");
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "otp_codes";
+
+ALTER TABLE "users" ADD CONSTRAINT "users_phone_key" UNIQUE ("phone");
@@ -0,0 +1,22 @@
+-- a soft deleted user's phone can be registered again, the live phones stay unique through "users_phone_deleted_at_unique"
+ALTER TABLE "users" DROP CONSTRAINT IF EXISTS "users_phone_key";
+
+CREATE TABLE IF NOT EXISTS "otp_codes" (
+    "guid" UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    "user_id" UUID NOT NULL,
+    "purpose" varchar(32) NOT NULL,
+    -- the phone the code was sent to
+    "target" varchar NOT NULL,
+    -- only the sha256 of the code is kept
+    "code_hash" varchar(64) NOT NULL,
+    "attempts" INTEGER NOT NULL DEFAULT 0,
+    "expires_at" TIMESTAMP WITH TIME ZONE NOT NULL,
+    "used_at" TIMESTAMP WITH TIME ZONE,
+    "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "otp_codes_user_fk"
+        FOREIGN KEY ("user_id")
+        REFERENCES "users" ("guid")
+);
+
+CREATE INDEX IF NOT EXISTS "otp_codes_user_id_purpose_idx" ON "otp_codes" ("user_id", "purpose", "created_at");
@@ -0,0 +1,2 @@
+-- tokens issued at or before this unix time are rejected, a password reset signs the user out everywhere
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "sessions_revoked_at" INTEGER NOT NULL DEFAULT 0;
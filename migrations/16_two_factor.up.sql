@@ -0,0 +1,21 @@
+-- the secret is kept as is, the codes have to be computed from it
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "totp_secret" varchar(64);
+-- set once the first code is confirmed, 2FA is off while it is NULL
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "totp_enabled_at" TIMESTAMP WITH TIME ZONE;
+-- the step of the last accepted code, a code can't be used twice
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "totp_last_step" BIGINT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS "mfa_recovery_codes" (
+    "guid" UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    "user_id" UUID NOT NULL,
+    -- only the sha256 of the code is kept
+    "code_hash" varchar(64) NOT NULL,
+    "used_at" TIMESTAMP WITH TIME ZONE,
+    "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "mfa_recovery_codes_user_fk"
+        FOREIGN KEY ("user_id")
+        REFERENCES "users" ("guid")
+);
+
+CREATE INDEX IF NOT EXISTS "mfa_recovery_codes_user_id_idx" ON "mfa_recovery_codes" ("user_id");
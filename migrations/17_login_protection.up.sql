@@ -0,0 +1,20 @@
+-- failed logins are counted per phone and per IP, the key is "phone:<phone>" or "ip:<address>"
+CREATE TABLE IF NOT EXISTS "login_failures" (
+    "key" varchar(128) PRIMARY KEY,
+    "failures" INTEGER NOT NULL DEFAULT 0,
+    "last_failed_at" TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    -- no login is tried for the key before this time
+    "locked_until" TIMESTAMP WITH TIME ZONE
+);
+
+CREATE TABLE IF NOT EXISTS "audit_events" (
+    "guid" UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    "event_type" varchar(64) NOT NULL,
+    -- the user the event is about, NULL when there is none such as a login with an unknown phone
+    "user_id" UUID,
+    "ip" varchar(64) NOT NULL DEFAULT '',
+    "details" JSONB NOT NULL DEFAULT '{}',
+    "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS "audit_events_user_id_idx" ON "audit_events" ("user_id", "created_at");
@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS "manual_adjustments";
+
+DELETE FROM "transactions"
+WHERE "account_id" = '00000000-0000-4000-8000-000000000105'
+    OR "recipient_id" = '00000000-0000-4000-8000-000000000105';
+
+DELETE FROM "accounts" WHERE "guid" = '00000000-0000-4000-8000-000000000105';
+
+ALTER TABLE "users" DROP CONSTRAINT IF EXISTS "users_role_check";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "role";
@@ -0,0 +1,32 @@
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "role" varchar(32) NOT NULL DEFAULT 'customer';
+ALTER TABLE "users" ADD CONSTRAINT "users_role_check"
+    CHECK ("role" IN ('customer', 'support', 'compliance', 'admin'));
+
+-- manual adjustments by the staff are booked against this account
+INSERT INTO "accounts" ("guid", "user_id", "balance", "system") VALUES
+    ('00000000-0000-4000-8000-000000000105', '00000000-0000-4000-8000-000000000001', 0, true)
+ON CONFLICT DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS "manual_adjustments" (
+    "guid" UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    "account_id" UUID NOT NULL,
+    -- credit adds to the account, debit takes from it
+    "direction" varchar(8) NOT NULL,
+    "amount" numeric NOT NULL,
+    "reason_code" varchar(32) NOT NULL,
+    "comment" varchar(255) NOT NULL DEFAULT '',
+    -- the staff member who made it
+    "actor_id" UUID NOT NULL,
+    -- the customer's leg of the posting
+    "transaction_id" UUID NOT NULL,
+    "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "manual_adjustments_account_fk"
+        FOREIGN KEY ("account_id")
+        REFERENCES "accounts" ("guid"),
+    CONSTRAINT "manual_adjustments_actor_fk"
+        FOREIGN KEY ("actor_id")
+        REFERENCES "users" ("guid")
+);
+
+CREATE INDEX IF NOT EXISTS "manual_adjustments_account_id_idx" ON "manual_adjustments" ("account_id", "created_at");
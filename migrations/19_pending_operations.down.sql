@@ -0,0 +1,3 @@
+ALTER TABLE "manual_adjustments" DROP COLUMN IF EXISTS "approved_by";
+
+DROP TABLE IF EXISTS "pending_operations";
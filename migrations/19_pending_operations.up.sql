@@ -0,0 +1,42 @@
+CREATE TABLE IF NOT EXISTS "pending_operations" (
+    "guid" UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    "operation_type" varchar(32) NOT NULL,
+    -- the account or transaction the operation is made on
+    "resource_id" UUID NOT NULL,
+    "payload" JSONB NOT NULL DEFAULT '{}',
+    "status" varchar(16) NOT NULL DEFAULT 'pending',
+    -- the maker, who proposed it
+    "proposed_by" UUID NOT NULL,
+    -- the checker, who approved or rejected it
+    "decided_by" UUID,
+    "comment" varchar(255) NOT NULL DEFAULT '',
+    -- what executing it made, such as the transactions posted
+    "result" JSONB NOT NULL DEFAULT '{}',
+    "expires_at" TIMESTAMP WITH TIME ZONE NOT NULL,
+    "decided_at" TIMESTAMP WITH TIME ZONE,
+    "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "pending_operations_proposed_by_fk"
+        FOREIGN KEY ("proposed_by")
+        REFERENCES "users" ("guid"),
+
+    CONSTRAINT "pending_operations_decided_by_fk"
+        FOREIGN KEY ("decided_by")
+        REFERENCES "users" ("guid"),
+
+    CONSTRAINT "pending_operations_type_check"
+        CHECK ("operation_type" IN ('manual_adjustment', 'account_unfreeze', 'transaction_reversal')),
+
+    CONSTRAINT "pending_operations_status_check"
+        CHECK ("status" IN ('pending', 'approved', 'rejected', 'expired'))
+);
+
+CREATE INDEX IF NOT EXISTS "pending_operations_status_idx" ON "pending_operations" ("status", "created_at");
+
+-- an account waits for one unfreeze at a time and a transaction is reversed only once
+CREATE UNIQUE INDEX IF NOT EXISTS "pending_operations_unfreeze_idx" ON "pending_operations" ("resource_id")
+    WHERE "operation_type" = 'account_unfreeze' AND "status" = 'pending';
+CREATE UNIQUE INDEX IF NOT EXISTS "pending_operations_reversal_idx" ON "pending_operations" ("resource_id")
+    WHERE "operation_type" = 'transaction_reversal' AND "status" IN ('pending', 'approved');
+
+ALTER TABLE "manual_adjustments" ADD COLUMN IF NOT EXISTS "approved_by" UUID REFERENCES "users" ("guid");
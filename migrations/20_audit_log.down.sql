@@ -0,0 +1,18 @@
+DROP TRIGGER IF EXISTS "audit_events_no_truncate" ON "audit_events";
+DROP TRIGGER IF EXISTS "audit_events_no_change" ON "audit_events";
+DROP FUNCTION IF EXISTS audit_events_append_only();
+
+DROP INDEX IF EXISTS "audit_events_resource_idx";
+DROP INDEX IF EXISTS "audit_events_actor_id_idx";
+DROP INDEX IF EXISTS "audit_events_seq_idx";
+
+ALTER TABLE "audit_events"
+    DROP COLUMN IF EXISTS "hash",
+    DROP COLUMN IF EXISTS "prev_hash",
+    DROP COLUMN IF EXISTS "seq",
+    DROP COLUMN IF EXISTS "after",
+    DROP COLUMN IF EXISTS "before",
+    DROP COLUMN IF EXISTS "request_id",
+    DROP COLUMN IF EXISTS "resource_id",
+    DROP COLUMN IF EXISTS "resource_type",
+    DROP COLUMN IF EXISTS "actor_id";
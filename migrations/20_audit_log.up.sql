@@ -0,0 +1,33 @@
+-- audit_events becomes the append-only audit log, every row is chained to the one before it
+-- by hash = sha256(prev_hash || canonical row), see pkg/auditlog
+ALTER TABLE "audit_events"
+    -- the staff member or customer who made the change, NULL for the system
+    ADD COLUMN IF NOT EXISTS "actor_id" UUID,
+    ADD COLUMN IF NOT EXISTS "resource_type" varchar(64) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS "resource_id" varchar(64) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS "request_id" varchar(64) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS "before" JSONB,
+    ADD COLUMN IF NOT EXISTS "after" JSONB,
+    -- the chain order, rows written before the chain existed keep an empty hash
+    ADD COLUMN IF NOT EXISTS "seq" BIGSERIAL,
+    ADD COLUMN IF NOT EXISTS "prev_hash" varchar(64) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS "hash" varchar(64) NOT NULL DEFAULT '';
+
+CREATE UNIQUE INDEX IF NOT EXISTS "audit_events_seq_idx" ON "audit_events" ("seq");
+CREATE INDEX IF NOT EXISTS "audit_events_actor_id_idx" ON "audit_events" ("actor_id", "created_at");
+CREATE INDEX IF NOT EXISTS "audit_events_resource_idx" ON "audit_events" ("resource_type", "resource_id");
+
+-- the log is append-only, the chain catches what is done around the trigger
+CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS "audit_events_no_change" ON "audit_events";
+CREATE TRIGGER "audit_events_no_change" BEFORE UPDATE OR DELETE ON "audit_events"
+    FOR EACH ROW EXECUTE PROCEDURE audit_events_append_only();
+
+DROP TRIGGER IF EXISTS "audit_events_no_truncate" ON "audit_events";
+CREATE TRIGGER "audit_events_no_truncate" BEFORE TRUNCATE ON "audit_events"
+    FOR EACH STATEMENT EXECUTE PROCEDURE audit_events_append_only();
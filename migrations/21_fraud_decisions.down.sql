@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS "transactions_debits_idx";
+DROP TABLE IF EXISTS "fraud_decisions";
@@ -0,0 +1,40 @@
+-- assessments of customer payments where at least one fraud rule fired
+CREATE TABLE IF NOT EXISTS "fraud_decisions" (
+    "guid" UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    "user_id" UUID NOT NULL,
+    "account_id" UUID NOT NULL,
+    "operation" varchar(16) NOT NULL,
+    "counterparty_id" UUID,
+    "amount" numeric NOT NULL,
+    "score" INTEGER NOT NULL,
+    "outcome" varchar(16) NOT NULL,
+    -- the rules that fired with their scores and reasons
+    "hits" JSONB NOT NULL DEFAULT '[]',
+    -- only challenged payments have one: pending until the code is confirmed, passed until
+    -- the payment is retried and used after that
+    "challenge_status" varchar(16),
+    "review_status" varchar(16) NOT NULL DEFAULT 'pending',
+    "reviewed_by" UUID,
+    "review_comment" varchar(255) NOT NULL DEFAULT '',
+    "reviewed_at" TIMESTAMP WITH TIME ZONE,
+    "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "fraud_decisions_operation_check"
+        CHECK ("operation" IN ('transfer', 'withdrawal')),
+
+    CONSTRAINT "fraud_decisions_outcome_check"
+        CHECK ("outcome" IN ('allow', 'challenge', 'block')),
+
+    CONSTRAINT "fraud_decisions_challenge_status_check"
+        CHECK ("challenge_status" IN ('pending', 'passed', 'used')),
+
+    CONSTRAINT "fraud_decisions_review_status_check"
+        CHECK ("review_status" IN ('pending', 'legitimate', 'fraud'))
+);
+
+CREATE INDEX IF NOT EXISTS "fraud_decisions_review_idx" ON "fraud_decisions" ("review_status", "created_at");
+CREATE INDEX IF NOT EXISTS "fraud_decisions_account_id_idx" ON "fraud_decisions" ("account_id", "created_at");
+
+-- the velocity and average checks read a user's recent debits
+CREATE INDEX IF NOT EXISTS "transactions_debits_idx" ON "transactions" ("account_id", "created_at")
+    WHERE "transaction_type" = 'debit' AND "deleted_at" IS NULL;
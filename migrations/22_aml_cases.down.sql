@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS "transactions_created_at_guid_idx";
+DROP TABLE IF EXISTS "aml_monitor_cursor";
+DROP TABLE IF EXISTS "aml_case_history";
+DROP TABLE IF EXISTS "aml_case_transactions";
+DROP TABLE IF EXISTS "aml_cases";
@@ -0,0 +1,87 @@
+-- suspicious activity cases opened by the AML monitoring
+CREATE TABLE IF NOT EXISTS "aml_cases" (
+    "guid" UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    "user_id" UUID NOT NULL,
+    "account_id" UUID NOT NULL,
+    "case_type" varchar(32) NOT NULL,
+    "status" varchar(16) NOT NULL DEFAULT 'open',
+    -- why the first transaction was flagged
+    "reason" varchar(255) NOT NULL DEFAULT '',
+    -- the compliance officer who took the case
+    "assignee_id" UUID,
+    -- the reference of the report filed with the regulator
+    "report_reference" varchar(64) NOT NULL DEFAULT '',
+    "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "aml_cases_account_id_fk"
+        FOREIGN KEY ("account_id")
+        REFERENCES "accounts" ("guid"),
+
+    CONSTRAINT "aml_cases_assignee_id_fk"
+        FOREIGN KEY ("assignee_id")
+        REFERENCES "users" ("guid"),
+
+    CONSTRAINT "aml_cases_type_check"
+        CHECK ("case_type" IN ('cash_threshold', 'structuring', 'rapid_movement')),
+
+    CONSTRAINT "aml_cases_status_check"
+        CHECK ("status" IN ('open', 'investigating', 'reported', 'closed'))
+);
+
+CREATE INDEX IF NOT EXISTS "aml_cases_status_idx" ON "aml_cases" ("status", "created_at");
+
+-- new flags of the same kind on an account go to its case that is still being worked on
+CREATE UNIQUE INDEX IF NOT EXISTS "aml_cases_active_idx" ON "aml_cases" ("account_id", "case_type")
+    WHERE "status" IN ('open', 'investigating');
+
+CREATE TABLE IF NOT EXISTS "aml_case_transactions" (
+    "case_id" UUID NOT NULL,
+    "transaction_id" UUID NOT NULL,
+    "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY ("case_id", "transaction_id"),
+
+    CONSTRAINT "aml_case_transactions_case_id_fk"
+        FOREIGN KEY ("case_id")
+        REFERENCES "aml_cases" ("guid"),
+
+    CONSTRAINT "aml_case_transactions_transaction_id_fk"
+        FOREIGN KEY ("transaction_id")
+        REFERENCES "transactions" ("guid")
+);
+
+CREATE TABLE IF NOT EXISTS "aml_case_history" (
+    "guid" UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    "case_id" UUID NOT NULL,
+    "from_status" varchar(16) NOT NULL DEFAULT '',
+    "to_status" varchar(16) NOT NULL,
+    -- empty when the monitoring opened the case
+    "actor_id" UUID,
+    "comment" varchar(255) NOT NULL DEFAULT '',
+    "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "aml_case_history_case_id_fk"
+        FOREIGN KEY ("case_id")
+        REFERENCES "aml_cases" ("guid")
+);
+
+CREATE INDEX IF NOT EXISTS "aml_case_history_case_id_idx" ON "aml_case_history" ("case_id", "created_at");
+
+-- how far the monitoring has read the transactions, a single row. It starts at the deployment,
+-- move it back to have older transactions checked.
+CREATE TABLE IF NOT EXISTS "aml_monitor_cursor" (
+    "id" INTEGER PRIMARY KEY DEFAULT 1,
+    "last_created_at" TIMESTAMP WITH TIME ZONE NOT NULL,
+    "last_transaction_id" UUID NOT NULL,
+    "updated_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "aml_monitor_cursor_single_row"
+        CHECK ("id" = 1)
+);
+
+INSERT INTO "aml_monitor_cursor" ("id", "last_created_at", "last_transaction_id")
+VALUES (1, CURRENT_TIMESTAMP, '00000000-0000-0000-0000-000000000000')
+ON CONFLICT ("id") DO NOTHING;
+
+CREATE INDEX IF NOT EXISTS "transactions_created_at_guid_idx" ON "transactions" ("created_at", "guid");
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS "sanctions_hits";
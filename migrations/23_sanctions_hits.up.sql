@@ -0,0 +1,34 @@
+-- names of users that matched an entry of the sanctions list, waiting for or past review
+CREATE TABLE IF NOT EXISTS "sanctions_hits" (
+    "guid" UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    "user_id" UUID NOT NULL REFERENCES "users" ("guid"),
+    "source" varchar(16) NOT NULL,
+    -- the user whose action led to the screening when that is not the screened user
+    "requested_by" UUID,
+    "screened_name" varchar(255) NOT NULL,
+    "entry_uid" varchar(32) NOT NULL,
+    "entry_name" varchar(512) NOT NULL,
+    "matched_name" varchar(512) NOT NULL,
+    "programs" TEXT[] NOT NULL DEFAULT '{}',
+    "score" numeric(5, 4) NOT NULL,
+    "status" varchar(16) NOT NULL DEFAULT 'pending',
+    "reviewed_by" UUID,
+    "review_comment" varchar(255) NOT NULL DEFAULT '',
+    "reviewed_at" TIMESTAMP WITH TIME ZONE,
+    "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "sanctions_hits_source_check"
+        CHECK ("source" IN ('registration', 'profile', 'beneficiary')),
+
+    CONSTRAINT "sanctions_hits_status_check"
+        CHECK ("status" IN ('pending', 'confirmed', 'dismissed')),
+
+    -- a user is matched against an entry once, screening again does not reopen a dismissed hit
+    CONSTRAINT "sanctions_hits_user_entry_key" UNIQUE ("user_id", "entry_uid")
+);
+
+CREATE INDEX IF NOT EXISTS "sanctions_hits_status_idx" ON "sanctions_hits" ("status", "created_at");
+
+-- payments look up the unresolved hits of the users on both ends
+CREATE INDEX IF NOT EXISTS "sanctions_hits_blocking_idx" ON "sanctions_hits" ("user_id")
+    WHERE "status" IN ('pending', 'confirmed');
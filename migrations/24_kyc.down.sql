@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "kyc_documents";
+DROP TABLE IF EXISTS "kyc_applications";
+
+ALTER TABLE "users" DROP CONSTRAINT IF EXISTS "users_kyc_tier_check";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "kyc_tier";
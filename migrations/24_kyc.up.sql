@@ -0,0 +1,50 @@
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "kyc_tier" varchar(16) NOT NULL DEFAULT 'unverified';
+ALTER TABLE "users" ADD CONSTRAINT "users_kyc_tier_check"
+    CHECK ("kyc_tier" IN ('unverified', 'basic', 'full'));
+
+-- the bank's own accounts are not capped
+UPDATE "users" SET "kyc_tier" = 'full' WHERE "guid" = '00000000-0000-4000-8000-000000000001';
+
+-- requests of users to be moved to a higher tier, reviewed by the staff
+CREATE TABLE IF NOT EXISTS "kyc_applications" (
+    "guid" UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    "user_id" UUID NOT NULL REFERENCES "users" ("guid"),
+    "tier" varchar(16) NOT NULL,
+    "status" varchar(16) NOT NULL DEFAULT 'pending',
+    "reviewed_by" UUID,
+    "review_comment" varchar(255) NOT NULL DEFAULT '',
+    "reviewed_at" TIMESTAMP WITH TIME ZONE,
+    "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "kyc_applications_tier_check"
+        CHECK ("tier" IN ('basic', 'full')),
+
+    CONSTRAINT "kyc_applications_status_check"
+        CHECK ("status" IN ('pending', 'approved', 'rejected'))
+);
+
+-- a user has one application under review at a time
+CREATE UNIQUE INDEX IF NOT EXISTS "kyc_applications_pending_key" ON "kyc_applications" ("user_id")
+    WHERE "status" = 'pending';
+
+CREATE INDEX IF NOT EXISTS "kyc_applications_status_idx" ON "kyc_applications" ("status", "created_at");
+
+-- metadata of the uploaded documents, the files are in the blob store under kyc/<user_id>/<guid>
+CREATE TABLE IF NOT EXISTS "kyc_documents" (
+    "guid" UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    "user_id" UUID NOT NULL REFERENCES "users" ("guid"),
+    -- set once the document is submitted with an application
+    "application_id" UUID REFERENCES "kyc_applications" ("guid"),
+    "type" varchar(32) NOT NULL,
+    "file_name" varchar(255) NOT NULL,
+    "content_type" varchar(64) NOT NULL,
+    "size" BIGINT NOT NULL,
+    "sha256" varchar(64) NOT NULL,
+    "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "kyc_documents_type_check"
+        CHECK ("type" IN ('passport', 'id_card', 'driving_licence', 'proof_of_address', 'selfie'))
+);
+
+CREATE INDEX IF NOT EXISTS "kyc_documents_user_id_idx" ON "kyc_documents" ("user_id", "created_at");
+CREATE INDEX IF NOT EXISTS "kyc_documents_application_id_idx" ON "kyc_documents" ("application_id");
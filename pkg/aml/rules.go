@@ -0,0 +1,101 @@
+// Package aml finds money laundering patterns in committed transactions.
+package aml
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dilmurodov/online_banking/pkg/models"
+)
+
+// Window is how far back the account's history has to go for the rules to see every pattern
+func Window(rules models.AMLRules) time.Duration {
+	if rules.StructuringWindow > rules.RapidWindow {
+		return rules.StructuringWindow
+	}
+	return rules.RapidWindow
+}
+
+// NeedsHistory tells whether a rule has to look past the transaction itself
+func NeedsHistory(rules models.AMLRules, t *models.AMLTransaction) bool {
+	return inStructuringBand(rules, t) || t.Type == "debit"
+}
+
+// Evaluate runs the rules on a transaction. The history is the account's transactions up to
+// and including this one, oldest first, going back Window.
+func Evaluate(rules models.AMLRules, t *models.AMLTransaction, history []*models.AMLTransaction) []*models.AMLFlag {
+	flags := make([]*models.AMLFlag, 0)
+
+	if t.IsCash() && t.Amount >= rules.CashThreshold {
+		operation := "deposit"
+		if t.Type == "debit" {
+			operation = "withdrawal"
+		}
+		flags = append(flags, &models.AMLFlag{
+			Type:           models.AMLCaseCashThreshold,
+			Reason:         fmt.Sprintf("cash %s of %.2f, the threshold is %.2f", operation, t.Amount, rules.CashThreshold),
+			TransactionIDs: []string{t.ID},
+		})
+	}
+
+	// several cash deposits each kept just under the threshold
+	if inStructuringBand(rules, t) {
+		var (
+			ids   []string
+			total float64
+		)
+		from := t.CreatedAt.Add(-rules.StructuringWindow)
+		for _, h := range history {
+			if h.CreatedAt.Before(from) || !inStructuringBand(rules, h) {
+				continue
+			}
+			ids = append(ids, h.ID)
+			total += h.Amount
+		}
+		if len(ids) >= rules.StructuringCount {
+			flags = append(flags, &models.AMLFlag{
+				Type: models.AMLCaseStructuring,
+				Reason: fmt.Sprintf("%d cash deposits just under the threshold of %.2f totalling %.2f within %s",
+					len(ids), rules.CashThreshold, total, rules.StructuringWindow),
+				TransactionIDs: ids,
+			})
+		}
+	}
+
+	// money that leaves the account soon after it came in
+	if t.Type == "debit" {
+		var (
+			ids     []string
+			in, out float64
+		)
+		from := t.CreatedAt.Add(-rules.RapidWindow)
+		for _, h := range history {
+			if h.CreatedAt.Before(from) {
+				continue
+			}
+			switch h.Type {
+			case "credit":
+				in += h.Amount
+			case "debit":
+				out += h.Amount
+			default:
+				continue
+			}
+			ids = append(ids, h.ID)
+		}
+		if in >= rules.RapidMinAmount && out >= in*rules.RapidRatio {
+			flags = append(flags, &models.AMLFlag{
+				Type:           models.AMLCaseRapidMovement,
+				Reason:         fmt.Sprintf("%.2f came in and %.2f went out within %s", in, out, rules.RapidWindow),
+				TransactionIDs: ids,
+			})
+		}
+	}
+
+	return flags
+}
+
+func inStructuringBand(rules models.AMLRules, t *models.AMLTransaction) bool {
+	return t.IsCash() && t.Type == "credit" &&
+		t.Amount < rules.CashThreshold && t.Amount >= rules.CashThreshold*(1-rules.StructuringMargin)
+}
@@ -0,0 +1,87 @@
+// Package auditlog computes the hash chain of the audit log
+package auditlog
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/dilmurodov/online_banking/pkg/models"
+)
+
+// entry is the part of an event the hash covers, the field order is the canonical form
+type entry struct {
+	EventType    string          `json:"event_type"`
+	ActorID      string          `json:"actor_id"`
+	UserID       string          `json:"user_id"`
+	ResourceType string          `json:"resource_type"`
+	ResourceID   string          `json:"resource_id"`
+	IP           string          `json:"ip"`
+	RequestID    string          `json:"request_id"`
+	Details      json.RawMessage `json:"details"`
+	Before       json.RawMessage `json:"before"`
+	After        json.RawMessage `json:"after"`
+	CreatedAt    string          `json:"created_at"`
+}
+
+// Now is the creation time of a new event in the form the hash is made over,
+// postgres keeps microseconds so a finer time would not verify once read back
+func Now() string {
+	return time.Now().UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano)
+}
+
+// Hash returns the hash of the event chained to prev, the hash of the event before it
+func Hash(prev string, e *models.AuditEvent) (string, error) {
+	createdAt, err := time.Parse(time.RFC3339Nano, e.CreatedAt)
+	if err != nil {
+		return "", err
+	}
+
+	en := entry{
+		EventType:    e.EventType,
+		ActorID:      e.ActorID,
+		UserID:       e.UserID,
+		ResourceType: e.ResourceType,
+		ResourceID:   e.ResourceID,
+		IP:           e.IP,
+		RequestID:    e.RequestID,
+		CreatedAt:    createdAt.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano),
+	}
+	if en.Details, err = canonical(e.Details); err != nil {
+		return "", err
+	}
+	if en.Before, err = canonical(e.Before); err != nil {
+		return "", err
+	}
+	if en.After, err = canonical(e.After); err != nil {
+		return "", err
+	}
+
+	body, err := json.Marshal(en)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.New()
+	sum.Write([]byte(prev))
+	sum.Write(body)
+	return hex.EncodeToString(sum.Sum(nil)), nil
+}
+
+// canonical brings a snapshot to the form it has once read back from JSONB,
+// numbers become float64 and the keys are sorted
+func canonical(v map[string]interface{}) (json.RawMessage, error) {
+	if v == nil {
+		return json.RawMessage("null"), nil
+	}
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var decoded interface{}
+	if err = json.Unmarshal(body, &decoded); err != nil {
+		return nil, err
+	}
+	return json.Marshal(decoded)
+}
@@ -0,0 +1,29 @@
+package auditlog
+
+import "context"
+
+type requestIDKey struct{}
+
+// WithRequestID returns a context carrying the id of the API request it serves
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID returns the id of the API request the context serves, empty outside of one
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+type clientIPKey struct{}
+
+// WithClientIP returns a context carrying the address of the client the API request came from
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, ip)
+}
+
+// ClientIP returns the address of the client the context serves, empty outside of a request
+func ClientIP(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey{}).(string)
+	return ip
+}
@@ -0,0 +1,114 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/dilmurodov/online_banking/config"
+)
+
+// ErrNotFound is returned for a key nothing was stored under
+var ErrNotFound = errors.New("blob not found")
+
+// StoreI keeps files by key, a slash separated path such as "kyc/<user id>/<document id>"
+type StoreI interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// New returns the store chosen by the config
+func New(cfg config.Config) (StoreI, error) {
+	switch cfg.BlobStore {
+	case "", "local":
+		return NewLocalStore(cfg.BlobLocalDir), nil
+	default:
+		return nil, fmt.Errorf("unknown blob store %q", cfg.BlobStore)
+	}
+}
+
+// LocalStore keeps the files under a directory of the local filesystem, one file per key
+type LocalStore struct {
+	dir string
+}
+
+func NewLocalStore(dir string) *LocalStore {
+	return &LocalStore{dir: dir}
+}
+
+// path maps the key to a file under the directory, keys that would leave it are refused
+func (s *LocalStore) path(key string) (string, error) {
+	clean := path.Clean("/" + key)
+	if key == "" || clean == "/" || clean != "/"+strings.TrimPrefix(key, "/") || strings.Contains(key, `\`) {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(clean)), nil
+}
+
+// Put writes the file next to its final place first, so a reader never sees half of it
+func (s *LocalStore) Put(ctx context.Context, key string, r io.Reader) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(name), 0700); err != nil {
+		return fmt.Errorf("failed to create blob directory: %w", err)
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create blob: %w", err)
+	}
+	defer os.Remove(f.Name())
+
+	if _, err = io.Copy(f, r); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write blob: %w", err)
+	}
+	if err = f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write blob: %w", err)
+	}
+	if err = f.Close(); err != nil {
+		return fmt.Errorf("failed to write blob: %w", err)
+	}
+
+	if err = os.Rename(f.Name(), name); err != nil {
+		return fmt.Errorf("failed to write blob: %w", err)
+	}
+	return nil
+}
+
+func (s *LocalStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	name, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to open blob: %w", err)
+	}
+	return f, nil
+}
+
+// Delete removes the file, a key with nothing stored under it is not an error
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err = os.Remove(name); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete blob: %w", err)
+	}
+	return nil
+}
@@ -1,12 +1,23 @@
 package customerrors
 
-import "fmt"
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
 
 type InsufficientFundsError struct {
+	AccountID string
+	// Shortfall is how much the request exceeds the available funds, the overdraft limit included
+	Shortfall string
 }
 
 func (e *InsufficientFundsError) Error() string {
-	return "В вашем счете недостаточно средств"
+	if e.Shortfall == "" {
+		return "В вашем счете недостаточно средств"
+	}
+	return fmt.Sprintf("В вашем счете недостаточно средств, с учетом овердрафта не хватает %s", e.Shortfall)
 }
 
 type AccountNotFoundError struct {
@@ -115,3 +126,391 @@ type InvalidGuidError struct {
 func (e *InvalidGuidError) Error() string {
 	return fmt.Sprintf("Неверный guid: %s", e.msg)
 }
+
+type RecipientNotFoundError struct {
+}
+
+func (e *RecipientNotFoundError) Error() string {
+	return "Получатель не найден"
+}
+
+type InvalidConfirmationTokenError struct {
+}
+
+func (e *InvalidConfirmationTokenError) Error() string {
+	return "Неверный или просроченный токен подтверждения"
+}
+
+type PaymentRequestNotFoundError struct {
+	Guid string
+}
+
+func (e *PaymentRequestNotFoundError) Error() string {
+	return fmt.Sprintf("Запрос на оплату (guid: %s) не найден", e.Guid)
+}
+
+type PaymentRequestStateError struct {
+	Status string
+}
+
+func (e *PaymentRequestStateError) Error() string {
+	return fmt.Sprintf("Запрос на оплату больше не в статусе %s", e.Status)
+}
+
+type BeneficiaryNotFoundError struct {
+	Guid string
+}
+
+func (e *BeneficiaryNotFoundError) Error() string {
+	return fmt.Sprintf("Получатель из справочника (guid: %s) не найден", e.Guid)
+}
+
+type TransferLimitExceededError struct {
+	Limit  float64
+	Reason string
+}
+
+func (e *TransferLimitExceededError) Error() string {
+	return fmt.Sprintf("Превышен лимит перевода (%s): %.2f", e.Reason, e.Limit)
+}
+
+type BeneficiaryAlreadyExistsError struct {
+}
+
+func (e *BeneficiaryAlreadyExistsError) Error() string {
+	return "Получатель с таким именем или счетом уже есть в справочнике"
+}
+
+type InvalidRemittanceError struct {
+	Field string
+}
+
+func (e *InvalidRemittanceError) Error() string {
+	return fmt.Sprintf("Недопустимое значение поля %s", e.Field)
+}
+
+type InvalidCursorError struct{}
+
+func (e *InvalidCursorError) Error() string {
+	return "Недопустимый курсор пагинации"
+}
+
+type ProductNotFoundError struct {
+	Code string
+}
+
+func (e *ProductNotFoundError) Error() string {
+	return fmt.Sprintf("Продукт (%s) не найден", e.Code)
+}
+
+type DepositNotFoundError struct {
+	Guid string
+}
+
+func (e *DepositNotFoundError) Error() string {
+	return fmt.Sprintf("Вклад (guid: %s) не найден", e.Guid)
+}
+
+type DepositStateError struct {
+	Status string
+}
+
+func (e *DepositStateError) Error() string {
+	return fmt.Sprintf("Операция недоступна для вклада в статусе %s", e.Status)
+}
+
+type AccountLockedError struct {
+	Guid string
+}
+
+func (e *AccountLockedError) Error() string {
+	return fmt.Sprintf("Счет (guid: %s) недоступен для платежей", e.Guid)
+}
+
+type OverdraftLimitError struct {
+	Used string
+}
+
+func (e *OverdraftLimitError) Error() string {
+	return fmt.Sprintf("Лимит овердрафта не может быть меньше используемой суммы %s", e.Used)
+}
+
+type LoanNotFoundError struct {
+	Guid string
+}
+
+func (e *LoanNotFoundError) Error() string {
+	return fmt.Sprintf("Кредит (guid: %s) не найден", e.Guid)
+}
+
+type LoanStateError struct {
+	Status string
+}
+
+func (e *LoanStateError) Error() string {
+	return fmt.Sprintf("Операция недоступна для кредита в статусе %s", e.Status)
+}
+
+type PotNotFoundError struct {
+	Guid string
+}
+
+func (e *PotNotFoundError) Error() string {
+	return fmt.Sprintf("Копилка (guid: %s) не найдена", e.Guid)
+}
+
+type AccountPermissionError struct {
+	Guid string
+}
+
+func (e *AccountPermissionError) Error() string {
+	return fmt.Sprintf("Недостаточно прав для операции по счету (guid: %s)", e.Guid)
+}
+
+type AccountHolderNotFoundError struct {
+	UserID string
+}
+
+func (e *AccountHolderNotFoundError) Error() string {
+	return fmt.Sprintf("Пользователь (guid: %s) не является владельцем счета", e.UserID)
+}
+
+type InvitationNotFoundError struct {
+	Guid string
+}
+
+func (e *InvitationNotFoundError) Error() string {
+	return fmt.Sprintf("Приглашение (guid: %s) не найдено", e.Guid)
+}
+
+type TransferApprovalNotFoundError struct {
+	Guid string
+}
+
+func (e *TransferApprovalNotFoundError) Error() string {
+	return fmt.Sprintf("Перевод на подтверждении (guid: %s) не найден", e.Guid)
+}
+
+type TransferApprovalStateError struct {
+	Status string
+}
+
+func (e *TransferApprovalStateError) Error() string {
+	return fmt.Sprintf("Операция недоступна для перевода в статусе %s", e.Status)
+}
+
+type DualApprovalRequiredError struct {
+	AccountID string
+}
+
+func (e *DualApprovalRequiredError) Error() string {
+	return fmt.Sprintf("Платеж со счета (guid: %s) требует подтверждения второго владельца", e.AccountID)
+}
+
+type AccountStatusError struct {
+	Guid   string
+	Status string
+}
+
+func (e *AccountStatusError) Error() string {
+	return fmt.Sprintf("Операция недоступна для счета (guid: %s) в статусе %s", e.Guid, e.Status)
+}
+
+type AccountCloseError struct {
+	Guid   string
+	Reason string
+}
+
+func (e *AccountCloseError) Error() string {
+	return fmt.Sprintf("Счет (guid: %s) нельзя закрыть: %s", e.Guid, e.Reason)
+}
+
+type InvalidOTPError struct {
+}
+
+func (e *InvalidOTPError) Error() string {
+	return "Неверный или просроченный код"
+}
+
+type OTPAttemptsExceededError struct {
+}
+
+func (e *OTPAttemptsExceededError) Error() string {
+	return "Превышено количество попыток ввода кода, запросите новый код"
+}
+
+type TwoFactorStateError struct {
+	Enabled bool
+}
+
+func (e *TwoFactorStateError) Error() string {
+	if e.Enabled {
+		return "Двухфакторная аутентификация уже включена"
+	}
+	return "Двухфакторная аутентификация не включена"
+}
+
+type InvalidMFACodeError struct {
+}
+
+func (e *InvalidMFACodeError) Error() string {
+	return "Неверный код подтверждения"
+}
+
+type StepUpRequiredError struct {
+}
+
+func (e *StepUpRequiredError) Error() string {
+	return "Операция требует подтверждения кодом двухфакторной аутентификации"
+}
+
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	return fmt.Sprintf("Слишком много неудачных попыток входа, повторите через %d сек.", int(math.Ceil(e.RetryAfter.Seconds())))
+}
+
+type WeakPasswordError struct {
+	Reason string
+}
+
+func (e *WeakPasswordError) Error() string {
+	return fmt.Sprintf("Пароль не подходит: %s", e.Reason)
+}
+
+type PendingOperationNotFoundError struct {
+	Guid string
+}
+
+func (e *PendingOperationNotFoundError) Error() string {
+	return fmt.Sprintf("Операция на подтверждении (guid: %s) не найдена", e.Guid)
+}
+
+type PendingOperationStateError struct {
+	Status string
+}
+
+func (e *PendingOperationStateError) Error() string {
+	return fmt.Sprintf("Операция недоступна в статусе %s", e.Status)
+}
+
+type PendingOperationExistsError struct {
+	ResourceID string
+}
+
+func (e *PendingOperationExistsError) Error() string {
+	return fmt.Sprintf("Для %s уже есть такая операция", e.ResourceID)
+}
+
+type FraudChallengeRequiredError struct {
+	DecisionID string
+}
+
+func (e *FraudChallengeRequiredError) Error() string {
+	return "Платеж нужно подтвердить кодом из SMS"
+}
+
+type FraudBlockedError struct {
+	DecisionID string
+}
+
+func (e *FraudBlockedError) Error() string {
+	return fmt.Sprintf("Платеж отклонен проверкой безопасности (решение: %s)", e.DecisionID)
+}
+
+type FraudChallengeInvalidError struct{}
+
+func (e *FraudChallengeInvalidError) Error() string {
+	return "Подтверждение платежа не найдено, истекло или относится к другому платежу"
+}
+
+type FraudDecisionNotFoundError struct {
+	Guid string
+}
+
+func (e *FraudDecisionNotFoundError) Error() string {
+	return fmt.Sprintf("Решение проверки платежа (guid: %s) не найдено", e.Guid)
+}
+
+type AMLCaseNotFoundError struct {
+	Guid string
+}
+
+func (e *AMLCaseNotFoundError) Error() string {
+	return fmt.Sprintf("AML-кейс (guid: %s) не найден", e.Guid)
+}
+
+type AMLCaseStatusError struct {
+	From string
+	To   string
+}
+
+func (e *AMLCaseStatusError) Error() string {
+	return fmt.Sprintf("AML-кейс нельзя перевести из статуса %s в статус %s", e.From, e.To)
+}
+
+type SanctionsHitNotFoundError struct {
+	Guid string
+}
+
+func (e *SanctionsHitNotFoundError) Error() string {
+	return fmt.Sprintf("Совпадение по санкционному списку (guid: %s) не найдено", e.Guid)
+}
+
+type SanctionsBlockedError struct{}
+
+func (e *SanctionsBlockedError) Error() string {
+	return "Платеж заблокирован до проверки совпадения с санкционным списком"
+}
+
+type KYCDocumentNotFoundError struct {
+	Guid string
+}
+
+func (e *KYCDocumentNotFoundError) Error() string {
+	return fmt.Sprintf("Документ (guid: %s) не найден", e.Guid)
+}
+
+type KYCApplicationNotFoundError struct {
+	Guid string
+}
+
+func (e *KYCApplicationNotFoundError) Error() string {
+	return fmt.Sprintf("Заявка на верификацию (guid: %s) не найдена", e.Guid)
+}
+
+type KYCApplicationExistsError struct{}
+
+func (e *KYCApplicationExistsError) Error() string {
+	return "Заявка на верификацию уже на проверке"
+}
+
+type KYCApplicationStateError struct {
+	Status string
+}
+
+func (e *KYCApplicationStateError) Error() string {
+	return fmt.Sprintf("Заявка на верификацию недоступна в статусе %s", e.Status)
+}
+
+type KYCDocumentsMissingError struct {
+	Missing []string
+}
+
+func (e *KYCDocumentsMissingError) Error() string {
+	return fmt.Sprintf("Для заявки не хватает документов: %s", strings.Join(e.Missing, ", "))
+}
+
+type KYCLimitExceededError struct {
+	AccountID string
+	Tier      string
+	// Limit is balance, deposit or withdrawal
+	Limit string
+	Cap   string
+}
+
+func (e *KYCLimitExceededError) Error() string {
+	return fmt.Sprintf("Превышен лимит (%s) уровня верификации %s: не более %s, пройдите верификацию, чтобы его повысить", e.Limit, e.Tier, e.Cap)
+}
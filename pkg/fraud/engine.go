@@ -0,0 +1,158 @@
+// Package fraud scores payments against configurable rules
+package fraud
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/dilmurodov/online_banking/pkg/models"
+)
+
+// DefaultRules are used when no rules file is configured
+func DefaultRules() models.FraudRules {
+	return models.FraudRules{
+		ChallengeScore: 50,
+		BlockScore:     100,
+		Velocity: models.FraudVelocityRule{
+			Enabled:          true,
+			MaxDebitsPerHour: 10,
+			Score:            50,
+		},
+		AmountAverage: models.FraudAmountRule{
+			Enabled:      true,
+			Multiplier:   5,
+			MinHistory:   5,
+			LookbackDays: 90,
+			Score:        40,
+		},
+		NewCounterparty: models.FraudCounterpartyRule{
+			Enabled:   true,
+			MinAmount: 1000,
+			Score:     20,
+		},
+		NightWithdrawal: models.FraudNightRule{
+			Enabled:   true,
+			StartHour: 0,
+			EndHour:   6,
+			MinAmount: 2000,
+			Score:     40,
+		},
+	}
+}
+
+// Engine holds the rules in force, they can be swapped while payments are being scored
+type Engine struct {
+	path string
+
+	mu    sync.RWMutex
+	rules models.FraudRules
+}
+
+// NewEngine returns an engine with the default rules, Reload reads the rules file over them
+func NewEngine(path string) *Engine {
+	return &Engine{path: path, rules: DefaultRules()}
+}
+
+// Rules returns the rules in force
+func (e *Engine) Rules() models.FraudRules {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.rules
+}
+
+// Reload reads the rules file again. The rules in force are kept when the file can't be
+// read or is not valid, so a bad edit does not switch the checks off.
+func (e *Engine) Reload() (models.FraudRules, error) {
+	if e.path == "" {
+		return e.Rules(), nil
+	}
+
+	body, err := os.ReadFile(e.path)
+	if err != nil {
+		return e.Rules(), err
+	}
+
+	rules := DefaultRules()
+	if err = json.Unmarshal(body, &rules); err != nil {
+		return e.Rules(), err
+	}
+	if err = Validate(rules); err != nil {
+		return e.Rules(), err
+	}
+
+	e.mu.Lock()
+	e.rules = rules
+	e.mu.Unlock()
+
+	return rules, nil
+}
+
+// Validate checks that the rules can be applied
+func Validate(rules models.FraudRules) error {
+	if rules.ChallengeScore <= 0 || rules.BlockScore < rules.ChallengeScore {
+		return fmt.Errorf("challenge_score must be positive and not above block_score")
+	}
+	night := rules.NightWithdrawal
+	if night.StartHour < 0 || night.StartHour > 23 || night.EndHour < 0 || night.EndHour > 24 {
+		return fmt.Errorf("night_withdrawal hours must be between 0 and 24")
+	}
+	if rules.AmountAverage.Enabled && (rules.AmountAverage.Multiplier <= 1 || rules.AmountAverage.LookbackDays <= 0) {
+		return fmt.Errorf("amount_vs_average needs a multiplier above 1 and a positive lookback_days")
+	}
+	if rules.Velocity.Enabled && rules.Velocity.MaxDebitsPerHour <= 0 {
+		return fmt.Errorf("velocity needs a positive max_debits_per_hour")
+	}
+	return nil
+}
+
+// Assess scores the payment with the rules in force
+func (e *Engine) Assess(signals *models.FraudSignals) *models.FraudAssessment {
+	return Assess(e.Rules(), signals)
+}
+
+// Assess runs every enabled rule on the payment and adds up the scores of the ones that fire
+func Assess(rules models.FraudRules, s *models.FraudSignals) *models.FraudAssessment {
+	a := &models.FraudAssessment{Outcome: models.FraudOutcomeAllow, Hits: make([]*models.FraudRuleHit, 0)}
+
+	hit := func(rule string, score int, reason string, args ...interface{}) {
+		a.Hits = append(a.Hits, &models.FraudRuleHit{Rule: rule, Score: score, Reason: fmt.Sprintf(reason, args...)})
+		a.Score += score
+	}
+
+	if r := rules.Velocity; r.Enabled && s.DebitsLastHour+1 > r.MaxDebitsPerHour {
+		hit(models.FraudRuleVelocity, r.Score, "%d debits from the account in the last hour", s.DebitsLastHour+1)
+	}
+	if r := rules.AmountAverage; r.Enabled && s.DebitCount >= r.MinHistory && s.AverageDebit > 0 &&
+		s.Amount >= s.AverageDebit*r.Multiplier {
+		hit(models.FraudRuleAmountAverage, r.Score, "amount is %.1f times the average debit of %.2f", s.Amount/s.AverageDebit, s.AverageDebit)
+	}
+	if r := rules.NewCounterparty; r.Enabled && s.Operation == models.FraudOperationTransfer &&
+		s.CounterpartyID != "" && !s.OwnCounterparty && s.CounterpartyPayments == 0 && s.Amount >= r.MinAmount {
+		hit(models.FraudRuleNewCounterparty, r.Score, "first transfer to the counterparty")
+	}
+	if r := rules.NightWithdrawal; r.Enabled && s.Operation == models.FraudOperationWithdrawal &&
+		inHours(s.Hour, r.StartHour, r.EndHour) && s.Amount >= r.MinAmount {
+		hit(models.FraudRuleNightWithdrawal, r.Score, "withdrawal of %.2f at %02d:00", s.Amount, s.Hour)
+	}
+
+	sort.SliceStable(a.Hits, func(i, j int) bool { return a.Hits[i].Score > a.Hits[j].Score })
+
+	switch {
+	case a.Score >= rules.BlockScore:
+		a.Outcome = models.FraudOutcomeBlock
+	case a.Score >= rules.ChallengeScore:
+		a.Outcome = models.FraudOutcomeChallenge
+	}
+	return a
+}
+
+// inHours tells whether the hour falls in [start, end), a window may wrap past midnight
+func inHours(hour, start, end int) bool {
+	if start <= end {
+		return hour >= start && hour < end
+	}
+	return hour >= start || hour < end
+}
@@ -0,0 +1,193 @@
+package fraud
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInHours(t *testing.T) {
+	tests := []struct {
+		name       string
+		hour       int
+		start, end int
+		want       bool
+	}{
+		{"START", 0, 0, 6, true},
+		{"INSIDE", 3, 0, 6, true},
+		{"END_EXCLUDED", 6, 0, 6, false},
+		{"AFTER", 12, 0, 6, false},
+		{"WHOLE_DAY", 23, 0, 24, true},
+		{"EMPTY", 5, 5, 5, false},
+		{"WRAPPED_START", 22, 22, 6, true},
+		{"WRAPPED_BEFORE_MIDNIGHT", 23, 22, 6, true},
+		{"WRAPPED_MIDNIGHT", 0, 22, 6, true},
+		{"WRAPPED_AFTER_MIDNIGHT", 5, 22, 6, true},
+		{"WRAPPED_END_EXCLUDED", 6, 22, 6, false},
+		{"WRAPPED_DAYTIME", 12, 22, 6, false},
+		{"WRAPPED_BEFORE_START", 21, 22, 6, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, inHours(tt.hour, tt.start, tt.end))
+		})
+	}
+}
+
+func TestAssess(t *testing.T) {
+	nightRules := DefaultRules()
+	nightRules.NightWithdrawal.StartHour = 22
+	nightRules.NightWithdrawal.EndHour = 6
+
+	disabled := DefaultRules()
+	disabled.Velocity.Enabled = false
+	disabled.AmountAverage.Enabled = false
+	disabled.NewCounterparty.Enabled = false
+	disabled.NightWithdrawal.Enabled = false
+
+	transfer := func(change func(s *models.FraudSignals)) *models.FraudSignals {
+		s := &models.FraudSignals{
+			Operation:            models.FraudOperationTransfer,
+			Amount:               100,
+			CounterpartyID:       "TestAccountID",
+			DebitsLastHour:       1,
+			AverageDebit:         100,
+			DebitCount:           10,
+			CounterpartyPayments: 3,
+			Hour:                 12,
+		}
+		change(s)
+		return s
+	}
+	withdrawal := func(amount float64, hour int) *models.FraudSignals {
+		return &models.FraudSignals{Operation: models.FraudOperationWithdrawal, Amount: amount, Hour: hour}
+	}
+
+	tests := []struct {
+		name    string
+		rules   models.FraudRules
+		signals *models.FraudSignals
+		score   int
+		outcome string
+		hits    []string
+	}{
+		{"ORDINARY", DefaultRules(), transfer(func(s *models.FraudSignals) {}), 0, models.FraudOutcomeAllow, nil},
+		{"VELOCITY_AT_LIMIT", DefaultRules(), transfer(func(s *models.FraudSignals) { s.DebitsLastHour = 9 }), 0, models.FraudOutcomeAllow, nil},
+		{"VELOCITY_OVER_LIMIT", DefaultRules(), transfer(func(s *models.FraudSignals) { s.DebitsLastHour = 10 }),
+			50, models.FraudOutcomeChallenge, []string{models.FraudRuleVelocity}},
+		{"AMOUNT_AVERAGE", DefaultRules(), transfer(func(s *models.FraudSignals) { s.Amount = 500 }),
+			40, models.FraudOutcomeAllow, []string{models.FraudRuleAmountAverage}},
+		{"AMOUNT_AVERAGE_SHORT_HISTORY", DefaultRules(), transfer(func(s *models.FraudSignals) { s.Amount = 500; s.DebitCount = 4 }),
+			0, models.FraudOutcomeAllow, nil},
+		{"NEW_COUNTERPARTY", DefaultRules(), transfer(func(s *models.FraudSignals) { s.Amount = 1000; s.AverageDebit = 1000; s.CounterpartyPayments = 0 }),
+			20, models.FraudOutcomeAllow, []string{models.FraudRuleNewCounterparty}},
+		{"NEW_OWN_COUNTERPARTY", DefaultRules(), transfer(func(s *models.FraudSignals) {
+			s.Amount = 1000
+			s.AverageDebit = 1000
+			s.CounterpartyPayments = 0
+			s.OwnCounterparty = true
+		}), 0, models.FraudOutcomeAllow, nil},
+		{"NEW_COUNTERPARTY_SMALL_AMOUNT", DefaultRules(), transfer(func(s *models.FraudSignals) { s.CounterpartyPayments = 0 }),
+			0, models.FraudOutcomeAllow, nil},
+		{"CHALLENGE_SUM", DefaultRules(), transfer(func(s *models.FraudSignals) { s.Amount = 1000; s.CounterpartyPayments = 0 }),
+			60, models.FraudOutcomeChallenge, []string{models.FraudRuleAmountAverage, models.FraudRuleNewCounterparty}},
+		{"BLOCK_SUM", DefaultRules(), transfer(func(s *models.FraudSignals) { s.Amount = 1000; s.CounterpartyPayments = 0; s.DebitsLastHour = 10 }),
+			110, models.FraudOutcomeBlock, []string{models.FraudRuleVelocity, models.FraudRuleAmountAverage, models.FraudRuleNewCounterparty}},
+		{"NIGHT_WITHDRAWAL", DefaultRules(), withdrawal(2000, 3), 40, models.FraudOutcomeAllow, []string{models.FraudRuleNightWithdrawal}},
+		{"NIGHT_WITHDRAWAL_SMALL_AMOUNT", DefaultRules(), withdrawal(1999.99, 3), 0, models.FraudOutcomeAllow, nil},
+		{"DAY_WITHDRAWAL", DefaultRules(), withdrawal(2000, 6), 0, models.FraudOutcomeAllow, nil},
+		{"WRAPPED_NIGHT_BEFORE_MIDNIGHT", nightRules, withdrawal(2000, 23), 40, models.FraudOutcomeAllow, []string{models.FraudRuleNightWithdrawal}},
+		{"WRAPPED_NIGHT_AFTER_MIDNIGHT", nightRules, withdrawal(2000, 2), 40, models.FraudOutcomeAllow, []string{models.FraudRuleNightWithdrawal}},
+		{"WRAPPED_NIGHT_DAYTIME", nightRules, withdrawal(2000, 14), 0, models.FraudOutcomeAllow, nil},
+		{"NIGHT_TRANSFER", nightRules, transfer(func(s *models.FraudSignals) { s.Hour = 23 }), 0, models.FraudOutcomeAllow, nil},
+		{"DISABLED", disabled, transfer(func(s *models.FraudSignals) { s.Amount = 1000; s.CounterpartyPayments = 0; s.DebitsLastHour = 10 }),
+			0, models.FraudOutcomeAllow, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := Assess(tt.rules, tt.signals)
+			require.Equal(t, tt.score, a.Score)
+			require.Equal(t, tt.outcome, a.Outcome)
+
+			// the hits come with the highest score first
+			got := make([]string, 0, len(a.Hits))
+			for _, h := range a.Hits {
+				got = append(got, h.Rule)
+			}
+			if tt.hits == nil {
+				require.Empty(t, got)
+			} else {
+				require.Equal(t, tt.hits, got)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	with := func(change func(r *models.FraudRules)) models.FraudRules {
+		r := DefaultRules()
+		change(&r)
+		return r
+	}
+
+	tests := []struct {
+		name    string
+		rules   models.FraudRules
+		wantErr bool
+	}{
+		{"DEFAULT", DefaultRules(), false},
+		{"WRAPPED_NIGHT", with(func(r *models.FraudRules) { r.NightWithdrawal.StartHour = 22 }), false},
+		{"ZERO_CHALLENGE", with(func(r *models.FraudRules) { r.ChallengeScore = 0 }), true},
+		{"BLOCK_BELOW_CHALLENGE", with(func(r *models.FraudRules) { r.BlockScore = 40 }), true},
+		{"START_HOUR_24", with(func(r *models.FraudRules) { r.NightWithdrawal.StartHour = 24 }), true},
+		{"END_HOUR_25", with(func(r *models.FraudRules) { r.NightWithdrawal.EndHour = 25 }), true},
+		{"MULTIPLIER_1", with(func(r *models.FraudRules) { r.AmountAverage.Multiplier = 1 }), true},
+		{"MULTIPLIER_1_DISABLED", with(func(r *models.FraudRules) {
+			r.AmountAverage.Multiplier = 1
+			r.AmountAverage.Enabled = false
+		}), false},
+		{"ZERO_VELOCITY", with(func(r *models.FraudRules) { r.Velocity.MaxDebitsPerHour = 0 }), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.rules)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+// a bad edit of the rules file keeps the rules in force
+func TestEngine_Reload(t *testing.T) {
+	r := require.New(t)
+
+	path := filepath.Join(t.TempDir(), "fraud_rules.json")
+	e := NewEngine(path)
+
+	_, err := e.Reload()
+	r.Error(err)
+	r.Equal(DefaultRules(), e.Rules())
+
+	r.NoError(os.WriteFile(path, []byte(`{"challenge_score": 30}`), 0o600))
+	rules, err := e.Reload()
+	r.NoError(err)
+	r.Equal(30, rules.ChallengeScore)
+	r.Equal(DefaultRules().BlockScore, rules.BlockScore)
+	r.Equal(rules, e.Rules())
+
+	r.NoError(os.WriteFile(path, []byte(`{"challenge_score": 0}`), 0o600))
+	_, err = e.Reload()
+	r.Error(err)
+	r.Equal(30, e.Rules().ChallengeScore)
+
+	r.NoError(os.WriteFile(path, []byte(`{`), 0o600))
+	_, err = e.Reload()
+	r.Error(err)
+	r.Equal(30, e.Rules().ChallengeScore)
+}
@@ -0,0 +1,77 @@
+package helper
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is a keyset position in a list ordered by (created_at, guid)
+type Cursor struct {
+	CreatedAt string `json:"c"`
+	ID        string `json:"i"`
+	// Backward is set on prev cursors: the page is read towards the start of the list
+	Backward bool `json:"b,omitempty"`
+}
+
+// EncodeCursor returns the opaque representation handed to API clients
+func EncodeCursor(c Cursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func DecodeCursor(s string) (*Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(b, &c); err != nil || c.CreatedAt == "" || c.ID == "" {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// KeysetPage describes how a keyset query must be built from a cursor and the requested order
+type KeysetPage struct {
+	Cursor *Cursor
+	Desc   bool
+}
+
+// Operator returns the row comparison operator for the (created_at, guid) condition
+func (p KeysetPage) Operator() string {
+	if p.Desc != p.backward() {
+		return "<"
+	}
+	return ">"
+}
+
+// Order returns the ORDER BY direction used to read the page
+func (p KeysetPage) Order() string {
+	if p.Desc != p.backward() {
+		return "DESC"
+	}
+	return "ASC"
+}
+
+func (p KeysetPage) backward() bool {
+	return p.Cursor != nil && p.Cursor.Backward
+}
+
+// Cursors computes next and prev cursors for a page that was read with limit+1 rows.
+// first and last are the positions of the page edges in the requested order.
+func (p KeysetPage) Cursors(first, last Cursor, hasMore bool) (next, prev string) {
+	backward := p.backward()
+
+	if (!backward && hasMore) || backward {
+		next = EncodeCursor(Cursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+	if (backward && hasMore) || (!backward && p.Cursor != nil) {
+		prev = EncodeCursor(Cursor{CreatedAt: first.CreatedAt, ID: first.ID, Backward: true})
+	}
+	return next, prev
+}
@@ -0,0 +1,107 @@
+package helper
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursor_RoundTrip(t *testing.T) {
+	for _, c := range []Cursor{
+		{CreatedAt: "2021-01-01T10:00:00.123456Z", ID: "8d1b5c0e-6b3a-4a8e-9a52-2d6f1f6f7b10"},
+		{CreatedAt: "2021-01-01T10:00:00Z", ID: "8d1b5c0e-6b3a-4a8e-9a52-2d6f1f6f7b10", Backward: true},
+	} {
+		encoded := EncodeCursor(c)
+		require.NotContains(t, encoded, "=", "cursors go in query strings unpadded")
+
+		decoded, err := DecodeCursor(encoded)
+		require.NoError(t, err)
+		require.Equal(t, c, *decoded)
+	}
+}
+
+func TestCursor_Invalid(t *testing.T) {
+	valid := EncodeCursor(Cursor{CreatedAt: "2021-01-01T10:00:00Z", ID: "TestTransactionID"})
+	encode := func(s string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(s))
+	}
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{"EMPTY", ""},
+		{"NOT_BASE64", "not a cursor!"},
+		{"PADDED", base64.URLEncoding.EncodeToString([]byte(`{"c":"2021-01-01T10:00:00Z","i":"TestTransactionID"}`)) + "=="},
+		{"STANDARD_ALPHABET", base64.RawStdEncoding.EncodeToString([]byte(`{"c":"2021-01-01T10:00:00Z?","i":"TestTransactionID>>"}`))},
+		{"TRUNCATED", valid[:len(valid)-5]},
+		{"TAMPERED", valid[:10] + "!" + valid[11:]},
+		{"NOT_JSON", encode("2021-01-01T10:00:00Z,TestTransactionID")},
+		{"JSON_ARRAY", encode(`["2021-01-01T10:00:00Z","TestTransactionID"]`)},
+		{"WRONG_TYPES", encode(`{"c":1609495200,"i":"TestTransactionID"}`)},
+		{"NO_CREATED_AT", encode(`{"i":"TestTransactionID"}`)},
+		{"NO_ID", encode(`{"c":"2021-01-01T10:00:00Z"}`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := DecodeCursor(tt.cursor)
+			require.ErrorIs(t, err, ErrInvalidCursor)
+			require.Nil(t, c)
+		})
+	}
+}
+
+func TestKeysetPage(t *testing.T) {
+	first := Cursor{CreatedAt: "2021-01-01T10:00:00Z", ID: "TestFirstID"}
+	last := Cursor{CreatedAt: "2021-01-02T10:00:00Z", ID: "TestLastID"}
+
+	tests := []struct {
+		name     string
+		page     KeysetPage
+		hasMore  bool
+		operator string
+		order    string
+		next     *Cursor
+		prev     *Cursor
+	}{
+		{name: "FIRST_PAGE", page: KeysetPage{}, hasMore: true, operator: ">", order: "ASC",
+			next: &Cursor{CreatedAt: last.CreatedAt, ID: last.ID}},
+		{name: "ONLY_PAGE", page: KeysetPage{Desc: true}, operator: "<", order: "DESC"},
+		{name: "FORWARD", page: KeysetPage{Cursor: &first}, hasMore: true, operator: ">", order: "ASC",
+			next: &Cursor{CreatedAt: last.CreatedAt, ID: last.ID},
+			prev: &Cursor{CreatedAt: first.CreatedAt, ID: first.ID, Backward: true}},
+		{name: "FORWARD_LAST_PAGE", page: KeysetPage{Cursor: &first, Desc: true}, operator: "<", order: "DESC",
+			prev: &Cursor{CreatedAt: first.CreatedAt, ID: first.ID, Backward: true}},
+		// a backward page is read in the opposite order and always has a page after it
+		{name: "BACKWARD", page: KeysetPage{Cursor: &Cursor{CreatedAt: first.CreatedAt, ID: first.ID, Backward: true}}, hasMore: true,
+			operator: "<", order: "DESC",
+			next: &Cursor{CreatedAt: last.CreatedAt, ID: last.ID},
+			prev: &Cursor{CreatedAt: first.CreatedAt, ID: first.ID, Backward: true}},
+		{name: "BACKWARD_FIRST_PAGE", page: KeysetPage{Cursor: &Cursor{CreatedAt: first.CreatedAt, ID: first.ID, Backward: true}, Desc: true},
+			operator: ">", order: "ASC",
+			next: &Cursor{CreatedAt: last.CreatedAt, ID: last.ID}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			r.Equal(tt.operator, tt.page.Operator())
+			r.Equal(tt.order, tt.page.Order())
+
+			next, prev := tt.page.Cursors(first, last, tt.hasMore)
+			for _, c := range []struct {
+				got  string
+				want *Cursor
+			}{{next, tt.next}, {prev, tt.prev}} {
+				if c.want == nil {
+					r.Empty(c.got)
+					continue
+				}
+				decoded, err := DecodeCursor(c.got)
+				r.NoError(err)
+				r.Equal(*c.want, *decoded)
+			}
+		})
+	}
+}
@@ -0,0 +1,14 @@
+package helper
+
+import "time"
+
+// BusinessToday returns the current business date in the timezone as a UTC midnight,
+// the form dates read from the database are parsed into. An unknown timezone falls back to UTC.
+func BusinessToday(timezone string) time.Time {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	now := time.Now().In(loc)
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,51 @@
+package helper
+
+import (
+	"strconv"
+	"strings"
+)
+
+// QueryBuilder collects SQL conditions and numbers their positional parameters in the order they are added
+type QueryBuilder struct {
+	conditions []string
+	args       []interface{}
+}
+
+func NewQueryBuilder() *QueryBuilder {
+	return &QueryBuilder{}
+}
+
+// Where adds a condition, replacing each "?" in it with the next "$n" placeholder
+func (b *QueryBuilder) Where(condition string, args ...interface{}) *QueryBuilder {
+	var sb strings.Builder
+	i := 0
+	for _, r := range condition {
+		if r == '?' && i < len(args) {
+			sb.WriteString(b.Arg(args[i]))
+			i++
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	b.conditions = append(b.conditions, sb.String())
+	return b
+}
+
+// Arg registers a parameter and returns its placeholder
+func (b *QueryBuilder) Arg(arg interface{}) string {
+	b.args = append(b.args, arg)
+	return "$" + strconv.Itoa(len(b.args))
+}
+
+// WhereClause returns the conditions joined with AND, prefixed with WHERE
+func (b *QueryBuilder) WhereClause() string {
+	if len(b.conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(b.conditions, " AND ")
+}
+
+// Args returns the parameters in placeholder order
+func (b *QueryBuilder) Args() []interface{} {
+	return b.args
+}
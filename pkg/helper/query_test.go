@@ -0,0 +1,52 @@
+package helper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryBuilder(t *testing.T) {
+	t.Run("NUMBERED", func(t *testing.T) {
+		r := require.New(t)
+
+		qb := NewQueryBuilder().
+			Where("account_id = ?", "TestAccountID").
+			Where("deleted_at IS NULL").
+			Where("created_at BETWEEN ? AND ?", "2021-01-01", "2021-02-01")
+		limit := qb.Arg(10)
+
+		r.Equal(" WHERE account_id = $1 AND deleted_at IS NULL AND created_at BETWEEN $2 AND $3", qb.WhereClause())
+		r.Equal("$4", limit)
+		r.Equal([]interface{}{"TestAccountID", "2021-01-01", "2021-02-01", 10}, qb.Args())
+	})
+
+	t.Run("SAME_VALUE_TWICE", func(t *testing.T) {
+		r := require.New(t)
+
+		qb := NewQueryBuilder().Where("(description ILIKE ? OR reference = ?)", "rent", "rent")
+
+		r.Equal(" WHERE (description ILIKE $1 OR reference = $2)", qb.WhereClause())
+		r.Equal([]interface{}{"rent", "rent"}, qb.Args())
+	})
+
+	// a "?" without an argument left for it is kept as it is
+	t.Run("MORE_MARKS_THAN_ARGS", func(t *testing.T) {
+		r := require.New(t)
+
+		qb := NewQueryBuilder().Where("metadata ? 'key' AND user_id = ?")
+		r.Equal(" WHERE metadata ? 'key' AND user_id = ?", qb.WhereClause())
+		r.Empty(qb.Args())
+
+		qb = NewQueryBuilder().Where("user_id = ? AND metadata ? 'key'", "TestUserID")
+		r.Equal(" WHERE user_id = $1 AND metadata ? 'key'", qb.WhereClause())
+	})
+
+	t.Run("EMPTY", func(t *testing.T) {
+		r := require.New(t)
+
+		qb := NewQueryBuilder()
+		r.Equal("", qb.WhereClause())
+		r.Empty(qb.Args())
+	})
+}
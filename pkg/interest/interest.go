@@ -0,0 +1,138 @@
+// Package interest implements day-count conventions and exact decimal interest arithmetic.
+// Amounts are carried as *big.Rat and only rounded when they are stored or posted.
+package interest
+
+import (
+	"errors"
+	"math/big"
+	"strconv"
+	"time"
+)
+
+// DayCount is a day-count convention
+type DayCount string
+
+const (
+	// ACT365 counts actual calendar days over a fixed 365-day year
+	ACT365 DayCount = "ACT/365"
+	// Thirty360 treats every month as 30 days of a 360-day year
+	Thirty360 DayCount = "30/360"
+)
+
+// AccrualScale is the number of decimal places daily accruals are stored with
+const AccrualScale = 10
+
+// PostingScale is the number of decimal places of amounts posted to accounts
+const PostingScale = 2
+
+var (
+	ErrInvalidDecimal = errors.New("invalid decimal")
+	ErrInvalidAmount  = errors.New("amounts must have at most two decimal places")
+)
+
+func (d DayCount) Valid() bool {
+	return d == ACT365 || d == Thirty360
+}
+
+// DayFraction returns the part of the year that accrues on the given calendar day.
+// Under 30/360 the 31st accrues nothing and the last day of February accrues up to the 30th.
+func DayFraction(dc DayCount, date time.Time) *big.Rat {
+	switch dc {
+	case Thirty360:
+		day := date.Day()
+		days := 1
+		if day == 31 {
+			days = 0
+		} else if IsLastDayOfMonth(date) && day < 30 {
+			days = 30 - day + 1
+		}
+		return big.NewRat(int64(days), 360)
+	default:
+		return big.NewRat(1, 365)
+	}
+}
+
+// DailyInterest returns balance * rate * DayFraction without rounding
+func DailyInterest(balance, rate *big.Rat, dc DayCount, date time.Time) *big.Rat {
+	amount := new(big.Rat).Mul(balance, rate)
+	return amount.Mul(amount, DayFraction(dc, date))
+}
+
+// ParseDecimal parses a decimal string such as a numeric column read as text
+func ParseDecimal(s string) (*big.Rat, error) {
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, ErrInvalidDecimal
+	}
+	return r, nil
+}
+
+// FormatDecimal formats the value with the given number of decimal places, rounding half away from zero
+func FormatDecimal(r *big.Rat, scale int) string {
+	return r.FloatString(scale)
+}
+
+// Round rounds the value half away from zero to the given number of decimal places
+func Round(r *big.Rat, scale int) *big.Rat {
+	rounded, _ := new(big.Rat).SetString(r.FloatString(scale))
+	return rounded
+}
+
+// ParseAmount converts an amount received as a JSON number to its exact decimal value.
+// The shortest decimal form of the float is what the customer typed, its binary value rarely is.
+func ParseAmount(f float64) (*big.Rat, error) {
+	r, err := ParseDecimal(strconv.FormatFloat(f, 'f', -1, 64))
+	if err != nil {
+		return nil, err
+	}
+	if RoundDown(r, PostingScale).Cmp(r) != 0 {
+		return nil, ErrInvalidAmount
+	}
+	return r, nil
+}
+
+// RoundDown truncates the value towards zero to the given number of decimal places
+func RoundDown(r *big.Rat, scale int) *big.Rat {
+	unit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil)
+	scaled := new(big.Int).Mul(r.Num(), unit)
+	scaled.Quo(scaled, r.Denom())
+	return new(big.Rat).SetFrac(scaled, unit)
+}
+
+// RoundUp returns the smallest multiple of unit that is not below the positive value
+func RoundUp(r, unit *big.Rat) *big.Rat {
+	q := new(big.Rat).Quo(r, unit)
+	n := new(big.Int).Quo(q.Num(), q.Denom())
+	if !q.IsInt() {
+		n.Add(n, big.NewInt(1))
+	}
+	return new(big.Rat).Mul(new(big.Rat).SetInt(n), unit)
+}
+
+// IsLastDayOfMonth reports whether the date closes its month
+func IsLastDayOfMonth(date time.Time) bool {
+	return date.AddDate(0, 0, 1).Day() == 1
+}
+
+// YearFraction sums the daily fractions of the days in [start, end), so interest over a period
+// equals the daily accruals over the same days
+func YearFraction(dc DayCount, start, end time.Time) *big.Rat {
+	total := new(big.Rat)
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		total.Add(total, DayFraction(dc, day))
+	}
+	return total
+}
+
+// AddMonths moves the date by whole months, clamping to the end of shorter months
+// so 31 January plus one month is the last day of February
+func AddMonths(date time.Time, months int) time.Time {
+	first := time.Date(date.Year(), date.Month()+time.Month(months), 1, 0, 0, 0, 0, date.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+
+	day := date.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(first.Year(), first.Month(), day, 0, 0, 0, 0, date.Location())
+}
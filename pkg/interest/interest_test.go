@@ -0,0 +1,161 @@
+package interest
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestDayFraction(t *testing.T) {
+	tests := []struct {
+		name string
+		dc   DayCount
+		date time.Time
+		want *big.Rat
+	}{
+		{"ACT365", ACT365, date(2023, time.March, 15), big.NewRat(1, 365)},
+		{"ACT365_31ST", ACT365, date(2023, time.March, 31), big.NewRat(1, 365)},
+		{"ACT365_LEAP_DAY", ACT365, date(2024, time.February, 29), big.NewRat(1, 365)},
+		{"30360", Thirty360, date(2023, time.March, 15), big.NewRat(1, 360)},
+		{"30360_31ST", Thirty360, date(2023, time.March, 31), big.NewRat(0, 360)},
+		{"30360_30TH", Thirty360, date(2023, time.April, 30), big.NewRat(1, 360)},
+		{"30360_END_OF_FEBRUARY", Thirty360, date(2023, time.February, 28), big.NewRat(3, 360)},
+		{"30360_END_OF_LEAP_FEBRUARY", Thirty360, date(2024, time.February, 29), big.NewRat(2, 360)},
+		{"30360_28TH_OF_LEAP_FEBRUARY", Thirty360, date(2024, time.February, 28), big.NewRat(1, 360)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want.String(), DayFraction(tt.dc, tt.date).String())
+		})
+	}
+}
+
+// every month is worth a twelfth under 30/360, whatever its number of days
+func TestYearFraction(t *testing.T) {
+	tests := []struct {
+		name       string
+		dc         DayCount
+		start, end time.Time
+		want       *big.Rat
+	}{
+		{"ACT365_YEAR", ACT365, date(2023, time.January, 1), date(2024, time.January, 1), big.NewRat(1, 1)},
+		{"ACT365_LEAP_YEAR", ACT365, date(2024, time.January, 1), date(2025, time.January, 1), big.NewRat(366, 365)},
+		{"ACT365_JANUARY", ACT365, date(2023, time.January, 1), date(2023, time.February, 1), big.NewRat(31, 365)},
+		{"30360_YEAR", Thirty360, date(2023, time.January, 1), date(2024, time.January, 1), big.NewRat(1, 1)},
+		{"30360_LEAP_YEAR", Thirty360, date(2024, time.January, 1), date(2025, time.January, 1), big.NewRat(1, 1)},
+		{"30360_JANUARY", Thirty360, date(2023, time.January, 1), date(2023, time.February, 1), big.NewRat(1, 12)},
+		{"30360_FEBRUARY", Thirty360, date(2023, time.February, 1), date(2023, time.March, 1), big.NewRat(1, 12)},
+		{"30360_MID_MONTH", Thirty360, date(2023, time.January, 15), date(2023, time.February, 15), big.NewRat(1, 12)},
+		{"EMPTY", ACT365, date(2023, time.January, 1), date(2023, time.January, 1), new(big.Rat)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want.String(), YearFraction(tt.dc, tt.start, tt.end).String())
+		})
+	}
+}
+
+func TestDailyInterest(t *testing.T) {
+	r := require.New(t)
+
+	balance, err := ParseDecimal("1000.00")
+	r.NoError(err)
+	rate, err := ParseDecimal("0.05")
+	r.NoError(err)
+
+	// 1000 * 5% / 365 is kept exact until it is stored
+	amount := DailyInterest(balance, rate, ACT365, date(2023, time.March, 15))
+	r.Equal("10/73", amount.String())
+	r.Equal("0.1369863014", FormatDecimal(amount, AccrualScale))
+	r.Equal("0.14", FormatDecimal(amount, PostingScale))
+
+	r.Equal("0", DailyInterest(balance, rate, Thirty360, date(2023, time.March, 31)).RatString())
+}
+
+func TestRounding(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		round string
+		down  string
+	}{
+		{"HALF", "2.345", "2.35", "2.34"},
+		{"NEGATIVE_HALF", "-2.345", "-2.35", "-2.34"},
+		{"BELOW_HALF", "2.3449", "2.34", "2.34"},
+		{"EXACT", "2.34", "2.34", "2.34"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			value, err := ParseDecimal(tt.value)
+			r.NoError(err)
+			r.Equal(tt.round, FormatDecimal(Round(value, 2), 2))
+			r.Equal(tt.down, FormatDecimal(RoundDown(value, 2), 2))
+		})
+	}
+
+	t.Run("ROUND_UP", func(t *testing.T) {
+		r := require.New(t)
+
+		unit := big.NewRat(10, 1)
+		r.Equal("20", RoundUp(big.NewRat(1001, 100), unit).RatString())
+		r.Equal("20", RoundUp(big.NewRat(20, 1), unit).RatString())
+		r.Equal("1/100", RoundUp(big.NewRat(1, 1000), big.NewRat(1, 100)).RatString())
+	})
+}
+
+func TestParse(t *testing.T) {
+	r := require.New(t)
+
+	_, err := ParseDecimal("12,50")
+	r.ErrorIs(err, ErrInvalidDecimal)
+
+	amount, err := ParseAmount(19.99)
+	r.NoError(err)
+	r.Equal("1999/100", amount.String())
+
+	amount, err = ParseAmount(0.29)
+	r.NoError(err)
+	r.Equal("29/100", amount.String())
+
+	// a sum computed in binary is not an amount anyone typed
+	a, b := 0.1, 0.2
+	_, err = ParseAmount(a + b)
+	r.ErrorIs(err, ErrInvalidAmount)
+
+	_, err = ParseAmount(1.005)
+	r.ErrorIs(err, ErrInvalidAmount)
+}
+
+func TestAddMonths(t *testing.T) {
+	tests := []struct {
+		name   string
+		date   time.Time
+		months int
+		want   time.Time
+	}{
+		{"SAME_DAY", date(2023, time.January, 15), 1, date(2023, time.February, 15)},
+		{"END_OF_FEBRUARY", date(2023, time.January, 31), 1, date(2023, time.February, 28)},
+		{"END_OF_LEAP_FEBRUARY", date(2024, time.January, 31), 1, date(2024, time.February, 29)},
+		{"INTO_NEXT_YEAR", date(2023, time.November, 30), 3, date(2024, time.February, 29)},
+		{"BACKWARD", date(2023, time.August, 31), -6, date(2023, time.February, 28)},
+		{"YEARS", date(2024, time.February, 29), 12, date(2025, time.February, 28)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, AddMonths(tt.date, tt.months))
+		})
+	}
+
+	r := require.New(t)
+	r.True(IsLastDayOfMonth(date(2023, time.February, 28)))
+	r.False(IsLastDayOfMonth(date(2024, time.February, 28)))
+	r.True(IsLastDayOfMonth(date(2023, time.December, 31)))
+}
@@ -47,6 +47,27 @@ func GenJWT(m map[interface{}]interface{}, signinigKey []byte) (access, refresh
 	return accessTokenString, refreshTokenString, nil
 }
 
+// GenToken generates a single signed token that expires after the given duration
+func GenToken(m map[interface{}]interface{}, signinigKey []byte, expiresIn time.Duration) (string, error) {
+	token := jwt.New(jwt.SigningMethodHS256)
+
+	claims := token.Claims.(jwt.MapClaims)
+	for key, value := range m {
+		claims[key.(string)] = value
+	}
+
+	claims["iss"] = "user"
+	claims["iat"] = time.Now().Unix()
+	claims["exp"] = time.Now().Add(expiresIn).Unix()
+
+	tokenString, err := token.SignedString(signinigKey)
+	if err != nil {
+		return "", fmt.Errorf("token generating error: %s", err)
+	}
+
+	return tokenString, nil
+}
+
 // ExtractClaims extracts claims from given token
 func ExtractClaims(tokenString string, tokenSecretKey string) (jwt.MapClaims, error) {
 	var (
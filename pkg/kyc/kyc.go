@@ -0,0 +1,85 @@
+package kyc
+
+import (
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/pkg/models"
+)
+
+// identityDocuments prove who the user is, any one of them will do
+var identityDocuments = []string{models.KYCDocumentPassport, models.KYCDocumentIDCard, models.KYCDocumentDrivingLicence}
+
+var tierRanks = map[string]int{
+	models.KYCTierUnverified: 0,
+	models.KYCTierBasic:      1,
+	models.KYCTierFull:       2,
+}
+
+// IsValidTier reports whether the tier exists
+func IsValidTier(tier string) bool {
+	_, ok := tierRanks[tier]
+	return ok
+}
+
+// Above reports whether tier a allows more than tier b
+func Above(a, b string) bool {
+	return tierRanks[a] > tierRanks[b]
+}
+
+// IsValidDocumentType reports whether documents of the type are taken
+func IsValidDocumentType(docType string) bool {
+	switch docType {
+	case models.KYCDocumentPassport, models.KYCDocumentIDCard, models.KYCDocumentDrivingLicence,
+		models.KYCDocumentProofOfAddress, models.KYCDocumentSelfie:
+		return true
+	}
+	return false
+}
+
+// Limits returns the caps of the tier, an unknown tier gets the unverified ones
+func Limits(cfg config.Config, tier string) models.KYCLimits {
+	switch tier {
+	case models.KYCTierBasic:
+		return models.KYCLimits{
+			Balance:    cfg.KYCBasicBalanceLimit,
+			Deposit:    cfg.KYCBasicDepositLimit,
+			Withdrawal: cfg.KYCBasicWithdrawalLimit,
+		}
+	case models.KYCTierFull:
+		return models.KYCLimits{
+			Balance:    cfg.KYCFullBalanceLimit,
+			Deposit:    cfg.KYCFullDepositLimit,
+			Withdrawal: cfg.KYCFullWithdrawalLimit,
+		}
+	default:
+		return models.KYCLimits{
+			Balance:    cfg.KYCUnverifiedBalanceLimit,
+			Deposit:    cfg.KYCUnverifiedDepositLimit,
+			Withdrawal: cfg.KYCUnverifiedWithdrawalLimit,
+		}
+	}
+}
+
+// MissingDocuments names what an application from the current tier to the target one lacks among
+// the document types submitted with it. The basic tier needs an identity document, the full tier
+// a proof of address as well, the identity document was already checked for a basic user.
+func MissingDocuments(current, target string, types []string) []string {
+	has := make(map[string]bool, len(types))
+	for _, t := range types {
+		has[t] = true
+	}
+
+	missing := make([]string, 0)
+	if current == models.KYCTierUnverified || current == "" {
+		identity := false
+		for _, t := range identityDocuments {
+			identity = identity || has[t]
+		}
+		if !identity {
+			missing = append(missing, "identity")
+		}
+	}
+	if target == models.KYCTierFull && !has[models.KYCDocumentProofOfAddress] {
+		missing = append(missing, models.KYCDocumentProofOfAddress)
+	}
+	return missing
+}
@@ -0,0 +1,159 @@
+package loan
+
+import (
+	"math/big"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/dilmurodov/online_banking/pkg/interest"
+	"github.com/stretchr/testify/require"
+)
+
+// row is an installment as it is posted: number, due date, principal, interest
+type row [4]string
+
+func rows(schedule []Installment) []row {
+	out := make([]row, 0, len(schedule))
+	for _, i := range schedule {
+		out = append(out, row{
+			strconv.Itoa(i.Number),
+			i.DueDate.Format("2006-01-02"),
+			interest.FormatDecimal(i.Principal, interest.PostingScale),
+			interest.FormatDecimal(i.Interest, interest.PostingScale),
+		})
+	}
+	return out
+}
+
+func decimal(t *testing.T, s string) *big.Rat {
+	r, err := interest.ParseDecimal(s)
+	require.NoError(t, err)
+	return r
+}
+
+func TestAnnuityPayment(t *testing.T) {
+	tests := []struct {
+		name      string
+		principal string
+		rate      string
+		months    int
+		want      string
+	}{
+		{"ONE_PERCENT_A_MONTH", "1000", "0.12", 3, "340.02"},
+		{"YEAR", "12000", "0.10", 12, "1054.99"},
+		{"NO_INTEREST", "100", "0", 3, "33.33"},
+		{"ONE_MONTH", "500", "0.12", 1, "505.00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payment := AnnuityPayment(decimal(t, tt.principal), decimal(t, tt.rate), tt.months)
+			require.Equal(t, tt.want, interest.FormatDecimal(payment, interest.PostingScale))
+		})
+	}
+}
+
+func TestBuild(t *testing.T) {
+	start := time.Date(2023, time.January, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		typ       ScheduleType
+		principal string
+		rate      string
+		months    int
+		first     int
+		want      []row
+	}{
+		// the last installment clears the rounding of the others and is a cent above them
+		{"ANNUITY", Annuity, "1000", "0.12", 3, 1, []row{
+			{"1", "2023-02-28", "330.02", "10.00"},
+			{"2", "2023-03-31", "333.32", "6.70"},
+			{"3", "2023-04-30", "336.66", "3.37"},
+		}},
+		{"DIFFERENTIATED", Differentiated, "1000", "0.12", 3, 1, []row{
+			{"1", "2023-02-28", "333.33", "10.00"},
+			{"2", "2023-03-31", "333.33", "6.67"},
+			{"3", "2023-04-30", "333.34", "3.33"},
+		}},
+		{"NO_INTEREST", Annuity, "100", "0", 3, 1, []row{
+			{"1", "2023-02-28", "33.33", "0.00"},
+			{"2", "2023-03-31", "33.33", "0.00"},
+			{"3", "2023-04-30", "33.34", "0.00"},
+		}},
+		// a rebuilt schedule keeps numbering and due dates from the original start
+		{"REBUILT", Annuity, "669.98", "0.12", 2, 2, []row{
+			{"2", "2023-03-31", "333.32", "6.70"},
+			{"3", "2023-04-30", "336.66", "3.37"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			principal := decimal(t, tt.principal)
+			schedule := Build(tt.typ, principal, decimal(t, tt.rate), tt.months, start, tt.first)
+			r.Equal(tt.want, rows(schedule))
+
+			repaid := new(big.Rat)
+			for _, i := range schedule {
+				repaid.Add(repaid, i.Principal)
+			}
+			r.Equal(0, repaid.Cmp(principal), "the schedule repays the principal exactly")
+		})
+	}
+
+	t.Run("ANNUITY_INSTALLMENTS", func(t *testing.T) {
+		r := require.New(t)
+
+		schedule := Build(Annuity, decimal(t, "1000"), decimal(t, "0.12"), 3, start, 1)
+		totals := make([]string, 0, len(schedule))
+		for _, i := range schedule {
+			totals = append(totals, interest.FormatDecimal(i.Total(), interest.PostingScale))
+		}
+		r.Equal([]string{"340.02", "340.02", "340.03"}, totals)
+	})
+}
+
+func TestBuildWithPayment(t *testing.T) {
+	start := time.Date(2023, time.January, 15, 0, 0, 0, 0, time.UTC)
+
+	t.Run("SHORTENED", func(t *testing.T) {
+		r := require.New(t)
+
+		schedule, err := BuildWithPayment(Annuity, decimal(t, "1000"), decimal(t, "0.12"), decimal(t, "500"), start, 1)
+		r.NoError(err)
+		r.Equal([]row{
+			{"1", "2023-02-15", "490.00", "10.00"},
+			{"2", "2023-03-15", "494.90", "5.10"},
+			{"3", "2023-04-15", "15.10", "0.15"},
+		}, rows(schedule))
+	})
+
+	t.Run("DIFFERENTIATED", func(t *testing.T) {
+		r := require.New(t)
+
+		schedule, err := BuildWithPayment(Differentiated, decimal(t, "1000"), decimal(t, "0.12"), decimal(t, "400"), start, 1)
+		r.NoError(err)
+		r.Equal([]row{
+			{"1", "2023-02-15", "400.00", "10.00"},
+			{"2", "2023-03-15", "400.00", "6.00"},
+			{"3", "2023-04-15", "200.00", "2.00"},
+		}, rows(schedule))
+	})
+
+	t.Run("INTEREST_NOT_COVERED", func(t *testing.T) {
+		_, err := BuildWithPayment(Annuity, decimal(t, "1000"), decimal(t, "0.12"), decimal(t, "10"), start, 1)
+		require.ErrorIs(t, err, ErrPaymentTooSmall)
+	})
+
+	// the schedule stops at MaxInstallments and the last one clears the rest
+	t.Run("MAX_INSTALLMENTS", func(t *testing.T) {
+		r := require.New(t)
+
+		schedule, err := BuildWithPayment(Differentiated, decimal(t, "1000"), decimal(t, "0"), decimal(t, "1"), start, 1)
+		r.NoError(err)
+		r.Len(schedule, MaxInstallments)
+		r.Equal("401.00", interest.FormatDecimal(schedule[MaxInstallments-1].Principal, interest.PostingScale))
+	})
+}
@@ -0,0 +1,123 @@
+// Package loan builds amortization schedules. Interest is charged per monthly period at a twelfth
+// of the annual rate and every installment is rounded to the cent; the last one clears what is left.
+package loan
+
+import (
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/dilmurodov/online_banking/pkg/interest"
+)
+
+// ScheduleType is the way the principal is spread over the installments
+type ScheduleType string
+
+const (
+	// Annuity repays in equal installments, the principal part grows as the interest shrinks
+	Annuity ScheduleType = "annuity"
+	// Differentiated repays equal principal parts with the interest on top, so installments decrease
+	Differentiated ScheduleType = "differentiated"
+)
+
+// MaxInstallments bounds schedules built from a fixed payment
+const MaxInstallments = 600
+
+var ErrPaymentTooSmall = errors.New("the payment does not cover the interest")
+
+func (t ScheduleType) Valid() bool {
+	return t == Annuity || t == Differentiated
+}
+
+type Installment struct {
+	Number    int
+	DueDate   time.Time
+	Principal *big.Rat
+	Interest  *big.Rat
+}
+
+func (i Installment) Total() *big.Rat {
+	return new(big.Rat).Add(i.Principal, i.Interest)
+}
+
+// MonthlyRate is the interest rate of one monthly period
+func MonthlyRate(annualRate *big.Rat) *big.Rat {
+	return new(big.Rat).Quo(annualRate, big.NewRat(12, 1))
+}
+
+// AnnuityPayment returns the equal installment repaying the principal over n months, rounded to the cent
+func AnnuityPayment(principal, annualRate *big.Rat, n int) *big.Rat {
+	r := MonthlyRate(annualRate)
+	if r.Sign() == 0 {
+		return interest.Round(new(big.Rat).Quo(principal, big.NewRat(int64(n), 1)), interest.PostingScale)
+	}
+
+	// P * r * (1+r)^n / ((1+r)^n - 1)
+	growth := new(big.Rat).SetInt64(1)
+	base := new(big.Rat).Add(big.NewRat(1, 1), r)
+	for i := 0; i < n; i++ {
+		growth.Mul(growth, base)
+	}
+
+	payment := new(big.Rat).Mul(principal, r)
+	payment.Mul(payment, growth)
+	payment.Quo(payment, new(big.Rat).Sub(growth, big.NewRat(1, 1)))
+
+	return interest.Round(payment, interest.PostingScale)
+}
+
+// Build spreads the principal over n installments. Numbering starts at first and installment k
+// falls due k months after start, so a rebuilt schedule keeps the original due dates.
+func Build(t ScheduleType, principal, annualRate *big.Rat, n int, start time.Time, first int) []Installment {
+	var payment *big.Rat
+	switch t {
+	case Annuity:
+		payment = AnnuityPayment(principal, annualRate, n)
+	default:
+		payment = interest.Round(new(big.Rat).Quo(principal, big.NewRat(int64(n), 1)), interest.PostingScale)
+	}
+
+	schedule, _ := build(t, principal, annualRate, payment, n, start, first)
+	return schedule
+}
+
+// BuildWithPayment keeps the installment, or the principal part of a differentiated schedule,
+// and repays the principal in as many months as that takes
+func BuildWithPayment(t ScheduleType, principal, annualRate, payment *big.Rat, start time.Time, first int) ([]Installment, error) {
+	return build(t, principal, annualRate, payment, MaxInstallments, start, first)
+}
+
+func build(t ScheduleType, principal, annualRate, payment *big.Rat, n int, start time.Time, first int) ([]Installment, error) {
+	r := MonthlyRate(annualRate)
+	remaining := new(big.Rat).Set(principal)
+	schedule := make([]Installment, 0, n)
+
+	for k := 0; k < n && remaining.Sign() > 0; k++ {
+		periodInterest := interest.Round(new(big.Rat).Mul(remaining, r), interest.PostingScale)
+
+		part := new(big.Rat).Set(payment)
+		if t == Annuity {
+			part.Sub(part, periodInterest)
+		}
+		if part.Sign() <= 0 {
+			return nil, ErrPaymentTooSmall
+		}
+		if k == n-1 || part.Cmp(remaining) > 0 {
+			part.Set(remaining)
+		}
+		remaining.Sub(remaining, part)
+
+		schedule = append(schedule, Installment{
+			Number:    first + k,
+			DueDate:   interest.AddMonths(start, first+k),
+			Principal: part,
+			Interest:  periodInterest,
+		})
+	}
+
+	if remaining.Sign() > 0 {
+		return nil, ErrPaymentTooSmall
+	}
+
+	return schedule, nil
+}
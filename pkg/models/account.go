@@ -2,17 +2,38 @@ package models
 
 // google uuid
 
+const (
+	AccountStatusActive = "active"
+	// AccountStatusFrozen accounts take credits but no debits
+	AccountStatusFrozen = "frozen"
+	// AccountStatusClosed accounts take no payments at all
+	AccountStatusClosed = "closed"
+
+	AccountStatusActorUser  = "user"
+	AccountStatusActorAdmin = "admin"
+)
+
 type Account struct {
-	ID        string  `json:"guid"`
-	UserID    string  `json:"user_id"`
-	Balance   float64 `json:"balance"`
-	CreatedAt string  `json:"created_at"`
-	UpdatedAt string  `json:"updated_at"`
+	ID      string  `json:"guid"`
+	UserID  string  `json:"user_id"`
+	Balance float64 `json:"balance"`
+	Product string  `json:"product"`
+	// AccruedInterest is the exact interest accrued since the last capitalization
+	AccruedInterest string `json:"accrued_interest"`
+	// OverdraftLimit is how far below zero the balance may go
+	OverdraftLimit float64 `json:"overdraft_limit"`
+	Status         string  `json:"status"`
+	ClosedAt       string  `json:"closed_at,omitempty"`
+	// Role is the requesting user's role on the account, only set by the accounts listing
+	Role      string `json:"role,omitempty"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
 }
 
 type CreateAccountRequest struct {
-	UserID  string  `json:"user_id"`
-	Balance float64 `json:"balance"`
+	UserID  string  `json:"-"`
+	Balance float64 `json:"-"`
+	Product string  `json:"product"`
 }
 
 type GetAccountByIDRequest struct {
@@ -29,9 +50,77 @@ type GetAccountsByUserIDRequest struct {
 	Offset  int    `json:"offset"`
 	OrderBy string `json:"order_by"`
 	Desc    bool   `json:"desc"`
+	// Cursor switches the listing to keyset pagination, Offset is ignored then
+	Cursor string `json:"cursor"`
 }
 
 type GetAccountsByUserIDResponse struct {
 	Accounts []*Account `json:"accounts"`
-	Count    int        `json:"count"`
+	// Count is only calculated in offset mode
+	Count      int    `json:"count"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+}
+
+// AccountStatusChange is an audited change of an account's status
+type AccountStatusChange struct {
+	ID         string `json:"guid"`
+	AccountID  string `json:"account_id"`
+	FromStatus string `json:"from_status"`
+	ToStatus   string `json:"to_status"`
+	// ActorID is the user or the staff member who made the change
+	ActorID   string `json:"actor_id,omitempty"`
+	ActorType string `json:"actor_type"`
+	Reason    string `json:"reason"`
+	CreatedAt string `json:"created_at"`
+}
+
+type GetAccountStatusHistoryResponse struct {
+	Changes []*AccountStatusChange `json:"changes"`
+}
+
+type CloseAccountRequest struct {
+	AccountID string `json:"-"`
+	UserID    string `json:"-"`
+	// PayoutAccountID receives the remaining balance, required unless the balance is zero
+	PayoutAccountID string `json:"payout_account_id"`
+	Reason          string `json:"reason"`
+	IP              string `json:"-"`
+}
+
+type CloseAccountResponse struct {
+	Account *Account `json:"account"`
+	// Transactions are the interest and payout postings made on closing
+	Transactions []*Transaction `json:"transactions"`
+}
+
+type FreezeAccountRequest struct {
+	AccountID string `json:"-"`
+	// ActorID is the staff member changing the status
+	ActorID string `json:"-"`
+	IP      string `json:"-"`
+	Reason  string `json:"reason"`
+}
+
+// AccountClosingBlockers counts what has to be settled before an account can be closed
+type AccountClosingBlockers struct {
+	Pots                int
+	Loans               int
+	Deposits            int
+	PendingTransactions int
+}
+
+// Reason names the first thing that keeps the account open, empty when nothing does
+func (b *AccountClosingBlockers) Reason() string {
+	switch {
+	case b.Pots > 0:
+		return "the account has open pots"
+	case b.Loans > 0:
+		return "the account has unpaid loans"
+	case b.Deposits > 0:
+		return "the account funds open term deposits"
+	case b.PendingTransactions > 0:
+		return "the account has pending transactions"
+	}
+	return ""
 }
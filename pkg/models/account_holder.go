@@ -0,0 +1,180 @@
+package models
+
+const (
+	AccountRoleOwner   = "owner"
+	AccountRoleCoOwner = "co_owner"
+	AccountRoleSigner  = "signer"
+	AccountRoleViewer  = "viewer"
+
+	// AccountPermissionView allows reading the account, its transactions and statements
+	AccountPermissionView = "view"
+	// AccountPermissionTransact allows paying from the account and approving its transfers
+	AccountPermissionTransact = "transact"
+	// AccountPermissionManage allows changing the account's products such as the overdraft and pots
+	AccountPermissionManage = "manage"
+	// AccountPermissionManageHolders allows inviting and removing holders and setting the dual approval
+	AccountPermissionManageHolders = "manage_holders"
+
+	InvitationStatusPending  = "pending"
+	InvitationStatusAccepted = "accepted"
+	InvitationStatusDeclined = "declined"
+	InvitationStatusRevoked  = "revoked"
+
+	TransferApprovalStatusPending  = "pending"
+	TransferApprovalStatusApproved = "approved"
+	TransferApprovalStatusRejected = "rejected"
+	TransferApprovalStatusExpired  = "expired"
+
+	AuditEventAccountHolderChanged      = "account_holder_changed"
+	AuditEventAccountHolderRemoved      = "account_holder_removed"
+	AuditEventDualApprovalChanged       = "dual_approval_changed"
+	AuditEventInvitationCreated         = "account_invitation_created"
+	AuditEventInvitationAnswered        = "account_invitation_answered"
+	AuditResourceInvitation             = "account_invitation"
+	AuditEventTransferApprovalRequested = "transfer_approval_requested"
+	AuditEventTransferApprovalDecided   = "transfer_approval_decided"
+	AuditResourceTransferApproval       = "transfer_approval"
+)
+
+var accountRolePermissions = map[string][]string{
+	AccountRoleOwner:   {AccountPermissionView, AccountPermissionTransact, AccountPermissionManage, AccountPermissionManageHolders},
+	AccountRoleCoOwner: {AccountPermissionView, AccountPermissionTransact, AccountPermissionManage},
+	AccountRoleSigner:  {AccountPermissionView, AccountPermissionTransact},
+	AccountRoleViewer:  {AccountPermissionView},
+}
+
+// AccountRoleAllows reports whether a holder with the role has the permission, an empty role has none
+func AccountRoleAllows(role, permission string) bool {
+	for _, p := range accountRolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// AccountRolesWith returns the roles that have the permission, for filtering holders in queries
+func AccountRolesWith(permission string) []string {
+	roles := make([]string, 0, len(accountRolePermissions))
+	for _, role := range []string{AccountRoleOwner, AccountRoleCoOwner, AccountRoleSigner, AccountRoleViewer} {
+		if AccountRoleAllows(role, permission) {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// IsInvitableAccountRole reports whether the role can be granted to another user, the owner can't
+func IsInvitableAccountRole(role string) bool {
+	return role == AccountRoleCoOwner || role == AccountRoleSigner || role == AccountRoleViewer
+}
+
+type AccountHolder struct {
+	AccountID string `json:"account_id"`
+	UserID    string `json:"user_id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Role      string `json:"role"`
+	CreatedAt string `json:"created_at"`
+}
+
+type GetAccountHoldersResponse struct {
+	Holders []*AccountHolder `json:"holders"`
+	// DualApprovalThreshold is the amount transfers above wait for a second holder, zero when off
+	DualApprovalThreshold string `json:"dual_approval_threshold"`
+}
+
+type AccountHolderRequest struct {
+	AccountID string `json:"-"`
+	// UserID is the holder making the change
+	UserID string `json:"-"`
+	// HolderID is the holder being changed
+	HolderID string `json:"-"`
+	Role     string `json:"role"`
+}
+
+type SetDualApprovalRequest struct {
+	AccountID string `json:"-"`
+	UserID    string `json:"-"`
+	// Threshold above which transfers need a second holder's approval, zero turns it off
+	Threshold float64 `json:"threshold"`
+}
+
+type AccountInvitation struct {
+	ID          string `json:"guid"`
+	AccountID   string `json:"account_id"`
+	InvitedBy   string `json:"invited_by"`
+	UserID      string `json:"user_id"`
+	Role        string `json:"role"`
+	Status      string `json:"status"`
+	ExpiresAt   string `json:"expires_at"`
+	RespondedAt string `json:"responded_at,omitempty"`
+	CreatedAt   string `json:"created_at"`
+}
+
+type CreateInvitationRequest struct {
+	AccountID string `json:"-"`
+	UserID    string `json:"-"`
+	// Phone of the user invited
+	Phone string `json:"phone"`
+	// Role is co_owner, signer or viewer
+	Role string `json:"role"`
+}
+
+type InvitationByIDRequest struct {
+	ID     string `json:"-"`
+	UserID string `json:"-"`
+	// AccountID is set when the account's owner acts on the invitation
+	AccountID string `json:"-"`
+}
+
+type GetInvitationsResponse struct {
+	Invitations []*AccountInvitation `json:"invitations"`
+}
+
+// ApprovalPolicy is what a transfer from an account needs to know about the holder requesting it
+type ApprovalPolicy struct {
+	Role      string
+	Threshold string
+	// Approvers is how many holders other than the requester may approve a transfer
+	Approvers int
+}
+
+type TransferApproval struct {
+	ID            string  `json:"guid"`
+	AccountID     string  `json:"account_id"`
+	RequestedBy   string  `json:"requested_by"`
+	ToAccountID   string  `json:"to_account_id"`
+	Amount        float64 `json:"amount"`
+	Description   string  `json:"description"`
+	Reference     string  `json:"reference"`
+	Status        string  `json:"status"`
+	DecidedBy     string  `json:"decided_by,omitempty"`
+	TransactionID string  `json:"transaction_id,omitempty"`
+	ExpiresAt     string  `json:"expires_at"`
+	DecidedAt     string  `json:"decided_at,omitempty"`
+	CreatedAt     string  `json:"created_at"`
+}
+
+type TransferApprovalByIDRequest struct {
+	ID        string `json:"-"`
+	AccountID string `json:"-"`
+	UserID    string `json:"-"`
+}
+
+type GetTransferApprovalsRequest struct {
+	AccountID string `json:"-"`
+	Status    string `json:"status"`
+	Limit     int    `json:"limit"`
+	Offset    int    `json:"offset"`
+}
+
+type GetTransferApprovalsResponse struct {
+	Approvals []*TransferApproval `json:"approvals"`
+	Count     int                 `json:"count"`
+}
+
+type TransferApprovalResponse struct {
+	Approval     *TransferApproval `json:"approval"`
+	Transactions []*Transaction    `json:"transactions,omitempty"`
+}
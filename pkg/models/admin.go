@@ -0,0 +1,151 @@
+package models
+
+const (
+	RoleCustomer   = "customer"
+	RoleSupport    = "support"
+	RoleCompliance = "compliance"
+	RoleAdmin      = "admin"
+
+	// PermissionUsersRead allows searching and viewing any user
+	PermissionUsersRead = "users.read"
+	// PermissionAccountsRead allows searching and viewing any account and its status history
+	PermissionAccountsRead = "accounts.read"
+	// PermissionTransactionsRead allows viewing any transaction
+	PermissionTransactionsRead = "transactions.read"
+	// PermissionAccountsFreeze allows freezing and unfreezing accounts
+	PermissionAccountsFreeze = "accounts.freeze"
+	// PermissionAdjustmentsCreate allows crediting and debiting accounts by hand
+	PermissionAdjustmentsCreate = "adjustments.create"
+	// PermissionRolesManage allows changing the roles of users
+	PermissionRolesManage = "roles.manage"
+	// PermissionTransactionsReverse allows posting transfers back
+	PermissionTransactionsReverse = "transactions.reverse"
+	// PermissionOperationsRead allows viewing the operations waiting for approval
+	PermissionOperationsRead = "operations.read"
+	// PermissionOperationsDecide allows approving and rejecting the operations waiting for approval
+	PermissionOperationsDecide = "operations.decide"
+	// PermissionAuditRead allows searching the audit log
+	PermissionAuditRead = "audit.read"
+	// PermissionFraudReview allows viewing and reviewing the fraud decisions
+	PermissionFraudReview = "fraud.review"
+	// PermissionFraudRulesManage allows reloading the fraud rules
+	PermissionFraudRulesManage = "fraud.rules_manage"
+	// PermissionAMLCases allows working the suspicious activity cases and exporting their reports
+	PermissionAMLCases = "aml.cases"
+	// PermissionSanctionsReview allows viewing and reviewing the sanctions screening hits
+	PermissionSanctionsReview = "sanctions.review"
+	// PermissionSanctionsListManage allows reloading the sanctions list
+	PermissionSanctionsListManage = "sanctions.list_manage"
+	// PermissionKYCReview allows viewing the KYC applications with their documents and approving or rejecting them
+	PermissionKYCReview = "kyc.review"
+
+	AdjustmentDirectionCredit = "credit"
+	AdjustmentDirectionDebit  = "debit"
+
+	AuditEventRoleChanged      = "role_changed"
+	AuditEventManualAdjustment = "manual_adjustment"
+)
+
+var rolePermissions = map[string][]string{
+	RoleCustomer: {},
+	RoleSupport:  {PermissionUsersRead, PermissionAccountsRead, PermissionTransactionsRead},
+	RoleCompliance: {PermissionUsersRead, PermissionAccountsRead, PermissionTransactionsRead,
+		PermissionAccountsFreeze, PermissionOperationsRead, PermissionAuditRead, PermissionFraudReview,
+		PermissionAMLCases, PermissionSanctionsReview, PermissionKYCReview},
+	RoleAdmin: {PermissionUsersRead, PermissionAccountsRead, PermissionTransactionsRead,
+		PermissionAccountsFreeze, PermissionAdjustmentsCreate, PermissionRolesManage,
+		PermissionTransactionsReverse, PermissionOperationsRead, PermissionOperationsDecide, PermissionAuditRead,
+		PermissionFraudReview, PermissionFraudRulesManage, PermissionAMLCases, PermissionSanctionsReview,
+		PermissionSanctionsListManage, PermissionKYCReview},
+}
+
+// AdjustmentReasonCodes are the reasons a manual adjustment or a reversal can be made for
+var AdjustmentReasonCodes = map[string]string{
+	"fee_refund":     "Возврат комиссии",
+	"chargeback":     "Оспоренная операция",
+	"goodwill":       "Компенсация клиенту",
+	"correction":     "Исправление ошибки",
+	"fraud_recovery": "Возврат мошеннических средств",
+}
+
+// RoleAllows reports whether the role has the permission, an unknown role has none
+func RoleAllows(role, permission string) bool {
+	for _, p := range rolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidRole reports whether the role exists
+func IsValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+type SearchUsersRequest struct {
+	// Query matches the phone, the names or the exact user id
+	Query  string `json:"q"`
+	Role   string `json:"role"`
+	Limit  int    `json:"limit"`
+	Offset int    `json:"offset"`
+}
+
+type SearchUsersResponse struct {
+	Users []*User `json:"users"`
+	Count int     `json:"count"`
+}
+
+type AdminUserResponse struct {
+	User     *User      `json:"user"`
+	Accounts []*Account `json:"accounts"`
+}
+
+type SetUserRoleRequest struct {
+	UserID  string `json:"-"`
+	ActorID string `json:"-"`
+	IP      string `json:"-"`
+	Role    string `json:"role"`
+}
+
+type SearchAccountsRequest struct {
+	// Query matches the exact account or user id, or the owner's phone
+	Query  string `json:"q"`
+	Status string `json:"status"`
+	Limit  int    `json:"limit"`
+	Offset int    `json:"offset"`
+}
+
+type SearchAccountsResponse struct {
+	Accounts []*Account `json:"accounts"`
+	Count    int        `json:"count"`
+}
+
+type CreateAdjustmentRequest struct {
+	AccountID  string  `json:"-"`
+	ActorID    string  `json:"-"`
+	IP         string  `json:"-"`
+	Direction  string  `json:"direction"`
+	Amount     float64 `json:"amount"`
+	ReasonCode string  `json:"reason_code"`
+	Comment    string  `json:"comment"`
+}
+
+type ManualAdjustment struct {
+	ID            string `json:"id"`
+	AccountID     string `json:"account_id"`
+	Direction     string `json:"direction"`
+	Amount        string `json:"amount"`
+	ReasonCode    string `json:"reason_code"`
+	Comment       string `json:"comment"`
+	ActorID       string `json:"actor_id"`
+	ApprovedBy    string `json:"approved_by"`
+	TransactionID string `json:"transaction_id"`
+	CreatedAt     string `json:"created_at"`
+}
+
+type CreateAdjustmentResponse struct {
+	Adjustment   *ManualAdjustment `json:"adjustment"`
+	Transactions []*Transaction    `json:"transactions"`
+}
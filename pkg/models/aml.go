@@ -0,0 +1,147 @@
+package models
+
+import "time"
+
+const (
+	AMLCaseCashThreshold = "cash_threshold"
+	AMLCaseStructuring   = "structuring"
+	AMLCaseRapidMovement = "rapid_movement"
+
+	AMLCaseStatusOpen          = "open"
+	AMLCaseStatusInvestigating = "investigating"
+	AMLCaseStatusReported      = "reported"
+	AMLCaseStatusClosed        = "closed"
+
+	AuditEventAMLCaseStatusChanged = "aml_case_status_changed"
+	AuditResourceAMLCase           = "aml_case"
+)
+
+var amlCaseTransitions = map[string][]string{
+	AMLCaseStatusOpen:          {AMLCaseStatusInvestigating, AMLCaseStatusClosed},
+	AMLCaseStatusInvestigating: {AMLCaseStatusReported, AMLCaseStatusClosed},
+	AMLCaseStatusReported:      {AMLCaseStatusClosed},
+}
+
+// AMLCaseTransitionAllowed tells whether a case may move from one status to the other
+func AMLCaseTransitionAllowed(from, to string) bool {
+	for _, s := range amlCaseTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// AMLRules are the thresholds the monitoring flags transactions with
+type AMLRules struct {
+	CashThreshold     float64
+	StructuringMargin float64
+	StructuringCount  int
+	StructuringWindow time.Duration
+	RapidMinAmount    float64
+	RapidRatio        float64
+	RapidWindow       time.Duration
+}
+
+// AMLTransaction is a committed transaction as the monitoring sees it
+type AMLTransaction struct {
+	ID          string    `json:"id"`
+	UserID      string    `json:"user_id"`
+	AccountID   string    `json:"account_id"`
+	RecipientID string    `json:"recipient_id"`
+	Type        string    `json:"type"`
+	Amount      float64   `json:"amount"`
+	Description string    `json:"description"`
+	Reference   string    `json:"reference"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// IsCash tells a deposit or a withdrawal apart from a transfer, cash moves in and out of the account itself
+func (t *AMLTransaction) IsCash() bool {
+	return t.AccountID == t.RecipientID
+}
+
+// AMLFlag is a pattern found by a rule with the transactions that make it up
+type AMLFlag struct {
+	Type           string
+	Reason         string
+	TransactionIDs []string
+}
+
+// AMLCursor is the last transaction the monitoring has read
+type AMLCursor struct {
+	CreatedAt     time.Time
+	TransactionID string
+}
+
+type GetAMLTransactionsRequest struct {
+	After *AMLCursor
+	// Before keeps the transactions that may not have committed yet out
+	Before time.Time
+	Limit  int
+}
+
+type GetAMLActivityRequest struct {
+	AccountID string
+	From      time.Time
+	To        time.Time
+}
+
+// AMLCase is a suspicious activity case with the transactions linked to it
+type AMLCase struct {
+	ID               string `json:"id"`
+	UserID           string `json:"user_id"`
+	AccountID        string `json:"account_id"`
+	Type             string `json:"type"`
+	Status           string `json:"status"`
+	Reason           string `json:"reason"`
+	AssigneeID       string `json:"assignee_id,omitempty"`
+	ReportReference  string `json:"report_reference,omitempty"`
+	TransactionCount int    `json:"transaction_count"`
+	CreatedAt        string `json:"created_at"`
+	UpdatedAt        string `json:"updated_at"`
+}
+
+type AMLCaseStatusChange struct {
+	ID         string `json:"id"`
+	CaseID     string `json:"case_id"`
+	FromStatus string `json:"from_status"`
+	ToStatus   string `json:"to_status"`
+	// ActorID is empty when the monitoring opened the case
+	ActorID   string `json:"actor_id,omitempty"`
+	Comment   string `json:"comment"`
+	CreatedAt string `json:"created_at"`
+}
+
+type GetAMLCasesRequest struct {
+	Status    string `json:"status"`
+	Type      string `json:"type"`
+	AccountID string `json:"account_id"`
+	Limit     int    `json:"limit"`
+	Offset    int    `json:"offset"`
+}
+
+type GetAMLCasesResponse struct {
+	Cases []*AMLCase `json:"cases"`
+	Count int        `json:"count"`
+}
+
+type UpdateAMLCaseStatusRequest struct {
+	ID      string `json:"-"`
+	ActorID string `json:"-"`
+	IP      string `json:"-"`
+	// Status is investigating, reported or closed
+	Status  string `json:"status"`
+	Comment string `json:"comment"`
+	// ReportReference is required to mark the case reported
+	ReportReference string `json:"report_reference"`
+}
+
+// AMLCaseReport is everything known about a case, exported for the regulator or an investigation
+type AMLCaseReport struct {
+	Case         *AMLCase               `json:"case"`
+	Subject      *User                  `json:"subject"`
+	Transactions []*AMLTransaction      `json:"transactions"`
+	History      []*AMLCaseStatusChange `json:"history"`
+	GeneratedAt  string                 `json:"generated_at"`
+}
@@ -0,0 +1,70 @@
+package models
+
+const (
+	// AuditEventLoginLocked is written when failed logins lock a phone or an IP out
+	AuditEventLoginLocked = "login_locked"
+	// AuditEventRequest is written for every state-changing API request
+	AuditEventRequest = "request"
+	// AuditEventAccountStatusChanged is written when staff freeze or unfreeze an account and when its holder closes it
+	AuditEventAccountStatusChanged = "account_status_changed"
+)
+
+// Audit resource types
+const (
+	AuditResourceUser      = "user"
+	AuditResourceAccount   = "account"
+	AuditResourceOperation = "operation"
+)
+
+type AuditEvent struct {
+	ID        string `json:"id"`
+	Seq       int64  `json:"seq"`
+	EventType string `json:"event_type"`
+	// ActorID is who made the change, empty for the system
+	ActorID string `json:"actor_id,omitempty"`
+	// UserID is the user the event is about
+	UserID       string                 `json:"user_id,omitempty"`
+	ResourceType string                 `json:"resource_type,omitempty"`
+	ResourceID   string                 `json:"resource_id,omitempty"`
+	IP           string                 `json:"ip"`
+	RequestID    string                 `json:"request_id,omitempty"`
+	Details      map[string]interface{} `json:"details"`
+	Before       map[string]interface{} `json:"before,omitempty"`
+	After        map[string]interface{} `json:"after,omitempty"`
+	PrevHash     string                 `json:"prev_hash"`
+	Hash         string                 `json:"hash"`
+	CreatedAt    string                 `json:"created_at"`
+}
+
+type GetAuditEventsRequest struct {
+	EventType    string `json:"event_type"`
+	ActorID      string `json:"actor_id"`
+	UserID       string `json:"user_id"`
+	ResourceType string `json:"resource_type"`
+	ResourceID   string `json:"resource_id"`
+	RequestID    string `json:"request_id"`
+	From         string `json:"from"`
+	To           string `json:"to"`
+	Limit        int    `json:"limit"`
+	Offset       int    `json:"offset"`
+}
+
+type GetAuditEventsResponse struct {
+	Events []*AuditEvent `json:"events"`
+	Count  int           `json:"count"`
+}
+
+// AuditChainReport is the result of walking the audit log hash chain
+type AuditChainReport struct {
+	Valid bool `json:"valid"`
+	// Checked is the number of chained events verified
+	Checked int `json:"checked"`
+	// Legacy is the number of events written before the chain existed
+	Legacy   int    `json:"legacy"`
+	HeadSeq  int64  `json:"head_seq"`
+	HeadHash string `json:"head_hash"`
+	// BrokenAtSeq and BrokenEventID point at the first event that does not verify
+	BrokenAtSeq   int64  `json:"broken_at_seq,omitempty"`
+	BrokenEventID string `json:"broken_event_id,omitempty"`
+	Reason        string `json:"reason,omitempty"`
+}
@@ -21,4 +21,6 @@ type UserWithAuth struct {
 type GetByCredentialsRequest struct {
 	Phone    string `json:"phone"`
 	Password string `json:"password"`
+	// IP is the client address the failed attempts are counted for too
+	IP string `json:"-"`
 }
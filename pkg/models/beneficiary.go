@@ -0,0 +1,57 @@
+package models
+
+const (
+	AuditEventBeneficiaryCreated = "beneficiary_created"
+	AuditEventBeneficiaryUpdated = "beneficiary_updated"
+	AuditEventBeneficiaryDeleted = "beneficiary_deleted"
+	AuditResourceBeneficiary     = "beneficiary"
+)
+
+type Beneficiary struct {
+	ID        string `json:"id"`
+	UserID    string `json:"user_id"`
+	Nickname  string `json:"nickname"`
+	AccountID string `json:"account_id"`
+	// TransferLimit caps a single transfer, 0 means no limit
+	TransferLimit float64 `json:"transfer_limit"`
+	// DailyLimit caps the total sent per day, 0 means no limit
+	DailyLimit      float64 `json:"daily_limit"`
+	CoolingOffUntil string  `json:"cooling_off_until"`
+	CreatedAt       string  `json:"created_at"`
+	UpdatedAt       string  `json:"updated_at"`
+}
+
+type CreateBeneficiaryRequest struct {
+	UserID        string  `json:"-"`
+	Nickname      string  `json:"nickname"`
+	AccountID     string  `json:"account_id"`
+	TransferLimit float64 `json:"transfer_limit"`
+	DailyLimit    float64 `json:"daily_limit"`
+}
+
+type UpdateBeneficiaryRequest struct {
+	ID            string  `json:"-"`
+	UserID        string  `json:"-"`
+	Nickname      string  `json:"nickname"`
+	TransferLimit float64 `json:"transfer_limit"`
+	DailyLimit    float64 `json:"daily_limit"`
+}
+
+type BeneficiaryByIDRequest struct {
+	ID     string `json:"id"`
+	UserID string `json:"user_id"`
+}
+
+type BeneficiaryByAccountRequest struct {
+	UserID    string `json:"user_id"`
+	AccountID string `json:"account_id"`
+}
+
+type GetBeneficiariesRequest struct {
+	UserID string `json:"user_id"`
+}
+
+type GetBeneficiariesResponse struct {
+	Beneficiaries []*Beneficiary `json:"beneficiaries"`
+	Count         int            `json:"count"`
+}
@@ -0,0 +1,98 @@
+package models
+
+const (
+	ProductTermDeposit = "term_deposit"
+
+	DepositInterestAtMaturity = "maturity"
+	DepositInterestMonthly    = "monthly"
+
+	DepositOnMaturityPayout   = "payout"
+	DepositOnMaturityRollover = "rollover"
+
+	DepositStatusActive     = "active"
+	DepositStatusMatured    = "matured"
+	DepositStatusRolledOver = "rolled_over"
+	DepositStatusWithdrawn  = "withdrawn"
+)
+
+// DepositTerm is a term the bank offers deposits for. Decimal values are strings to keep them exact.
+type DepositTerm struct {
+	TermMonths          int    `json:"term_months"`
+	AnnualRate          string `json:"annual_rate"`
+	EarlyWithdrawalRate string `json:"early_withdrawal_rate"`
+	DayCount            string `json:"day_count"`
+	MinAmount           string `json:"min_amount"`
+}
+
+type GetDepositTermsResponse struct {
+	Terms []*DepositTerm `json:"terms"`
+}
+
+type TermDeposit struct {
+	ID                  string `json:"guid"`
+	UserID              string `json:"user_id"`
+	AccountID           string `json:"account_id"`
+	SourceAccountID     string `json:"source_account_id"`
+	Principal           string `json:"principal"`
+	TermMonths          int    `json:"term_months"`
+	AnnualRate          string `json:"annual_rate"`
+	EarlyWithdrawalRate string `json:"early_withdrawal_rate"`
+	DayCount            string `json:"day_count"`
+	InterestPayout      string `json:"interest_payout"`
+	OnMaturity          string `json:"on_maturity"`
+	Status              string `json:"status"`
+	OpenedOn            string `json:"opened_on"`
+	MaturityDate        string `json:"maturity_date"`
+	InterestPaidUntil   string `json:"interest_paid_until"`
+	InterestPeriodsPaid int    `json:"interest_periods_paid"`
+	PaidInterest        string `json:"paid_interest"`
+	RolledFromID        string `json:"rolled_from_id,omitempty"`
+	ClosedAt            string `json:"closed_at,omitempty"`
+	CreatedAt           string `json:"created_at"`
+	UpdatedAt           string `json:"updated_at"`
+}
+
+type OpenDepositRequest struct {
+	UserID          string  `json:"-"`
+	SourceAccountID string  `json:"source_account_id"`
+	Amount          float64 `json:"amount"`
+	TermMonths      int     `json:"term_months"`
+	// InterestPayout is "maturity" or "monthly"
+	InterestPayout string `json:"interest_payout"`
+	// OnMaturity is "payout" or "rollover"
+	OnMaturity string `json:"on_maturity"`
+}
+
+type DepositByIDRequest struct {
+	ID     string `json:"-"`
+	UserID string `json:"-"`
+}
+
+type GetDepositsRequest struct {
+	UserID string `json:"-"`
+	Status string `json:"status"`
+	Limit  int    `json:"limit"`
+	Offset int    `json:"offset"`
+}
+
+type GetDepositsResponse struct {
+	Deposits []*TermDeposit `json:"deposits"`
+	Count    int            `json:"count"`
+}
+
+type UpdateDepositInstructionRequest struct {
+	ID         string `json:"-"`
+	UserID     string `json:"-"`
+	OnMaturity string `json:"on_maturity"`
+}
+
+type WithdrawDepositResponse struct {
+	Deposit *TermDeposit `json:"deposit"`
+	// Interest is what the early withdrawal rate earns over the elapsed days
+	Interest string `json:"interest"`
+	// Penalty is the interest already paid above that, taken back from the principal
+	Penalty string `json:"penalty"`
+	// Payout is the amount returned to the source account
+	Payout       string         `json:"payout"`
+	Transactions []*Transaction `json:"transactions"`
+}
@@ -0,0 +1,180 @@
+package models
+
+const (
+	FraudOutcomeAllow     = "allow"
+	FraudOutcomeChallenge = "challenge"
+	FraudOutcomeBlock     = "block"
+
+	FraudOperationTransfer   = "transfer"
+	FraudOperationWithdrawal = "withdrawal"
+
+	FraudRuleVelocity        = "velocity"
+	FraudRuleAmountAverage   = "amount_vs_average"
+	FraudRuleNewCounterparty = "new_counterparty"
+	FraudRuleNightWithdrawal = "night_withdrawal"
+
+	// FraudChallengePending decisions wait for the code, passed ones let one retry of the
+	// same payment through and are used by it
+	FraudChallengePending = "pending"
+	FraudChallengePassed  = "passed"
+	FraudChallengeUsed    = "used"
+
+	FraudReviewPending    = "pending"
+	FraudReviewLegitimate = "legitimate"
+	FraudReviewFraud      = "fraud"
+
+	// OTPPurposePaymentChallenge codes confirm a payment the fraud checks challenged
+	OTPPurposePaymentChallenge = "payment_challenge"
+
+	AuditEventFraudReviewed      = "fraud_decision_reviewed"
+	AuditEventFraudRulesReloaded = "fraud_rules_reloaded"
+	AuditResourceFraudDecision   = "fraud_decision"
+	AuditResourceFraudRules      = "fraud_rules"
+)
+
+// FraudRules is the configuration of the fraud checks, it is read from a JSON file
+type FraudRules struct {
+	// ChallengeScore and BlockScore are the total scores a payment is challenged and blocked at
+	ChallengeScore  int                   `json:"challenge_score"`
+	BlockScore      int                   `json:"block_score"`
+	Velocity        FraudVelocityRule     `json:"velocity"`
+	AmountAverage   FraudAmountRule       `json:"amount_vs_average"`
+	NewCounterparty FraudCounterpartyRule `json:"new_counterparty"`
+	NightWithdrawal FraudNightRule        `json:"night_withdrawal"`
+}
+
+// FraudVelocityRule scores an account that makes more debits in an hour than allowed
+type FraudVelocityRule struct {
+	Enabled          bool `json:"enabled"`
+	MaxDebitsPerHour int  `json:"max_debits_per_hour"`
+	Score            int  `json:"score"`
+}
+
+// FraudAmountRule scores an amount that is Multiplier times the user's average debit or more,
+// once the user has MinHistory debits in the last LookbackDays
+type FraudAmountRule struct {
+	Enabled      bool    `json:"enabled"`
+	Multiplier   float64 `json:"multiplier"`
+	MinHistory   int     `json:"min_history"`
+	LookbackDays int     `json:"lookback_days"`
+	Score        int     `json:"score"`
+}
+
+// FraudCounterpartyRule scores the first transfer of at least MinAmount to someone else's account
+type FraudCounterpartyRule struct {
+	Enabled   bool    `json:"enabled"`
+	MinAmount float64 `json:"min_amount"`
+	Score     int     `json:"score"`
+}
+
+// FraudNightRule scores a withdrawal of at least MinAmount between StartHour and EndHour
+// in the business timezone
+type FraudNightRule struct {
+	Enabled   bool    `json:"enabled"`
+	StartHour int     `json:"start_hour"`
+	EndHour   int     `json:"end_hour"`
+	MinAmount float64 `json:"min_amount"`
+	Score     int     `json:"score"`
+}
+
+type GetFraudSignalsRequest struct {
+	UserID         string
+	AccountID      string
+	CounterpartyID string
+	// HourAgo starts the velocity window, Since the window the average debit is taken over
+	HourAgo string
+	Since   string
+}
+
+// FraudSignals is what the checks know about a payment and the history behind it
+type FraudSignals struct {
+	Operation      string
+	Amount         float64
+	CounterpartyID string
+	// OwnCounterparty is set when the money goes to another account of the same user
+	OwnCounterparty      bool
+	DebitsLastHour       int
+	AverageDebit         float64
+	DebitCount           int
+	CounterpartyPayments int
+	// Hour is the hour of the day in the business timezone
+	Hour int
+}
+
+type FraudRuleHit struct {
+	Rule   string `json:"rule"`
+	Score  int    `json:"score"`
+	Reason string `json:"reason"`
+}
+
+type FraudAssessment struct {
+	Score   int             `json:"score"`
+	Outcome string          `json:"outcome"`
+	Hits    []*FraudRuleHit `json:"hits"`
+}
+
+// FraudDecision is a stored assessment of a payment, kept for review when any rule fired
+type FraudDecision struct {
+	ID              string          `json:"id"`
+	UserID          string          `json:"user_id"`
+	AccountID       string          `json:"account_id"`
+	Operation       string          `json:"operation"`
+	CounterpartyID  string          `json:"counterparty_id,omitempty"`
+	Amount          string          `json:"amount"`
+	Score           int             `json:"score"`
+	Outcome         string          `json:"outcome"`
+	Hits            []*FraudRuleHit `json:"hits"`
+	ChallengeStatus string          `json:"challenge_status,omitempty"`
+	ReviewStatus    string          `json:"review_status"`
+	ReviewedBy      string          `json:"reviewed_by,omitempty"`
+	ReviewComment   string          `json:"review_comment"`
+	ReviewedAt      string          `json:"reviewed_at,omitempty"`
+	CreatedAt       string          `json:"created_at"`
+}
+
+// FraudChallenge is returned instead of the payment when it has to be confirmed with a code
+type FraudChallenge struct {
+	DecisionID string `json:"decision_id"`
+	ExpiresAt  string `json:"expires_at"`
+	Message    string `json:"message"`
+}
+
+type ConfirmFraudChallengeRequest struct {
+	DecisionID string `json:"-"`
+	UserID     string `json:"-"`
+	Code       string `json:"code"`
+}
+
+// UseFraudChallengeRequest spends a passed challenge on the payment it was made for
+type UseFraudChallengeRequest struct {
+	DecisionID     string
+	UserID         string
+	AccountID      string
+	CounterpartyID string
+	Amount         string
+	// CreatedAfter is the oldest decision that may still be used
+	CreatedAfter string
+}
+
+type GetFraudDecisionsRequest struct {
+	UserID       string `json:"user_id"`
+	AccountID    string `json:"account_id"`
+	Outcome      string `json:"outcome"`
+	ReviewStatus string `json:"review_status"`
+	Limit        int    `json:"limit"`
+	Offset       int    `json:"offset"`
+}
+
+type GetFraudDecisionsResponse struct {
+	Decisions []*FraudDecision `json:"decisions"`
+	Count     int              `json:"count"`
+}
+
+type ReviewFraudDecisionRequest struct {
+	ID      string `json:"-"`
+	ActorID string `json:"-"`
+	IP      string `json:"-"`
+	// Status is legitimate or fraud
+	Status  string `json:"status"`
+	Comment string `json:"comment"`
+}
@@ -0,0 +1,86 @@
+package models
+
+import "time"
+
+const (
+	ProductCurrent = "current"
+	ProductSavings = "savings"
+)
+
+// Product is an account product. Decimal values are strings to keep them exact.
+type Product struct {
+	Code       string `json:"code"`
+	Name       string `json:"name"`
+	AnnualRate string `json:"annual_rate"`
+	DayCount   string `json:"day_count"`
+	CreatedAt  string `json:"created_at"`
+	UpdatedAt  string `json:"updated_at"`
+}
+
+type GetProductsResponse struct {
+	Products []*Product `json:"products"`
+}
+
+type InterestAccrual struct {
+	ID           string `json:"guid"`
+	AccountID    string `json:"account_id"`
+	BusinessDate string `json:"business_date"`
+	Balance      string `json:"balance"`
+	AnnualRate   string `json:"annual_rate"`
+	DayCount     string `json:"day_count"`
+	Amount       string `json:"amount"`
+	CreatedAt    string `json:"created_at"`
+}
+
+// AccrualCandidate is an interest-bearing account with its balance at the end of the business date
+type AccrualCandidate struct {
+	AccountID  string
+	Balance    string
+	AnnualRate string
+	DayCount   string
+}
+
+type GetAccrualCandidatesRequest struct {
+	BusinessDate string
+	// EndOfDay is the first instant after the business date
+	EndOfDay time.Time
+}
+
+type CapitalizationCandidate struct {
+	AccountID       string
+	AccruedInterest string
+}
+
+type GetCapitalizationCandidatesRequest struct {
+	// Period is the first day of the capitalized month
+	Period string
+}
+
+type InterestCapitalization struct {
+	ID            string `json:"guid"`
+	AccountID     string `json:"account_id"`
+	Period        string `json:"period"`
+	Amount        string `json:"amount"`
+	TransactionID string `json:"transaction_id"`
+}
+
+type GetInterestAccrualsRequest struct {
+	AccountID string `json:"account_id"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Limit     int    `json:"limit"`
+	Offset    int    `json:"offset"`
+}
+
+type GetInterestAccrualsResponse struct {
+	Accruals []*InterestAccrual `json:"accruals"`
+	Count    int                `json:"count"`
+	// AccruedInterest is the amount waiting for the next capitalization
+	AccruedInterest string `json:"accrued_interest"`
+}
+
+type AdjustAccountBalanceRequest struct {
+	AccountID string
+	// Amount is a signed decimal added to the balance
+	Amount string
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+const (
+	JobStatusRunning = "running"
+	JobStatusDone    = "done"
+	JobStatusFailed  = "failed"
+)
+
+type StartJobRunRequest struct {
+	Job          string
+	BusinessDate string
+	// runs still marked as running before this time are treated as crashed and may be taken over
+	StaleBefore time.Time
+}
+
+type FinishJobRunRequest struct {
+	Job          string
+	BusinessDate string
+	Error        string
+}
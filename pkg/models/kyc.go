@@ -0,0 +1,107 @@
+package models
+
+const (
+	// KYCTierUnverified users only registered, KYCTierBasic ones had an identity document checked and
+	// KYCTierFull ones their address as well
+	KYCTierUnverified = "unverified"
+	KYCTierBasic      = "basic"
+	KYCTierFull       = "full"
+
+	// KYCDocumentPassport, KYCDocumentIDCard and KYCDocumentDrivingLicence prove who the user is,
+	// KYCDocumentProofOfAddress where they live
+	KYCDocumentPassport       = "passport"
+	KYCDocumentIDCard         = "id_card"
+	KYCDocumentDrivingLicence = "driving_licence"
+	KYCDocumentProofOfAddress = "proof_of_address"
+	KYCDocumentSelfie         = "selfie"
+
+	KYCApplicationPending  = "pending"
+	KYCApplicationApproved = "approved"
+	KYCApplicationRejected = "rejected"
+
+	// KYCLimitBalance caps what an account can hold, KYCLimitDeposit and KYCLimitWithdrawal
+	// a single deposit and withdrawal
+	KYCLimitBalance    = "balance"
+	KYCLimitDeposit    = "deposit"
+	KYCLimitWithdrawal = "withdrawal"
+
+	AuditEventKYCApplicationReviewed = "kyc_application_reviewed"
+	AuditResourceKYCApplication      = "kyc_application"
+)
+
+// KYCLimits are the caps of a tier, zero is no cap
+type KYCLimits struct {
+	Balance    float64 `json:"balance"`
+	Deposit    float64 `json:"deposit"`
+	Withdrawal float64 `json:"withdrawal"`
+}
+
+// KYCDocument is the metadata of an uploaded document, the file itself is in the blob store.
+// ApplicationID is empty until the document is submitted for review.
+type KYCDocument struct {
+	ID            string `json:"id"`
+	UserID        string `json:"user_id"`
+	ApplicationID string `json:"application_id,omitempty"`
+	Type          string `json:"type"`
+	FileName      string `json:"file_name"`
+	ContentType   string `json:"content_type"`
+	Size          int64  `json:"size"`
+	SHA256        string `json:"sha256"`
+	CreatedAt     string `json:"created_at"`
+}
+
+// KYCApplication asks for the user to be moved to a tier on the documents submitted with it
+type KYCApplication struct {
+	ID            string         `json:"id"`
+	UserID        string         `json:"user_id"`
+	Tier          string         `json:"tier"`
+	Status        string         `json:"status"`
+	ReviewedBy    string         `json:"reviewed_by,omitempty"`
+	ReviewComment string         `json:"review_comment"`
+	ReviewedAt    string         `json:"reviewed_at,omitempty"`
+	CreatedAt     string         `json:"created_at"`
+	Documents     []*KYCDocument `json:"documents,omitempty"`
+}
+
+// KYCStatus is the tier of a user with its caps, the latest application and the documents not submitted yet
+type KYCStatus struct {
+	Tier        string          `json:"tier"`
+	Limits      KYCLimits       `json:"limits"`
+	Application *KYCApplication `json:"application,omitempty"`
+	Documents   []*KYCDocument  `json:"documents"`
+}
+
+type UploadKYCDocumentRequest struct {
+	UserID   string
+	Type     string
+	FileName string
+	Content  []byte
+}
+
+type SubmitKYCApplicationRequest struct {
+	UserID string `json:"-"`
+	// Tier is basic or full
+	Tier string `json:"tier"`
+}
+
+type GetKYCApplicationsRequest struct {
+	UserID string `json:"user_id"`
+	Status string `json:"status"`
+	Tier   string `json:"tier"`
+	Limit  int    `json:"limit"`
+	Offset int    `json:"offset"`
+}
+
+type GetKYCApplicationsResponse struct {
+	Applications []*KYCApplication `json:"applications"`
+	Count        int               `json:"count"`
+}
+
+type ReviewKYCApplicationRequest struct {
+	ID      string `json:"-"`
+	ActorID string `json:"-"`
+	IP      string `json:"-"`
+	// Status is approved or rejected
+	Status  string `json:"status"`
+	Comment string `json:"comment"`
+}
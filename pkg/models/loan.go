@@ -0,0 +1,135 @@
+package models
+
+const (
+	LoanScheduleAnnuity        = "annuity"
+	LoanScheduleDifferentiated = "differentiated"
+
+	LoanStatusActive     = "active"
+	LoanStatusDelinquent = "delinquent"
+	LoanStatusDefaulted  = "defaulted"
+	LoanStatusRepaid     = "repaid"
+
+	LoanInstallmentPending = "pending"
+	LoanInstallmentOverdue = "overdue"
+	LoanInstallmentPaid    = "paid"
+
+	EarlyRepaymentReducePayment = "reduce_payment"
+	EarlyRepaymentReduceTerm    = "reduce_term"
+
+	AuditEventLoanDisbursed      = "loan_disbursed"
+	AuditEventLoanEarlyRepayment = "loan_early_repayment"
+	AuditResourceLoan            = "loan"
+)
+
+// LoanProduct is a loan the bank offers. Decimal values are strings to keep them exact.
+type LoanProduct struct {
+	Code          string `json:"code"`
+	Name          string `json:"name"`
+	AnnualRate    string `json:"annual_rate"`
+	MinAmount     string `json:"min_amount"`
+	MaxAmount     string `json:"max_amount"`
+	MinTermMonths int    `json:"min_term_months"`
+	MaxTermMonths int    `json:"max_term_months"`
+	LateFee       string `json:"late_fee"`
+	GraceDays     int    `json:"grace_days"`
+}
+
+type GetLoanProductsResponse struct {
+	Products []*LoanProduct `json:"products"`
+}
+
+type LoanInstallment struct {
+	ID        string `json:"guid"`
+	LoanID    string `json:"loan_id"`
+	Number    int    `json:"number"`
+	DueDate   string `json:"due_date"`
+	Principal string `json:"principal"`
+	Interest  string `json:"interest"`
+	LateFee   string `json:"late_fee"`
+	Total     string `json:"total"`
+	Status    string `json:"status"`
+	PaidAt    string `json:"paid_at,omitempty"`
+}
+
+type Loan struct {
+	ID                        string             `json:"guid"`
+	UserID                    string             `json:"user_id"`
+	AccountID                 string             `json:"account_id"`
+	Product                   string             `json:"product"`
+	Principal                 string             `json:"principal"`
+	AnnualRate                string             `json:"annual_rate"`
+	TermMonths                int                `json:"term_months"`
+	ScheduleType              string             `json:"schedule_type"`
+	LateFee                   string             `json:"late_fee"`
+	GraceDays                 int                `json:"grace_days"`
+	Status                    string             `json:"status"`
+	OutstandingPrincipal      string             `json:"outstanding_principal"`
+	LateFeesDue               string             `json:"late_fees_due"`
+	DisbursedOn               string             `json:"disbursed_on"`
+	DisbursementTransactionID string             `json:"disbursement_transaction_id"`
+	ClosedAt                  string             `json:"closed_at,omitempty"`
+	CreatedAt                 string             `json:"created_at"`
+	UpdatedAt                 string             `json:"updated_at"`
+	Installments              []*LoanInstallment `json:"installments,omitempty"`
+}
+
+type LoanQuoteRequest struct {
+	Product    string  `json:"product"`
+	Amount     float64 `json:"amount"`
+	TermMonths int     `json:"term_months"`
+	// ScheduleType is "annuity" or "differentiated"
+	ScheduleType string `json:"schedule_type"`
+}
+
+// LoanQuote is the schedule a loan would have if it were disbursed today
+type LoanQuote struct {
+	Product       string             `json:"product"`
+	Principal     string             `json:"principal"`
+	AnnualRate    string             `json:"annual_rate"`
+	TermMonths    int                `json:"term_months"`
+	ScheduleType  string             `json:"schedule_type"`
+	TotalInterest string             `json:"total_interest"`
+	TotalPayment  string             `json:"total_payment"`
+	Installments  []*LoanInstallment `json:"installments"`
+}
+
+type ApplyLoanRequest struct {
+	UserID string `json:"-"`
+	// AccountID receives the loan and is debited for the installments
+	AccountID string `json:"account_id"`
+	LoanQuoteRequest
+}
+
+type LoanByIDRequest struct {
+	ID     string `json:"-"`
+	UserID string `json:"-"`
+}
+
+type GetLoansRequest struct {
+	UserID string `json:"-"`
+	Status string `json:"status"`
+	Limit  int    `json:"limit"`
+	Offset int    `json:"offset"`
+}
+
+type GetLoansResponse struct {
+	Loans []*Loan `json:"loans"`
+	Count int     `json:"count"`
+}
+
+type EarlyRepaymentRequest struct {
+	ID     string  `json:"-"`
+	UserID string  `json:"-"`
+	Amount float64 `json:"amount"`
+	// Mode is "reduce_payment" or "reduce_term", it is ignored when the loan is repaid in full
+	Mode string `json:"mode"`
+}
+
+type EarlyRepaymentResponse struct {
+	Loan *Loan `json:"loan"`
+	// Principal is the part of the amount that went to the principal
+	Principal string `json:"principal"`
+	// Interest is the interest accrued since the last due date, only charged on full repayment
+	Interest     string         `json:"interest"`
+	Transactions []*Transaction `json:"transactions"`
+}
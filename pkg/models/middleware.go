@@ -5,4 +5,6 @@ type HasAccessModel struct {
 	UserId    string `json:"user_id"`
 	FirstName string `json:"first_name"`
 	LastName  string `json:"last_name"`
+	// Role is the staff role of the user, customer for everyone else
+	Role string `json:"role"`
 }
@@ -0,0 +1,81 @@
+package models
+
+const (
+	// OperationTypeManualAdjustment credits or debits an account by hand
+	OperationTypeManualAdjustment = "manual_adjustment"
+	// OperationTypeAccountUnfreeze lets a frozen account make payments again
+	OperationTypeAccountUnfreeze = "account_unfreeze"
+	// OperationTypeTransactionReversal posts a transfer back to the account it came from
+	OperationTypeTransactionReversal = "transaction_reversal"
+
+	OperationStatusPending  = "pending"
+	OperationStatusApproved = "approved"
+	OperationStatusRejected = "rejected"
+	OperationStatusExpired  = "expired"
+
+	AuditEventOperationProposed = "operation_proposed"
+	AuditEventOperationApproved = "operation_approved"
+	AuditEventOperationRejected = "operation_rejected"
+)
+
+// operationPermissions is what both the maker and the checker of an operation need
+var operationPermissions = map[string]string{
+	OperationTypeManualAdjustment:    PermissionAdjustmentsCreate,
+	OperationTypeAccountUnfreeze:     PermissionAccountsFreeze,
+	OperationTypeTransactionReversal: PermissionTransactionsReverse,
+}
+
+// OperationPermission returns the permission the operation type needs, empty for an unknown type
+func OperationPermission(operationType string) string {
+	return operationPermissions[operationType]
+}
+
+// PendingOperation is a staff operation proposed by one person that waits for another one's approval
+type PendingOperation struct {
+	ID         string                 `json:"id"`
+	Type       string                 `json:"type"`
+	ResourceID string                 `json:"resource_id"`
+	Payload    map[string]interface{} `json:"payload"`
+	Status     string                 `json:"status"`
+	ProposedBy string                 `json:"proposed_by"`
+	DecidedBy  string                 `json:"decided_by,omitempty"`
+	Comment    string                 `json:"comment"`
+	Result     map[string]interface{} `json:"result"`
+	ExpiresAt  string                 `json:"expires_at"`
+	DecidedAt  string                 `json:"decided_at,omitempty"`
+	CreatedAt  string                 `json:"created_at"`
+}
+
+type GetPendingOperationsRequest struct {
+	Type   string `json:"type"`
+	Status string `json:"status"`
+	Limit  int    `json:"limit"`
+	Offset int    `json:"offset"`
+}
+
+type GetPendingOperationsResponse struct {
+	Operations []*PendingOperation `json:"operations"`
+	Count      int                 `json:"count"`
+}
+
+type DecideOperationRequest struct {
+	ID        string `json:"-"`
+	ActorID   string `json:"-"`
+	ActorRole string `json:"-"`
+	IP        string `json:"-"`
+	Comment   string `json:"comment"`
+}
+
+type ReverseTransactionRequest struct {
+	TransactionID string `json:"-"`
+	ActorID       string `json:"-"`
+	IP            string `json:"-"`
+	ReasonCode    string `json:"reason_code"`
+	Comment       string `json:"comment"`
+}
+
+type DecideOperationResponse struct {
+	Operation    *PendingOperation `json:"operation"`
+	Transactions []*Transaction    `json:"transactions,omitempty"`
+	Account      *Account          `json:"account,omitempty"`
+}
@@ -0,0 +1,29 @@
+package models
+
+type SetOverdraftLimitRequest struct {
+	AccountID string  `json:"-"`
+	UserID    string  `json:"-"`
+	Limit     float64 `json:"limit"`
+}
+
+// OverdraftUsage shows how much of the overdraft an account uses. Decimal values are strings to keep them exact.
+type OverdraftUsage struct {
+	AccountID string `json:"account_id"`
+	Balance   string `json:"balance"`
+	Limit     string `json:"limit"`
+	// Used is the overdrawn part of the balance
+	Used string `json:"used"`
+	// Available is what the account can still spend, the limit included
+	Available  string `json:"available"`
+	AnnualRate string `json:"annual_rate"`
+	// AccruedInterest is the overdraft interest waiting for the next monthly charge
+	AccruedInterest string `json:"accrued_interest"`
+}
+
+// OverdraftChargeCandidate is an account with overdraft interest to charge
+type OverdraftChargeCandidate struct {
+	AccountID       string
+	AccruedInterest string
+	// Available is the balance plus the limit, the most that can be charged without breaching the limit
+	Available string
+}
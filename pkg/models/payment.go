@@ -1,18 +1,36 @@
 package models
 
+// AuditEventTransferMade is written for the transfers holders make, not for the postings the bank books itself
+const AuditEventTransferMade = "transfer_made"
+
 type TransferRequest struct {
+	UserID        string  `json:"-"`
 	FromAccountID string  `json:"from_account_id"`
 	ToAccountID   string  `json:"to_account_id"`
+	BeneficiaryID string  `json:"beneficiary_id"`
 	Amount        float64 `json:"amount"`
+	Description   string  `json:"description"`
+	Reference     string  `json:"reference"`
+	// Immediate refuses a transfer that would have to wait for a second holder's approval
+	Immediate bool `json:"-"`
+	// ChallengeID is the confirmed fraud challenge of an earlier attempt at the same transfer
+	ChallengeID string `json:"challenge_id"`
 }
 
 type TransferResponse struct {
 	Transactions []*Transaction `json:"transaction"`
+	// Approval is set instead of the transactions when the transfer waits for a second holder
+	Approval *TransferApproval `json:"approval,omitempty"`
 }
 
 type WithDrawalRequest struct {
-	AccountID string  `json:"account_id"`
-	Amount    float64 `json:"amount"`
+	UserID      string  `json:"-"`
+	AccountID   string  `json:"account_id"`
+	Amount      float64 `json:"amount"`
+	Description string  `json:"description"`
+	Reference   string  `json:"reference"`
+	// ChallengeID is the confirmed fraud challenge of an earlier attempt at the same withdrawal
+	ChallengeID string `json:"challenge_id"`
 }
 
 type WithDrawalResponse struct {
@@ -20,8 +38,10 @@ type WithDrawalResponse struct {
 }
 
 type DepositRequest struct {
-	AccountID string  `json:"account_id"`
-	Amount    float64 `json:"amount"`
+	AccountID   string  `json:"account_id"`
+	Amount      float64 `json:"amount"`
+	Description string  `json:"description"`
+	Reference   string  `json:"reference"`
 }
 
 type DepositResponse struct {
@@ -37,3 +57,36 @@ type ConfirmPaymentRequest struct {
 	Phone string `json:"phone"`
 	Code  string `json:"code"`
 }
+
+type PhoneLookupRequest struct {
+	UserID string `json:"-"`
+	Phone  string `json:"phone"`
+}
+
+type PhoneLookupResponse struct {
+	MaskedName        string `json:"masked_name"`
+	ConfirmationToken string `json:"confirmation_token"`
+	ExpiresAt         string `json:"expires_at"`
+}
+
+type PhoneTransferRequest struct {
+	UserID            string  `json:"-"`
+	FromAccountID     string  `json:"from_account_id"`
+	ConfirmationToken string  `json:"confirmation_token"`
+	Amount            float64 `json:"amount"`
+	Description       string  `json:"description"`
+	Reference         string  `json:"reference"`
+	ChallengeID       string  `json:"challenge_id"`
+}
+
+// PostTransferRequest is a settled transfer booked in the caller's transaction, the amount is an exact decimal
+type PostTransferRequest struct {
+	FromAccountID string `json:"from_account_id"`
+	ToAccountID   string `json:"to_account_id"`
+	Amount        string `json:"amount"`
+	Description   string `json:"description"`
+	Reference     string `json:"reference"`
+	// CustomerInitiated is set when a customer asks for the posting, such as moving money to a pot,
+	// it is then screened like a transfer. The bank's own postings, interest or maturities, are not.
+	CustomerInitiated bool `json:"-"`
+}
@@ -0,0 +1,90 @@
+package models
+
+const (
+	PaymentRequestStatusPending   = "pending"
+	PaymentRequestStatusAccepted  = "accepted"
+	PaymentRequestStatusDeclined  = "declined"
+	PaymentRequestStatusCancelled = "cancelled"
+	PaymentRequestStatusExpired   = "expired"
+)
+
+type PaymentRequest struct {
+	ID                 string                   `json:"id"`
+	RequesterID        string                   `json:"requester_id"`
+	RequesterAccountID string                   `json:"requester_account_id"`
+	PayerID            string                   `json:"payer_id"`
+	PayerAccountID     string                   `json:"payer_account_id"`
+	Amount             float64                  `json:"amount"`
+	Memo               string                   `json:"memo"`
+	Status             string                   `json:"status"`
+	TransactionID      string                   `json:"transaction_id"`
+	ExpiresAt          string                   `json:"expires_at"`
+	CreatedAt          string                   `json:"created_at"`
+	UpdatedAt          string                   `json:"updated_at"`
+	History            []*PaymentRequestHistory `json:"history,omitempty"`
+}
+
+type PaymentRequestHistory struct {
+	ID         string `json:"id"`
+	FromStatus string `json:"from_status"`
+	ToStatus   string `json:"to_status"`
+	ActorID    string `json:"actor_id"`
+	Note       string `json:"note"`
+	CreatedAt  string `json:"created_at"`
+}
+
+type CreatePaymentRequestRequest struct {
+	RequesterID string `json:"-"`
+	// AccountID is the requester's account that receives the money
+	AccountID string  `json:"account_id"`
+	ToPhone   string  `json:"to_phone"`
+	ToAccount string  `json:"to_account_id"`
+	Amount    float64 `json:"amount"`
+	Memo      string  `json:"memo"`
+	ExpiresAt string  `json:"expires_at"`
+}
+
+type GetPaymentRequestsRequest struct {
+	RequesterID string `json:"requester_id"`
+	PayerID     string `json:"payer_id"`
+	Status      string `json:"status"`
+	Limit       int    `json:"limit"`
+	Offset      int    `json:"offset"`
+}
+
+type GetPaymentRequestsResponse struct {
+	PaymentRequests []*PaymentRequest `json:"payment_requests"`
+	Count           int               `json:"count"`
+}
+
+type GetPaymentRequestByIDRequest struct {
+	ID     string `json:"id"`
+	UserID string `json:"-"`
+}
+
+type AcceptPaymentRequestRequest struct {
+	ID            string `json:"-"`
+	UserID        string `json:"-"`
+	FromAccountID string `json:"from_account_id"`
+	// ChallengeID is the confirmed fraud challenge of an earlier attempt to pay the request
+	ChallengeID string `json:"challenge_id"`
+}
+
+type AcceptPaymentRequestResponse struct {
+	PaymentRequest *PaymentRequest   `json:"payment_request"`
+	Transfer       *TransferResponse `json:"transfer"`
+}
+
+type DeclinePaymentRequestRequest struct {
+	ID     string `json:"-"`
+	UserID string `json:"-"`
+}
+
+type UpdatePaymentRequestStatusRequest struct {
+	ID            string `json:"id"`
+	FromStatus    string `json:"from_status"`
+	ToStatus      string `json:"to_status"`
+	ActorID       string `json:"actor_id"`
+	Note          string `json:"note"`
+	TransactionID string `json:"transaction_id"`
+}
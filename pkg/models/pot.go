@@ -0,0 +1,95 @@
+package models
+
+const (
+	ProductPot = "pot"
+
+	PotStatusActive = "active"
+	PotStatusClosed = "closed"
+
+	AuditEventPotCreated = "pot_created"
+	AuditEventPotUpdated = "pot_updated"
+	AuditEventPotMoved   = "pot_moved"
+	AuditEventPotClosed  = "pot_closed"
+	AuditResourcePot     = "pot"
+)
+
+// Pot is money set aside from an account towards a goal. Decimal values are strings to keep them exact.
+type Pot struct {
+	ID           string `json:"guid"`
+	UserID       string `json:"user_id"`
+	AccountID    string `json:"account_id"`
+	PotAccountID string `json:"pot_account_id"`
+	Name         string `json:"name"`
+	TargetAmount string `json:"target_amount,omitempty"`
+	TargetDate   string `json:"target_date,omitempty"`
+	RoundUpTo    string `json:"round_up_to,omitempty"`
+	Status       string `json:"status"`
+	Balance      string `json:"balance"`
+	// Progress is only calculated for a pot with a target amount
+	Progress  *PotProgress `json:"progress,omitempty"`
+	ClosedAt  string       `json:"closed_at,omitempty"`
+	CreatedAt string       `json:"created_at"`
+	UpdatedAt string       `json:"updated_at"`
+}
+
+type PotProgress struct {
+	// Percent is the share of the target saved, it goes past 100 when the pot is over its target
+	Percent   string `json:"percent"`
+	Remaining string `json:"remaining"`
+	// DaysLeft and MonthlyNeeded are only calculated for a pot with a target date
+	DaysLeft int `json:"days_left,omitempty"`
+	// MonthlyNeeded is what has to be put aside every month to reach the target on time
+	MonthlyNeeded string `json:"monthly_needed,omitempty"`
+}
+
+type CreatePotRequest struct {
+	UserID       string  `json:"-"`
+	AccountID    string  `json:"-"`
+	Name         string  `json:"name"`
+	TargetAmount float64 `json:"target_amount"`
+	// TargetDate is YYYY-MM-DD
+	TargetDate string `json:"target_date"`
+	// RoundUpTo is 1, 5 or 10, zero turns the round-ups off
+	RoundUpTo float64 `json:"round_up_to"`
+}
+
+type UpdatePotRequest struct {
+	ID           string  `json:"-"`
+	UserID       string  `json:"-"`
+	AccountID    string  `json:"-"`
+	Name         string  `json:"name"`
+	TargetAmount float64 `json:"target_amount"`
+	TargetDate   string  `json:"target_date"`
+	RoundUpTo    float64 `json:"round_up_to"`
+}
+
+type PotByIDRequest struct {
+	ID        string `json:"-"`
+	UserID    string `json:"-"`
+	AccountID string `json:"-"`
+}
+
+type GetPotsRequest struct {
+	UserID    string `json:"-"`
+	AccountID string `json:"-"`
+	// Closed includes the closed pots
+	Closed bool `json:"closed"`
+}
+
+type GetPotsResponse struct {
+	Pots []*Pot `json:"pots"`
+	// Saved is the total balance of the active pots
+	Saved string `json:"saved"`
+}
+
+type PotMoveRequest struct {
+	ID        string  `json:"-"`
+	UserID    string  `json:"-"`
+	AccountID string  `json:"-"`
+	Amount    float64 `json:"amount"`
+}
+
+type PotMoveResponse struct {
+	Pot          *Pot           `json:"pot"`
+	Transactions []*Transaction `json:"transactions"`
+}
@@ -0,0 +1,99 @@
+package models
+
+const (
+	// SanctionsSourceRegistration hits come from the name a user registered with, SanctionsSourceProfile
+	// ones from a changed name and SanctionsSourceBeneficiary ones from the owner of an account
+	// saved as a beneficiary
+	SanctionsSourceRegistration = "registration"
+	SanctionsSourceProfile      = "profile"
+	SanctionsSourceBeneficiary  = "beneficiary"
+
+	// SanctionsHitPending and SanctionsHitConfirmed hits block the payments of the user,
+	// SanctionsHitDismissed ones were found to be someone else
+	SanctionsHitPending   = "pending"
+	SanctionsHitConfirmed = "confirmed"
+	SanctionsHitDismissed = "dismissed"
+
+	AuditEventSanctionsHitReviewed  = "sanctions_hit_reviewed"
+	AuditEventSanctionsListReloaded = "sanctions_list_reloaded"
+	AuditResourceSanctionsHit       = "sanctions_hit"
+	AuditResourceSanctionsList      = "sanctions_list"
+)
+
+// SanctionsEntry is a listed person or entity with the names it is known by
+type SanctionsEntry struct {
+	UID      string   `json:"uid"`
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`
+	Programs []string `json:"programs"`
+	Aliases  []string `json:"aliases"`
+}
+
+// SanctionsListInfo describes the list in force
+type SanctionsListInfo struct {
+	File      string  `json:"file"`
+	Entries   int     `json:"entries"`
+	Names     int     `json:"names"`
+	Threshold float64 `json:"threshold"`
+	LoadedAt  string  `json:"loaded_at,omitempty"`
+}
+
+// SanctionsMatch is an entry whose name is close enough to the screened one
+type SanctionsMatch struct {
+	EntryUID    string   `json:"entry_uid"`
+	EntryName   string   `json:"entry_name"`
+	MatchedName string   `json:"matched_name"`
+	Programs    []string `json:"programs"`
+	Score       float64  `json:"score"`
+}
+
+// SanctionsHit is a stored match of a user against the list, waiting for or past review
+type SanctionsHit struct {
+	ID            string   `json:"id"`
+	UserID        string   `json:"user_id"`
+	Source        string   `json:"source"`
+	RequestedBy   string   `json:"requested_by,omitempty"`
+	ScreenedName  string   `json:"screened_name"`
+	EntryUID      string   `json:"entry_uid"`
+	EntryName     string   `json:"entry_name"`
+	MatchedName   string   `json:"matched_name"`
+	Programs      []string `json:"programs"`
+	Score         float64  `json:"score"`
+	Status        string   `json:"status"`
+	ReviewedBy    string   `json:"reviewed_by,omitempty"`
+	ReviewComment string   `json:"review_comment"`
+	ReviewedAt    string   `json:"reviewed_at,omitempty"`
+	CreatedAt     string   `json:"created_at"`
+}
+
+// ScreenUserRequest screens the name of a user, the current one when Name is empty. RequestedBy is
+// the user whose action led to it when that is someone else: the one saving the user's account
+// as a beneficiary.
+type ScreenUserRequest struct {
+	UserID      string
+	Name        string
+	Source      string
+	RequestedBy string
+}
+
+type GetSanctionsHitsRequest struct {
+	UserID string `json:"user_id"`
+	Status string `json:"status"`
+	Source string `json:"source"`
+	Limit  int    `json:"limit"`
+	Offset int    `json:"offset"`
+}
+
+type GetSanctionsHitsResponse struct {
+	Hits  []*SanctionsHit `json:"hits"`
+	Count int             `json:"count"`
+}
+
+type ReviewSanctionsHitRequest struct {
+	ID      string `json:"-"`
+	ActorID string `json:"-"`
+	IP      string `json:"-"`
+	// Status is confirmed or dismissed
+	Status  string `json:"status"`
+	Comment string `json:"comment"`
+}
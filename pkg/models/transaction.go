@@ -1,14 +1,16 @@
 package models
 
 type Transaction struct {
-	ID           string  `json:"id"`
-	AccountID    string  `json:"account_id"`
-	RecipientID  string  `json:"recipient_id"`
-	Amount       float64 `json:"amount"`
-	Type         string  `json:"type"`
-	CreatedAt    string  `json:"created_at"`
-	Approved     bool    `json:"approved"`
-	Done         bool    `json:"done"`
+	ID            string  `json:"id"`
+	AccountID     string  `json:"account_id"`
+	RecipientID   string  `json:"recipient_id"`
+	Amount        float64 `json:"amount"`
+	Type          string  `json:"type"`
+	Description   string  `json:"description"`
+	Reference     string  `json:"reference"`
+	CreatedAt     string  `json:"created_at"`
+	Approved      bool    `json:"approved"`
+	Done          bool    `json:"done"`
 	DoneTimestamp string  `json:"done_timestamp"`
 }
 
@@ -21,11 +23,18 @@ type GetTransactionsByAccountIDRequest struct {
 	To          string `json:"to"`
 	RecipientID string `json:"recipient_id"`
 	AccountID   string `json:"account_id"`
+	// Search matches the description text or the exact reference
+	Search string `json:"search"`
+	// Cursor switches the listing to keyset pagination, Offset is ignored then
+	Cursor string `json:"cursor"`
 }
 
 type GetTransactionsByAccountIDResponse struct {
 	Transactions []*Transaction `json:"transactions"`
-	Count        int            `json:"count"`
+	// Count is only calculated in offset mode
+	Count      int    `json:"count"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
 }
 
 type GetTransactionByIDRequest struct {
@@ -48,3 +57,44 @@ type GetTransactionsByIDSRequest struct {
 type GetTransactionsByIDSResponse struct {
 	Transactions []*Transaction `json:"transactions"`
 }
+
+type GetTransferredTotalRequest struct {
+	UserID      string `json:"user_id"`
+	RecipientID string `json:"recipient_id"`
+	From        string `json:"from"`
+}
+
+const (
+	TransactionStatusPending   = "pending"
+	TransactionStatusCompleted = "completed"
+)
+
+type SearchTransactionsRequest struct {
+	UserID         string  `json:"-"`
+	Query          string  `json:"q"`
+	Type           string  `json:"type"`
+	Status         string  `json:"status"`
+	MinAmount      float64 `json:"min_amount"`
+	MaxAmount      float64 `json:"max_amount"`
+	CounterpartyID string  `json:"counterparty_id"`
+	From           string  `json:"from"`
+	To             string  `json:"to"`
+	Limit          int     `json:"limit"`
+	Offset         int     `json:"offset"`
+}
+
+type FacetCount struct {
+	Key   string `json:"key"`
+	Count int    `json:"count"`
+}
+
+type TransactionFacets struct {
+	ByType  []*FacetCount `json:"by_type"`
+	ByMonth []*FacetCount `json:"by_month"`
+}
+
+type SearchTransactionsResponse struct {
+	Transactions []*Transaction     `json:"transactions"`
+	Count        int                `json:"count"`
+	Facets       *TransactionFacets `json:"facets"`
+}
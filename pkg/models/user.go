@@ -1,5 +1,19 @@
 package models
 
+const (
+	// OTPPurposePhoneChange codes confirm a new phone number
+	OTPPurposePhoneChange = "phone_change"
+	// OTPPurposePasswordReset codes let a user who forgot the password set a new one
+	OTPPurposePasswordReset = "password_reset"
+
+	// the password events carry no before and after, hashes are kept out of the audit log
+	AuditEventPasswordChanged          = "password_changed"
+	AuditEventPasswordReset            = "password_reset"
+	AuditEventPhoneChanged             = "phone_changed"
+	AuditEventTwoFactorChanged         = "two_factor_changed"
+	AuditEventRecoveryCodesRegenerated = "recovery_codes_regenerated"
+)
+
 type User struct {
 	Guid      string `json:"guid"`
 	Phone     string `json:"phone"`
@@ -8,6 +22,11 @@ type User struct {
 	LastName  string `json:"last_name"`
 	CreatedAt string `json:"created_at"`
 	UpdatedAt string `json:"updated_at"`
+	Role      string `json:"role"`
+	// TwoFactorEnabled is set once the user confirmed an authenticator app
+	TwoFactorEnabled bool `json:"two_factor_enabled"`
+	// SessionsRevokedAt is the unix time tokens have to be issued after
+	SessionsRevokedAt int64 `json:"-"`
 }
 
 type GetUserByIDRequest struct {
@@ -22,3 +41,140 @@ type GetUserByIDResponse struct {
 type CreateUserRequest struct {
 	User *User `json:"user"`
 }
+
+type SetDefaultAccountRequest struct {
+	UserID    string `json:"-"`
+	AccountID string `json:"account_id"`
+}
+
+type PhoneRecipient struct {
+	UserID    string `json:"user_id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	AccountID string `json:"account_id"`
+}
+
+// UpdateProfileRequest changes only the names that are given
+type UpdateProfileRequest struct {
+	UserID    string  `json:"-"`
+	FirstName *string `json:"first_name"`
+	LastName  *string `json:"last_name"`
+}
+
+type ChangePasswordRequest struct {
+	UserID      string `json:"-"`
+	Phone       string `json:"-"`
+	OldPassword string `json:"old_password"`
+	NewPassword string `json:"new_password"`
+}
+
+// ChangePhoneRequest asks for a code to be sent to the new phone number
+type ChangePhoneRequest struct {
+	UserID string `json:"-"`
+	Phone  string `json:"phone"`
+}
+
+type ChangePhoneResponse struct {
+	Phone     string `json:"phone"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+// ConfirmPhoneRequest moves the user to the phone number the code was sent to
+type ConfirmPhoneRequest struct {
+	UserID string `json:"-"`
+	Phone  string `json:"-"`
+	Code   string `json:"code"`
+}
+
+type DeleteUserRequest struct {
+	UserID   string `json:"-"`
+	Phone    string `json:"-"`
+	Password string `json:"password"`
+}
+
+// OTPCode is a one-time code sent by SMS, only its hash is stored
+type OTPCode struct {
+	ID        string
+	UserID    string
+	Purpose   string
+	Target    string
+	CodeHash  string
+	Attempts  int
+	ExpiresAt string
+	UsedAt    string
+	CreatedAt string
+}
+
+type ForgotPasswordRequest struct {
+	Phone string `json:"phone"`
+}
+
+type ResetPasswordRequest struct {
+	Phone       string `json:"phone"`
+	Code        string `json:"code"`
+	NewPassword string `json:"new_password"`
+}
+
+// TOTPSettings is the user's authenticator app setup
+type TOTPSettings struct {
+	UserID    string
+	Secret    string
+	EnabledAt string
+	// LastStep is the step of the last accepted code
+	LastStep int64
+}
+
+type EnrollTOTPRequest struct {
+	UserID   string `json:"-"`
+	Phone    string `json:"-"`
+	Password string `json:"password"`
+}
+
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	// URI is the otpauth:// link to show as a QR code
+	URI string `json:"uri"`
+}
+
+type ConfirmTOTPRequest struct {
+	UserID string `json:"-"`
+	Code   string `json:"code"`
+}
+
+type RecoveryCodesResponse struct {
+	// Codes are shown once, each one can be used instead of an authenticator code a single time
+	Codes []string `json:"codes"`
+}
+
+type DisableTOTPRequest struct {
+	UserID   string `json:"-"`
+	Phone    string `json:"-"`
+	Password string `json:"password"`
+	// Code is an authenticator or a recovery code
+	Code string `json:"code"`
+}
+
+// MFAChallenge is the login answer for a user with 2FA, the token is exchanged for the real ones with a code
+type MFAChallenge struct {
+	MFARequired bool   `json:"mfa_required"`
+	MFAToken    string `json:"mfa_token"`
+	ExpiresAt   string `json:"expires_at"`
+}
+
+type MFALoginRequest struct {
+	MFAToken string `json:"mfa_token"`
+	// Code is an authenticator or a recovery code
+	Code string `json:"code"`
+}
+
+type StepUpRequest struct {
+	UserID string `json:"-"`
+	// Code is an authenticator or a recovery code
+	Code string `json:"code"`
+}
+
+// StepUpResponse carries the token sensitive operations take in the X-Step-Up-Token header
+type StepUpResponse struct {
+	StepUpToken string `json:"step_up_token"`
+	ExpiresAt   string `json:"expires_at"`
+}
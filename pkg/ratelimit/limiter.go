@@ -0,0 +1,58 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Limiter is an in-memory fixed window rate limiter keyed by an arbitrary string
+type Limiter struct {
+	mu      sync.Mutex
+	limit   int
+	window  time.Duration
+	windows map[string]*window
+}
+
+type window struct {
+	start time.Time
+	hits  int
+}
+
+// New returns a limiter that allows at most limit hits per key in every window
+func New(limit int, period time.Duration) *Limiter {
+	return &Limiter{
+		limit:   limit,
+		window:  period,
+		windows: make(map[string]*window),
+	}
+}
+
+// Allow registers a hit for the given key and reports whether it is within the limit
+func (l *Limiter) Allow(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	w, ok := l.windows[key]
+	if !ok || now.Sub(w.start) >= l.window {
+		l.cleanup(now)
+		l.windows[key] = &window{start: now, hits: 1}
+		return true
+	}
+
+	if w.hits >= l.limit {
+		return false
+	}
+	w.hits++
+
+	return true
+}
+
+// cleanup drops expired windows so the map does not grow without bound
+func (l *Limiter) cleanup(now time.Time) {
+	for k, w := range l.windows {
+		if now.Sub(w.start) >= l.window {
+			delete(l.windows, k)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+// Package sanctions loads a watchlist in the OFAC SDN format and screens names against it
+package sanctions
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/dilmurodov/online_banking/pkg/models"
+)
+
+// sdnEmpty is how the SDN CSV files mark a field without a value
+const sdnEmpty = "-0-"
+
+// akaPattern finds the aliases listed in the remarks column of sdn.csv
+var akaPattern = regexp.MustCompile(`(?i)a\.k\.a\.,?\s*'([^']+)'`)
+
+// LoadFile reads a list in the SDN XML format when the file ends in .xml, in the SDN CSV format otherwise
+func LoadFile(path string) ([]*models.SanctionsEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".xml") {
+		return ParseXML(f)
+	}
+	return ParseCSV(f)
+}
+
+// ParseCSV reads sdn.csv: ent_num, SDN_Name, SDN_Type, Program, Title, Call_Sign, Vess_type,
+// Tonnage, GRT, Vess_flag, Vess_owner, Remarks. The file has no header, a header row is skipped
+// when there is one.
+func ParseCSV(r io.Reader) ([]*models.SanctionsEntry, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	entries := make([]*models.SanctionsEntry, 0)
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		// the file ends with a control character on a line of its own
+		if len(record) < 2 || strings.EqualFold(record[0], "ent_num") {
+			continue
+		}
+
+		entry := &models.SanctionsEntry{
+			UID:  sdnValue(record[0]),
+			Name: sdnValue(record[1]),
+		}
+		if entry.UID == "" || entry.Name == "" {
+			return nil, fmt.Errorf("line %d: ent_num and SDN_Name are required", line)
+		}
+		if len(record) > 2 {
+			entry.Type = sdnValue(record[2])
+		}
+		if len(record) > 3 {
+			entry.Programs = splitPrograms(sdnValue(record[3]))
+		}
+		if len(record) > 11 {
+			for _, m := range akaPattern.FindAllStringSubmatch(record[11], -1) {
+				entry.Aliases = append(entry.Aliases, strings.TrimSpace(m[1]))
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+type sdnList struct {
+	Entries []sdnEntry `xml:"sdnEntry"`
+}
+
+type sdnEntry struct {
+	UID       string   `xml:"uid"`
+	FirstName string   `xml:"firstName"`
+	LastName  string   `xml:"lastName"`
+	Type      string   `xml:"sdnType"`
+	Programs  []string `xml:"programList>program"`
+	Akas      []sdnAka `xml:"akaList>aka"`
+}
+
+type sdnAka struct {
+	FirstName string `xml:"firstName"`
+	LastName  string `xml:"lastName"`
+}
+
+// ParseXML reads sdn.xml, the names of individuals are put together as "first last"
+func ParseXML(r io.Reader) ([]*models.SanctionsEntry, error) {
+	var list sdnList
+	if err := xml.NewDecoder(r).Decode(&list); err != nil {
+		return nil, err
+	}
+
+	entries := make([]*models.SanctionsEntry, 0, len(list.Entries))
+	for i, e := range list.Entries {
+		entry := &models.SanctionsEntry{
+			UID:      strings.TrimSpace(e.UID),
+			Name:     joinName(e.FirstName, e.LastName),
+			Type:     strings.TrimSpace(e.Type),
+			Programs: make([]string, 0, len(e.Programs)),
+		}
+		if entry.UID == "" || entry.Name == "" {
+			return nil, fmt.Errorf("sdnEntry %d: uid and lastName are required", i+1)
+		}
+		for _, p := range e.Programs {
+			if p = strings.TrimSpace(p); p != "" {
+				entry.Programs = append(entry.Programs, p)
+			}
+		}
+		for _, aka := range e.Akas {
+			if name := joinName(aka.FirstName, aka.LastName); name != "" {
+				entry.Aliases = append(entry.Aliases, name)
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func sdnValue(s string) string {
+	s = strings.TrimSpace(s)
+	if s == sdnEmpty {
+		return ""
+	}
+	return s
+}
+
+// splitPrograms splits a program field like "SDGT] [IRGC"
+func splitPrograms(s string) []string {
+	programs := make([]string, 0)
+	for _, p := range strings.Split(s, "] [") {
+		if p = strings.Trim(p, "[] "); p != "" {
+			programs = append(programs, p)
+		}
+	}
+	return programs
+}
+
+func joinName(first, last string) string {
+	return strings.TrimSpace(strings.TrimSpace(first) + " " + strings.TrimSpace(last))
+}
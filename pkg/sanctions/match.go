@@ -0,0 +1,186 @@
+package sanctions
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// cyrillic spells Russian and Uzbek Cyrillic letters in Latin the way passports usually do
+var cyrillic = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya", 'ў': "o", 'қ': "q", 'ғ': "g", 'ҳ': "h", 'і': "i", 'ї': "yi", 'є': "ye",
+	'ґ': "g",
+}
+
+// spellings folds the Latin spellings that different transliterations give the same sound
+var spellings = strings.NewReplacer(
+	"dzh", "j",
+	"dj", "j",
+	"zh", "j",
+	"kh", "h",
+	"ph", "f",
+	"w", "v",
+	"y", "i",
+)
+
+// Normalize lowercases the name, spells Cyrillic in Latin, drops diacritics and punctuation and
+// folds spelling variants, so "Иванов, Иван" and "IVANOV Ivan" come out the same
+func Normalize(name string) string {
+	var latin strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.Is(unicode.Cyrillic, r) {
+			latin.WriteString(cyrillic[r])
+			continue
+		}
+		latin.WriteRune(r)
+	}
+
+	var b strings.Builder
+	for _, r := range norm.NFD.String(latin.String()) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// combining marks left over from decomposed letters: é, ü, ş
+		case r == '\'' || r == 'ʻ' || r == 'ʼ' || r == '‘' || r == '’':
+			// the apostrophes of Uzbek Latin: o'g'li. Checked before letters, ʻ and ʼ are modifier letters
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		default:
+			b.WriteRune(' ')
+		}
+	}
+	return spellings.Replace(strings.Join(strings.Fields(b.String()), " "))
+}
+
+// Score compares two normalized names from 0 to 1. It takes the best of the names as they are,
+// with their words sorted, and word by word when one name is a part of the other, so
+// "ivan ivanov" matches "ivanov ivan petrovich".
+func Score(a, b string) float64 {
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+
+	aw, bw := strings.Fields(a), strings.Fields(b)
+	score := JaroWinkler(a, b)
+	if s := JaroWinkler(sortedWords(aw), sortedWords(bw)); s > score {
+		score = s
+	}
+	if s := wordScore(aw, bw); s > score {
+		score = s
+	}
+	return score
+}
+
+// wordScore pairs every word of the shorter name with its best match among the words of the
+// longer one, each word used once, and averages the scores. Single words are left to the
+// whole name comparison, a first name alone must not match every entry that has it.
+func wordScore(a, b []string) float64 {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	if len(a) < 2 {
+		return 0
+	}
+
+	used := make([]bool, len(b))
+	total := 0.0
+	for _, w := range a {
+		best, bestIdx := 0.0, -1
+		for i, v := range b {
+			if used[i] {
+				continue
+			}
+			if s := JaroWinkler(w, v); s > best {
+				best, bestIdx = s, i
+			}
+		}
+		if bestIdx < 0 {
+			return 0
+		}
+		used[bestIdx] = true
+		total += best
+	}
+	return total / float64(len(a))
+}
+
+func sortedWords(words []string) string {
+	sorted := append([]string(nil), words...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, " ")
+}
+
+// JaroWinkler is the Jaro similarity of the two strings raised for a common prefix of up to
+// four characters
+func JaroWinkler(a, b string) float64 {
+	s1, s2 := []rune(a), []rune(b)
+	if len(s1) == 0 || len(s2) == 0 {
+		return 0
+	}
+
+	window := max(len(s1), len(s2))/2 - 1
+	if window < 0 {
+		window = 0
+	}
+
+	m1 := make([]bool, len(s1))
+	m2 := make([]bool, len(s2))
+	matches := 0
+	for i := range s1 {
+		lo, hi := max(0, i-window), min(len(s2), i+window+1)
+		for j := lo; j < hi; j++ {
+			if !m2[j] && s1[i] == s2[j] {
+				m1[i], m2[j] = true, true
+				matches++
+				break
+			}
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions, j := 0, 0
+	for i := range s1 {
+		if !m1[i] {
+			continue
+		}
+		for !m2[j] {
+			j++
+		}
+		if s1[i] != s2[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(s1)) + m/float64(len(s2)) + (m-float64(transpositions)/2)/m) / 3
+
+	prefix := 0
+	for prefix < min(4, min(len(s1), len(s2))) && s1[prefix] == s2[prefix] {
+		prefix++
+	}
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,94 @@
+package sanctions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJaroWinkler(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want float64
+	}{
+		{"EQUAL", "ivanov", "ivanov", 1},
+		{"TRANSPOSITION", "martha", "marhta", 0.961111},
+		{"ONE_LETTER_PREFIX", "dwayne", "duane", 0.84},
+		{"LONGER", "dixon", "dicksonx", 0.813333},
+		{"NOTHING_IN_COMMON", "abc", "xyz", 0},
+		{"EMPTY", "", "ivanov", 0},
+		{"SINGLE_LETTER", "a", "a", 1},
+		{"CYRILLIC_RUNES", "иван", "иван", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.InDelta(t, tt.want, JaroWinkler(tt.a, tt.b), 0.000001)
+			require.InDelta(t, tt.want, JaroWinkler(tt.b, tt.a), 0.000001)
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"LATIN", "IVANOV, Ivan", "ivanov ivan"},
+		{"CYRILLIC", "Иванов, Иван", "ivanov ivan"},
+		{"SPACES", "  Ivan \t Ivanov ", "ivan ivanov"},
+		{"ZH", "Жуков", "jukov"},
+		{"ZH_LATIN", "Zhukov", "jukov"},
+		{"DZH", "Джамшид", "jamshid"},
+		{"DJ", "Djamshid", "jamshid"},
+		{"KH", "Хамидов", "hamidov"},
+		{"YU", "Юсупов", "iusupov"},
+		{"YU_LATIN", "Yusupov", "iusupov"},
+		{"YO", "Ёркин", "iorkin"},
+		{"SOFT_SIGN", "Игорь", "igor"},
+		{"SHCH", "Щукин", "shchukin"},
+		{"UZBEK_CYRILLIC", "Ўктам Қодиров", "oktam qodirov"},
+		{"UZBEK_LATIN_APOSTROPHES", "Karimov O'g'li", "karimov ogli"},
+		{"UZBEK_LATIN_TURNED_COMMA", "Karimov Oʻgʻli", "karimov ogli"},
+		{"DIACRITICS", "Müller Şahin", "muller sahin"},
+		{"HYPHEN", "Al-Rashid", "al rashid"},
+		{"DIGITS", "Vessel 7", "vessel 7"},
+		{"PH_W", "Stephan Wagner", "stefan vagner"},
+		{"EMPTY", " , ", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, Normalize(tt.in))
+		})
+	}
+}
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    string
+		atLeast float64
+		below   float64
+	}{
+		{"EQUAL", "Ivan Ivanov", "IVANOV, Ivan", 1, 0},
+		{"CYRILLIC_OTHER_ORDER", "Иван Иванов", "IVANOV, Ivan Petrovich", 0.92, 0},
+		{"ALIAS_SPELLING", "Ivan Ivanoff", "IVANOFF, Ivan", 1, 0},
+		{"TRANSLITERATION", "Джамшид Хамидов", "Djamshid Khamidov", 1, 0},
+		{"TYPO", "Gulnara Karimova", "Gulnara Karimowa", 1, 0},
+		{"FIRST_NAME_ONLY", "Ivan", "Ivan Ivanov", 0, 0.92},
+		{"OTHER_PERSON", "Aziz Rakhimov", "Ivan Ivanov", 0, 0.92},
+		{"EMPTY", "", "Ivan Ivanov", 0, 0.000001},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score := Score(Normalize(tt.a), Normalize(tt.b))
+			if tt.atLeast > 0 {
+				require.GreaterOrEqual(t, score, tt.atLeast)
+			}
+			if tt.below > 0 {
+				require.Less(t, score, tt.below)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package sanctions
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dilmurodov/online_banking/pkg/models"
+)
+
+// DefaultThreshold is the least score a name has to reach to be reported as a match
+const DefaultThreshold = 0.92
+
+type indexedEntry struct {
+	entry *models.SanctionsEntry
+	// names are the normalized primary name and aliases, original the same names as listed
+	names    []string
+	original []string
+}
+
+type list struct {
+	entries  []*indexedEntry
+	names    int
+	loadedAt time.Time
+}
+
+// Screener holds the list in force, it can be swapped while names are being screened
+type Screener struct {
+	path      string
+	threshold float64
+
+	mu   sync.RWMutex
+	list *list
+}
+
+// NewScreener returns a screener with an empty list, Reload reads the list file into it
+func NewScreener(path string, threshold float64) *Screener {
+	if threshold <= 0 || threshold > 1 {
+		threshold = DefaultThreshold
+	}
+	return &Screener{path: path, threshold: threshold, list: &list{}}
+}
+
+// Reload reads the list file again. The list in force is kept when the file can't be read,
+// is not valid or has no entries, so a bad upload does not switch the screening off.
+func (s *Screener) Reload() (*models.SanctionsListInfo, error) {
+	if s.path == "" {
+		return s.Info(), nil
+	}
+
+	entries, err := LoadFile(s.path)
+	if err != nil {
+		return s.Info(), err
+	}
+	if len(entries) == 0 {
+		return s.Info(), fmt.Errorf("%s has no entries", s.path)
+	}
+
+	l := &list{entries: make([]*indexedEntry, 0, len(entries)), loadedAt: time.Now().UTC()}
+	for _, e := range entries {
+		ie := &indexedEntry{entry: e}
+		for _, name := range append([]string{e.Name}, e.Aliases...) {
+			if n := Normalize(name); n != "" {
+				ie.names = append(ie.names, n)
+				ie.original = append(ie.original, name)
+			}
+		}
+		if len(ie.names) > 0 {
+			l.entries = append(l.entries, ie)
+			l.names += len(ie.names)
+		}
+	}
+
+	s.mu.Lock()
+	s.list = l
+	s.mu.Unlock()
+
+	return s.Info(), nil
+}
+
+// Info describes the list in force
+func (s *Screener) Info() *models.SanctionsListInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	info := &models.SanctionsListInfo{
+		File:      s.path,
+		Entries:   len(s.list.entries),
+		Names:     s.list.names,
+		Threshold: s.threshold,
+	}
+	if !s.list.loadedAt.IsZero() {
+		info.LoadedAt = s.list.loadedAt.Format(time.RFC3339)
+	}
+	return info
+}
+
+// Screen returns the entries one of whose names scores at least the threshold against the name,
+// best match first
+func (s *Screener) Screen(name string) []*models.SanctionsMatch {
+	matches := make([]*models.SanctionsMatch, 0)
+
+	screened := Normalize(name)
+	if screened == "" {
+		return matches
+	}
+
+	s.mu.RLock()
+	l := s.list
+	s.mu.RUnlock()
+
+	for _, ie := range l.entries {
+		var best *models.SanctionsMatch
+		for i, n := range ie.names {
+			score := Score(screened, n)
+			if score < s.threshold || (best != nil && score <= best.Score) {
+				continue
+			}
+			best = &models.SanctionsMatch{
+				EntryUID:    ie.entry.UID,
+				EntryName:   ie.entry.Name,
+				MatchedName: ie.original[i],
+				Programs:    ie.entry.Programs,
+				Score:       score,
+			}
+		}
+		if best != nil {
+			matches = append(matches, best)
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+	return matches
+}
@@ -27,55 +27,114 @@ const (
 	A2IDsaltLen = 16
 )
 
-// HashPassword is used to generate a new password hash for storing and comparing at a later date.
+// Params are the Argon2id costs a hash is made with, they are written into the hash
+type Params struct {
+	// Memory is in KiB
+	Memory  uint32
+	Time    uint32
+	Threads uint8
+	KeyLen  uint32
+	SaltLen uint32
+}
+
+// DefaultParams are the costs used when none are configured
+var DefaultParams = Params{
+	Memory:  A2IDmemory,
+	Time:    A2IDtime,
+	Threads: A2IDthreads,
+	KeyLen:  A2IDkeyLen,
+	SaltLen: A2IDsaltLen,
+}
+
+// HashPassword is used to generate a new password hash with the default costs.
 func HashPassword(password string) (hashedPassword string, err error) {
+	return HashPasswordWithParams(password, DefaultParams)
+}
+
+// HashPasswordWithParams generates a PHC string such as $argon2id$v=19$m=65536,t=3,p=4$<salt>$<hash>
+func HashPasswordWithParams(password string, p Params) (hashedPassword string, err error) {
 
 	// Generate a cryptographically secure random salt.
-	salt, err := GenerateRandomBytes(A2IDsaltLen)
+	salt, err := GenerateRandomBytes(int(p.SaltLen))
 	if err != nil {
 		return "", err
 	}
 
-	hash := argon2.IDKey([]byte(password), salt, A2IDtime, A2IDmemory, A2IDthreads, A2IDkeyLen)
+	hash := argon2.IDKey([]byte(password), salt, p.Time, p.Memory, p.Threads, p.KeyLen)
 
 	// Base64 encode the salt and hashed password.
 	b64Salt := base64.RawStdEncoding.EncodeToString(salt)
 	b64Hash := base64.RawStdEncoding.EncodeToString(hash)
 
-	format := "$argon2id$v=%d$models=%d,t=%d,p=%d$%s$%s"
-	hashedPassword = fmt.Sprintf(format, argon2.Version, A2IDmemory, A2IDtime, A2IDthreads, b64Salt, b64Hash)
+	format := "$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s"
+	hashedPassword = fmt.Sprintf(format, argon2.Version, p.Memory, p.Time, p.Threads, b64Salt, b64Hash)
 	return hashedPassword, nil
 }
 
 // ComparePassword is used to compare a user-inputted password to a hash to see if the password matches or not.
+// The hash is recomputed with the costs stored in it.
 func ComparePassword(hashedPassword, password string) (match bool, err error) {
-	parts := strings.Split(hashedPassword, "$")
+	p, salt, decodedHash, _, err := decodeHash(hashedPassword)
+	if err != nil {
+		return false, err
+	}
+
+	comparisonHash := argon2.IDKey([]byte(password), salt, p.Time, p.Memory, p.Threads, p.KeyLen)
+
+	return (subtle.ConstantTimeCompare(decodedHash, comparisonHash) == 1), nil
+}
 
-	if len(parts) <= 5 {
-		return false, errors.New("incorrectly hashed")
+// NeedsRehash reports whether the hash was made with other costs or in the old format,
+// the password should then be hashed again while it is at hand
+func NeedsRehash(hashedPassword string, p Params) bool {
+	stored, _, _, legacy, err := decodeHash(hashedPassword)
+	if err != nil || legacy {
+		return true
 	}
 
-	memory := A2IDmemory
-	time := A2IDtime
-	threads := A2IDthreads
+	return stored.Memory != p.Memory ||
+		stored.Time != p.Time ||
+		stored.Threads != p.Threads ||
+		stored.KeyLen != p.KeyLen ||
+		stored.SaltLen != p.SaltLen
+}
 
-	_, err = fmt.Sscanf(parts[3], "models=%d,t=%d,p=%d", &memory, &time, &threads)
-	if err != nil {
-		return false, err
+// decodeHash reads the PHC string. Hashes written before the format was fixed label the memory "models=",
+// they are still read and reported as legacy.
+func decodeHash(hashedPassword string) (p Params, salt, hash []byte, legacy bool, err error) {
+	parts := strings.Split(hashedPassword, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return p, nil, nil, false, errors.New("incorrectly hashed")
 	}
 
-	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
-	if err != nil {
-		return false, err
+	var version int
+	if _, err = fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return p, nil, nil, false, err
+	}
+	if version != argon2.Version {
+		return p, nil, nil, false, fmt.Errorf("unsupported argon2 version %d", version)
 	}
 
-	decodedHash, err := base64.RawStdEncoding.DecodeString(parts[5])
+	format := "m=%d,t=%d,p=%d"
+	if strings.HasPrefix(parts[3], "models=") {
+		format = "models=%d,t=%d,p=%d"
+		legacy = true
+	}
+	if _, err = fmt.Sscanf(parts[3], format, &p.Memory, &p.Time, &p.Threads); err != nil {
+		return p, nil, nil, false, err
+	}
+
+	salt, err = base64.RawStdEncoding.DecodeString(parts[4])
 	if err != nil {
-		return false, err
+		return p, nil, nil, false, err
 	}
-	keyLen := uint32(len(decodedHash))
+	p.SaltLen = uint32(len(salt))
 
-	comparisonHash := argon2.IDKey([]byte(password), salt, A2IDtime, A2IDmemory, A2IDthreads, keyLen)
+	hash, err = base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return p, nil, nil, false, err
+	}
+	p.KeyLen = uint32(len(hash))
 
-	return (subtle.ConstantTimeCompare(decodedHash, comparisonHash) == 1), nil
+	return p, salt, hash, legacy, nil
 }
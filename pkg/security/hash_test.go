@@ -0,0 +1,117 @@
+package security
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testParams keep the hashing cheap, the format doesn't depend on the costs
+var testParams = Params{Memory: 1024, Time: 1, Threads: 1, KeyLen: 16, SaltLen: 8}
+
+var phcFormat = regexp.MustCompile(`^\$argon2id\$v=19\$m=1024,t=1,p=1\$[A-Za-z0-9+/]{11}\$[A-Za-z0-9+/]{22}$`)
+
+func TestHashPasswordWithParams_RoundTrip(t *testing.T) {
+	r := require.New(t)
+
+	hashed, err := HashPasswordWithParams("correct horse", testParams)
+	r.NoError(err)
+	r.Regexp(phcFormat, hashed)
+
+	p, salt, hash, legacy, err := decodeHash(hashed)
+	r.NoError(err)
+	r.Equal(testParams, p)
+	r.Len(salt, int(testParams.SaltLen))
+	r.Len(hash, int(testParams.KeyLen))
+	r.False(legacy)
+
+	match, err := ComparePassword(hashed, "correct horse")
+	r.NoError(err)
+	r.True(match)
+
+	match, err = ComparePassword(hashed, "correct horse ")
+	r.NoError(err)
+	r.False(match)
+
+	// every hash gets its own salt
+	again, err := HashPasswordWithParams("correct horse", testParams)
+	r.NoError(err)
+	r.NotEqual(hashed, again)
+}
+
+// a hash written before the format was fixed labels the memory "models=", it still verifies
+func TestComparePassword_Legacy(t *testing.T) {
+	r := require.New(t)
+
+	hashed, err := HashPasswordWithParams("correct horse", testParams)
+	r.NoError(err)
+	old := strings.Replace(hashed, "$m=", "$models=", 1)
+
+	p, _, _, legacy, err := decodeHash(old)
+	r.NoError(err)
+	r.Equal(testParams, p)
+	r.True(legacy)
+
+	match, err := ComparePassword(old, "correct horse")
+	r.NoError(err)
+	r.True(match)
+}
+
+func TestDecodeHash_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		hashed string
+	}{
+		{"EMPTY", ""},
+		{"BCRYPT", "$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy"},
+		{"ARGON2I", "$argon2i$v=19$m=1024,t=1,p=1$c2FsdHNhbHQ$aGFzaGhhc2hoYXNoaGFzaA"},
+		{"MISSING_HASH", "$argon2id$v=19$m=1024,t=1,p=1$c2FsdHNhbHQ"},
+		{"OTHER_VERSION", "$argon2id$v=16$m=1024,t=1,p=1$c2FsdHNhbHQ$aGFzaGhhc2hoYXNoaGFzaA"},
+		{"BAD_PARAMS", "$argon2id$v=19$m=x,t=1,p=1$c2FsdHNhbHQ$aGFzaGhhc2hoYXNoaGFzaA"},
+		{"BAD_SALT", "$argon2id$v=19$m=1024,t=1,p=1$c2Fsd!!!$aGFzaGhhc2hoYXNoaGFzaA"},
+		{"BAD_HASH", "$argon2id$v=19$m=1024,t=1,p=1$c2FsdHNhbHQ$aGFza!!!"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, _, _, err := decodeHash(tt.hashed)
+			require.Error(t, err)
+
+			_, err = ComparePassword(tt.hashed, "correct horse")
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	hashed, err := HashPasswordWithParams("correct horse", testParams)
+	require.NoError(t, err)
+
+	with := func(change func(p *Params)) Params {
+		p := testParams
+		change(&p)
+		return p
+	}
+
+	tests := []struct {
+		name   string
+		hashed string
+		params Params
+		want   bool
+	}{
+		{"SAME_COSTS", hashed, testParams, false},
+		{"MORE_MEMORY", hashed, with(func(p *Params) { p.Memory = 2048 }), true},
+		{"MORE_TIME", hashed, with(func(p *Params) { p.Time = 2 }), true},
+		{"MORE_THREADS", hashed, with(func(p *Params) { p.Threads = 2 }), true},
+		{"LONGER_KEY", hashed, with(func(p *Params) { p.KeyLen = 32 }), true},
+		{"LONGER_SALT", hashed, with(func(p *Params) { p.SaltLen = 16 }), true},
+		{"LEGACY", strings.Replace(hashed, "$m=", "$models=", 1), testParams, true},
+		{"INVALID", "plain text", testParams, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, NeedsRehash(tt.hashed, tt.params))
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package security
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+)
+
+const (
+	// PasswordMaxLength keeps a password cheap enough to hash
+	PasswordMaxLength = 128
+
+	// phoneTailDigits is how many last digits of the phone a password must not contain
+	phoneTailDigits = 7
+)
+
+// PasswordPolicy decides which new passwords are accepted and how they are hashed
+type PasswordPolicy struct {
+	MinLength int
+	Params    Params
+
+	// breached holds the lowercased passwords known from leaks
+	breached map[string]struct{}
+}
+
+// NewPasswordPolicy builds the policy from the config and reads the breached passwords file, one password per line
+func NewPasswordPolicy(cfg config.Config) (*PasswordPolicy, error) {
+	p := &PasswordPolicy{
+		MinLength: cfg.PasswordMinLength,
+		Params: Params{
+			Memory:  uint32(cfg.Argon2Memory),
+			Time:    uint32(cfg.Argon2Time),
+			Threads: uint8(cfg.Argon2Threads),
+			KeyLen:  A2IDkeyLen,
+			SaltLen: A2IDsaltLen,
+		},
+		breached: make(map[string]struct{}),
+	}
+	if p.Params.Memory == 0 || p.Params.Time == 0 || p.Params.Threads == 0 {
+		return nil, fmt.Errorf("argon2 memory, time and threads must be positive")
+	}
+
+	if cfg.PasswordBreachedFile == "" {
+		return p, nil
+	}
+
+	f, err := os.Open(cfg.PasswordBreachedFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breached passwords file: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			p.breached[strings.ToLower(line)] = struct{}{}
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read breached passwords file: %w", err)
+	}
+
+	return p, nil
+}
+
+// Check tells why a new password is not accepted for the phone, nil when it is
+func (p *PasswordPolicy) Check(password, phone string) error {
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength {
+		return &customerrors.WeakPasswordError{Reason: fmt.Sprintf("не короче %d символов", p.MinLength)}
+	}
+	if length > PasswordMaxLength {
+		return &customerrors.WeakPasswordError{Reason: fmt.Sprintf("не длиннее %d символов", PasswordMaxLength)}
+	}
+
+	if _, ok := p.breached[strings.ToLower(password)]; ok {
+		return &customerrors.WeakPasswordError{Reason: "пароль найден в утечках, выберите другой"}
+	}
+
+	if resemblesPhone(password, phone) {
+		return &customerrors.WeakPasswordError{Reason: "пароль не должен повторять номер телефона"}
+	}
+
+	return nil
+}
+
+// Hash hashes the password with the configured costs
+func (p *PasswordPolicy) Hash(password string) (string, error) {
+	return HashPasswordWithParams(password, p.Params)
+}
+
+// NeedsRehash reports whether the hash was made with other costs than the configured ones
+func (p *PasswordPolicy) NeedsRehash(hashedPassword string) bool {
+	return NeedsRehash(hashedPassword, p.Params)
+}
+
+// resemblesPhone catches passwords built around the phone: ones holding the end of the number
+// and all digit ones that are a part of it
+func resemblesPhone(password, phone string) bool {
+	phoneDigits := digits(phone)
+	if phoneDigits == "" {
+		return false
+	}
+
+	passwordDigits := digits(password)
+	tail := phoneDigits
+	if len(tail) > phoneTailDigits {
+		tail = tail[len(tail)-phoneTailDigits:]
+	}
+	if strings.Contains(passwordDigits, tail) {
+		return true
+	}
+
+	return passwordDigits != "" && passwordDigits == password && strings.Contains(phoneDigits, password)
+}
+
+func digits(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, s)
+}
@@ -0,0 +1,71 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/pkg/logger"
+)
+
+// SenderI delivers text messages to phone numbers
+type SenderI interface {
+	Send(ctx context.Context, phone, text string) error
+}
+
+// New returns the sender chosen by the config
+func New(cfg config.Config, log logger.LoggerI) (SenderI, error) {
+	switch cfg.SMSSender {
+	case "", "log":
+		return NewLogSender(log), nil
+	case "file":
+		return NewFileSender(cfg.SMSFilePath), nil
+	default:
+		return nil, fmt.Errorf("unknown sms sender %q", cfg.SMSSender)
+	}
+}
+
+// LogSender writes the messages to the service log instead of sending them, for local development
+type LogSender struct {
+	log logger.LoggerI
+}
+
+func NewLogSender(log logger.LoggerI) *LogSender {
+	return &LogSender{log: log}
+}
+
+func (s *LogSender) Send(ctx context.Context, phone, text string) error {
+	s.log.Info("---SMS--->", logger.String("phone", phone), logger.String("text", text))
+	return nil
+}
+
+// FileSender appends the messages to a local file, one per line, for local development and tests
+type FileSender struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewFileSender(path string) *FileSender {
+	return &FileSender{path: path}
+}
+
+func (s *FileSender) Send(ctx context.Context, phone, text string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open sms file: %w", err)
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintf(f, "%s\t%s\t%s\n", time.Now().Format(time.RFC3339), phone, text)
+	if err != nil {
+		return fmt.Errorf("failed to write sms file: %w", err)
+	}
+
+	return nil
+}
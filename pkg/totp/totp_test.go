@@ -0,0 +1,115 @@
+package totp
+
+import (
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// rfcSecret is the SHA1 seed of RFC 6238 appendix B, "12345678901234567890" in ASCII
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+// the appendix lists 8 digit codes, the last 6 digits are the 6 digit code of the same step
+func TestCode(t *testing.T) {
+	tests := []struct {
+		name string
+		unix int64
+		want string
+	}{
+		{"59", 59, "287082"},
+		{"1111111109", 1111111109, "081804"},
+		{"1111111111", 1111111111, "050471"},
+		{"1234567890", 1234567890, "005924"},
+		{"2000000000", 2000000000, "279037"},
+		{"20000000000", 20000000000, "353130"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := Code(rfcSecret, Step(time.Unix(tt.unix, 0)))
+			require.NoError(t, err)
+			require.Equal(t, tt.want, code)
+		})
+	}
+}
+
+func TestCode_LowerCaseSecret(t *testing.T) {
+	lower := []byte(rfcSecret)
+	for i, c := range lower {
+		if c >= 'A' && c <= 'Z' {
+			lower[i] = c + 'a' - 'A'
+		}
+	}
+
+	code, err := Code(string(lower), Step(time.Unix(59, 0)))
+	require.NoError(t, err)
+	require.Equal(t, "287082", code)
+}
+
+func TestCode_InvalidSecret(t *testing.T) {
+	_, err := Code("not base32!", 1)
+	require.Error(t, err)
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := Step(now)
+
+	tests := []struct {
+		name     string
+		code     string
+		wantStep int64
+		wantOK   bool
+	}{
+		{"CURRENT_STEP", "050471", current, true},
+		{"WITH_SPACES", " 050471 ", current, true},
+		{"PREVIOUS_STEP", mustCode(t, current-1), current - 1, true},
+		{"NEXT_STEP", mustCode(t, current+1), current + 1, true},
+		{"TWO_STEPS_AGO", mustCode(t, current-2), 0, false},
+		{"TWO_STEPS_AHEAD", mustCode(t, current+2), 0, false},
+		{"WRONG_CODE", "000000", 0, false},
+		{"TOO_SHORT", "05047", 0, false},
+		{"TOO_LONG", "0504710", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := Validate(rfcSecret, tt.code, now)
+			require.Equal(t, tt.wantOK, ok)
+			require.Equal(t, tt.wantStep, step)
+		})
+	}
+}
+
+func TestURI(t *testing.T) {
+	uri := URI("Online Banking", "+998901234567", rfcSecret)
+
+	u, err := url.Parse(uri)
+	require.NoError(t, err)
+	require.Equal(t, "otpauth", u.Scheme)
+	require.Equal(t, "totp", u.Host)
+	require.Equal(t, "/Online Banking:+998901234567", u.Path)
+
+	q := u.Query()
+	require.Equal(t, rfcSecret, q.Get("secret"))
+	require.Equal(t, "Online Banking", q.Get("issuer"))
+	require.Equal(t, "SHA1", q.Get("algorithm"))
+	require.Equal(t, "6", q.Get("digits"))
+	require.Equal(t, "30", q.Get("period"))
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	require.NoError(t, err)
+	require.Len(t, key, secretLen)
+}
+
+func mustCode(t *testing.T, step int64) string {
+	code, err := Code(rfcSecret, step)
+	require.NoError(t, err)
+	return code
+}
@@ -0,0 +1,21 @@
+package util
+
+import "unicode/utf8"
+
+// MaskName hides most of a person's name, e.g. "John", "Doe" -> "J*** D."
+func MaskName(firstName, lastName string) string {
+	masked := ""
+
+	if r, _ := utf8.DecodeRuneInString(firstName); r != utf8.RuneError {
+		masked = string(r) + "***"
+	}
+
+	if r, _ := utf8.DecodeRuneInString(lastName); r != utf8.RuneError {
+		if masked != "" {
+			masked += " "
+		}
+		masked += string(r) + "."
+	}
+
+	return masked
+}
@@ -0,0 +1,33 @@
+package util
+
+import (
+	"regexp"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// DescriptionMaxLength is the longest free-text description a transaction may carry
+	DescriptionMaxLength = 140
+	// ReferenceMaxLength matches the ISO 20022 end-to-end identifier length
+	ReferenceMaxLength = 35
+)
+
+// IsValidDescription checks that a transaction description is short and has no control characters
+func IsValidDescription(description string) bool {
+	if !utf8.ValidString(description) || utf8.RuneCountInString(description) > DescriptionMaxLength {
+		return false
+	}
+	for _, r := range description {
+		if unicode.IsControl(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsValidReference checks that an external reference uses the SWIFT character set
+func IsValidReference(reference string) bool {
+	r := regexp.MustCompile(`^[A-Za-z0-9/\-?:().,'+ ]{0,35}$`)
+	return r.MatchString(reference)
+}
@@ -8,6 +8,7 @@ import (
 	context "context"
 	sql "database/sql"
 	reflect "reflect"
+	time "time"
 
 	models "github.com/dilmurodov/online_banking/pkg/models"
 	storage "github.com/dilmurodov/online_banking/storage"
@@ -37,6 +38,20 @@ func (m *MockStorageI) EXPECT() *MockStorageIMockRecorder {
 	return m.recorder
 }
 
+// AML mocks base method.
+func (m *MockStorageI) AML() storage.AMLRepoI {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AML")
+	ret0, _ := ret[0].(storage.AMLRepoI)
+	return ret0
+}
+
+// AML indicates an expected call of AML.
+func (mr *MockStorageIMockRecorder) AML() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AML", reflect.TypeOf((*MockStorageI)(nil).AML))
+}
+
 // Account mocks base method.
 func (m *MockStorageI) Account() storage.AccountRepoI {
 	m.ctrl.T.Helper()
@@ -51,6 +66,62 @@ func (mr *MockStorageIMockRecorder) Account() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Account", reflect.TypeOf((*MockStorageI)(nil).Account))
 }
 
+// AccountHolder mocks base method.
+func (m *MockStorageI) AccountHolder() storage.AccountHolderRepoI {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AccountHolder")
+	ret0, _ := ret[0].(storage.AccountHolderRepoI)
+	return ret0
+}
+
+// AccountHolder indicates an expected call of AccountHolder.
+func (mr *MockStorageIMockRecorder) AccountHolder() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AccountHolder", reflect.TypeOf((*MockStorageI)(nil).AccountHolder))
+}
+
+// Admin mocks base method.
+func (m *MockStorageI) Admin() storage.AdminRepoI {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Admin")
+	ret0, _ := ret[0].(storage.AdminRepoI)
+	return ret0
+}
+
+// Admin indicates an expected call of Admin.
+func (mr *MockStorageIMockRecorder) Admin() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Admin", reflect.TypeOf((*MockStorageI)(nil).Admin))
+}
+
+// Audit mocks base method.
+func (m *MockStorageI) Audit() storage.AuditRepoI {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Audit")
+	ret0, _ := ret[0].(storage.AuditRepoI)
+	return ret0
+}
+
+// Audit indicates an expected call of Audit.
+func (mr *MockStorageIMockRecorder) Audit() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Audit", reflect.TypeOf((*MockStorageI)(nil).Audit))
+}
+
+// Beneficiary mocks base method.
+func (m *MockStorageI) Beneficiary() storage.BeneficiaryRepoI {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Beneficiary")
+	ret0, _ := ret[0].(storage.BeneficiaryRepoI)
+	return ret0
+}
+
+// Beneficiary indicates an expected call of Beneficiary.
+func (mr *MockStorageIMockRecorder) Beneficiary() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Beneficiary", reflect.TypeOf((*MockStorageI)(nil).Beneficiary))
+}
+
 // CloseDB mocks base method.
 func (m *MockStorageI) CloseDB() {
 	m.ctrl.T.Helper()
@@ -63,6 +134,202 @@ func (mr *MockStorageIMockRecorder) CloseDB() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseDB", reflect.TypeOf((*MockStorageI)(nil).CloseDB))
 }
 
+// Deposit mocks base method.
+func (m *MockStorageI) Deposit() storage.DepositRepoI {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Deposit")
+	ret0, _ := ret[0].(storage.DepositRepoI)
+	return ret0
+}
+
+// Deposit indicates an expected call of Deposit.
+func (mr *MockStorageIMockRecorder) Deposit() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deposit", reflect.TypeOf((*MockStorageI)(nil).Deposit))
+}
+
+// Fraud mocks base method.
+func (m *MockStorageI) Fraud() storage.FraudRepoI {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Fraud")
+	ret0, _ := ret[0].(storage.FraudRepoI)
+	return ret0
+}
+
+// Fraud indicates an expected call of Fraud.
+func (mr *MockStorageIMockRecorder) Fraud() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Fraud", reflect.TypeOf((*MockStorageI)(nil).Fraud))
+}
+
+// Interest mocks base method.
+func (m *MockStorageI) Interest() storage.InterestRepoI {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Interest")
+	ret0, _ := ret[0].(storage.InterestRepoI)
+	return ret0
+}
+
+// Interest indicates an expected call of Interest.
+func (mr *MockStorageIMockRecorder) Interest() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Interest", reflect.TypeOf((*MockStorageI)(nil).Interest))
+}
+
+// Job mocks base method.
+func (m *MockStorageI) Job() storage.JobRepoI {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Job")
+	ret0, _ := ret[0].(storage.JobRepoI)
+	return ret0
+}
+
+// Job indicates an expected call of Job.
+func (mr *MockStorageIMockRecorder) Job() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Job", reflect.TypeOf((*MockStorageI)(nil).Job))
+}
+
+// KYC mocks base method.
+func (m *MockStorageI) KYC() storage.KYCRepoI {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "KYC")
+	ret0, _ := ret[0].(storage.KYCRepoI)
+	return ret0
+}
+
+// KYC indicates an expected call of KYC.
+func (mr *MockStorageIMockRecorder) KYC() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "KYC", reflect.TypeOf((*MockStorageI)(nil).KYC))
+}
+
+// Loan mocks base method.
+func (m *MockStorageI) Loan() storage.LoanRepoI {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Loan")
+	ret0, _ := ret[0].(storage.LoanRepoI)
+	return ret0
+}
+
+// Loan indicates an expected call of Loan.
+func (mr *MockStorageIMockRecorder) Loan() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Loan", reflect.TypeOf((*MockStorageI)(nil).Loan))
+}
+
+// LoginFailure mocks base method.
+func (m *MockStorageI) LoginFailure() storage.LoginFailureRepoI {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoginFailure")
+	ret0, _ := ret[0].(storage.LoginFailureRepoI)
+	return ret0
+}
+
+// LoginFailure indicates an expected call of LoginFailure.
+func (mr *MockStorageIMockRecorder) LoginFailure() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoginFailure", reflect.TypeOf((*MockStorageI)(nil).LoginFailure))
+}
+
+// OTP mocks base method.
+func (m *MockStorageI) OTP() storage.OTPRepoI {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OTP")
+	ret0, _ := ret[0].(storage.OTPRepoI)
+	return ret0
+}
+
+// OTP indicates an expected call of OTP.
+func (mr *MockStorageIMockRecorder) OTP() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OTP", reflect.TypeOf((*MockStorageI)(nil).OTP))
+}
+
+// Operation mocks base method.
+func (m *MockStorageI) Operation() storage.OperationRepoI {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Operation")
+	ret0, _ := ret[0].(storage.OperationRepoI)
+	return ret0
+}
+
+// Operation indicates an expected call of Operation.
+func (mr *MockStorageIMockRecorder) Operation() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Operation", reflect.TypeOf((*MockStorageI)(nil).Operation))
+}
+
+// Overdraft mocks base method.
+func (m *MockStorageI) Overdraft() storage.OverdraftRepoI {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Overdraft")
+	ret0, _ := ret[0].(storage.OverdraftRepoI)
+	return ret0
+}
+
+// Overdraft indicates an expected call of Overdraft.
+func (mr *MockStorageIMockRecorder) Overdraft() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Overdraft", reflect.TypeOf((*MockStorageI)(nil).Overdraft))
+}
+
+// PaymentRequest mocks base method.
+func (m *MockStorageI) PaymentRequest() storage.PaymentRequestRepoI {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PaymentRequest")
+	ret0, _ := ret[0].(storage.PaymentRequestRepoI)
+	return ret0
+}
+
+// PaymentRequest indicates an expected call of PaymentRequest.
+func (mr *MockStorageIMockRecorder) PaymentRequest() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PaymentRequest", reflect.TypeOf((*MockStorageI)(nil).PaymentRequest))
+}
+
+// Pot mocks base method.
+func (m *MockStorageI) Pot() storage.PotRepoI {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Pot")
+	ret0, _ := ret[0].(storage.PotRepoI)
+	return ret0
+}
+
+// Pot indicates an expected call of Pot.
+func (mr *MockStorageIMockRecorder) Pot() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pot", reflect.TypeOf((*MockStorageI)(nil).Pot))
+}
+
+// Sanctions mocks base method.
+func (m *MockStorageI) Sanctions() storage.SanctionsRepoI {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Sanctions")
+	ret0, _ := ret[0].(storage.SanctionsRepoI)
+	return ret0
+}
+
+// Sanctions indicates an expected call of Sanctions.
+func (mr *MockStorageIMockRecorder) Sanctions() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sanctions", reflect.TypeOf((*MockStorageI)(nil).Sanctions))
+}
+
+// TwoFactor mocks base method.
+func (m *MockStorageI) TwoFactor() storage.TwoFactorRepoI {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TwoFactor")
+	ret0, _ := ret[0].(storage.TwoFactorRepoI)
+	return ret0
+}
+
+// TwoFactor indicates an expected call of TwoFactor.
+func (mr *MockStorageIMockRecorder) TwoFactor() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TwoFactor", reflect.TypeOf((*MockStorageI)(nil).TwoFactor))
+}
+
 // TxRepo mocks base method.
 func (m *MockStorageI) TxRepo() storage.TxRepoI {
 	m.ctrl.T.Helper()
@@ -115,18 +382,47 @@ func (m *MockUserRepoI) EXPECT() *MockUserRepoIMockRecorder {
 }
 
 // CreateUser mocks base method.
-func (m *MockUserRepoI) CreateUser(arg0 context.Context, arg1 *models.CreateUserRequest) (*models.User, error) {
+func (m *MockUserRepoI) CreateUser(ctx context.Context, tx *sql.Tx, req *models.CreateUserRequest) (*models.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateUser", arg0, arg1)
+	ret := m.ctrl.Call(m, "CreateUser", ctx, tx, req)
 	ret0, _ := ret[0].(*models.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateUser indicates an expected call of CreateUser.
-func (mr *MockUserRepoIMockRecorder) CreateUser(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockUserRepoIMockRecorder) CreateUser(ctx, tx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockUserRepoI)(nil).CreateUser), ctx, tx, req)
+}
+
+// DeleteUser mocks base method.
+func (m *MockUserRepoI) DeleteUser(ctx context.Context, tx *sql.Tx, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUser", ctx, tx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUser indicates an expected call of DeleteUser.
+func (mr *MockUserRepoIMockRecorder) DeleteUser(ctx, tx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockUserRepoI)(nil).DeleteUser), ctx, tx, userID)
+}
+
+// GetPhoneRecipient mocks base method.
+func (m *MockUserRepoI) GetPhoneRecipient(ctx context.Context, phone string) (*models.PhoneRecipient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPhoneRecipient", ctx, phone)
+	ret0, _ := ret[0].(*models.PhoneRecipient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPhoneRecipient indicates an expected call of GetPhoneRecipient.
+func (mr *MockUserRepoIMockRecorder) GetPhoneRecipient(ctx, phone interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockUserRepoI)(nil).CreateUser), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPhoneRecipient", reflect.TypeOf((*MockUserRepoI)(nil).GetPhoneRecipient), ctx, phone)
 }
 
 // GetUserByID mocks base method.
@@ -159,6 +455,91 @@ func (mr *MockUserRepoIMockRecorder) GetUserPasswordByPhone(ctx, phone interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserPasswordByPhone", reflect.TypeOf((*MockUserRepoI)(nil).GetUserPasswordByPhone), ctx, phone)
 }
 
+// ResetUserPassword mocks base method.
+func (m *MockUserRepoI) ResetUserPassword(ctx context.Context, tx *sql.Tx, userID, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetUserPassword", ctx, tx, userID, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetUserPassword indicates an expected call of ResetUserPassword.
+func (mr *MockUserRepoIMockRecorder) ResetUserPassword(ctx, tx, userID, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetUserPassword", reflect.TypeOf((*MockUserRepoI)(nil).ResetUserPassword), ctx, tx, userID, password)
+}
+
+// SetDefaultAccount mocks base method.
+func (m *MockUserRepoI) SetDefaultAccount(ctx context.Context, req *models.SetDefaultAccountRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetDefaultAccount", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetDefaultAccount indicates an expected call of SetDefaultAccount.
+func (mr *MockUserRepoIMockRecorder) SetDefaultAccount(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDefaultAccount", reflect.TypeOf((*MockUserRepoI)(nil).SetDefaultAccount), ctx, req)
+}
+
+// SetUserRole mocks base method.
+func (m *MockUserRepoI) SetUserRole(ctx context.Context, tx *sql.Tx, userID, role string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserRole", ctx, tx, userID, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetUserRole indicates an expected call of SetUserRole.
+func (mr *MockUserRepoIMockRecorder) SetUserRole(ctx, tx, userID, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserRole", reflect.TypeOf((*MockUserRepoI)(nil).SetUserRole), ctx, tx, userID, role)
+}
+
+// UpdateUser mocks base method.
+func (m *MockUserRepoI) UpdateUser(ctx context.Context, req *models.UpdateProfileRequest) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUser", ctx, req)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUser indicates an expected call of UpdateUser.
+func (mr *MockUserRepoIMockRecorder) UpdateUser(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockUserRepoI)(nil).UpdateUser), ctx, req)
+}
+
+// UpdateUserPassword mocks base method.
+func (m *MockUserRepoI) UpdateUserPassword(ctx context.Context, tx *sql.Tx, userID, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserPassword", ctx, tx, userID, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserPassword indicates an expected call of UpdateUserPassword.
+func (mr *MockUserRepoIMockRecorder) UpdateUserPassword(ctx, tx, userID, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserPassword", reflect.TypeOf((*MockUserRepoI)(nil).UpdateUserPassword), ctx, tx, userID, password)
+}
+
+// UpdateUserPhone mocks base method.
+func (m *MockUserRepoI) UpdateUserPhone(ctx context.Context, tx *sql.Tx, userID, phone string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserPhone", ctx, tx, userID, phone)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserPhone indicates an expected call of UpdateUserPhone.
+func (mr *MockUserRepoIMockRecorder) UpdateUserPhone(ctx, tx, userID, phone interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserPhone", reflect.TypeOf((*MockUserRepoI)(nil).UpdateUserPhone), ctx, tx, userID, phone)
+}
+
 // MockAccountRepoI is a mock of AccountRepoI interface.
 type MockAccountRepoI struct {
 	ctrl     *gomock.Controller
@@ -182,6 +563,20 @@ func (m *MockAccountRepoI) EXPECT() *MockAccountRepoIMockRecorder {
 	return m.recorder
 }
 
+// AdjustAccountBalance mocks base method.
+func (m *MockAccountRepoI) AdjustAccountBalance(ctx context.Context, tx *sql.Tx, req *models.AdjustAccountBalanceRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdjustAccountBalance", ctx, tx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AdjustAccountBalance indicates an expected call of AdjustAccountBalance.
+func (mr *MockAccountRepoIMockRecorder) AdjustAccountBalance(ctx, tx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdjustAccountBalance", reflect.TypeOf((*MockAccountRepoI)(nil).AdjustAccountBalance), ctx, tx, req)
+}
+
 // CreateAccount mocks base method.
 func (m *MockAccountRepoI) CreateAccount(arg0 context.Context, arg1 *models.CreateAccountRequest) (*models.Account, error) {
 	m.ctrl.T.Helper()
@@ -212,6 +607,51 @@ func (mr *MockAccountRepoIMockRecorder) GetAccountByID(arg0, arg1 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountByID", reflect.TypeOf((*MockAccountRepoI)(nil).GetAccountByID), arg0, arg1)
 }
 
+// GetAccountForUpdate mocks base method.
+func (m *MockAccountRepoI) GetAccountForUpdate(ctx context.Context, tx *sql.Tx, accountID string) (*models.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountForUpdate", ctx, tx, accountID)
+	ret0, _ := ret[0].(*models.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountForUpdate indicates an expected call of GetAccountForUpdate.
+func (mr *MockAccountRepoIMockRecorder) GetAccountForUpdate(ctx, tx, accountID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountForUpdate", reflect.TypeOf((*MockAccountRepoI)(nil).GetAccountForUpdate), ctx, tx, accountID)
+}
+
+// GetAccountStatus mocks base method.
+func (m *MockAccountRepoI) GetAccountStatus(ctx context.Context, tx *sql.Tx, accountID string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountStatus", ctx, tx, accountID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountStatus indicates an expected call of GetAccountStatus.
+func (mr *MockAccountRepoIMockRecorder) GetAccountStatus(ctx, tx, accountID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountStatus", reflect.TypeOf((*MockAccountRepoI)(nil).GetAccountStatus), ctx, tx, accountID)
+}
+
+// GetAccountStatusHistory mocks base method.
+func (m *MockAccountRepoI) GetAccountStatusHistory(ctx context.Context, accountID string) (*models.GetAccountStatusHistoryResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountStatusHistory", ctx, accountID)
+	ret0, _ := ret[0].(*models.GetAccountStatusHistoryResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountStatusHistory indicates an expected call of GetAccountStatusHistory.
+func (mr *MockAccountRepoIMockRecorder) GetAccountStatusHistory(ctx, accountID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountStatusHistory", reflect.TypeOf((*MockAccountRepoI)(nil).GetAccountStatusHistory), ctx, accountID)
+}
+
 // GetAccountsByUserID mocks base method.
 func (m *MockAccountRepoI) GetAccountsByUserID(arg0 context.Context, arg1 *models.GetAccountsByUserIDRequest) (*models.GetAccountsByUserIDResponse, error) {
 	m.ctrl.T.Helper()
@@ -227,18 +667,48 @@ func (mr *MockAccountRepoIMockRecorder) GetAccountsByUserID(arg0, arg1 interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountsByUserID", reflect.TypeOf((*MockAccountRepoI)(nil).GetAccountsByUserID), arg0, arg1)
 }
 
-// UpdateAccountBalance mocks base method.
-func (m *MockAccountRepoI) UpdateAccountBalance(arg0 context.Context, arg1 *sql.Tx, arg2 *models.Account) error {
+// GetClosingBlockers mocks base method.
+func (m *MockAccountRepoI) GetClosingBlockers(ctx context.Context, tx *sql.Tx, accountID string) (*models.AccountClosingBlockers, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetClosingBlockers", ctx, tx, accountID)
+	ret0, _ := ret[0].(*models.AccountClosingBlockers)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetClosingBlockers indicates an expected call of GetClosingBlockers.
+func (mr *MockAccountRepoIMockRecorder) GetClosingBlockers(ctx, tx, accountID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClosingBlockers", reflect.TypeOf((*MockAccountRepoI)(nil).GetClosingBlockers), ctx, tx, accountID)
+}
+
+// GetOwnedAccountsForUpdate mocks base method.
+func (m *MockAccountRepoI) GetOwnedAccountsForUpdate(ctx context.Context, tx *sql.Tx, userID string) ([]*models.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOwnedAccountsForUpdate", ctx, tx, userID)
+	ret0, _ := ret[0].([]*models.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOwnedAccountsForUpdate indicates an expected call of GetOwnedAccountsForUpdate.
+func (mr *MockAccountRepoIMockRecorder) GetOwnedAccountsForUpdate(ctx, tx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOwnedAccountsForUpdate", reflect.TypeOf((*MockAccountRepoI)(nil).GetOwnedAccountsForUpdate), ctx, tx, userID)
+}
+
+// UpdateAccountStatus mocks base method.
+func (m *MockAccountRepoI) UpdateAccountStatus(ctx context.Context, tx *sql.Tx, req *models.AccountStatusChange) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateAccountBalance", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "UpdateAccountStatus", ctx, tx, req)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// UpdateAccountBalance indicates an expected call of UpdateAccountBalance.
-func (mr *MockAccountRepoIMockRecorder) UpdateAccountBalance(arg0, arg1, arg2 interface{}) *gomock.Call {
+// UpdateAccountStatus indicates an expected call of UpdateAccountStatus.
+func (mr *MockAccountRepoIMockRecorder) UpdateAccountStatus(ctx, tx, req interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAccountBalance", reflect.TypeOf((*MockAccountRepoI)(nil).UpdateAccountBalance), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAccountStatus", reflect.TypeOf((*MockAccountRepoI)(nil).UpdateAccountStatus), ctx, tx, req)
 }
 
 // MockTxRepoI is a mock of TxRepoI interface.
@@ -352,3 +822,2485 @@ func (mr *MockTxRepoIMockRecorder) GetTransactionsByIDS(ctx, req interface{}) *g
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransactionsByIDS", reflect.TypeOf((*MockTxRepoI)(nil).GetTransactionsByIDS), ctx, req)
 }
+
+// GetTransferredTotal mocks base method.
+func (m *MockTxRepoI) GetTransferredTotal(ctx context.Context, req *models.GetTransferredTotalRequest) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransferredTotal", ctx, req)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransferredTotal indicates an expected call of GetTransferredTotal.
+func (mr *MockTxRepoIMockRecorder) GetTransferredTotal(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransferredTotal", reflect.TypeOf((*MockTxRepoI)(nil).GetTransferredTotal), ctx, req)
+}
+
+// SearchTransactions mocks base method.
+func (m *MockTxRepoI) SearchTransactions(ctx context.Context, req *models.SearchTransactionsRequest) (*models.SearchTransactionsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchTransactions", ctx, req)
+	ret0, _ := ret[0].(*models.SearchTransactionsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchTransactions indicates an expected call of SearchTransactions.
+func (mr *MockTxRepoIMockRecorder) SearchTransactions(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchTransactions", reflect.TypeOf((*MockTxRepoI)(nil).SearchTransactions), ctx, req)
+}
+
+// MockPaymentRequestRepoI is a mock of PaymentRequestRepoI interface.
+type MockPaymentRequestRepoI struct {
+	ctrl     *gomock.Controller
+	recorder *MockPaymentRequestRepoIMockRecorder
+}
+
+// MockPaymentRequestRepoIMockRecorder is the mock recorder for MockPaymentRequestRepoI.
+type MockPaymentRequestRepoIMockRecorder struct {
+	mock *MockPaymentRequestRepoI
+}
+
+// NewMockPaymentRequestRepoI creates a new mock instance.
+func NewMockPaymentRequestRepoI(ctrl *gomock.Controller) *MockPaymentRequestRepoI {
+	mock := &MockPaymentRequestRepoI{ctrl: ctrl}
+	mock.recorder = &MockPaymentRequestRepoIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPaymentRequestRepoI) EXPECT() *MockPaymentRequestRepoIMockRecorder {
+	return m.recorder
+}
+
+// CreatePaymentRequest mocks base method.
+func (m *MockPaymentRequestRepoI) CreatePaymentRequest(ctx context.Context, req *models.PaymentRequest) (*models.PaymentRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePaymentRequest", ctx, req)
+	ret0, _ := ret[0].(*models.PaymentRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePaymentRequest indicates an expected call of CreatePaymentRequest.
+func (mr *MockPaymentRequestRepoIMockRecorder) CreatePaymentRequest(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePaymentRequest", reflect.TypeOf((*MockPaymentRequestRepoI)(nil).CreatePaymentRequest), ctx, req)
+}
+
+// ExpirePaymentRequests mocks base method.
+func (m *MockPaymentRequestRepoI) ExpirePaymentRequests(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpirePaymentRequests", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExpirePaymentRequests indicates an expected call of ExpirePaymentRequests.
+func (mr *MockPaymentRequestRepoIMockRecorder) ExpirePaymentRequests(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpirePaymentRequests", reflect.TypeOf((*MockPaymentRequestRepoI)(nil).ExpirePaymentRequests), ctx)
+}
+
+// GetPaymentRequestByID mocks base method.
+func (m *MockPaymentRequestRepoI) GetPaymentRequestByID(ctx context.Context, id string) (*models.PaymentRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentRequestByID", ctx, id)
+	ret0, _ := ret[0].(*models.PaymentRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentRequestByID indicates an expected call of GetPaymentRequestByID.
+func (mr *MockPaymentRequestRepoIMockRecorder) GetPaymentRequestByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentRequestByID", reflect.TypeOf((*MockPaymentRequestRepoI)(nil).GetPaymentRequestByID), ctx, id)
+}
+
+// GetPaymentRequests mocks base method.
+func (m *MockPaymentRequestRepoI) GetPaymentRequests(ctx context.Context, req *models.GetPaymentRequestsRequest) (*models.GetPaymentRequestsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentRequests", ctx, req)
+	ret0, _ := ret[0].(*models.GetPaymentRequestsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentRequests indicates an expected call of GetPaymentRequests.
+func (mr *MockPaymentRequestRepoIMockRecorder) GetPaymentRequests(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentRequests", reflect.TypeOf((*MockPaymentRequestRepoI)(nil).GetPaymentRequests), ctx, req)
+}
+
+// UpdatePaymentRequestStatus mocks base method.
+func (m *MockPaymentRequestRepoI) UpdatePaymentRequestStatus(ctx context.Context, req *models.UpdatePaymentRequestStatusRequest) (*models.PaymentRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePaymentRequestStatus", ctx, req)
+	ret0, _ := ret[0].(*models.PaymentRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePaymentRequestStatus indicates an expected call of UpdatePaymentRequestStatus.
+func (mr *MockPaymentRequestRepoIMockRecorder) UpdatePaymentRequestStatus(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePaymentRequestStatus", reflect.TypeOf((*MockPaymentRequestRepoI)(nil).UpdatePaymentRequestStatus), ctx, req)
+}
+
+// MockBeneficiaryRepoI is a mock of BeneficiaryRepoI interface.
+type MockBeneficiaryRepoI struct {
+	ctrl     *gomock.Controller
+	recorder *MockBeneficiaryRepoIMockRecorder
+}
+
+// MockBeneficiaryRepoIMockRecorder is the mock recorder for MockBeneficiaryRepoI.
+type MockBeneficiaryRepoIMockRecorder struct {
+	mock *MockBeneficiaryRepoI
+}
+
+// NewMockBeneficiaryRepoI creates a new mock instance.
+func NewMockBeneficiaryRepoI(ctrl *gomock.Controller) *MockBeneficiaryRepoI {
+	mock := &MockBeneficiaryRepoI{ctrl: ctrl}
+	mock.recorder = &MockBeneficiaryRepoIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBeneficiaryRepoI) EXPECT() *MockBeneficiaryRepoIMockRecorder {
+	return m.recorder
+}
+
+// CreateBeneficiary mocks base method.
+func (m *MockBeneficiaryRepoI) CreateBeneficiary(ctx context.Context, tx *sql.Tx, req *models.CreateBeneficiaryRequest) (*models.Beneficiary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBeneficiary", ctx, tx, req)
+	ret0, _ := ret[0].(*models.Beneficiary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateBeneficiary indicates an expected call of CreateBeneficiary.
+func (mr *MockBeneficiaryRepoIMockRecorder) CreateBeneficiary(ctx, tx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBeneficiary", reflect.TypeOf((*MockBeneficiaryRepoI)(nil).CreateBeneficiary), ctx, tx, req)
+}
+
+// DeleteBeneficiary mocks base method.
+func (m *MockBeneficiaryRepoI) DeleteBeneficiary(ctx context.Context, tx *sql.Tx, req *models.BeneficiaryByIDRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBeneficiary", ctx, tx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteBeneficiary indicates an expected call of DeleteBeneficiary.
+func (mr *MockBeneficiaryRepoIMockRecorder) DeleteBeneficiary(ctx, tx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBeneficiary", reflect.TypeOf((*MockBeneficiaryRepoI)(nil).DeleteBeneficiary), ctx, tx, req)
+}
+
+// GetBeneficiaries mocks base method.
+func (m *MockBeneficiaryRepoI) GetBeneficiaries(ctx context.Context, req *models.GetBeneficiariesRequest) (*models.GetBeneficiariesResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBeneficiaries", ctx, req)
+	ret0, _ := ret[0].(*models.GetBeneficiariesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBeneficiaries indicates an expected call of GetBeneficiaries.
+func (mr *MockBeneficiaryRepoIMockRecorder) GetBeneficiaries(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBeneficiaries", reflect.TypeOf((*MockBeneficiaryRepoI)(nil).GetBeneficiaries), ctx, req)
+}
+
+// GetBeneficiaryByAccount mocks base method.
+func (m *MockBeneficiaryRepoI) GetBeneficiaryByAccount(ctx context.Context, req *models.BeneficiaryByAccountRequest) (*models.Beneficiary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBeneficiaryByAccount", ctx, req)
+	ret0, _ := ret[0].(*models.Beneficiary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBeneficiaryByAccount indicates an expected call of GetBeneficiaryByAccount.
+func (mr *MockBeneficiaryRepoIMockRecorder) GetBeneficiaryByAccount(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBeneficiaryByAccount", reflect.TypeOf((*MockBeneficiaryRepoI)(nil).GetBeneficiaryByAccount), ctx, req)
+}
+
+// GetBeneficiaryByID mocks base method.
+func (m *MockBeneficiaryRepoI) GetBeneficiaryByID(ctx context.Context, req *models.BeneficiaryByIDRequest) (*models.Beneficiary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBeneficiaryByID", ctx, req)
+	ret0, _ := ret[0].(*models.Beneficiary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBeneficiaryByID indicates an expected call of GetBeneficiaryByID.
+func (mr *MockBeneficiaryRepoIMockRecorder) GetBeneficiaryByID(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBeneficiaryByID", reflect.TypeOf((*MockBeneficiaryRepoI)(nil).GetBeneficiaryByID), ctx, req)
+}
+
+// GetBeneficiaryForUpdate mocks base method.
+func (m *MockBeneficiaryRepoI) GetBeneficiaryForUpdate(ctx context.Context, tx *sql.Tx, req *models.BeneficiaryByIDRequest) (*models.Beneficiary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBeneficiaryForUpdate", ctx, tx, req)
+	ret0, _ := ret[0].(*models.Beneficiary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBeneficiaryForUpdate indicates an expected call of GetBeneficiaryForUpdate.
+func (mr *MockBeneficiaryRepoIMockRecorder) GetBeneficiaryForUpdate(ctx, tx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBeneficiaryForUpdate", reflect.TypeOf((*MockBeneficiaryRepoI)(nil).GetBeneficiaryForUpdate), ctx, tx, req)
+}
+
+// UpdateBeneficiary mocks base method.
+func (m *MockBeneficiaryRepoI) UpdateBeneficiary(ctx context.Context, tx *sql.Tx, req *models.UpdateBeneficiaryRequest) (*models.Beneficiary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateBeneficiary", ctx, tx, req)
+	ret0, _ := ret[0].(*models.Beneficiary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateBeneficiary indicates an expected call of UpdateBeneficiary.
+func (mr *MockBeneficiaryRepoIMockRecorder) UpdateBeneficiary(ctx, tx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBeneficiary", reflect.TypeOf((*MockBeneficiaryRepoI)(nil).UpdateBeneficiary), ctx, tx, req)
+}
+
+// MockInterestRepoI is a mock of InterestRepoI interface.
+type MockInterestRepoI struct {
+	ctrl     *gomock.Controller
+	recorder *MockInterestRepoIMockRecorder
+}
+
+// MockInterestRepoIMockRecorder is the mock recorder for MockInterestRepoI.
+type MockInterestRepoIMockRecorder struct {
+	mock *MockInterestRepoI
+}
+
+// NewMockInterestRepoI creates a new mock instance.
+func NewMockInterestRepoI(ctrl *gomock.Controller) *MockInterestRepoI {
+	mock := &MockInterestRepoI{ctrl: ctrl}
+	mock.recorder = &MockInterestRepoIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockInterestRepoI) EXPECT() *MockInterestRepoIMockRecorder {
+	return m.recorder
+}
+
+// CreateAccrual mocks base method.
+func (m *MockInterestRepoI) CreateAccrual(ctx context.Context, req *models.InterestAccrual) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccrual", ctx, req)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAccrual indicates an expected call of CreateAccrual.
+func (mr *MockInterestRepoIMockRecorder) CreateAccrual(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccrual", reflect.TypeOf((*MockInterestRepoI)(nil).CreateAccrual), ctx, req)
+}
+
+// CreateCapitalization mocks base method.
+func (m *MockInterestRepoI) CreateCapitalization(ctx context.Context, tx *sql.Tx, req *models.InterestCapitalization) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCapitalization", ctx, tx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateCapitalization indicates an expected call of CreateCapitalization.
+func (mr *MockInterestRepoIMockRecorder) CreateCapitalization(ctx, tx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCapitalization", reflect.TypeOf((*MockInterestRepoI)(nil).CreateCapitalization), ctx, tx, req)
+}
+
+// GetAccrualCandidates mocks base method.
+func (m *MockInterestRepoI) GetAccrualCandidates(ctx context.Context, req *models.GetAccrualCandidatesRequest) ([]*models.AccrualCandidate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccrualCandidates", ctx, req)
+	ret0, _ := ret[0].([]*models.AccrualCandidate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccrualCandidates indicates an expected call of GetAccrualCandidates.
+func (mr *MockInterestRepoIMockRecorder) GetAccrualCandidates(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccrualCandidates", reflect.TypeOf((*MockInterestRepoI)(nil).GetAccrualCandidates), ctx, req)
+}
+
+// GetCapitalizationCandidates mocks base method.
+func (m *MockInterestRepoI) GetCapitalizationCandidates(ctx context.Context, req *models.GetCapitalizationCandidatesRequest) ([]*models.CapitalizationCandidate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCapitalizationCandidates", ctx, req)
+	ret0, _ := ret[0].([]*models.CapitalizationCandidate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCapitalizationCandidates indicates an expected call of GetCapitalizationCandidates.
+func (mr *MockInterestRepoIMockRecorder) GetCapitalizationCandidates(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCapitalizationCandidates", reflect.TypeOf((*MockInterestRepoI)(nil).GetCapitalizationCandidates), ctx, req)
+}
+
+// GetInterestAccruals mocks base method.
+func (m *MockInterestRepoI) GetInterestAccruals(ctx context.Context, req *models.GetInterestAccrualsRequest) (*models.GetInterestAccrualsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInterestAccruals", ctx, req)
+	ret0, _ := ret[0].(*models.GetInterestAccrualsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInterestAccruals indicates an expected call of GetInterestAccruals.
+func (mr *MockInterestRepoIMockRecorder) GetInterestAccruals(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInterestAccruals", reflect.TypeOf((*MockInterestRepoI)(nil).GetInterestAccruals), ctx, req)
+}
+
+// GetProductByCode mocks base method.
+func (m *MockInterestRepoI) GetProductByCode(ctx context.Context, code string) (*models.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductByCode", ctx, code)
+	ret0, _ := ret[0].(*models.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductByCode indicates an expected call of GetProductByCode.
+func (mr *MockInterestRepoIMockRecorder) GetProductByCode(ctx, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductByCode", reflect.TypeOf((*MockInterestRepoI)(nil).GetProductByCode), ctx, code)
+}
+
+// GetProducts mocks base method.
+func (m *MockInterestRepoI) GetProducts(ctx context.Context) (*models.GetProductsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProducts", ctx)
+	ret0, _ := ret[0].(*models.GetProductsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProducts indicates an expected call of GetProducts.
+func (mr *MockInterestRepoIMockRecorder) GetProducts(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProducts", reflect.TypeOf((*MockInterestRepoI)(nil).GetProducts), ctx)
+}
+
+// MockJobRepoI is a mock of JobRepoI interface.
+type MockJobRepoI struct {
+	ctrl     *gomock.Controller
+	recorder *MockJobRepoIMockRecorder
+}
+
+// MockJobRepoIMockRecorder is the mock recorder for MockJobRepoI.
+type MockJobRepoIMockRecorder struct {
+	mock *MockJobRepoI
+}
+
+// NewMockJobRepoI creates a new mock instance.
+func NewMockJobRepoI(ctrl *gomock.Controller) *MockJobRepoI {
+	mock := &MockJobRepoI{ctrl: ctrl}
+	mock.recorder = &MockJobRepoIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockJobRepoI) EXPECT() *MockJobRepoIMockRecorder {
+	return m.recorder
+}
+
+// FinishJobRun mocks base method.
+func (m *MockJobRepoI) FinishJobRun(ctx context.Context, req *models.FinishJobRunRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishJobRun", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FinishJobRun indicates an expected call of FinishJobRun.
+func (mr *MockJobRepoIMockRecorder) FinishJobRun(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishJobRun", reflect.TypeOf((*MockJobRepoI)(nil).FinishJobRun), ctx, req)
+}
+
+// GetJobRunStatus mocks base method.
+func (m *MockJobRepoI) GetJobRunStatus(ctx context.Context, job, businessDate string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJobRunStatus", ctx, job, businessDate)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJobRunStatus indicates an expected call of GetJobRunStatus.
+func (mr *MockJobRepoIMockRecorder) GetJobRunStatus(ctx, job, businessDate interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJobRunStatus", reflect.TypeOf((*MockJobRepoI)(nil).GetJobRunStatus), ctx, job, businessDate)
+}
+
+// StartJobRun mocks base method.
+func (m *MockJobRepoI) StartJobRun(ctx context.Context, req *models.StartJobRunRequest) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartJobRun", ctx, req)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartJobRun indicates an expected call of StartJobRun.
+func (mr *MockJobRepoIMockRecorder) StartJobRun(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartJobRun", reflect.TypeOf((*MockJobRepoI)(nil).StartJobRun), ctx, req)
+}
+
+// MockDepositRepoI is a mock of DepositRepoI interface.
+type MockDepositRepoI struct {
+	ctrl     *gomock.Controller
+	recorder *MockDepositRepoIMockRecorder
+}
+
+// MockDepositRepoIMockRecorder is the mock recorder for MockDepositRepoI.
+type MockDepositRepoIMockRecorder struct {
+	mock *MockDepositRepoI
+}
+
+// NewMockDepositRepoI creates a new mock instance.
+func NewMockDepositRepoI(ctrl *gomock.Controller) *MockDepositRepoI {
+	mock := &MockDepositRepoI{ctrl: ctrl}
+	mock.recorder = &MockDepositRepoIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDepositRepoI) EXPECT() *MockDepositRepoIMockRecorder {
+	return m.recorder
+}
+
+// CreateDeposit mocks base method.
+func (m *MockDepositRepoI) CreateDeposit(ctx context.Context, tx *sql.Tx, req *models.TermDeposit) (*models.TermDeposit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDeposit", ctx, tx, req)
+	ret0, _ := ret[0].(*models.TermDeposit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateDeposit indicates an expected call of CreateDeposit.
+func (mr *MockDepositRepoIMockRecorder) CreateDeposit(ctx, tx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDeposit", reflect.TypeOf((*MockDepositRepoI)(nil).CreateDeposit), ctx, tx, req)
+}
+
+// GetDepositByID mocks base method.
+func (m *MockDepositRepoI) GetDepositByID(ctx context.Context, req *models.DepositByIDRequest) (*models.TermDeposit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDepositByID", ctx, req)
+	ret0, _ := ret[0].(*models.TermDeposit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDepositByID indicates an expected call of GetDepositByID.
+func (mr *MockDepositRepoIMockRecorder) GetDepositByID(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDepositByID", reflect.TypeOf((*MockDepositRepoI)(nil).GetDepositByID), ctx, req)
+}
+
+// GetDepositForUpdate mocks base method.
+func (m *MockDepositRepoI) GetDepositForUpdate(ctx context.Context, tx *sql.Tx, id string) (*models.TermDeposit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDepositForUpdate", ctx, tx, id)
+	ret0, _ := ret[0].(*models.TermDeposit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDepositForUpdate indicates an expected call of GetDepositForUpdate.
+func (mr *MockDepositRepoIMockRecorder) GetDepositForUpdate(ctx, tx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDepositForUpdate", reflect.TypeOf((*MockDepositRepoI)(nil).GetDepositForUpdate), ctx, tx, id)
+}
+
+// GetDepositTerm mocks base method.
+func (m *MockDepositRepoI) GetDepositTerm(ctx context.Context, termMonths int) (*models.DepositTerm, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDepositTerm", ctx, termMonths)
+	ret0, _ := ret[0].(*models.DepositTerm)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDepositTerm indicates an expected call of GetDepositTerm.
+func (mr *MockDepositRepoIMockRecorder) GetDepositTerm(ctx, termMonths interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDepositTerm", reflect.TypeOf((*MockDepositRepoI)(nil).GetDepositTerm), ctx, termMonths)
+}
+
+// GetDepositTerms mocks base method.
+func (m *MockDepositRepoI) GetDepositTerms(ctx context.Context) (*models.GetDepositTermsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDepositTerms", ctx)
+	ret0, _ := ret[0].(*models.GetDepositTermsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDepositTerms indicates an expected call of GetDepositTerms.
+func (mr *MockDepositRepoIMockRecorder) GetDepositTerms(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDepositTerms", reflect.TypeOf((*MockDepositRepoI)(nil).GetDepositTerms), ctx)
+}
+
+// GetDeposits mocks base method.
+func (m *MockDepositRepoI) GetDeposits(ctx context.Context, req *models.GetDepositsRequest) (*models.GetDepositsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeposits", ctx, req)
+	ret0, _ := ret[0].(*models.GetDepositsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeposits indicates an expected call of GetDeposits.
+func (mr *MockDepositRepoIMockRecorder) GetDeposits(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeposits", reflect.TypeOf((*MockDepositRepoI)(nil).GetDeposits), ctx, req)
+}
+
+// GetDueDepositIDs mocks base method.
+func (m *MockDepositRepoI) GetDueDepositIDs(ctx context.Context, dueDate string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDueDepositIDs", ctx, dueDate)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDueDepositIDs indicates an expected call of GetDueDepositIDs.
+func (mr *MockDepositRepoIMockRecorder) GetDueDepositIDs(ctx, dueDate interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDueDepositIDs", reflect.TypeOf((*MockDepositRepoI)(nil).GetDueDepositIDs), ctx, dueDate)
+}
+
+// UpdateDeposit mocks base method.
+func (m *MockDepositRepoI) UpdateDeposit(ctx context.Context, tx *sql.Tx, req *models.TermDeposit) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDeposit", ctx, tx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateDeposit indicates an expected call of UpdateDeposit.
+func (mr *MockDepositRepoIMockRecorder) UpdateDeposit(ctx, tx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDeposit", reflect.TypeOf((*MockDepositRepoI)(nil).UpdateDeposit), ctx, tx, req)
+}
+
+// MockOverdraftRepoI is a mock of OverdraftRepoI interface.
+type MockOverdraftRepoI struct {
+	ctrl     *gomock.Controller
+	recorder *MockOverdraftRepoIMockRecorder
+}
+
+// MockOverdraftRepoIMockRecorder is the mock recorder for MockOverdraftRepoI.
+type MockOverdraftRepoIMockRecorder struct {
+	mock *MockOverdraftRepoI
+}
+
+// NewMockOverdraftRepoI creates a new mock instance.
+func NewMockOverdraftRepoI(ctrl *gomock.Controller) *MockOverdraftRepoI {
+	mock := &MockOverdraftRepoI{ctrl: ctrl}
+	mock.recorder = &MockOverdraftRepoIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOverdraftRepoI) EXPECT() *MockOverdraftRepoIMockRecorder {
+	return m.recorder
+}
+
+// CreateOverdraftAccrual mocks base method.
+func (m *MockOverdraftRepoI) CreateOverdraftAccrual(ctx context.Context, req *models.InterestAccrual) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOverdraftAccrual", ctx, req)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOverdraftAccrual indicates an expected call of CreateOverdraftAccrual.
+func (mr *MockOverdraftRepoIMockRecorder) CreateOverdraftAccrual(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOverdraftAccrual", reflect.TypeOf((*MockOverdraftRepoI)(nil).CreateOverdraftAccrual), ctx, req)
+}
+
+// CreateOverdraftCharge mocks base method.
+func (m *MockOverdraftRepoI) CreateOverdraftCharge(ctx context.Context, tx *sql.Tx, req *models.InterestCapitalization) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOverdraftCharge", ctx, tx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateOverdraftCharge indicates an expected call of CreateOverdraftCharge.
+func (mr *MockOverdraftRepoIMockRecorder) CreateOverdraftCharge(ctx, tx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOverdraftCharge", reflect.TypeOf((*MockOverdraftRepoI)(nil).CreateOverdraftCharge), ctx, tx, req)
+}
+
+// GetOverdraftAccrualCandidates mocks base method.
+func (m *MockOverdraftRepoI) GetOverdraftAccrualCandidates(ctx context.Context, req *models.GetAccrualCandidatesRequest) ([]*models.AccrualCandidate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOverdraftAccrualCandidates", ctx, req)
+	ret0, _ := ret[0].([]*models.AccrualCandidate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOverdraftAccrualCandidates indicates an expected call of GetOverdraftAccrualCandidates.
+func (mr *MockOverdraftRepoIMockRecorder) GetOverdraftAccrualCandidates(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOverdraftAccrualCandidates", reflect.TypeOf((*MockOverdraftRepoI)(nil).GetOverdraftAccrualCandidates), ctx, req)
+}
+
+// GetOverdraftChargeCandidates mocks base method.
+func (m *MockOverdraftRepoI) GetOverdraftChargeCandidates(ctx context.Context, req *models.GetCapitalizationCandidatesRequest) ([]*models.OverdraftChargeCandidate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOverdraftChargeCandidates", ctx, req)
+	ret0, _ := ret[0].([]*models.OverdraftChargeCandidate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOverdraftChargeCandidates indicates an expected call of GetOverdraftChargeCandidates.
+func (mr *MockOverdraftRepoIMockRecorder) GetOverdraftChargeCandidates(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOverdraftChargeCandidates", reflect.TypeOf((*MockOverdraftRepoI)(nil).GetOverdraftChargeCandidates), ctx, req)
+}
+
+// GetOverdraftUsage mocks base method.
+func (m *MockOverdraftRepoI) GetOverdraftUsage(ctx context.Context, accountID string) (*models.OverdraftUsage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOverdraftUsage", ctx, accountID)
+	ret0, _ := ret[0].(*models.OverdraftUsage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOverdraftUsage indicates an expected call of GetOverdraftUsage.
+func (mr *MockOverdraftRepoIMockRecorder) GetOverdraftUsage(ctx, accountID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOverdraftUsage", reflect.TypeOf((*MockOverdraftRepoI)(nil).GetOverdraftUsage), ctx, accountID)
+}
+
+// SetOverdraftLimit mocks base method.
+func (m *MockOverdraftRepoI) SetOverdraftLimit(ctx context.Context, req *models.SetOverdraftLimitRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetOverdraftLimit", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetOverdraftLimit indicates an expected call of SetOverdraftLimit.
+func (mr *MockOverdraftRepoIMockRecorder) SetOverdraftLimit(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetOverdraftLimit", reflect.TypeOf((*MockOverdraftRepoI)(nil).SetOverdraftLimit), ctx, req)
+}
+
+// MockLoanRepoI is a mock of LoanRepoI interface.
+type MockLoanRepoI struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoanRepoIMockRecorder
+}
+
+// MockLoanRepoIMockRecorder is the mock recorder for MockLoanRepoI.
+type MockLoanRepoIMockRecorder struct {
+	mock *MockLoanRepoI
+}
+
+// NewMockLoanRepoI creates a new mock instance.
+func NewMockLoanRepoI(ctrl *gomock.Controller) *MockLoanRepoI {
+	mock := &MockLoanRepoI{ctrl: ctrl}
+	mock.recorder = &MockLoanRepoIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLoanRepoI) EXPECT() *MockLoanRepoIMockRecorder {
+	return m.recorder
+}
+
+// CreateInstallments mocks base method.
+func (m *MockLoanRepoI) CreateInstallments(ctx context.Context, tx *sql.Tx, installments []*models.LoanInstallment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateInstallments", ctx, tx, installments)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateInstallments indicates an expected call of CreateInstallments.
+func (mr *MockLoanRepoIMockRecorder) CreateInstallments(ctx, tx, installments interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInstallments", reflect.TypeOf((*MockLoanRepoI)(nil).CreateInstallments), ctx, tx, installments)
+}
+
+// CreateLoan mocks base method.
+func (m *MockLoanRepoI) CreateLoan(ctx context.Context, tx *sql.Tx, req *models.Loan) (*models.Loan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateLoan", ctx, tx, req)
+	ret0, _ := ret[0].(*models.Loan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateLoan indicates an expected call of CreateLoan.
+func (mr *MockLoanRepoIMockRecorder) CreateLoan(ctx, tx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLoan", reflect.TypeOf((*MockLoanRepoI)(nil).CreateLoan), ctx, tx, req)
+}
+
+// DeletePendingInstallments mocks base method.
+func (m *MockLoanRepoI) DeletePendingInstallments(ctx context.Context, tx *sql.Tx, loanID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePendingInstallments", ctx, tx, loanID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePendingInstallments indicates an expected call of DeletePendingInstallments.
+func (mr *MockLoanRepoIMockRecorder) DeletePendingInstallments(ctx, tx, loanID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePendingInstallments", reflect.TypeOf((*MockLoanRepoI)(nil).DeletePendingInstallments), ctx, tx, loanID)
+}
+
+// GetDueLoanIDs mocks base method.
+func (m *MockLoanRepoI) GetDueLoanIDs(ctx context.Context, businessDate string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDueLoanIDs", ctx, businessDate)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDueLoanIDs indicates an expected call of GetDueLoanIDs.
+func (mr *MockLoanRepoIMockRecorder) GetDueLoanIDs(ctx, businessDate interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDueLoanIDs", reflect.TypeOf((*MockLoanRepoI)(nil).GetDueLoanIDs), ctx, businessDate)
+}
+
+// GetLoanByID mocks base method.
+func (m *MockLoanRepoI) GetLoanByID(ctx context.Context, req *models.LoanByIDRequest) (*models.Loan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoanByID", ctx, req)
+	ret0, _ := ret[0].(*models.Loan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoanByID indicates an expected call of GetLoanByID.
+func (mr *MockLoanRepoIMockRecorder) GetLoanByID(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoanByID", reflect.TypeOf((*MockLoanRepoI)(nil).GetLoanByID), ctx, req)
+}
+
+// GetLoanForUpdate mocks base method.
+func (m *MockLoanRepoI) GetLoanForUpdate(ctx context.Context, tx *sql.Tx, id string) (*models.Loan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoanForUpdate", ctx, tx, id)
+	ret0, _ := ret[0].(*models.Loan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoanForUpdate indicates an expected call of GetLoanForUpdate.
+func (mr *MockLoanRepoIMockRecorder) GetLoanForUpdate(ctx, tx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoanForUpdate", reflect.TypeOf((*MockLoanRepoI)(nil).GetLoanForUpdate), ctx, tx, id)
+}
+
+// GetLoanProduct mocks base method.
+func (m *MockLoanRepoI) GetLoanProduct(ctx context.Context, code string) (*models.LoanProduct, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoanProduct", ctx, code)
+	ret0, _ := ret[0].(*models.LoanProduct)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoanProduct indicates an expected call of GetLoanProduct.
+func (mr *MockLoanRepoIMockRecorder) GetLoanProduct(ctx, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoanProduct", reflect.TypeOf((*MockLoanRepoI)(nil).GetLoanProduct), ctx, code)
+}
+
+// GetLoanProducts mocks base method.
+func (m *MockLoanRepoI) GetLoanProducts(ctx context.Context) (*models.GetLoanProductsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoanProducts", ctx)
+	ret0, _ := ret[0].(*models.GetLoanProductsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoanProducts indicates an expected call of GetLoanProducts.
+func (mr *MockLoanRepoIMockRecorder) GetLoanProducts(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoanProducts", reflect.TypeOf((*MockLoanRepoI)(nil).GetLoanProducts), ctx)
+}
+
+// GetLoans mocks base method.
+func (m *MockLoanRepoI) GetLoans(ctx context.Context, req *models.GetLoansRequest) (*models.GetLoansResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoans", ctx, req)
+	ret0, _ := ret[0].(*models.GetLoansResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoans indicates an expected call of GetLoans.
+func (mr *MockLoanRepoIMockRecorder) GetLoans(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoans", reflect.TypeOf((*MockLoanRepoI)(nil).GetLoans), ctx, req)
+}
+
+// UpdateInstallment mocks base method.
+func (m *MockLoanRepoI) UpdateInstallment(ctx context.Context, tx *sql.Tx, req *models.LoanInstallment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateInstallment", ctx, tx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateInstallment indicates an expected call of UpdateInstallment.
+func (mr *MockLoanRepoIMockRecorder) UpdateInstallment(ctx, tx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateInstallment", reflect.TypeOf((*MockLoanRepoI)(nil).UpdateInstallment), ctx, tx, req)
+}
+
+// UpdateLoan mocks base method.
+func (m *MockLoanRepoI) UpdateLoan(ctx context.Context, tx *sql.Tx, req *models.Loan) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateLoan", ctx, tx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateLoan indicates an expected call of UpdateLoan.
+func (mr *MockLoanRepoIMockRecorder) UpdateLoan(ctx, tx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateLoan", reflect.TypeOf((*MockLoanRepoI)(nil).UpdateLoan), ctx, tx, req)
+}
+
+// MockPotRepoI is a mock of PotRepoI interface.
+type MockPotRepoI struct {
+	ctrl     *gomock.Controller
+	recorder *MockPotRepoIMockRecorder
+}
+
+// MockPotRepoIMockRecorder is the mock recorder for MockPotRepoI.
+type MockPotRepoIMockRecorder struct {
+	mock *MockPotRepoI
+}
+
+// NewMockPotRepoI creates a new mock instance.
+func NewMockPotRepoI(ctrl *gomock.Controller) *MockPotRepoI {
+	mock := &MockPotRepoI{ctrl: ctrl}
+	mock.recorder = &MockPotRepoIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPotRepoI) EXPECT() *MockPotRepoIMockRecorder {
+	return m.recorder
+}
+
+// ClosePot mocks base method.
+func (m *MockPotRepoI) ClosePot(ctx context.Context, tx *sql.Tx, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClosePot", ctx, tx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClosePot indicates an expected call of ClosePot.
+func (mr *MockPotRepoIMockRecorder) ClosePot(ctx, tx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClosePot", reflect.TypeOf((*MockPotRepoI)(nil).ClosePot), ctx, tx, id)
+}
+
+// CreatePot mocks base method.
+func (m *MockPotRepoI) CreatePot(ctx context.Context, tx *sql.Tx, req *models.Pot) (*models.Pot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePot", ctx, tx, req)
+	ret0, _ := ret[0].(*models.Pot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePot indicates an expected call of CreatePot.
+func (mr *MockPotRepoIMockRecorder) CreatePot(ctx, tx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePot", reflect.TypeOf((*MockPotRepoI)(nil).CreatePot), ctx, tx, req)
+}
+
+// GetPotByID mocks base method.
+func (m *MockPotRepoI) GetPotByID(ctx context.Context, req *models.PotByIDRequest) (*models.Pot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPotByID", ctx, req)
+	ret0, _ := ret[0].(*models.Pot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPotByID indicates an expected call of GetPotByID.
+func (mr *MockPotRepoIMockRecorder) GetPotByID(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPotByID", reflect.TypeOf((*MockPotRepoI)(nil).GetPotByID), ctx, req)
+}
+
+// GetPotForUpdate mocks base method.
+func (m *MockPotRepoI) GetPotForUpdate(ctx context.Context, tx *sql.Tx, id string) (*models.Pot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPotForUpdate", ctx, tx, id)
+	ret0, _ := ret[0].(*models.Pot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPotForUpdate indicates an expected call of GetPotForUpdate.
+func (mr *MockPotRepoIMockRecorder) GetPotForUpdate(ctx, tx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPotForUpdate", reflect.TypeOf((*MockPotRepoI)(nil).GetPotForUpdate), ctx, tx, id)
+}
+
+// GetPots mocks base method.
+func (m *MockPotRepoI) GetPots(ctx context.Context, req *models.GetPotsRequest) ([]*models.Pot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPots", ctx, req)
+	ret0, _ := ret[0].([]*models.Pot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPots indicates an expected call of GetPots.
+func (mr *MockPotRepoIMockRecorder) GetPots(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPots", reflect.TypeOf((*MockPotRepoI)(nil).GetPots), ctx, req)
+}
+
+// GetRoundUpPot mocks base method.
+func (m *MockPotRepoI) GetRoundUpPot(ctx context.Context, accountID string) (*models.Pot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoundUpPot", ctx, accountID)
+	ret0, _ := ret[0].(*models.Pot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRoundUpPot indicates an expected call of GetRoundUpPot.
+func (mr *MockPotRepoIMockRecorder) GetRoundUpPot(ctx, accountID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoundUpPot", reflect.TypeOf((*MockPotRepoI)(nil).GetRoundUpPot), ctx, accountID)
+}
+
+// UpdatePot mocks base method.
+func (m *MockPotRepoI) UpdatePot(ctx context.Context, tx *sql.Tx, req *models.Pot) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePot", ctx, tx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePot indicates an expected call of UpdatePot.
+func (mr *MockPotRepoIMockRecorder) UpdatePot(ctx, tx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePot", reflect.TypeOf((*MockPotRepoI)(nil).UpdatePot), ctx, tx, req)
+}
+
+// MockAccountHolderRepoI is a mock of AccountHolderRepoI interface.
+type MockAccountHolderRepoI struct {
+	ctrl     *gomock.Controller
+	recorder *MockAccountHolderRepoIMockRecorder
+}
+
+// MockAccountHolderRepoIMockRecorder is the mock recorder for MockAccountHolderRepoI.
+type MockAccountHolderRepoIMockRecorder struct {
+	mock *MockAccountHolderRepoI
+}
+
+// NewMockAccountHolderRepoI creates a new mock instance.
+func NewMockAccountHolderRepoI(ctrl *gomock.Controller) *MockAccountHolderRepoI {
+	mock := &MockAccountHolderRepoI{ctrl: ctrl}
+	mock.recorder = &MockAccountHolderRepoIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAccountHolderRepoI) EXPECT() *MockAccountHolderRepoIMockRecorder {
+	return m.recorder
+}
+
+// AddAccountHolder mocks base method.
+func (m *MockAccountHolderRepoI) AddAccountHolder(ctx context.Context, tx *sql.Tx, accountID, userID, role string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddAccountHolder", ctx, tx, accountID, userID, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddAccountHolder indicates an expected call of AddAccountHolder.
+func (mr *MockAccountHolderRepoIMockRecorder) AddAccountHolder(ctx, tx, accountID, userID, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAccountHolder", reflect.TypeOf((*MockAccountHolderRepoI)(nil).AddAccountHolder), ctx, tx, accountID, userID, role)
+}
+
+// CreateInvitation mocks base method.
+func (m *MockAccountHolderRepoI) CreateInvitation(ctx context.Context, tx *sql.Tx, req *models.AccountInvitation) (*models.AccountInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateInvitation", ctx, tx, req)
+	ret0, _ := ret[0].(*models.AccountInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateInvitation indicates an expected call of CreateInvitation.
+func (mr *MockAccountHolderRepoIMockRecorder) CreateInvitation(ctx, tx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInvitation", reflect.TypeOf((*MockAccountHolderRepoI)(nil).CreateInvitation), ctx, tx, req)
+}
+
+// CreateTransferApproval mocks base method.
+func (m *MockAccountHolderRepoI) CreateTransferApproval(ctx context.Context, tx *sql.Tx, req *models.TransferApproval) (*models.TransferApproval, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransferApproval", ctx, tx, req)
+	ret0, _ := ret[0].(*models.TransferApproval)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTransferApproval indicates an expected call of CreateTransferApproval.
+func (mr *MockAccountHolderRepoIMockRecorder) CreateTransferApproval(ctx, tx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransferApproval", reflect.TypeOf((*MockAccountHolderRepoI)(nil).CreateTransferApproval), ctx, tx, req)
+}
+
+// DeleteAccountHolder mocks base method.
+func (m *MockAccountHolderRepoI) DeleteAccountHolder(ctx context.Context, tx *sql.Tx, accountID, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccountHolder", ctx, tx, accountID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAccountHolder indicates an expected call of DeleteAccountHolder.
+func (mr *MockAccountHolderRepoIMockRecorder) DeleteAccountHolder(ctx, tx, accountID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccountHolder", reflect.TypeOf((*MockAccountHolderRepoI)(nil).DeleteAccountHolder), ctx, tx, accountID, userID)
+}
+
+// DeleteUserHoldings mocks base method.
+func (m *MockAccountHolderRepoI) DeleteUserHoldings(ctx context.Context, tx *sql.Tx, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserHoldings", ctx, tx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserHoldings indicates an expected call of DeleteUserHoldings.
+func (mr *MockAccountHolderRepoIMockRecorder) DeleteUserHoldings(ctx, tx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserHoldings", reflect.TypeOf((*MockAccountHolderRepoI)(nil).DeleteUserHoldings), ctx, tx, userID)
+}
+
+// GetAccountHolders mocks base method.
+func (m *MockAccountHolderRepoI) GetAccountHolders(ctx context.Context, accountID string) (*models.GetAccountHoldersResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountHolders", ctx, accountID)
+	ret0, _ := ret[0].(*models.GetAccountHoldersResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountHolders indicates an expected call of GetAccountHolders.
+func (mr *MockAccountHolderRepoIMockRecorder) GetAccountHolders(ctx, accountID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountHolders", reflect.TypeOf((*MockAccountHolderRepoI)(nil).GetAccountHolders), ctx, accountID)
+}
+
+// GetAccountRole mocks base method.
+func (m *MockAccountHolderRepoI) GetAccountRole(ctx context.Context, accountID, userID string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountRole", ctx, accountID, userID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountRole indicates an expected call of GetAccountRole.
+func (mr *MockAccountHolderRepoIMockRecorder) GetAccountRole(ctx, accountID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountRole", reflect.TypeOf((*MockAccountHolderRepoI)(nil).GetAccountRole), ctx, accountID, userID)
+}
+
+// GetAccountRoleForUpdate mocks base method.
+func (m *MockAccountHolderRepoI) GetAccountRoleForUpdate(ctx context.Context, tx *sql.Tx, accountID, userID string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountRoleForUpdate", ctx, tx, accountID, userID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountRoleForUpdate indicates an expected call of GetAccountRoleForUpdate.
+func (mr *MockAccountHolderRepoIMockRecorder) GetAccountRoleForUpdate(ctx, tx, accountID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountRoleForUpdate", reflect.TypeOf((*MockAccountHolderRepoI)(nil).GetAccountRoleForUpdate), ctx, tx, accountID, userID)
+}
+
+// GetApprovalPolicy mocks base method.
+func (m *MockAccountHolderRepoI) GetApprovalPolicy(ctx context.Context, accountID, userID string) (*models.ApprovalPolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetApprovalPolicy", ctx, accountID, userID)
+	ret0, _ := ret[0].(*models.ApprovalPolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetApprovalPolicy indicates an expected call of GetApprovalPolicy.
+func (mr *MockAccountHolderRepoIMockRecorder) GetApprovalPolicy(ctx, accountID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApprovalPolicy", reflect.TypeOf((*MockAccountHolderRepoI)(nil).GetApprovalPolicy), ctx, accountID, userID)
+}
+
+// GetInvitationForUpdate mocks base method.
+func (m *MockAccountHolderRepoI) GetInvitationForUpdate(ctx context.Context, tx *sql.Tx, id string) (*models.AccountInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInvitationForUpdate", ctx, tx, id)
+	ret0, _ := ret[0].(*models.AccountInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInvitationForUpdate indicates an expected call of GetInvitationForUpdate.
+func (mr *MockAccountHolderRepoIMockRecorder) GetInvitationForUpdate(ctx, tx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInvitationForUpdate", reflect.TypeOf((*MockAccountHolderRepoI)(nil).GetInvitationForUpdate), ctx, tx, id)
+}
+
+// GetInvitations mocks base method.
+func (m *MockAccountHolderRepoI) GetInvitations(ctx context.Context, userID string) (*models.GetInvitationsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInvitations", ctx, userID)
+	ret0, _ := ret[0].(*models.GetInvitationsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInvitations indicates an expected call of GetInvitations.
+func (mr *MockAccountHolderRepoIMockRecorder) GetInvitations(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInvitations", reflect.TypeOf((*MockAccountHolderRepoI)(nil).GetInvitations), ctx, userID)
+}
+
+// GetTransferApprovalForUpdate mocks base method.
+func (m *MockAccountHolderRepoI) GetTransferApprovalForUpdate(ctx context.Context, tx *sql.Tx, req *models.TransferApprovalByIDRequest) (*models.TransferApproval, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransferApprovalForUpdate", ctx, tx, req)
+	ret0, _ := ret[0].(*models.TransferApproval)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransferApprovalForUpdate indicates an expected call of GetTransferApprovalForUpdate.
+func (mr *MockAccountHolderRepoIMockRecorder) GetTransferApprovalForUpdate(ctx, tx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransferApprovalForUpdate", reflect.TypeOf((*MockAccountHolderRepoI)(nil).GetTransferApprovalForUpdate), ctx, tx, req)
+}
+
+// GetTransferApprovals mocks base method.
+func (m *MockAccountHolderRepoI) GetTransferApprovals(ctx context.Context, req *models.GetTransferApprovalsRequest) (*models.GetTransferApprovalsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransferApprovals", ctx, req)
+	ret0, _ := ret[0].(*models.GetTransferApprovalsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransferApprovals indicates an expected call of GetTransferApprovals.
+func (mr *MockAccountHolderRepoIMockRecorder) GetTransferApprovals(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransferApprovals", reflect.TypeOf((*MockAccountHolderRepoI)(nil).GetTransferApprovals), ctx, req)
+}
+
+// SetDualApprovalThreshold mocks base method.
+func (m *MockAccountHolderRepoI) SetDualApprovalThreshold(ctx context.Context, tx *sql.Tx, accountID, threshold string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetDualApprovalThreshold", ctx, tx, accountID, threshold)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetDualApprovalThreshold indicates an expected call of SetDualApprovalThreshold.
+func (mr *MockAccountHolderRepoIMockRecorder) SetDualApprovalThreshold(ctx, tx, accountID, threshold interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDualApprovalThreshold", reflect.TypeOf((*MockAccountHolderRepoI)(nil).SetDualApprovalThreshold), ctx, tx, accountID, threshold)
+}
+
+// UpdateAccountHolder mocks base method.
+func (m *MockAccountHolderRepoI) UpdateAccountHolder(ctx context.Context, tx *sql.Tx, accountID, userID, role string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAccountHolder", ctx, tx, accountID, userID, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAccountHolder indicates an expected call of UpdateAccountHolder.
+func (mr *MockAccountHolderRepoIMockRecorder) UpdateAccountHolder(ctx, tx, accountID, userID, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAccountHolder", reflect.TypeOf((*MockAccountHolderRepoI)(nil).UpdateAccountHolder), ctx, tx, accountID, userID, role)
+}
+
+// UpdateInvitationStatus mocks base method.
+func (m *MockAccountHolderRepoI) UpdateInvitationStatus(ctx context.Context, tx *sql.Tx, id, status string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateInvitationStatus", ctx, tx, id, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateInvitationStatus indicates an expected call of UpdateInvitationStatus.
+func (mr *MockAccountHolderRepoIMockRecorder) UpdateInvitationStatus(ctx, tx, id, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateInvitationStatus", reflect.TypeOf((*MockAccountHolderRepoI)(nil).UpdateInvitationStatus), ctx, tx, id, status)
+}
+
+// UpdateTransferApproval mocks base method.
+func (m *MockAccountHolderRepoI) UpdateTransferApproval(ctx context.Context, tx *sql.Tx, req *models.TransferApproval) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTransferApproval", ctx, tx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTransferApproval indicates an expected call of UpdateTransferApproval.
+func (mr *MockAccountHolderRepoIMockRecorder) UpdateTransferApproval(ctx, tx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTransferApproval", reflect.TypeOf((*MockAccountHolderRepoI)(nil).UpdateTransferApproval), ctx, tx, req)
+}
+
+// MockOTPRepoI is a mock of OTPRepoI interface.
+type MockOTPRepoI struct {
+	ctrl     *gomock.Controller
+	recorder *MockOTPRepoIMockRecorder
+}
+
+// MockOTPRepoIMockRecorder is the mock recorder for MockOTPRepoI.
+type MockOTPRepoIMockRecorder struct {
+	mock *MockOTPRepoI
+}
+
+// NewMockOTPRepoI creates a new mock instance.
+func NewMockOTPRepoI(ctrl *gomock.Controller) *MockOTPRepoI {
+	mock := &MockOTPRepoI{ctrl: ctrl}
+	mock.recorder = &MockOTPRepoIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOTPRepoI) EXPECT() *MockOTPRepoIMockRecorder {
+	return m.recorder
+}
+
+// CreateOTP mocks base method.
+func (m *MockOTPRepoI) CreateOTP(ctx context.Context, req *models.OTPCode) (*models.OTPCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOTP", ctx, req)
+	ret0, _ := ret[0].(*models.OTPCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOTP indicates an expected call of CreateOTP.
+func (mr *MockOTPRepoIMockRecorder) CreateOTP(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOTP", reflect.TypeOf((*MockOTPRepoI)(nil).CreateOTP), ctx, req)
+}
+
+// GetActiveOTPForUpdate mocks base method.
+func (m *MockOTPRepoI) GetActiveOTPForUpdate(ctx context.Context, tx *sql.Tx, userID, purpose string) (*models.OTPCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActiveOTPForUpdate", ctx, tx, userID, purpose)
+	ret0, _ := ret[0].(*models.OTPCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActiveOTPForUpdate indicates an expected call of GetActiveOTPForUpdate.
+func (mr *MockOTPRepoIMockRecorder) GetActiveOTPForUpdate(ctx, tx, userID, purpose interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveOTPForUpdate", reflect.TypeOf((*MockOTPRepoI)(nil).GetActiveOTPForUpdate), ctx, tx, userID, purpose)
+}
+
+// IncrementOTPAttempts mocks base method.
+func (m *MockOTPRepoI) IncrementOTPAttempts(ctx context.Context, tx *sql.Tx, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrementOTPAttempts", ctx, tx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IncrementOTPAttempts indicates an expected call of IncrementOTPAttempts.
+func (mr *MockOTPRepoIMockRecorder) IncrementOTPAttempts(ctx, tx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementOTPAttempts", reflect.TypeOf((*MockOTPRepoI)(nil).IncrementOTPAttempts), ctx, tx, id)
+}
+
+// UseOTP mocks base method.
+func (m *MockOTPRepoI) UseOTP(ctx context.Context, tx *sql.Tx, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseOTP", ctx, tx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseOTP indicates an expected call of UseOTP.
+func (mr *MockOTPRepoIMockRecorder) UseOTP(ctx, tx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseOTP", reflect.TypeOf((*MockOTPRepoI)(nil).UseOTP), ctx, tx, id)
+}
+
+// MockTwoFactorRepoI is a mock of TwoFactorRepoI interface.
+type MockTwoFactorRepoI struct {
+	ctrl     *gomock.Controller
+	recorder *MockTwoFactorRepoIMockRecorder
+}
+
+// MockTwoFactorRepoIMockRecorder is the mock recorder for MockTwoFactorRepoI.
+type MockTwoFactorRepoIMockRecorder struct {
+	mock *MockTwoFactorRepoI
+}
+
+// NewMockTwoFactorRepoI creates a new mock instance.
+func NewMockTwoFactorRepoI(ctrl *gomock.Controller) *MockTwoFactorRepoI {
+	mock := &MockTwoFactorRepoI{ctrl: ctrl}
+	mock.recorder = &MockTwoFactorRepoIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTwoFactorRepoI) EXPECT() *MockTwoFactorRepoIMockRecorder {
+	return m.recorder
+}
+
+// DisableTOTP mocks base method.
+func (m *MockTwoFactorRepoI) DisableTOTP(ctx context.Context, tx *sql.Tx, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableTOTP", ctx, tx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableTOTP indicates an expected call of DisableTOTP.
+func (mr *MockTwoFactorRepoIMockRecorder) DisableTOTP(ctx, tx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableTOTP", reflect.TypeOf((*MockTwoFactorRepoI)(nil).DisableTOTP), ctx, tx, userID)
+}
+
+// EnableTOTP mocks base method.
+func (m *MockTwoFactorRepoI) EnableTOTP(ctx context.Context, tx *sql.Tx, userID string, step int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableTOTP", ctx, tx, userID, step)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnableTOTP indicates an expected call of EnableTOTP.
+func (mr *MockTwoFactorRepoIMockRecorder) EnableTOTP(ctx, tx, userID, step interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableTOTP", reflect.TypeOf((*MockTwoFactorRepoI)(nil).EnableTOTP), ctx, tx, userID, step)
+}
+
+// GetTOTPForUpdate mocks base method.
+func (m *MockTwoFactorRepoI) GetTOTPForUpdate(ctx context.Context, tx *sql.Tx, userID string) (*models.TOTPSettings, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTOTPForUpdate", ctx, tx, userID)
+	ret0, _ := ret[0].(*models.TOTPSettings)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTOTPForUpdate indicates an expected call of GetTOTPForUpdate.
+func (mr *MockTwoFactorRepoIMockRecorder) GetTOTPForUpdate(ctx, tx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTOTPForUpdate", reflect.TypeOf((*MockTwoFactorRepoI)(nil).GetTOTPForUpdate), ctx, tx, userID)
+}
+
+// ReplaceRecoveryCodes mocks base method.
+func (m *MockTwoFactorRepoI) ReplaceRecoveryCodes(ctx context.Context, tx *sql.Tx, userID string, hashes []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceRecoveryCodes", ctx, tx, userID, hashes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceRecoveryCodes indicates an expected call of ReplaceRecoveryCodes.
+func (mr *MockTwoFactorRepoIMockRecorder) ReplaceRecoveryCodes(ctx, tx, userID, hashes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceRecoveryCodes", reflect.TypeOf((*MockTwoFactorRepoI)(nil).ReplaceRecoveryCodes), ctx, tx, userID, hashes)
+}
+
+// SetTOTPSecret mocks base method.
+func (m *MockTwoFactorRepoI) SetTOTPSecret(ctx context.Context, userID, secret string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTOTPSecret", ctx, userID, secret)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTOTPSecret indicates an expected call of SetTOTPSecret.
+func (mr *MockTwoFactorRepoIMockRecorder) SetTOTPSecret(ctx, userID, secret interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTOTPSecret", reflect.TypeOf((*MockTwoFactorRepoI)(nil).SetTOTPSecret), ctx, userID, secret)
+}
+
+// UpdateTOTPLastStep mocks base method.
+func (m *MockTwoFactorRepoI) UpdateTOTPLastStep(ctx context.Context, tx *sql.Tx, userID string, step int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTOTPLastStep", ctx, tx, userID, step)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTOTPLastStep indicates an expected call of UpdateTOTPLastStep.
+func (mr *MockTwoFactorRepoIMockRecorder) UpdateTOTPLastStep(ctx, tx, userID, step interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTOTPLastStep", reflect.TypeOf((*MockTwoFactorRepoI)(nil).UpdateTOTPLastStep), ctx, tx, userID, step)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockTwoFactorRepoI) UseRecoveryCode(ctx context.Context, tx *sql.Tx, userID, hash string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", ctx, tx, userID, hash)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockTwoFactorRepoIMockRecorder) UseRecoveryCode(ctx, tx, userID, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockTwoFactorRepoI)(nil).UseRecoveryCode), ctx, tx, userID, hash)
+}
+
+// MockLoginFailureRepoI is a mock of LoginFailureRepoI interface.
+type MockLoginFailureRepoI struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoginFailureRepoIMockRecorder
+}
+
+// MockLoginFailureRepoIMockRecorder is the mock recorder for MockLoginFailureRepoI.
+type MockLoginFailureRepoIMockRecorder struct {
+	mock *MockLoginFailureRepoI
+}
+
+// NewMockLoginFailureRepoI creates a new mock instance.
+func NewMockLoginFailureRepoI(ctrl *gomock.Controller) *MockLoginFailureRepoI {
+	mock := &MockLoginFailureRepoI{ctrl: ctrl}
+	mock.recorder = &MockLoginFailureRepoIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLoginFailureRepoI) EXPECT() *MockLoginFailureRepoIMockRecorder {
+	return m.recorder
+}
+
+// GetLoginLock mocks base method.
+func (m *MockLoginFailureRepoI) GetLoginLock(ctx context.Context, keys []string) (time.Duration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoginLock", ctx, keys)
+	ret0, _ := ret[0].(time.Duration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoginLock indicates an expected call of GetLoginLock.
+func (mr *MockLoginFailureRepoIMockRecorder) GetLoginLock(ctx, keys interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginLock", reflect.TypeOf((*MockLoginFailureRepoI)(nil).GetLoginLock), ctx, keys)
+}
+
+// LockLogin mocks base method.
+func (m *MockLoginFailureRepoI) LockLogin(ctx context.Context, tx *sql.Tx, key string, duration time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockLogin", ctx, tx, key, duration)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockLogin indicates an expected call of LockLogin.
+func (mr *MockLoginFailureRepoIMockRecorder) LockLogin(ctx, tx, key, duration interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockLogin", reflect.TypeOf((*MockLoginFailureRepoI)(nil).LockLogin), ctx, tx, key, duration)
+}
+
+// RecordLoginFailure mocks base method.
+func (m *MockLoginFailureRepoI) RecordLoginFailure(ctx context.Context, tx *sql.Tx, key string, window time.Duration) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordLoginFailure", ctx, tx, key, window)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordLoginFailure indicates an expected call of RecordLoginFailure.
+func (mr *MockLoginFailureRepoIMockRecorder) RecordLoginFailure(ctx, tx, key, window interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordLoginFailure", reflect.TypeOf((*MockLoginFailureRepoI)(nil).RecordLoginFailure), ctx, tx, key, window)
+}
+
+// ResetLoginFailures mocks base method.
+func (m *MockLoginFailureRepoI) ResetLoginFailures(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetLoginFailures", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetLoginFailures indicates an expected call of ResetLoginFailures.
+func (mr *MockLoginFailureRepoIMockRecorder) ResetLoginFailures(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetLoginFailures", reflect.TypeOf((*MockLoginFailureRepoI)(nil).ResetLoginFailures), ctx, key)
+}
+
+// MockAuditRepoI is a mock of AuditRepoI interface.
+type MockAuditRepoI struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditRepoIMockRecorder
+}
+
+// MockAuditRepoIMockRecorder is the mock recorder for MockAuditRepoI.
+type MockAuditRepoIMockRecorder struct {
+	mock *MockAuditRepoI
+}
+
+// NewMockAuditRepoI creates a new mock instance.
+func NewMockAuditRepoI(ctrl *gomock.Controller) *MockAuditRepoI {
+	mock := &MockAuditRepoI{ctrl: ctrl}
+	mock.recorder = &MockAuditRepoIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditRepoI) EXPECT() *MockAuditRepoIMockRecorder {
+	return m.recorder
+}
+
+// BeginTx mocks base method.
+func (m *MockAuditRepoI) BeginTx(ctx context.Context) (*sql.Tx, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginTx", ctx)
+	ret0, _ := ret[0].(*sql.Tx)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BeginTx indicates an expected call of BeginTx.
+func (mr *MockAuditRepoIMockRecorder) BeginTx(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginTx", reflect.TypeOf((*MockAuditRepoI)(nil).BeginTx), ctx)
+}
+
+// CreateAuditEvent mocks base method.
+func (m *MockAuditRepoI) CreateAuditEvent(ctx context.Context, tx *sql.Tx, req *models.AuditEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAuditEvent", ctx, tx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAuditEvent indicates an expected call of CreateAuditEvent.
+func (mr *MockAuditRepoIMockRecorder) CreateAuditEvent(ctx, tx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAuditEvent", reflect.TypeOf((*MockAuditRepoI)(nil).CreateAuditEvent), ctx, tx, req)
+}
+
+// GetAuditChain mocks base method.
+func (m *MockAuditRepoI) GetAuditChain(ctx context.Context, afterSeq int64, limit int) ([]*models.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditChain", ctx, afterSeq, limit)
+	ret0, _ := ret[0].([]*models.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditChain indicates an expected call of GetAuditChain.
+func (mr *MockAuditRepoIMockRecorder) GetAuditChain(ctx, afterSeq, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditChain", reflect.TypeOf((*MockAuditRepoI)(nil).GetAuditChain), ctx, afterSeq, limit)
+}
+
+// GetAuditEvents mocks base method.
+func (m *MockAuditRepoI) GetAuditEvents(ctx context.Context, req *models.GetAuditEventsRequest) (*models.GetAuditEventsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditEvents", ctx, req)
+	ret0, _ := ret[0].(*models.GetAuditEventsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditEvents indicates an expected call of GetAuditEvents.
+func (mr *MockAuditRepoIMockRecorder) GetAuditEvents(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditEvents", reflect.TypeOf((*MockAuditRepoI)(nil).GetAuditEvents), ctx, req)
+}
+
+// MockAdminRepoI is a mock of AdminRepoI interface.
+type MockAdminRepoI struct {
+	ctrl     *gomock.Controller
+	recorder *MockAdminRepoIMockRecorder
+}
+
+// MockAdminRepoIMockRecorder is the mock recorder for MockAdminRepoI.
+type MockAdminRepoIMockRecorder struct {
+	mock *MockAdminRepoI
+}
+
+// NewMockAdminRepoI creates a new mock instance.
+func NewMockAdminRepoI(ctrl *gomock.Controller) *MockAdminRepoI {
+	mock := &MockAdminRepoI{ctrl: ctrl}
+	mock.recorder = &MockAdminRepoIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAdminRepoI) EXPECT() *MockAdminRepoIMockRecorder {
+	return m.recorder
+}
+
+// CreateManualAdjustment mocks base method.
+func (m *MockAdminRepoI) CreateManualAdjustment(ctx context.Context, tx *sql.Tx, req *models.ManualAdjustment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateManualAdjustment", ctx, tx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateManualAdjustment indicates an expected call of CreateManualAdjustment.
+func (mr *MockAdminRepoIMockRecorder) CreateManualAdjustment(ctx, tx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateManualAdjustment", reflect.TypeOf((*MockAdminRepoI)(nil).CreateManualAdjustment), ctx, tx, req)
+}
+
+// GetTransaction mocks base method.
+func (m *MockAdminRepoI) GetTransaction(ctx context.Context, id string) (*models.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransaction", ctx, id)
+	ret0, _ := ret[0].(*models.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransaction indicates an expected call of GetTransaction.
+func (mr *MockAdminRepoIMockRecorder) GetTransaction(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransaction", reflect.TypeOf((*MockAdminRepoI)(nil).GetTransaction), ctx, id)
+}
+
+// SearchAccounts mocks base method.
+func (m *MockAdminRepoI) SearchAccounts(ctx context.Context, req *models.SearchAccountsRequest) (*models.SearchAccountsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchAccounts", ctx, req)
+	ret0, _ := ret[0].(*models.SearchAccountsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchAccounts indicates an expected call of SearchAccounts.
+func (mr *MockAdminRepoIMockRecorder) SearchAccounts(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchAccounts", reflect.TypeOf((*MockAdminRepoI)(nil).SearchAccounts), ctx, req)
+}
+
+// SearchUsers mocks base method.
+func (m *MockAdminRepoI) SearchUsers(ctx context.Context, req *models.SearchUsersRequest) (*models.SearchUsersResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchUsers", ctx, req)
+	ret0, _ := ret[0].(*models.SearchUsersResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchUsers indicates an expected call of SearchUsers.
+func (mr *MockAdminRepoIMockRecorder) SearchUsers(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchUsers", reflect.TypeOf((*MockAdminRepoI)(nil).SearchUsers), ctx, req)
+}
+
+// MockOperationRepoI is a mock of OperationRepoI interface.
+type MockOperationRepoI struct {
+	ctrl     *gomock.Controller
+	recorder *MockOperationRepoIMockRecorder
+}
+
+// MockOperationRepoIMockRecorder is the mock recorder for MockOperationRepoI.
+type MockOperationRepoIMockRecorder struct {
+	mock *MockOperationRepoI
+}
+
+// NewMockOperationRepoI creates a new mock instance.
+func NewMockOperationRepoI(ctrl *gomock.Controller) *MockOperationRepoI {
+	mock := &MockOperationRepoI{ctrl: ctrl}
+	mock.recorder = &MockOperationRepoIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOperationRepoI) EXPECT() *MockOperationRepoIMockRecorder {
+	return m.recorder
+}
+
+// CreatePendingOperation mocks base method.
+func (m *MockOperationRepoI) CreatePendingOperation(ctx context.Context, tx *sql.Tx, req *models.PendingOperation) (*models.PendingOperation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePendingOperation", ctx, tx, req)
+	ret0, _ := ret[0].(*models.PendingOperation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePendingOperation indicates an expected call of CreatePendingOperation.
+func (mr *MockOperationRepoIMockRecorder) CreatePendingOperation(ctx, tx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePendingOperation", reflect.TypeOf((*MockOperationRepoI)(nil).CreatePendingOperation), ctx, tx, req)
+}
+
+// GetPendingOperation mocks base method.
+func (m *MockOperationRepoI) GetPendingOperation(ctx context.Context, id string) (*models.PendingOperation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPendingOperation", ctx, id)
+	ret0, _ := ret[0].(*models.PendingOperation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPendingOperation indicates an expected call of GetPendingOperation.
+func (mr *MockOperationRepoIMockRecorder) GetPendingOperation(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingOperation", reflect.TypeOf((*MockOperationRepoI)(nil).GetPendingOperation), ctx, id)
+}
+
+// GetPendingOperationForUpdate mocks base method.
+func (m *MockOperationRepoI) GetPendingOperationForUpdate(ctx context.Context, tx *sql.Tx, id string) (*models.PendingOperation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPendingOperationForUpdate", ctx, tx, id)
+	ret0, _ := ret[0].(*models.PendingOperation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPendingOperationForUpdate indicates an expected call of GetPendingOperationForUpdate.
+func (mr *MockOperationRepoIMockRecorder) GetPendingOperationForUpdate(ctx, tx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingOperationForUpdate", reflect.TypeOf((*MockOperationRepoI)(nil).GetPendingOperationForUpdate), ctx, tx, id)
+}
+
+// GetPendingOperations mocks base method.
+func (m *MockOperationRepoI) GetPendingOperations(ctx context.Context, req *models.GetPendingOperationsRequest) (*models.GetPendingOperationsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPendingOperations", ctx, req)
+	ret0, _ := ret[0].(*models.GetPendingOperationsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPendingOperations indicates an expected call of GetPendingOperations.
+func (mr *MockOperationRepoIMockRecorder) GetPendingOperations(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingOperations", reflect.TypeOf((*MockOperationRepoI)(nil).GetPendingOperations), ctx, req)
+}
+
+// UpdatePendingOperation mocks base method.
+func (m *MockOperationRepoI) UpdatePendingOperation(ctx context.Context, tx *sql.Tx, req *models.PendingOperation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePendingOperation", ctx, tx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePendingOperation indicates an expected call of UpdatePendingOperation.
+func (mr *MockOperationRepoIMockRecorder) UpdatePendingOperation(ctx, tx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePendingOperation", reflect.TypeOf((*MockOperationRepoI)(nil).UpdatePendingOperation), ctx, tx, req)
+}
+
+// MockFraudRepoI is a mock of FraudRepoI interface.
+type MockFraudRepoI struct {
+	ctrl     *gomock.Controller
+	recorder *MockFraudRepoIMockRecorder
+}
+
+// MockFraudRepoIMockRecorder is the mock recorder for MockFraudRepoI.
+type MockFraudRepoIMockRecorder struct {
+	mock *MockFraudRepoI
+}
+
+// NewMockFraudRepoI creates a new mock instance.
+func NewMockFraudRepoI(ctrl *gomock.Controller) *MockFraudRepoI {
+	mock := &MockFraudRepoI{ctrl: ctrl}
+	mock.recorder = &MockFraudRepoIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFraudRepoI) EXPECT() *MockFraudRepoIMockRecorder {
+	return m.recorder
+}
+
+// CreateFraudDecision mocks base method.
+func (m *MockFraudRepoI) CreateFraudDecision(ctx context.Context, req *models.FraudDecision) (*models.FraudDecision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFraudDecision", ctx, req)
+	ret0, _ := ret[0].(*models.FraudDecision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateFraudDecision indicates an expected call of CreateFraudDecision.
+func (mr *MockFraudRepoIMockRecorder) CreateFraudDecision(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFraudDecision", reflect.TypeOf((*MockFraudRepoI)(nil).CreateFraudDecision), ctx, req)
+}
+
+// GetFraudDecision mocks base method.
+func (m *MockFraudRepoI) GetFraudDecision(ctx context.Context, id string) (*models.FraudDecision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFraudDecision", ctx, id)
+	ret0, _ := ret[0].(*models.FraudDecision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFraudDecision indicates an expected call of GetFraudDecision.
+func (mr *MockFraudRepoIMockRecorder) GetFraudDecision(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFraudDecision", reflect.TypeOf((*MockFraudRepoI)(nil).GetFraudDecision), ctx, id)
+}
+
+// GetFraudDecisions mocks base method.
+func (m *MockFraudRepoI) GetFraudDecisions(ctx context.Context, req *models.GetFraudDecisionsRequest) (*models.GetFraudDecisionsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFraudDecisions", ctx, req)
+	ret0, _ := ret[0].(*models.GetFraudDecisionsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFraudDecisions indicates an expected call of GetFraudDecisions.
+func (mr *MockFraudRepoIMockRecorder) GetFraudDecisions(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFraudDecisions", reflect.TypeOf((*MockFraudRepoI)(nil).GetFraudDecisions), ctx, req)
+}
+
+// GetFraudSignals mocks base method.
+func (m *MockFraudRepoI) GetFraudSignals(ctx context.Context, req *models.GetFraudSignalsRequest) (*models.FraudSignals, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFraudSignals", ctx, req)
+	ret0, _ := ret[0].(*models.FraudSignals)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFraudSignals indicates an expected call of GetFraudSignals.
+func (mr *MockFraudRepoIMockRecorder) GetFraudSignals(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFraudSignals", reflect.TypeOf((*MockFraudRepoI)(nil).GetFraudSignals), ctx, req)
+}
+
+// PassFraudChallenge mocks base method.
+func (m *MockFraudRepoI) PassFraudChallenge(ctx context.Context, tx *sql.Tx, id, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PassFraudChallenge", ctx, tx, id, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PassFraudChallenge indicates an expected call of PassFraudChallenge.
+func (mr *MockFraudRepoIMockRecorder) PassFraudChallenge(ctx, tx, id, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PassFraudChallenge", reflect.TypeOf((*MockFraudRepoI)(nil).PassFraudChallenge), ctx, tx, id, userID)
+}
+
+// ReviewFraudDecision mocks base method.
+func (m *MockFraudRepoI) ReviewFraudDecision(ctx context.Context, tx *sql.Tx, req *models.ReviewFraudDecisionRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReviewFraudDecision", ctx, tx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReviewFraudDecision indicates an expected call of ReviewFraudDecision.
+func (mr *MockFraudRepoIMockRecorder) ReviewFraudDecision(ctx, tx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReviewFraudDecision", reflect.TypeOf((*MockFraudRepoI)(nil).ReviewFraudDecision), ctx, tx, req)
+}
+
+// UseFraudChallenge mocks base method.
+func (m *MockFraudRepoI) UseFraudChallenge(ctx context.Context, req *models.UseFraudChallengeRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseFraudChallenge", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseFraudChallenge indicates an expected call of UseFraudChallenge.
+func (mr *MockFraudRepoIMockRecorder) UseFraudChallenge(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseFraudChallenge", reflect.TypeOf((*MockFraudRepoI)(nil).UseFraudChallenge), ctx, req)
+}
+
+// MockAMLRepoI is a mock of AMLRepoI interface.
+type MockAMLRepoI struct {
+	ctrl     *gomock.Controller
+	recorder *MockAMLRepoIMockRecorder
+}
+
+// MockAMLRepoIMockRecorder is the mock recorder for MockAMLRepoI.
+type MockAMLRepoIMockRecorder struct {
+	mock *MockAMLRepoI
+}
+
+// NewMockAMLRepoI creates a new mock instance.
+func NewMockAMLRepoI(ctrl *gomock.Controller) *MockAMLRepoI {
+	mock := &MockAMLRepoI{ctrl: ctrl}
+	mock.recorder = &MockAMLRepoIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAMLRepoI) EXPECT() *MockAMLRepoIMockRecorder {
+	return m.recorder
+}
+
+// CreateAMLCase mocks base method.
+func (m *MockAMLRepoI) CreateAMLCase(ctx context.Context, tx *sql.Tx, req *models.AMLCase) (*models.AMLCase, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAMLCase", ctx, tx, req)
+	ret0, _ := ret[0].(*models.AMLCase)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAMLCase indicates an expected call of CreateAMLCase.
+func (mr *MockAMLRepoIMockRecorder) CreateAMLCase(ctx, tx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAMLCase", reflect.TypeOf((*MockAMLRepoI)(nil).CreateAMLCase), ctx, tx, req)
+}
+
+// GetAMLActivity mocks base method.
+func (m *MockAMLRepoI) GetAMLActivity(ctx context.Context, tx *sql.Tx, req *models.GetAMLActivityRequest) ([]*models.AMLTransaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAMLActivity", ctx, tx, req)
+	ret0, _ := ret[0].([]*models.AMLTransaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAMLActivity indicates an expected call of GetAMLActivity.
+func (mr *MockAMLRepoIMockRecorder) GetAMLActivity(ctx, tx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAMLActivity", reflect.TypeOf((*MockAMLRepoI)(nil).GetAMLActivity), ctx, tx, req)
+}
+
+// GetAMLCase mocks base method.
+func (m *MockAMLRepoI) GetAMLCase(ctx context.Context, id string) (*models.AMLCase, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAMLCase", ctx, id)
+	ret0, _ := ret[0].(*models.AMLCase)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAMLCase indicates an expected call of GetAMLCase.
+func (mr *MockAMLRepoIMockRecorder) GetAMLCase(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAMLCase", reflect.TypeOf((*MockAMLRepoI)(nil).GetAMLCase), ctx, id)
+}
+
+// GetAMLCaseHistory mocks base method.
+func (m *MockAMLRepoI) GetAMLCaseHistory(ctx context.Context, caseID string) ([]*models.AMLCaseStatusChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAMLCaseHistory", ctx, caseID)
+	ret0, _ := ret[0].([]*models.AMLCaseStatusChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAMLCaseHistory indicates an expected call of GetAMLCaseHistory.
+func (mr *MockAMLRepoIMockRecorder) GetAMLCaseHistory(ctx, caseID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAMLCaseHistory", reflect.TypeOf((*MockAMLRepoI)(nil).GetAMLCaseHistory), ctx, caseID)
+}
+
+// GetAMLCaseTransactions mocks base method.
+func (m *MockAMLRepoI) GetAMLCaseTransactions(ctx context.Context, caseID string) ([]*models.AMLTransaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAMLCaseTransactions", ctx, caseID)
+	ret0, _ := ret[0].([]*models.AMLTransaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAMLCaseTransactions indicates an expected call of GetAMLCaseTransactions.
+func (mr *MockAMLRepoIMockRecorder) GetAMLCaseTransactions(ctx, caseID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAMLCaseTransactions", reflect.TypeOf((*MockAMLRepoI)(nil).GetAMLCaseTransactions), ctx, caseID)
+}
+
+// GetAMLCases mocks base method.
+func (m *MockAMLRepoI) GetAMLCases(ctx context.Context, req *models.GetAMLCasesRequest) (*models.GetAMLCasesResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAMLCases", ctx, req)
+	ret0, _ := ret[0].(*models.GetAMLCasesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAMLCases indicates an expected call of GetAMLCases.
+func (mr *MockAMLRepoIMockRecorder) GetAMLCases(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAMLCases", reflect.TypeOf((*MockAMLRepoI)(nil).GetAMLCases), ctx, req)
+}
+
+// GetAMLCursor mocks base method.
+func (m *MockAMLRepoI) GetAMLCursor(ctx context.Context, tx *sql.Tx) (*models.AMLCursor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAMLCursor", ctx, tx)
+	ret0, _ := ret[0].(*models.AMLCursor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAMLCursor indicates an expected call of GetAMLCursor.
+func (mr *MockAMLRepoIMockRecorder) GetAMLCursor(ctx, tx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAMLCursor", reflect.TypeOf((*MockAMLRepoI)(nil).GetAMLCursor), ctx, tx)
+}
+
+// GetAMLTransactions mocks base method.
+func (m *MockAMLRepoI) GetAMLTransactions(ctx context.Context, tx *sql.Tx, req *models.GetAMLTransactionsRequest) ([]*models.AMLTransaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAMLTransactions", ctx, tx, req)
+	ret0, _ := ret[0].([]*models.AMLTransaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAMLTransactions indicates an expected call of GetAMLTransactions.
+func (mr *MockAMLRepoIMockRecorder) GetAMLTransactions(ctx, tx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAMLTransactions", reflect.TypeOf((*MockAMLRepoI)(nil).GetAMLTransactions), ctx, tx, req)
+}
+
+// GetActiveAMLCase mocks base method.
+func (m *MockAMLRepoI) GetActiveAMLCase(ctx context.Context, tx *sql.Tx, accountID, caseType string) (*models.AMLCase, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActiveAMLCase", ctx, tx, accountID, caseType)
+	ret0, _ := ret[0].(*models.AMLCase)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActiveAMLCase indicates an expected call of GetActiveAMLCase.
+func (mr *MockAMLRepoIMockRecorder) GetActiveAMLCase(ctx, tx, accountID, caseType interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveAMLCase", reflect.TypeOf((*MockAMLRepoI)(nil).GetActiveAMLCase), ctx, tx, accountID, caseType)
+}
+
+// LinkAMLCaseTransactions mocks base method.
+func (m *MockAMLRepoI) LinkAMLCaseTransactions(ctx context.Context, tx *sql.Tx, caseID string, transactionIDs []string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LinkAMLCaseTransactions", ctx, tx, caseID, transactionIDs)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LinkAMLCaseTransactions indicates an expected call of LinkAMLCaseTransactions.
+func (mr *MockAMLRepoIMockRecorder) LinkAMLCaseTransactions(ctx, tx, caseID, transactionIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LinkAMLCaseTransactions", reflect.TypeOf((*MockAMLRepoI)(nil).LinkAMLCaseTransactions), ctx, tx, caseID, transactionIDs)
+}
+
+// SetAMLCursor mocks base method.
+func (m *MockAMLRepoI) SetAMLCursor(ctx context.Context, tx *sql.Tx, cursor *models.AMLCursor) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetAMLCursor", ctx, tx, cursor)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetAMLCursor indicates an expected call of SetAMLCursor.
+func (mr *MockAMLRepoIMockRecorder) SetAMLCursor(ctx, tx, cursor interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAMLCursor", reflect.TypeOf((*MockAMLRepoI)(nil).SetAMLCursor), ctx, tx, cursor)
+}
+
+// UpdateAMLCaseStatus mocks base method.
+func (m *MockAMLRepoI) UpdateAMLCaseStatus(ctx context.Context, tx *sql.Tx, req *models.UpdateAMLCaseStatusRequest, from string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAMLCaseStatus", ctx, tx, req, from)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAMLCaseStatus indicates an expected call of UpdateAMLCaseStatus.
+func (mr *MockAMLRepoIMockRecorder) UpdateAMLCaseStatus(ctx, tx, req, from interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAMLCaseStatus", reflect.TypeOf((*MockAMLRepoI)(nil).UpdateAMLCaseStatus), ctx, tx, req, from)
+}
+
+// MockSanctionsRepoI is a mock of SanctionsRepoI interface.
+type MockSanctionsRepoI struct {
+	ctrl     *gomock.Controller
+	recorder *MockSanctionsRepoIMockRecorder
+}
+
+// MockSanctionsRepoIMockRecorder is the mock recorder for MockSanctionsRepoI.
+type MockSanctionsRepoIMockRecorder struct {
+	mock *MockSanctionsRepoI
+}
+
+// NewMockSanctionsRepoI creates a new mock instance.
+func NewMockSanctionsRepoI(ctrl *gomock.Controller) *MockSanctionsRepoI {
+	mock := &MockSanctionsRepoI{ctrl: ctrl}
+	mock.recorder = &MockSanctionsRepoIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSanctionsRepoI) EXPECT() *MockSanctionsRepoIMockRecorder {
+	return m.recorder
+}
+
+// CreateSanctionsHits mocks base method.
+func (m *MockSanctionsRepoI) CreateSanctionsHits(ctx context.Context, tx *sql.Tx, hits []*models.SanctionsHit) ([]*models.SanctionsHit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSanctionsHits", ctx, tx, hits)
+	ret0, _ := ret[0].([]*models.SanctionsHit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSanctionsHits indicates an expected call of CreateSanctionsHits.
+func (mr *MockSanctionsRepoIMockRecorder) CreateSanctionsHits(ctx, tx, hits interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSanctionsHits", reflect.TypeOf((*MockSanctionsRepoI)(nil).CreateSanctionsHits), ctx, tx, hits)
+}
+
+// GetSanctionsHit mocks base method.
+func (m *MockSanctionsRepoI) GetSanctionsHit(ctx context.Context, id string) (*models.SanctionsHit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSanctionsHit", ctx, id)
+	ret0, _ := ret[0].(*models.SanctionsHit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSanctionsHit indicates an expected call of GetSanctionsHit.
+func (mr *MockSanctionsRepoIMockRecorder) GetSanctionsHit(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSanctionsHit", reflect.TypeOf((*MockSanctionsRepoI)(nil).GetSanctionsHit), ctx, id)
+}
+
+// GetSanctionsHits mocks base method.
+func (m *MockSanctionsRepoI) GetSanctionsHits(ctx context.Context, req *models.GetSanctionsHitsRequest) (*models.GetSanctionsHitsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSanctionsHits", ctx, req)
+	ret0, _ := ret[0].(*models.GetSanctionsHitsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSanctionsHits indicates an expected call of GetSanctionsHits.
+func (mr *MockSanctionsRepoIMockRecorder) GetSanctionsHits(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSanctionsHits", reflect.TypeOf((*MockSanctionsRepoI)(nil).GetSanctionsHits), ctx, req)
+}
+
+// HasBlockingSanctionsHit mocks base method.
+func (m *MockSanctionsRepoI) HasBlockingSanctionsHit(ctx context.Context, accountIDs []string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasBlockingSanctionsHit", ctx, accountIDs)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasBlockingSanctionsHit indicates an expected call of HasBlockingSanctionsHit.
+func (mr *MockSanctionsRepoIMockRecorder) HasBlockingSanctionsHit(ctx, accountIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasBlockingSanctionsHit", reflect.TypeOf((*MockSanctionsRepoI)(nil).HasBlockingSanctionsHit), ctx, accountIDs)
+}
+
+// ReviewSanctionsHit mocks base method.
+func (m *MockSanctionsRepoI) ReviewSanctionsHit(ctx context.Context, tx *sql.Tx, req *models.ReviewSanctionsHitRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReviewSanctionsHit", ctx, tx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReviewSanctionsHit indicates an expected call of ReviewSanctionsHit.
+func (mr *MockSanctionsRepoIMockRecorder) ReviewSanctionsHit(ctx, tx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReviewSanctionsHit", reflect.TypeOf((*MockSanctionsRepoI)(nil).ReviewSanctionsHit), ctx, tx, req)
+}
+
+// MockKYCRepoI is a mock of KYCRepoI interface.
+type MockKYCRepoI struct {
+	ctrl     *gomock.Controller
+	recorder *MockKYCRepoIMockRecorder
+}
+
+// MockKYCRepoIMockRecorder is the mock recorder for MockKYCRepoI.
+type MockKYCRepoIMockRecorder struct {
+	mock *MockKYCRepoI
+}
+
+// NewMockKYCRepoI creates a new mock instance.
+func NewMockKYCRepoI(ctrl *gomock.Controller) *MockKYCRepoI {
+	mock := &MockKYCRepoI{ctrl: ctrl}
+	mock.recorder = &MockKYCRepoIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockKYCRepoI) EXPECT() *MockKYCRepoIMockRecorder {
+	return m.recorder
+}
+
+// AttachKYCDocuments mocks base method.
+func (m *MockKYCRepoI) AttachKYCDocuments(ctx context.Context, tx *sql.Tx, userID, applicationID string) ([]*models.KYCDocument, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AttachKYCDocuments", ctx, tx, userID, applicationID)
+	ret0, _ := ret[0].([]*models.KYCDocument)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AttachKYCDocuments indicates an expected call of AttachKYCDocuments.
+func (mr *MockKYCRepoIMockRecorder) AttachKYCDocuments(ctx, tx, userID, applicationID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AttachKYCDocuments", reflect.TypeOf((*MockKYCRepoI)(nil).AttachKYCDocuments), ctx, tx, userID, applicationID)
+}
+
+// CreateKYCApplication mocks base method.
+func (m *MockKYCRepoI) CreateKYCApplication(ctx context.Context, tx *sql.Tx, req *models.KYCApplication) (*models.KYCApplication, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateKYCApplication", ctx, tx, req)
+	ret0, _ := ret[0].(*models.KYCApplication)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateKYCApplication indicates an expected call of CreateKYCApplication.
+func (mr *MockKYCRepoIMockRecorder) CreateKYCApplication(ctx, tx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateKYCApplication", reflect.TypeOf((*MockKYCRepoI)(nil).CreateKYCApplication), ctx, tx, req)
+}
+
+// CreateKYCDocument mocks base method.
+func (m *MockKYCRepoI) CreateKYCDocument(ctx context.Context, tx *sql.Tx, req *models.KYCDocument) (*models.KYCDocument, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateKYCDocument", ctx, tx, req)
+	ret0, _ := ret[0].(*models.KYCDocument)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateKYCDocument indicates an expected call of CreateKYCDocument.
+func (mr *MockKYCRepoIMockRecorder) CreateKYCDocument(ctx, tx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateKYCDocument", reflect.TypeOf((*MockKYCRepoI)(nil).CreateKYCDocument), ctx, tx, req)
+}
+
+// GetAccountKYCTier mocks base method.
+func (m *MockKYCRepoI) GetAccountKYCTier(ctx context.Context, accountID string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountKYCTier", ctx, accountID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountKYCTier indicates an expected call of GetAccountKYCTier.
+func (mr *MockKYCRepoIMockRecorder) GetAccountKYCTier(ctx, accountID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountKYCTier", reflect.TypeOf((*MockKYCRepoI)(nil).GetAccountKYCTier), ctx, accountID)
+}
+
+// GetKYCApplication mocks base method.
+func (m *MockKYCRepoI) GetKYCApplication(ctx context.Context, id string) (*models.KYCApplication, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKYCApplication", ctx, id)
+	ret0, _ := ret[0].(*models.KYCApplication)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKYCApplication indicates an expected call of GetKYCApplication.
+func (mr *MockKYCRepoIMockRecorder) GetKYCApplication(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKYCApplication", reflect.TypeOf((*MockKYCRepoI)(nil).GetKYCApplication), ctx, id)
+}
+
+// GetKYCApplicationDocuments mocks base method.
+func (m *MockKYCRepoI) GetKYCApplicationDocuments(ctx context.Context, applicationID string) ([]*models.KYCDocument, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKYCApplicationDocuments", ctx, applicationID)
+	ret0, _ := ret[0].([]*models.KYCDocument)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKYCApplicationDocuments indicates an expected call of GetKYCApplicationDocuments.
+func (mr *MockKYCRepoIMockRecorder) GetKYCApplicationDocuments(ctx, applicationID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKYCApplicationDocuments", reflect.TypeOf((*MockKYCRepoI)(nil).GetKYCApplicationDocuments), ctx, applicationID)
+}
+
+// GetKYCApplicationForUpdate mocks base method.
+func (m *MockKYCRepoI) GetKYCApplicationForUpdate(ctx context.Context, tx *sql.Tx, id string) (*models.KYCApplication, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKYCApplicationForUpdate", ctx, tx, id)
+	ret0, _ := ret[0].(*models.KYCApplication)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKYCApplicationForUpdate indicates an expected call of GetKYCApplicationForUpdate.
+func (mr *MockKYCRepoIMockRecorder) GetKYCApplicationForUpdate(ctx, tx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKYCApplicationForUpdate", reflect.TypeOf((*MockKYCRepoI)(nil).GetKYCApplicationForUpdate), ctx, tx, id)
+}
+
+// GetKYCApplications mocks base method.
+func (m *MockKYCRepoI) GetKYCApplications(ctx context.Context, req *models.GetKYCApplicationsRequest) (*models.GetKYCApplicationsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKYCApplications", ctx, req)
+	ret0, _ := ret[0].(*models.GetKYCApplicationsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKYCApplications indicates an expected call of GetKYCApplications.
+func (mr *MockKYCRepoIMockRecorder) GetKYCApplications(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKYCApplications", reflect.TypeOf((*MockKYCRepoI)(nil).GetKYCApplications), ctx, req)
+}
+
+// GetKYCDocument mocks base method.
+func (m *MockKYCRepoI) GetKYCDocument(ctx context.Context, id string) (*models.KYCDocument, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKYCDocument", ctx, id)
+	ret0, _ := ret[0].(*models.KYCDocument)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKYCDocument indicates an expected call of GetKYCDocument.
+func (mr *MockKYCRepoIMockRecorder) GetKYCDocument(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKYCDocument", reflect.TypeOf((*MockKYCRepoI)(nil).GetKYCDocument), ctx, id)
+}
+
+// GetKYCTier mocks base method.
+func (m *MockKYCRepoI) GetKYCTier(ctx context.Context, userID string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKYCTier", ctx, userID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKYCTier indicates an expected call of GetKYCTier.
+func (mr *MockKYCRepoIMockRecorder) GetKYCTier(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKYCTier", reflect.TypeOf((*MockKYCRepoI)(nil).GetKYCTier), ctx, userID)
+}
+
+// GetLatestKYCApplication mocks base method.
+func (m *MockKYCRepoI) GetLatestKYCApplication(ctx context.Context, userID string) (*models.KYCApplication, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatestKYCApplication", ctx, userID)
+	ret0, _ := ret[0].(*models.KYCApplication)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLatestKYCApplication indicates an expected call of GetLatestKYCApplication.
+func (mr *MockKYCRepoIMockRecorder) GetLatestKYCApplication(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestKYCApplication", reflect.TypeOf((*MockKYCRepoI)(nil).GetLatestKYCApplication), ctx, userID)
+}
+
+// GetUnsubmittedKYCDocuments mocks base method.
+func (m *MockKYCRepoI) GetUnsubmittedKYCDocuments(ctx context.Context, userID string) ([]*models.KYCDocument, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUnsubmittedKYCDocuments", ctx, userID)
+	ret0, _ := ret[0].([]*models.KYCDocument)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUnsubmittedKYCDocuments indicates an expected call of GetUnsubmittedKYCDocuments.
+func (mr *MockKYCRepoIMockRecorder) GetUnsubmittedKYCDocuments(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUnsubmittedKYCDocuments", reflect.TypeOf((*MockKYCRepoI)(nil).GetUnsubmittedKYCDocuments), ctx, userID)
+}
+
+// ReviewKYCApplication mocks base method.
+func (m *MockKYCRepoI) ReviewKYCApplication(ctx context.Context, tx *sql.Tx, req *models.ReviewKYCApplicationRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReviewKYCApplication", ctx, tx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReviewKYCApplication indicates an expected call of ReviewKYCApplication.
+func (mr *MockKYCRepoIMockRecorder) ReviewKYCApplication(ctx, tx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReviewKYCApplication", reflect.TypeOf((*MockKYCRepoI)(nil).ReviewKYCApplication), ctx, tx, req)
+}
+
+// SetKYCTier mocks base method.
+func (m *MockKYCRepoI) SetKYCTier(ctx context.Context, tx *sql.Tx, userID, tier string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetKYCTier", ctx, tx, userID, tier)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetKYCTier indicates an expected call of SetKYCTier.
+func (mr *MockKYCRepoIMockRecorder) SetKYCTier(ctx, tx, userID, tier interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetKYCTier", reflect.TypeOf((*MockKYCRepoI)(nil).SetKYCTier), ctx, tx, userID, tier)
+}
@@ -3,9 +3,9 @@ package postgres
 import (
 	"context"
 	"database/sql"
-	"strings"
 
 	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/helper"
 	"github.com/dilmurodov/online_banking/pkg/models"
 	"github.com/pkg/errors"
 )
@@ -23,8 +23,9 @@ func (r *accountRepo) CreateAccount(ctx context.Context, account *models.CreateA
 	stmt, err := r.db.PrepareContext(ctx,
 		`INSERT INTO accounts (
 			user_id, 
-			balance
-		) VALUES ($1, $2) RETURNING guid`,
+			balance,
+			product
+		) VALUES ($1, $2, $3) RETURNING guid`,
 	)
 	if err != nil {
 		return nil, err
@@ -34,15 +35,18 @@ func (r *accountRepo) CreateAccount(ctx context.Context, account *models.CreateA
 	row := stmt.QueryRowContext(ctx,
 		account.UserID,
 		account.Balance,
+		account.Product,
 	)
 	err = row.Scan(&accountID)
 	if err != nil {
 		return nil, &customerrors.InternalServerError{Message: err.Error()}
 	}
 	return &models.Account{
-		ID:      accountID,
-		UserID:  account.UserID,
-		Balance: account.Balance,
+		ID:              accountID,
+		UserID:          account.UserID,
+		Balance:         account.Balance,
+		Product:         account.Product,
+		AccruedInterest: "0",
 	}, nil
 }
 
@@ -52,6 +56,7 @@ func (r *accountRepo) GetAccountByID(ctx context.Context, req *models.GetAccount
 	var (
 		createdAt sql.NullString
 		updatedAt sql.NullString
+		closedAt  sql.NullString
 	)
 
 	err := r.db.QueryRowContext(ctx,
@@ -59,88 +64,179 @@ func (r *accountRepo) GetAccountByID(ctx context.Context, req *models.GetAccount
 			guid, 
 			user_id, 
 			balance, 
+			product,
+			accrued_interest,
+			overdraft_limit,
 			created_at,
-			updated_at 
+			updated_at,
+			status,
+			closed_at
 		FROM accounts 
 		WHERE guid=$1`, req.ID,
 	).Scan(
 		&account.ID,
 		&account.UserID,
 		&account.Balance,
+		&account.Product,
+		&account.AccruedInterest,
+		&account.OverdraftLimit,
 		&createdAt,
 		&updatedAt,
+		&account.Status,
+		&closedAt,
 	)
 	if err != nil && errors.Is(err, sql.ErrNoRows) {
 		return nil, &customerrors.UserNotFoundError{Guid: req.ID}
 	}
 	account.CreatedAt = createdAt.String
 	account.UpdatedAt = updatedAt.String
+	account.ClosedAt = closedAt.String
 	return &account, nil
 }
 
+// GetAccountsByUserID returns the user's accounts, by keyset when a cursor is given and by offset otherwise
 func (r *accountRepo) GetAccountsByUserID(ctx context.Context, req *models.GetAccountsByUserIDRequest) (resp *models.GetAccountsByUserIDResponse, err error) {
 	var (
-		accounts []*models.Account
+		accounts = make([]*models.Account, 0)
 		count    int
+		page     = helper.KeysetPage{Desc: req.Desc}
 	)
 
-	rows, err := r.db.QueryContext(ctx, `
+	// the user's accounts are the ones they hold in any role, joint accounts included
+	qb := helper.NewQueryBuilder().
+		Where("h.user_id = ?", req.UserID).
+		Where("a.deleted_at = 0")
+
+	countColumn := "count(1) OVER()"
+	if req.Cursor != "" {
+		cursor, err := helper.DecodeCursor(req.Cursor)
+		if err != nil {
+			return nil, &customerrors.InvalidCursorError{}
+		}
+		page.Cursor = cursor
+		countColumn = "0"
+		qb.Where("(a.created_at, a.guid) "+page.Operator()+" (?, ?)", cursor.CreatedAt, cursor.ID)
+	}
+
+	query := `
 		SELECT 
-			guid, 
-			user_id, 
-			balance, 
-			created_at,
-			updated_at,
-			count(1) OVER() AS count
-		FROM accounts WHERE user_id=$1 AND deleted_at = 0`, req.UserID)
+			a.guid, 
+			a.user_id, 
+			a.balance, 
+			a.product,
+			a.accrued_interest,
+			a.overdraft_limit,
+			a.created_at,
+			a.updated_at,
+			a.status,
+			a.closed_at,
+			h.role,
+			` + countColumn + ` AS count
+		FROM accounts a
+		JOIN account_holders h ON h.account_id = a.guid` + qb.WhereClause() + `
+		ORDER BY a.created_at ` + page.Order() + `, a.guid ` + page.Order()
+
+	// without a limit every account is returned, as before pagination was added
+	if req.Limit > 0 {
+		query += ` LIMIT ` + qb.Arg(req.Limit+1)
+	}
+	if req.Cursor == "" && req.Offset > 0 {
+		query += ` OFFSET ` + qb.Arg(req.Offset)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, qb.Args()...)
 	if err != nil {
 		return nil, &customerrors.InternalServerError{Message: err.Error()}
 	}
 	defer rows.Close()
 
 	for rows.Next() {
-		var a models.Account
+		var (
+			a        models.Account
+			closedAt sql.NullString
+		)
 		err := rows.Scan(
 			&a.ID,
 			&a.UserID,
 			&a.Balance,
+			&a.Product,
+			&a.AccruedInterest,
+			&a.OverdraftLimit,
 			&a.CreatedAt,
 			&a.UpdatedAt,
+			&a.Status,
+			&closedAt,
+			&a.Role,
 			&count,
 		)
 		if err != nil {
 			return nil, err
 		}
+		a.ClosedAt = closedAt.String
 		accounts = append(accounts, &a)
 	}
 	if err = rows.Err(); err != nil {
 		return nil, &customerrors.InternalServerError{Message: err.Error()}
 	}
 
-	return &models.GetAccountsByUserIDResponse{
-		Accounts: accounts,
-		Count:    count,
-	}, nil
+	resp = &models.GetAccountsByUserIDResponse{
+		Count: count,
+	}
+
+	hasMore := req.Limit > 0 && len(accounts) > req.Limit
+	if hasMore {
+		accounts = accounts[:req.Limit]
+	}
+	if page.Cursor != nil && page.Cursor.Backward {
+		for i, j := 0, len(accounts)-1; i < j; i, j = i+1, j-1 {
+			accounts[i], accounts[j] = accounts[j], accounts[i]
+		}
+	}
+	if len(accounts) > 0 {
+		first, last := accounts[0], accounts[len(accounts)-1]
+		resp.NextCursor, resp.PrevCursor = page.Cursors(
+			helper.Cursor{CreatedAt: first.CreatedAt, ID: first.ID},
+			helper.Cursor{CreatedAt: last.CreatedAt, ID: last.ID},
+			hasMore,
+		)
+	}
+	resp.Accounts = accounts
+
+	return resp, nil
 }
 
-func (r *accountRepo) UpdateAccountBalance(ctx context.Context, tx *sql.Tx, account *models.Account) error {
-	stmt, err := tx.PrepareContext(ctx,
+// AdjustAccountBalance adds a signed decimal amount to the balance without a float round trip.
+// A debit beyond the overdraft limit changes nothing and reports the shortfall.
+func (r *accountRepo) AdjustAccountBalance(ctx context.Context, tx *sql.Tx, req *models.AdjustAccountBalanceRequest) error {
+	result, err := tx.ExecContext(ctx,
 		`UPDATE accounts 
-		SET balance = $1 WHERE guid=$2`)
+		SET balance = balance + $1::numeric, updated_at = CURRENT_TIMESTAMP 
+		WHERE guid=$2 AND (system OR balance + $1::numeric >= -overdraft_limit)`,
+		req.Amount,
+		req.AccountID,
+	)
 	if err != nil {
-		return errors.Wrap(err, "failed to prepare update account balance statement")
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+	if cn, err := result.RowsAffected(); err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	} else if cn > 0 {
+		return nil
 	}
-	defer stmt.Close()
 
-	_, err = stmt.ExecContext(ctx,
-		account.Balance,
-		account.ID,
-	)
-	// CHeck positive balance constraint
-	if err != nil && strings.Contains(err.Error(), "constraint \"positive_balance\"") {
-		return &customerrors.InsufficientFundsError{}
+	var shortfall string
+	err = tx.QueryRowContext(ctx,
+		`SELECT (-(balance + overdraft_limit + $1::numeric))::numeric(20, 2)::text
+		FROM accounts
+		WHERE guid=$2`,
+		req.Amount,
+		req.AccountID,
+	).Scan(&shortfall)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &customerrors.AccountNotFoundError{Guid: req.AccountID}
 	} else if err != nil {
 		return &customerrors.InternalServerError{Message: err.Error()}
 	}
-	return nil
+
+	return &customerrors.InsufficientFundsError{AccountID: req.AccountID, Shortfall: shortfall}
 }
@@ -0,0 +1,499 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/helper"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/pkg/errors"
+)
+
+type accountHolderRepo struct {
+	db *sql.DB
+}
+
+func NewAccountHolderRepo(db *sql.DB) *accountHolderRepo {
+	return &accountHolderRepo{db: db}
+}
+
+// GetAccountRole returns the user's role on the account, or an empty role when the user doesn't hold it
+func (r *accountHolderRepo) GetAccountRole(ctx context.Context, accountID, userID string) (string, error) {
+	var role string
+
+	err := r.db.QueryRowContext(ctx,
+		`SELECT h.role
+		FROM account_holders h
+		JOIN accounts a ON a.guid = h.account_id AND a.deleted_at = 0
+		WHERE h.account_id = $1 AND h.user_id = $2`,
+		accountID,
+		userID,
+	).Scan(&role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	} else if err != nil {
+		return "", &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return role, nil
+}
+
+// GetAccountRoleForUpdate returns the user's role like GetAccountRole and locks the holder until the transaction ends
+func (r *accountHolderRepo) GetAccountRoleForUpdate(ctx context.Context, tx *sql.Tx, accountID, userID string) (string, error) {
+	var role string
+
+	err := tx.QueryRowContext(ctx,
+		`SELECT h.role
+		FROM account_holders h
+		JOIN accounts a ON a.guid = h.account_id AND a.deleted_at = 0
+		WHERE h.account_id = $1 AND h.user_id = $2
+		FOR UPDATE OF h`,
+		accountID,
+		userID,
+	).Scan(&role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	} else if err != nil {
+		return "", &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return role, nil
+}
+
+// GetApprovalPolicy returns the requester's role, the account's dual approval threshold
+// and how many other holders could approve the requester's transfer
+func (r *accountHolderRepo) GetApprovalPolicy(ctx context.Context, accountID, userID string) (*models.ApprovalPolicy, error) {
+	var policy models.ApprovalPolicy
+
+	err := r.db.QueryRowContext(ctx,
+		`SELECT
+			COALESCE((SELECT role FROM account_holders WHERE account_id = a.guid AND user_id = $2), ''),
+			a.dual_approval_threshold::text,
+			(SELECT count(1) FROM account_holders
+				WHERE account_id = a.guid AND user_id <> $2 AND role IN ('owner', 'co_owner', 'signer'))
+		FROM accounts a
+		WHERE a.guid = $1 AND a.deleted_at = 0`,
+		accountID,
+		userID,
+	).Scan(&policy.Role, &policy.Threshold, &policy.Approvers)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &customerrors.AccountNotFoundError{Guid: accountID}
+	} else if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return &policy, nil
+}
+
+func (r *accountHolderRepo) GetAccountHolders(ctx context.Context, accountID string) (*models.GetAccountHoldersResponse, error) {
+	resp := &models.GetAccountHoldersResponse{
+		Holders: make([]*models.AccountHolder, 0),
+	}
+
+	err := r.db.QueryRowContext(ctx,
+		`SELECT dual_approval_threshold::text FROM accounts WHERE guid = $1`,
+		accountID,
+	).Scan(&resp.DualApprovalThreshold)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &customerrors.AccountNotFoundError{Guid: accountID}
+	} else if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT
+			h.account_id,
+			h.user_id,
+			u.first_name,
+			u.last_name,
+			h.role,
+			h.created_at
+		FROM account_holders h
+		JOIN users u ON u.guid = h.user_id
+		WHERE h.account_id = $1
+		ORDER BY (h.role = 'owner') DESC, h.created_at`,
+		accountID,
+	)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var h models.AccountHolder
+		if err := rows.Scan(&h.AccountID, &h.UserID, &h.FirstName, &h.LastName, &h.Role, &h.CreatedAt); err != nil {
+			return nil, &customerrors.InternalServerError{Message: err.Error()}
+		}
+		resp.Holders = append(resp.Holders, &h)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return resp, nil
+}
+
+func (r *accountHolderRepo) AddAccountHolder(ctx context.Context, tx *sql.Tx, accountID, userID, role string) error {
+	_, err := tx.ExecContext(ctx,
+		`INSERT INTO account_holders (account_id, user_id, role)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (account_id, user_id) DO UPDATE SET role = EXCLUDED.role, updated_at = CURRENT_TIMESTAMP`,
+		accountID,
+		userID,
+		role,
+	)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return nil
+}
+
+// UpdateAccountHolder changes the role of a holder other than the owner
+func (r *accountHolderRepo) UpdateAccountHolder(ctx context.Context, tx *sql.Tx, accountID, userID, role string) error {
+	result, err := tx.ExecContext(ctx,
+		`UPDATE account_holders SET role = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE account_id = $1 AND user_id = $2 AND role <> 'owner'`,
+		accountID,
+		userID,
+		role,
+	)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return &customerrors.AccountHolderNotFoundError{UserID: userID}
+	}
+
+	return nil
+}
+
+// DeleteAccountHolder removes a holder other than the owner
+func (r *accountHolderRepo) DeleteAccountHolder(ctx context.Context, tx *sql.Tx, accountID, userID string) error {
+	result, err := tx.ExecContext(ctx,
+		`DELETE FROM account_holders
+		WHERE account_id = $1 AND user_id = $2 AND role <> 'owner'`,
+		accountID,
+		userID,
+	)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return &customerrors.AccountHolderNotFoundError{UserID: userID}
+	}
+
+	return nil
+}
+
+func (r *accountHolderRepo) SetDualApprovalThreshold(ctx context.Context, tx *sql.Tx, accountID, threshold string) error {
+	_, err := tx.ExecContext(ctx,
+		`UPDATE accounts SET dual_approval_threshold = $2::numeric, updated_at = CURRENT_TIMESTAMP
+		WHERE guid = $1`,
+		accountID,
+		threshold,
+	)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return nil
+}
+
+const invitationColumns = `
+			guid,
+			account_id,
+			invited_by,
+			user_id,
+			role,
+			status,
+			expires_at,
+			responded_at,
+			created_at`
+
+func scanInvitation(row rowScanner, extra ...interface{}) (*models.AccountInvitation, error) {
+	var (
+		i           models.AccountInvitation
+		respondedAt sql.NullString
+	)
+
+	dest := []interface{}{
+		&i.ID,
+		&i.AccountID,
+		&i.InvitedBy,
+		&i.UserID,
+		&i.Role,
+		&i.Status,
+		&i.ExpiresAt,
+		&respondedAt,
+		&i.CreatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	i.RespondedAt = respondedAt.String
+
+	return &i, nil
+}
+
+// CreateInvitation stores the invitation, replacing a pending one for the same user
+func (r *accountHolderRepo) CreateInvitation(ctx context.Context, tx *sql.Tx, req *models.AccountInvitation) (*models.AccountInvitation, error) {
+	resp, err := scanInvitation(tx.QueryRowContext(ctx,
+		`INSERT INTO account_invitations (account_id, invited_by, user_id, role, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (account_id, user_id) WHERE status = 'pending'
+		DO UPDATE SET invited_by = EXCLUDED.invited_by, role = EXCLUDED.role, expires_at = EXCLUDED.expires_at
+		RETURNING`+invitationColumns,
+		req.AccountID,
+		req.InvitedBy,
+		req.UserID,
+		req.Role,
+		req.ExpiresAt,
+	))
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return resp, nil
+}
+
+// GetInvitations returns the invitations waiting for the user's answer
+func (r *accountHolderRepo) GetInvitations(ctx context.Context, userID string) (*models.GetInvitationsResponse, error) {
+	resp := &models.GetInvitationsResponse{
+		Invitations: make([]*models.AccountInvitation, 0),
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT`+invitationColumns+`
+		FROM account_invitations
+		WHERE user_id = $1 AND status = 'pending' AND expires_at > $2
+		ORDER BY created_at DESC`,
+		userID,
+		time.Now(),
+	)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		i, err := scanInvitation(rows)
+		if err != nil {
+			return nil, &customerrors.InternalServerError{Message: err.Error()}
+		}
+		resp.Invitations = append(resp.Invitations, i)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return resp, nil
+}
+
+// GetInvitationForUpdate locks the invitation until the transaction ends, so it is answered only once
+func (r *accountHolderRepo) GetInvitationForUpdate(ctx context.Context, tx *sql.Tx, id string) (*models.AccountInvitation, error) {
+	resp, err := scanInvitation(tx.QueryRowContext(ctx,
+		`SELECT`+invitationColumns+`
+		FROM account_invitations
+		WHERE guid = $1
+		FOR UPDATE`,
+		id,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &customerrors.InvitationNotFoundError{Guid: id}
+	} else if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return resp, nil
+}
+
+func (r *accountHolderRepo) UpdateInvitationStatus(ctx context.Context, tx *sql.Tx, id, status string) error {
+	_, err := tx.ExecContext(ctx,
+		`UPDATE account_invitations SET status = $2, responded_at = CURRENT_TIMESTAMP
+		WHERE guid = $1`,
+		id,
+		status,
+	)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return nil
+}
+
+const transferApprovalColumns = `
+			guid,
+			account_id,
+			requested_by,
+			to_account_id,
+			amount,
+			description,
+			reference,
+			status,
+			decided_by,
+			transaction_id,
+			expires_at,
+			decided_at,
+			created_at`
+
+func scanTransferApproval(row rowScanner, extra ...interface{}) (*models.TransferApproval, error) {
+	var (
+		a             models.TransferApproval
+		decidedBy     sql.NullString
+		transactionID sql.NullString
+		decidedAt     sql.NullString
+	)
+
+	dest := []interface{}{
+		&a.ID,
+		&a.AccountID,
+		&a.RequestedBy,
+		&a.ToAccountID,
+		&a.Amount,
+		&a.Description,
+		&a.Reference,
+		&a.Status,
+		&decidedBy,
+		&transactionID,
+		&a.ExpiresAt,
+		&decidedAt,
+		&a.CreatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	a.DecidedBy = decidedBy.String
+	a.TransactionID = transactionID.String
+	a.DecidedAt = decidedAt.String
+
+	return &a, nil
+}
+
+func (r *accountHolderRepo) CreateTransferApproval(ctx context.Context, tx *sql.Tx, req *models.TransferApproval) (*models.TransferApproval, error) {
+	resp, err := scanTransferApproval(tx.QueryRowContext(ctx,
+		`INSERT INTO transfer_approvals (
+			account_id,
+			requested_by,
+			to_account_id,
+			amount,
+			description,
+			reference,
+			expires_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING`+transferApprovalColumns,
+		req.AccountID,
+		req.RequestedBy,
+		req.ToAccountID,
+		req.Amount,
+		req.Description,
+		req.Reference,
+		req.ExpiresAt,
+	))
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return resp, nil
+}
+
+func (r *accountHolderRepo) GetTransferApprovals(ctx context.Context, req *models.GetTransferApprovalsRequest) (*models.GetTransferApprovalsResponse, error) {
+	var count int
+	resp := &models.GetTransferApprovalsResponse{
+		Approvals: make([]*models.TransferApproval, 0),
+	}
+
+	qb := helper.NewQueryBuilder().Where("account_id = ?", req.AccountID)
+	if req.Status != "" {
+		qb.Where("status = ?", req.Status)
+	}
+
+	query := `SELECT` + transferApprovalColumns + `,
+			count(1) OVER() AS count
+		FROM transfer_approvals` + qb.WhereClause() + `
+		ORDER BY created_at DESC
+		LIMIT ` + qb.Arg(req.Limit) + ` OFFSET ` + qb.Arg(req.Offset)
+
+	rows, err := r.db.QueryContext(ctx, query, qb.Args()...)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		a, err := scanTransferApproval(rows, &count)
+		if err != nil {
+			return nil, &customerrors.InternalServerError{Message: err.Error()}
+		}
+		resp.Approvals = append(resp.Approvals, a)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	resp.Count = count
+
+	return resp, nil
+}
+
+// GetTransferApprovalForUpdate locks the approval until the transaction ends, so the transfer is made only once
+func (r *accountHolderRepo) GetTransferApprovalForUpdate(ctx context.Context, tx *sql.Tx, req *models.TransferApprovalByIDRequest) (*models.TransferApproval, error) {
+	resp, err := scanTransferApproval(tx.QueryRowContext(ctx,
+		`SELECT`+transferApprovalColumns+`
+		FROM transfer_approvals
+		WHERE guid = $1 AND account_id = $2
+		FOR UPDATE`,
+		req.ID,
+		req.AccountID,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &customerrors.TransferApprovalNotFoundError{Guid: req.ID}
+	} else if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return resp, nil
+}
+
+func (r *accountHolderRepo) UpdateTransferApproval(ctx context.Context, tx *sql.Tx, req *models.TransferApproval) error {
+	_, err := tx.ExecContext(ctx,
+		`UPDATE transfer_approvals SET
+			status = $2,
+			decided_by = $3,
+			transaction_id = $4,
+			decided_at = CURRENT_TIMESTAMP
+		WHERE guid = $1`,
+		req.ID,
+		req.Status,
+		toNullString(req.DecidedBy),
+		toNullString(req.TransactionID),
+	)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return nil
+}
+
+// DeleteUserHoldings takes a leaving user off the accounts others own and declines the invitations waiting for them
+func (r *accountHolderRepo) DeleteUserHoldings(ctx context.Context, tx *sql.Tx, userID string) error {
+	_, err := tx.ExecContext(ctx,
+		`DELETE FROM account_holders WHERE user_id = $1 AND role <> 'owner'`,
+		userID,
+	)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE account_invitations SET status = 'declined', responded_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND status = 'pending'`,
+		userID,
+	)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return nil
+}
@@ -0,0 +1,215 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/pkg/errors"
+)
+
+// GetAccountStatus reads the account's status in the transaction and keeps the row from changing status until it ends
+func (r *accountRepo) GetAccountStatus(ctx context.Context, tx *sql.Tx, accountID string) (string, error) {
+	var status string
+
+	err := tx.QueryRowContext(ctx,
+		`SELECT status FROM accounts WHERE guid = $1 FOR SHARE`,
+		accountID,
+	).Scan(&status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", &customerrors.AccountNotFoundError{Guid: accountID}
+	} else if err != nil {
+		return "", &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return status, nil
+}
+
+// GetAccountForUpdate locks the account for a status change that depends on its balance
+func (r *accountRepo) GetAccountForUpdate(ctx context.Context, tx *sql.Tx, accountID string) (*models.Account, error) {
+	var (
+		account  models.Account
+		closedAt sql.NullString
+	)
+
+	err := tx.QueryRowContext(ctx,
+		`SELECT
+			guid,
+			user_id,
+			balance,
+			product,
+			accrued_interest,
+			overdraft_limit,
+			status,
+			closed_at
+		FROM accounts
+		WHERE guid = $1 AND deleted_at = 0
+		FOR UPDATE`,
+		accountID,
+	).Scan(
+		&account.ID,
+		&account.UserID,
+		&account.Balance,
+		&account.Product,
+		&account.AccruedInterest,
+		&account.OverdraftLimit,
+		&account.Status,
+		&closedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &customerrors.AccountNotFoundError{Guid: accountID}
+	} else if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	account.ClosedAt = closedAt.String
+
+	return &account, nil
+}
+
+// GetOwnedAccountsForUpdate locks the accounts the user owns, so none of them changes hands or status while the caller acts on all of them
+func (r *accountRepo) GetOwnedAccountsForUpdate(ctx context.Context, tx *sql.Tx, userID string) ([]*models.Account, error) {
+	rows, err := tx.QueryContext(ctx,
+		`SELECT
+			a.guid,
+			a.product,
+			a.status
+		FROM accounts a
+		JOIN account_holders h ON h.account_id = a.guid
+		WHERE h.user_id = $1 AND h.role = $2 AND a.deleted_at = 0
+		ORDER BY a.created_at, a.guid
+		FOR UPDATE OF a`,
+		userID,
+		models.AccountRoleOwner,
+	)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	accounts := make([]*models.Account, 0)
+	for rows.Next() {
+		var a models.Account
+		if err = rows.Scan(&a.ID, &a.Product, &a.Status); err != nil {
+			return nil, &customerrors.InternalServerError{Message: err.Error()}
+		}
+		a.Role = models.AccountRoleOwner
+		accounts = append(accounts, &a)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return accounts, nil
+}
+
+// UpdateAccountStatus moves the account from FromStatus to ToStatus and records the change.
+// Closing an account also takes its overdraft away.
+func (r *accountRepo) UpdateAccountStatus(ctx context.Context, tx *sql.Tx, req *models.AccountStatusChange) error {
+	result, err := tx.ExecContext(ctx,
+		`UPDATE accounts SET
+			status = $3,
+			closed_at = CASE WHEN $3 = 'closed' THEN CURRENT_TIMESTAMP END,
+			overdraft_limit = CASE WHEN $3 = 'closed' THEN 0 ELSE overdraft_limit END,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE guid = $1 AND status = $2`,
+		req.AccountID,
+		req.FromStatus,
+		req.ToStatus,
+	)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	} else if n == 0 {
+		return &customerrors.AccountStatusError{Guid: req.AccountID, Status: req.FromStatus}
+	}
+
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO account_status_history (
+			account_id,
+			from_status,
+			to_status,
+			actor_id,
+			actor_type,
+			reason
+		) VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING guid, created_at`,
+		req.AccountID,
+		req.FromStatus,
+		req.ToStatus,
+		toNullString(req.ActorID),
+		req.ActorType,
+		req.Reason,
+	).Scan(&req.ID, &req.CreatedAt)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return nil
+}
+
+func (r *accountRepo) GetAccountStatusHistory(ctx context.Context, accountID string) (*models.GetAccountStatusHistoryResponse, error) {
+	resp := &models.GetAccountStatusHistoryResponse{
+		Changes: make([]*models.AccountStatusChange, 0),
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT
+			guid,
+			account_id,
+			from_status,
+			to_status,
+			actor_id,
+			actor_type,
+			reason,
+			created_at
+		FROM account_status_history
+		WHERE account_id = $1
+		ORDER BY created_at DESC`,
+		accountID,
+	)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			c       models.AccountStatusChange
+			actorID sql.NullString
+		)
+		err := rows.Scan(&c.ID, &c.AccountID, &c.FromStatus, &c.ToStatus, &actorID, &c.ActorType, &c.Reason, &c.CreatedAt)
+		if err != nil {
+			return nil, &customerrors.InternalServerError{Message: err.Error()}
+		}
+		c.ActorID = actorID.String
+		resp.Changes = append(resp.Changes, &c)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return resp, nil
+}
+
+// GetClosingBlockers counts the products and payments still tied to the account in the transaction
+// that locked it
+func (r *accountRepo) GetClosingBlockers(ctx context.Context, tx *sql.Tx, accountID string) (*models.AccountClosingBlockers, error) {
+	var resp models.AccountClosingBlockers
+
+	err := tx.QueryRowContext(ctx,
+		`SELECT
+			(SELECT count(1) FROM pots WHERE account_id = $1 AND status = 'active'),
+			(SELECT count(1) FROM loans WHERE account_id = $1 AND status <> 'repaid'),
+			(SELECT count(1) FROM term_deposits WHERE source_account_id = $1 AND status = 'active'),
+			(SELECT count(1) FROM transactions WHERE account_id = $1 AND NOT done AND deleted_at IS NULL)`,
+		accountID,
+	).Scan(&resp.Pots, &resp.Loans, &resp.Deposits, &resp.PendingTransactions)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return &resp, nil
+}
@@ -0,0 +1,238 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/helper"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/dilmurodov/online_banking/pkg/util"
+)
+
+type adminRepo struct {
+	db *sql.DB
+}
+
+func NewAdminRepo(db *sql.DB) *adminRepo {
+	return &adminRepo{db: db}
+}
+
+// SearchUsers looks users up by phone, name or exact id, newest first
+func (r *adminRepo) SearchUsers(ctx context.Context, req *models.SearchUsersRequest) (*models.SearchUsersResponse, error) {
+	var (
+		count int
+		users = make([]*models.User, 0)
+	)
+
+	qb := helper.NewQueryBuilder().Where("deleted_at = 0")
+	if util.IsValidUUID(req.Query) {
+		qb.Where("guid = ?", req.Query)
+	} else if req.Query != "" {
+		qb.Where("(phone ILIKE ? OR first_name ILIKE ? OR last_name ILIKE ?)",
+			"%"+req.Query+"%", "%"+req.Query+"%", "%"+req.Query+"%")
+	}
+	if req.Role != "" {
+		qb.Where("role = ?", req.Role)
+	}
+
+	query := `
+		SELECT
+			guid,
+			first_name,
+			last_name,
+			phone,
+			created_at,
+			updated_at,
+			role,
+			totp_enabled_at IS NOT NULL,
+			count(*) OVER() AS count
+		FROM "users"` + qb.WhereClause() + `
+		ORDER BY created_at DESC, guid DESC
+		LIMIT ` + qb.Arg(req.Limit) + ` OFFSET ` + qb.Arg(req.Offset)
+
+	rows, err := r.db.QueryContext(ctx, query, qb.Args()...)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var u models.User
+		if err := rows.Scan(
+			&u.Guid,
+			&u.FirstName,
+			&u.LastName,
+			&u.Phone,
+			&u.CreatedAt,
+			&u.UpdatedAt,
+			&u.Role,
+			&u.TwoFactorEnabled,
+			&count,
+		); err != nil {
+			return nil, &customerrors.InternalServerError{Message: err.Error()}
+		}
+		users = append(users, &u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return &models.SearchUsersResponse{Users: users, Count: count}, nil
+}
+
+// SearchAccounts looks accounts up by their id, the owner's id or the owner's phone.
+// System accounts are left out.
+func (r *adminRepo) SearchAccounts(ctx context.Context, req *models.SearchAccountsRequest) (*models.SearchAccountsResponse, error) {
+	var (
+		count    int
+		accounts = make([]*models.Account, 0)
+	)
+
+	qb := helper.NewQueryBuilder().
+		Where("a.deleted_at = 0").
+		Where("a.system = false")
+	if util.IsValidUUID(req.Query) {
+		qb.Where("(a.guid = ? OR a.user_id = ?)", req.Query, req.Query)
+	} else if req.Query != "" {
+		qb.Where("u.phone ILIKE ?", "%"+req.Query+"%")
+	}
+	if req.Status != "" {
+		qb.Where("a.status = ?", req.Status)
+	}
+
+	query := `
+		SELECT
+			a.guid,
+			a.user_id,
+			a.balance,
+			a.product,
+			a.accrued_interest,
+			a.overdraft_limit,
+			a.created_at,
+			a.updated_at,
+			a.status,
+			a.closed_at,
+			count(*) OVER() AS count
+		FROM accounts a
+		JOIN "users" u ON u.guid = a.user_id` + qb.WhereClause() + `
+		ORDER BY a.created_at DESC, a.guid DESC
+		LIMIT ` + qb.Arg(req.Limit) + ` OFFSET ` + qb.Arg(req.Offset)
+
+	rows, err := r.db.QueryContext(ctx, query, qb.Args()...)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			a         models.Account
+			createdAt sql.NullString
+			updatedAt sql.NullString
+			closedAt  sql.NullString
+		)
+		if err := rows.Scan(
+			&a.ID,
+			&a.UserID,
+			&a.Balance,
+			&a.Product,
+			&a.AccruedInterest,
+			&a.OverdraftLimit,
+			&createdAt,
+			&updatedAt,
+			&a.Status,
+			&closedAt,
+			&count,
+		); err != nil {
+			return nil, &customerrors.InternalServerError{Message: err.Error()}
+		}
+		a.CreatedAt = createdAt.String
+		a.UpdatedAt = updatedAt.String
+		a.ClosedAt = closedAt.String
+		accounts = append(accounts, &a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return &models.SearchAccountsResponse{Accounts: accounts, Count: count}, nil
+}
+
+// GetTransaction returns any transaction by its id, whoever owns the account
+func (r *adminRepo) GetTransaction(ctx context.Context, id string) (*models.Transaction, error) {
+	var (
+		createdAt     sql.NullString
+		doneTimestamp sql.NullString
+	)
+	t := &models.Transaction{}
+
+	err := r.db.QueryRowContext(ctx,
+		`SELECT
+			guid,
+			account_id,
+			transaction_amount,
+			transaction_type,
+			recipient_id,
+			description,
+			reference,
+			created_at,
+			approved,
+			done,
+			done_timestamp
+		FROM transactions
+		WHERE guid = $1 AND deleted_at IS NULL`,
+		id,
+	).Scan(
+		&t.ID,
+		&t.AccountID,
+		&t.Amount,
+		&t.Type,
+		&t.RecipientID,
+		&t.Description,
+		&t.Reference,
+		&createdAt,
+		&t.Approved,
+		&t.Done,
+		&doneTimestamp,
+	)
+	if err == sql.ErrNoRows {
+		return nil, &customerrors.TransactionNotFoundError{Guid: id}
+	} else if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	t.CreatedAt = createdAt.String
+	t.DoneTimestamp = doneTimestamp.String
+
+	return t, nil
+}
+
+// CreateManualAdjustment records the adjustment in the caller's transaction
+func (r *adminRepo) CreateManualAdjustment(ctx context.Context, tx *sql.Tx, req *models.ManualAdjustment) error {
+	err := tx.QueryRowContext(ctx,
+		`INSERT INTO manual_adjustments (
+			account_id,
+			direction,
+			amount,
+			reason_code,
+			comment,
+			actor_id,
+			approved_by,
+			transaction_id
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING guid, created_at`,
+		req.AccountID,
+		req.Direction,
+		req.Amount,
+		req.ReasonCode,
+		req.Comment,
+		req.ActorID,
+		toNullString(req.ApprovedBy),
+		req.TransactionID,
+	).Scan(&req.ID, &req.CreatedAt)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return nil
+}
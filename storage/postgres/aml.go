@@ -0,0 +1,442 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dilmurodov/online_banking/config"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/helper"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+type amlRepo struct {
+	db *sql.DB
+}
+
+func NewAMLRepo(db *sql.DB) *amlRepo {
+	return &amlRepo{db: db}
+}
+
+// GetAMLCursor reads where the monitoring stopped and holds the cursor until the transaction
+// ends, so only one instance reads a batch
+func (r *amlRepo) GetAMLCursor(ctx context.Context, tx *sql.Tx) (*models.AMLCursor, error) {
+	var cursor models.AMLCursor
+
+	err := tx.QueryRowContext(ctx,
+		`SELECT last_created_at, last_transaction_id FROM aml_monitor_cursor WHERE id = 1 FOR UPDATE`,
+	).Scan(&cursor.CreatedAt, &cursor.TransactionID)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return &cursor, nil
+}
+
+func (r *amlRepo) SetAMLCursor(ctx context.Context, tx *sql.Tx, cursor *models.AMLCursor) error {
+	_, err := tx.ExecContext(ctx,
+		`UPDATE aml_monitor_cursor SET
+			last_created_at = $1,
+			last_transaction_id = $2,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = 1`,
+		cursor.CreatedAt,
+		cursor.TransactionID,
+	)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+	return nil
+}
+
+const amlTransactionColumns = `
+			t.guid,
+			a.user_id,
+			t.account_id,
+			t.recipient_id,
+			t.transaction_type,
+			t.transaction_amount,
+			t.description,
+			t.reference,
+			t.created_at`
+
+func scanAMLTransactions(rows *sql.Rows) ([]*models.AMLTransaction, error) {
+	defer rows.Close()
+
+	resp := make([]*models.AMLTransaction, 0)
+	for rows.Next() {
+		var t models.AMLTransaction
+		err := rows.Scan(
+			&t.ID,
+			&t.UserID,
+			&t.AccountID,
+			&t.RecipientID,
+			&t.Type,
+			&t.Amount,
+			&t.Description,
+			&t.Reference,
+			&t.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		resp = append(resp, &t)
+	}
+	return resp, rows.Err()
+}
+
+// GetAMLTransactions returns the customers' transactions after the cursor in the order they were made,
+// the bank's own ledger accounts are left out
+func (r *amlRepo) GetAMLTransactions(ctx context.Context, tx *sql.Tx, req *models.GetAMLTransactionsRequest) ([]*models.AMLTransaction, error) {
+	rows, err := tx.QueryContext(ctx,
+		`SELECT`+amlTransactionColumns+`
+		FROM transactions t
+		JOIN accounts a ON a.guid = t.account_id
+		WHERE (t.created_at, t.guid) > ($1, $2)
+			AND t.created_at < $3
+			AND t.deleted_at IS NULL
+			AND a.user_id <> $4
+		ORDER BY t.created_at, t.guid
+		LIMIT $5`,
+		req.After.CreatedAt,
+		req.After.TransactionID,
+		req.Before,
+		config.SystemUserID,
+		req.Limit,
+	)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	resp, err := scanAMLTransactions(rows)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	return resp, nil
+}
+
+// GetAMLActivity returns the account's transactions in the period, oldest first
+func (r *amlRepo) GetAMLActivity(ctx context.Context, tx *sql.Tx, req *models.GetAMLActivityRequest) ([]*models.AMLTransaction, error) {
+	rows, err := tx.QueryContext(ctx,
+		`SELECT`+amlTransactionColumns+`
+		FROM transactions t
+		JOIN accounts a ON a.guid = t.account_id
+		WHERE t.account_id = $1
+			AND t.created_at >= $2
+			AND t.created_at <= $3
+			AND t.deleted_at IS NULL
+		ORDER BY t.created_at, t.guid`,
+		req.AccountID,
+		req.From,
+		req.To,
+	)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	resp, err := scanAMLTransactions(rows)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	return resp, nil
+}
+
+const amlCaseColumns = `
+			c.guid,
+			c.user_id,
+			c.account_id,
+			c.case_type,
+			c.status,
+			c.reason,
+			c.assignee_id,
+			c.report_reference,
+			(SELECT count(*) FROM aml_case_transactions ct WHERE ct.case_id = c.guid),
+			c.created_at,
+			c.updated_at`
+
+func scanAMLCase(row rowScanner, extra ...interface{}) (*models.AMLCase, error) {
+	var (
+		c          models.AMLCase
+		assigneeID sql.NullString
+	)
+
+	dest := []interface{}{
+		&c.ID,
+		&c.UserID,
+		&c.AccountID,
+		&c.Type,
+		&c.Status,
+		&c.Reason,
+		&assigneeID,
+		&c.ReportReference,
+		&c.TransactionCount,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	c.AssigneeID = assigneeID.String
+
+	return &c, nil
+}
+
+// GetActiveAMLCase returns the account's case of the type that is still being worked on
+func (r *amlRepo) GetActiveAMLCase(ctx context.Context, tx *sql.Tx, accountID, caseType string) (*models.AMLCase, error) {
+	resp, err := scanAMLCase(tx.QueryRowContext(ctx,
+		`SELECT`+amlCaseColumns+`
+		FROM aml_cases c
+		WHERE c.account_id = $1 AND c.case_type = $2 AND c.status IN ('open', 'investigating')`,
+		accountID,
+		caseType,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &customerrors.AMLCaseNotFoundError{}
+	} else if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return resp, nil
+}
+
+// CreateAMLCase opens a case and starts its history
+func (r *amlRepo) CreateAMLCase(ctx context.Context, tx *sql.Tx, req *models.AMLCase) (*models.AMLCase, error) {
+	resp := *req
+	resp.Status = models.AMLCaseStatusOpen
+
+	err := tx.QueryRowContext(ctx,
+		`INSERT INTO aml_cases (
+			user_id,
+			account_id,
+			case_type,
+			reason
+		) VALUES ($1, $2, $3, $4)
+		RETURNING guid, created_at, updated_at`,
+		req.UserID,
+		req.AccountID,
+		req.Type,
+		req.Reason,
+	).Scan(&resp.ID, &resp.CreatedAt, &resp.UpdatedAt)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO aml_case_history (case_id, to_status, comment) VALUES ($1, $2, $3)`,
+		resp.ID,
+		resp.Status,
+		req.Reason,
+	)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return &resp, nil
+}
+
+// LinkAMLCaseTransactions adds the transactions the case does not have yet and returns how many were added
+func (r *amlRepo) LinkAMLCaseTransactions(ctx context.Context, tx *sql.Tx, caseID string, transactionIDs []string) (int64, error) {
+	result, err := tx.ExecContext(ctx,
+		`INSERT INTO aml_case_transactions (case_id, transaction_id)
+		SELECT $1, unnest($2::uuid[])
+		ON CONFLICT DO NOTHING`,
+		caseID,
+		pq.Array(transactionIDs),
+	)
+	if err != nil {
+		return 0, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	if n == 0 {
+		return 0, nil
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE aml_cases SET updated_at = CURRENT_TIMESTAMP WHERE guid = $1`, caseID)
+	if err != nil {
+		return 0, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return n, nil
+}
+
+// GetAMLCases returns the cases matching the filters, newest first
+func (r *amlRepo) GetAMLCases(ctx context.Context, req *models.GetAMLCasesRequest) (*models.GetAMLCasesResponse, error) {
+	var count int
+	resp := &models.GetAMLCasesResponse{
+		Cases: make([]*models.AMLCase, 0),
+	}
+
+	qb := helper.NewQueryBuilder()
+	if req.Status != "" {
+		qb.Where("c.status = ?", req.Status)
+	}
+	if req.Type != "" {
+		qb.Where("c.case_type = ?", req.Type)
+	}
+	if req.AccountID != "" {
+		qb.Where("c.account_id = ?", req.AccountID)
+	}
+
+	query := `SELECT` + amlCaseColumns + `,
+			count(1) OVER() AS count
+		FROM aml_cases c` + qb.WhereClause() + `
+		ORDER BY c.created_at DESC, c.guid DESC
+		LIMIT ` + qb.Arg(req.Limit) + ` OFFSET ` + qb.Arg(req.Offset)
+
+	rows, err := r.db.QueryContext(ctx, query, qb.Args()...)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		c, err := scanAMLCase(rows, &count)
+		if err != nil {
+			return nil, &customerrors.InternalServerError{Message: err.Error()}
+		}
+		resp.Cases = append(resp.Cases, c)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	resp.Count = count
+
+	return resp, nil
+}
+
+func (r *amlRepo) GetAMLCase(ctx context.Context, id string) (*models.AMLCase, error) {
+	resp, err := scanAMLCase(r.db.QueryRowContext(ctx,
+		`SELECT`+amlCaseColumns+`
+		FROM aml_cases c
+		WHERE c.guid = $1`,
+		id,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &customerrors.AMLCaseNotFoundError{Guid: id}
+	} else if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return resp, nil
+}
+
+// UpdateAMLCaseStatus moves the case on from the status it was read in and records the change.
+// Taking a case into investigation assigns it to the actor.
+func (r *amlRepo) UpdateAMLCaseStatus(ctx context.Context, tx *sql.Tx, req *models.UpdateAMLCaseStatusRequest, from string) error {
+	result, err := tx.ExecContext(ctx,
+		`UPDATE aml_cases SET
+			status = $3,
+			assignee_id = CASE WHEN $3 = 'investigating' THEN $4::uuid ELSE assignee_id END,
+			report_reference = CASE WHEN $3 = 'reported' THEN $5 ELSE report_reference END,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE guid = $1 AND status = $2`,
+		req.ID,
+		from,
+		req.Status,
+		req.ActorID,
+		req.ReportReference,
+	)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	} else if n == 0 {
+		return &customerrors.AMLCaseStatusError{From: from, To: req.Status}
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO aml_case_history (
+			case_id,
+			from_status,
+			to_status,
+			actor_id,
+			comment
+		) VALUES ($1, $2, $3, $4, $5)`,
+		req.ID,
+		from,
+		req.Status,
+		req.ActorID,
+		req.Comment,
+	)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return nil
+}
+
+// GetAMLCaseTransactions returns the transactions linked to the case, oldest first
+func (r *amlRepo) GetAMLCaseTransactions(ctx context.Context, caseID string) ([]*models.AMLTransaction, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT`+amlTransactionColumns+`
+		FROM aml_case_transactions ct
+		JOIN transactions t ON t.guid = ct.transaction_id
+		JOIN accounts a ON a.guid = t.account_id
+		WHERE ct.case_id = $1
+		ORDER BY t.created_at, t.guid`,
+		caseID,
+	)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	resp, err := scanAMLTransactions(rows)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	return resp, nil
+}
+
+func (r *amlRepo) GetAMLCaseHistory(ctx context.Context, caseID string) ([]*models.AMLCaseStatusChange, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT
+			guid,
+			case_id,
+			from_status,
+			to_status,
+			actor_id,
+			comment,
+			created_at
+		FROM aml_case_history
+		WHERE case_id = $1
+		ORDER BY created_at, guid`,
+		caseID,
+	)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	resp := make([]*models.AMLCaseStatusChange, 0)
+	for rows.Next() {
+		var (
+			c       models.AMLCaseStatusChange
+			actorID sql.NullString
+		)
+		err = rows.Scan(
+			&c.ID,
+			&c.CaseID,
+			&c.FromStatus,
+			&c.ToStatus,
+			&actorID,
+			&c.Comment,
+			&c.CreatedAt,
+		)
+		if err != nil {
+			return nil, &customerrors.InternalServerError{Message: err.Error()}
+		}
+		c.ActorID = actorID.String
+		resp = append(resp, &c)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,284 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/dilmurodov/online_banking/pkg/auditlog"
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/helper"
+	"github.com/dilmurodov/online_banking/pkg/models"
+)
+
+type auditRepo struct {
+	db *sql.DB
+}
+
+func NewAuditRepo(db *sql.DB) *auditRepo {
+	return &auditRepo{db: db}
+}
+
+// BeginTx begins a transaction that may append to the audit chain. The table is locked
+// before anything else runs: a serializable transaction takes its snapshot at its first
+// query and a lock taken after it would leave the head read stale, LOCK TABLE takes none.
+// The lock lets readers through and makes writers append one at a time until the transaction ends.
+func (r *auditRepo) BeginTx(ctx context.Context) (*sql.Tx, error) {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{
+		Isolation: sql.LevelSerializable,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = tx.ExecContext(ctx, `LOCK TABLE audit_events IN EXCLUSIVE MODE`); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return tx, nil
+}
+
+const auditEventColumns = `
+			guid,
+			seq,
+			event_type,
+			actor_id,
+			user_id,
+			resource_type,
+			resource_id,
+			ip,
+			request_id,
+			details,
+			before,
+			after,
+			prev_hash,
+			hash,
+			created_at`
+
+func scanAuditEvent(row rowScanner, extra ...interface{}) (*models.AuditEvent, error) {
+	var (
+		e       models.AuditEvent
+		actorID sql.NullString
+		userID  sql.NullString
+		details []byte
+		before  []byte
+		after   []byte
+	)
+
+	dest := []interface{}{
+		&e.ID,
+		&e.Seq,
+		&e.EventType,
+		&actorID,
+		&userID,
+		&e.ResourceType,
+		&e.ResourceID,
+		&e.IP,
+		&e.RequestID,
+		&details,
+		&before,
+		&after,
+		&e.PrevHash,
+		&e.Hash,
+		&e.CreatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(details, &e.Details); err != nil {
+		return nil, err
+	}
+	if before != nil {
+		if err := json.Unmarshal(before, &e.Before); err != nil {
+			return nil, err
+		}
+	}
+	if after != nil {
+		if err := json.Unmarshal(after, &e.After); err != nil {
+			return nil, err
+		}
+	}
+	e.ActorID = actorID.String
+	e.UserID = userID.String
+
+	return &e, nil
+}
+
+// marshalSnapshot keeps a missing snapshot NULL
+func marshalSnapshot(v map[string]interface{}) (sql.NullString, error) {
+	if v == nil {
+		return sql.NullString{}, nil
+	}
+	body, err := json.Marshal(v)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(body), Valid: true}, nil
+}
+
+// CreateAuditEvent appends the event to the chain in the caller's transaction, which has to be
+// begun with BeginTx so the head read here stays the head until the event is committed after it.
+// The request id and the client's address are taken from the context when the event has none.
+func (r *auditRepo) CreateAuditEvent(ctx context.Context, tx *sql.Tx, req *models.AuditEvent) error {
+	if req.RequestID == "" {
+		req.RequestID = auditlog.RequestID(ctx)
+	}
+	if req.IP == "" {
+		req.IP = auditlog.ClientIP(ctx)
+	}
+
+	details, err := json.Marshal(req.Details)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+	before, err := marshalSnapshot(req.Before)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+	after, err := marshalSnapshot(req.After)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	err = tx.QueryRowContext(ctx,
+		`SELECT hash FROM audit_events WHERE hash <> '' ORDER BY seq DESC LIMIT 1`,
+	).Scan(&req.PrevHash)
+	if err != nil && err != sql.ErrNoRows {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	req.CreatedAt = auditlog.Now()
+	if req.Hash, err = auditlog.Hash(req.PrevHash, req); err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO audit_events (
+			event_type,
+			user_id,
+			ip,
+			details,
+			actor_id,
+			resource_type,
+			resource_id,
+			request_id,
+			before,
+			after,
+			prev_hash,
+			hash,
+			created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING guid, seq`,
+		req.EventType,
+		toNullString(req.UserID),
+		req.IP,
+		details,
+		toNullString(req.ActorID),
+		req.ResourceType,
+		req.ResourceID,
+		req.RequestID,
+		before,
+		after,
+		req.PrevHash,
+		req.Hash,
+		req.CreatedAt,
+	).Scan(&req.ID, &req.Seq)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return nil
+}
+
+// GetAuditEvents returns the events matching the filters, newest first
+func (r *auditRepo) GetAuditEvents(ctx context.Context, req *models.GetAuditEventsRequest) (*models.GetAuditEventsResponse, error) {
+	var (
+		count  int
+		events = make([]*models.AuditEvent, 0)
+	)
+
+	qb := helper.NewQueryBuilder()
+	if req.EventType != "" {
+		qb.Where("event_type = ?", req.EventType)
+	}
+	if req.ActorID != "" {
+		qb.Where("actor_id = ?", req.ActorID)
+	}
+	if req.UserID != "" {
+		qb.Where("user_id = ?", req.UserID)
+	}
+	if req.ResourceType != "" {
+		qb.Where("resource_type = ?", req.ResourceType)
+	}
+	if req.ResourceID != "" {
+		qb.Where("resource_id = ?", req.ResourceID)
+	}
+	if req.RequestID != "" {
+		qb.Where("request_id = ?", req.RequestID)
+	}
+	if req.From != "" {
+		qb.Where("created_at >= ?", req.From)
+	}
+	if req.To != "" {
+		qb.Where("created_at < ?", req.To)
+	}
+
+	query := `
+		SELECT` + auditEventColumns + `,
+			count(*) OVER() AS count
+		FROM audit_events` + qb.WhereClause() + `
+		ORDER BY seq DESC
+		LIMIT ` + qb.Arg(req.Limit) + ` OFFSET ` + qb.Arg(req.Offset)
+
+	rows, err := r.db.QueryContext(ctx, query, qb.Args()...)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		e, err := scanAuditEvent(rows, &count)
+		if err != nil {
+			return nil, &customerrors.InternalServerError{Message: err.Error()}
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return &models.GetAuditEventsResponse{Events: events, Count: count}, nil
+}
+
+// GetAuditChain returns up to limit events after the given seq in chain order
+func (r *auditRepo) GetAuditChain(ctx context.Context, afterSeq int64, limit int) ([]*models.AuditEvent, error) {
+	events := make([]*models.AuditEvent, 0)
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT`+auditEventColumns+`
+		FROM audit_events
+		WHERE seq > $1
+		ORDER BY seq
+		LIMIT $2`,
+		afterSeq,
+		limit,
+	)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		e, err := scanAuditEvent(rows)
+		if err != nil {
+			return nil, &customerrors.InternalServerError{Message: err.Error()}
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return events, nil
+}
@@ -0,0 +1,211 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/models"
+)
+
+type beneficiaryRepo struct {
+	db *sql.DB
+}
+
+func NewBeneficiaryRepo(db *sql.DB) *beneficiaryRepo {
+	return &beneficiaryRepo{db: db}
+}
+
+const beneficiaryColumns = `
+			guid,
+			user_id,
+			nickname,
+			account_id,
+			transfer_limit,
+			daily_limit,
+			created_at,
+			updated_at`
+
+func scanBeneficiary(row rowScanner, extra ...interface{}) (*models.Beneficiary, error) {
+	var b models.Beneficiary
+
+	dest := []interface{}{
+		&b.ID,
+		&b.UserID,
+		&b.Nickname,
+		&b.AccountID,
+		&b.TransferLimit,
+		&b.DailyLimit,
+		&b.CreatedAt,
+		&b.UpdatedAt,
+	}
+
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	return &b, nil
+}
+
+func beneficiaryWriteError(err error) error {
+	if strings.Contains(err.Error(), "beneficiaries_user_id_nickname_unique") ||
+		strings.Contains(err.Error(), "beneficiaries_user_id_account_id_unique") {
+		return &customerrors.BeneficiaryAlreadyExistsError{}
+	}
+	return &customerrors.InternalServerError{Message: err.Error()}
+}
+
+func (r *beneficiaryRepo) CreateBeneficiary(ctx context.Context, tx *sql.Tx, req *models.CreateBeneficiaryRequest) (*models.Beneficiary, error) {
+	resp, err := scanBeneficiary(tx.QueryRowContext(ctx,
+		`INSERT INTO beneficiaries (
+			user_id,
+			nickname,
+			account_id,
+			transfer_limit,
+			daily_limit
+		) VALUES ($1, $2, $3, $4, $5)
+		RETURNING`+beneficiaryColumns,
+		req.UserID,
+		req.Nickname,
+		req.AccountID,
+		req.TransferLimit,
+		req.DailyLimit,
+	))
+	if err != nil {
+		return nil, beneficiaryWriteError(err)
+	}
+
+	return resp, nil
+}
+
+func (r *beneficiaryRepo) GetBeneficiaryByID(ctx context.Context, req *models.BeneficiaryByIDRequest) (*models.Beneficiary, error) {
+	resp, err := scanBeneficiary(r.db.QueryRowContext(ctx,
+		`SELECT`+beneficiaryColumns+`
+		FROM beneficiaries
+		WHERE guid = $1 AND user_id = $2 AND deleted_at IS NULL`,
+		req.ID,
+		req.UserID,
+	))
+	if err != nil && err == sql.ErrNoRows {
+		return nil, &customerrors.BeneficiaryNotFoundError{Guid: req.ID}
+	} else if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return resp, nil
+}
+
+// GetBeneficiaryForUpdate locks the user's beneficiary until the end of the transaction
+func (r *beneficiaryRepo) GetBeneficiaryForUpdate(ctx context.Context, tx *sql.Tx, req *models.BeneficiaryByIDRequest) (*models.Beneficiary, error) {
+	resp, err := scanBeneficiary(tx.QueryRowContext(ctx,
+		`SELECT`+beneficiaryColumns+`
+		FROM beneficiaries
+		WHERE guid = $1 AND user_id = $2 AND deleted_at IS NULL
+		FOR UPDATE`,
+		req.ID,
+		req.UserID,
+	))
+	if err == sql.ErrNoRows {
+		return nil, &customerrors.BeneficiaryNotFoundError{Guid: req.ID}
+	} else if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return resp, nil
+}
+
+// GetBeneficiaryByAccount returns the user's beneficiary for the account, nil when the account isn't in
+// the address book
+func (r *beneficiaryRepo) GetBeneficiaryByAccount(ctx context.Context, req *models.BeneficiaryByAccountRequest) (*models.Beneficiary, error) {
+	resp, err := scanBeneficiary(r.db.QueryRowContext(ctx,
+		`SELECT`+beneficiaryColumns+`
+		FROM beneficiaries
+		WHERE user_id = $1 AND account_id = $2 AND deleted_at IS NULL`,
+		req.UserID,
+		req.AccountID,
+	))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return resp, nil
+}
+
+func (r *beneficiaryRepo) GetBeneficiaries(ctx context.Context, req *models.GetBeneficiariesRequest) (*models.GetBeneficiariesResponse, error) {
+	var count int
+	resp := &models.GetBeneficiariesResponse{
+		Beneficiaries: make([]*models.Beneficiary, 0),
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT`+beneficiaryColumns+`,
+			count(1) OVER() AS count
+		FROM beneficiaries
+		WHERE user_id = $1 AND deleted_at IS NULL
+		ORDER BY nickname`,
+		req.UserID,
+	)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		b, err := scanBeneficiary(rows, &count)
+		if err != nil {
+			return nil, &customerrors.InternalServerError{Message: err.Error()}
+		}
+		resp.Beneficiaries = append(resp.Beneficiaries, b)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	resp.Count = count
+
+	return resp, nil
+}
+
+func (r *beneficiaryRepo) UpdateBeneficiary(ctx context.Context, tx *sql.Tx, req *models.UpdateBeneficiaryRequest) (*models.Beneficiary, error) {
+	resp, err := scanBeneficiary(tx.QueryRowContext(ctx,
+		`UPDATE beneficiaries SET
+			nickname = $1,
+			transfer_limit = $2,
+			daily_limit = $3,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE guid = $4 AND user_id = $5 AND deleted_at IS NULL
+		RETURNING`+beneficiaryColumns,
+		req.Nickname,
+		req.TransferLimit,
+		req.DailyLimit,
+		req.ID,
+		req.UserID,
+	))
+	if err != nil && err == sql.ErrNoRows {
+		return nil, &customerrors.BeneficiaryNotFoundError{Guid: req.ID}
+	} else if err != nil {
+		return nil, beneficiaryWriteError(err)
+	}
+
+	return resp, nil
+}
+
+func (r *beneficiaryRepo) DeleteBeneficiary(ctx context.Context, tx *sql.Tx, req *models.BeneficiaryByIDRequest) error {
+	result, err := tx.ExecContext(ctx,
+		`UPDATE beneficiaries SET
+			deleted_at = CURRENT_TIMESTAMP
+		WHERE guid = $1 AND user_id = $2 AND deleted_at IS NULL`,
+		req.ID,
+		req.UserID,
+	)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+	if cn, err := result.RowsAffected(); err != nil || cn == 0 {
+		return &customerrors.BeneficiaryNotFoundError{Guid: req.ID}
+	}
+
+	return nil
+}
@@ -0,0 +1,324 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/helper"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/pkg/errors"
+)
+
+type depositRepo struct {
+	db *sql.DB
+}
+
+func NewDepositRepo(db *sql.DB) *depositRepo {
+	return &depositRepo{db: db}
+}
+
+const depositColumns = `
+			guid,
+			user_id,
+			account_id,
+			source_account_id,
+			principal,
+			term_months,
+			annual_rate,
+			early_withdrawal_rate,
+			day_count,
+			interest_payout,
+			on_maturity,
+			status,
+			to_char(opened_on, 'YYYY-MM-DD'),
+			to_char(maturity_date, 'YYYY-MM-DD'),
+			to_char(interest_paid_until, 'YYYY-MM-DD'),
+			interest_periods_paid,
+			paid_interest,
+			rolled_from_id,
+			closed_at,
+			created_at,
+			updated_at`
+
+func scanDeposit(row rowScanner, extra ...interface{}) (*models.TermDeposit, error) {
+	var (
+		d            models.TermDeposit
+		rolledFromID sql.NullString
+		closedAt     sql.NullString
+	)
+
+	dest := []interface{}{
+		&d.ID,
+		&d.UserID,
+		&d.AccountID,
+		&d.SourceAccountID,
+		&d.Principal,
+		&d.TermMonths,
+		&d.AnnualRate,
+		&d.EarlyWithdrawalRate,
+		&d.DayCount,
+		&d.InterestPayout,
+		&d.OnMaturity,
+		&d.Status,
+		&d.OpenedOn,
+		&d.MaturityDate,
+		&d.InterestPaidUntil,
+		&d.InterestPeriodsPaid,
+		&d.PaidInterest,
+		&rolledFromID,
+		&closedAt,
+		&d.CreatedAt,
+		&d.UpdatedAt,
+	}
+
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	d.RolledFromID = rolledFromID.String
+	d.ClosedAt = closedAt.String
+
+	return &d, nil
+}
+
+func (r *depositRepo) GetDepositTerms(ctx context.Context) (*models.GetDepositTermsResponse, error) {
+	resp := &models.GetDepositTermsResponse{
+		Terms: make([]*models.DepositTerm, 0),
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT
+			term_months,
+			annual_rate,
+			early_withdrawal_rate,
+			day_count,
+			min_amount
+		FROM term_deposit_terms
+		ORDER BY term_months`)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t models.DepositTerm
+		if err := rows.Scan(&t.TermMonths, &t.AnnualRate, &t.EarlyWithdrawalRate, &t.DayCount, &t.MinAmount); err != nil {
+			return nil, &customerrors.InternalServerError{Message: err.Error()}
+		}
+		resp.Terms = append(resp.Terms, &t)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return resp, nil
+}
+
+// GetDepositTerm returns the offered term, or nil when the bank does not offer it
+func (r *depositRepo) GetDepositTerm(ctx context.Context, termMonths int) (*models.DepositTerm, error) {
+	var t models.DepositTerm
+
+	err := r.db.QueryRowContext(ctx,
+		`SELECT
+			term_months,
+			annual_rate,
+			early_withdrawal_rate,
+			day_count,
+			min_amount
+		FROM term_deposit_terms
+		WHERE term_months = $1`, termMonths,
+	).Scan(&t.TermMonths, &t.AnnualRate, &t.EarlyWithdrawalRate, &t.DayCount, &t.MinAmount)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return &t, nil
+}
+
+// CreateDeposit stores the deposit. Without an account id a new deposit account is opened
+// for it in the same statement, a rolled over deposit keeps the account of its predecessor.
+func (r *depositRepo) CreateDeposit(ctx context.Context, tx *sql.Tx, req *models.TermDeposit) (*models.TermDeposit, error) {
+	resp, err := scanDeposit(tx.QueryRowContext(ctx,
+		`WITH deposit_account AS (
+			INSERT INTO accounts (user_id, balance, product)
+			SELECT $1, 0, 'term_deposit'
+			WHERE $2::uuid IS NULL
+			RETURNING guid
+		)
+		INSERT INTO term_deposits (
+			user_id,
+			account_id,
+			source_account_id,
+			principal,
+			term_months,
+			annual_rate,
+			early_withdrawal_rate,
+			day_count,
+			interest_payout,
+			on_maturity,
+			opened_on,
+			maturity_date,
+			interest_paid_until,
+			rolled_from_id
+		)
+		SELECT
+			$1,
+			COALESCE($2::uuid, (SELECT guid FROM deposit_account)),
+			$3, $4::numeric, $5, $6::numeric, $7::numeric, $8, $9, $10, $11, $12, $11, $13
+		RETURNING`+depositColumns,
+		req.UserID,
+		toNullString(req.AccountID),
+		req.SourceAccountID,
+		req.Principal,
+		req.TermMonths,
+		req.AnnualRate,
+		req.EarlyWithdrawalRate,
+		req.DayCount,
+		req.InterestPayout,
+		req.OnMaturity,
+		req.OpenedOn,
+		req.MaturityDate,
+		toNullString(req.RolledFromID),
+	))
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return resp, nil
+}
+
+func (r *depositRepo) GetDepositByID(ctx context.Context, req *models.DepositByIDRequest) (*models.TermDeposit, error) {
+	resp, err := scanDeposit(r.db.QueryRowContext(ctx,
+		`SELECT`+depositColumns+`
+		FROM term_deposits
+		WHERE guid = $1 AND user_id = $2`,
+		req.ID,
+		req.UserID,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &customerrors.DepositNotFoundError{Guid: req.ID}
+	} else if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return resp, nil
+}
+
+// GetDepositForUpdate locks the deposit row until the transaction ends, so the maturity job
+// and an early withdrawal can't settle the same deposit twice
+func (r *depositRepo) GetDepositForUpdate(ctx context.Context, tx *sql.Tx, id string) (*models.TermDeposit, error) {
+	resp, err := scanDeposit(tx.QueryRowContext(ctx,
+		`SELECT`+depositColumns+`
+		FROM term_deposits
+		WHERE guid = $1
+		FOR UPDATE`,
+		id,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &customerrors.DepositNotFoundError{Guid: id}
+	} else if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return resp, nil
+}
+
+func (r *depositRepo) GetDeposits(ctx context.Context, req *models.GetDepositsRequest) (*models.GetDepositsResponse, error) {
+	var count int
+	resp := &models.GetDepositsResponse{
+		Deposits: make([]*models.TermDeposit, 0),
+	}
+
+	qb := helper.NewQueryBuilder().Where("user_id = ?", req.UserID)
+	if req.Status != "" {
+		qb.Where("status = ?", req.Status)
+	}
+
+	query := `SELECT` + depositColumns + `,
+			count(1) OVER() AS count
+		FROM term_deposits` + qb.WhereClause() + `
+		ORDER BY created_at DESC
+		LIMIT ` + qb.Arg(req.Limit) + ` OFFSET ` + qb.Arg(req.Offset)
+
+	rows, err := r.db.QueryContext(ctx, query, qb.Args()...)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		d, err := scanDeposit(rows, &count)
+		if err != nil {
+			return nil, &customerrors.InternalServerError{Message: err.Error()}
+		}
+		resp.Deposits = append(resp.Deposits, d)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	resp.Count = count
+
+	return resp, nil
+}
+
+// GetDueDepositIDs returns the active deposits with a monthly interest payment or the maturity due by the date
+func (r *depositRepo) GetDueDepositIDs(ctx context.Context, dueDate string) ([]string, error) {
+	ids := make([]string, 0)
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT guid
+		FROM term_deposits
+		WHERE
+			status = 'active' AND (
+				maturity_date <= $1 OR
+				(interest_payout = 'monthly' AND (opened_on + (interest_periods_paid + 1) * interval '1 month')::date <= $1)
+			)
+		ORDER BY maturity_date`,
+		dueDate,
+	)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, &customerrors.InternalServerError{Message: err.Error()}
+		}
+		ids = append(ids, id)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return ids, nil
+}
+
+// UpdateDeposit saves the settlement state of a deposit locked by GetDepositForUpdate
+func (r *depositRepo) UpdateDeposit(ctx context.Context, tx *sql.Tx, req *models.TermDeposit) error {
+	_, err := tx.ExecContext(ctx,
+		`UPDATE term_deposits SET
+			status = $2,
+			on_maturity = $3,
+			interest_paid_until = $4,
+			interest_periods_paid = $5,
+			paid_interest = $6::numeric,
+			closed_at = CASE WHEN $2 = 'active' THEN NULL ELSE COALESCE(closed_at, CURRENT_TIMESTAMP) END,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE guid = $1`,
+		req.ID,
+		req.Status,
+		req.OnMaturity,
+		req.InterestPaidUntil,
+		req.InterestPeriodsPaid,
+		req.PaidInterest,
+	)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return nil
+}
@@ -0,0 +1,279 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/helper"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/pkg/errors"
+)
+
+type fraudRepo struct {
+	db *sql.DB
+}
+
+func NewFraudRepo(db *sql.DB) *fraudRepo {
+	return &fraudRepo{db: db}
+}
+
+// GetFraudSignals reads the user's recent debits across all of their accounts
+func (r *fraudRepo) GetFraudSignals(ctx context.Context, req *models.GetFraudSignalsRequest) (*models.FraudSignals, error) {
+	var resp = models.FraudSignals{CounterpartyID: req.CounterpartyID}
+
+	err := r.db.QueryRowContext(ctx,
+		`SELECT
+			count(*) FILTER (WHERE t.account_id = $2 AND t.created_at > $4),
+			COALESCE(avg(t.transaction_amount) FILTER (WHERE t.created_at > $5), 0),
+			count(*) FILTER (WHERE t.created_at > $5),
+			count(*) FILTER (WHERE t.recipient_id = $3),
+			EXISTS (SELECT 1 FROM accounts WHERE guid = $3 AND user_id = $1)
+		FROM transactions t
+		JOIN accounts a ON a.guid = t.account_id
+		WHERE a.user_id = $1 AND t.transaction_type = 'debit' AND t.deleted_at IS NULL`,
+		req.UserID,
+		req.AccountID,
+		toNullString(req.CounterpartyID),
+		req.HourAgo,
+		req.Since,
+	).Scan(
+		&resp.DebitsLastHour,
+		&resp.AverageDebit,
+		&resp.DebitCount,
+		&resp.CounterpartyPayments,
+		&resp.OwnCounterparty,
+	)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return &resp, nil
+}
+
+const fraudDecisionColumns = `
+			guid,
+			user_id,
+			account_id,
+			operation,
+			counterparty_id,
+			amount,
+			score,
+			outcome,
+			hits,
+			challenge_status,
+			review_status,
+			reviewed_by,
+			review_comment,
+			reviewed_at,
+			created_at`
+
+func scanFraudDecision(row rowScanner, extra ...interface{}) (*models.FraudDecision, error) {
+	var (
+		d               models.FraudDecision
+		counterpartyID  sql.NullString
+		hits            []byte
+		challengeStatus sql.NullString
+		reviewedBy      sql.NullString
+		reviewedAt      sql.NullString
+	)
+
+	dest := []interface{}{
+		&d.ID,
+		&d.UserID,
+		&d.AccountID,
+		&d.Operation,
+		&counterpartyID,
+		&d.Amount,
+		&d.Score,
+		&d.Outcome,
+		&hits,
+		&challengeStatus,
+		&d.ReviewStatus,
+		&reviewedBy,
+		&d.ReviewComment,
+		&reviewedAt,
+		&d.CreatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(hits, &d.Hits); err != nil {
+		return nil, err
+	}
+	d.CounterpartyID = counterpartyID.String
+	d.ChallengeStatus = challengeStatus.String
+	d.ReviewedBy = reviewedBy.String
+	d.ReviewedAt = reviewedAt.String
+
+	return &d, nil
+}
+
+// CreateFraudDecision stores the decision on its own, a blocked payment's transaction never commits
+func (r *fraudRepo) CreateFraudDecision(ctx context.Context, req *models.FraudDecision) (*models.FraudDecision, error) {
+	hits, err := json.Marshal(req.Hits)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	resp, err := scanFraudDecision(r.db.QueryRowContext(ctx,
+		`INSERT INTO fraud_decisions (
+			user_id,
+			account_id,
+			operation,
+			counterparty_id,
+			amount,
+			score,
+			outcome,
+			hits,
+			challenge_status
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING`+fraudDecisionColumns,
+		req.UserID,
+		req.AccountID,
+		req.Operation,
+		toNullString(req.CounterpartyID),
+		req.Amount,
+		req.Score,
+		req.Outcome,
+		hits,
+		toNullString(req.ChallengeStatus),
+	))
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return resp, nil
+}
+
+// PassFraudChallenge marks the user's pending challenge as confirmed
+func (r *fraudRepo) PassFraudChallenge(ctx context.Context, tx *sql.Tx, id, userID string) error {
+	result, err := tx.ExecContext(ctx,
+		`UPDATE fraud_decisions SET challenge_status = 'passed'
+		WHERE guid = $1 AND user_id = $2 AND challenge_status = 'pending'`,
+		id,
+		userID,
+	)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return &customerrors.FraudChallengeInvalidError{}
+	}
+	return nil
+}
+
+// UseFraudChallenge spends a passed challenge. It only matches the payment the challenge
+// was made for, so a confirmed code can't be moved to another one.
+func (r *fraudRepo) UseFraudChallenge(ctx context.Context, req *models.UseFraudChallengeRequest) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE fraud_decisions SET challenge_status = 'used'
+		WHERE guid = $1
+			AND user_id = $2
+			AND account_id = $3
+			AND counterparty_id IS NOT DISTINCT FROM $4
+			AND amount = $5
+			AND challenge_status = 'passed'
+			AND created_at > $6`,
+		req.DecisionID,
+		req.UserID,
+		req.AccountID,
+		toNullString(req.CounterpartyID),
+		req.Amount,
+		req.CreatedAfter,
+	)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return &customerrors.FraudChallengeInvalidError{}
+	}
+	return nil
+}
+
+// GetFraudDecisions returns the decisions matching the filters, newest first
+func (r *fraudRepo) GetFraudDecisions(ctx context.Context, req *models.GetFraudDecisionsRequest) (*models.GetFraudDecisionsResponse, error) {
+	var count int
+	resp := &models.GetFraudDecisionsResponse{
+		Decisions: make([]*models.FraudDecision, 0),
+	}
+
+	qb := helper.NewQueryBuilder()
+	if req.UserID != "" {
+		qb.Where("user_id = ?", req.UserID)
+	}
+	if req.AccountID != "" {
+		qb.Where("account_id = ?", req.AccountID)
+	}
+	if req.Outcome != "" {
+		qb.Where("outcome = ?", req.Outcome)
+	}
+	if req.ReviewStatus != "" {
+		qb.Where("review_status = ?", req.ReviewStatus)
+	}
+
+	query := `SELECT` + fraudDecisionColumns + `,
+			count(1) OVER() AS count
+		FROM fraud_decisions` + qb.WhereClause() + `
+		ORDER BY created_at DESC, guid DESC
+		LIMIT ` + qb.Arg(req.Limit) + ` OFFSET ` + qb.Arg(req.Offset)
+
+	rows, err := r.db.QueryContext(ctx, query, qb.Args()...)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		d, err := scanFraudDecision(rows, &count)
+		if err != nil {
+			return nil, &customerrors.InternalServerError{Message: err.Error()}
+		}
+		resp.Decisions = append(resp.Decisions, d)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	resp.Count = count
+
+	return resp, nil
+}
+
+func (r *fraudRepo) GetFraudDecision(ctx context.Context, id string) (*models.FraudDecision, error) {
+	resp, err := scanFraudDecision(r.db.QueryRowContext(ctx,
+		`SELECT`+fraudDecisionColumns+`
+		FROM fraud_decisions
+		WHERE guid = $1`,
+		id,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &customerrors.FraudDecisionNotFoundError{Guid: id}
+	} else if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return resp, nil
+}
+
+func (r *fraudRepo) ReviewFraudDecision(ctx context.Context, tx *sql.Tx, req *models.ReviewFraudDecisionRequest) error {
+	result, err := tx.ExecContext(ctx,
+		`UPDATE fraud_decisions SET
+			review_status = $2,
+			reviewed_by = $3,
+			review_comment = $4,
+			reviewed_at = CURRENT_TIMESTAMP
+		WHERE guid = $1`,
+		req.ID,
+		req.Status,
+		req.ActorID,
+		req.Comment,
+	)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return &customerrors.FraudDecisionNotFoundError{Guid: req.ID}
+	}
+	return nil
+}
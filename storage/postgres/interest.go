@@ -0,0 +1,323 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/helper"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/pkg/errors"
+)
+
+type interestRepo struct {
+	db *sql.DB
+}
+
+func NewInterestRepo(db *sql.DB) *interestRepo {
+	return &interestRepo{db: db}
+}
+
+func (r *interestRepo) GetProducts(ctx context.Context) (*models.GetProductsResponse, error) {
+	resp := &models.GetProductsResponse{
+		Products: make([]*models.Product, 0),
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT
+			code,
+			name,
+			annual_rate,
+			day_count,
+			created_at,
+			updated_at
+		FROM products
+		ORDER BY code`)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p models.Product
+		err := rows.Scan(
+			&p.Code,
+			&p.Name,
+			&p.AnnualRate,
+			&p.DayCount,
+			&p.CreatedAt,
+			&p.UpdatedAt,
+		)
+		if err != nil {
+			return nil, &customerrors.InternalServerError{Message: err.Error()}
+		}
+		resp.Products = append(resp.Products, &p)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return resp, nil
+}
+
+func (r *interestRepo) GetProductByCode(ctx context.Context, code string) (*models.Product, error) {
+	var p models.Product
+
+	err := r.db.QueryRowContext(ctx,
+		`SELECT
+			code,
+			name,
+			annual_rate,
+			day_count,
+			created_at,
+			updated_at
+		FROM products
+		WHERE code = $1`, code,
+	).Scan(
+		&p.Code,
+		&p.Name,
+		&p.AnnualRate,
+		&p.DayCount,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &customerrors.ProductNotFoundError{Code: code}
+	} else if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return &p, nil
+}
+
+// GetAccrualCandidates returns the interest-bearing accounts that have no accrual for the business date yet.
+// The balance is rewound to the end of the business date by undoing the transactions completed after it,
+// so a late or repeated run accrues on the same balance as a punctual one.
+func (r *interestRepo) GetAccrualCandidates(ctx context.Context, req *models.GetAccrualCandidatesRequest) ([]*models.AccrualCandidate, error) {
+	resp := make([]*models.AccrualCandidate, 0)
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT
+			a.guid,
+			(a.balance - COALESCE((
+				SELECT SUM(CASE WHEN t.transaction_type = 'credit' THEN t.transaction_amount ELSE -t.transaction_amount END)
+				FROM transactions t
+				WHERE t.account_id = a.guid AND t.done AND t.done_timestamp >= $2 AND t.deleted_at IS NULL
+			), 0))::text,
+			p.annual_rate::text,
+			p.day_count
+		FROM accounts a
+		JOIN products p ON p.code = a.product
+		WHERE
+			p.annual_rate > 0 AND
+			NOT a.system AND
+			a.deleted_at = 0 AND
+			a.status <> 'closed' AND
+			a.created_at < $2 AND
+			NOT EXISTS (
+				SELECT 1 FROM interest_accruals ia
+				WHERE ia.account_id = a.guid AND ia.business_date = $1
+			)`,
+		req.BusinessDate,
+		req.EndOfDay,
+	)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c models.AccrualCandidate
+		if err := rows.Scan(&c.AccountID, &c.Balance, &c.AnnualRate, &c.DayCount); err != nil {
+			return nil, &customerrors.InternalServerError{Message: err.Error()}
+		}
+		resp = append(resp, &c)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return resp, nil
+}
+
+// CreateAccrual records the daily accrual and adds it to the account's accrued interest.
+// It returns false without changing anything when the account already accrued for that date.
+func (r *interestRepo) CreateAccrual(ctx context.Context, req *models.InterestAccrual) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	defer tx.Rollback()
+
+	var guid string
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO interest_accruals (
+			account_id,
+			business_date,
+			balance,
+			annual_rate,
+			day_count,
+			amount
+		) VALUES ($1, $2, $3::numeric, $4::numeric, $5, $6::numeric)
+		ON CONFLICT (account_id, business_date) DO NOTHING
+		RETURNING guid`,
+		req.AccountID,
+		req.BusinessDate,
+		req.Balance,
+		req.AnnualRate,
+		req.DayCount,
+		req.Amount,
+	).Scan(&guid)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	} else if err != nil {
+		return false, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE accounts SET accrued_interest = accrued_interest + $1::numeric WHERE guid = $2`,
+		req.Amount,
+		req.AccountID,
+	)
+	if err != nil {
+		return false, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	req.ID = guid
+
+	return true, nil
+}
+
+// GetCapitalizationCandidates returns the accounts with at least a cent of accrued interest not capitalized for the period
+func (r *interestRepo) GetCapitalizationCandidates(ctx context.Context, req *models.GetCapitalizationCandidatesRequest) ([]*models.CapitalizationCandidate, error) {
+	resp := make([]*models.CapitalizationCandidate, 0)
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT
+			a.guid,
+			a.accrued_interest::text
+		FROM accounts a
+		WHERE
+			NOT a.system AND
+			a.deleted_at = 0 AND
+			a.status <> 'closed' AND
+			a.accrued_interest >= 0.01 AND
+			NOT EXISTS (
+				SELECT 1 FROM interest_capitalizations ic
+				WHERE ic.account_id = a.guid AND ic.period = $1
+			)`,
+		req.Period,
+	)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c models.CapitalizationCandidate
+		if err := rows.Scan(&c.AccountID, &c.AccruedInterest); err != nil {
+			return nil, &customerrors.InternalServerError{Message: err.Error()}
+		}
+		resp = append(resp, &c)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return resp, nil
+}
+
+// CreateCapitalization records the posted interest and takes it off the accrued amount.
+// The unique period index makes a second capitalization of the same month fail the whole transaction.
+func (r *interestRepo) CreateCapitalization(ctx context.Context, tx *sql.Tx, req *models.InterestCapitalization) error {
+	err := tx.QueryRowContext(ctx,
+		`INSERT INTO interest_capitalizations (
+			account_id,
+			period,
+			amount,
+			transaction_id
+		) VALUES ($1, $2, $3::numeric, $4)
+		RETURNING guid`,
+		req.AccountID,
+		req.Period,
+		req.Amount,
+		req.TransactionID,
+	).Scan(&req.ID)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE accounts SET accrued_interest = accrued_interest - $1::numeric WHERE guid = $2`,
+		req.Amount,
+		req.AccountID,
+	)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return nil
+}
+
+func (r *interestRepo) GetInterestAccruals(ctx context.Context, req *models.GetInterestAccrualsRequest) (*models.GetInterestAccrualsResponse, error) {
+	var count int
+	resp := &models.GetInterestAccrualsResponse{
+		Accruals: make([]*models.InterestAccrual, 0),
+	}
+
+	qb := helper.NewQueryBuilder().Where("account_id = ?", req.AccountID)
+	if req.From != "" {
+		qb.Where("business_date >= ?", req.From)
+	}
+	if req.To != "" {
+		qb.Where("business_date <= ?", req.To)
+	}
+
+	query := `SELECT
+			guid,
+			account_id,
+			to_char(business_date, 'YYYY-MM-DD'),
+			balance,
+			annual_rate,
+			day_count,
+			amount,
+			created_at,
+			count(1) OVER() AS count
+		FROM interest_accruals` + qb.WhereClause() + `
+		ORDER BY business_date DESC
+		LIMIT ` + qb.Arg(req.Limit) + ` OFFSET ` + qb.Arg(req.Offset)
+
+	rows, err := r.db.QueryContext(ctx, query, qb.Args()...)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var a models.InterestAccrual
+		err := rows.Scan(
+			&a.ID,
+			&a.AccountID,
+			&a.BusinessDate,
+			&a.Balance,
+			&a.AnnualRate,
+			&a.DayCount,
+			&a.Amount,
+			&a.CreatedAt,
+			&count,
+		)
+		if err != nil {
+			return nil, &customerrors.InternalServerError{Message: err.Error()}
+		}
+		resp.Accruals = append(resp.Accruals, &a)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	resp.Count = count
+
+	return resp, nil
+}
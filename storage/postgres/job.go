@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/pkg/errors"
+)
+
+type jobRepo struct {
+	db *sql.DB
+}
+
+func NewJobRepo(db *sql.DB) *jobRepo {
+	return &jobRepo{db: db}
+}
+
+// StartJobRun claims the job for the business date. It returns false when the date is already done
+// or another instance is running it; failed and stale runs are taken over.
+func (r *jobRepo) StartJobRun(ctx context.Context, req *models.StartJobRunRequest) (bool, error) {
+	var job string
+
+	err := r.db.QueryRowContext(ctx,
+		`INSERT INTO job_runs (
+			job,
+			business_date,
+			status
+		) VALUES ($1, $2, 'running')
+		ON CONFLICT (job, business_date) DO UPDATE SET
+			status = 'running',
+			error = NULL,
+			started_at = CURRENT_TIMESTAMP,
+			finished_at = NULL
+		WHERE
+			job_runs.status = 'failed' OR
+			(job_runs.status = 'running' AND job_runs.started_at < $3)
+		RETURNING job`,
+		req.Job,
+		req.BusinessDate,
+		req.StaleBefore,
+	).Scan(&job)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	} else if err != nil {
+		return false, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return true, nil
+}
+
+// FinishJobRun marks the run done, or failed when an error is given
+func (r *jobRepo) FinishJobRun(ctx context.Context, req *models.FinishJobRunRequest) error {
+	status := models.JobStatusDone
+	if req.Error != "" {
+		status = models.JobStatusFailed
+	}
+
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE job_runs SET
+			status = $3,
+			error = NULLIF($4, ''),
+			finished_at = CURRENT_TIMESTAMP
+		WHERE job = $1 AND business_date = $2`,
+		req.Job,
+		req.BusinessDate,
+		status,
+		req.Error,
+	)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return nil
+}
+
+func (r *jobRepo) GetJobRunStatus(ctx context.Context, job, businessDate string) (string, error) {
+	var status string
+
+	err := r.db.QueryRowContext(ctx,
+		`SELECT status FROM job_runs WHERE job = $1 AND business_date = $2`,
+		job,
+		businessDate,
+	).Scan(&status)
+	if err != nil {
+		return "", &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return status, nil
+}
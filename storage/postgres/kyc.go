@@ -0,0 +1,395 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/helper"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+type kycRepo struct {
+	db *sql.DB
+}
+
+func NewKYCRepo(db *sql.DB) *kycRepo {
+	return &kycRepo{db: db}
+}
+
+const kycDocumentColumns = `
+			guid,
+			user_id,
+			application_id,
+			type,
+			file_name,
+			content_type,
+			size,
+			sha256,
+			created_at`
+
+const kycApplicationColumns = `
+			guid,
+			user_id,
+			tier,
+			status,
+			reviewed_by,
+			review_comment,
+			reviewed_at,
+			created_at`
+
+func scanKYCDocument(row rowScanner) (*models.KYCDocument, error) {
+	var (
+		d             models.KYCDocument
+		applicationID sql.NullString
+	)
+
+	err := row.Scan(
+		&d.ID,
+		&d.UserID,
+		&applicationID,
+		&d.Type,
+		&d.FileName,
+		&d.ContentType,
+		&d.Size,
+		&d.SHA256,
+		&d.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	d.ApplicationID = applicationID.String
+
+	return &d, nil
+}
+
+func scanKYCApplication(row rowScanner, extra ...interface{}) (*models.KYCApplication, error) {
+	var (
+		a          models.KYCApplication
+		reviewedBy sql.NullString
+		reviewedAt sql.NullString
+	)
+
+	dest := []interface{}{
+		&a.ID,
+		&a.UserID,
+		&a.Tier,
+		&a.Status,
+		&reviewedBy,
+		&a.ReviewComment,
+		&reviewedAt,
+		&a.CreatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	a.ReviewedBy = reviewedBy.String
+	a.ReviewedAt = reviewedAt.String
+
+	return &a, nil
+}
+
+func (r *kycRepo) GetKYCTier(ctx context.Context, userID string) (string, error) {
+	var tier string
+
+	err := r.db.QueryRowContext(ctx,
+		`SELECT kyc_tier FROM users WHERE guid = $1 AND deleted_at = 0`,
+		userID,
+	).Scan(&tier)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", &customerrors.UserNotFoundError{Guid: userID}
+	} else if err != nil {
+		return "", &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return tier, nil
+}
+
+// GetAccountKYCTier returns the tier of the owner of the account, the one whose caps apply to it
+func (r *kycRepo) GetAccountKYCTier(ctx context.Context, accountID string) (string, error) {
+	var tier string
+
+	err := r.db.QueryRowContext(ctx,
+		`SELECT u.kyc_tier
+		FROM accounts a
+		JOIN users u ON u.guid = a.user_id
+		WHERE a.guid = $1`,
+		accountID,
+	).Scan(&tier)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", &customerrors.AccountNotFoundError{Guid: accountID}
+	} else if err != nil {
+		return "", &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return tier, nil
+}
+
+func (r *kycRepo) SetKYCTier(ctx context.Context, tx *sql.Tx, userID, tier string) error {
+	result, err := tx.ExecContext(ctx,
+		`UPDATE users SET kyc_tier = $2, updated_at = CURRENT_TIMESTAMP WHERE guid = $1 AND deleted_at = 0`,
+		userID,
+		tier,
+	)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return &customerrors.UserNotFoundError{Guid: userID}
+	}
+	return nil
+}
+
+func (r *kycRepo) CreateKYCDocument(ctx context.Context, tx *sql.Tx, req *models.KYCDocument) (*models.KYCDocument, error) {
+	resp, err := scanKYCDocument(tx.QueryRowContext(ctx,
+		`INSERT INTO kyc_documents (
+			user_id,
+			type,
+			file_name,
+			content_type,
+			size,
+			sha256
+		) VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING`+kycDocumentColumns,
+		req.UserID,
+		req.Type,
+		req.FileName,
+		req.ContentType,
+		req.Size,
+		req.SHA256,
+	))
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return resp, nil
+}
+
+func (r *kycRepo) GetKYCDocument(ctx context.Context, id string) (*models.KYCDocument, error) {
+	resp, err := scanKYCDocument(r.db.QueryRowContext(ctx,
+		`SELECT`+kycDocumentColumns+`
+		FROM kyc_documents
+		WHERE guid = $1`,
+		id,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &customerrors.KYCDocumentNotFoundError{Guid: id}
+	} else if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return resp, nil
+}
+
+// GetUnsubmittedKYCDocuments returns the documents of the user not submitted with an application yet
+func (r *kycRepo) GetUnsubmittedKYCDocuments(ctx context.Context, userID string) ([]*models.KYCDocument, error) {
+	return r.getKYCDocuments(ctx,
+		`SELECT`+kycDocumentColumns+`
+		FROM kyc_documents
+		WHERE user_id = $1 AND application_id IS NULL
+		ORDER BY created_at, guid`,
+		userID,
+	)
+}
+
+func (r *kycRepo) GetKYCApplicationDocuments(ctx context.Context, applicationID string) ([]*models.KYCDocument, error) {
+	return r.getKYCDocuments(ctx,
+		`SELECT`+kycDocumentColumns+`
+		FROM kyc_documents
+		WHERE application_id = $1
+		ORDER BY created_at, guid`,
+		applicationID,
+	)
+}
+
+func (r *kycRepo) getKYCDocuments(ctx context.Context, query string, args ...interface{}) ([]*models.KYCDocument, error) {
+	resp := make([]*models.KYCDocument, 0)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		d, err := scanKYCDocument(rows)
+		if err != nil {
+			return nil, &customerrors.InternalServerError{Message: err.Error()}
+		}
+		resp = append(resp, d)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return resp, nil
+}
+
+func (r *kycRepo) CreateKYCApplication(ctx context.Context, tx *sql.Tx, req *models.KYCApplication) (*models.KYCApplication, error) {
+	resp, err := scanKYCApplication(tx.QueryRowContext(ctx,
+		`INSERT INTO kyc_applications (
+			user_id,
+			tier
+		) VALUES ($1, $2)
+		RETURNING`+kycApplicationColumns,
+		req.UserID,
+		req.Tier,
+	))
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+		return nil, &customerrors.KYCApplicationExistsError{}
+	} else if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return resp, nil
+}
+
+// AttachKYCDocuments submits the documents of the user not submitted yet with the application and returns them
+func (r *kycRepo) AttachKYCDocuments(ctx context.Context, tx *sql.Tx, userID, applicationID string) ([]*models.KYCDocument, error) {
+	resp := make([]*models.KYCDocument, 0)
+
+	rows, err := tx.QueryContext(ctx,
+		`UPDATE kyc_documents SET
+			application_id = $2
+		WHERE user_id = $1 AND application_id IS NULL
+		RETURNING`+kycDocumentColumns,
+		userID,
+		applicationID,
+	)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		d, err := scanKYCDocument(rows)
+		if err != nil {
+			return nil, &customerrors.InternalServerError{Message: err.Error()}
+		}
+		resp = append(resp, d)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return resp, nil
+}
+
+// GetKYCApplications returns the applications matching the filters, oldest first so the queue is worked in order
+func (r *kycRepo) GetKYCApplications(ctx context.Context, req *models.GetKYCApplicationsRequest) (*models.GetKYCApplicationsResponse, error) {
+	var count int
+	resp := &models.GetKYCApplicationsResponse{
+		Applications: make([]*models.KYCApplication, 0),
+	}
+
+	qb := helper.NewQueryBuilder()
+	if req.UserID != "" {
+		qb.Where("user_id = ?", req.UserID)
+	}
+	if req.Status != "" {
+		qb.Where("status = ?", req.Status)
+	}
+	if req.Tier != "" {
+		qb.Where("tier = ?", req.Tier)
+	}
+
+	query := `SELECT` + kycApplicationColumns + `,
+			count(1) OVER() AS count
+		FROM kyc_applications` + qb.WhereClause() + `
+		ORDER BY created_at, guid
+		LIMIT ` + qb.Arg(req.Limit) + ` OFFSET ` + qb.Arg(req.Offset)
+
+	rows, err := r.db.QueryContext(ctx, query, qb.Args()...)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		a, err := scanKYCApplication(rows, &count)
+		if err != nil {
+			return nil, &customerrors.InternalServerError{Message: err.Error()}
+		}
+		resp.Applications = append(resp.Applications, a)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	resp.Count = count
+
+	return resp, nil
+}
+
+func (r *kycRepo) GetKYCApplication(ctx context.Context, id string) (*models.KYCApplication, error) {
+	resp, err := scanKYCApplication(r.db.QueryRowContext(ctx,
+		`SELECT`+kycApplicationColumns+`
+		FROM kyc_applications
+		WHERE guid = $1`,
+		id,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &customerrors.KYCApplicationNotFoundError{Guid: id}
+	} else if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return resp, nil
+}
+
+// GetLatestKYCApplication returns the last application of the user, nil when there is none
+func (r *kycRepo) GetLatestKYCApplication(ctx context.Context, userID string) (*models.KYCApplication, error) {
+	resp, err := scanKYCApplication(r.db.QueryRowContext(ctx,
+		`SELECT`+kycApplicationColumns+`
+		FROM kyc_applications
+		WHERE user_id = $1
+		ORDER BY created_at DESC, guid DESC
+		LIMIT 1`,
+		userID,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return resp, nil
+}
+
+// GetKYCApplicationForUpdate locks the application until the transaction ends, so it is reviewed once
+func (r *kycRepo) GetKYCApplicationForUpdate(ctx context.Context, tx *sql.Tx, id string) (*models.KYCApplication, error) {
+	resp, err := scanKYCApplication(tx.QueryRowContext(ctx,
+		`SELECT`+kycApplicationColumns+`
+		FROM kyc_applications
+		WHERE guid = $1
+		FOR UPDATE`,
+		id,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &customerrors.KYCApplicationNotFoundError{Guid: id}
+	} else if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return resp, nil
+}
+
+func (r *kycRepo) ReviewKYCApplication(ctx context.Context, tx *sql.Tx, req *models.ReviewKYCApplicationRequest) error {
+	_, err := tx.ExecContext(ctx,
+		`UPDATE kyc_applications SET
+			status = $2,
+			reviewed_by = $3,
+			review_comment = $4,
+			reviewed_at = CURRENT_TIMESTAMP
+		WHERE guid = $1`,
+		req.ID,
+		req.Status,
+		req.ActorID,
+		req.Comment,
+	)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+	return nil
+}
@@ -0,0 +1,446 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/helper"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/pkg/errors"
+)
+
+type loanRepo struct {
+	db *sql.DB
+}
+
+func NewLoanRepo(db *sql.DB) *loanRepo {
+	return &loanRepo{db: db}
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Tx
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+const loanProductColumns = `
+			code,
+			name,
+			annual_rate,
+			min_amount,
+			max_amount,
+			min_term_months,
+			max_term_months,
+			late_fee,
+			grace_days`
+
+func scanLoanProduct(row rowScanner) (*models.LoanProduct, error) {
+	var p models.LoanProduct
+	err := row.Scan(
+		&p.Code,
+		&p.Name,
+		&p.AnnualRate,
+		&p.MinAmount,
+		&p.MaxAmount,
+		&p.MinTermMonths,
+		&p.MaxTermMonths,
+		&p.LateFee,
+		&p.GraceDays,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+const loanColumns = `
+			guid,
+			user_id,
+			account_id,
+			product,
+			principal,
+			annual_rate,
+			term_months,
+			schedule_type,
+			late_fee,
+			grace_days,
+			status,
+			outstanding_principal,
+			late_fees_due,
+			to_char(disbursed_on, 'YYYY-MM-DD'),
+			disbursement_transaction_id,
+			closed_at,
+			created_at,
+			updated_at`
+
+func scanLoan(row rowScanner, extra ...interface{}) (*models.Loan, error) {
+	var (
+		l        models.Loan
+		closedAt sql.NullString
+	)
+
+	dest := []interface{}{
+		&l.ID,
+		&l.UserID,
+		&l.AccountID,
+		&l.Product,
+		&l.Principal,
+		&l.AnnualRate,
+		&l.TermMonths,
+		&l.ScheduleType,
+		&l.LateFee,
+		&l.GraceDays,
+		&l.Status,
+		&l.OutstandingPrincipal,
+		&l.LateFeesDue,
+		&l.DisbursedOn,
+		&l.DisbursementTransactionID,
+		&closedAt,
+		&l.CreatedAt,
+		&l.UpdatedAt,
+	}
+
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	l.ClosedAt = closedAt.String
+
+	return &l, nil
+}
+
+func (r *loanRepo) GetLoanProducts(ctx context.Context) (*models.GetLoanProductsResponse, error) {
+	resp := &models.GetLoanProductsResponse{
+		Products: make([]*models.LoanProduct, 0),
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT`+loanProductColumns+`
+		FROM loan_products
+		ORDER BY code`)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		p, err := scanLoanProduct(rows)
+		if err != nil {
+			return nil, &customerrors.InternalServerError{Message: err.Error()}
+		}
+		resp.Products = append(resp.Products, p)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return resp, nil
+}
+
+func (r *loanRepo) GetLoanProduct(ctx context.Context, code string) (*models.LoanProduct, error) {
+	resp, err := scanLoanProduct(r.db.QueryRowContext(ctx,
+		`SELECT`+loanProductColumns+`
+		FROM loan_products
+		WHERE code = $1`, code,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &customerrors.ProductNotFoundError{Code: code}
+	} else if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return resp, nil
+}
+
+func (r *loanRepo) CreateLoan(ctx context.Context, tx *sql.Tx, req *models.Loan) (*models.Loan, error) {
+	resp, err := scanLoan(tx.QueryRowContext(ctx,
+		`INSERT INTO loans (
+			user_id,
+			account_id,
+			product,
+			principal,
+			annual_rate,
+			term_months,
+			schedule_type,
+			late_fee,
+			grace_days,
+			outstanding_principal,
+			disbursed_on,
+			disbursement_transaction_id
+		) VALUES ($1, $2, $3, $4::numeric, $5::numeric, $6, $7, $8::numeric, $9, $4::numeric, $10, $11)
+		RETURNING`+loanColumns,
+		req.UserID,
+		req.AccountID,
+		req.Product,
+		req.Principal,
+		req.AnnualRate,
+		req.TermMonths,
+		req.ScheduleType,
+		req.LateFee,
+		req.GraceDays,
+		req.DisbursedOn,
+		req.DisbursementTransactionID,
+	))
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return resp, nil
+}
+
+func (r *loanRepo) CreateInstallments(ctx context.Context, tx *sql.Tx, installments []*models.LoanInstallment) error {
+	stmt, err := tx.PrepareContext(ctx,
+		`INSERT INTO loan_installments (
+			loan_id,
+			number,
+			due_date,
+			principal,
+			interest
+		) VALUES ($1, $2, $3, $4::numeric, $5::numeric)
+		RETURNING guid`,
+	)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+	defer stmt.Close()
+
+	for _, i := range installments {
+		err = stmt.QueryRowContext(ctx,
+			i.LoanID,
+			i.Number,
+			i.DueDate,
+			i.Principal,
+			i.Interest,
+		).Scan(&i.ID)
+		if err != nil {
+			return &customerrors.InternalServerError{Message: err.Error()}
+		}
+	}
+
+	return nil
+}
+
+// DeletePendingInstallments removes the installments not yet due so an early repayment can rebuild them
+func (r *loanRepo) DeletePendingInstallments(ctx context.Context, tx *sql.Tx, loanID string) error {
+	_, err := tx.ExecContext(ctx,
+		`DELETE FROM loan_installments WHERE loan_id = $1 AND status = 'pending'`,
+		loanID,
+	)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return nil
+}
+
+func (r *loanRepo) getInstallments(ctx context.Context, q queryer, loanID string) ([]*models.LoanInstallment, error) {
+	installments := make([]*models.LoanInstallment, 0)
+
+	rows, err := q.QueryContext(ctx,
+		`SELECT
+			guid,
+			loan_id,
+			number,
+			to_char(due_date, 'YYYY-MM-DD'),
+			principal,
+			interest,
+			late_fee,
+			(principal + interest + late_fee)::text,
+			status,
+			paid_at
+		FROM loan_installments
+		WHERE loan_id = $1
+		ORDER BY number`,
+		loanID,
+	)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			i      models.LoanInstallment
+			paidAt sql.NullString
+		)
+		err := rows.Scan(
+			&i.ID,
+			&i.LoanID,
+			&i.Number,
+			&i.DueDate,
+			&i.Principal,
+			&i.Interest,
+			&i.LateFee,
+			&i.Total,
+			&i.Status,
+			&paidAt,
+		)
+		if err != nil {
+			return nil, &customerrors.InternalServerError{Message: err.Error()}
+		}
+		i.PaidAt = paidAt.String
+		installments = append(installments, &i)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return installments, nil
+}
+
+// GetLoanByID returns the borrower's loan with its schedule
+func (r *loanRepo) GetLoanByID(ctx context.Context, req *models.LoanByIDRequest) (*models.Loan, error) {
+	resp, err := scanLoan(r.db.QueryRowContext(ctx,
+		`SELECT`+loanColumns+`
+		FROM loans
+		WHERE guid = $1 AND user_id = $2`,
+		req.ID,
+		req.UserID,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &customerrors.LoanNotFoundError{Guid: req.ID}
+	} else if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	if resp.Installments, err = r.getInstallments(ctx, r.db, resp.ID); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// GetLoanForUpdate locks the loan row until the transaction ends and reads its schedule in the same
+// transaction, so the repayment job and an early repayment can't collect the same installment twice
+func (r *loanRepo) GetLoanForUpdate(ctx context.Context, tx *sql.Tx, id string) (*models.Loan, error) {
+	resp, err := scanLoan(tx.QueryRowContext(ctx,
+		`SELECT`+loanColumns+`
+		FROM loans
+		WHERE guid = $1
+		FOR UPDATE`,
+		id,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &customerrors.LoanNotFoundError{Guid: id}
+	} else if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	if resp.Installments, err = r.getInstallments(ctx, tx, resp.ID); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (r *loanRepo) GetLoans(ctx context.Context, req *models.GetLoansRequest) (*models.GetLoansResponse, error) {
+	var count int
+	resp := &models.GetLoansResponse{
+		Loans: make([]*models.Loan, 0),
+	}
+
+	qb := helper.NewQueryBuilder().Where("user_id = ?", req.UserID)
+	if req.Status != "" {
+		qb.Where("status = ?", req.Status)
+	}
+
+	query := `SELECT` + loanColumns + `,
+			count(1) OVER() AS count
+		FROM loans` + qb.WhereClause() + `
+		ORDER BY created_at DESC
+		LIMIT ` + qb.Arg(req.Limit) + ` OFFSET ` + qb.Arg(req.Offset)
+
+	rows, err := r.db.QueryContext(ctx, query, qb.Args()...)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		l, err := scanLoan(rows, &count)
+		if err != nil {
+			return nil, &customerrors.InternalServerError{Message: err.Error()}
+		}
+		resp.Loans = append(resp.Loans, l)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	resp.Count = count
+
+	return resp, nil
+}
+
+// GetDueLoanIDs returns the open loans with an unpaid installment due by the business date
+func (r *loanRepo) GetDueLoanIDs(ctx context.Context, businessDate string) ([]string, error) {
+	ids := make([]string, 0)
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT l.guid
+		FROM loans l
+		WHERE
+			l.status <> 'repaid' AND
+			EXISTS (
+				SELECT 1 FROM loan_installments i
+				WHERE i.loan_id = l.guid AND i.status <> 'paid' AND i.due_date <= $1
+			)
+		ORDER BY l.created_at`,
+		businessDate,
+	)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, &customerrors.InternalServerError{Message: err.Error()}
+		}
+		ids = append(ids, id)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return ids, nil
+}
+
+// UpdateLoan saves the repayment state of a loan locked by GetLoanForUpdate
+func (r *loanRepo) UpdateLoan(ctx context.Context, tx *sql.Tx, req *models.Loan) error {
+	_, err := tx.ExecContext(ctx,
+		`UPDATE loans SET
+			status = $2,
+			outstanding_principal = $3::numeric,
+			late_fees_due = $4::numeric,
+			closed_at = CASE WHEN $2 = 'repaid' THEN COALESCE(closed_at, CURRENT_TIMESTAMP) ELSE NULL END,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE guid = $1`,
+		req.ID,
+		req.Status,
+		req.OutstandingPrincipal,
+		req.LateFeesDue,
+	)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return nil
+}
+
+func (r *loanRepo) UpdateInstallment(ctx context.Context, tx *sql.Tx, req *models.LoanInstallment) error {
+	_, err := tx.ExecContext(ctx,
+		`UPDATE loan_installments SET
+			status = $2,
+			late_fee = $3::numeric,
+			paid_at = CASE WHEN $2 = 'paid' THEN COALESCE(paid_at, CURRENT_TIMESTAMP) ELSE NULL END
+		WHERE guid = $1`,
+		req.ID,
+		req.Status,
+		req.LateFee,
+	)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return nil
+}
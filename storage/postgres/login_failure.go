@@ -0,0 +1,81 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/lib/pq"
+)
+
+type loginFailureRepo struct {
+	db *sql.DB
+}
+
+func NewLoginFailureRepo(db *sql.DB) *loginFailureRepo {
+	return &loginFailureRepo{db: db}
+}
+
+// GetLoginLock returns how long the longest running lock of the keys still lasts, zero when none is locked
+func (r *loginFailureRepo) GetLoginLock(ctx context.Context, keys []string) (time.Duration, error) {
+	var seconds float64
+
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COALESCE(max(extract(epoch FROM locked_until - CURRENT_TIMESTAMP)), 0)
+		FROM login_failures
+		WHERE key = ANY($1) AND locked_until > CURRENT_TIMESTAMP`,
+		pq.Array(keys),
+	).Scan(&seconds)
+	if err != nil {
+		return 0, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// RecordLoginFailure counts a failed login for the key and returns the failures so far.
+// The count starts over once the last failure is older than the window.
+func (r *loginFailureRepo) RecordLoginFailure(ctx context.Context, tx *sql.Tx, key string, window time.Duration) (int, error) {
+	var failures int
+
+	err := tx.QueryRowContext(ctx,
+		`INSERT INTO login_failures (key, failures, last_failed_at)
+		VALUES ($1, 1, CURRENT_TIMESTAMP)
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE
+				WHEN login_failures.last_failed_at < CURRENT_TIMESTAMP - make_interval(secs => $2) THEN 1
+				ELSE login_failures.failures + 1
+			END,
+			last_failed_at = CURRENT_TIMESTAMP
+		RETURNING failures`,
+		key,
+		window.Seconds(),
+	).Scan(&failures)
+	if err != nil {
+		return 0, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return failures, nil
+}
+
+// LockLogin keeps logins for the key off for the duration
+func (r *loginFailureRepo) LockLogin(ctx context.Context, tx *sql.Tx, key string, duration time.Duration) error {
+	_, err := tx.ExecContext(ctx,
+		`UPDATE login_failures SET locked_until = CURRENT_TIMESTAMP + make_interval(secs => $2) WHERE key = $1`,
+		key,
+		duration.Seconds(),
+	)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+	return nil
+}
+
+func (r *loginFailureRepo) ResetLoginFailures(ctx context.Context, key string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM login_failures WHERE key = $1`, key)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+	return nil
+}
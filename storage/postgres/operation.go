@@ -0,0 +1,206 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/helper"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+type operationRepo struct {
+	db *sql.DB
+}
+
+func NewOperationRepo(db *sql.DB) *operationRepo {
+	return &operationRepo{db: db}
+}
+
+const pendingOperationColumns = `
+			guid,
+			operation_type,
+			resource_id,
+			payload,
+			status,
+			proposed_by,
+			decided_by,
+			comment,
+			result,
+			expires_at,
+			decided_at,
+			created_at`
+
+func scanPendingOperation(row rowScanner, extra ...interface{}) (*models.PendingOperation, error) {
+	var (
+		o         models.PendingOperation
+		payload   []byte
+		result    []byte
+		decidedBy sql.NullString
+		decidedAt sql.NullString
+	)
+
+	dest := []interface{}{
+		&o.ID,
+		&o.Type,
+		&o.ResourceID,
+		&payload,
+		&o.Status,
+		&o.ProposedBy,
+		&decidedBy,
+		&o.Comment,
+		&result,
+		&o.ExpiresAt,
+		&decidedAt,
+		&o.CreatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(payload, &o.Payload); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(result, &o.Result); err != nil {
+		return nil, err
+	}
+	o.DecidedBy = decidedBy.String
+	o.DecidedAt = decidedAt.String
+
+	return &o, nil
+}
+
+// CreatePendingOperation stores the proposal in the caller's transaction
+func (r *operationRepo) CreatePendingOperation(ctx context.Context, tx *sql.Tx, req *models.PendingOperation) (*models.PendingOperation, error) {
+	payload, err := json.Marshal(req.Payload)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	resp, err := scanPendingOperation(tx.QueryRowContext(ctx,
+		`INSERT INTO pending_operations (
+			operation_type,
+			resource_id,
+			payload,
+			proposed_by,
+			expires_at
+		) VALUES ($1, $2, $3, $4, $5)
+		RETURNING`+pendingOperationColumns,
+		req.Type,
+		req.ResourceID,
+		payload,
+		req.ProposedBy,
+		req.ExpiresAt,
+	))
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+		return nil, &customerrors.PendingOperationExistsError{ResourceID: req.ResourceID}
+	} else if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return resp, nil
+}
+
+func (r *operationRepo) GetPendingOperations(ctx context.Context, req *models.GetPendingOperationsRequest) (*models.GetPendingOperationsResponse, error) {
+	var count int
+	resp := &models.GetPendingOperationsResponse{
+		Operations: make([]*models.PendingOperation, 0),
+	}
+
+	qb := helper.NewQueryBuilder()
+	if req.Type != "" {
+		qb.Where("operation_type = ?", req.Type)
+	}
+	if req.Status != "" {
+		qb.Where("status = ?", req.Status)
+	}
+
+	query := `SELECT` + pendingOperationColumns + `,
+			count(1) OVER() AS count
+		FROM pending_operations` + qb.WhereClause() + `
+		ORDER BY created_at DESC
+		LIMIT ` + qb.Arg(req.Limit) + ` OFFSET ` + qb.Arg(req.Offset)
+
+	rows, err := r.db.QueryContext(ctx, query, qb.Args()...)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		o, err := scanPendingOperation(rows, &count)
+		if err != nil {
+			return nil, &customerrors.InternalServerError{Message: err.Error()}
+		}
+		resp.Operations = append(resp.Operations, o)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	resp.Count = count
+
+	return resp, nil
+}
+
+func (r *operationRepo) GetPendingOperation(ctx context.Context, id string) (*models.PendingOperation, error) {
+	resp, err := scanPendingOperation(r.db.QueryRowContext(ctx,
+		`SELECT`+pendingOperationColumns+`
+		FROM pending_operations
+		WHERE guid = $1`,
+		id,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &customerrors.PendingOperationNotFoundError{Guid: id}
+	} else if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return resp, nil
+}
+
+// GetPendingOperationForUpdate locks the operation until the transaction ends, so it is executed only once
+func (r *operationRepo) GetPendingOperationForUpdate(ctx context.Context, tx *sql.Tx, id string) (*models.PendingOperation, error) {
+	resp, err := scanPendingOperation(tx.QueryRowContext(ctx,
+		`SELECT`+pendingOperationColumns+`
+		FROM pending_operations
+		WHERE guid = $1
+		FOR UPDATE`,
+		id,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &customerrors.PendingOperationNotFoundError{Guid: id}
+	} else if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return resp, nil
+}
+
+func (r *operationRepo) UpdatePendingOperation(ctx context.Context, tx *sql.Tx, req *models.PendingOperation) error {
+	result, err := json.Marshal(req.Result)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE pending_operations SET
+			status = $2,
+			decided_by = $3,
+			comment = $4,
+			result = $5,
+			decided_at = CURRENT_TIMESTAMP
+		WHERE guid = $1`,
+		req.ID,
+		req.Status,
+		toNullString(req.DecidedBy),
+		req.Comment,
+		result,
+	)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/pkg/errors"
+)
+
+type otpRepo struct {
+	db *sql.DB
+}
+
+func NewOTPRepo(db *sql.DB) *otpRepo {
+	return &otpRepo{db: db}
+}
+
+// CreateOTP stores a new code and expires the user's earlier codes for the same purpose,
+// so only the code sent last can be used
+func (r *otpRepo) CreateOTP(ctx context.Context, req *models.OTPCode) (*models.OTPCode, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE otp_codes SET expires_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > CURRENT_TIMESTAMP`,
+		req.UserID,
+		req.Purpose,
+	)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO otp_codes (
+			user_id,
+			purpose,
+			target,
+			code_hash,
+			expires_at
+		) VALUES ($1, $2, $3, $4, $5)
+		RETURNING guid, created_at`,
+		req.UserID,
+		req.Purpose,
+		req.Target,
+		req.CodeHash,
+		req.ExpiresAt,
+	).Scan(&req.ID, &req.CreatedAt)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return req, nil
+}
+
+// GetActiveOTPForUpdate locks the user's unused, unexpired code for the purpose
+func (r *otpRepo) GetActiveOTPForUpdate(ctx context.Context, tx *sql.Tx, userID, purpose string) (*models.OTPCode, error) {
+	var code models.OTPCode
+
+	err := tx.QueryRowContext(ctx,
+		`SELECT
+			guid,
+			user_id,
+			purpose,
+			target,
+			code_hash,
+			attempts,
+			expires_at
+		FROM otp_codes
+		WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+		ORDER BY created_at DESC
+		LIMIT 1
+		FOR UPDATE`,
+		userID,
+		purpose,
+	).Scan(
+		&code.ID,
+		&code.UserID,
+		&code.Purpose,
+		&code.Target,
+		&code.CodeHash,
+		&code.Attempts,
+		&code.ExpiresAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &customerrors.InvalidOTPError{}
+	} else if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return &code, nil
+}
+
+func (r *otpRepo) IncrementOTPAttempts(ctx context.Context, tx *sql.Tx, id string) error {
+	_, err := tx.ExecContext(ctx, `UPDATE otp_codes SET attempts = attempts + 1 WHERE guid = $1`, id)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+	return nil
+}
+
+func (r *otpRepo) UseOTP(ctx context.Context, tx *sql.Tx, id string) error {
+	_, err := tx.ExecContext(ctx, `UPDATE otp_codes SET used_at = CURRENT_TIMESTAMP WHERE guid = $1`, id)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+	return nil
+}
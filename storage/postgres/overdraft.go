@@ -0,0 +1,260 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/pkg/errors"
+)
+
+type overdraftRepo struct {
+	db *sql.DB
+}
+
+func NewOverdraftRepo(db *sql.DB) *overdraftRepo {
+	return &overdraftRepo{db: db}
+}
+
+// SetOverdraftLimit changes the limit. A limit below the overdrawn part of the balance changes nothing.
+func (r *overdraftRepo) SetOverdraftLimit(ctx context.Context, req *models.SetOverdraftLimitRequest) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE accounts
+		SET overdraft_limit = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE guid = $2 AND deleted_at = 0 AND balance >= -$1::numeric`,
+		req.Limit,
+		req.AccountID,
+	)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+	if cn, err := result.RowsAffected(); err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	} else if cn > 0 {
+		return nil
+	}
+
+	var used string
+	err = r.db.QueryRowContext(ctx,
+		`SELECT (-balance)::numeric(20, 2)::text FROM accounts WHERE guid = $1 AND deleted_at = 0`,
+		req.AccountID,
+	).Scan(&used)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &customerrors.AccountNotFoundError{Guid: req.AccountID}
+	} else if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return &customerrors.OverdraftLimitError{Used: used}
+}
+
+func (r *overdraftRepo) GetOverdraftUsage(ctx context.Context, accountID string) (*models.OverdraftUsage, error) {
+	var u models.OverdraftUsage
+
+	err := r.db.QueryRowContext(ctx,
+		`SELECT
+			a.guid,
+			a.balance::numeric(20, 2)::text,
+			a.overdraft_limit::numeric(20, 2)::text,
+			GREATEST(-a.balance, 0)::numeric(20, 2)::text,
+			GREATEST(a.balance + a.overdraft_limit, 0)::numeric(20, 2)::text,
+			p.overdraft_rate::text,
+			a.accrued_overdraft_interest::text
+		FROM accounts a
+		JOIN products p ON p.code = a.product
+		WHERE a.guid = $1 AND a.deleted_at = 0`,
+		accountID,
+	).Scan(
+		&u.AccountID,
+		&u.Balance,
+		&u.Limit,
+		&u.Used,
+		&u.Available,
+		&u.AnnualRate,
+		&u.AccruedInterest,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &customerrors.AccountNotFoundError{Guid: accountID}
+	} else if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return &u, nil
+}
+
+// GetOverdraftAccrualCandidates returns the accounts overdrawn at the end of the business date that have
+// no overdraft accrual for it yet. The balance is rewound the same way as for credit interest.
+func (r *overdraftRepo) GetOverdraftAccrualCandidates(ctx context.Context, req *models.GetAccrualCandidatesRequest) ([]*models.AccrualCandidate, error) {
+	resp := make([]*models.AccrualCandidate, 0)
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT guid, balance::text, overdraft_rate::text, day_count
+		FROM (
+			SELECT
+				a.guid,
+				a.balance - COALESCE((
+					SELECT SUM(CASE WHEN t.transaction_type = 'credit' THEN t.transaction_amount ELSE -t.transaction_amount END)
+					FROM transactions t
+					WHERE t.account_id = a.guid AND t.done AND t.done_timestamp >= $2 AND t.deleted_at IS NULL
+				), 0) AS balance,
+				p.overdraft_rate,
+				p.day_count
+			FROM accounts a
+			JOIN products p ON p.code = a.product
+			WHERE
+				p.overdraft_rate > 0 AND
+				NOT a.system AND
+				a.deleted_at = 0 AND
+				a.status <> 'closed' AND
+				a.created_at < $2 AND
+				NOT EXISTS (
+					SELECT 1 FROM overdraft_accruals oa
+					WHERE oa.account_id = a.guid AND oa.business_date = $1
+				)
+		) eod
+		WHERE balance < 0`,
+		req.BusinessDate,
+		req.EndOfDay,
+	)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c models.AccrualCandidate
+		if err := rows.Scan(&c.AccountID, &c.Balance, &c.AnnualRate, &c.DayCount); err != nil {
+			return nil, &customerrors.InternalServerError{Message: err.Error()}
+		}
+		resp = append(resp, &c)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return resp, nil
+}
+
+// CreateOverdraftAccrual records the daily overdraft accrual and adds it to the interest the account owes.
+// It returns false without changing anything when the account already accrued for that date.
+func (r *overdraftRepo) CreateOverdraftAccrual(ctx context.Context, req *models.InterestAccrual) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	defer tx.Rollback()
+
+	var guid string
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO overdraft_accruals (
+			account_id,
+			business_date,
+			balance,
+			annual_rate,
+			day_count,
+			amount
+		) VALUES ($1, $2, $3::numeric, $4::numeric, $5, $6::numeric)
+		ON CONFLICT (account_id, business_date) DO NOTHING
+		RETURNING guid`,
+		req.AccountID,
+		req.BusinessDate,
+		req.Balance,
+		req.AnnualRate,
+		req.DayCount,
+		req.Amount,
+	).Scan(&guid)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	} else if err != nil {
+		return false, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE accounts SET accrued_overdraft_interest = accrued_overdraft_interest + $1::numeric WHERE guid = $2`,
+		req.Amount,
+		req.AccountID,
+	)
+	if err != nil {
+		return false, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	req.ID = guid
+
+	return true, nil
+}
+
+// GetOverdraftChargeCandidates returns the accounts owing at least a cent of overdraft interest not charged for the period
+func (r *overdraftRepo) GetOverdraftChargeCandidates(ctx context.Context, req *models.GetCapitalizationCandidatesRequest) ([]*models.OverdraftChargeCandidate, error) {
+	resp := make([]*models.OverdraftChargeCandidate, 0)
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT
+			a.guid,
+			a.accrued_overdraft_interest::text,
+			(a.balance + a.overdraft_limit)::text
+		FROM accounts a
+		WHERE
+			NOT a.system AND
+			a.deleted_at = 0 AND
+			a.status <> 'closed' AND
+			a.accrued_overdraft_interest >= 0.01 AND
+			NOT EXISTS (
+				SELECT 1 FROM overdraft_charges oc
+				WHERE oc.account_id = a.guid AND oc.period = $1
+			)`,
+		req.Period,
+	)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c models.OverdraftChargeCandidate
+		if err := rows.Scan(&c.AccountID, &c.AccruedInterest, &c.Available); err != nil {
+			return nil, &customerrors.InternalServerError{Message: err.Error()}
+		}
+		resp = append(resp, &c)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return resp, nil
+}
+
+// CreateOverdraftCharge records the charged interest and takes it off the amount owed.
+// The unique period index makes a second charge for the same month fail the whole transaction.
+func (r *overdraftRepo) CreateOverdraftCharge(ctx context.Context, tx *sql.Tx, req *models.InterestCapitalization) error {
+	err := tx.QueryRowContext(ctx,
+		`INSERT INTO overdraft_charges (
+			account_id,
+			period,
+			amount,
+			transaction_id
+		) VALUES ($1, $2, $3::numeric, $4)
+		RETURNING guid`,
+		req.AccountID,
+		req.Period,
+		req.Amount,
+		req.TransactionID,
+	).Scan(&req.ID)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE accounts SET accrued_overdraft_interest = accrued_overdraft_interest - $1::numeric WHERE guid = $2`,
+		req.Amount,
+		req.AccountID,
+	)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return nil
+}
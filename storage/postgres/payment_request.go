@@ -0,0 +1,310 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/helper"
+	"github.com/dilmurodov/online_banking/pkg/models"
+)
+
+type paymentRequestRepo struct {
+	db *sql.DB
+}
+
+func NewPaymentRequestRepo(db *sql.DB) *paymentRequestRepo {
+	return &paymentRequestRepo{db: db}
+}
+
+const paymentRequestColumns = `
+			guid,
+			requester_id,
+			requester_account_id,
+			payer_id,
+			payer_account_id,
+			amount,
+			memo,
+			status,
+			transaction_id,
+			expires_at,
+			created_at,
+			updated_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanPaymentRequest(row rowScanner, extra ...interface{}) (*models.PaymentRequest, error) {
+	var (
+		p              models.PaymentRequest
+		payerAccountID sql.NullString
+		transactionID  sql.NullString
+	)
+
+	dest := []interface{}{
+		&p.ID,
+		&p.RequesterID,
+		&p.RequesterAccountID,
+		&p.PayerID,
+		&payerAccountID,
+		&p.Amount,
+		&p.Memo,
+		&p.Status,
+		&transactionID,
+		&p.ExpiresAt,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	}
+
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	p.PayerAccountID = payerAccountID.String
+	p.TransactionID = transactionID.String
+
+	return &p, nil
+}
+
+// CreatePaymentRequest stores a new pending payment request together with its first history entry
+func (r *paymentRequestRepo) CreatePaymentRequest(ctx context.Context, req *models.PaymentRequest) (*models.PaymentRequest, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	defer tx.Rollback()
+
+	resp, err := scanPaymentRequest(tx.QueryRowContext(ctx,
+		`INSERT INTO payment_requests (
+			requester_id,
+			requester_account_id,
+			payer_id,
+			payer_account_id,
+			amount,
+			memo,
+			expires_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING`+paymentRequestColumns,
+		req.RequesterID,
+		req.RequesterAccountID,
+		req.PayerID,
+		toNullString(req.PayerAccountID),
+		req.Amount,
+		req.Memo,
+		req.ExpiresAt,
+	))
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO payment_request_history (
+			payment_request_id,
+			to_status,
+			actor_id
+		) VALUES ($1, $2, $3)`,
+		resp.ID,
+		resp.Status,
+		req.RequesterID,
+	)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return resp, nil
+}
+
+// GetPaymentRequestByID returns the payment request with its full status history
+func (r *paymentRequestRepo) GetPaymentRequestByID(ctx context.Context, id string) (*models.PaymentRequest, error) {
+	resp, err := scanPaymentRequest(r.db.QueryRowContext(ctx,
+		`SELECT`+paymentRequestColumns+`
+		FROM payment_requests
+		WHERE guid = $1`, id,
+	))
+	if err != nil && err == sql.ErrNoRows {
+		return nil, &customerrors.PaymentRequestNotFoundError{Guid: id}
+	} else if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT
+			guid,
+			from_status,
+			to_status,
+			actor_id,
+			note,
+			created_at
+		FROM payment_request_history
+		WHERE payment_request_id = $1
+		ORDER BY created_at`, id,
+	)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	resp.History = make([]*models.PaymentRequestHistory, 0)
+	for rows.Next() {
+		var (
+			h          models.PaymentRequestHistory
+			fromStatus sql.NullString
+			actorID    sql.NullString
+		)
+		err := rows.Scan(
+			&h.ID,
+			&fromStatus,
+			&h.ToStatus,
+			&actorID,
+			&h.Note,
+			&h.CreatedAt,
+		)
+		if err != nil {
+			return nil, &customerrors.InternalServerError{Message: err.Error()}
+		}
+		h.FromStatus = fromStatus.String
+		h.ActorID = actorID.String
+		resp.History = append(resp.History, &h)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return resp, nil
+}
+
+// GetPaymentRequests lists payment requests sent by a requester or addressed to a payer
+func (r *paymentRequestRepo) GetPaymentRequests(ctx context.Context, req *models.GetPaymentRequestsRequest) (*models.GetPaymentRequestsResponse, error) {
+	var (
+		count  int
+		params = map[string]interface{}{}
+	)
+	resp := &models.GetPaymentRequestsResponse{
+		PaymentRequests: make([]*models.PaymentRequest, 0),
+	}
+
+	query := `SELECT` + paymentRequestColumns + `,
+			count(1) OVER() AS count
+		FROM payment_requests
+		WHERE TRUE`
+
+	if req.RequesterID != "" {
+		query += ` AND requester_id = :requester_id`
+		params["requester_id"] = req.RequesterID
+	}
+	if req.PayerID != "" {
+		query += ` AND payer_id = :payer_id`
+		params["payer_id"] = req.PayerID
+	}
+	if req.Status != "" {
+		query += ` AND status = :status`
+		params["status"] = req.Status
+	}
+
+	query += ` ORDER BY created_at DESC LIMIT :limit OFFSET :offset`
+	params["limit"] = req.Limit
+	params["offset"] = req.Offset
+
+	query, args := helper.ReplaceQueryParams(query, params)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		p, err := scanPaymentRequest(rows, &count)
+		if err != nil {
+			return nil, &customerrors.InternalServerError{Message: err.Error()}
+		}
+		resp.PaymentRequests = append(resp.PaymentRequests, p)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	resp.Count = count
+
+	return resp, nil
+}
+
+// UpdatePaymentRequestStatus moves a payment request from one status to another and records the transition.
+// The update only succeeds while the request is still in FromStatus, so concurrent transitions can't both win.
+func (r *paymentRequestRepo) UpdatePaymentRequestStatus(ctx context.Context, req *models.UpdatePaymentRequestStatusRequest) (*models.PaymentRequest, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	defer tx.Rollback()
+
+	resp, err := scanPaymentRequest(tx.QueryRowContext(ctx,
+		`UPDATE payment_requests SET
+			status = $1,
+			transaction_id = COALESCE($2, transaction_id),
+			updated_at = CURRENT_TIMESTAMP
+		WHERE guid = $3 AND status = $4
+		RETURNING`+paymentRequestColumns,
+		req.ToStatus,
+		toNullString(req.TransactionID),
+		req.ID,
+		req.FromStatus,
+	))
+	if err != nil && err == sql.ErrNoRows {
+		return nil, &customerrors.PaymentRequestStateError{Status: req.FromStatus}
+	} else if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO payment_request_history (
+			payment_request_id,
+			from_status,
+			to_status,
+			actor_id,
+			note
+		) VALUES ($1, $2, $3, $4, $5)`,
+		req.ID,
+		req.FromStatus,
+		req.ToStatus,
+		toNullString(req.ActorID),
+		req.Note,
+	)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return resp, nil
+}
+
+// ExpirePaymentRequests marks every overdue pending request as expired
+func (r *paymentRequestRepo) ExpirePaymentRequests(ctx context.Context) error {
+	_, err := r.db.ExecContext(ctx,
+		`WITH expired AS (
+			UPDATE payment_requests SET
+				status = 'expired',
+				updated_at = CURRENT_TIMESTAMP
+			WHERE status = 'pending' AND expires_at <= CURRENT_TIMESTAMP
+			RETURNING guid
+		)
+		INSERT INTO payment_request_history (
+			payment_request_id,
+			from_status,
+			to_status,
+			note
+		)
+		SELECT guid, 'pending', 'expired', 'expired automatically' FROM expired`,
+	)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return nil
+}
@@ -13,10 +13,29 @@ import (
 )
 
 type Store struct {
-	db          *sql.DB
-	userRepo    *userRepo
-	accountRepo *accountRepo
-	txRepo      *txRepo
+	db                 *sql.DB
+	userRepo           *userRepo
+	accountRepo        *accountRepo
+	txRepo             *txRepo
+	paymentRequestRepo *paymentRequestRepo
+	beneficiaryRepo    *beneficiaryRepo
+	interestRepo       *interestRepo
+	jobRepo            *jobRepo
+	depositRepo        *depositRepo
+	overdraftRepo      *overdraftRepo
+	loanRepo           *loanRepo
+	potRepo            *potRepo
+	accountHolderRepo  *accountHolderRepo
+	otpRepo            *otpRepo
+	twoFactorRepo      *twoFactorRepo
+	loginFailureRepo   *loginFailureRepo
+	auditRepo          *auditRepo
+	adminRepo          *adminRepo
+	operationRepo      *operationRepo
+	fraudRepo          *fraudRepo
+	amlRepo            *amlRepo
+	sanctionsRepo      *sanctionsRepo
+	kycRepo            *kycRepo
 }
 
 func NewPostgres(ctx context.Context, cfg config.Config) (storage.StorageI, error) {
@@ -44,10 +63,29 @@ func NewPostgres(ctx context.Context, cfg config.Config) (storage.StorageI, erro
 
 func NewStore(db *sql.DB) *Store {
 	return &Store{
-		db:          db,
-		accountRepo: &accountRepo{db: db},
-		userRepo:    &userRepo{db: db},
-		txRepo:      &txRepo{db: db},
+		db:                 db,
+		accountRepo:        &accountRepo{db: db},
+		userRepo:           &userRepo{db: db},
+		txRepo:             &txRepo{db: db},
+		paymentRequestRepo: &paymentRequestRepo{db: db},
+		beneficiaryRepo:    &beneficiaryRepo{db: db},
+		interestRepo:       &interestRepo{db: db},
+		jobRepo:            &jobRepo{db: db},
+		depositRepo:        &depositRepo{db: db},
+		overdraftRepo:      &overdraftRepo{db: db},
+		loanRepo:           &loanRepo{db: db},
+		potRepo:            &potRepo{db: db},
+		accountHolderRepo:  &accountHolderRepo{db: db},
+		otpRepo:            &otpRepo{db: db},
+		twoFactorRepo:      &twoFactorRepo{db: db},
+		loginFailureRepo:   &loginFailureRepo{db: db},
+		auditRepo:          &auditRepo{db: db},
+		adminRepo:          &adminRepo{db: db},
+		operationRepo:      &operationRepo{db: db},
+		fraudRepo:          &fraudRepo{db: db},
+		amlRepo:            &amlRepo{db: db},
+		sanctionsRepo:      &sanctionsRepo{db: db},
+		kycRepo:            &kycRepo{db: db},
 	}
 }
 
@@ -75,3 +113,141 @@ func (s *Store) TxRepo() storage.TxRepoI {
 	}
 	return s.txRepo
 }
+
+func (s *Store) PaymentRequest() storage.PaymentRequestRepoI {
+	if s.paymentRequestRepo != nil {
+		return NewPaymentRequestRepo(s.db)
+	}
+	return s.paymentRequestRepo
+}
+
+func (s *Store) Beneficiary() storage.BeneficiaryRepoI {
+	if s.beneficiaryRepo != nil {
+		return NewBeneficiaryRepo(s.db)
+	}
+	return s.beneficiaryRepo
+}
+
+func (s *Store) Interest() storage.InterestRepoI {
+	if s.interestRepo != nil {
+		return NewInterestRepo(s.db)
+	}
+	return s.interestRepo
+}
+
+func (s *Store) Job() storage.JobRepoI {
+	if s.jobRepo != nil {
+		return NewJobRepo(s.db)
+	}
+	return s.jobRepo
+}
+
+func (s *Store) Deposit() storage.DepositRepoI {
+	if s.depositRepo != nil {
+		return NewDepositRepo(s.db)
+	}
+	return s.depositRepo
+}
+
+// toNullString maps an empty string to a SQL NULL
+func toNullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+func (s *Store) Overdraft() storage.OverdraftRepoI {
+	if s.overdraftRepo != nil {
+		return NewOverdraftRepo(s.db)
+	}
+	return s.overdraftRepo
+}
+
+func (s *Store) Loan() storage.LoanRepoI {
+	if s.loanRepo != nil {
+		return NewLoanRepo(s.db)
+	}
+	return s.loanRepo
+}
+
+func (s *Store) Pot() storage.PotRepoI {
+	if s.potRepo != nil {
+		return NewPotRepo(s.db)
+	}
+	return s.potRepo
+}
+
+func (s *Store) AccountHolder() storage.AccountHolderRepoI {
+	if s.accountHolderRepo != nil {
+		return NewAccountHolderRepo(s.db)
+	}
+	return s.accountHolderRepo
+}
+
+func (s *Store) OTP() storage.OTPRepoI {
+	if s.otpRepo != nil {
+		return NewOTPRepo(s.db)
+	}
+	return s.otpRepo
+}
+
+func (s *Store) TwoFactor() storage.TwoFactorRepoI {
+	if s.twoFactorRepo != nil {
+		return NewTwoFactorRepo(s.db)
+	}
+	return s.twoFactorRepo
+}
+
+func (s *Store) LoginFailure() storage.LoginFailureRepoI {
+	if s.loginFailureRepo != nil {
+		return NewLoginFailureRepo(s.db)
+	}
+	return s.loginFailureRepo
+}
+
+func (s *Store) Audit() storage.AuditRepoI {
+	if s.auditRepo != nil {
+		return NewAuditRepo(s.db)
+	}
+	return s.auditRepo
+}
+
+func (s *Store) Admin() storage.AdminRepoI {
+	if s.adminRepo != nil {
+		return NewAdminRepo(s.db)
+	}
+	return s.adminRepo
+}
+
+func (s *Store) Operation() storage.OperationRepoI {
+	if s.operationRepo != nil {
+		return NewOperationRepo(s.db)
+	}
+	return s.operationRepo
+}
+
+func (s *Store) Fraud() storage.FraudRepoI {
+	if s.fraudRepo != nil {
+		return NewFraudRepo(s.db)
+	}
+	return s.fraudRepo
+}
+
+func (s *Store) AML() storage.AMLRepoI {
+	if s.amlRepo != nil {
+		return NewAMLRepo(s.db)
+	}
+	return s.amlRepo
+}
+
+func (s *Store) Sanctions() storage.SanctionsRepoI {
+	if s.sanctionsRepo != nil {
+		return NewSanctionsRepo(s.db)
+	}
+	return s.sanctionsRepo
+}
+
+func (s *Store) KYC() storage.KYCRepoI {
+	if s.kycRepo != nil {
+		return NewKYCRepo(s.db)
+	}
+	return s.kycRepo
+}
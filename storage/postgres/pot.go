@@ -0,0 +1,242 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+type potRepo struct {
+	db *sql.DB
+}
+
+func NewPotRepo(db *sql.DB) *potRepo {
+	return &potRepo{db: db}
+}
+
+const potColumns = `
+			p.guid,
+			p.user_id,
+			p.account_id,
+			p.pot_account_id,
+			p.name,
+			p.target_amount::text,
+			to_char(p.target_date, 'YYYY-MM-DD'),
+			p.round_up_to::text,
+			p.status,
+			a.balance::numeric(20, 2)::text,
+			p.closed_at,
+			p.created_at,
+			p.updated_at`
+
+func scanPot(row rowScanner) (*models.Pot, error) {
+	var (
+		p            models.Pot
+		targetAmount sql.NullString
+		targetDate   sql.NullString
+		roundUpTo    sql.NullString
+		closedAt     sql.NullString
+	)
+
+	err := row.Scan(
+		&p.ID,
+		&p.UserID,
+		&p.AccountID,
+		&p.PotAccountID,
+		&p.Name,
+		&targetAmount,
+		&targetDate,
+		&roundUpTo,
+		&p.Status,
+		&p.Balance,
+		&closedAt,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	p.TargetAmount = targetAmount.String
+	p.TargetDate = targetDate.String
+	p.RoundUpTo = roundUpTo.String
+	p.ClosedAt = closedAt.String
+
+	return &p, nil
+}
+
+// CreatePot opens the account holding the pot's balance and stores the pot in the same statement
+func (r *potRepo) CreatePot(ctx context.Context, tx *sql.Tx, req *models.Pot) (*models.Pot, error) {
+	resp, err := scanPot(tx.QueryRowContext(ctx,
+		`WITH pot_account AS (
+			INSERT INTO accounts (user_id, balance, product)
+			VALUES ($1, 0, 'pot')
+			RETURNING guid, balance
+		), p AS (
+			INSERT INTO pots (
+				user_id,
+				account_id,
+				pot_account_id,
+				name,
+				target_amount,
+				target_date,
+				round_up_to
+			)
+			SELECT $1, $2, guid, $3, $4::numeric, $5::date, $6::numeric
+			FROM pot_account
+			RETURNING *
+		)
+		SELECT`+potColumns+`
+		FROM p
+		JOIN pot_account a ON a.guid = p.pot_account_id`,
+		req.UserID,
+		req.AccountID,
+		req.Name,
+		toNullString(req.TargetAmount),
+		toNullString(req.TargetDate),
+		toNullString(req.RoundUpTo),
+	))
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return resp, nil
+}
+
+// GetPots returns the pots of the account when the user holds it in a role that can view it
+func (r *potRepo) GetPots(ctx context.Context, req *models.GetPotsRequest) ([]*models.Pot, error) {
+	pots := make([]*models.Pot, 0)
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT`+potColumns+`
+		FROM pots p
+		JOIN accounts a ON a.guid = p.pot_account_id
+		WHERE p.account_id = $1 AND EXISTS (
+			SELECT 1 FROM account_holders h WHERE h.account_id = p.account_id AND h.user_id = $2 AND h.role = ANY($4))
+			AND ($3 OR p.status = 'active')
+		ORDER BY p.created_at`,
+		req.AccountID,
+		req.UserID,
+		req.Closed,
+		pq.Array(models.AccountRolesWith(models.AccountPermissionView)),
+	)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		p, err := scanPot(rows)
+		if err != nil {
+			return nil, &customerrors.InternalServerError{Message: err.Error()}
+		}
+		pots = append(pots, p)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return pots, nil
+}
+
+// GetPotByID returns the pot of the account when the user holds it in a role that can view it
+func (r *potRepo) GetPotByID(ctx context.Context, req *models.PotByIDRequest) (*models.Pot, error) {
+	resp, err := scanPot(r.db.QueryRowContext(ctx,
+		`SELECT`+potColumns+`
+		FROM pots p
+		JOIN accounts a ON a.guid = p.pot_account_id
+		WHERE p.guid = $1 AND p.account_id = $2 AND EXISTS (
+			SELECT 1 FROM account_holders h WHERE h.account_id = p.account_id AND h.user_id = $3 AND h.role = ANY($4))`,
+		req.ID,
+		req.AccountID,
+		req.UserID,
+		pq.Array(models.AccountRolesWith(models.AccountPermissionView)),
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &customerrors.PotNotFoundError{Guid: req.ID}
+	} else if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return resp, nil
+}
+
+// GetPotForUpdate locks the pot row until the transaction ends, so a move can't race the pot closing
+func (r *potRepo) GetPotForUpdate(ctx context.Context, tx *sql.Tx, id string) (*models.Pot, error) {
+	resp, err := scanPot(tx.QueryRowContext(ctx,
+		`SELECT`+potColumns+`
+		FROM pots p
+		JOIN accounts a ON a.guid = p.pot_account_id
+		WHERE p.guid = $1
+		FOR UPDATE OF p`,
+		id,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &customerrors.PotNotFoundError{Guid: id}
+	} else if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return resp, nil
+}
+
+// GetRoundUpPot returns the active pot collecting the account's round-ups, or nil when there is none
+func (r *potRepo) GetRoundUpPot(ctx context.Context, accountID string) (*models.Pot, error) {
+	resp, err := scanPot(r.db.QueryRowContext(ctx,
+		`SELECT`+potColumns+`
+		FROM pots p
+		JOIN accounts a ON a.guid = p.pot_account_id
+		WHERE p.account_id = $1 AND p.round_up_to IS NOT NULL AND p.status = 'active'`,
+		accountID,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return resp, nil
+}
+
+// UpdatePot stores the settings of a pot locked by GetPotForUpdate
+func (r *potRepo) UpdatePot(ctx context.Context, tx *sql.Tx, req *models.Pot) error {
+	_, err := tx.ExecContext(ctx,
+		`UPDATE pots SET
+			name = $2,
+			target_amount = $3::numeric,
+			target_date = $4::date,
+			round_up_to = $5::numeric,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE guid = $1`,
+		req.ID,
+		req.Name,
+		toNullString(req.TargetAmount),
+		toNullString(req.TargetDate),
+		toNullString(req.RoundUpTo),
+	)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return nil
+}
+
+// ClosePot marks a pot locked by GetPotForUpdate as closed, its balance has to be moved out first
+func (r *potRepo) ClosePot(ctx context.Context, tx *sql.Tx, id string) error {
+	_, err := tx.ExecContext(ctx,
+		`UPDATE pots SET
+			status = 'closed',
+			closed_at = CURRENT_TIMESTAMP,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE guid = $1`,
+		id,
+	)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return nil
+}
@@ -0,0 +1,217 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/helper"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+type sanctionsRepo struct {
+	db *sql.DB
+}
+
+func NewSanctionsRepo(db *sql.DB) *sanctionsRepo {
+	return &sanctionsRepo{db: db}
+}
+
+const sanctionsHitColumns = `
+			guid,
+			user_id,
+			source,
+			requested_by,
+			screened_name,
+			entry_uid,
+			entry_name,
+			matched_name,
+			programs,
+			score,
+			status,
+			reviewed_by,
+			review_comment,
+			reviewed_at,
+			created_at`
+
+func scanSanctionsHit(row rowScanner, extra ...interface{}) (*models.SanctionsHit, error) {
+	var (
+		h           models.SanctionsHit
+		requestedBy sql.NullString
+		reviewedBy  sql.NullString
+		reviewedAt  sql.NullString
+	)
+
+	dest := []interface{}{
+		&h.ID,
+		&h.UserID,
+		&h.Source,
+		&requestedBy,
+		&h.ScreenedName,
+		&h.EntryUID,
+		&h.EntryName,
+		&h.MatchedName,
+		pq.Array(&h.Programs),
+		&h.Score,
+		&h.Status,
+		&reviewedBy,
+		&h.ReviewComment,
+		&reviewedAt,
+		&h.CreatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	h.RequestedBy = requestedBy.String
+	h.ReviewedBy = reviewedBy.String
+	h.ReviewedAt = reviewedAt.String
+
+	return &h, nil
+}
+
+// CreateSanctionsHits stores the hits and returns the ones that are new, a user already matched
+// against an entry keeps the hit and its review
+func (r *sanctionsRepo) CreateSanctionsHits(ctx context.Context, tx *sql.Tx, hits []*models.SanctionsHit) ([]*models.SanctionsHit, error) {
+	resp := make([]*models.SanctionsHit, 0, len(hits))
+
+	for _, req := range hits {
+		h, err := scanSanctionsHit(tx.QueryRowContext(ctx,
+			`INSERT INTO sanctions_hits (
+				user_id,
+				source,
+				requested_by,
+				screened_name,
+				entry_uid,
+				entry_name,
+				matched_name,
+				programs,
+				score
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			ON CONFLICT (user_id, entry_uid) DO NOTHING
+			RETURNING`+sanctionsHitColumns,
+			req.UserID,
+			req.Source,
+			toNullString(req.RequestedBy),
+			req.ScreenedName,
+			req.EntryUID,
+			req.EntryName,
+			req.MatchedName,
+			pq.Array(req.Programs),
+			req.Score,
+		))
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		} else if err != nil {
+			return nil, &customerrors.InternalServerError{Message: err.Error()}
+		}
+		resp = append(resp, h)
+	}
+
+	return resp, nil
+}
+
+// HasBlockingSanctionsHit tells whether a holder of any of the accounts has a hit that is
+// pending review or confirmed
+func (r *sanctionsRepo) HasBlockingSanctionsHit(ctx context.Context, accountIDs []string) (bool, error) {
+	var exists bool
+
+	err := r.db.QueryRowContext(ctx,
+		`SELECT EXISTS (
+			SELECT 1
+			FROM account_holders ah
+			JOIN sanctions_hits h ON h.user_id = ah.user_id
+			WHERE ah.account_id = ANY($1) AND h.status IN ('pending', 'confirmed')
+		)`,
+		pq.Array(accountIDs),
+	).Scan(&exists)
+	if err != nil {
+		return false, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return exists, nil
+}
+
+// GetSanctionsHits returns the hits matching the filters, newest first
+func (r *sanctionsRepo) GetSanctionsHits(ctx context.Context, req *models.GetSanctionsHitsRequest) (*models.GetSanctionsHitsResponse, error) {
+	var count int
+	resp := &models.GetSanctionsHitsResponse{
+		Hits: make([]*models.SanctionsHit, 0),
+	}
+
+	qb := helper.NewQueryBuilder()
+	if req.UserID != "" {
+		qb.Where("user_id = ?", req.UserID)
+	}
+	if req.Status != "" {
+		qb.Where("status = ?", req.Status)
+	}
+	if req.Source != "" {
+		qb.Where("source = ?", req.Source)
+	}
+
+	query := `SELECT` + sanctionsHitColumns + `,
+			count(1) OVER() AS count
+		FROM sanctions_hits` + qb.WhereClause() + `
+		ORDER BY created_at DESC, guid DESC
+		LIMIT ` + qb.Arg(req.Limit) + ` OFFSET ` + qb.Arg(req.Offset)
+
+	rows, err := r.db.QueryContext(ctx, query, qb.Args()...)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		h, err := scanSanctionsHit(rows, &count)
+		if err != nil {
+			return nil, &customerrors.InternalServerError{Message: err.Error()}
+		}
+		resp.Hits = append(resp.Hits, h)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	resp.Count = count
+
+	return resp, nil
+}
+
+func (r *sanctionsRepo) GetSanctionsHit(ctx context.Context, id string) (*models.SanctionsHit, error) {
+	resp, err := scanSanctionsHit(r.db.QueryRowContext(ctx,
+		`SELECT`+sanctionsHitColumns+`
+		FROM sanctions_hits
+		WHERE guid = $1`,
+		id,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &customerrors.SanctionsHitNotFoundError{Guid: id}
+	} else if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return resp, nil
+}
+
+func (r *sanctionsRepo) ReviewSanctionsHit(ctx context.Context, tx *sql.Tx, req *models.ReviewSanctionsHitRequest) error {
+	result, err := tx.ExecContext(ctx,
+		`UPDATE sanctions_hits SET
+			status = $2,
+			reviewed_by = $3,
+			review_comment = $4,
+			reviewed_at = CURRENT_TIMESTAMP
+		WHERE guid = $1`,
+		req.ID,
+		req.Status,
+		req.ActorID,
+		req.Comment,
+	)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return &customerrors.SanctionsHitNotFoundError{Guid: req.ID}
+	}
+	return nil
+}
@@ -3,9 +3,9 @@ package postgres
 import (
 	"context"
 	"database/sql"
-	"fmt"
 
 	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/helper"
 	"github.com/dilmurodov/online_banking/pkg/models"
 	"github.com/dilmurodov/online_banking/pkg/util"
 	"github.com/lib/pq"
@@ -35,14 +35,18 @@ func (r *txRepo) CreateTransaction(ctx context.Context, tx *sql.Tx, transaction
 			(account_id, 
 			transaction_amount,
 			recipient_id,
-			transaction_type
+			transaction_type,
+			description,
+			reference
 		) 
-		VALUES ($1, $2, $3, $4) 
+		VALUES ($1, $2, $3, $4, $5, $6) 
 		RETURNING 
 			guid, 
 			transaction_amount, 
 			transaction_type, 
 			recipient_id, 
+			description,
+			reference,
 			created_at`)
 	if err != nil {
 		return nil, &customerrors.InternalServerError{Message: err.Error()}
@@ -54,12 +58,16 @@ func (r *txRepo) CreateTransaction(ctx context.Context, tx *sql.Tx, transaction
 		transaction.Amount,
 		transaction.RecipientID,
 		transaction.Type,
+		transaction.Description,
+		transaction.Reference,
 	)
 	err = row.Scan(
 		&resp.ID,
 		&resp.Amount,
 		&resp.Type,
 		&resp.RecipientID,
+		&resp.Description,
+		&resp.Reference,
 		&resp.CreatedAt,
 	)
 	if err != nil {
@@ -68,70 +76,79 @@ func (r *txRepo) CreateTransaction(ctx context.Context, tx *sql.Tx, transaction
 	return resp, nil
 }
 
-// GetTransactionsByAccountID returns all transactions associated with the given account ID
+// GetTransactionsByAccountID returns the transactions of the given account.
+// With a cursor the page is read by the (created_at, guid) keyset, otherwise by the legacy offset.
 func (r *txRepo) GetTransactionsByAccountID(ctx context.Context, req *models.GetTransactionsByAccountIDRequest) (*models.GetTransactionsByAccountIDResponse, error) {
 	var (
 		count         int
-		params        = []interface{}{}
 		doneTimestamp sql.NullString
+		page          = helper.KeysetPage{Desc: req.Desc}
+		limit         = 10
 	)
 	transactions := make([]*models.Transaction, 0)
 
-	query :=
-		`SELECT 
-			guid, 
-			account_id, 
-			transaction_amount, 
-			transaction_type,
-			recipient_id, 
-			created_at,
-			count(1) filter (where deleted_at IS NULL) OVER() AS count,
-			approved,
-			done,
-			done_timestamp
-		FROM transactions`
-
-	filter := ` WHERE account_id=$1 AND deleted_at IS NULL`
-	params = append(params, req.AccountID)
+	qb := helper.NewQueryBuilder().
+		Where("account_id = ?", req.AccountID).
+		Where("deleted_at IS NULL")
 
-	order := ` ORDER BY created_at`
-	limit := ` LIMIT 10`
-	offset := ` OFFSET 0`
+	// the window count is what makes deep pages slow, so it is only kept for offset mode
+	countColumn := "count(1) filter (where deleted_at IS NULL) OVER()"
+	if req.Cursor != "" {
+		cursor, err := helper.DecodeCursor(req.Cursor)
+		if err != nil {
+			return nil, &customerrors.InvalidCursorError{}
+		}
+		page.Cursor = cursor
+		countColumn = "0"
+		qb.Where("(created_at, guid) "+page.Operator()+" (?, ?)", cursor.CreatedAt, cursor.ID)
+	}
 
 	if util.IsValidTimeStamp(req.From) {
-		filter += " AND created_at >= $3"
-		params = append(params, req.From)
+		qb.Where("created_at >= ?", req.From)
 	}
 
 	if util.IsValidTimeStamp(req.To) {
-		filter += " AND created_at <= $4"
-		params = append(params, req.To)
+		qb.Where("created_at <= ?", req.To)
 	}
 
 	if util.IsValidUUID(req.RecipientID) {
-		filter += " AND recipient_id = $5"
-		params = append(params, req.RecipientID)
+		qb.Where("recipient_id = ?", req.RecipientID)
 	}
 
-	switch req.Desc {
-	case false:
-		order += " ASC"
-	default:
-		order += " DESC"
+	if req.Search != "" {
+		qb.Where("(to_tsvector('simple', description) @@ plainto_tsquery('simple', ?) OR reference = ?)", req.Search, req.Search)
 	}
 
-	if req.Limit != 0 {
-		limit = fmt.Sprintf(" LIMIT %d", req.Limit)
+	if req.Limit > 0 {
+		limit = req.Limit
 	}
 
-	if req.Offset != 0 {
-		offset = fmt.Sprintf(" OFFSET %d", req.Offset)
+	query :=
+		`SELECT 
+			guid, 
+			account_id, 
+			transaction_amount, 
+			transaction_type,
+			recipient_id, 
+			description,
+			reference,
+			created_at,
+			` + countColumn + ` AS count,
+			approved,
+			done,
+			done_timestamp
+		FROM transactions` + qb.WhereClause() + `
+		ORDER BY created_at ` + page.Order() + `, guid ` + page.Order()
+
+	// one extra row tells whether there is a page after this one
+	query += ` LIMIT ` + qb.Arg(limit+1)
+	if req.Cursor == "" {
+		query += ` OFFSET ` + qb.Arg(req.Offset)
 	}
 
-	query = query + filter + order + limit + offset
 	rows, err := r.db.QueryContext(
 		ctx,
-		query, params...)
+		query, qb.Args()...)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to query rows")
 	}
@@ -145,6 +162,8 @@ func (r *txRepo) GetTransactionsByAccountID(ctx context.Context, req *models.Get
 			&t.Amount,
 			&t.Type,
 			&t.RecipientID,
+			&t.Description,
+			&t.Reference,
 			&t.CreatedAt,
 			&count,
 			&t.Approved,
@@ -157,14 +176,34 @@ func (r *txRepo) GetTransactionsByAccountID(ctx context.Context, req *models.Get
 		t.DoneTimestamp = doneTimestamp.String
 		transactions = append(transactions, &t)
 	}
-	if err != nil {
+	if err = rows.Err(); err != nil {
 		return nil, &customerrors.InternalServerError{Message: err.Error()}
 	}
 
-	return &models.GetTransactionsByAccountIDResponse{
-		Transactions: transactions,
-		Count:        count,
-	}, nil
+	resp := &models.GetTransactionsByAccountIDResponse{
+		Count: count,
+	}
+
+	hasMore := len(transactions) > limit
+	if hasMore {
+		transactions = transactions[:limit]
+	}
+	if page.Cursor != nil && page.Cursor.Backward {
+		for i, j := 0, len(transactions)-1; i < j; i, j = i+1, j-1 {
+			transactions[i], transactions[j] = transactions[j], transactions[i]
+		}
+	}
+	if len(transactions) > 0 {
+		first, last := transactions[0], transactions[len(transactions)-1]
+		resp.NextCursor, resp.PrevCursor = page.Cursors(
+			helper.Cursor{CreatedAt: first.CreatedAt, ID: first.ID},
+			helper.Cursor{CreatedAt: last.CreatedAt, ID: last.ID},
+			hasMore,
+		)
+	}
+	resp.Transactions = transactions
+
+	return resp, nil
 }
 
 func (r *txRepo) GetTransactionByID(ctx context.Context, req *models.GetTransactionByIDRequest) (*models.Transaction, error) {
@@ -180,6 +219,8 @@ func (r *txRepo) GetTransactionByID(ctx context.Context, req *models.GetTransact
 			transaction_amount,
 			transaction_type, 
 			recipient_id, 
+			description,
+			reference,
 			created_at,
 			approved,
 			done,
@@ -198,6 +239,8 @@ func (r *txRepo) GetTransactionByID(ctx context.Context, req *models.GetTransact
 		&t.Amount,
 		&t.Type,
 		&t.RecipientID,
+		&t.Description,
+		&t.Reference,
 		&createdAt,
 		&t.Approved,
 		&t.Done,
@@ -231,6 +274,8 @@ func (r *txRepo) GetTransactionsByIDS(ctx context.Context, req *models.GetTransa
 			transaction_amount,
 			transaction_type, 
 			recipient_id, 
+			description,
+			reference,
 			created_at,
 			approved,
 			done,
@@ -256,6 +301,8 @@ func (r *txRepo) GetTransactionsByIDS(ctx context.Context, req *models.GetTransa
 			&t.Amount,
 			&t.Type,
 			&t.RecipientID,
+			&t.Description,
+			&t.Reference,
 			&createdAt,
 			&t.Approved,
 			&t.Done,
@@ -301,3 +348,168 @@ func (r *txRepo) ApproveTransactions(ctx context.Context, tx *sql.Tx, req *model
 
 	return nil
 }
+
+// GetTransferredTotal sums the debits sent to the recipient since the given time from any account the user holds,
+// joint accounts included
+func (r *txRepo) GetTransferredTotal(ctx context.Context, req *models.GetTransferredTotalRequest) (total float64, err error) {
+	query :=
+		`SELECT
+			COALESCE(SUM(t.transaction_amount), 0)
+		FROM transactions t
+		JOIN account_holders h ON h.account_id = t.account_id
+		WHERE
+			h.user_id = $1 AND
+			t.recipient_id = $2 AND
+			t.transaction_type = 'debit' AND
+			t.created_at >= $3 AND
+			t.deleted_at IS NULL`
+
+	err = r.db.QueryRowContext(ctx, query, req.UserID, req.RecipientID, req.From).Scan(&total)
+	if err != nil {
+		return 0, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return total, nil
+}
+
+// SearchTransactions searches the transactions of all the user's accounts and counts the matches by type and month
+func (r *txRepo) SearchTransactions(ctx context.Context, req *models.SearchTransactionsRequest) (*models.SearchTransactionsResponse, error) {
+	var count int
+
+	resp := &models.SearchTransactionsResponse{
+		Transactions: make([]*models.Transaction, 0),
+		Facets: &models.TransactionFacets{
+			ByType:  make([]*models.FacetCount, 0),
+			ByMonth: make([]*models.FacetCount, 0),
+		},
+	}
+
+	// the user's accounts are the ones they hold in a role that can view them, joint accounts included
+	qb := helper.NewQueryBuilder().
+		Where(`t.account_id IN (SELECT h.account_id FROM account_holders h
+			JOIN accounts a ON a.guid = h.account_id AND a.deleted_at = 0
+			WHERE h.user_id = ? AND h.role = ANY(?))`, req.UserID, pq.Array(models.AccountRolesWith(models.AccountPermissionView))).
+		Where("t.deleted_at IS NULL")
+
+	if req.Query != "" {
+		qb.Where("(to_tsvector('simple', t.description) @@ plainto_tsquery('simple', ?) OR t.reference = ?)", req.Query, req.Query)
+	}
+	if req.Type != "" {
+		qb.Where("t.transaction_type = ?", req.Type)
+	}
+	switch req.Status {
+	case models.TransactionStatusPending:
+		qb.Where("t.done = false")
+	case models.TransactionStatusCompleted:
+		qb.Where("t.done = true")
+	}
+	if req.MinAmount > 0 {
+		qb.Where("t.transaction_amount >= ?", req.MinAmount)
+	}
+	if req.MaxAmount > 0 {
+		qb.Where("t.transaction_amount <= ?", req.MaxAmount)
+	}
+	if util.IsValidUUID(req.CounterpartyID) {
+		qb.Where("t.recipient_id = ?", req.CounterpartyID)
+	}
+	if util.IsValidTimeStamp(req.From) {
+		qb.Where("t.created_at >= ?", req.From)
+	}
+	if util.IsValidTimeStamp(req.To) {
+		qb.Where("t.created_at <= ?", req.To)
+	}
+
+	where := qb.WhereClause()
+
+	// Facets are computed over the same filter before the pagination parameters are added
+	facetRows, err := r.db.QueryContext(ctx,
+		`SELECT
+			t.transaction_type,
+			to_char(date_trunc('month', t.created_at), 'YYYY-MM'),
+			GROUPING(t.transaction_type),
+			count(1)
+		FROM transactions t`+where+`
+		GROUP BY GROUPING SETS ((t.transaction_type), (date_trunc('month', t.created_at)))
+		ORDER BY 2, 1`,
+		qb.Args()...,
+	)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	defer facetRows.Close()
+
+	for facetRows.Next() {
+		var (
+			txType   sql.NullString
+			month    sql.NullString
+			grouping int
+			cnt      int
+		)
+		if err := facetRows.Scan(&txType, &month, &grouping, &cnt); err != nil {
+			return nil, &customerrors.InternalServerError{Message: err.Error()}
+		}
+		if grouping == 0 {
+			resp.Facets.ByType = append(resp.Facets.ByType, &models.FacetCount{Key: txType.String, Count: cnt})
+		} else {
+			resp.Facets.ByMonth = append(resp.Facets.ByMonth, &models.FacetCount{Key: month.String, Count: cnt})
+		}
+	}
+	if err = facetRows.Err(); err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	query := `SELECT
+			t.guid,
+			t.account_id,
+			t.transaction_amount,
+			t.transaction_type,
+			t.recipient_id,
+			t.description,
+			t.reference,
+			t.created_at,
+			t.approved,
+			t.done,
+			t.done_timestamp,
+			count(1) OVER() AS count
+		FROM transactions t` + where + `
+		ORDER BY t.created_at DESC
+		LIMIT ` + qb.Arg(req.Limit) + ` OFFSET ` + qb.Arg(req.Offset)
+
+	rows, err := r.db.QueryContext(ctx, query, qb.Args()...)
+	if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			t             models.Transaction
+			doneTimestamp sql.NullString
+		)
+		err := rows.Scan(
+			&t.ID,
+			&t.AccountID,
+			&t.Amount,
+			&t.Type,
+			&t.RecipientID,
+			&t.Description,
+			&t.Reference,
+			&t.CreatedAt,
+			&t.Approved,
+			&t.Done,
+			&doneTimestamp,
+			&count,
+		)
+		if err != nil {
+			return nil, &customerrors.InternalServerError{Message: err.Error()}
+		}
+		t.DoneTimestamp = doneTimestamp.String
+		resp.Transactions = append(resp.Transactions, &t)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	resp.Count = count
+
+	return resp, nil
+}
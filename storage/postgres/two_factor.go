@@ -0,0 +1,162 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dilmurodov/online_banking/pkg/customerrors"
+	"github.com/dilmurodov/online_banking/pkg/models"
+	"github.com/pkg/errors"
+)
+
+type twoFactorRepo struct {
+	db *sql.DB
+}
+
+func NewTwoFactorRepo(db *sql.DB) *twoFactorRepo {
+	return &twoFactorRepo{db: db}
+}
+
+// SetTOTPSecret stores a new secret that waits for its first code, 2FA is off until then
+func (r *twoFactorRepo) SetTOTPSecret(ctx context.Context, userID, secret string) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE "users" SET
+			totp_secret = $2,
+			totp_enabled_at = NULL,
+			totp_last_step = 0,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE guid = $1 AND deleted_at = 0 AND totp_enabled_at IS NULL`,
+		userID,
+		secret,
+	)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+	if cn, err := result.RowsAffected(); err != nil || cn == 0 {
+		return &customerrors.TwoFactorStateError{Enabled: true}
+	}
+
+	return nil
+}
+
+// GetTOTPForUpdate locks the user's row so a code can't be accepted twice
+func (r *twoFactorRepo) GetTOTPForUpdate(ctx context.Context, tx *sql.Tx, userID string) (*models.TOTPSettings, error) {
+	var (
+		settings  = models.TOTPSettings{UserID: userID}
+		secret    sql.NullString
+		enabledAt sql.NullString
+	)
+
+	err := tx.QueryRowContext(ctx,
+		`SELECT
+			totp_secret,
+			totp_enabled_at,
+			totp_last_step
+		FROM "users"
+		WHERE guid = $1 AND deleted_at = 0
+		FOR UPDATE`,
+		userID,
+	).Scan(
+		&secret,
+		&enabledAt,
+		&settings.LastStep,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &customerrors.UserNotFoundError{Guid: userID}
+	} else if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+	settings.Secret = secret.String
+	settings.EnabledAt = enabledAt.String
+
+	return &settings, nil
+}
+
+func (r *twoFactorRepo) EnableTOTP(ctx context.Context, tx *sql.Tx, userID string, step int64) error {
+	_, err := tx.ExecContext(ctx,
+		`UPDATE "users" SET
+			totp_enabled_at = CURRENT_TIMESTAMP,
+			totp_last_step = $2,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE guid = $1`,
+		userID,
+		step,
+	)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+	return nil
+}
+
+func (r *twoFactorRepo) UpdateTOTPLastStep(ctx context.Context, tx *sql.Tx, userID string, step int64) error {
+	_, err := tx.ExecContext(ctx, `UPDATE "users" SET totp_last_step = $2 WHERE guid = $1`, userID, step)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+	return nil
+}
+
+// DisableTOTP drops the secret and the recovery codes
+func (r *twoFactorRepo) DisableTOTP(ctx context.Context, tx *sql.Tx, userID string) error {
+	_, err := tx.ExecContext(ctx,
+		`UPDATE "users" SET
+			totp_secret = NULL,
+			totp_enabled_at = NULL,
+			totp_last_step = 0,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE guid = $1`,
+		userID,
+	)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return nil
+}
+
+// ReplaceRecoveryCodes drops the user's recovery codes and stores the new hashes
+func (r *twoFactorRepo) ReplaceRecoveryCodes(ctx context.Context, tx *sql.Tx, userID string, hashes []string) error {
+	_, err := tx.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO mfa_recovery_codes (user_id, code_hash) VALUES ($1, $2)`)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+	defer stmt.Close()
+
+	for _, hash := range hashes {
+		if _, err = stmt.ExecContext(ctx, userID, hash); err != nil {
+			return &customerrors.InternalServerError{Message: err.Error()}
+		}
+	}
+
+	return nil
+}
+
+// UseRecoveryCode spends the matching unused code, false means there is none
+func (r *twoFactorRepo) UseRecoveryCode(ctx context.Context, tx *sql.Tx, userID, hash string) (bool, error) {
+	result, err := tx.ExecContext(ctx,
+		`UPDATE mfa_recovery_codes SET used_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`,
+		userID,
+		hash,
+	)
+	if err != nil {
+		return false, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	cn, err := result.RowsAffected()
+	if err != nil {
+		return false, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return cn > 0, nil
+}
@@ -3,10 +3,11 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"strings"
 
 	"github.com/dilmurodov/online_banking/pkg/customerrors"
 	"github.com/dilmurodov/online_banking/pkg/models"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 type userRepo struct {
@@ -30,7 +31,10 @@ func (u *userRepo) GetUserByID(ctx context.Context, req *models.GetUserByIDReque
 			last_name, 
 			phone, 
 			created_at, 
-			updated_at
+			updated_at,
+			sessions_revoked_at,
+			totp_enabled_at IS NOT NULL,
+			role
 		FROM "users"
 		`
 
@@ -54,6 +58,9 @@ func (u *userRepo) GetUserByID(ctx context.Context, req *models.GetUserByIDReque
 		&user.Phone,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.SessionsRevokedAt,
+		&user.TwoFactorEnabled,
+		&user.Role,
 	)
 	if err != nil && err == sql.ErrNoRows {
 		return nil, &customerrors.UserNotFoundError{Guid: req.UserId}
@@ -78,7 +85,9 @@ func (u *userRepo) GetUserPasswordByPhone(ctx context.Context, phone string) (re
 			phone,
 			password,
 			created_at,
-			updated_at
+			updated_at,
+			totp_enabled_at IS NOT NULL,
+			role
 		FROM "users"
 		WHERE phone = $1 AND deleted_at = 0
 	`
@@ -94,6 +103,8 @@ func (u *userRepo) GetUserPasswordByPhone(ctx context.Context, phone string) (re
 		&resp.Password,
 		&resp.CreatedAt,
 		&resp.UpdatedAt,
+		&resp.TwoFactorEnabled,
+		&resp.Role,
 	)
 
 	if err != nil && err == sql.ErrNoRows {
@@ -105,7 +116,7 @@ func (u *userRepo) GetUserPasswordByPhone(ctx context.Context, phone string) (re
 	return resp, nil
 }
 
-func (u *userRepo) CreateUser(ctx context.Context, req *models.CreateUserRequest) (resp *models.User, err error) {
+func (u *userRepo) CreateUser(ctx context.Context, tx *sql.Tx, req *models.CreateUserRequest) (resp *models.User, err error) {
 	resp = &models.User{}
 
 	query := `
@@ -119,7 +130,7 @@ func (u *userRepo) CreateUser(ctx context.Context, req *models.CreateUserRequest
 		) RETURNING guid, first_name, last_name, phone, created_at, updated_at
 	`
 
-	row := u.db.QueryRowContext(ctx, query,
+	row := tx.QueryRowContext(ctx, query,
 		req.User.FirstName,
 		req.User.LastName,
 		req.User.Phone,
@@ -140,3 +151,194 @@ func (u *userRepo) CreateUser(ctx context.Context, req *models.CreateUserRequest
 
 	return resp, nil
 }
+
+func (u *userRepo) SetDefaultAccount(ctx context.Context, req *models.SetDefaultAccountRequest) error {
+
+	query := `
+		UPDATE "users" SET
+			default_account_id = $1,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE guid = $2 AND deleted_at = 0
+	`
+
+	result, err := u.db.ExecContext(ctx, query, req.AccountID, req.UserID)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+	if cn, err := result.RowsAffected(); err != nil || cn == 0 {
+		return &customerrors.UserNotFoundError{Guid: req.UserID}
+	}
+
+	return nil
+}
+
+// GetPhoneRecipient resolves a phone number to the user's default receiving account,
+// falling back to the user's oldest own account when no default is set. The accounts are the
+// ones the user can transact on, joint accounts included, like the ones a default can be set to.
+func (u *userRepo) GetPhoneRecipient(ctx context.Context, phone string) (resp *models.PhoneRecipient, err error) {
+
+	query := `
+		SELECT
+			u.guid,
+			u.first_name,
+			u.last_name,
+			a.guid
+		FROM "users" u
+		JOIN account_holders h ON h.user_id = u.guid AND h.role = ANY($2)
+		JOIN accounts a ON a.guid = h.account_id AND a.deleted_at = 0 AND a.status <> 'closed' AND a.product NOT IN ('term_deposit', 'pot')
+		WHERE u.phone = $1 AND u.deleted_at = 0
+		ORDER BY (a.guid = u.default_account_id) DESC NULLS LAST, (a.user_id = u.guid) DESC, a.created_at
+		LIMIT 1
+	`
+
+	resp = &models.PhoneRecipient{}
+	err = u.db.QueryRowContext(ctx, query, phone, pq.Array(models.AccountRolesWith(models.AccountPermissionTransact))).Scan(
+		&resp.UserID,
+		&resp.FirstName,
+		&resp.LastName,
+		&resp.AccountID,
+	)
+	if err != nil && err == sql.ErrNoRows {
+		return nil, &customerrors.RecipientNotFoundError{}
+	} else if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return resp, nil
+}
+
+// UpdateUser changes the names that are set in the request and keeps the others
+func (u *userRepo) UpdateUser(ctx context.Context, req *models.UpdateProfileRequest) (resp *models.User, err error) {
+	resp = &models.User{}
+
+	query := `
+		UPDATE "users" SET
+			first_name = COALESCE($2, first_name),
+			last_name = COALESCE($3, last_name),
+			updated_at = CURRENT_TIMESTAMP
+		WHERE guid = $1 AND deleted_at = 0
+		RETURNING guid, first_name, last_name, phone, created_at, updated_at
+	`
+
+	err = u.db.QueryRowContext(ctx, query, req.UserID, req.FirstName, req.LastName).Scan(
+		&resp.Guid,
+		&resp.FirstName,
+		&resp.LastName,
+		&resp.Phone,
+		&resp.CreatedAt,
+		&resp.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, &customerrors.UserNotFoundError{Guid: req.UserID}
+	} else if err != nil {
+		return nil, &customerrors.InternalServerError{Message: err.Error()}
+	}
+
+	return resp, nil
+}
+
+func (u *userRepo) UpdateUserPassword(ctx context.Context, tx *sql.Tx, userID, password string) error {
+
+	query := `
+		UPDATE "users" SET
+			password = $2,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE guid = $1 AND deleted_at = 0
+	`
+
+	result, err := tx.ExecContext(ctx, query, userID, password)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+	if cn, err := result.RowsAffected(); err != nil || cn == 0 {
+		return &customerrors.UserNotFoundError{Guid: userID}
+	}
+
+	return nil
+}
+
+// ResetUserPassword sets the new password and revokes every token issued so far
+func (u *userRepo) ResetUserPassword(ctx context.Context, tx *sql.Tx, userID, password string) error {
+
+	query := `
+		UPDATE "users" SET
+			password = $2,
+			sessions_revoked_at = floor(extract(epoch FROM CURRENT_TIMESTAMP))::bigint,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE guid = $1 AND deleted_at = 0
+	`
+
+	result, err := tx.ExecContext(ctx, query, userID, password)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+	if cn, err := result.RowsAffected(); err != nil || cn == 0 {
+		return &customerrors.UserNotFoundError{Guid: userID}
+	}
+
+	return nil
+}
+
+func (u *userRepo) UpdateUserPhone(ctx context.Context, tx *sql.Tx, userID, phone string) error {
+
+	query := `
+		UPDATE "users" SET
+			phone = $2,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE guid = $1 AND deleted_at = 0
+	`
+
+	result, err := tx.ExecContext(ctx, query, userID, phone)
+	if err != nil && strings.Contains(err.Error(), "users_phone_deleted_at_unique") {
+		return &customerrors.UserAlreadyExistsError{Phone: phone}
+	} else if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+	if cn, err := result.RowsAffected(); err != nil || cn == 0 {
+		return &customerrors.UserNotFoundError{Guid: userID}
+	}
+
+	return nil
+}
+
+// DeleteUser soft deletes the user, deleted_at holds the unix time so the phone can be registered again
+func (u *userRepo) DeleteUser(ctx context.Context, tx *sql.Tx, userID string) error {
+
+	query := `
+		UPDATE "users" SET
+			deleted_at = extract(epoch FROM CURRENT_TIMESTAMP)::integer,
+			default_account_id = NULL,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE guid = $1 AND deleted_at = 0
+	`
+
+	result, err := tx.ExecContext(ctx, query, userID)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+	if cn, err := result.RowsAffected(); err != nil || cn == 0 {
+		return &customerrors.UserNotFoundError{Guid: userID}
+	}
+
+	return nil
+}
+
+func (u *userRepo) SetUserRole(ctx context.Context, tx *sql.Tx, userID, role string) error {
+
+	query := `
+		UPDATE "users" SET
+			role = $2,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE guid = $1 AND deleted_at = 0
+	`
+
+	result, err := tx.ExecContext(ctx, query, userID, role)
+	if err != nil {
+		return &customerrors.InternalServerError{Message: err.Error()}
+	}
+	if cn, err := result.RowsAffected(); err != nil || cn == 0 {
+		return &customerrors.UserNotFoundError{Guid: userID}
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/dilmurodov/online_banking/pkg/models"
 )
@@ -12,19 +13,52 @@ type StorageI interface {
 	User() UserRepoI
 	Account() AccountRepoI
 	TxRepo() TxRepoI
+	PaymentRequest() PaymentRequestRepoI
+	Beneficiary() BeneficiaryRepoI
+	Interest() InterestRepoI
+	Job() JobRepoI
+	Deposit() DepositRepoI
+	Overdraft() OverdraftRepoI
+	Loan() LoanRepoI
+	Pot() PotRepoI
+	AccountHolder() AccountHolderRepoI
+	OTP() OTPRepoI
+	TwoFactor() TwoFactorRepoI
+	LoginFailure() LoginFailureRepoI
+	Audit() AuditRepoI
+	Admin() AdminRepoI
+	Operation() OperationRepoI
+	Fraud() FraudRepoI
+	AML() AMLRepoI
+	Sanctions() SanctionsRepoI
+	KYC() KYCRepoI
 }
 
 type UserRepoI interface {
 	GetUserByID(context.Context, *models.GetUserByIDRequest) (*models.GetUserByIDResponse, error)
-	CreateUser(context.Context, *models.CreateUserRequest) (*models.User, error)
+	CreateUser(ctx context.Context, tx *sql.Tx, req *models.CreateUserRequest) (*models.User, error)
 	GetUserPasswordByPhone(ctx context.Context, phone string) (resp *models.User, err error)
+	SetDefaultAccount(ctx context.Context, req *models.SetDefaultAccountRequest) error
+	GetPhoneRecipient(ctx context.Context, phone string) (resp *models.PhoneRecipient, err error)
+	UpdateUser(ctx context.Context, req *models.UpdateProfileRequest) (*models.User, error)
+	UpdateUserPassword(ctx context.Context, tx *sql.Tx, userID, password string) error
+	ResetUserPassword(ctx context.Context, tx *sql.Tx, userID, password string) error
+	UpdateUserPhone(ctx context.Context, tx *sql.Tx, userID, phone string) error
+	DeleteUser(ctx context.Context, tx *sql.Tx, userID string) error
+	SetUserRole(ctx context.Context, tx *sql.Tx, userID, role string) error
 }
 
 type AccountRepoI interface {
 	GetAccountByID(context.Context, *models.GetAccountByIDRequest) (*models.Account, error)
 	CreateAccount(context.Context, *models.CreateAccountRequest) (*models.Account, error)
 	GetAccountsByUserID(context.Context, *models.GetAccountsByUserIDRequest) (resp *models.GetAccountsByUserIDResponse, err error)
-	UpdateAccountBalance(context.Context, *sql.Tx, *models.Account) error
+	AdjustAccountBalance(ctx context.Context, tx *sql.Tx, req *models.AdjustAccountBalanceRequest) error
+	GetAccountStatus(ctx context.Context, tx *sql.Tx, accountID string) (string, error)
+	GetAccountForUpdate(ctx context.Context, tx *sql.Tx, accountID string) (*models.Account, error)
+	GetOwnedAccountsForUpdate(ctx context.Context, tx *sql.Tx, userID string) ([]*models.Account, error)
+	UpdateAccountStatus(ctx context.Context, tx *sql.Tx, req *models.AccountStatusChange) error
+	GetAccountStatusHistory(ctx context.Context, accountID string) (*models.GetAccountStatusHistoryResponse, error)
+	GetClosingBlockers(ctx context.Context, tx *sql.Tx, accountID string) (*models.AccountClosingBlockers, error)
 }
 
 type TxRepoI interface {
@@ -34,4 +68,200 @@ type TxRepoI interface {
 	GetTransactionByID(ctx context.Context, req *models.GetTransactionByIDRequest) (resp *models.Transaction, err error)
 	GetTransactionsByIDS(ctx context.Context, req *models.GetTransactionsByIDSRequest) (resp *models.GetTransactionsByIDSResponse, err error)
 	ApproveTransactions(ctx context.Context, tx *sql.Tx, req *models.ApproveTransactionsRequest) (err error)
+	GetTransferredTotal(ctx context.Context, req *models.GetTransferredTotalRequest) (total float64, err error)
+	SearchTransactions(ctx context.Context, req *models.SearchTransactionsRequest) (*models.SearchTransactionsResponse, error)
+}
+
+type PaymentRequestRepoI interface {
+	CreatePaymentRequest(ctx context.Context, req *models.PaymentRequest) (*models.PaymentRequest, error)
+	GetPaymentRequestByID(ctx context.Context, id string) (*models.PaymentRequest, error)
+	GetPaymentRequests(ctx context.Context, req *models.GetPaymentRequestsRequest) (*models.GetPaymentRequestsResponse, error)
+	UpdatePaymentRequestStatus(ctx context.Context, req *models.UpdatePaymentRequestStatusRequest) (*models.PaymentRequest, error)
+	ExpirePaymentRequests(ctx context.Context) error
+}
+
+type BeneficiaryRepoI interface {
+	CreateBeneficiary(ctx context.Context, tx *sql.Tx, req *models.CreateBeneficiaryRequest) (*models.Beneficiary, error)
+	GetBeneficiaryByID(ctx context.Context, req *models.BeneficiaryByIDRequest) (*models.Beneficiary, error)
+	GetBeneficiaryForUpdate(ctx context.Context, tx *sql.Tx, req *models.BeneficiaryByIDRequest) (*models.Beneficiary, error)
+	GetBeneficiaryByAccount(ctx context.Context, req *models.BeneficiaryByAccountRequest) (*models.Beneficiary, error)
+	GetBeneficiaries(ctx context.Context, req *models.GetBeneficiariesRequest) (*models.GetBeneficiariesResponse, error)
+	UpdateBeneficiary(ctx context.Context, tx *sql.Tx, req *models.UpdateBeneficiaryRequest) (*models.Beneficiary, error)
+	DeleteBeneficiary(ctx context.Context, tx *sql.Tx, req *models.BeneficiaryByIDRequest) error
+}
+
+type InterestRepoI interface {
+	GetProducts(ctx context.Context) (*models.GetProductsResponse, error)
+	GetProductByCode(ctx context.Context, code string) (*models.Product, error)
+	GetAccrualCandidates(ctx context.Context, req *models.GetAccrualCandidatesRequest) ([]*models.AccrualCandidate, error)
+	CreateAccrual(ctx context.Context, req *models.InterestAccrual) (bool, error)
+	GetCapitalizationCandidates(ctx context.Context, req *models.GetCapitalizationCandidatesRequest) ([]*models.CapitalizationCandidate, error)
+	CreateCapitalization(ctx context.Context, tx *sql.Tx, req *models.InterestCapitalization) error
+	GetInterestAccruals(ctx context.Context, req *models.GetInterestAccrualsRequest) (*models.GetInterestAccrualsResponse, error)
+}
+
+type JobRepoI interface {
+	StartJobRun(ctx context.Context, req *models.StartJobRunRequest) (bool, error)
+	FinishJobRun(ctx context.Context, req *models.FinishJobRunRequest) error
+	GetJobRunStatus(ctx context.Context, job, businessDate string) (string, error)
+}
+
+type DepositRepoI interface {
+	GetDepositTerms(ctx context.Context) (*models.GetDepositTermsResponse, error)
+	GetDepositTerm(ctx context.Context, termMonths int) (*models.DepositTerm, error)
+	CreateDeposit(ctx context.Context, tx *sql.Tx, req *models.TermDeposit) (*models.TermDeposit, error)
+	GetDepositByID(ctx context.Context, req *models.DepositByIDRequest) (*models.TermDeposit, error)
+	GetDepositForUpdate(ctx context.Context, tx *sql.Tx, id string) (*models.TermDeposit, error)
+	GetDeposits(ctx context.Context, req *models.GetDepositsRequest) (*models.GetDepositsResponse, error)
+	GetDueDepositIDs(ctx context.Context, dueDate string) ([]string, error)
+	UpdateDeposit(ctx context.Context, tx *sql.Tx, req *models.TermDeposit) error
+}
+
+type OverdraftRepoI interface {
+	SetOverdraftLimit(ctx context.Context, req *models.SetOverdraftLimitRequest) error
+	GetOverdraftUsage(ctx context.Context, accountID string) (*models.OverdraftUsage, error)
+	GetOverdraftAccrualCandidates(ctx context.Context, req *models.GetAccrualCandidatesRequest) ([]*models.AccrualCandidate, error)
+	CreateOverdraftAccrual(ctx context.Context, req *models.InterestAccrual) (bool, error)
+	GetOverdraftChargeCandidates(ctx context.Context, req *models.GetCapitalizationCandidatesRequest) ([]*models.OverdraftChargeCandidate, error)
+	CreateOverdraftCharge(ctx context.Context, tx *sql.Tx, req *models.InterestCapitalization) error
+}
+
+type LoanRepoI interface {
+	GetLoanProducts(ctx context.Context) (*models.GetLoanProductsResponse, error)
+	GetLoanProduct(ctx context.Context, code string) (*models.LoanProduct, error)
+	CreateLoan(ctx context.Context, tx *sql.Tx, req *models.Loan) (*models.Loan, error)
+	CreateInstallments(ctx context.Context, tx *sql.Tx, installments []*models.LoanInstallment) error
+	DeletePendingInstallments(ctx context.Context, tx *sql.Tx, loanID string) error
+	GetLoanByID(ctx context.Context, req *models.LoanByIDRequest) (*models.Loan, error)
+	GetLoanForUpdate(ctx context.Context, tx *sql.Tx, id string) (*models.Loan, error)
+	GetLoans(ctx context.Context, req *models.GetLoansRequest) (*models.GetLoansResponse, error)
+	GetDueLoanIDs(ctx context.Context, businessDate string) ([]string, error)
+	UpdateLoan(ctx context.Context, tx *sql.Tx, req *models.Loan) error
+	UpdateInstallment(ctx context.Context, tx *sql.Tx, req *models.LoanInstallment) error
+}
+
+type PotRepoI interface {
+	CreatePot(ctx context.Context, tx *sql.Tx, req *models.Pot) (*models.Pot, error)
+	GetPots(ctx context.Context, req *models.GetPotsRequest) ([]*models.Pot, error)
+	GetPotByID(ctx context.Context, req *models.PotByIDRequest) (*models.Pot, error)
+	GetPotForUpdate(ctx context.Context, tx *sql.Tx, id string) (*models.Pot, error)
+	GetRoundUpPot(ctx context.Context, accountID string) (*models.Pot, error)
+	UpdatePot(ctx context.Context, tx *sql.Tx, req *models.Pot) error
+	ClosePot(ctx context.Context, tx *sql.Tx, id string) error
+}
+
+type AccountHolderRepoI interface {
+	GetAccountRole(ctx context.Context, accountID, userID string) (string, error)
+	GetAccountRoleForUpdate(ctx context.Context, tx *sql.Tx, accountID, userID string) (string, error)
+	GetApprovalPolicy(ctx context.Context, accountID, userID string) (*models.ApprovalPolicy, error)
+	GetAccountHolders(ctx context.Context, accountID string) (*models.GetAccountHoldersResponse, error)
+	AddAccountHolder(ctx context.Context, tx *sql.Tx, accountID, userID, role string) error
+	UpdateAccountHolder(ctx context.Context, tx *sql.Tx, accountID, userID, role string) error
+	DeleteAccountHolder(ctx context.Context, tx *sql.Tx, accountID, userID string) error
+	SetDualApprovalThreshold(ctx context.Context, tx *sql.Tx, accountID, threshold string) error
+	CreateInvitation(ctx context.Context, tx *sql.Tx, req *models.AccountInvitation) (*models.AccountInvitation, error)
+	GetInvitations(ctx context.Context, userID string) (*models.GetInvitationsResponse, error)
+	GetInvitationForUpdate(ctx context.Context, tx *sql.Tx, id string) (*models.AccountInvitation, error)
+	UpdateInvitationStatus(ctx context.Context, tx *sql.Tx, id, status string) error
+	CreateTransferApproval(ctx context.Context, tx *sql.Tx, req *models.TransferApproval) (*models.TransferApproval, error)
+	GetTransferApprovals(ctx context.Context, req *models.GetTransferApprovalsRequest) (*models.GetTransferApprovalsResponse, error)
+	GetTransferApprovalForUpdate(ctx context.Context, tx *sql.Tx, req *models.TransferApprovalByIDRequest) (*models.TransferApproval, error)
+	UpdateTransferApproval(ctx context.Context, tx *sql.Tx, req *models.TransferApproval) error
+	DeleteUserHoldings(ctx context.Context, tx *sql.Tx, userID string) error
+}
+
+type OTPRepoI interface {
+	CreateOTP(ctx context.Context, req *models.OTPCode) (*models.OTPCode, error)
+	GetActiveOTPForUpdate(ctx context.Context, tx *sql.Tx, userID, purpose string) (*models.OTPCode, error)
+	IncrementOTPAttempts(ctx context.Context, tx *sql.Tx, id string) error
+	UseOTP(ctx context.Context, tx *sql.Tx, id string) error
+}
+
+type TwoFactorRepoI interface {
+	SetTOTPSecret(ctx context.Context, userID, secret string) error
+	GetTOTPForUpdate(ctx context.Context, tx *sql.Tx, userID string) (*models.TOTPSettings, error)
+	EnableTOTP(ctx context.Context, tx *sql.Tx, userID string, step int64) error
+	UpdateTOTPLastStep(ctx context.Context, tx *sql.Tx, userID string, step int64) error
+	DisableTOTP(ctx context.Context, tx *sql.Tx, userID string) error
+	ReplaceRecoveryCodes(ctx context.Context, tx *sql.Tx, userID string, hashes []string) error
+	UseRecoveryCode(ctx context.Context, tx *sql.Tx, userID, hash string) (bool, error)
+}
+
+type LoginFailureRepoI interface {
+	GetLoginLock(ctx context.Context, keys []string) (time.Duration, error)
+	RecordLoginFailure(ctx context.Context, tx *sql.Tx, key string, window time.Duration) (int, error)
+	LockLogin(ctx context.Context, tx *sql.Tx, key string, duration time.Duration) error
+	ResetLoginFailures(ctx context.Context, key string) error
+}
+
+type AuditRepoI interface {
+	BeginTx(ctx context.Context) (*sql.Tx, error)
+	CreateAuditEvent(ctx context.Context, tx *sql.Tx, req *models.AuditEvent) error
+	GetAuditEvents(ctx context.Context, req *models.GetAuditEventsRequest) (*models.GetAuditEventsResponse, error)
+	GetAuditChain(ctx context.Context, afterSeq int64, limit int) ([]*models.AuditEvent, error)
+}
+
+type AdminRepoI interface {
+	SearchUsers(ctx context.Context, req *models.SearchUsersRequest) (*models.SearchUsersResponse, error)
+	SearchAccounts(ctx context.Context, req *models.SearchAccountsRequest) (*models.SearchAccountsResponse, error)
+	GetTransaction(ctx context.Context, id string) (*models.Transaction, error)
+	CreateManualAdjustment(ctx context.Context, tx *sql.Tx, req *models.ManualAdjustment) error
+}
+
+type OperationRepoI interface {
+	CreatePendingOperation(ctx context.Context, tx *sql.Tx, req *models.PendingOperation) (*models.PendingOperation, error)
+	GetPendingOperations(ctx context.Context, req *models.GetPendingOperationsRequest) (*models.GetPendingOperationsResponse, error)
+	GetPendingOperation(ctx context.Context, id string) (*models.PendingOperation, error)
+	GetPendingOperationForUpdate(ctx context.Context, tx *sql.Tx, id string) (*models.PendingOperation, error)
+	UpdatePendingOperation(ctx context.Context, tx *sql.Tx, req *models.PendingOperation) error
+}
+
+type FraudRepoI interface {
+	GetFraudSignals(ctx context.Context, req *models.GetFraudSignalsRequest) (*models.FraudSignals, error)
+	CreateFraudDecision(ctx context.Context, req *models.FraudDecision) (*models.FraudDecision, error)
+	PassFraudChallenge(ctx context.Context, tx *sql.Tx, id, userID string) error
+	UseFraudChallenge(ctx context.Context, req *models.UseFraudChallengeRequest) error
+	GetFraudDecisions(ctx context.Context, req *models.GetFraudDecisionsRequest) (*models.GetFraudDecisionsResponse, error)
+	GetFraudDecision(ctx context.Context, id string) (*models.FraudDecision, error)
+	ReviewFraudDecision(ctx context.Context, tx *sql.Tx, req *models.ReviewFraudDecisionRequest) error
+}
+
+type AMLRepoI interface {
+	GetAMLCursor(ctx context.Context, tx *sql.Tx) (*models.AMLCursor, error)
+	SetAMLCursor(ctx context.Context, tx *sql.Tx, cursor *models.AMLCursor) error
+	GetAMLTransactions(ctx context.Context, tx *sql.Tx, req *models.GetAMLTransactionsRequest) ([]*models.AMLTransaction, error)
+	GetAMLActivity(ctx context.Context, tx *sql.Tx, req *models.GetAMLActivityRequest) ([]*models.AMLTransaction, error)
+	GetActiveAMLCase(ctx context.Context, tx *sql.Tx, accountID, caseType string) (*models.AMLCase, error)
+	CreateAMLCase(ctx context.Context, tx *sql.Tx, req *models.AMLCase) (*models.AMLCase, error)
+	LinkAMLCaseTransactions(ctx context.Context, tx *sql.Tx, caseID string, transactionIDs []string) (int64, error)
+	GetAMLCases(ctx context.Context, req *models.GetAMLCasesRequest) (*models.GetAMLCasesResponse, error)
+	GetAMLCase(ctx context.Context, id string) (*models.AMLCase, error)
+	UpdateAMLCaseStatus(ctx context.Context, tx *sql.Tx, req *models.UpdateAMLCaseStatusRequest, from string) error
+	GetAMLCaseTransactions(ctx context.Context, caseID string) ([]*models.AMLTransaction, error)
+	GetAMLCaseHistory(ctx context.Context, caseID string) ([]*models.AMLCaseStatusChange, error)
+}
+
+type SanctionsRepoI interface {
+	CreateSanctionsHits(ctx context.Context, tx *sql.Tx, hits []*models.SanctionsHit) ([]*models.SanctionsHit, error)
+	HasBlockingSanctionsHit(ctx context.Context, accountIDs []string) (bool, error)
+	GetSanctionsHits(ctx context.Context, req *models.GetSanctionsHitsRequest) (*models.GetSanctionsHitsResponse, error)
+	GetSanctionsHit(ctx context.Context, id string) (*models.SanctionsHit, error)
+	ReviewSanctionsHit(ctx context.Context, tx *sql.Tx, req *models.ReviewSanctionsHitRequest) error
+}
+
+type KYCRepoI interface {
+	GetKYCTier(ctx context.Context, userID string) (string, error)
+	GetAccountKYCTier(ctx context.Context, accountID string) (string, error)
+	SetKYCTier(ctx context.Context, tx *sql.Tx, userID, tier string) error
+	CreateKYCDocument(ctx context.Context, tx *sql.Tx, req *models.KYCDocument) (*models.KYCDocument, error)
+	GetKYCDocument(ctx context.Context, id string) (*models.KYCDocument, error)
+	GetUnsubmittedKYCDocuments(ctx context.Context, userID string) ([]*models.KYCDocument, error)
+	GetKYCApplicationDocuments(ctx context.Context, applicationID string) ([]*models.KYCDocument, error)
+	CreateKYCApplication(ctx context.Context, tx *sql.Tx, req *models.KYCApplication) (*models.KYCApplication, error)
+	AttachKYCDocuments(ctx context.Context, tx *sql.Tx, userID, applicationID string) ([]*models.KYCDocument, error)
+	GetKYCApplications(ctx context.Context, req *models.GetKYCApplicationsRequest) (*models.GetKYCApplicationsResponse, error)
+	GetKYCApplication(ctx context.Context, id string) (*models.KYCApplication, error)
+	GetLatestKYCApplication(ctx context.Context, userID string) (*models.KYCApplication, error)
+	GetKYCApplicationForUpdate(ctx context.Context, tx *sql.Tx, id string) (*models.KYCApplication, error)
+	ReviewKYCApplication(ctx context.Context, tx *sql.Tx, req *models.ReviewKYCApplicationRequest) error
 }